@@ -0,0 +1,106 @@
+//go:build lambda.norpc
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// CORS lets browser-based MCP clients and the web dashboard call the proxy
+// directly. Configure with:
+//
+//	CORS_ALLOWED_ORIGINS  comma-separated origins, or "*" (unset = CORS disabled)
+//	CORS_ALLOWED_HEADERS  comma-separated request headers (default: corsDefaultHeaders)
+//	CORS_MAX_AGE          preflight cache lifetime in seconds (default 600)
+
+const (
+	corsDefaultHeaders = "Authorization, Content-Type, Accept, Mcp-Session-Id, Mcp-Protocol-Version, X-Podcaster-Runtime, Traceparent, Tracestate"
+	corsExposeHeaders  = "Mcp-Session-Id, X-Podcaster-Runtime, X-Podcaster-Trace-Id"
+	corsAllowMethods   = "POST, OPTIONS"
+	corsDefaultMaxAge  = 600
+)
+
+type corsConfig struct {
+	origins   map[string]bool
+	anyOrigin bool
+	headers   string
+	maxAge    string
+}
+
+// loadCORSConfig reads CORS settings from the environment.
+// Returns nil when CORS_ALLOWED_ORIGINS is unset.
+func loadCORSConfig() *corsConfig {
+	raw := strings.TrimSpace(os.Getenv("CORS_ALLOWED_ORIGINS"))
+	if raw == "" {
+		return nil
+	}
+
+	c := &corsConfig{
+		origins: make(map[string]bool),
+		headers: corsDefaultHeaders,
+		maxAge:  strconv.Itoa(corsDefaultMaxAge),
+	}
+	for _, o := range strings.Split(raw, ",") {
+		o = strings.TrimRight(strings.TrimSpace(o), "/")
+		switch o {
+		case "":
+		case "*":
+			c.anyOrigin = true
+		default:
+			c.origins[strings.ToLower(o)] = true
+		}
+	}
+	if h := strings.TrimSpace(os.Getenv("CORS_ALLOWED_HEADERS")); h != "" {
+		c.headers = h
+	}
+	if v := os.Getenv("CORS_MAX_AGE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			c.maxAge = strconv.Itoa(n)
+		} else {
+			log.Warn("Ignoring invalid CORS_MAX_AGE", "value", v)
+		}
+	}
+	return c
+}
+
+// allowedOrigin returns the value for Access-Control-Allow-Origin, or "" if
+// the request origin is not permitted.
+func (c *corsConfig) allowedOrigin(origin string) string {
+	if c == nil || origin == "" {
+		return ""
+	}
+	if c.anyOrigin {
+		return "*"
+	}
+	if c.origins[strings.ToLower(strings.TrimRight(origin, "/"))] {
+		return origin
+	}
+	return ""
+}
+
+// applyCORS adds CORS response headers for an allowed origin. Preflight
+// responses additionally carry the allowed methods, headers, and max-age.
+func (c *corsConfig) applyCORS(origin string, preflight bool, resp *events.LambdaFunctionURLResponse) {
+	allow := c.allowedOrigin(origin)
+	if allow == "" {
+		return
+	}
+	if resp.Headers == nil {
+		resp.Headers = make(map[string]string)
+	}
+	resp.Headers["Access-Control-Allow-Origin"] = allow
+	if allow != "*" {
+		resp.Headers["Vary"] = "Origin"
+	}
+	if preflight {
+		resp.Headers["Access-Control-Allow-Methods"] = corsAllowMethods
+		resp.Headers["Access-Control-Allow-Headers"] = c.headers
+		resp.Headers["Access-Control-Max-Age"] = c.maxAge
+		return
+	}
+	resp.Headers["Access-Control-Expose-Headers"] = corsExposeHeaders
+}
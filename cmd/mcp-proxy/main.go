@@ -3,6 +3,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -22,13 +23,16 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/bedrockagentcore"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
 )
 
 var (
 	ddbClient *dynamodb.Client
 	acClient  *bedrockagentcore.Client
 	tableName string
-	runtimeARN string
+	router    *runtimeRouter
+	cors      *corsConfig
+	trial     *trialConfig
 	log       *slog.Logger
 )
 
@@ -36,18 +40,33 @@ func init() {
 	log = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
 
 	tableName = os.Getenv("DYNAMODB_TABLE")
-	runtimeARN = os.Getenv("RUNTIME_ARN")
+	if tableName == "" {
+		log.Error("DYNAMODB_TABLE environment variable is required")
+		os.Exit(1)
+	}
 
-	if tableName == "" || runtimeARN == "" {
-		log.Error("DYNAMODB_TABLE and RUNTIME_ARN environment variables are required")
+	var err error
+	router, err = newRuntimeRouter(os.Getenv("RUNTIMES"), os.Getenv("RUNTIME_ARN"))
+	if err != nil {
+		log.Error("RUNTIMES or RUNTIME_ARN environment variable is required", "error", err)
 		os.Exit(1)
 	}
+	for _, t := range router.targets {
+		log.Info("Configured runtime", "runtime", t.Name, "arn", t.ARN, "weight", t.Weight)
+	}
+
+	cors = loadCORSConfig()
+	trial = loadTrialConfig()
+
+	initTracing(context.Background())
 
 	cfg, err := awsconfig.LoadDefaultConfig(context.Background())
 	if err != nil {
 		log.Error("Failed to load AWS config", "error", err)
 		os.Exit(1)
 	}
+	// DynamoDB and AgentCore calls become spans of the request's trace.
+	otelaws.AppendMiddlewares(&cfg.APIOptions)
 
 	ddbClient = dynamodb.NewFromConfig(cfg)
 	acClient = bedrockagentcore.NewFromConfig(cfg)
@@ -57,91 +76,167 @@ func main() {
 	lambda.Start(handler)
 }
 
+// handler wraps handle with CORS headers so browser clients can read both
+// successful and error responses, and with the request's span (tracing.go).
 func handler(ctx context.Context, req events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
 	if req.RequestContext.HTTP.Method == "OPTIONS" {
-		return events.LambdaFunctionURLResponse{StatusCode: 204}, nil
+		resp := events.LambdaFunctionURLResponse{StatusCode: 204}
+		cors.applyCORS(getHeader(req.Headers, "origin"), true, &resp)
+		return resp, nil
+	}
+
+	ctx, span := startRequestSpan(ctx, req)
+	resp, err := handle(ctx, req)
+	cors.applyCORS(getHeader(req.Headers, "origin"), false, &resp)
+	endRequestSpan(ctx, span, &resp)
+	return resp, err
+}
+
+func handle(ctx context.Context, req events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+	if isWebhook(req) {
+		return handleWebhook(ctx, req), nil
 	}
 
 	if req.RequestContext.HTTP.Method != "POST" {
 		return jsonRPCError(405, nil, -32600, "Method not allowed"), nil
 	}
 
+	body := []byte(req.Body)
+
 	// Validate auth
+	var userID, keyID, keyRuntime, trialIPHash string
 	authHeader := getHeader(req.Headers, "authorization")
 	if authHeader == "" {
-		return jsonRPCError(401, nil, -32001, "Missing Authorization header"), nil
+		if trial == nil {
+			return jsonRPCError(401, nil, -32001, "Missing Authorization header"), nil
+		}
+		if !trialAllowed(body) {
+			return jsonRPCError(401, nil, -32001, "This tool requires an API key. Get one at https://podcasts.apresai.dev"), nil
+		}
+		trialIPHash = trial.ipHash(clientIP(req))
+		keyID = "trial-" + trialIPHash[:8] // sticky routing key
+		log.InfoContext(ctx, "Trial request", "ip_hash", trialIPHash)
+	} else {
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		if token == authHeader || token == "" {
+			return jsonRPCError(401, nil, -32001, "Invalid Authorization format, expected: Bearer <api-key>"), nil
+		}
+
+		var err error
+		userID, keyID, keyRuntime, err = validateAPIKey(ctx, token)
+		if err != nil {
+			log.WarnContext(ctx, "Auth failed", "error", err)
+			// Distinguish user-status errors (403) from key errors (401)
+			if strings.Contains(err.Error(), "user account is") {
+				return jsonRPCError(403, nil, -32001, err.Error()), nil
+			}
+			return jsonRPCError(401, nil, -32001, "Invalid API key"), nil
+		}
+
+		log.InfoContext(ctx, "Authenticated", "user_id", userID, "key_id", keyID)
 	}
 
-	token := strings.TrimPrefix(authHeader, "Bearer ")
-	if token == authHeader || token == "" {
-		return jsonRPCError(401, nil, -32001, "Invalid Authorization format, expected: Bearer <api-key>"), nil
+	// Parse JSON-RPC to possibly inject user context. Trial callers get an
+	// empty _user_id/_key_id so they can't impersonate a user.
+	userCtx := map[string]string{
+		"_user_id":       userID,
+		"_key_id":        keyID,
+		"_trial_ip_hash": trialIPHash,
 	}
+	if trialIPHash != "" {
+		userCtx["_key_id"] = ""
+	}
+	body, rpcID := maybeInjectUserContext(body, userCtx)
 
-	userID, keyID, err := validateAPIKey(ctx, token)
-	if err != nil {
-		log.WarnContext(ctx, "Auth failed", "error", err)
-		// Distinguish user-status errors (403) from key errors (401)
-		if strings.Contains(err.Error(), "user account is") {
-			return jsonRPCError(403, nil, -32001, err.Error()), nil
+	// Extract MCP session ID from request headers. Sessions are bound to the
+	// runtime that created them, so a session ID overrides all routing; an
+	// untagged one predates multi-runtime routing and is the default's.
+	sessionRuntime, mcpSessionID := router.splitSessionID(getHeader(req.Headers, "mcp-session-id"))
+
+	var target *runtimeTarget
+	if sessionRuntime != "" {
+		target = router.lookup(sessionRuntime)
+	} else if mcpSessionID != "" {
+		target = router.defaultTarget()
+	} else {
+		preferred := getHeader(req.Headers, runtimeHeader)
+		if preferred == "" {
+			preferred = keyRuntime
 		}
-		return jsonRPCError(401, nil, -32001, "Invalid API key"), nil
+		target = router.pick(preferred, keyID)
 	}
 
-	log.InfoContext(ctx, "Authenticated", "user_id", userID, "key_id", keyID)
+	// Forward to AgentCore
+	respBody, upstreamSessionID, err := invokeRuntime(ctx, target, body, mcpSessionID)
+	if err != nil && mcpSessionID == "" {
+		// No session yet, so any runtime can serve this request.
+		if alt := router.fallback(target, keyID); alt != nil {
+			log.WarnContext(ctx, "Retrying on fallback runtime", "failed", target.Name, "fallback", alt.Name)
+			target = alt
+			respBody, upstreamSessionID, err = invokeRuntime(ctx, target, body, mcpSessionID)
+		}
+	}
+	if err != nil {
+		return jsonRPCError(502, rpcID, -32603, "Upstream server error"), nil
+	}
 
-	// Parse JSON-RPC to possibly inject user context
-	body := []byte(req.Body)
-	body, rpcID := maybeInjectUserContext(body, userID, keyID)
+	respHeaders := map[string]string{
+		"Content-Type":        "application/json",
+		"X-Podcaster-Runtime": target.Name,
+	}
+	if upstreamSessionID != "" {
+		respHeaders["Mcp-Session-Id"] = router.joinSessionID(target.Name, upstreamSessionID)
+	}
 
-	// Extract MCP session ID from request headers
-	mcpSessionID := getHeader(req.Headers, "mcp-session-id")
+	return events.LambdaFunctionURLResponse{
+		StatusCode: 200,
+		Headers:    respHeaders,
+		Body:       string(respBody),
+	}, nil
+}
 
-	// Forward to AgentCore
+// invokeRuntime forwards the JSON-RPC payload to one AgentCore runtime and
+// records the outcome against its health. Returns the response body and the
+// upstream session ID (if any).
+func invokeRuntime(ctx context.Context, target *runtimeTarget, body []byte, sessionID string) ([]byte, string, error) {
 	input := &bedrockagentcore.InvokeAgentRuntimeInput{
-		AgentRuntimeArn: &runtimeARN,
+		AgentRuntimeArn: &target.ARN,
 		Payload:         body,
 		ContentType:     aws.String("application/json"),
 		Accept:          aws.String("application/json, text/event-stream"),
 	}
-	if mcpSessionID != "" {
-		input.McpSessionId = &mcpSessionID
+	if sessionID != "" {
+		input.McpSessionId = &sessionID
 	}
+	withTraceContext(ctx, input)
 
 	out, err := acClient.InvokeAgentRuntime(ctx, input)
 	if err != nil {
-		log.ErrorContext(ctx, "AgentCore invocation failed", "error", err)
-		return jsonRPCError(502, rpcID, -32603, "Upstream server error"), nil
+		log.ErrorContext(ctx, "AgentCore invocation failed", "runtime", target.Name, "error", err)
+		target.recordFailure()
+		return nil, "", err
 	}
 	defer out.Response.Close()
 
 	respBody, err := io.ReadAll(out.Response)
 	if err != nil {
-		log.ErrorContext(ctx, "Failed to read AgentCore response", "error", err)
-		return jsonRPCError(502, rpcID, -32603, "Failed to read upstream response"), nil
+		log.ErrorContext(ctx, "Failed to read AgentCore response", "runtime", target.Name, "error", err)
+		target.recordFailure()
+		return nil, "", err
 	}
+	target.recordSuccess()
 
-	respHeaders := map[string]string{
-		"Content-Type": "application/json",
-	}
-	if out.McpSessionId != nil && *out.McpSessionId != "" {
-		respHeaders["Mcp-Session-Id"] = *out.McpSessionId
-	}
-
-	return events.LambdaFunctionURLResponse{
-		StatusCode: 200,
-		Headers:    respHeaders,
-		Body:       string(respBody),
-	}, nil
+	return respBody, aws.ToString(out.McpSessionId), nil
 }
 
 // validateAPIKey checks the bearer token against DynamoDB.
-// Returns (userID, keyPrefix, error).
-func validateAPIKey(ctx context.Context, token string) (string, string, error) {
+// Returns (userID, keyPrefix, runtime pin, error).
+func validateAPIKey(ctx context.Context, token string) (string, string, string, error) {
 	if !strings.HasPrefix(token, "pk_") {
-		return "", "", fmt.Errorf("invalid API key format")
+		return "", "", "", fmt.Errorf("invalid API key format")
 	}
 	if len(token) < 11 {
-		return "", "", fmt.Errorf("API key too short")
+		return "", "", "", fmt.Errorf("API key too short")
 	}
 
 	prefix := token[3:11]
@@ -158,26 +253,27 @@ func validateAPIKey(ctx context.Context, token string) (string, string, error) {
 		},
 	})
 	if err != nil {
-		return "", "", fmt.Errorf("lookup API key: %w", err)
+		return "", "", "", fmt.Errorf("lookup API key: %w", err)
 	}
 	if result.Item == nil {
-		return "", "", fmt.Errorf("API key not found")
+		return "", "", "", fmt.Errorf("API key not found")
 	}
 
 	var keyRecord struct {
 		UserID  string `dynamodbav:"userId"`
 		KeyHash string `dynamodbav:"keyHash"`
 		Status  string `dynamodbav:"status"`
+		Runtime string `dynamodbav:"runtime"` // optional routing pin, e.g. "canary"
 	}
 	if err := attributevalue.UnmarshalMap(result.Item, &keyRecord); err != nil {
-		return "", "", fmt.Errorf("unmarshal API key: %w", err)
+		return "", "", "", fmt.Errorf("unmarshal API key: %w", err)
 	}
 
 	if keyRecord.KeyHash != keyHash {
-		return "", "", fmt.Errorf("invalid API key")
+		return "", "", "", fmt.Errorf("invalid API key")
 	}
 	if keyRecord.Status != "active" {
-		return "", "", fmt.Errorf("API key is %s", keyRecord.Status)
+		return "", "", "", fmt.Errorf("API key is %s", keyRecord.Status)
 	}
 
 	// Look up user record
@@ -189,26 +285,26 @@ func validateAPIKey(ctx context.Context, token string) (string, string, error) {
 		},
 	})
 	if err != nil {
-		return "", "", fmt.Errorf("lookup user: %w", err)
+		return "", "", "", fmt.Errorf("lookup user: %w", err)
 	}
 	if userResult.Item == nil {
-		return "", "", fmt.Errorf("user not found for API key")
+		return "", "", "", fmt.Errorf("user not found for API key")
 	}
 
 	var userRecord struct {
 		Status string `dynamodbav:"status"`
 	}
 	if err := attributevalue.UnmarshalMap(userResult.Item, &userRecord); err != nil {
-		return "", "", fmt.Errorf("unmarshal user: %w", err)
+		return "", "", "", fmt.Errorf("unmarshal user: %w", err)
 	}
 	if userRecord.Status != "active" {
-		return "", "", fmt.Errorf("user account is %s", userRecord.Status)
+		return "", "", "", fmt.Errorf("user account is %s", userRecord.Status)
 	}
 
 	// Update lastUsedAt (best-effort)
 	go updateKeyLastUsed(prefix)
 
-	return keyRecord.UserID, prefix, nil
+	return keyRecord.UserID, prefix, keyRecord.Runtime, nil
 }
 
 // updateKeyLastUsed updates the lastUsedAt timestamp on the API key record.
@@ -239,9 +335,32 @@ func updateKeyLastUsed(prefix string) {
 }
 
 // maybeInjectUserContext parses the JSON-RPC body. If the method is "tools/call",
-// it injects _user_id and _key_id into params.arguments. Returns the (possibly
-// modified) body and the parsed JSON-RPC id.
-func maybeInjectUserContext(body []byte, userID, keyID string) ([]byte, json.RawMessage) {
+// it injects the userCtx fields (_user_id, _key_id, _trial_ip_hash) into
+// params.arguments, overwriting any client-supplied values. Returns the (possibly
+// modified) body and the parsed JSON-RPC id. For a batch array every entry is
+// injected individually and the returned id is nil, since errors for a batch
+// as a whole are reported with a null id.
+func maybeInjectUserContext(body []byte, userCtx map[string]string) ([]byte, json.RawMessage) {
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var entries []json.RawMessage
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return body, nil
+		}
+		for i, entry := range entries {
+			entries[i], _ = injectUserContext(entry, userCtx)
+		}
+		newBody, err := json.Marshal(entries)
+		if err != nil {
+			return body, nil
+		}
+		log.Info("Forwarding JSON-RPC batch", "entries", len(entries), "user_id", userCtx["_user_id"])
+		return newBody, nil
+	}
+	return injectUserContext(body, userCtx)
+}
+
+// injectUserContext handles a single JSON-RPC message for maybeInjectUserContext.
+func injectUserContext(body []byte, userCtx map[string]string) ([]byte, json.RawMessage) {
 	var rpc struct {
 		JSONRPC string          `json:"jsonrpc"`
 		ID      json.RawMessage `json:"id"`
@@ -276,10 +395,9 @@ func maybeInjectUserContext(body []byte, userID, keyID string) ([]byte, json.Raw
 	}
 
 	// Inject user context
-	userIDJSON, _ := json.Marshal(userID)
-	keyIDJSON, _ := json.Marshal(keyID)
-	args["_user_id"] = userIDJSON
-	args["_key_id"] = keyIDJSON
+	for k, v := range userCtx {
+		args[k] = mustMarshal(v)
+	}
 
 	// Rebuild the JSON-RPC request
 	newArgs, err := json.Marshal(args)
@@ -307,7 +425,7 @@ func maybeInjectUserContext(body []byte, userID, keyID string) ([]byte, json.Raw
 		return body, rpc.ID
 	}
 
-	log.Info("Injected user context into tools/call", "tool", params.Name, "user_id", userID)
+	log.Info("Injected user context into tools/call", "tool", params.Name, "user_id", userCtx["_user_id"])
 	return newBody, rpc.ID
 }
 
@@ -0,0 +1,220 @@
+//go:build lambda.norpc
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Routing across multiple AgentCore runtimes (e.g. stable + canary builds of
+// the MCP server). Configure with the RUNTIMES env var, a JSON array:
+//
+//	[{"name":"stable","arn":"arn:...:runtime/a","weight":95},
+//	 {"name":"canary","arn":"arn:...:runtime/b","weight":5}]
+//
+// When RUNTIMES is unset, RUNTIME_ARN is used as a single "default" runtime.
+// Otherwise the entry whose ARN is RUNTIME_ARN, or else the first entry, is
+// the default runtime.
+//
+// Selection order for each request:
+//  1. Mcp-Session-Id prefix — sessions live on one runtime, so they stay there.
+//     An untagged session ID (issued with a single runtime, before RUNTIMES
+//     was set) belongs to the default runtime
+//  2. X-Podcaster-Runtime header (explicit pin, e.g. for smoke-testing a canary)
+//  3. "runtime" attribute on the API key record (pin a tester's key to canary)
+//  4. Weighted choice, hashed on the key prefix so a key sticks to one runtime
+//
+// Health is tracked per Lambda instance. A runtime that fails
+// runtimeFailThreshold times in a row is skipped for runtimeCooldown, after
+// which it is tried again (automatic failback).
+
+const (
+	runtimeHeader        = "x-podcaster-runtime"
+	sessionSeparator     = "~"
+	runtimeFailThreshold = 3
+	runtimeCooldown      = 30 * time.Second
+)
+
+type runtimeTarget struct {
+	Name   string `json:"name"`
+	ARN    string `json:"arn"`
+	Weight int    `json:"weight"`
+
+	mu               sync.Mutex
+	consecutiveFails int
+	unhealthyUntil   time.Time
+}
+
+// healthy reports whether the runtime is eligible for new (non-session) traffic.
+func (t *runtimeTarget) healthy(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return now.After(t.unhealthyUntil)
+}
+
+// recordSuccess resets the failure counter, restoring the runtime to rotation.
+func (t *runtimeTarget) recordSuccess() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.consecutiveFails >= runtimeFailThreshold {
+		log.Info("Runtime recovered", "runtime", t.Name)
+	}
+	t.consecutiveFails = 0
+	t.unhealthyUntil = time.Time{}
+}
+
+// recordFailure counts an upstream failure and trips the runtime out of
+// rotation once the threshold is reached.
+func (t *runtimeTarget) recordFailure() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.consecutiveFails++
+	if t.consecutiveFails >= runtimeFailThreshold {
+		t.unhealthyUntil = time.Now().Add(runtimeCooldown)
+		log.Warn("Runtime marked unhealthy", "runtime", t.Name,
+			"consecutive_failures", t.consecutiveFails, "cooldown", runtimeCooldown)
+	}
+}
+
+// runtimeRouter picks an AgentCore runtime for each request.
+type runtimeRouter struct {
+	targets []*runtimeTarget
+	byName  map[string]*runtimeTarget
+	def     *runtimeTarget // owner of untagged session IDs
+}
+
+// newRuntimeRouter builds a router from the RUNTIMES JSON (or a single ARN).
+func newRuntimeRouter(runtimesJSON, fallbackARN string) (*runtimeRouter, error) {
+	var targets []*runtimeTarget
+	if runtimesJSON != "" {
+		if err := json.Unmarshal([]byte(runtimesJSON), &targets); err != nil {
+			return nil, fmt.Errorf("parse RUNTIMES: %w", err)
+		}
+	} else if fallbackARN != "" {
+		targets = []*runtimeTarget{{Name: "default", ARN: fallbackARN, Weight: 100}}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no runtimes configured")
+	}
+
+	r := &runtimeRouter{byName: make(map[string]*runtimeTarget, len(targets))}
+	for _, t := range targets {
+		if t.Name == "" || t.ARN == "" {
+			return nil, fmt.Errorf("runtime entries require name and arn")
+		}
+		if strings.Contains(t.Name, sessionSeparator) {
+			return nil, fmt.Errorf("runtime name %q must not contain %q", t.Name, sessionSeparator)
+		}
+		if _, dup := r.byName[t.Name]; dup {
+			return nil, fmt.Errorf("duplicate runtime name %q", t.Name)
+		}
+		if t.Weight < 0 {
+			return nil, fmt.Errorf("runtime %q has negative weight", t.Name)
+		}
+		r.targets = append(r.targets, t)
+		r.byName[t.Name] = t
+		if r.def == nil && t.ARN == fallbackARN {
+			r.def = t
+		}
+	}
+	if r.def == nil {
+		r.def = r.targets[0]
+	}
+	return r, nil
+}
+
+// lookup returns a runtime by name, or nil if unknown.
+func (r *runtimeRouter) lookup(name string) *runtimeTarget {
+	return r.byName[name]
+}
+
+// defaultTarget returns the runtime that owns untagged session IDs.
+func (r *runtimeRouter) defaultTarget() *runtimeTarget {
+	return r.def
+}
+
+// pick chooses a runtime for a request without a session. preferred is the
+// header or key pin (may be empty). Unhealthy runtimes are skipped unless
+// nothing else is available.
+func (r *runtimeRouter) pick(preferred, stickyKey string) *runtimeTarget {
+	now := time.Now()
+
+	if t := r.lookup(preferred); t != nil && t.healthy(now) {
+		return t
+	}
+
+	candidates := r.healthyTargets(now, nil)
+	if len(candidates) == 0 {
+		// Everything is tripped; better to try than to fail outright.
+		candidates = r.targets
+	}
+	return weightedChoice(candidates, stickyKey)
+}
+
+// fallback returns another healthy runtime to retry on after failed fails,
+// or nil if there is none.
+func (r *runtimeRouter) fallback(failed *runtimeTarget, stickyKey string) *runtimeTarget {
+	candidates := r.healthyTargets(time.Now(), failed)
+	if len(candidates) == 0 {
+		return nil
+	}
+	return weightedChoice(candidates, stickyKey)
+}
+
+func (r *runtimeRouter) healthyTargets(now time.Time, exclude *runtimeTarget) []*runtimeTarget {
+	var out []*runtimeTarget
+	for _, t := range r.targets {
+		if t != exclude && t.healthy(now) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// weightedChoice maps stickyKey onto the cumulative weight range so the same
+// key consistently lands on the same runtime while the weights hold.
+func weightedChoice(targets []*runtimeTarget, stickyKey string) *runtimeTarget {
+	total := 0
+	for _, t := range targets {
+		total += t.Weight
+	}
+	if total == 0 {
+		return targets[0]
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(stickyKey))
+	n := int(h.Sum32() % uint32(total))
+	for _, t := range targets {
+		if n < t.Weight {
+			return t
+		}
+		n -= t.Weight
+	}
+	return targets[len(targets)-1]
+}
+
+// splitSessionID separates the runtime name prefix from an upstream session ID.
+// IDs without a known prefix are returned unchanged with an empty runtime name.
+func (r *runtimeRouter) splitSessionID(id string) (runtime, upstreamID string) {
+	if i := strings.Index(id, sessionSeparator); i > 0 {
+		if r.lookup(id[:i]) != nil {
+			return id[:i], id[i+len(sessionSeparator):]
+		}
+	}
+	return "", id
+}
+
+// joinSessionID tags an upstream session ID with the runtime that owns it.
+// With a single runtime the ID is passed through untouched.
+func (r *runtimeRouter) joinSessionID(runtime, upstreamID string) string {
+	if len(r.targets) == 1 {
+		return upstreamID
+	}
+	return runtime + sessionSeparator + upstreamID
+}
@@ -0,0 +1,110 @@
+//go:build lambda.norpc
+
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/observability"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockagentcore"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The proxy starts each request's trace, or continues the caller's when it
+// sends a W3C traceparent, and hands it to AgentCore in the
+// InvokeAgentRuntime trace fields. AgentCore forwards them to the MCP
+// server, whose request, tool, pipeline stage, and provider spans join the
+// same trace, and the trace ID comes back in traceIDHeader.
+//
+// Spans are exported only with OTEL_EXPORTER_OTLP_ENDPOINT set (e.g. to the
+// ADOT collector layer). Without it the proxy still mints trace IDs, so the
+// server's spans and the proxy's logs share one.
+
+// traceIDHeader returns the request's trace ID to the client, for quoting
+// in a report of a slow or failed episode.
+const traceIDHeader = "X-Podcaster-Trace-Id"
+
+var (
+	tracer = otel.Tracer("podcaster-mcp-proxy")
+
+	// exporter is the provider to flush before each invocation returns,
+	// since Lambda freezes the process in between; nil when not exporting.
+	exporter *sdktrace.TracerProvider
+)
+
+// initTracing sets up the tracer provider and W3C propagation.
+func initTracing(ctx context.Context) {
+	observability.InitPropagator()
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider())
+		return
+	}
+	tp, err := observability.InitTracer(ctx, "podcaster-mcp-proxy", "1.0.0")
+	if err != nil {
+		log.Warn("Failed to init tracer, continuing without exporting spans", "error", err)
+		otel.SetTracerProvider(sdktrace.NewTracerProvider())
+		return
+	}
+	exporter = tp
+}
+
+// startRequestSpan starts the span for one Function URL request, as a child
+// of the caller's traceparent if it sent one.
+func startRequestSpan(ctx context.Context, req events.LambdaFunctionURLRequest) (context.Context, trace.Span) {
+	headers := make(propagation.MapCarrier, len(req.Headers))
+	for k, v := range req.Headers {
+		headers[strings.ToLower(k)] = v
+	}
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headers)
+	return tracer.Start(ctx, "proxy.request",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.RequestContext.HTTP.Method),
+			attribute.String("url.path", req.RequestContext.HTTP.Path),
+		),
+	)
+}
+
+// endRequestSpan records the response on the span, ends it, and flushes it
+// before Lambda freezes the process.
+func endRequestSpan(ctx context.Context, span trace.Span, resp *events.LambdaFunctionURLResponse) {
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.SetStatus(codes.Error, "upstream error")
+	}
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		if resp.Headers == nil {
+			resp.Headers = map[string]string{}
+		}
+		resp.Headers[traceIDHeader] = sc.TraceID().String()
+	}
+	span.End()
+	if exporter != nil {
+		if err := exporter.ForceFlush(ctx); err != nil {
+			log.Warn("Failed to flush spans", "error", err)
+		}
+	}
+}
+
+// withTraceContext sets the InvokeAgentRuntime trace fields from ctx.
+func withTraceContext(ctx context.Context, input *bedrockagentcore.InvokeAgentRuntimeInput) {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if v := carrier.Get("traceparent"); v != "" {
+		input.TraceParent = &v
+	}
+	if v := carrier.Get("tracestate"); v != "" {
+		input.TraceState = &v
+	}
+	if v := carrier.Get("baggage"); v != "" {
+		input.Baggage = &v
+	}
+}
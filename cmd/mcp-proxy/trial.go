@@ -0,0 +1,102 @@
+//go:build lambda.norpc
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Trial mode admits requests without an Authorization header so prospective
+// users can try the hosted service. Configure with:
+//
+//	TRIAL_ENABLED   "true" to admit unauthenticated requests
+//	TRIAL_IP_SALT   secret mixed into the IP hash (required; trial stays off without it)
+//
+// The proxy forwards a salted hash of the caller's IP as _trial_ip_hash; the
+// MCP server enforces the per-IP daily limit (TRIAL_DAILY_LIMIT on the
+// runtime) and the short-episode restrictions.
+
+// trialTools are the tools an unauthenticated caller may invoke.
+var trialTools = map[string]bool{
+	"generate_podcast": true,
+	"get_podcast":      true,
+	"list_options":     true,
+	"list_voices":      true,
+}
+
+type trialConfig struct {
+	salt string
+}
+
+// loadTrialConfig reads trial settings from the environment.
+// Returns nil when trial mode is disabled.
+func loadTrialConfig() *trialConfig {
+	if os.Getenv("TRIAL_ENABLED") != "true" {
+		return nil
+	}
+	salt := os.Getenv("TRIAL_IP_SALT")
+	if salt == "" {
+		log.Warn("TRIAL_ENABLED is set but TRIAL_IP_SALT is empty; trial mode disabled")
+		return nil
+	}
+	return &trialConfig{salt: salt}
+}
+
+// clientIP returns the viewer's IP. Behind CloudFront the Function URL sees an
+// edge IP, so CloudFront-Viewer-Address (forwarded by the origin request
+// policy) takes precedence.
+func clientIP(req events.LambdaFunctionURLRequest) string {
+	if addr := getHeader(req.Headers, "cloudfront-viewer-address"); addr != "" {
+		// "ip:port" — IPv6 addresses are not bracketed, so split on the last colon.
+		if i := strings.LastIndex(addr, ":"); i > 0 {
+			return addr[:i]
+		}
+		return addr
+	}
+	return req.RequestContext.HTTP.SourceIP
+}
+
+// ipHash returns the salted hash used as the trial counter key. IPv6 callers
+// are bucketed by /64 so a single host can't rotate through its prefix.
+func (t *trialConfig) ipHash(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		ip = parsed.Mask(net.CIDRMask(64, 128)).String() + "/64"
+	}
+	h := sha256.Sum256([]byte(t.salt + "|" + ip))
+	return hex.EncodeToString(h[:16])
+}
+
+// trialAllowed reports whether every tools/call in body (a single message or
+// a batch) targets a trial tool. Other methods (initialize, tools/list, ...)
+// are allowed.
+func trialAllowed(body []byte) bool {
+	entries := []json.RawMessage{body}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return false
+		}
+	}
+	for _, entry := range entries {
+		var rpc struct {
+			Method string `json:"method"`
+			Params struct {
+				Name string `json:"name"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(entry, &rpc); err != nil {
+			return false
+		}
+		if rpc.Method == "tools/call" && !trialTools[rpc.Params.Name] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,230 @@
+//go:build lambda.norpc
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// The inbound webhook lets no-code automations (Zapier, Make, n8n) start a
+// generation with a plain HTTP POST instead of an MCP session:
+//
+//	POST /webhook
+//	Authorization: Bearer pk_...
+//	{"url": "https://...", "preset": "daily-brief"}
+//
+// The body is JSON or form-encoded with url or text, and optionally preset
+// and topic. The proxy turns it into a generate_podcast tools/call for the
+// key's runtime and answers 202 with {"podcast_id", "status"}, or an
+// {"error"} with the tool's error status. Trial callers are not admitted.
+
+// webhookPath is the path the webhook is served on.
+const webhookPath = "/webhook"
+
+// webhookRequest is the webhook body.
+type webhookRequest struct {
+	URL    string `json:"url"`
+	Text   string `json:"text"`
+	Preset string `json:"preset"`
+	Topic  string `json:"topic"`
+}
+
+// isWebhook reports whether req is for the webhook rather than MCP.
+func isWebhook(req events.LambdaFunctionURLRequest) bool {
+	return strings.TrimSuffix(req.RawPath, "/") == webhookPath
+}
+
+// handleWebhook serves POST /webhook.
+func handleWebhook(ctx context.Context, req events.LambdaFunctionURLRequest) events.LambdaFunctionURLResponse {
+	if req.RequestContext.HTTP.Method != "POST" {
+		return webhookError(405, "Method not allowed")
+	}
+
+	token := strings.TrimPrefix(getHeader(req.Headers, "authorization"), "Bearer ")
+	if token == "" || token == getHeader(req.Headers, "authorization") {
+		return webhookError(401, "Missing API key, expected: Authorization: Bearer <api-key>")
+	}
+	userID, keyID, keyRuntime, err := validateAPIKey(ctx, token)
+	if err != nil {
+		log.WarnContext(ctx, "Webhook auth failed", "error", err)
+		if strings.Contains(err.Error(), "user account is") {
+			return webhookError(403, err.Error())
+		}
+		return webhookError(401, "Invalid API key")
+	}
+
+	wr, err := parseWebhookRequest(req)
+	if err != nil {
+		return webhookError(400, err.Error())
+	}
+	if wr.URL == "" && wr.Text == "" {
+		return webhookError(400, "url or text is required")
+	}
+
+	args := map[string]string{
+		"input_url":  wr.URL,
+		"input_text": wr.Text,
+		"preset":     wr.Preset,
+		"topic":      wr.Topic,
+		"_user_id":   userID,
+		"_key_id":    keyID,
+	}
+	for k, v := range args {
+		if v == "" {
+			delete(args, k)
+		}
+	}
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": "generate_podcast", "arguments": args},
+	})
+	if err != nil {
+		return webhookError(500, "Failed to build request")
+	}
+
+	log.InfoContext(ctx, "Webhook generate", "user_id", userID, "key_id", keyID, "preset", wr.Preset)
+	target := router.pick(keyRuntime, keyID)
+	respBody, _, err := invokeRuntime(ctx, target, body, "")
+	if err != nil {
+		if alt := router.fallback(target, keyID); alt != nil {
+			log.WarnContext(ctx, "Retrying on fallback runtime", "failed", target.Name, "fallback", alt.Name)
+			target = alt
+			respBody, _, err = invokeRuntime(ctx, target, body, "")
+		}
+	}
+	if err != nil {
+		return webhookError(502, "Upstream server error")
+	}
+
+	result, status, err := toolCallResult(respBody)
+	if err != nil {
+		log.ErrorContext(ctx, "Webhook got an unreadable response", "runtime", target.Name, "error", err)
+		return webhookError(502, "Upstream server error")
+	}
+	if status != 0 {
+		return webhookError(status, result)
+	}
+	var started struct {
+		PodcastID string `json:"podcast_id"`
+		Status    string `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(result), &started); err != nil || started.PodcastID == "" {
+		log.ErrorContext(ctx, "Webhook response has no podcast_id", "runtime", target.Name, "result", result)
+		return webhookError(502, "Upstream server error")
+	}
+	return webhookJSON(202, map[string]string{
+		"podcast_id": started.PodcastID,
+		"status":     started.Status,
+	})
+}
+
+// parseWebhookRequest reads a JSON or form-encoded webhook body.
+func parseWebhookRequest(req events.LambdaFunctionURLRequest) (webhookRequest, error) {
+	raw := []byte(req.Body)
+	if req.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(req.Body)
+		if err != nil {
+			return webhookRequest{}, fmt.Errorf("invalid body encoding")
+		}
+		raw = decoded
+	}
+
+	var wr webhookRequest
+	if strings.HasPrefix(getHeader(req.Headers, "content-type"), "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(raw))
+		if err != nil {
+			return webhookRequest{}, fmt.Errorf("invalid form body")
+		}
+		wr = webhookRequest{URL: form.Get("url"), Text: form.Get("text"), Preset: form.Get("preset"), Topic: form.Get("topic")}
+	} else if err := json.Unmarshal(raw, &wr); err != nil {
+		return webhookRequest{}, fmt.Errorf("body must be JSON or form-encoded with url or text")
+	}
+	wr.URL = strings.TrimSpace(wr.URL)
+	wr.Preset = strings.TrimSpace(wr.Preset)
+	return wr, nil
+}
+
+// toolCallResult extracts a tools/call result from an AgentCore response,
+// which is plain JSON-RPC or an SSE stream carrying it. Returns the result
+// text, and for a tool or JSON-RPC error an HTTP status to report it with.
+func toolCallResult(body []byte) (string, int, error) {
+	msg := bytes.TrimSpace(body)
+	if len(msg) > 0 && msg[0] != '{' {
+		// SSE: the response is the last data line.
+		msg = nil
+		sc := bufio.NewScanner(bytes.NewReader(body))
+		sc.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+		for sc.Scan() {
+			if data, ok := bytes.CutPrefix(sc.Bytes(), []byte("data:")); ok {
+				msg = append(msg[:0], bytes.TrimSpace(data)...)
+			}
+		}
+	}
+
+	var rpc struct {
+		Result *struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError           bool `json:"isError"`
+			StructuredContent struct {
+				ErrorStatus int `json:"error_status"`
+			} `json:"structuredContent"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(msg, &rpc); err != nil {
+		return "", 0, fmt.Errorf("parse response: %w", err)
+	}
+	if rpc.Error != nil {
+		return rpc.Error.Message, 502, nil
+	}
+	if rpc.Result == nil {
+		return "", 0, fmt.Errorf("response has no result")
+	}
+	var text string
+	for _, c := range rpc.Result.Content {
+		if c.Type == "text" {
+			text += c.Text
+		}
+	}
+	if rpc.Result.IsError {
+		status := rpc.Result.StructuredContent.ErrorStatus
+		if status == 0 {
+			status = 400
+		}
+		return text, status, nil
+	}
+	return text, 0, nil
+}
+
+// webhookJSON builds a webhook response with a JSON body.
+func webhookJSON(status int, v any) events.LambdaFunctionURLResponse {
+	body, _ := json.Marshal(v)
+	return events.LambdaFunctionURLResponse{
+		StatusCode: status,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: string(body),
+	}
+}
+
+// webhookError builds a webhook error response.
+func webhookError(status int, message string) events.LambdaFunctionURLResponse {
+	return webhookJSON(status, map[string]string{"error": message})
+}
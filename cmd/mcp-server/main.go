@@ -2,6 +2,11 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,6 +17,33 @@ import (
 )
 
 func main() {
+	configFile := flag.String("config", os.Getenv("MCP_CONFIG_FILE"), "YAML config file; environment variables override it")
+	checkConfig := flag.Bool("check-config", false, "Validate the configuration, print it (API keys redacted), and exit")
+	healthcheck := flag.Bool("healthcheck", false, "Probe the running server's /healthz and exit 0 if it is healthy (for container HEALTHCHECK)")
+	flag.Parse()
+
+	if *healthcheck {
+		port := mcpserver.DefaultConfig().Port
+		if cfg, err := mcpserver.LoadConfig(*configFile); err == nil {
+			port = cfg.Port
+		}
+		if err := probeHealth(port); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *checkConfig {
+		cfg, err := mcpserver.LoadConfig(*configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+			os.Exit(1)
+		}
+		slog.New(slog.NewTextHandler(os.Stdout, nil)).Info("Effective config", "config", cfg)
+		return
+	}
+
 	logger := observability.InitLogger()
 
 	logger.Info("Podcaster MCP Server starting...")
@@ -30,7 +62,22 @@ func main() {
 		}()
 	}
 
-	cfg := mcpserver.DefaultConfig()
+	mp, err := observability.InitMeter(ctx, "podcaster-mcp", "1.0.0")
+	if err != nil {
+		logger.Warn("Failed to init meter, continuing without metrics", "error", err)
+	} else {
+		defer func() {
+			if err := mp.Shutdown(context.Background()); err != nil {
+				logger.Error("Meter shutdown error", "error", err)
+			}
+		}()
+	}
+
+	cfg, err := mcpserver.LoadConfig(*configFile)
+	if err != nil {
+		logger.Error("Invalid config", "error", err)
+		os.Exit(1)
+	}
 
 	srv, err := mcpserver.New(ctx, cfg, logger)
 	if err != nil {
@@ -40,10 +87,10 @@ func main() {
 
 	go func() {
 		<-ctx.Done()
-		logger.Info("Shutdown signal received, waiting for active tasks...")
-		// Give goroutines up to 8 seconds to clean up (FailJob → DynamoDB)
-		// before AgentCore sends SIGKILL (~10s after SIGTERM).
-		time.Sleep(8 * time.Second)
+		logger.Info("Shutdown signal received, draining tasks...", "timeout", cfg.ShutdownTimeout.String())
+		// Finish within cfg.ShutdownTimeout, before AgentCore sends SIGKILL
+		// (~10s after SIGTERM).
+		srv.Shutdown()
 		logger.Info("Shutdown complete")
 		os.Exit(0)
 	}()
@@ -53,3 +100,19 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// probeHealth checks the server listening on port on this host, so images
+// without curl can still declare a HEALTHCHECK.
+func probeHealth(port int) error {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d/healthz", port))
+	if err != nil {
+		return fmt.Errorf("healthcheck: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("healthcheck: HTTP %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
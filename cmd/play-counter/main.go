@@ -2,13 +2,18 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -18,8 +23,15 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
-// audioPathRegex matches GET /audio/{ULID}.mp3 requests with 200/206 status.
-var audioPathRegex = regexp.MustCompile(`GET /audio/([A-Z0-9]{26})\.mp3`)
+const (
+	// defaultWorkers is how many log files are fetched and parsed at once
+	// (PLAY_COUNTER_WORKERS overrides it).
+	defaultWorkers = 8
+	// maxLineBytes is the read buffer, and so the longest log line counted.
+	maxLineBytes = 256 << 10
+	// transactMax is DynamoDB's TransactWriteItems action limit.
+	transactMax = 100
+)
 
 func main() {
 	ctx := context.Background()
@@ -52,9 +64,7 @@ func main() {
 		Prefix: &logPrefix,
 	})
 
-	playCounts := make(map[string]int) // podcastID -> count
-	var processedKeys []string
-
+	var keys []string
 	for paginator.HasMorePages() {
 		page, err := paginator.NextPage(ctx)
 		if err != nil {
@@ -65,48 +75,70 @@ func main() {
 			if obj.LastModified != nil && obj.LastModified.Format(time.RFC3339) <= lastProcessed {
 				continue
 			}
-
-			counts, err := processLogFile(ctx, s3Client, logBucket, *obj.Key)
-			if err != nil {
-				log.Printf("process %s: %v", *obj.Key, err)
-				continue
-			}
-
-			for id, count := range counts {
-				playCounts[id] += count
-			}
-			processedKeys = append(processedKeys, *obj.Key)
+			keys = append(keys, *obj.Key)
 		}
 	}
 
+	workers := defaultWorkers
+	if v, err := strconv.Atoi(os.Getenv("PLAY_COUNTER_WORKERS")); err == nil && v > 0 {
+		workers = v
+	}
+	playCounts, processedKeys := countPlays(ctx, s3Client, logBucket, keys, workers)
+
 	if len(playCounts) == 0 {
 		log.Println("No new play counts to update")
 		return
 	}
 
-	// Batch increment play counts in DynamoDB
-	for podcastID, count := range playCounts {
-		_, err := ddbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
-			TableName: &tableName,
-			Key: map[string]types.AttributeValue{
-				"PK": &types.AttributeValueMemberS{Value: "PODCAST#" + podcastID},
-				"SK": &types.AttributeValueMemberS{Value: "METADATA"},
-			},
-			UpdateExpression: aws.String("ADD playCount :n"),
-			ExpressionAttributeValues: map[string]types.AttributeValue{
-				":n": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", count)},
-			},
-		})
-		if err != nil {
-			log.Printf("update play count for %s: %v", podcastID, err)
-			continue
-		}
-		log.Printf("Updated %s: +%d plays", podcastID, count)
+	// Counts are already summed across files, so each podcast gets one
+	// increment, written in transactions of up to transactMax.
+	updated, missing, err := writePlayCounts(ctx, ddbClient, tableName, playCounts)
+	if err != nil {
+		log.Printf("write play counts: %v", err)
+	}
+	if len(missing) > 0 {
+		log.Printf("Skipped %d deleted podcasts: %s", len(missing), strings.Join(missing, ", "))
 	}
 
 	// Update last processed timestamp
 	setLastProcessed(ctx, ddbClient, tableName, time.Now().UTC().Format(time.RFC3339))
-	log.Printf("Processed %d log files, updated %d podcasts", len(processedKeys), len(playCounts))
+	log.Printf("Processed %d log files, updated %d podcasts", len(processedKeys), updated)
+}
+
+// countPlays processes the log files with a pool of workers and sums their
+// per-podcast counts. Files that fail are logged and left out of processed.
+func countPlays(ctx context.Context, client *s3.Client, bucket string, keys []string, workers int) (counts map[string]int, processed []string) {
+	jobs := make(chan string)
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	counts = make(map[string]int) // podcastID -> count
+	for range min(workers, len(keys)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				fileCounts, err := processLogFile(ctx, client, bucket, key)
+				if err != nil {
+					log.Printf("process %s: %v", key, err)
+					continue
+				}
+				mu.Lock()
+				for id, n := range fileCounts {
+					counts[id] += n
+				}
+				processed = append(processed, key)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+	return counts, processed
 }
 
 func processLogFile(ctx context.Context, client *s3.Client, bucket, key string) (map[string]int, error) {
@@ -119,49 +151,198 @@ func processLogFile(ctx context.Context, client *s3.Client, bucket, key string)
 	}
 	defer result.Body.Close()
 
-	var scanner *bufio.Scanner
+	var body io.Reader = result.Body
 	if strings.HasSuffix(key, ".gz") {
 		gz, err := gzip.NewReader(result.Body)
 		if err != nil {
 			return nil, fmt.Errorf("gzip reader: %w", err)
 		}
 		defer gz.Close()
-		scanner = bufio.NewScanner(gz)
-	} else {
-		scanner = bufio.NewScanner(result.Body)
+		body = gz
 	}
 
-	counts := make(map[string]int)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "#") {
-			continue
-		}
+	counts, skipped, err := countAudioRequests(body)
+	if skipped > 0 {
+		log.Printf("%s: skipped %d lines longer than %d bytes", key, skipped, maxLineBytes)
+	}
+	return counts, err
+}
 
-		// CloudFront log fields: date time x-edge-location sc-bytes c-ip cs-method cs-uri-stem sc-status ...
-		fields := strings.Fields(line)
-		if len(fields) < 8 {
+// countAudioRequests counts successful GET /audio/{ULID}.{ext} requests per
+// podcast ID in a CloudFront access log. Lines are read in place from the
+// reader's buffer, so only new podcast IDs allocate. Lines longer than
+// maxLineBytes (huge query strings or user agents) can't be audio plays
+// worth counting and are skipped rather than failing the file, which is
+// what bufio.Scanner did.
+func countAudioRequests(r io.Reader) (counts map[string]int, skipped int, err error) {
+	br := bufio.NewReaderSize(r, maxLineBytes)
+	counts = make(map[string]int)
+	for {
+		line, err := br.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			skipped++
+			for err == bufio.ErrBufferFull {
+				_, err = br.ReadSlice('\n')
+			}
+			if err == io.EOF {
+				return counts, skipped, nil
+			}
+			if err != nil {
+				return counts, skipped, err
+			}
 			continue
 		}
-
-		status := fields[7]
-		if status != "200" && status != "206" {
-			continue
+		if len(line) > 0 && line[0] != '#' {
+			if id, ok := audioPlayID(line); ok {
+				counts[string(id)]++
+			}
 		}
-
-		method := fields[5]
-		path := fields[6]
-		if method != "GET" {
-			continue
+		if err == io.EOF {
+			return counts, skipped, nil
+		}
+		if err != nil {
+			return counts, skipped, err
 		}
+	}
+}
+
+// audioExts are the extensions episodes are uploaded with, one per output
+// format (assembly.Format.Ext).
+var audioExts = map[string]bool{"mp3": true, "m4a": true, "opus": true, "wav": true}
 
-		matches := audioPathRegex.FindStringSubmatch("GET " + path)
-		if len(matches) >= 2 {
-			counts[matches[1]]++
+// audioPlayID returns the podcast ID if line is a 200/206 GET of
+// /audio/{ULID}.{ext}. CloudFront log fields: date time x-edge-location
+// sc-bytes c-ip cs-method cs-uri-stem sc-status ...
+func audioPlayID(line []byte) ([]byte, bool) {
+	var method, path, status []byte
+	for i := 0; i <= 7; i++ {
+		var field []byte
+		field, line = nextField(line)
+		if field == nil {
+			return nil, false
+		}
+		switch i {
+		case 5:
+			method = field
+		case 6:
+			path = field
+		case 7:
+			status = field
+		}
+	}
+	if string(method) != "GET" || (string(status) != "200" && string(status) != "206") {
+		return nil, false
+	}
+	id, ok := bytes.CutPrefix(path, []byte("/audio/"))
+	if !ok {
+		return nil, false
+	}
+	id, ext, ok := bytes.Cut(id, []byte("."))
+	if !ok || len(id) != 26 || !audioExts[string(ext)] {
+		return nil, false
+	}
+	for _, c := range id {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return nil, false
 		}
 	}
+	return id, true
+}
+
+// nextField splits the first whitespace-separated field off line. It
+// returns a nil field when none is left.
+func nextField(line []byte) (field, rest []byte) {
+	start := 0
+	for start < len(line) && isLogSpace(line[start]) {
+		start++
+	}
+	if start == len(line) {
+		return nil, nil
+	}
+	end := start
+	for end < len(line) && !isLogSpace(line[end]) {
+		end++
+	}
+	return line[start:end], line[end:]
+}
+
+func isLogSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// writePlayCounts adds each podcast's count to its playCount with
+// TransactWriteItems, transactMax updates per call instead of one UpdateItem
+// each. Each update requires the podcast to exist, so plays of a deleted
+// podcast don't recreate a stub record; those IDs are returned as missing.
+// A cancelled transaction is retried without them (and after conflicts or
+// throttling) with a short backoff. The SDK's idempotency token makes its
+// own retries of one call safe. Returns the number of podcasts updated.
+func writePlayCounts(ctx context.Context, client *dynamodb.Client, tableName string, counts map[string]int) (updated int, missing []string, err error) {
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var errs []error
+	for start := 0; start < len(ids); start += transactMax {
+		pending := ids[start:min(start+transactMax, len(ids))]
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt == 5 {
+				errs = append(errs, fmt.Errorf("%d updates not applied after retries", len(pending)))
+				break
+			}
+			if attempt > 0 {
+				select {
+				case <-ctx.Done():
+					return updated, missing, ctx.Err()
+				case <-time.After(time.Duration(attempt*200) * time.Millisecond):
+				}
+			}
+
+			items := make([]types.TransactWriteItem, len(pending))
+			for i, id := range pending {
+				items[i] = types.TransactWriteItem{Update: &types.Update{
+					TableName: &tableName,
+					Key: map[string]types.AttributeValue{
+						"PK": &types.AttributeValueMemberS{Value: "PODCAST#" + id},
+						"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+					},
+					UpdateExpression:    aws.String("ADD playCount :n"),
+					ConditionExpression: aws.String("attribute_exists(PK)"),
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":n": &types.AttributeValueMemberN{Value: strconv.Itoa(counts[id])},
+					},
+				}}
+			}
+			_, err := client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+			if err == nil {
+				for _, id := range pending {
+					log.Printf("Updated %s: +%d plays", id, counts[id])
+				}
+				updated += len(pending)
+				break
+			}
 
-	return counts, scanner.Err()
+			var canceled *types.TransactionCanceledException
+			if !errors.As(err, &canceled) {
+				errs = append(errs, fmt.Errorf("%d updates: %w", len(pending), err))
+				break
+			}
+			// Reasons line up with the items; drop the podcasts that no
+			// longer exist and retry the rest.
+			var retry []string
+			for i, id := range pending {
+				if i < len(canceled.CancellationReasons) && aws.ToString(canceled.CancellationReasons[i].Code) == "ConditionalCheckFailed" {
+					missing = append(missing, id)
+					continue
+				}
+				retry = append(retry, id)
+			}
+			pending = retry
+		}
+	}
+	return updated, missing, errors.Join(errs...)
 }
 
 func getLastProcessed(ctx context.Context, client *dynamodb.Client, tableName string) string {
@@ -0,0 +1,116 @@
+//go:build lambda.norpc
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// keyUsage is one API key's usage for one UTC day, written by the MCP
+// server (mcpserver.KeyUsageRecord). PK=APIKEY#{prefix}, SK=USAGE#{YYYY-MM-DD}.
+type keyUsage struct {
+	PK        string  `dynamodbav:"PK"`
+	SK        string  `dynamodbav:"SK"`
+	UserID    string  `dynamodbav:"userId"`
+	Requests  int     `dynamodbav:"requests"`
+	CostUSD   float64 `dynamodbav:"costUSD"`
+	FlaggedAt string  `dynamodbav:"flaggedAt,omitempty"`
+}
+
+func (r keyUsage) prefix() string { return r.PK[len("APIKEY#"):] }
+func (r keyUsage) day() string    { return r.SK[len("USAGE#"):] }
+
+// thresholds tune detect.
+type thresholds struct {
+	BaselineDays int     // trailing days averaged for the baseline (today excluded)
+	Multiplier   float64 // flag when today exceeds Multiplier × baseline...
+	MinRequests  int     // ...and at least this many requests,
+	MinCostUSD   float64 // ...or at least this much estimated cost
+}
+
+// defaultThresholds flag a key at 5× its two-week average once it has made
+// at least 20 requests or spent $5 today.
+var defaultThresholds = thresholds{
+	BaselineDays: 14,
+	Multiplier:   5,
+	MinRequests:  20,
+	MinCostUSD:   5,
+}
+
+// anomaly describes a key whose usage today is far above its baseline.
+type anomaly struct {
+	KeyPrefix        string  `json:"key_prefix"`
+	UserID           string  `json:"user_id"`
+	Day              string  `json:"day"`
+	Requests         int     `json:"requests"`
+	BaselineRequests float64 `json:"baseline_requests"`
+	CostUSD          float64 `json:"cost_usd"`
+	BaselineCostUSD  float64 `json:"baseline_cost_usd"`
+	Reason           string  `json:"reason"`
+	flagged          bool    // already reported today
+}
+
+// detect compares each key's usage on today (YYYY-MM-DD) with its daily
+// average over the preceding t.BaselineDays. Days without a record count as
+// zero, so a brand-new key is judged against the minimums alone. Results are
+// sorted by key prefix.
+func detect(records []keyUsage, today string, t thresholds) ([]anomaly, error) {
+	day, err := time.Parse("2006-01-02", today)
+	if err != nil {
+		return nil, fmt.Errorf("parse day: %w", err)
+	}
+	if t.BaselineDays < 1 {
+		t.BaselineDays = 1
+	}
+	from := day.AddDate(0, 0, -t.BaselineDays).Format("2006-01-02")
+
+	type totals struct {
+		today          *keyUsage
+		requests, cost float64
+	}
+	byKey := make(map[string]*totals)
+	for i, r := range records {
+		k := byKey[r.prefix()]
+		if k == nil {
+			k = &totals{}
+			byKey[r.prefix()] = k
+		}
+		switch d := r.day(); {
+		case d == today:
+			k.today = &records[i]
+		case d >= from && d < today:
+			k.requests += float64(r.Requests)
+			k.cost += r.CostUSD
+		}
+	}
+
+	var out []anomaly
+	for prefix, k := range byKey {
+		if k.today == nil {
+			continue
+		}
+		a := anomaly{
+			KeyPrefix:        prefix,
+			UserID:           k.today.UserID,
+			Day:              today,
+			Requests:         k.today.Requests,
+			BaselineRequests: k.requests / float64(t.BaselineDays),
+			CostUSD:          k.today.CostUSD,
+			BaselineCostUSD:  k.cost / float64(t.BaselineDays),
+			flagged:          k.today.FlaggedAt != "",
+		}
+		switch {
+		case a.Requests >= t.MinRequests && float64(a.Requests) > t.Multiplier*a.BaselineRequests:
+			a.Reason = fmt.Sprintf("%d requests today vs %.1f/day baseline", a.Requests, a.BaselineRequests)
+		case a.CostUSD >= t.MinCostUSD && a.CostUSD > t.Multiplier*a.BaselineCostUSD:
+			a.Reason = fmt.Sprintf("$%.2f estimated cost today vs $%.2f/day baseline", a.CostUSD, a.BaselineCostUSD)
+		default:
+			continue
+		}
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].KeyPrefix < out[j].KeyPrefix })
+	return out, nil
+}
@@ -0,0 +1,296 @@
+//go:build lambda.norpc
+
+// Command usage-monitor is a scheduled Lambda that flags API keys whose usage
+// today is far above their trailing baseline, then alerts and optionally
+// suspends them. The MCP server writes the per-key daily counters it reads
+// (mcpserver.RecordKeyRequest / RecordKeyCost).
+//
+// Environment:
+//
+//	DYNAMODB_TABLE         table name (required)
+//	ANOMALY_ACTION         "alert" (default) or "suspend"
+//	ALERT_TOPIC_ARN        SNS topic for alerts (optional; logs only without it)
+//	ANOMALY_BASELINE_DAYS  trailing days in the baseline (default 14)
+//	ANOMALY_MULTIPLIER     flag at this multiple of the baseline (default 5)
+//	ANOMALY_MIN_REQUESTS   ignore keys below this many requests today (default 20)
+//	ANOMALY_MIN_COST_USD   ...unless they've spent at least this much (default 5)
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+var (
+	ddbClient *dynamodb.Client
+	snsClient *sns.Client
+	tableName string
+	topicARN  string
+	suspend   bool
+	limits    thresholds
+	log       *slog.Logger
+)
+
+func init() {
+	log = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	tableName = os.Getenv("DYNAMODB_TABLE")
+	if tableName == "" {
+		log.Error("DYNAMODB_TABLE environment variable is required")
+		os.Exit(1)
+	}
+	topicARN = os.Getenv("ALERT_TOPIC_ARN")
+
+	switch action := os.Getenv("ANOMALY_ACTION"); action {
+	case "", "alert":
+	case "suspend":
+		suspend = true
+	default:
+		log.Error("ANOMALY_ACTION must be alert or suspend", "value", action)
+		os.Exit(1)
+	}
+
+	limits = defaultThresholds
+	envInt("ANOMALY_BASELINE_DAYS", &limits.BaselineDays)
+	envFloat("ANOMALY_MULTIPLIER", &limits.Multiplier)
+	envInt("ANOMALY_MIN_REQUESTS", &limits.MinRequests)
+	envFloat("ANOMALY_MIN_COST_USD", &limits.MinCostUSD)
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Error("Failed to load AWS config", "error", err)
+		os.Exit(1)
+	}
+	ddbClient = dynamodb.NewFromConfig(cfg)
+	snsClient = sns.NewFromConfig(cfg)
+}
+
+func envInt(name string, dst *int) {
+	if v := os.Getenv(name); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			log.Warn("Ignoring invalid setting", "name", name, "value", v)
+			return
+		}
+		*dst = n
+	}
+}
+
+func envFloat(name string, dst *float64) {
+	if v := os.Getenv(name); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 {
+			log.Warn("Ignoring invalid setting", "name", name, "value", v)
+			return
+		}
+		*dst = f
+	}
+}
+
+func main() {
+	lambda.Start(handler)
+}
+
+func handler(ctx context.Context) error {
+	now := time.Now().UTC()
+	today := now.Format("2006-01-02")
+	since := now.AddDate(0, 0, -limits.BaselineDays).Format("2006-01-02")
+
+	records, err := listKeyUsage(ctx, since)
+	if err != nil {
+		return err
+	}
+	anomalies, err := detect(records, today, limits)
+	if err != nil {
+		return err
+	}
+
+	reported := 0
+	for _, a := range anomalies {
+		if a.flagged {
+			continue
+		}
+		// Flag first: if two invocations overlap, only one acts.
+		first, err := flagKeyUsage(ctx, a.KeyPrefix, a.Day)
+		if err != nil {
+			log.Error("Failed to flag key usage", "key_prefix", a.KeyPrefix, "error", err)
+			continue
+		}
+		if !first {
+			continue
+		}
+		reported++
+
+		action := "alerted"
+		if suspend {
+			action = suspendKey(ctx, a)
+		}
+		log.Warn("Usage anomaly",
+			"key_prefix", a.KeyPrefix,
+			"user_id", a.UserID,
+			"requests", a.Requests,
+			"baseline_requests", a.BaselineRequests,
+			"cost_usd", a.CostUSD,
+			"baseline_cost_usd", a.BaselineCostUSD,
+			"reason", a.Reason,
+			"action", action,
+		)
+		publishAlert(ctx, a, action)
+	}
+
+	log.Info("Usage check complete", "records", len(records), "anomalies", len(anomalies), "reported", reported)
+	return nil
+}
+
+// suspendKey suspends the anomaly's key unless it belongs to an admin, whose
+// keys are alert-only so the monitor can't lock operators out. Returns the
+// action taken, for the log and alert.
+func suspendKey(ctx context.Context, a anomaly) string {
+	role, err := userRole(ctx, a.UserID)
+	if err != nil {
+		log.Error("Failed to look up key owner; not suspending", "key_prefix", a.KeyPrefix, "error", err)
+		return "alerted (owner lookup failed)"
+	}
+	if role == "admin" {
+		return "alerted (admin key)"
+	}
+
+	_, err = ddbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "APIKEY#" + a.KeyPrefix},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		UpdateExpression:    aws.String("SET #status = :suspended, suspendedAt = :now, suspendedReason = :reason"),
+		ConditionExpression: aws.String("#status = :active"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":suspended": &types.AttributeValueMemberS{Value: "suspended"},
+			":active":    &types.AttributeValueMemberS{Value: "active"},
+			":now":       &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+			":reason":    &types.AttributeValueMemberS{Value: "usage anomaly: " + a.Reason},
+		},
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return "alerted (key not active)"
+		}
+		log.Error("Failed to suspend key", "key_prefix", a.KeyPrefix, "error", err)
+		return "alerted (suspend failed)"
+	}
+	return "suspended"
+}
+
+func listKeyUsage(ctx context.Context, since string) ([]keyUsage, error) {
+	var records []keyUsage
+	p := dynamodb.NewScanPaginator(ddbClient, &dynamodb.ScanInput{
+		TableName:        &tableName,
+		FilterExpression: aws.String("begins_with(PK, :pk) AND begins_with(SK, :sk) AND SK >= :since"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":    &types.AttributeValueMemberS{Value: "APIKEY#"},
+			":sk":    &types.AttributeValueMemberS{Value: "USAGE#"},
+			":since": &types.AttributeValueMemberS{Value: "USAGE#" + since},
+		},
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("scan key usage: %w", err)
+		}
+		var batch []keyUsage
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			return nil, fmt.Errorf("unmarshal key usage: %w", err)
+		}
+		records = append(records, batch...)
+	}
+	return records, nil
+}
+
+// flagKeyUsage marks a key's day as reported. Returns false if it was
+// already flagged.
+func flagKeyUsage(ctx context.Context, prefix, day string) (bool, error) {
+	_, err := ddbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "APIKEY#" + prefix},
+			"SK": &types.AttributeValueMemberS{Value: "USAGE#" + day},
+		},
+		UpdateExpression:    aws.String("SET flaggedAt = :now"),
+		ConditionExpression: aws.String("attribute_exists(PK) AND attribute_not_exists(flaggedAt)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return false, nil
+		}
+		return false, fmt.Errorf("flag key usage: %w", err)
+	}
+	return true, nil
+}
+
+func userRole(ctx context.Context, userID string) (string, error) {
+	result, err := ddbClient.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "USER#" + userID},
+			"SK": &types.AttributeValueMemberS{Value: "PROFILE"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("get user: %w", err)
+	}
+	if result.Item == nil {
+		return "", nil
+	}
+	var user struct {
+		Role string `dynamodbav:"role"`
+	}
+	if err := attributevalue.UnmarshalMap(result.Item, &user); err != nil {
+		return "", fmt.Errorf("unmarshal user: %w", err)
+	}
+	return user.Role, nil
+}
+
+// publishAlert sends the anomaly to ALERT_TOPIC_ARN. Best-effort: the log
+// line above is the record of truth.
+func publishAlert(ctx context.Context, a anomaly, action string) {
+	if topicARN == "" {
+		return
+	}
+	body, err := json.MarshalIndent(struct {
+		anomaly
+		Action string `json:"action"`
+	}{a, action}, "", "  ")
+	if err != nil {
+		log.Error("Failed to marshal alert", "error", err)
+		return
+	}
+	_, err = snsClient.Publish(ctx, &sns.PublishInput{
+		TopicArn: &topicARN,
+		Subject:  aws.String(fmt.Sprintf("Podcaster usage anomaly: key %s %s", a.KeyPrefix, action)),
+		Message:  aws.String(string(body)),
+	})
+	if err != nil {
+		log.Error("Failed to publish alert", "key_prefix", a.KeyPrefix, "error", err)
+	}
+}
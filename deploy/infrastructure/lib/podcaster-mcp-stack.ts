@@ -14,6 +14,7 @@ import * as route53Targets from 'aws-cdk-lib/aws-route53-targets';
 import * as s3 from 'aws-cdk-lib/aws-s3';
 import * as s3deploy from 'aws-cdk-lib/aws-s3-deployment';
 import * as secretsmanager from 'aws-cdk-lib/aws-secretsmanager';
+import * as sns from 'aws-cdk-lib/aws-sns';
 import { Construct } from 'constructs';
 
 interface PodcasterMcpStackProps extends cdk.StackProps {
@@ -43,6 +44,31 @@ export class PodcasterMcpStack extends cdk.Stack {
       bucketName: `podcaster-audio-${cdk.Aws.ACCOUNT_ID}`,
       blockPublicAccess: s3.BlockPublicAccess.BLOCK_ALL,
       removalPolicy: cdk.RemovalPolicy.RETAIN,
+      // Account exports hold personal data; keep them only long enough to download.
+      lifecycleRules: [{
+        prefix: 'exports/',
+        expiration: cdk.Duration.days(7),
+      }, {
+        // Deleted podcasts' files; one day past the 30-day restore window.
+        prefix: 'trash/',
+        expiration: cdk.Duration.days(31),
+      }, {
+        // Failed jobs' finished segments, kept for generate_podcast's resume_from.
+        prefix: 'partial/',
+        expiration: cdk.Duration.days(7),
+      }, {
+        // Failed jobs' debug bundles, for `podcaster debug replay`.
+        prefix: 'debug/',
+        expiration: cdk.Duration.days(14),
+      }, {
+        // Episode audio tagged at upload; restore_podcast retrieves it.
+        // Must match the server's MCP_ARCHIVE_AFTER_DAYS (Makefile ARCHIVE_AFTER_DAYS).
+        tagFilters: { archive: 'true' },
+        transitions: [{
+          storageClass: s3.StorageClass.GLACIER,
+          transitionAfter: cdk.Duration.days(90),
+        }],
+      }],
       cors: [{
         allowedMethods: [s3.HttpMethods.PUT],
         allowedOrigins: [`https://${domainName}`, 'http://localhost:3000'],
@@ -58,6 +84,7 @@ export class PodcasterMcpStack extends cdk.Stack {
       sortKey: { name: 'SK', type: dynamodb.AttributeType.STRING },
       billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
       removalPolicy: cdk.RemovalPolicy.RETAIN,
+      timeToLiveAttribute: 'ttl', // expires SESSION# records (MCP_SESSION_STORE=dynamodb)
     });
     table.addGlobalSecondaryIndex({
       indexName: 'GSI1',
@@ -242,6 +269,8 @@ export class PodcasterMcpStack extends cdk.Stack {
       environment: {
         DYNAMODB_TABLE: table.tableName,
         RUNTIME_ARN: 'arn:aws:bedrock-agentcore:us-east-1:228029809749:runtime/podcaster_mcp-t01dg1G007',
+        // Browser clients (portal, web-based MCP clients) call /mcp directly
+        CORS_ALLOWED_ORIGINS: `https://${domainName},http://localhost:3000`,
       },
     });
 
@@ -273,6 +302,11 @@ export class PodcasterMcpStack extends cdk.Stack {
       protocolPolicy: cloudfront.OriginProtocolPolicy.HTTPS_ONLY,
     });
 
+    const mcpProxyOrigin = new origins.HttpOrigin(
+      cdk.Fn.select(2, cdk.Fn.split('/', mcpProxyFnUrl.url)),
+      { protocolPolicy: cloudfront.OriginProtocolPolicy.HTTPS_ONLY }
+    );
+
     const s3AudioOrigin = origins.S3BucketOrigin.withOriginAccessControl(audioBucket);
     const s3StaticOrigin = origins.S3BucketOrigin.withOriginAccessControl(staticAssetsBucket);
 
@@ -318,11 +352,30 @@ export class PodcasterMcpStack extends cdk.Stack {
           cachedMethods: cloudfront.CachedMethods.CACHE_GET_HEAD_OPTIONS,
           cachePolicy: audioCachePolicy,
         },
+        '/transcripts/*': {
+          origin: s3AudioOrigin,
+          viewerProtocolPolicy: cloudfront.ViewerProtocolPolicy.REDIRECT_TO_HTTPS,
+          allowedMethods: cloudfront.AllowedMethods.ALLOW_GET_HEAD_OPTIONS,
+          cachedMethods: cloudfront.CachedMethods.CACHE_GET_HEAD_OPTIONS,
+          cachePolicy: audioCachePolicy,
+        },
+        '/samples/*': {
+          origin: s3AudioOrigin,
+          viewerProtocolPolicy: cloudfront.ViewerProtocolPolicy.REDIRECT_TO_HTTPS,
+          allowedMethods: cloudfront.AllowedMethods.ALLOW_GET_HEAD_OPTIONS,
+          cachedMethods: cloudfront.CachedMethods.CACHE_GET_HEAD_OPTIONS,
+          cachePolicy: audioCachePolicy,
+        },
         '/mcp': {
-          origin: new origins.HttpOrigin(
-            cdk.Fn.select(2, cdk.Fn.split('/', mcpProxyFnUrl.url)),
-            { protocolPolicy: cloudfront.OriginProtocolPolicy.HTTPS_ONLY }
-          ),
+          origin: mcpProxyOrigin,
+          viewerProtocolPolicy: cloudfront.ViewerProtocolPolicy.REDIRECT_TO_HTTPS,
+          allowedMethods: cloudfront.AllowedMethods.ALLOW_ALL,
+          cachePolicy: cloudfront.CachePolicy.CACHING_DISABLED,
+          originRequestPolicy: cloudfront.OriginRequestPolicy.ALL_VIEWER_EXCEPT_HOST_HEADER,
+        },
+        // Inbound webhook for no-code automations (cmd/mcp-proxy/webhook.go)
+        '/webhook': {
+          origin: mcpProxyOrigin,
           viewerProtocolPolicy: cloudfront.ViewerProtocolPolicy.REDIRECT_TO_HTTPS,
           allowedMethods: cloudfront.AllowedMethods.ALLOW_ALL,
           cachePolicy: cloudfront.CachePolicy.CACHING_DISABLED,
@@ -369,6 +422,12 @@ export class PodcasterMcpStack extends cdk.Stack {
     // S3 read/write to audio bucket
     audioBucket.grantReadWrite(agentCoreRole);
 
+    // Retrieve archived episode audio (restore_podcast)
+    agentCoreRole.addToPolicy(new iam.PolicyStatement({
+      actions: ['s3:RestoreObject'],
+      resources: [audioBucket.arnForObjects('audio/*')],
+    }));
+
     // Secrets Manager read (MCP server secrets)
     agentCoreRole.addToPolicy(new iam.PolicyStatement({
       actions: ['secretsmanager:GetSecretValue'],
@@ -445,6 +504,39 @@ export class PodcasterMcpStack extends cdk.Stack {
       targets: [new targets.LambdaFunction(playCounterFn)],
     });
 
+    // --- Usage Monitor Lambda ---
+    // Pre-built binary: run `make build-usage-monitor` before `make deploy-infra`.
+    // Flags API keys whose usage today is far above their 14-day baseline.
+    // Starts in alert-only mode; set ANOMALY_ACTION=suspend to auto-suspend.
+    const usageAlertTopic = new sns.Topic(this, 'UsageAlertTopic', {
+      topicName: 'podcaster-usage-alerts',
+    });
+
+    const usageMonitorFn = new lambda.Function(this, 'UsageMonitorFn', {
+      functionName: 'podcaster-usage-monitor',
+      runtime: lambda.Runtime.PROVIDED_AL2023,
+      architecture: lambda.Architecture.ARM_64,
+      handler: 'bootstrap',
+      code: lambda.Code.fromAsset('../../deploy/usage-monitor-build'),
+      timeout: cdk.Duration.minutes(5),
+      memorySize: 256,
+      environment: {
+        DYNAMODB_TABLE: table.tableName,
+        ALERT_TOPIC_ARN: usageAlertTopic.topicArn,
+        ANOMALY_ACTION: 'alert',
+      },
+    });
+
+    table.grantReadWriteData(usageMonitorFn);
+    usageAlertTopic.grantPublish(usageMonitorFn);
+
+    // EventBridge schedule: hourly, so runaway keys are caught the same day
+    new events.Rule(this, 'UsageMonitorSchedule', {
+      ruleName: 'podcaster-usage-monitor-schedule',
+      schedule: events.Schedule.rate(cdk.Duration.hours(1)),
+      targets: [new targets.LambdaFunction(usageMonitorFn)],
+    });
+
     // --- Outputs ---
     new cdk.CfnOutput(this, 'EcrRepoUri', {
       value: ecrRepo.repositoryUri,
@@ -0,0 +1,63 @@
+package assembly
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Effects are speed and pitch changes applied with FFmpeg filters while
+// converting audio, for TTS providers that can't apply them natively.
+type Effects struct {
+	Speed float64 // tempo multiplier, pitch preserved (0 or 1 = unchanged)
+	Pitch float64 // semitones, duration preserved (0 = unchanged)
+}
+
+// IsZero reports whether the effects leave the audio unchanged.
+func (e Effects) IsZero() bool {
+	return (e.Speed == 0 || e.Speed == 1) && e.Pitch == 0
+}
+
+// filter returns the -af chain for the effects. The audio is first
+// resampled to AudioSampleRate so the pitch shift has a known base rate.
+// Pitch uses asetrate, which shifts pitch and tempo together, then atempo
+// undoes the tempo change; the requested speed folds into the same atempo.
+func (e Effects) filter() string {
+	if e.IsZero() {
+		return AudioResampler
+	}
+	rate, _ := strconv.Atoi(AudioSampleRate)
+	chain := []string{AudioResampler + "=" + AudioSampleRate}
+
+	tempo := 1.0
+	if e.Speed > 0 {
+		tempo = e.Speed
+	}
+	if e.Pitch != 0 {
+		factor := math.Pow(2, e.Pitch/12)
+		chain = append(chain,
+			fmt.Sprintf("asetrate=%d", int(math.Round(float64(rate)*factor))),
+			AudioResampler+"="+AudioSampleRate)
+		tempo /= factor
+	}
+	return strings.Join(append(chain, atempoChain(tempo)...), ",")
+}
+
+// atempoChain splits a tempo factor into atempo filters within 0.5-2.0,
+// the range every FFmpeg version accepts.
+func atempoChain(tempo float64) []string {
+	var chain []string
+	for tempo < 0.5 {
+		chain = append(chain, "atempo=0.5")
+		tempo /= 0.5
+	}
+	for tempo > 2 {
+		chain = append(chain, "atempo=2.0")
+		tempo /= 2
+	}
+	if math.Abs(tempo-1) > 1e-6 {
+		chain = append(chain, "atempo="+strconv.FormatFloat(tempo, 'f', 6, 64))
+	}
+	return chain
+}
@@ -0,0 +1,106 @@
+package assembly
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/apresai/podcaster/internal/observability/logctx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("podcaster-assembly")
+
+// Limits on a single FFmpeg or ffprobe run. A process still running at its
+// limit is killed, so a hung FFmpeg fails the job instead of blocking a
+// hosted worker indefinitely.
+const (
+	probeTimeout   = 30 * time.Second
+	segmentTimeout = 2 * time.Minute  // one segment's conversion or normalization
+	episodeTimeout = 15 * time.Minute // whole-episode concat or analysis
+
+	// waitDelay bounds how long Wait blocks on output pipes after the
+	// process is killed (a child process may still hold them open).
+	waitDelay = 5 * time.Second
+
+	// maxErrStderr is how much of a failed run's stderr (the end, where
+	// FFmpeg reports the failure) is included in the error.
+	maxErrStderr = 4 << 10
+)
+
+// runTool runs ffmpeg or ffprobe with args under a span and a timeout, and
+// returns its stdout and stderr. op names the operation ("concat",
+// "normalization", ...) in errors, logs, and the span. ffmpeg also gets
+// -nostdin, so it never waits on a terminal, plus -hide_banner and -nostats
+// to keep stderr to the diagnostics. A failure's error includes the tail of
+// stderr and is logged with the context's logger (see logctx).
+func runTool(ctx context.Context, tool, op string, timeout time.Duration, args ...string) (stdout, stderr []byte, err error) {
+	ctx, span := tracer.Start(ctx, "assembly."+tool, trace.WithAttributes(
+		attribute.String("tool", tool),
+		attribute.String("operation", op),
+		attribute.String("timeout", timeout.String()),
+	))
+	defer span.End()
+
+	if tool == "ffmpeg" {
+		args = append([]string{"-nostdin", "-hide_banner", "-nostats"}, args...)
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, tool, args...)
+	cmd.WaitDelay = waitDelay
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+
+	start := time.Now()
+	runErr := cmd.Run()
+	elapsed := time.Since(start)
+	span.SetAttributes(attribute.Int64("duration_ms", elapsed.Milliseconds()))
+
+	log := logctx.From(ctx)
+	if runErr == nil {
+		log.DebugContext(ctx, "FFmpeg run complete", "tool", tool, "op", op, "elapsed", elapsed.Round(time.Millisecond).String())
+		return out.Bytes(), errOut.Bytes(), nil
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		// The caller gave up (job cancelled or shutting down); not a tool failure.
+		err = fmt.Errorf("%s %s cancelled: %w", tool, op, ctx.Err())
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		err = fmt.Errorf("%s %s timed out after %s%s", tool, op, timeout, tail(errOut.Bytes()))
+	default:
+		err = fmt.Errorf("%s %s failed: %w%s", tool, op, runErr, tail(errOut.Bytes()))
+	}
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		span.SetAttributes(attribute.Int("exit_code", exitErr.ExitCode()))
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, op+" failed")
+	log.WarnContext(ctx, "FFmpeg run failed", "tool", tool, "op", op,
+		"elapsed", elapsed.Round(time.Millisecond).String(), "error", runErr)
+	return out.Bytes(), errOut.Bytes(), err
+}
+
+// tail formats the last maxErrStderr bytes of stderr for an error message,
+// on lines of their own ("" if there was no output).
+func tail(stderr []byte) string {
+	stderr = bytes.TrimSpace(stderr)
+	switch {
+	case len(stderr) == 0:
+		return ""
+	case len(stderr) > maxErrStderr:
+		return "\n..." + string(stderr[len(stderr)-maxErrStderr:])
+	default:
+		return "\n" + string(stderr)
+	}
+}
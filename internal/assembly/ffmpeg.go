@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // Audio quality constants for consistent output across all FFmpeg operations.
@@ -17,16 +20,28 @@ const (
 	AudioCodec      = "libmp3lame"
 	AudioQuality    = "0" // LAME quality (0 = best)
 	AudioResampler  = "aresample"
+
+	// Segments are normalized to this PCM format (at AudioSampleRate and
+	// AudioChannels) before concatenation; see normalizeSegments.
+	AudioSampleFormat = "s16"
+	AudioPCMCodec     = "pcm_s16le"
 )
 
 type Assembler interface {
 	Assemble(ctx context.Context, segments []string, tmpDir string, output string) error
 }
 
-type FFmpegAssembler struct{}
+type FFmpegAssembler struct {
+	enc           Encoding
+	pacing        Pacing
+	segmentSecs   []float64
+	segmentStarts []float64
+}
 
-func NewFFmpegAssembler() *FFmpegAssembler {
-	return &FFmpegAssembler{}
+// NewFFmpegAssembler creates an assembler that encodes episodes with enc
+// and joins segments with pacing.
+func NewFFmpegAssembler(enc Encoding, pacing Pacing) *FFmpegAssembler {
+	return &FFmpegAssembler{enc: enc, pacing: pacing}
 }
 
 func (a *FFmpegAssembler) Assemble(ctx context.Context, segments []string, tmpDir string, output string) error {
@@ -34,57 +49,131 @@ func (a *FFmpegAssembler) Assemble(ctx context.Context, segments []string, tmpDi
 		return fmt.Errorf("no audio segments to assemble")
 	}
 
-	// Generate silence file (200ms)
-	silencePath := filepath.Join(tmpDir, "silence.mp3")
-	if err := generateSilence(ctx, silencePath); err != nil {
-		return fmt.Errorf("generate silence: %w", err)
+	// Resample every segment to one PCM format, so mixed-provider episodes
+	// don't change rate or channel layout mid-stream.
+	normalized, secs, err := normalizeSegments(ctx, segments, tmpDir, a.pacing.Trim)
+	if err != nil {
+		return fmt.Errorf("normalize segments: %w", err)
+	}
+	joins := a.pacing.joins(secs)
+	a.segmentSecs, a.segmentStarts = secs, segmentStarts(secs, joins)
+	if slices.Contains(secs, 0) {
+		a.segmentSecs, a.segmentStarts = nil, nil
+	}
+
+	// Silences, beats, sound effects, and crossfaded runs between the
+	// segments
+	pieces, err := joinPieces(ctx, normalized, joins, a.pacing, tmpDir)
+	if err != nil {
+		return err
 	}
 
 	// Build concat list
 	listPath := filepath.Join(tmpDir, "concat.txt")
-	if err := buildConcatList(segments, silencePath, listPath); err != nil {
+	if err := buildConcatList(pieces, listPath); err != nil {
 		return fmt.Errorf("build concat list: %w", err)
 	}
 
 	// Run FFmpeg concat
-	if err := runFFmpegConcat(ctx, listPath, output); err != nil {
+	if err := runFFmpegConcat(ctx, listPath, output, a.enc); err != nil {
 		return fmt.Errorf("ffmpeg concat: %w", err)
 	}
 
 	return nil
 }
 
-func generateSilence(ctx context.Context, output string) error {
-	cmd := exec.CommandContext(ctx, "ffmpeg",
+// SegmentSeconds returns the duration of each segment in the episode from
+// the last Assemble, in order, not counting the pauses between them. It is
+// nil if any segment could not be measured.
+func (a *FFmpegAssembler) SegmentSeconds() []float64 {
+	return a.segmentSecs
+}
+
+// SegmentStarts returns when each segment starts in the episode from the
+// last Assemble, in seconds, after the gaps, beats, and crossfades before
+// it. It is nil when SegmentSeconds is.
+func (a *FFmpegAssembler) SegmentStarts() []float64 {
+	return a.segmentStarts
+}
+
+func generateSilence(ctx context.Context, output string, secs float64) error {
+	_, _, err := runTool(ctx, "ffmpeg", "silence generation", segmentTimeout,
 		"-f", "lavfi",
 		"-i", fmt.Sprintf("anullsrc=r=%s:cl=stereo", AudioSampleRate),
-		"-t", "0.2",
-		"-c:a", AudioCodec,
-		"-b:a", AudioBitrate,
+		"-t", strconv.FormatFloat(secs, 'f', -1, 64),
+		"-c:a", AudioPCMCodec,
 		"-y",
 		output,
 	)
-	var stderr strings.Builder
-	cmd.Stderr = &stderr
-	cmd.Stdout = nil
+	return err
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("ffmpeg silence generation failed: %w\n%s", err, stderr.String())
+// normalizeSegments decodes each segment to PCM WAV at AudioSampleRate,
+// AudioSampleFormat, and AudioChannels, in parallel, trimming the silence
+// at its ends with trim. Providers deliver
+// different rates (24 kHz Gemini PCM, 44.1 kHz ElevenLabs MP3, ...), and the
+// concat demuxer assumes every file matches the first, so unnormalized
+// mixes jump in quality or play at the wrong pitch. PCM intermediates also
+// mean the episode is MP3-encoded once, at concat. Returns the WAV paths
+// and their durations in segment order; a duration that could not be
+// probed is 0.
+func normalizeSegments(ctx context.Context, segments []string, tmpDir string, trim SilenceTrim) ([]string, []float64, error) {
+	out := make([]string, len(segments))
+	secs := make([]float64, len(segments))
+	errs := make([]error, len(segments))
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for i, seg := range segments {
+		out[i] = filepath.Join(tmpDir, fmt.Sprintf("normalized_%03d.wav", i))
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := normalizeSegment(ctx, seg, out[i], trim); err != nil {
+				errs[i] = fmt.Errorf("segment %d: %w", i+1, err)
+				return
+			}
+			// Only transcript timing depends on this, so a failed probe
+			// isn't an assembly error.
+			if d, err := ProbeSeconds(ctx, out[i]); err == nil {
+				secs[i] = d
+			}
+		}()
 	}
-	return nil
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return out, secs, nil
+}
+
+func normalizeSegment(ctx context.Context, input, output string, trim SilenceTrim) error {
+	filters := AudioResampler + "=" + AudioSampleRate
+	if f := trim.filter(); f != "" {
+		filters = f + "," + filters
+	}
+	_, _, err := runTool(ctx, "ffmpeg", "normalization", segmentTimeout,
+		"-i", input,
+		"-af", filters,
+		"-c:a", AudioPCMCodec,
+		"-sample_fmt", AudioSampleFormat,
+		"-ar", AudioSampleRate,
+		"-ac", AudioChannels,
+		"-y",
+		output,
+	)
+	return err
 }
 
-func buildConcatList(segments []string, silencePath string, listPath string) error {
+func buildConcatList(pieces []string, listPath string) error {
 	// Use basenames — all files are in the same directory as the concat list,
 	// and FFmpeg resolves relative paths relative to the concat file location.
-	silenceBase := filepath.Base(silencePath)
 	var lines []string
-	for i, seg := range segments {
-		lines = append(lines, fmt.Sprintf("file '%s'", filepath.Base(seg)))
-		// Add silence between segments (not after the last one)
-		if i < len(segments)-1 {
-			lines = append(lines, fmt.Sprintf("file '%s'", silenceBase))
-		}
+	for _, piece := range pieces {
+		lines = append(lines, fmt.Sprintf("file '%s'", filepath.Base(piece)))
 	}
 
 	content := strings.Join(lines, "\n") + "\n"
@@ -100,6 +189,15 @@ func buildConcatList(segments []string, silencePath string, listPath string) err
 //   - "lpcm": raw 24kHz 16-bit signed little-endian mono (same as pcm)
 //   - "wav":  standard WAV header (auto-detected by FFmpeg)
 func ConvertToMP3(ctx context.Context, input string, format string, output string) error {
+	return ConvertWithEffects(ctx, input, format, output, Effects{}, Encoding{})
+}
+
+// ConvertWithEffects is ConvertToMP3 with speed and pitch effects applied in
+// the same FFmpeg pass, encoding in output's format (FormatOf) with enc. It
+// also accepts "mp3" input, which is re-encoded, for MP3 providers whose
+// audio needs effects and for batch episodes written in another format or
+// encoding.
+func ConvertWithEffects(ctx context.Context, input string, format string, output string, fx Effects, enc Encoding) error {
 	var args []string
 	switch format {
 	case "pcm", "lpcm":
@@ -108,62 +206,33 @@ func ConvertToMP3(ctx context.Context, input string, format string, output strin
 			"-ar", "24000",
 			"-ac", "1",
 			"-i", input,
-			"-af", AudioResampler,
-			"-c:a", AudioCodec,
-			"-b:a", AudioBitrate,
-			"-q:a", AudioQuality,
-			"-ar", AudioSampleRate,
-			"-ac", AudioChannels,
-			"-y",
-			output,
-		}
-	case "wav":
-		args = []string{
-			"-i", input,
-			"-af", AudioResampler,
-			"-c:a", AudioCodec,
-			"-b:a", AudioBitrate,
-			"-q:a", AudioQuality,
-			"-ar", AudioSampleRate,
-			"-ac", AudioChannels,
-			"-y",
-			output,
 		}
+	case "wav", "mp3":
+		args = []string{"-i", input}
 	default:
 		return fmt.Errorf("unsupported audio format for conversion: %s", format)
 	}
+	to := FormatOf(output)
+	args = append(args, "-af", fx.filter())
+	args = append(args, to.encodeArgs(enc)...)
+	args = append(args, "-y", output)
 
-	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
-	var stderr strings.Builder
-	cmd.Stderr = &stderr
-	cmd.Stdout = nil
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("ffmpeg conversion (%s → mp3) failed: %w\n%s", format, err, stderr.String())
-	}
-	return nil
+	_, _, err := runTool(ctx, "ffmpeg", fmt.Sprintf("conversion (%s → %s)", format, to), segmentTimeout, args...)
+	return err
 }
 
-func runFFmpegConcat(ctx context.Context, listPath string, output string) error {
-	cmd := exec.CommandContext(ctx, "ffmpeg",
+func runFFmpegConcat(ctx context.Context, listPath string, output string, enc Encoding) error {
+	args := []string{
 		"-f", "concat",
 		"-safe", "0",
 		"-i", listPath,
 		"-af", AudioResampler,
-		"-c:a", AudioCodec,
-		"-b:a", AudioBitrate,
-		"-q:a", AudioQuality,
-		"-ar", AudioSampleRate,
-		"-ac", AudioChannels,
-		"-y",
-		output,
-	)
-	var stderr strings.Builder
-	cmd.Stderr = &stderr
-	cmd.Stdout = nil
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("ffmpeg concat failed: %w\n%s", err, stderr.String())
+	}
+	args = append(args, FormatOf(output).encodeArgs(enc)...)
+	args = append(args, "-y", output)
+	_, _, err := runTool(ctx, "ffmpeg", "concat", episodeTimeout, args...)
+	if err != nil {
+		return err
 	}
 
 	// Verify output exists and has non-zero size
@@ -0,0 +1,207 @@
+package assembly
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Format is an episode's output audio format. Each step that encodes the
+// episode (concat, music, stingers, loudnorm) picks its encoder from the
+// output file's extension (FormatOf), so the episode is always encoded in
+// its final format; per-segment intermediates stay MP3.
+type Format string
+
+const (
+	FormatMP3  Format = "mp3"
+	FormatAAC  Format = "aac"  // AAC-LC in an MP4 container (.m4a)
+	FormatOpus Format = "opus" // Opus in Ogg (.opus)
+	FormatWAV  Format = "wav"  // 16-bit PCM
+)
+
+// Opus encoder settings. Opus runs at 48 kHz only, and reaches MP3's
+// quality for speech at about half the bitrate.
+const (
+	OpusBitrate    = "96k"
+	OpusSampleRate = "48000"
+)
+
+// OutputFormats returns the accepted output format names.
+func OutputFormats() []string {
+	return []string{string(FormatMP3), string(FormatAAC), string(FormatOpus), string(FormatWAV)}
+}
+
+// ParseFormat returns the Format named s ("" is MP3; "m4a" is accepted for
+// AAC).
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "mp3":
+		return FormatMP3, nil
+	case "aac", "m4a":
+		return FormatAAC, nil
+	case "opus":
+		return FormatOpus, nil
+	case "wav":
+		return FormatWAV, nil
+	}
+	return "", fmt.Errorf("unknown output format %q: must be one of %s", s, strings.Join(OutputFormats(), ", "))
+}
+
+// FormatOf returns the format for path's extension, MP3 if it names none.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".m4a", ".aac", ".mp4":
+		return FormatAAC
+	case ".opus", ".ogg":
+		return FormatOpus
+	case ".wav":
+		return FormatWAV
+	}
+	return FormatMP3
+}
+
+// Ext returns the file extension episodes in f are written with.
+func (f Format) Ext() string {
+	switch f {
+	case FormatAAC:
+		return ".m4a"
+	case FormatOpus:
+		return ".opus"
+	case FormatWAV:
+		return ".wav"
+	}
+	return ".mp3"
+}
+
+// WithExt returns path with its extension replaced by f's.
+func (f Format) WithExt(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + f.Ext()
+}
+
+// ContentType returns the MIME type of files in f.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatAAC:
+		return "audio/mp4"
+	case FormatOpus:
+		return "audio/ogg"
+	case FormatWAV:
+		return "audio/wav"
+	}
+	return "audio/mpeg"
+}
+
+// SupportsCover reports whether f's container can carry embedded cover
+// art (MP3 and MP4 can; FFmpeg's Ogg and WAV muxers can't).
+func (f Format) SupportsCover() bool {
+	return f == FormatMP3 || f == FormatAAC
+}
+
+// SupportsChapters reports whether f's container can carry chapters.
+func (f Format) SupportsChapters() bool {
+	return f != FormatWAV
+}
+
+// Encoding overrides the episode's bitrate and channel count
+// (--bitrate, --channels), trading file size against quality: 64k mono is
+// plenty for voice-only distribution. Zero fields keep the format's
+// defaults (AudioBitrate or OpusBitrate, AudioChannels). Intermediate
+// files (segments, the normalized PCM) always use the defaults; only the
+// steps that encode the episode apply it.
+type Encoding struct {
+	Bitrate  string // FFmpeg bitrate, e.g. "64k"; ignored for WAV
+	Channels string // "1" or "2"
+}
+
+// Bitrate limits for --bitrate, in kbps.
+const (
+	minBitrateKbps = 32
+	maxBitrateKbps = 320
+)
+
+// IsZero reports whether e keeps every default.
+func (e Encoding) IsZero() bool {
+	return e.Bitrate == "" && e.Channels == ""
+}
+
+// ParseBitrate parses a bitrate in kbps ("64", "64k") into FFmpeg's form
+// ("64k"). "" stays "" (the format's default).
+func ParseBitrate(s string) (string, error) {
+	s = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "k")
+	if s == "" {
+		return "", nil
+	}
+	kbps, err := strconv.Atoi(s)
+	if err != nil || kbps < minBitrateKbps || kbps > maxBitrateKbps {
+		return "", fmt.Errorf("bitrate must be %d-%d kbps (e.g. 64k)", minBitrateKbps, maxBitrateKbps)
+	}
+	return strconv.Itoa(kbps) + "k", nil
+}
+
+// ParseChannels parses a channel count: 1 or "mono", 2 or "stereo". ""
+// stays "" (AudioChannels).
+func ParseChannels(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return "", nil
+	case "1", "mono":
+		return "1", nil
+	case "2", "stereo":
+		return "2", nil
+	}
+	return "", fmt.Errorf("channels must be 1 (mono) or 2 (stereo), got %q", s)
+}
+
+// ChannelCount returns the episode's channel count.
+func (e Encoding) ChannelCount() string {
+	if e.Channels != "" {
+		return e.Channels
+	}
+	return AudioChannels
+}
+
+// bitrate returns the bitrate to encode f at.
+func (e Encoding) bitrate(f Format) string {
+	switch {
+	case e.Bitrate != "":
+		return e.Bitrate
+	case f == FormatOpus:
+		return OpusBitrate
+	}
+	return AudioBitrate
+}
+
+// encodeArgs returns the FFmpeg output options that encode audio in f.
+func (f Format) encodeArgs(e Encoding) []string {
+	switch f {
+	case FormatAAC:
+		return []string{
+			"-c:a", "aac",
+			"-b:a", e.bitrate(f),
+			"-ar", AudioSampleRate,
+			"-ac", e.ChannelCount(),
+			"-movflags", "+faststart", // index first, so players can stream it
+		}
+	case FormatOpus:
+		return []string{
+			"-c:a", "libopus",
+			"-b:a", e.bitrate(f),
+			"-ar", OpusSampleRate,
+			"-ac", e.ChannelCount(),
+		}
+	case FormatWAV:
+		return []string{
+			"-c:a", AudioPCMCodec,
+			"-ar", AudioSampleRate,
+			"-ac", e.ChannelCount(),
+		}
+	}
+	args := []string{"-c:a", AudioCodec, "-b:a", e.bitrate(f)}
+	if e.Bitrate == "" {
+		// The default keeps the VBR quality setting; an explicit bitrate
+		// is encoded as asked, without -q:a overriding it.
+		args = append(args, "-q:a", AudioQuality)
+	}
+	return append(args, "-ar", AudioSampleRate, "-ac", e.ChannelCount())
+}
@@ -0,0 +1,51 @@
+package assembly
+
+import (
+	"context"
+	"fmt"
+)
+
+// Loudness targets for the finished episode (EBU R128 via FFmpeg's
+// loudnorm), per the common podcast recommendations: -16 LUFS for stereo,
+// -19 for mono, which are perceived as equally loud. Episodes mixing TTS
+// providers otherwise vary by several LU from one to the next.
+const (
+	StereoLoudnessTarget = -16.0
+	MonoLoudnessTarget   = -19.0
+
+	loudnessTruePeak = -1.5 // dBTP ceiling
+	loudnessRange    = 11.0 // LU; speech rarely exceeds it
+)
+
+// LoudnessTarget returns the integrated loudness target in LUFS for an
+// output with channels channels.
+func LoudnessTarget(channels string) float64 {
+	if channels == "1" {
+		return MonoLoudnessTarget
+	}
+	return StereoLoudnessTarget
+}
+
+// NormalizeLoudness writes output, encoded with enc: input normalized to
+// the loudness target for enc's channel count. It runs loudnorm twice,
+// measuring first so the second pass can apply a single linear gain
+// instead of dynamic compression, which would pump on speech.
+func NormalizeLoudness(ctx context.Context, input, output string, enc Encoding) error {
+	target := loudnormTarget(LoudnessTarget(enc.ChannelCount()))
+	m, err := analyzeLoudness(ctx, input, target+":print_format=json")
+	if err != nil {
+		return fmt.Errorf("measure loudness: %w", err)
+	}
+
+	filter := fmt.Sprintf("%s:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
+		target, ffFloat(m.Integrated), ffFloat(m.TruePeak), ffFloat(m.Range), ffFloat(m.Threshold), ffFloat(m.Offset))
+	args := append([]string{"-i", input, "-af", filter}, FormatOf(output).encodeArgs(enc)...)
+	args = append(args, "-y", output)
+	_, _, err = runTool(ctx, "ffmpeg", "loudness normalization", episodeTimeout, args...)
+	return err
+}
+
+// loudnormTarget returns the loudnorm filter with the targets set.
+func loudnormTarget(integrated float64) string {
+	return fmt.Sprintf("loudnorm=I=%s:TP=%s:LRA=%s", ffFloat(integrated), ffFloat(loudnessTruePeak), ffFloat(loudnessRange))
+}
@@ -0,0 +1,132 @@
+package assembly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Music bed timing. The bed plays alone for musicIntro seconds before the
+// first voice and musicOutro seconds after the last, fading in and out at
+// the ends.
+const (
+	musicIntro   = 4.0
+	musicOutro   = 5.0
+	musicFadeIn  = 2.0
+	musicFadeOut = 4.0
+
+	// DefaultMusicVolume is the bed's gain in dB before ducking: quiet
+	// enough to sit under speech, audible in the intro and outro.
+	DefaultMusicVolume = -18.0
+)
+
+// MusicLead is how far MixMusic delays the voices, in seconds, to let the
+// bed play in alone.
+const MusicLead = musicIntro
+
+// builtinBeds are generated music beds, synthesized by FFmpeg's aevalsrc
+// so there is no licensing to track. Each is a slow chord pad; the
+// expressions loop indefinitely and the bed is cut to the episode length.
+var builtinBeds = map[string]string{
+	// A minor pad (A3, C4, E4) with a slow swell.
+	"ambient": "0.25*(sin(2*PI*220*t)+sin(2*PI*261.63*t)+sin(2*PI*329.63*t))*(0.6+0.4*sin(2*PI*0.05*t))",
+	// C major pad (C3, E3, G3, C4) with a gentle four-beats-per-second pulse.
+	"pulse": "0.2*(sin(2*PI*130.81*t)+sin(2*PI*164.81*t)+sin(2*PI*196*t)+sin(2*PI*261.63*t))*(0.7+0.3*sin(2*PI*2*t))",
+	// Low D drone (D2, A2, D3) for serious or documentary episodes.
+	"drone": "0.3*(sin(2*PI*73.42*t)+sin(2*PI*110*t)+sin(2*PI*146.83*t))*(0.8+0.2*sin(2*PI*0.03*t))",
+}
+
+// MusicBeds returns the names of the built-in music beds, sorted.
+func MusicBeds() []string {
+	names := make([]string, 0, len(builtinBeds))
+	for name := range builtinBeds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsBuiltinMusic reports whether name is a built-in music bed.
+func IsBuiltinMusic(name string) bool {
+	_, ok := builtinBeds[name]
+	return ok
+}
+
+// Music is a background bed mixed under an episode's voices.
+type Music struct {
+	// Source is an audio file, looped as needed, or a built-in bed name
+	// (see MusicBeds).
+	Source string
+	// Volume is the bed's gain in dB (0 = DefaultMusicVolume).
+	Volume float64
+}
+
+// ValidateMusic checks that source is a built-in bed or a readable file.
+func ValidateMusic(source string) error {
+	if IsBuiltinMusic(source) {
+		return nil
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("music %q is neither a built-in bed (%s) nor a readable file: %w", source, strings.Join(MusicBeds(), ", "), err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("music %q is a directory", source)
+	}
+	return nil
+}
+
+// MixMusic writes output: the voice track with m mixed underneath. The bed
+// is ducked while anyone is speaking (sidechain compression keyed on the
+// voices), leads the first line by a short intro, runs on for an outro
+// after the last, and fades in and out. output is encoded with enc.
+func MixMusic(ctx context.Context, voice string, m Music, output string, enc Encoding) error {
+	voiceSecs, err := ProbeSeconds(ctx, voice)
+	if err != nil {
+		return fmt.Errorf("probe voice track: %w", err)
+	}
+	total := voiceSecs + musicIntro + musicOutro
+
+	volume := m.Volume
+	if volume == 0 {
+		volume = DefaultMusicVolume
+	}
+
+	args := []string{"-i", voice}
+	if expr, ok := builtinBeds[m.Source]; ok {
+		args = append(args, "-f", "lavfi", "-i", fmt.Sprintf("aevalsrc=%s:s=%s:c=stereo", expr, AudioSampleRate))
+	} else {
+		args = append(args, "-stream_loop", "-1", "-i", m.Source)
+	}
+
+	format := "aresample=" + AudioSampleRate + ",aformat=sample_fmts=fltp:channel_layouts=stereo"
+	introMs := strconv.Itoa(int(musicIntro * 1000))
+	graph := strings.Join([]string{
+		// Voices, shifted by the intro and padded by the outro; one copy is
+		// heard, the other keys the ducking.
+		fmt.Sprintf("[0:a]%s,adelay=%s|%s,apad=pad_dur=%s,asplit=2[voice][key]", format, introMs, introMs, ffFloat(musicOutro)),
+		// The bed, cut to the episode and faded at both ends.
+		fmt.Sprintf("[1:a]%s,volume=%sdB,atrim=end=%s,afade=t=in:d=%s,afade=t=out:st=%s:d=%s[bed]",
+			format, ffFloat(volume), ffFloat(total), ffFloat(musicFadeIn), ffFloat(total-musicFadeOut), ffFloat(musicFadeOut)),
+		"[bed][key]sidechaincompress=threshold=0.02:ratio=8:attack=20:release=600[ducked]",
+		"[voice][ducked]amix=inputs=2:duration=first:normalize=0[out]",
+	}, ";")
+
+	args = append(args,
+		"-filter_complex", graph,
+		"-map", "[out]",
+		"-t", ffFloat(total),
+	)
+	args = append(args, FormatOf(output).encodeArgs(enc)...)
+	args = append(args, "-y", output)
+	_, _, err = runTool(ctx, "ffmpeg", "music mix", episodeTimeout, args...)
+	return err
+}
+
+// ffFloat formats seconds or decibels for a filter argument.
+func ffFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
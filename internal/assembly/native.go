@@ -0,0 +1,533 @@
+package assembly
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+)
+
+// Without FFmpeg, NativeAssembler joins segments in Go, within limits: MP3
+// segments are spliced frame by frame into an MP3 (so they must share a
+// sample rate and channel mode), and PCM WAV segments sample by sample into
+// a WAV. Pauses are silent MP3 frames or zero samples. Anything that
+// decodes, resamples, mixes, or re-encodes (crossfades, music, stingers,
+// loudness, tags, other output formats) needs FFmpeg.
+
+// RawPCMRate is the sample rate of raw PCM from providers (16-bit signed
+// little-endian mono), as ConvertWithEffects reads it.
+const RawPCMRate = 24000
+
+// HasFFmpeg reports whether ffmpeg is on PATH.
+func HasFFmpeg() bool {
+	_, err := exec.LookPath("ffmpeg")
+	return err == nil
+}
+
+// NativeAssembler is the Assembler used without FFmpeg.
+type NativeAssembler struct {
+	pacing        Pacing
+	segmentSecs   []float64
+	segmentStarts []float64
+}
+
+// NewNativeAssembler creates an assembler that joins segments with pacing,
+// less crossfades, in Go.
+func NewNativeAssembler(pacing Pacing) *NativeAssembler {
+	pacing.Crossfade = 0
+	return &NativeAssembler{pacing: pacing}
+}
+
+// Assemble writes segments to output, which must be MP3 (from MP3
+// segments) or WAV (from PCM WAV segments). tmpDir is unused.
+func (a *NativeAssembler) Assemble(ctx context.Context, segments []string, tmpDir string, output string) error {
+	if len(segments) == 0 {
+		return fmt.Errorf("no audio segments to assemble")
+	}
+	a.segmentSecs, a.segmentStarts = nil, nil
+
+	var (
+		secs, starts []float64
+		err          error
+	)
+	switch f := FormatOf(output); f {
+	case FormatMP3:
+		secs, starts, err = a.joinMP3(ctx, segments, output)
+	case FormatWAV:
+		secs, starts, err = a.joinWAV(ctx, segments, output)
+	default:
+		return fmt.Errorf("%s output needs FFmpeg; use MP3 or WAV, or install FFmpeg", f)
+	}
+	if err != nil {
+		return err
+	}
+	a.segmentSecs, a.segmentStarts = secs, starts
+	return nil
+}
+
+// SegmentSeconds returns the duration of each segment from the last
+// Assemble, as FFmpegAssembler.SegmentSeconds does.
+func (a *NativeAssembler) SegmentSeconds() []float64 {
+	return a.segmentSecs
+}
+
+// SegmentStarts returns when each segment starts from the last Assemble,
+// as FFmpegAssembler.SegmentStarts does.
+func (a *NativeAssembler) SegmentStarts() []float64 {
+	return a.segmentStarts
+}
+
+// joinMP3 splices MP3 segments into output. It returns the segment
+// durations and starts, in whole frames.
+func (a *NativeAssembler) joinMP3(ctx context.Context, segments []string, output string) ([]float64, []float64, error) {
+	audio := make([]mp3Audio, len(segments))
+	secs := make([]float64, len(segments))
+	for i, seg := range segments {
+		data, err := os.ReadFile(seg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("segment %d: %w", i+1, err)
+		}
+		if isWAV(data) {
+			return nil, nil, fmt.Errorf("segment %d is PCM audio; joining it into an MP3 needs FFmpeg (or use --output-format wav)", i+1)
+		}
+		if audio[i], err = readMP3(data); err != nil {
+			return nil, nil, fmt.Errorf("segment %d: %w", i+1, err)
+		}
+		if h, first := audio[i].header, audio[0].header; h.rate != first.rate || h.mono != first.mono {
+			return nil, nil, fmt.Errorf("segment %d is %s and segment 1 is %s; mixing them needs FFmpeg", i+1, h, first)
+		}
+		secs[i] = audio[i].seconds()
+	}
+
+	// Pauses are whole silent frames.
+	ref := audio[0].header
+	frameSecs := float64(ref.samples) / float64(ref.rate)
+	joins := a.pacing.joins(secs)
+	silences := make([]int, len(segments))
+	starts := make([]float64, len(segments))
+	var pos float64
+	for i := range audio {
+		if i > 0 {
+			silences[i] = int(math.Round(joins[i] / frameSecs))
+			pos += float64(silences[i]) * frameSecs
+		}
+		starts[i] = pos
+		pos += secs[i]
+	}
+
+	silent := ref.silentFrame()
+	err := writeFile(output, func(w *bufio.Writer) error {
+		for i, seg := range audio {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			for range silences[i] {
+				w.Write(silent)
+			}
+			for _, frame := range seg.frames {
+				w.Write(frame)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("write %s: %w", output, err)
+	}
+	return secs, starts, nil
+}
+
+// joinWAV joins PCM WAV segments into output. It returns the segment
+// durations and starts.
+func (a *NativeAssembler) joinWAV(ctx context.Context, segments []string, output string) ([]float64, []float64, error) {
+	audio := make([]pcmAudio, len(segments))
+	secs := make([]float64, len(segments))
+	var size int
+	for i, seg := range segments {
+		data, err := os.ReadFile(seg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("segment %d: %w", i+1, err)
+		}
+		if !isWAV(data) {
+			return nil, nil, fmt.Errorf("segment %d is compressed audio; decoding it into a WAV needs FFmpeg (or use --output-format mp3)", i+1)
+		}
+		if audio[i], err = readWAV(data); err != nil {
+			return nil, nil, fmt.Errorf("segment %d: %w", i+1, err)
+		}
+		if p, first := audio[i], audio[0]; p.rate != first.rate || p.channels != first.channels || p.bits != first.bits {
+			return nil, nil, fmt.Errorf("segment %d is %s and segment 1 is %s; mixing them needs FFmpeg", i+1, p, first)
+		}
+		secs[i] = audio[i].seconds()
+		size += len(audio[i].data)
+	}
+
+	// Pauses are whole sample frames of zeros.
+	ref := audio[0]
+	joins := a.pacing.joins(secs)
+	silences := make([]int, len(segments))
+	starts := make([]float64, len(segments))
+	var pos float64
+	for i := range audio {
+		if i > 0 {
+			silences[i] = int(math.Round(joins[i]*float64(ref.rate))) * ref.blockAlign()
+			size += silences[i]
+			pos += float64(silences[i]) / float64(ref.bytesPerSecond())
+		}
+		starts[i] = pos
+		pos += secs[i]
+	}
+
+	err := writeFile(output, func(w *bufio.Writer) error {
+		w.Write(wavHeader(ref.rate, ref.channels, ref.bits, size))
+		for i, seg := range audio {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			w.Write(make([]byte, silences[i]))
+			w.Write(seg.data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("write %s: %w", output, err)
+	}
+	return secs, starts, nil
+}
+
+// writeFile creates path and writes it with fn, removing it on failure.
+func writeFile(path string, fn func(w *bufio.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = fn(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// NativeSeconds returns the duration of an MP3 or PCM WAV file, reading it
+// in Go. ProbeSeconds falls back to it without ffprobe.
+func NativeSeconds(path string) (float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	secs, err := DataSeconds(data)
+	if err != nil {
+		return 0, fmt.Errorf("read %s: %w", path, err)
+	}
+	return secs, nil
+}
+
+// DataSeconds returns the duration of MP3 or PCM WAV audio in memory.
+func DataSeconds(data []byte) (float64, error) {
+	if isWAV(data) {
+		p, err := readWAV(data)
+		if err != nil {
+			return 0, err
+		}
+		return p.seconds(), nil
+	}
+	m, err := readMP3(data)
+	if err != nil {
+		return 0, err
+	}
+	return m.seconds(), nil
+}
+
+// WriteRawPCM writes raw provider PCM (RawPCMRate, 16-bit, mono) to path
+// as a WAV.
+func WriteRawPCM(path string, pcm []byte) error {
+	return writeFile(path, func(w *bufio.Writer) error {
+		w.Write(wavHeader(RawPCMRate, 1, 16, len(pcm)))
+		w.Write(pcm)
+		return nil
+	})
+}
+
+// MP3 (MPEG-1, -2, and -2.5 Layer III) frames.
+
+var (
+	mp3Bitrates1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3Bitrates2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mp3Rates     = [4]int{44100, 48000, 32000, 0}
+)
+
+// mp3Header is a parsed Layer III frame header.
+type mp3Header struct {
+	raw     [4]byte
+	mpeg1   bool
+	crc     bool
+	mono    bool
+	rate    int // Hz
+	samples int // per frame
+	size    int // frame length in bytes
+}
+
+// parseMP3Header parses the frame header at the start of b. Free-format
+// bitrates and layers other than III are rejected.
+func parseMP3Header(b []byte) (mp3Header, bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return mp3Header{}, false
+	}
+	version := b[1] >> 3 & 3 // 0 MPEG-2.5, 2 MPEG-2, 3 MPEG-1
+	layer := b[1] >> 1 & 3   // 1 Layer III
+	bitrate, rate := b[2]>>4, b[2]>>2&3
+	if version == 1 || layer != 1 || bitrate == 0 || bitrate == 15 || rate == 3 {
+		return mp3Header{}, false
+	}
+	h := mp3Header{
+		mpeg1: version == 3,
+		crc:   b[1]&1 == 0,
+		mono:  b[3]>>6 == 3,
+		rate:  mp3Rates[rate],
+	}
+	copy(h.raw[:], b)
+	switch version {
+	case 2:
+		h.rate /= 2
+	case 0:
+		h.rate /= 4
+	}
+	padding := int(b[2] >> 1 & 1)
+	if h.mpeg1 {
+		h.samples = 1152
+		h.size = 144*mp3Bitrates1[bitrate]*1000/h.rate + padding
+	} else {
+		h.samples = 576
+		h.size = 72*mp3Bitrates2[bitrate]*1000/h.rate + padding
+	}
+	return h, true
+}
+
+func (h mp3Header) String() string {
+	layout := "stereo"
+	if h.mono {
+		layout = "mono"
+	}
+	return fmt.Sprintf("%d Hz %s MP3", h.rate, layout)
+}
+
+// sideInfo returns the length of the side information after the header.
+func (h mp3Header) sideInfo() int {
+	switch {
+	case h.mpeg1 && h.mono:
+		return 17
+	case h.mpeg1:
+		return 32
+	case h.mono:
+		return 9
+	default:
+		return 17
+	}
+}
+
+// silentFrame returns a frame like h's, unpadded and without a CRC, whose
+// side information and main data are all zero: no coded samples, which
+// decodes as silence.
+func (h mp3Header) silentFrame() []byte {
+	b := h.raw
+	b[1] |= 1  // no CRC
+	b[2] &^= 2 // no padding
+	silent, _ := parseMP3Header(b[:])
+	frame := make([]byte, silent.size)
+	copy(frame, b[:])
+	return frame
+}
+
+// isInfoFrame reports whether frame is a Xing, Info, or VBRI header, which
+// describes the file it opens rather than holding audio.
+func (h mp3Header) isInfoFrame(frame []byte) bool {
+	off := 4 + h.sideInfo()
+	if h.crc {
+		off += 2
+	}
+	if len(frame) >= off+4 {
+		if tag := string(frame[off : off+4]); tag == "Xing" || tag == "Info" {
+			return true
+		}
+	}
+	return len(frame) >= 40 && string(frame[36:40]) == "VBRI"
+}
+
+// mp3Audio is an MP3 file's audio frames.
+type mp3Audio struct {
+	header mp3Header // of the first frame
+	frames [][]byte
+}
+
+func (m mp3Audio) seconds() float64 {
+	return float64(len(m.frames)*m.header.samples) / float64(m.header.rate)
+}
+
+// readMP3 returns data's audio frames, skipping ID3v2 tags before them,
+// a Xing/Info header frame, and whatever follows the last frame (an ID3v1
+// tag, say).
+func readMP3(data []byte) (mp3Audio, error) {
+	data = skipID3v2(data)
+
+	// Sync on a header whose next frame also parses, so stray 0xFF bytes
+	// aren't mistaken for one.
+	off := -1
+	for i := 0; i+4 <= len(data); i++ {
+		h, ok := parseMP3Header(data[i:])
+		if !ok || i+h.size > len(data) {
+			continue
+		}
+		if next := i + h.size; next+4 <= len(data) {
+			if _, ok := parseMP3Header(data[next:]); !ok {
+				continue
+			}
+		}
+		off = i
+		break
+	}
+	if off < 0 {
+		return mp3Audio{}, errors.New("no MP3 frames found")
+	}
+
+	var m mp3Audio
+	for off < len(data) {
+		h, ok := parseMP3Header(data[off:])
+		if !ok || off+h.size > len(data) {
+			break
+		}
+		frame := data[off : off+h.size]
+		off += h.size
+		if len(m.frames) == 0 && h.isInfoFrame(frame) {
+			continue
+		}
+		if len(m.frames) == 0 {
+			m.header = h
+		} else if h.rate != m.header.rate || h.mono != m.header.mono {
+			return mp3Audio{}, fmt.Errorf("changes from %s to %s mid-stream", m.header, h)
+		}
+		m.frames = append(m.frames, frame)
+	}
+	if len(m.frames) == 0 {
+		return mp3Audio{}, errors.New("no MP3 audio frames found")
+	}
+	return m, nil
+}
+
+// skipID3v2 returns data after any leading ID3v2 tags.
+func skipID3v2(data []byte) []byte {
+	for len(data) >= 10 && string(data[:3]) == "ID3" {
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		size += 10
+		if data[5]&0x10 != 0 { // footer
+			size += 10
+		}
+		if size > len(data) {
+			return nil
+		}
+		data = data[size:]
+	}
+	return data
+}
+
+// PCM WAV.
+
+// pcmAudio is a PCM WAV file's format and samples.
+type pcmAudio struct {
+	rate, channels, bits int
+	data                 []byte
+}
+
+func (p pcmAudio) blockAlign() int {
+	return p.channels * p.bits / 8
+}
+
+func (p pcmAudio) bytesPerSecond() int {
+	return p.rate * p.blockAlign()
+}
+
+func (p pcmAudio) seconds() float64 {
+	return float64(len(p.data)) / float64(p.bytesPerSecond())
+}
+
+func (p pcmAudio) String() string {
+	return fmt.Sprintf("%d Hz %d-channel %d-bit PCM", p.rate, p.channels, p.bits)
+}
+
+func isWAV(data []byte) bool {
+	return len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WAVE"
+}
+
+// readWAV parses a RIFF WAVE file of integer PCM.
+func readWAV(data []byte) (pcmAudio, error) {
+	if !isWAV(data) {
+		return pcmAudio{}, errors.New("not a WAV file")
+	}
+	var p pcmAudio
+	var haveFormat bool
+	for off := 12; off+8 <= len(data); {
+		id := string(data[off : off+4])
+		size := int(binary.LittleEndian.Uint32(data[off+4:]))
+		body := data[off+8:]
+		if size > len(body) {
+			// A streamed WAV may leave the data size unset.
+			size = len(body)
+		}
+		body = body[:size]
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return pcmAudio{}, errors.New("short WAV fmt chunk")
+			}
+			// 1 is PCM; 0xFFFE (extensible) is also PCM when written by
+			// FFmpeg for these formats.
+			if tag := binary.LittleEndian.Uint16(body); tag != 1 && tag != 0xFFFE {
+				return pcmAudio{}, fmt.Errorf("WAV format %#x isn't PCM", tag)
+			}
+			p.channels = int(binary.LittleEndian.Uint16(body[2:]))
+			p.rate = int(binary.LittleEndian.Uint32(body[4:]))
+			p.bits = int(binary.LittleEndian.Uint16(body[14:]))
+			if p.channels == 0 || p.rate == 0 || p.bits == 0 || p.bits%8 != 0 {
+				return pcmAudio{}, errors.New("invalid WAV format")
+			}
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return pcmAudio{}, errors.New("WAV data before its format")
+			}
+			p.data = body[:len(body)/p.blockAlign()*p.blockAlign()]
+			return p, nil
+		}
+		off += 8 + size + size%2 // chunks are word-aligned
+	}
+	return pcmAudio{}, errors.New("no WAV data chunk")
+}
+
+// wavHeader returns the 44-byte header of a PCM WAV with size bytes of
+// samples.
+func wavHeader(rate, channels, bits, size int) []byte {
+	var b bytes.Buffer
+	le := binary.LittleEndian
+	b.WriteString("RIFF")
+	binary.Write(&b, le, uint32(36+size))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, le, uint32(16))
+	binary.Write(&b, le, uint16(1))
+	binary.Write(&b, le, uint16(channels))
+	binary.Write(&b, le, uint32(rate))
+	binary.Write(&b, le, uint32(rate*channels*bits/8))
+	binary.Write(&b, le, uint16(channels*bits/8))
+	binary.Write(&b, le, uint16(bits))
+	b.WriteString("data")
+	binary.Write(&b, le, uint32(size))
+	return b.Bytes()
+}
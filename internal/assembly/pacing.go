@@ -0,0 +1,212 @@
+package assembly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pacing limits. The gap is the silence between segments; a beat is the
+// longer pause before a segment the script marks (script.Segment.Beat).
+const (
+	DefaultGap = 200 * time.Millisecond
+	MinGap     = 50 * time.Millisecond
+	MaxGap     = time.Second
+	BeatGap    = 800 * time.Millisecond
+
+	MinCrossfade = 10 * time.Millisecond
+	MaxCrossfade = 300 * time.Millisecond
+)
+
+// Pacing controls how Assemble joins segments.
+type Pacing struct {
+	// Gap is the silence between segments; 0 means DefaultGap.
+	Gap time.Duration
+
+	// Crossfade, if set, overlaps each segment with the next by this much
+	// instead of separating them with Gap. Beats, and segments too short
+	// to fade at both ends, still get silence.
+	Crossfade time.Duration
+
+	// Beats marks the segments that follow a BeatGap pause, by index. It
+	// may be shorter than the segments, or nil.
+	Beats []bool
+
+	// SFX names the sound effect (sfx.go) played before each segment, by
+	// index, with the usual pause (or a beat) before it and Gap after. It
+	// may be shorter than the segments, or nil; the first segment's is
+	// ignored.
+	SFX []string
+
+	// Trim cuts leading and trailing silence from each segment before
+	// they are joined (trim.go).
+	Trim SilenceTrim
+}
+
+// Validate checks Gap and Crossfade against their limits.
+func (p Pacing) Validate() error {
+	if p.Gap != 0 && (p.Gap < MinGap || p.Gap > MaxGap) {
+		return fmt.Errorf("gap must be %s-%s, got %s", MinGap, MaxGap, p.Gap)
+	}
+	if p.Crossfade != 0 && (p.Crossfade < MinCrossfade || p.Crossfade > MaxCrossfade) {
+		return fmt.Errorf("crossfade must be %s-%s, got %s", MinCrossfade, MaxCrossfade, p.Crossfade)
+	}
+	return nil
+}
+
+func (p Pacing) gap() time.Duration {
+	if p.Gap == 0 {
+		return DefaultGap
+	}
+	return p.Gap
+}
+
+// beat reports whether segment i follows a beat.
+func (p Pacing) beat(i int) bool {
+	return i < len(p.Beats) && p.Beats[i]
+}
+
+// sfx returns the effect before segment i, if any.
+func (p Pacing) sfx(i int) string {
+	if i < len(p.SFX) {
+		return p.SFX[i]
+	}
+	return ""
+}
+
+// joins returns, for each segment after the first, the pause before it in
+// seconds, or a negative overlap where it crossfades with the one before.
+// secs are the segment durations; a crossfade needs both segments measured
+// and at least twice its length, since a middle segment fades at both ends.
+func (p Pacing) joins(secs []float64) []float64 {
+	xf := p.Crossfade.Seconds()
+	out := make([]float64, len(secs))
+	for i := 1; i < len(secs); i++ {
+		switch {
+		case p.sfx(i) != "":
+			lead := p.gap().Seconds()
+			if p.beat(i) {
+				lead = BeatGap.Seconds()
+			}
+			out[i] = lead + SFXSeconds(p.sfx(i)) + p.gap().Seconds()
+		case p.beat(i):
+			out[i] = BeatGap.Seconds()
+		case xf > 0 && secs[i-1] >= 2*xf && secs[i] >= 2*xf:
+			out[i] = -xf
+		default:
+			out[i] = p.gap().Seconds()
+		}
+	}
+	return out
+}
+
+// segmentStarts returns when each segment starts in the assembled voice
+// track, from the segment durations and joins.
+func segmentStarts(secs, joins []float64) []float64 {
+	starts := make([]float64, len(secs))
+	for i := 1; i < len(secs); i++ {
+		starts[i] = starts[i-1] + secs[i-1] + joins[i]
+	}
+	return starts
+}
+
+// joinPieces lays out the concat list: segments, silences between them,
+// sound effects with their pauses, and runs of crossfaded segments mixed
+// into one WAV each. Silence and effect files are generated once each.
+func joinPieces(ctx context.Context, segments []string, joins []float64, p Pacing, tmpDir string) ([]string, error) {
+	silences := map[float64]string{}
+	silence := func(secs float64) (string, error) {
+		if path, ok := silences[secs]; ok {
+			return path, nil
+		}
+		path := filepath.Join(tmpDir, fmt.Sprintf("silence_%dms.wav", int(secs*1000)))
+		if err := generateSilence(ctx, path, secs); err != nil {
+			return "", fmt.Errorf("generate silence: %w", err)
+		}
+		silences[secs] = path
+		return path, nil
+	}
+	effects := map[string]string{}
+	effect := func(name string) (string, error) {
+		if path, ok := effects[name]; ok {
+			return path, nil
+		}
+		path := filepath.Join(tmpDir, "sfx_"+name+".wav")
+		if err := writeSFX(name, path); err != nil {
+			return "", fmt.Errorf("generate sound effect: %w", err)
+		}
+		effects[name] = path
+		return path, nil
+	}
+
+	var pieces []string
+	for i := 0; i < len(segments); {
+		// Extend the run while the next segment crossfades in.
+		j := i + 1
+		for j < len(segments) && joins[j] < 0 {
+			j++
+		}
+		piece := segments[i]
+		if j-i > 1 {
+			piece = filepath.Join(tmpDir, fmt.Sprintf("crossfade_%03d.wav", i))
+			if err := crossfadeRun(ctx, segments[i:j], -joins[i+1], piece); err != nil {
+				return nil, fmt.Errorf("crossfade segments %d-%d: %w", i+1, j, err)
+			}
+		}
+		pieces = append(pieces, piece)
+		if j < len(segments) {
+			pause := joins[j]
+			if name := p.sfx(j); name != "" {
+				// The pause before the effect, the effect, then Gap.
+				trail := p.gap().Seconds()
+				lead, err := silence(math.Round((pause-SFXSeconds(name)-trail)*1000) / 1000)
+				if err != nil {
+					return nil, err
+				}
+				fx, err := effect(name)
+				if err != nil {
+					return nil, err
+				}
+				pieces = append(pieces, lead, fx)
+				pause = trail
+			}
+			path, err := silence(pause)
+			if err != nil {
+				return nil, err
+			}
+			pieces = append(pieces, path)
+		}
+		i = j
+	}
+	return pieces, nil
+}
+
+// crossfadeRun mixes segments into one PCM WAV, each overlapping the next
+// by secs with a triangular crossfade.
+func crossfadeRun(ctx context.Context, segments []string, secs float64, output string) error {
+	var args []string
+	for _, seg := range segments {
+		args = append(args, "-i", seg)
+	}
+	d := strconv.FormatFloat(secs, 'f', 3, 64)
+	var graph []string
+	prev := "[0:a]"
+	for i := 1; i < len(segments); i++ {
+		out := fmt.Sprintf("[x%d]", i)
+		graph = append(graph, fmt.Sprintf("%s[%d:a]acrossfade=d=%s:c1=tri:c2=tri%s", prev, i, d, out))
+		prev = out
+	}
+	args = append(args,
+		"-filter_complex", strings.Join(graph, ";"),
+		"-map", prev,
+		"-c:a", AudioPCMCodec,
+		"-y",
+		output,
+	)
+	_, _, err := runTool(ctx, "ffmpeg", "crossfade", episodeTimeout, args...)
+	return err
+}
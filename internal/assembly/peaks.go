@@ -0,0 +1,84 @@
+package assembly
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Waveform peaks for web players: the episode is cut into equal buckets and
+// each bucket's loudest sample is reported as a 0-1 amplitude. FFmpeg's
+// astats measures one frame of asetnsamples per bucket; decoding at
+// peakRate is plenty for a waveform.
+const (
+	PeakBuckets = 1000
+
+	peakRate = 8000 // Hz
+	peakKey  = "lavfi.astats.Overall.Peak_level"
+)
+
+// Peaks is an episode's waveform, as written to peaks.json.
+type Peaks struct {
+	Version       int       `json:"version"`
+	Duration      float64   `json:"duration"`       // seconds
+	BucketSeconds float64   `json:"bucket_seconds"` // span of each peak
+	Peaks         []float64 `json:"peaks"`          // 0-1, across all channels
+}
+
+// MeasurePeaks returns path's waveform in about buckets peaks.
+func MeasurePeaks(ctx context.Context, path string, buckets int) (Peaks, error) {
+	secs, err := ProbeSeconds(ctx, path)
+	if err != nil {
+		return Peaks{}, err
+	}
+	if secs <= 0 || buckets <= 0 {
+		return Peaks{}, fmt.Errorf("waveform peaks: %s has no duration", path)
+	}
+	samples := max(int(math.Ceil(secs*peakRate/float64(buckets))), 1)
+	stdout, _, err := runTool(ctx, "ffmpeg", "waveform peaks", episodeTimeout,
+		"-i", path,
+		"-map", "0:a:0",
+		"-af", fmt.Sprintf("aresample=%d,asetnsamples=n=%d:p=0,astats=metadata=1:reset=1,ametadata=mode=print:key=%s:file=-", peakRate, samples, peakKey),
+		"-f", "null", "-",
+	)
+	if err != nil {
+		return Peaks{}, err
+	}
+	peaks, err := parsePeaks(stdout)
+	if err != nil {
+		return Peaks{}, err
+	}
+	return Peaks{
+		Version:       1,
+		Duration:      math.Round(secs*1000) / 1000,
+		BucketSeconds: float64(samples) / peakRate,
+		Peaks:         peaks,
+	}, nil
+}
+
+// parsePeaks reads ametadata's per-frame peak levels (dBFS) as amplitudes.
+func parsePeaks(out []byte) ([]float64, error) {
+	var peaks []float64
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		value, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), peakKey+"=")
+		if !ok {
+			continue
+		}
+		db, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsInf(db, -1) {
+			peaks = append(peaks, 0) // -inf: digital silence
+			continue
+		}
+		amp := math.Min(math.Pow(10, db/20), 1)
+		peaks = append(peaks, math.Round(amp*1000)/1000)
+	}
+	if len(peaks) == 0 {
+		return nil, fmt.Errorf("no peak levels in ffmpeg output")
+	}
+	return peaks, nil
+}
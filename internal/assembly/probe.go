@@ -0,0 +1,179 @@
+package assembly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Loudness is an EBU R128 measurement of an audio file.
+type Loudness struct {
+	Integrated float64 // integrated loudness, LUFS
+	TruePeak   float64 // true peak, dBTP
+	Range      float64 // loudness range, LU
+
+	// Threshold and Offset are loudnorm's gating threshold and target
+	// offset, needed for a second, linear normalization pass.
+	Threshold float64
+	Offset    float64
+}
+
+// MeasureLoudness runs FFmpeg's loudnorm filter in analysis mode over path
+// and returns the measured input loudness. Nothing is written.
+func MeasureLoudness(ctx context.Context, path string) (Loudness, error) {
+	return analyzeLoudness(ctx, path, "loudnorm=print_format=json")
+}
+
+// analyzeLoudness runs a loudnorm analysis filter over path and parses its
+// summary.
+func analyzeLoudness(ctx context.Context, path, filter string) (Loudness, error) {
+	_, stderr, err := runTool(ctx, "ffmpeg", "loudness analysis", episodeTimeout,
+		"-i", path,
+		"-af", filter,
+		"-f", "null", "-",
+	)
+	if err != nil {
+		return Loudness{}, err
+	}
+	return parseLoudnorm(stderr)
+}
+
+// parseLoudnorm parses the summary loudnorm prints as the last JSON block
+// on stderr.
+func parseLoudnorm(stderr []byte) (Loudness, error) {
+	out := string(stderr)
+	start, end := strings.LastIndex(out, "{"), strings.LastIndex(out, "}")
+	if start < 0 || end < start {
+		return Loudness{}, fmt.Errorf("no loudnorm summary in ffmpeg output")
+	}
+	var summary struct {
+		InputI   string `json:"input_i"`
+		InputTP  string `json:"input_tp"`
+		InputLRA string `json:"input_lra"`
+		Thresh   string `json:"input_thresh"`
+		Offset   string `json:"target_offset"`
+	}
+	if err := json.Unmarshal([]byte(out[start:end+1]), &summary); err != nil {
+		return Loudness{}, fmt.Errorf("parse loudnorm summary: %w", err)
+	}
+
+	var l Loudness
+	var err error
+	if l.Integrated, err = strconv.ParseFloat(summary.InputI, 64); err != nil {
+		return Loudness{}, fmt.Errorf("parse integrated loudness %q: %w", summary.InputI, err)
+	}
+	l.TruePeak, _ = strconv.ParseFloat(summary.InputTP, 64)
+	l.Range, _ = strconv.ParseFloat(summary.InputLRA, 64)
+	l.Threshold, _ = strconv.ParseFloat(summary.Thresh, 64)
+	l.Offset, _ = strconv.ParseFloat(summary.Offset, 64)
+	return l, nil
+}
+
+// ProbeSeconds returns the duration of an audio file in seconds via ffprobe,
+// or for MP3 and WAV files, NativeSeconds if ffprobe isn't installed.
+func ProbeSeconds(ctx context.Context, path string) (float64, error) {
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		return NativeSeconds(path)
+	}
+	out, _, err := runTool(ctx, "ffprobe", "duration probe", probeTimeout,
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		path,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("probe %s: %w", path, err)
+	}
+	secs, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse duration %q: %w", strings.TrimSpace(string(out)), err)
+	}
+	return secs, nil
+}
+
+// AudioInfo is what ffprobe reports about an episode file: its audio
+// stream, embedded cover art, and container tags.
+type AudioInfo struct {
+	Codec      string // ffprobe codec name: "mp3", "aac", ...
+	SampleRate int    // Hz
+	Channels   int
+	Bitrate    int // bits per second; 0 if unknown
+	Seconds    float64
+	Tags       map[string]string // container tags, keys lowercased
+	Cover      *CoverInfo        // nil without embedded art
+}
+
+// CoverInfo describes embedded cover art.
+type CoverInfo struct {
+	Codec         string // "mjpeg" or "png"
+	Width, Height int
+	PixFmt        string // e.g. "yuvj420p", "rgb24", "cmyk"
+}
+
+// ProbeAudio returns path's first audio stream, attached picture, and
+// container tags.
+func ProbeAudio(ctx context.Context, path string) (AudioInfo, error) {
+	out, _, err := runTool(ctx, "ffprobe", "stream probe", probeTimeout,
+		"-v", "error",
+		"-show_streams",
+		"-show_format",
+		"-of", "json",
+		path,
+	)
+	if err != nil {
+		return AudioInfo{}, fmt.Errorf("probe %s: %w", path, err)
+	}
+	var probe struct {
+		Streams []struct {
+			CodecType   string `json:"codec_type"`
+			CodecName   string `json:"codec_name"`
+			SampleRate  string `json:"sample_rate"`
+			Channels    int    `json:"channels"`
+			BitRate     string `json:"bit_rate"`
+			Width       int    `json:"width"`
+			Height      int    `json:"height"`
+			PixFmt      string `json:"pix_fmt"`
+			Disposition struct {
+				AttachedPic int `json:"attached_pic"`
+			} `json:"disposition"`
+		} `json:"streams"`
+		Format struct {
+			Duration string            `json:"duration"`
+			BitRate  string            `json:"bit_rate"`
+			Tags     map[string]string `json:"tags"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return AudioInfo{}, fmt.Errorf("parse probe of %s: %w", path, err)
+	}
+
+	info := AudioInfo{Tags: make(map[string]string, len(probe.Format.Tags))}
+	for k, v := range probe.Format.Tags {
+		info.Tags[strings.ToLower(k)] = v
+	}
+	info.Seconds, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+	found := false
+	for _, s := range probe.Streams {
+		switch {
+		case s.CodecType == "audio" && !found:
+			found = true
+			info.Codec = s.CodecName
+			info.SampleRate, _ = strconv.Atoi(s.SampleRate)
+			info.Channels = s.Channels
+			info.Bitrate, _ = strconv.Atoi(s.BitRate)
+		case s.CodecType == "video" && s.Disposition.AttachedPic == 1 && info.Cover == nil:
+			info.Cover = &CoverInfo{Codec: s.CodecName, Width: s.Width, Height: s.Height, PixFmt: s.PixFmt}
+		}
+	}
+	if !found {
+		return AudioInfo{}, fmt.Errorf("%s has no audio stream", path)
+	}
+	if info.Bitrate == 0 {
+		// Some demuxers only report the container's overall rate.
+		info.Bitrate, _ = strconv.Atoi(probe.Format.BitRate)
+	}
+	return info, nil
+}
@@ -0,0 +1,183 @@
+package assembly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Quality check defaults. Loudness is checked against LoudnessTarget with
+// some tolerance, since a linear loudnorm pass can fall short when the
+// true-peak ceiling limits its gain. Silence counts only pauses of at least
+// qcSilenceMin below qcSilenceFloor, so gaps and beats don't.
+const (
+	QCLoudnessTolerance = 2.0  // LU either side of the target
+	QCMaxTruePeak       = -1.0 // dBTP; Apple's recommended ceiling
+	QCMaxSilence        = 0.10 // fraction of the episode
+
+	qcSilenceFloor = "-50dB"
+	qcSilenceMin   = "1"   // seconds
+	qcFloor        = -99.0 // reported for -inf (digital silence)
+)
+
+// QCBounds are the limits an episode's audio is checked against.
+type QCBounds struct {
+	MinLoudness float64 `json:"min_lufs"`
+	MaxLoudness float64 `json:"max_lufs"`
+	MaxTruePeak float64 `json:"max_true_peak_dbtp"`
+	MaxSilence  float64 `json:"max_silence_ratio"`
+}
+
+// DefaultQCBounds returns the default bounds for an output with channels
+// channels ("1" is mono).
+func DefaultQCBounds(channels string) QCBounds {
+	target := LoudnessTarget(channels)
+	return QCBounds{
+		MinLoudness: target - QCLoudnessTolerance,
+		MaxLoudness: target + QCLoudnessTolerance,
+		MaxTruePeak: QCMaxTruePeak,
+		MaxSilence:  QCMaxSilence,
+	}
+}
+
+// ParseQCBounds returns b with the limits in spec overridden, as in
+// "loudness=-18:-14,peak=-1,silence=0.15" (any subset, comma-separated).
+func ParseQCBounds(spec string, b QCBounds) (QCBounds, error) {
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return QCBounds{}, fmt.Errorf("QC bound %q: want name=value", part)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		switch name {
+		case "loudness":
+			lo, hi, ok := strings.Cut(value, ":")
+			min, err1 := strconv.ParseFloat(lo, 64)
+			max, err2 := strconv.ParseFloat(hi, 64)
+			if !ok || err1 != nil || err2 != nil || min > max {
+				return QCBounds{}, fmt.Errorf("QC loudness %q: want min:max in LUFS, e.g. -18:-14", value)
+			}
+			b.MinLoudness, b.MaxLoudness = min, max
+		case "peak":
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil || v > 0 {
+				return QCBounds{}, fmt.Errorf("QC peak %q: want a ceiling in dBTP, 0 or below", value)
+			}
+			b.MaxTruePeak = v
+		case "silence":
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil || v < 0 || v > 1 {
+				return QCBounds{}, fmt.Errorf("QC silence %q: want a fraction from 0 to 1", value)
+			}
+			b.MaxSilence = v
+		default:
+			return QCBounds{}, fmt.Errorf("unknown QC bound %q: must be loudness, peak, or silence", name)
+		}
+	}
+	return b, nil
+}
+
+// String formats b as ParseQCBounds input.
+func (b QCBounds) String() string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return "loudness=" + f(b.MinLoudness) + ":" + f(b.MaxLoudness) + ",peak=" + f(b.MaxTruePeak) + ",silence=" + f(b.MaxSilence)
+}
+
+// QCReport is an episode's measured loudness, peak, and silence, and the
+// bounds it was checked against.
+type QCReport struct {
+	Integrated   float64  `json:"integrated_lufs"`
+	TruePeak     float64  `json:"true_peak_dbtp"`
+	Range        float64  `json:"loudness_range_lu"`
+	Seconds      float64  `json:"duration_seconds"`
+	Silence      float64  `json:"silence_seconds"`
+	SilenceRatio float64  `json:"silence_ratio"`
+	Bounds       QCBounds `json:"bounds"`
+
+	// Problems describes each bound the episode is outside of; none means
+	// it passed.
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Passed reports whether the episode is within every bound.
+func (r QCReport) Passed() bool {
+	return len(r.Problems) == 0
+}
+
+func (r QCReport) String() string {
+	return fmt.Sprintf("%.1f LUFS, %.1f dBTP peak, %.0f%% silence", r.Integrated, r.TruePeak, r.SilenceRatio*100)
+}
+
+var silenceDurationRE = regexp.MustCompile(`silence_duration: *([0-9.]+)`)
+
+// MeasureQC measures path's integrated loudness, true peak, and silence in
+// one FFmpeg pass and checks them against b.
+func MeasureQC(ctx context.Context, path string, b QCBounds) (QCReport, error) {
+	secs, err := ProbeSeconds(ctx, path)
+	if err != nil {
+		return QCReport{}, err
+	}
+	_, stderr, err := runTool(ctx, "ffmpeg", "quality check", episodeTimeout,
+		"-i", path,
+		"-af", fmt.Sprintf("silencedetect=n=%s:d=%s,loudnorm=print_format=json", qcSilenceFloor, qcSilenceMin),
+		"-f", "null", "-",
+	)
+	if err != nil {
+		return QCReport{}, err
+	}
+	l, err := parseLoudnorm(stderr)
+	if err != nil {
+		return QCReport{}, err
+	}
+
+	r := QCReport{
+		Integrated: finiteDB(l.Integrated),
+		TruePeak:   finiteDB(l.TruePeak),
+		Range:      finiteDB(l.Range),
+		Seconds:    secs,
+		Bounds:     b,
+	}
+	for _, m := range silenceDurationRE.FindAllSubmatch(stderr, -1) {
+		d, _ := strconv.ParseFloat(string(m[1]), 64)
+		r.Silence += d
+	}
+	if secs > 0 {
+		r.SilenceRatio = math.Min(r.Silence/secs, 1)
+	}
+	r.Problems = b.check(r)
+	return r, nil
+}
+
+// check returns the bounds r is outside of.
+func (b QCBounds) check(r QCReport) []string {
+	var problems []string
+	switch {
+	case r.Integrated < b.MinLoudness:
+		problems = append(problems, fmt.Sprintf("too quiet: %.1f LUFS, under %.1f", r.Integrated, b.MinLoudness))
+	case r.Integrated > b.MaxLoudness:
+		problems = append(problems, fmt.Sprintf("too loud: %.1f LUFS, over %.1f", r.Integrated, b.MaxLoudness))
+	}
+	if r.TruePeak > b.MaxTruePeak {
+		problems = append(problems, fmt.Sprintf("may clip: true peak %.1f dBTP, over %.1f", r.TruePeak, b.MaxTruePeak))
+	}
+	if r.SilenceRatio > b.MaxSilence {
+		problems = append(problems, fmt.Sprintf("too much silence: %.0f%% (%.0fs), over %.0f%%", r.SilenceRatio*100, r.Silence, b.MaxSilence*100))
+	}
+	return problems
+}
+
+// finiteDB replaces loudnorm's -inf (for digital silence) with qcFloor, so
+// the report stays comparable and JSON-encodable.
+func finiteDB(v float64) float64 {
+	if math.IsInf(v, -1) || math.IsNaN(v) {
+		return qcFloor
+	}
+	return v
+}
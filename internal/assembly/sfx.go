@@ -0,0 +1,173 @@
+package assembly
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+)
+
+// Sound effects for the script's [SFX:name] markers (--sfx). The library is
+// bundled as code rather than audio files: each effect is synthesized in Go
+// and rendered once per run to PCM WAV in the segments' format
+// (AudioSampleRate, AudioChannels, 16-bit), so it joins the concat list like
+// a silence. Effects peak at sfxLevel, under typical speech; loudnorm evens
+// out the episode afterwards.
+const (
+	sfxLevel = 0.3
+	sfxFade  = 0.01 // seconds of fade-out, so no effect ends in a click
+)
+
+type soundEffect struct {
+	secs   float64
+	render func(rate int, t []float64) []float64 // samples at times t
+}
+
+var sfxLibrary = map[string]soundEffect{
+	"whoosh": {0.7, whoosh},
+	"riser":  {1.5, riser},
+	"chime":  {1.2, chime},
+	"ding":   {0.9, ding},
+	"pop":    {0.12, pop},
+}
+
+// SFXNames returns the bundled effects' names, sorted.
+func SFXNames() []string {
+	names := make([]string, 0, len(sfxLibrary))
+	for name := range sfxLibrary {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// IsSFX reports whether name is a bundled effect.
+func IsSFX(name string) bool {
+	_, ok := sfxLibrary[name]
+	return ok
+}
+
+// SFXSeconds returns the length of the named effect, or 0 if there is none.
+func SFXSeconds(name string) float64 {
+	return sfxLibrary[name].secs
+}
+
+// writeSFX renders the named effect to path as a PCM WAV.
+func writeSFX(name, path string) error {
+	fx, ok := sfxLibrary[name]
+	if !ok {
+		return fmt.Errorf("unknown sound effect %q", name)
+	}
+	rate, _ := strconv.Atoi(AudioSampleRate)
+	channels, _ := strconv.Atoi(AudioChannels)
+	t := make([]float64, int(fx.secs*float64(rate)))
+	for i := range t {
+		t[i] = float64(i) / float64(rate)
+	}
+	samples := fx.render(rate, t)
+
+	// Scale to sfxLevel and fade out the tail.
+	var peak float64
+	for _, v := range samples {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	if peak == 0 {
+		peak = 1
+	}
+	for i := range samples {
+		samples[i] *= sfxLevel / peak * math.Min(1, (fx.secs-t[i])/sfxFade)
+	}
+
+	return writeFile(path, func(w *bufio.Writer) error {
+		w.Write(wavHeader(rate, channels, 16, len(samples)*channels*2))
+		for _, v := range samples {
+			s := int16(math.Round(v * math.MaxInt16))
+			for range channels {
+				binary.Write(w, binary.LittleEndian, s)
+			}
+		}
+		return nil
+	})
+}
+
+// whoosh is noise through a low-pass filter that opens and closes as it
+// swells and fades.
+func whoosh(rate int, t []float64) []float64 {
+	rng := rand.New(rand.NewPCG(1, 2))
+	secs := float64(len(t)) / float64(rate)
+	out := make([]float64, len(t))
+	var lp float64
+	for i, ti := range t {
+		shape := math.Sin(math.Pi * ti / secs)
+		cutoff := 300 + 3700*shape // Hz
+		a := 1 - math.Exp(-2*math.Pi*cutoff/float64(rate))
+		lp += a * (rng.Float64()*2 - 1 - lp)
+		out[i] = lp * shape * shape
+	}
+	return out
+}
+
+// riser is a tone sweeping up two and a half octaves under rising noise,
+// building to a cut.
+func riser(rate int, t []float64) []float64 {
+	rng := rand.New(rand.NewPCG(3, 4))
+	secs := float64(len(t)) / float64(rate)
+	out := make([]float64, len(t))
+	var phase, prev float64
+	for i, ti := range t {
+		x := ti / secs
+		freq := 200 * math.Pow(8, x)
+		phase += 2 * math.Pi * freq / float64(rate)
+		noise := rng.Float64()*2 - 1
+		hiss := noise - prev // first difference: a crude high-pass
+		prev = noise
+		out[i] = (math.Sin(phase) + 0.3*hiss) * x * x
+	}
+	return out
+}
+
+// bell is a struck tone at freq: the fundamental and an inharmonic
+// partial, decaying with time constant decay (seconds) after a 3ms attack.
+// It is silent before start.
+func bell(ti, start, freq, decay float64) float64 {
+	d := ti - start
+	if d < 0 {
+		return 0
+	}
+	env := math.Min(1, d/0.003) * math.Exp(-d/decay)
+	return env * (math.Sin(2*math.Pi*freq*d) + 0.3*math.Sin(2*math.Pi*2.76*freq*d))
+}
+
+// chime is two rising bell notes (B5, then E6).
+func chime(rate int, t []float64) []float64 {
+	out := make([]float64, len(t))
+	for i, ti := range t {
+		out[i] = bell(ti, 0, 987.77, 0.35) + bell(ti, 0.18, 1318.51, 0.4)
+	}
+	return out
+}
+
+// ding is a single bell note (C6).
+func ding(rate int, t []float64) []float64 {
+	out := make([]float64, len(t))
+	for i, ti := range t {
+		out[i] = bell(ti, 0, 1046.5, 0.25)
+	}
+	return out
+}
+
+// pop is a short tone dropping from 900 to 200 Hz.
+func pop(rate int, t []float64) []float64 {
+	secs := float64(len(t)) / float64(rate)
+	out := make([]float64, len(t))
+	var phase float64
+	for i, ti := range t {
+		freq := 900 * math.Pow(200.0/900, ti/secs)
+		phase += 2 * math.Pi * freq / float64(rate)
+		out[i] = math.Min(1, ti/0.001) * math.Exp(-ti/0.03) * math.Sin(phase)
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package assembly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Speech extraction for transcription: the audio track of an audio or video
+// file, downmixed to 16 kHz mono, the rate speech-to-text models work at.
+// Video, music, and stereo are dropped, which keeps the upload a fraction
+// of the source's size.
+
+// speechTimeout bounds one extraction; decoding is much faster than real
+// time, so this covers recordings of several hours.
+const speechTimeout = 30 * time.Minute
+
+// ExtractSpeech writes input's audio as 16 kHz mono into dir and returns
+// the files in order. wav selects 16-bit PCM WAV (whisper.cpp's input),
+// otherwise low-bitrate MP3 for upload. A positive chunk splits the audio
+// into pieces of that length (for APIs with an upload limit); zero writes
+// one file.
+func ExtractSpeech(ctx context.Context, input, dir string, wav bool, chunk time.Duration) ([]string, error) {
+	ext := ".mp3"
+	codec := []string{"-c:a", AudioCodec, "-b:a", "32k"}
+	if wav {
+		ext = ".wav"
+		codec = []string{"-c:a", AudioPCMCodec}
+	}
+	args := []string{"-y", "-i", input, "-vn", "-ac", "1", "-ar", "16000"}
+	args = append(args, codec...)
+	if chunk <= 0 {
+		out := filepath.Join(dir, "speech"+ext)
+		if _, _, err := runTool(ctx, "ffmpeg", "speech extraction", speechTimeout, append(args, out)...); err != nil {
+			return nil, err
+		}
+		return []string{out}, nil
+	}
+
+	args = append(args, "-f", "segment", "-segment_time", strconv.Itoa(int(chunk.Seconds())),
+		"-reset_timestamps", "1", filepath.Join(dir, "speech-%03d"+ext))
+	if _, _, err := runTool(ctx, "ffmpeg", "speech extraction", speechTimeout, args...); err != nil {
+		return nil, err
+	}
+	chunks, err := filepath.Glob(filepath.Join(dir, "speech-*"+ext))
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("no audio track in %s", filepath.Base(input))
+	}
+	sort.Strings(chunks)
+	return chunks, nil
+}
+
+// HasAudioTrack reports whether input has at least one audio stream.
+func HasAudioTrack(ctx context.Context, input string) (bool, error) {
+	if _, err := os.Stat(input); err != nil {
+		return false, err
+	}
+	out, _, err := runTool(ctx, "ffprobe", "audio track probe", probeTimeout,
+		"-v", "error", "-select_streams", "a", "-show_entries", "stream=index", "-of", "csv=p=0", input)
+	if err != nil {
+		return false, err
+	}
+	return len(out) > 0, nil
+}
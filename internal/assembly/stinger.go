@@ -0,0 +1,106 @@
+package assembly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// stingerCrossfade is how long an intro or outro overlaps the episode, in
+// seconds. Stingers shorter than twice this overlap for half their length.
+const stingerCrossfade = 1.5
+
+// Stingers are audio clips joined to the start and end of an episode. Either
+// may be empty.
+type Stingers struct {
+	Intro string
+	Outro string
+}
+
+// IsZero reports whether there is nothing to add.
+func (s Stingers) IsZero() bool {
+	return s.Intro == "" && s.Outro == ""
+}
+
+// ValidateStinger checks that path is a readable file.
+func ValidateStinger(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("stinger %q: %w", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("stinger %q is a directory", path)
+	}
+	return nil
+}
+
+// AddStingers writes output: the episode with s.Intro crossfaded into its
+// start and s.Outro crossfaded out of its end, encoded with enc.
+func AddStingers(ctx context.Context, episode string, s Stingers, output string, enc Encoding) error {
+	if s.IsZero() {
+		return fmt.Errorf("no stingers to add")
+	}
+
+	format := "aresample=" + AudioSampleRate + ",aformat=sample_fmts=fltp:channel_layouts=stereo"
+	var args, filters []string
+	addInput := func(path, label string) {
+		filters = append(filters, fmt.Sprintf("[%d:a]%s[%s]", len(args)/2, format, label))
+		args = append(args, "-i", path)
+	}
+
+	last := "episode"
+	if s.Intro != "" {
+		d, err := crossfadeFor(ctx, s.Intro)
+		if err != nil {
+			return fmt.Errorf("intro: %w", err)
+		}
+		addInput(s.Intro, "intro")
+		addInput(episode, "episode")
+		filters = append(filters, fmt.Sprintf("[intro][episode]acrossfade=d=%s:c1=tri:c2=tri[withintro]", ffFloat(d)))
+		last = "withintro"
+	} else {
+		addInput(episode, "episode")
+	}
+	if s.Outro != "" {
+		d, err := crossfadeFor(ctx, s.Outro)
+		if err != nil {
+			return fmt.Errorf("outro: %w", err)
+		}
+		addInput(s.Outro, "outro")
+		filters = append(filters, fmt.Sprintf("[%s][outro]acrossfade=d=%s:c1=tri:c2=tri[withoutro]", last, ffFloat(d)))
+		last = "withoutro"
+	}
+
+	args = append(args,
+		"-filter_complex", strings.Join(filters, ";"),
+		"-map", "["+last+"]",
+	)
+	args = append(args, FormatOf(output).encodeArgs(enc)...)
+	args = append(args, "-y", output)
+	_, _, err := runTool(ctx, "ffmpeg", "stingers", episodeTimeout, args...)
+	return err
+}
+
+// StingerLead returns how far adding intro shifts the episode's audio
+// later: the clip's length less the crossfade.
+func StingerLead(ctx context.Context, intro string) (float64, error) {
+	secs, err := ProbeSeconds(ctx, intro)
+	if err != nil {
+		return 0, fmt.Errorf("probe stinger: %w", err)
+	}
+	return secs - min(stingerCrossfade, secs/2), nil
+}
+
+// crossfadeFor returns the crossfade length for a stinger: stingerCrossfade,
+// or half the clip if it is shorter than twice that.
+func crossfadeFor(ctx context.Context, path string) (float64, error) {
+	secs, err := ProbeSeconds(ctx, path)
+	if err != nil {
+		return 0, fmt.Errorf("probe stinger: %w", err)
+	}
+	if secs < 0.1 {
+		return 0, fmt.Errorf("stinger %q is too short to crossfade (%.2fs)", path, secs)
+	}
+	return min(stingerCrossfade, secs/2), nil
+}
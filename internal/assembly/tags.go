@@ -0,0 +1,191 @@
+package assembly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultArtist is the artist tag written on every episode.
+const DefaultArtist = "Podcaster"
+
+// The content advisory tag iTunes and Apple Podcasts read from a file
+// (a TXXX frame in ID3), and its values. MP4 carries the same values in
+// its rtng atom, FFmpeg's "rating" key.
+const (
+	AdvisoryTag      = "ITUNESADVISORY"
+	AdvisoryExplicit = "1"
+	AdvisoryClean    = "2"
+
+	mp4AdvisoryKey = "rating"
+)
+
+// advisoryArgs returns the FFmpeg -metadata arguments marking a file in
+// format f explicit or clean.
+func advisoryArgs(f Format, explicit bool) []string {
+	key, value := AdvisoryTag, AdvisoryClean
+	if f == FormatAAC {
+		key = mp4AdvisoryKey
+	}
+	if explicit {
+		value = AdvisoryExplicit
+	}
+	return []string{"-metadata", key + "=" + value}
+}
+
+// AdvisoryOf reads the content advisory from a file's tags (as
+// AudioInfo.Tags, keys lowercased). ok is false if there is none.
+func AdvisoryOf(tags map[string]string) (explicit, ok bool) {
+	for _, key := range []string{strings.ToLower(AdvisoryTag), mp4AdvisoryKey} {
+		switch tags[key] {
+		case AdvisoryExplicit:
+			return true, true
+		case AdvisoryClean:
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// Tags is the ID3 metadata written onto a finished episode. Empty fields
+// are left out.
+type Tags struct {
+	Title   string
+	Artist  string
+	Album   string // show name
+	Comment string // episode summary
+	Date    string // YYYY-MM-DD
+	Cover   string // JPEG or PNG embedded as front cover art
+
+	// Explicit, if set, writes the content advisory (AdvisoryTag).
+	Explicit *bool
+
+	// Chapters are written as ID3 CHAP frames with a CTOC table of
+	// contents, which podcast players show as a chapter list.
+	Chapters []Chapter
+}
+
+// Chapter is a titled span of the episode, in seconds.
+type Chapter struct {
+	Title string
+	Start float64
+	End   float64
+}
+
+// ValidateCover checks that path is a readable JPEG or PNG file, the formats
+// podcast apps display as cover art.
+func ValidateCover(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png":
+	default:
+		return fmt.Errorf("cover art %q must be a .jpg or .png image", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cover art %q: %w", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cover art %q is a directory", path)
+	}
+	return nil
+}
+
+// WriteTags writes output: the episode with its audio stream copied
+// unchanged, t as ID3v2.3 tags (the version Apple Podcasts and most players
+// read most reliably), t.Cover as attached front cover art, and
+// t.Chapters. It runs last, since re-encoding steps don't carry the cover
+// through. Other formats get t in their container's own metadata; the
+// cover and chapters are left out where the container can't carry them
+// (Format.SupportsCover, Format.SupportsChapters).
+func WriteTags(ctx context.Context, episode string, t Tags, output string) error {
+	f := FormatOf(output)
+	args := []string{"-i", episode}
+	cover := -1
+	if t.Cover != "" && f.SupportsCover() {
+		args = append(args, "-i", t.Cover)
+		cover = 1
+	}
+	if len(t.Chapters) > 0 && f.SupportsChapters() {
+		meta, err := writeChapterMetadata(filepath.Dir(output), t.Chapters)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(meta)
+		args = append(args, "-f", "ffmetadata", "-i", meta, "-map_chapters", fmt.Sprint(len(args)/2))
+	} else {
+		args = append(args, "-map_chapters", "-1")
+	}
+	args = append(args, "-map", "0:a", "-map_metadata", "-1")
+	if cover >= 0 {
+		args = append(args,
+			"-map", fmt.Sprintf("%d:v:0", cover),
+			"-disposition:v:0", "attached_pic",
+			"-metadata:s:v:0", "title=Album cover",
+			"-metadata:s:v:0", "comment=Cover (front)",
+		)
+	}
+	args = append(args, "-c", "copy")
+	if f == FormatMP3 {
+		args = append(args, "-id3v2_version", "3", "-write_id3v1", "1")
+	}
+	for _, kv := range [][2]string{
+		{"title", t.Title},
+		{"artist", t.Artist},
+		{"album_artist", t.Artist},
+		{"album", t.Album},
+		{"comment", t.Comment},
+		{"date", t.Date},
+		{"genre", "Podcast"},
+	} {
+		if kv[1] != "" {
+			args = append(args, "-metadata", kv[0]+"="+kv[1])
+		}
+	}
+	if t.Explicit != nil {
+		args = append(args, advisoryArgs(f, *t.Explicit)...)
+	}
+	args = append(args, "-y", output)
+	_, _, err := runTool(ctx, "ffmpeg", "tags", episodeTimeout, args...)
+	return err
+}
+
+// SetAdvisory writes output: episode with its streams, tags, and chapters
+// copied unchanged and the content advisory set to explicit or clean.
+func SetAdvisory(ctx context.Context, episode string, explicit bool, output string) error {
+	f := FormatOf(output)
+	args := []string{"-i", episode, "-map", "0", "-map_metadata", "0", "-c", "copy"}
+	if f == FormatMP3 {
+		args = append(args, "-id3v2_version", "3", "-write_id3v1", "1")
+	}
+	args = append(args, advisoryArgs(f, explicit)...)
+	args = append(args, "-y", output)
+	_, _, err := runTool(ctx, "ffmpeg", "advisory tag", episodeTimeout, args...)
+	return err
+}
+
+// writeChapterMetadata writes chapters as an FFmpeg metadata file in dir
+// and returns its path.
+func writeChapterMetadata(dir string, chapters []Chapter) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(";FFMETADATA1\n")
+	esc := strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", " ")
+	for _, c := range chapters {
+		fmt.Fprintf(&sb, "[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\ntitle=%s\n",
+			int64(c.Start*1000), int64(c.End*1000), esc.Replace(c.Title))
+	}
+	f, err := os.CreateTemp(dir, "chapters-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("create chapter metadata: %w", err)
+	}
+	_, err = f.WriteString(sb.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("write chapter metadata: %w", err)
+	}
+	return f.Name(), nil
+}
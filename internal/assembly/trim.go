@@ -0,0 +1,108 @@
+package assembly
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Silence trimming (--trim-silence). Providers pad segments with silence,
+// Gemini's PCM often by 0.5-1.5s at each end, which the gap between
+// segments then adds to, so conversations drag. normalizeSegments trims
+// each segment's ends down to Keep with silenceremove; the middle of a
+// segment is left alone.
+const (
+	DefaultTrimThreshold = -50.0 // dBFS
+	DefaultTrimKeep      = 50 * time.Millisecond
+
+	MinTrimThreshold = -80.0
+	MaxTrimThreshold = -20.0
+	MinTrimKeep      = 10 * time.Millisecond
+	MaxTrimKeep      = time.Second
+)
+
+// SilenceTrim controls how much leading and trailing silence is cut from
+// each segment. The zero value trims with the defaults.
+type SilenceTrim struct {
+	Off bool
+
+	// Threshold is the level (dBFS) below which audio counts as silence;
+	// 0 means DefaultTrimThreshold.
+	Threshold float64
+
+	// Keep is the silence left at each end; 0 means DefaultTrimKeep.
+	Keep time.Duration
+}
+
+func (t SilenceTrim) threshold() float64 {
+	if t.Threshold == 0 {
+		return DefaultTrimThreshold
+	}
+	return t.Threshold
+}
+
+func (t SilenceTrim) keep() time.Duration {
+	if t.Keep == 0 {
+		return DefaultTrimKeep
+	}
+	return t.Keep
+}
+
+// String returns the trim in the form ParseSilenceTrim accepts.
+func (t SilenceTrim) String() string {
+	if t.Off {
+		return "off"
+	}
+	return fmt.Sprintf("threshold=%s,keep=%s", strconv.FormatFloat(t.threshold(), 'f', -1, 64), t.keep())
+}
+
+// ParseSilenceTrim parses --trim-silence: "" for the defaults, "off", or
+// comma-separated overrides, e.g. "threshold=-45,keep=100ms" (threshold in
+// dBFS, keep a duration).
+func ParseSilenceTrim(s string) (SilenceTrim, error) {
+	var t SilenceTrim
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return t, nil
+	}
+	if s == "off" {
+		return SilenceTrim{Off: true}, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return t, fmt.Errorf("invalid silence trim %q: want key=value", part)
+		}
+		switch strings.TrimSpace(key) {
+		case "threshold":
+			db, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "dB"), 64)
+			if err != nil || db < MinTrimThreshold || db > MaxTrimThreshold {
+				return t, fmt.Errorf("invalid silence trim threshold %q: want %g to %g dBFS", value, MinTrimThreshold, MaxTrimThreshold)
+			}
+			t.Threshold = db
+		case "keep":
+			d, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || d < MinTrimKeep || d > MaxTrimKeep {
+				return t, fmt.Errorf("invalid silence trim keep %q: want %s-%s", value, MinTrimKeep, MaxTrimKeep)
+			}
+			t.Keep = d
+		default:
+			return t, fmt.Errorf("invalid silence trim %q: keys are threshold and keep", key)
+		}
+	}
+	return t, nil
+}
+
+// filter returns the -af filters that trim the segment's ends, or "" when
+// trimming is off. silenceremove only trims the start reliably, so the
+// audio is reversed to trim the end the same way, then restored.
+func (t SilenceTrim) filter() string {
+	if t.Off {
+		return ""
+	}
+	trim := fmt.Sprintf("silenceremove=start_periods=1:start_threshold=%sdB:start_silence=%s",
+		strconv.FormatFloat(t.threshold(), 'f', -1, 64),
+		strconv.FormatFloat(t.keep().Seconds(), 'f', -1, 64))
+	return strings.Join([]string{trim, "areverse", trim, "areverse"}, ",")
+}
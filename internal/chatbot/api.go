@@ -0,0 +1,162 @@
+package chatbot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultAPIURL is the hosted MCP endpoint the bot generates through.
+const DefaultAPIURL = "https://podcasts.apresai.dev/mcp"
+
+// Client calls the hosted generation API: session-less MCP tools/call
+// requests to the proxy, authenticated with a podcaster API key (pk_...).
+type Client struct {
+	url    string
+	apiKey string
+	http   *http.Client
+}
+
+// NewClient creates a client for the MCP endpoint at url.
+func NewClient(url, apiKey string) *Client {
+	return &Client{url: url, apiKey: apiKey, http: &http.Client{Timeout: 60 * time.Second}}
+}
+
+// Podcast is the part of a get_podcast result the bot reports.
+type Podcast struct {
+	ID              string  `json:"podcast_id"`
+	Status          string  `json:"status"`
+	ProgressPercent float64 `json:"progress_percent"`
+	StageMessage    string  `json:"stage_message"`
+	Title           string  `json:"title"`
+	Duration        string  `json:"duration"`
+	AudioURL        string  `json:"audio_url"`
+	TranscriptURL   string  `json:"transcript_url"`
+	Error           string  `json:"error"`
+}
+
+// Done reports whether p has finished, successfully or not.
+func (p Podcast) Done() bool {
+	return p.Status == "complete" || p.Status == "failed"
+}
+
+// Generate starts a generation from sourceURL with an optional preset and
+// returns the podcast ID.
+func (c *Client) Generate(ctx context.Context, sourceURL, preset string) (string, error) {
+	args := map[string]any{"input_url": sourceURL}
+	if preset != "" {
+		args["preset"] = preset
+	}
+	var started Podcast
+	if err := c.callTool(ctx, "generate_podcast", args, &started); err != nil {
+		return "", err
+	}
+	if started.ID == "" {
+		return "", fmt.Errorf("generate_podcast returned no podcast_id")
+	}
+	return started.ID, nil
+}
+
+// Podcast fetches the podcast's status.
+func (c *Client) Podcast(ctx context.Context, id string) (Podcast, error) {
+	var p Podcast
+	err := c.callTool(ctx, "get_podcast", map[string]any{"podcast_id": id}, &p)
+	return p, err
+}
+
+// callTool calls an MCP tool and decodes its JSON text result into out. A
+// tool error is returned as an error carrying the tool's message.
+func (c *Client) callTool(ctx context.Context, name string, args map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": name, "arguments": args},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("%s: read response: %w", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: HTTP %d: %s", name, resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	text, err := toolResultText(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := json.Unmarshal([]byte(text), out); err != nil {
+		return fmt.Errorf("%s: unexpected result %q", name, text)
+	}
+	return nil
+}
+
+// toolResultText returns the text of a tools/call result, which arrives as
+// plain JSON-RPC or an SSE stream carrying it.
+func toolResultText(body []byte) (string, error) {
+	msg := bytes.TrimSpace(body)
+	if len(msg) > 0 && msg[0] != '{' {
+		// SSE: the response is the last data line.
+		msg = nil
+		sc := bufio.NewScanner(bytes.NewReader(body))
+		sc.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+		for sc.Scan() {
+			if data, ok := bytes.CutPrefix(sc.Bytes(), []byte("data:")); ok {
+				msg = append(msg[:0], bytes.TrimSpace(data)...)
+			}
+		}
+	}
+
+	var rpc struct {
+		Result *struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(msg, &rpc); err != nil {
+		return "", fmt.Errorf("parse response: %w", err)
+	}
+	if rpc.Error != nil {
+		return "", fmt.Errorf("%s", rpc.Error.Message)
+	}
+	if rpc.Result == nil {
+		return "", fmt.Errorf("response has no result")
+	}
+	var text string
+	for _, c := range rpc.Result.Content {
+		if c.Type == "text" {
+			text += c.Text
+		}
+	}
+	if rpc.Result.IsError {
+		return "", fmt.Errorf("%s", text)
+	}
+	return text, nil
+}
@@ -0,0 +1,233 @@
+// Package chatbot serves /podcast slash commands for Slack and Discord. A
+// command starts a hosted generation through the MCP API (Client), and the
+// bot follows it, posting progress into a thread under the command's
+// message and the finished episode's audio link at the end:
+//
+//	/podcast https://example.com/article [preset]
+//
+// Handlers answer within the platforms' three-second deadline and do the
+// rest in the background, under the context the Bot was created with.
+package chatbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Polling bounds for following a generation. maxFollow is past the
+// longest (deep) episodes, so a job still running then is reported as
+// stuck rather than followed forever.
+const (
+	pollInterval = 15 * time.Second
+	maxFollow    = 45 * time.Minute
+)
+
+// statusText is what a thread update says for each running job status.
+var statusText = map[string]string{
+	"submitted":    "Queued",
+	"ingesting":    "Reading the source",
+	"scripting":    "Writing the script",
+	"synthesizing": "Recording the voices",
+	"assembling":   "Mixing the episode",
+	"uploading":    "Uploading",
+}
+
+// Thread is where updates about one command are posted: a Slack thread or
+// a Discord thread (or the interaction's follow-ups).
+type Thread interface {
+	Post(ctx context.Context, text string) error
+	// Finish posts the final message, which platforms may also surface in
+	// the channel.
+	Finish(ctx context.Context, text string) error
+}
+
+// Bot runs commands for the platform handlers.
+type Bot struct {
+	ctx  context.Context
+	api  *Client
+	log  *slog.Logger
+	http *http.Client // platform API calls
+}
+
+// NewBot creates a bot generating through api. Background work stops when
+// ctx is cancelled.
+func NewBot(ctx context.Context, api *Client, log *slog.Logger) *Bot {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Bot{ctx: ctx, api: api, log: log, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Command is a parsed /podcast command.
+type Command struct {
+	URL    string
+	Preset string
+}
+
+// ParseCommand parses a /podcast command's text: a URL and an optional
+// preset. Slack's escaped links (<https://...|label>) are unwrapped.
+func ParseCommand(text string) (Command, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Command{}, fmt.Errorf("usage: /podcast <url> [preset]")
+	}
+	raw := strings.TrimSuffix(strings.TrimPrefix(fields[0], "<"), ">")
+	raw, _, _ = strings.Cut(raw, "|")
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Command{}, fmt.Errorf("%q is not an http(s) URL; usage: /podcast <url> [preset]", fields[0])
+	}
+	c := Command{URL: u.String()}
+	if len(fields) == 2 {
+		c.Preset = fields[1]
+	}
+	return c, nil
+}
+
+// Run starts a generation for c and follows it in the background, posting
+// to the thread newThread opens. newThread runs in the background too, so
+// it may wait on the platform (e.g. for the command's message to exist).
+func (b *Bot) Run(c Command, newThread func(ctx context.Context) (Thread, error)) {
+	go func() {
+		ctx := b.ctx
+		t, err := newThread(ctx)
+		if err != nil {
+			b.log.ErrorContext(ctx, "Open thread failed", "url", c.URL, "error", err)
+			return
+		}
+		id, err := b.api.Generate(ctx, c.URL, c.Preset)
+		if err != nil {
+			b.log.WarnContext(ctx, "Generate failed", "url", c.URL, "error", err)
+			b.post(ctx, t.Finish, fmt.Sprintf("Couldn't start the podcast: %v", err))
+			return
+		}
+		b.log.InfoContext(ctx, "Generation started", "podcast_id", id, "url", c.URL, "preset", c.Preset)
+		b.post(ctx, t.Post, fmt.Sprintf("Started podcast `%s`. Generation takes 3-8 minutes; I'll post progress here.", id))
+		b.followPodcast(ctx, id, t)
+	}()
+}
+
+// followPodcast polls the podcast until it is done, posting each status
+// change and then the result.
+func (b *Bot) followPodcast(ctx context.Context, id string, t Thread) {
+	deadline := time.Now().Add(maxFollow)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	var last string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		p, err := b.api.Podcast(ctx, id)
+		if err != nil {
+			// Transient API trouble: try again next tick.
+			b.log.WarnContext(ctx, "Poll failed", "podcast_id", id, "error", err)
+		} else if p.Done() {
+			b.post(ctx, t.Finish, resultText(p))
+			return
+		} else if p.Status != last {
+			last = p.Status
+			b.post(ctx, t.Post, progressText(p))
+		}
+		if time.Now().After(deadline) {
+			b.post(ctx, t.Finish, fmt.Sprintf("Podcast `%s` is still running after %s; check it with get_podcast.", id, maxFollow))
+			return
+		}
+	}
+}
+
+// post sends text with fn, logging failures: a thread that can't be
+// posted to shouldn't stop the generation being followed.
+func (b *Bot) post(ctx context.Context, fn func(context.Context, string) error, text string) {
+	if err := fn(ctx, text); err != nil {
+		b.log.WarnContext(ctx, "Post to thread failed", "error", err)
+	}
+}
+
+// progressText describes a running podcast.
+func progressText(p Podcast) string {
+	text, ok := statusText[p.Status]
+	if !ok {
+		text = p.Status
+	}
+	text = fmt.Sprintf("%s… (%.0f%%)", text, p.ProgressPercent)
+	if p.StageMessage != "" {
+		text += " " + p.StageMessage
+	}
+	return text
+}
+
+// resultText describes a finished podcast.
+func resultText(p Podcast) string {
+	if p.Status == "failed" {
+		msg := p.Error
+		if msg == "" {
+			msg = "unknown error"
+		}
+		return fmt.Sprintf("Podcast `%s` failed: %s", p.ID, msg)
+	}
+	title := p.Title
+	if title == "" {
+		title = "Your podcast"
+	}
+	text := "🎧 " + title
+	if p.Duration != "" {
+		text += " (" + p.Duration + ")"
+	}
+	text += "\n" + p.AudioURL
+	if p.TranscriptURL != "" {
+		text += "\nTranscript: " + p.TranscriptURL
+	}
+	return text
+}
+
+// callPlatform sends a JSON request to a platform API with the given
+// Authorization header and decodes the JSON response into out (if not
+// nil). in is sent as the body unless nil.
+func (b *Bot) callPlatform(ctx context.Context, method, endpoint, auth string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err := b.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: HTTP %d: %s", method, req.URL.Path, resp.StatusCode, bytes.TrimSpace(data))
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
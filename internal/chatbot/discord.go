@@ -0,0 +1,222 @@
+package chatbot
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Discord: the application's interactions endpoint URL is
+// /discord/interactions, and /podcast is registered as a slash command
+// with a required string option "url" and an optional "preset". Requests
+// are verified with the application's Ed25519 public key. The command's
+// reply is the thread's starter: with a bot token (Create Public Threads
+// and Send Messages in Threads permissions) updates go into a thread on it,
+// otherwise they are posted as interaction follow-ups, which Discord
+// accepts for 15 minutes.
+
+const discordAPI = "https://discord.com/api/v10"
+
+// Interaction types and callback types used here.
+const (
+	discordPing               = 1
+	discordApplicationCommand = 2
+
+	discordPong           = 1
+	discordChannelMessage = 4
+	discordEphemeral      = 1 << 6 // message flag
+)
+
+// DiscordHandler serves Discord slash-command interactions.
+type DiscordHandler struct {
+	bot       *Bot
+	publicKey ed25519.PublicKey
+	botToken  string // optional; enables threads
+}
+
+// NewDiscordHandler creates a handler for the application with publicKey
+// (hex, from the developer portal). botToken may be empty.
+func NewDiscordHandler(bot *Bot, publicKey, botToken string) (*DiscordHandler, error) {
+	key, err := hex.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("discord public key must be %d hex-encoded bytes", ed25519.PublicKeySize)
+	}
+	return &DiscordHandler{bot: bot, publicKey: key, botToken: botToken}, nil
+}
+
+// discordInteraction is the part of an interaction the bot reads.
+type discordInteraction struct {
+	Type          int    `json:"type"`
+	ApplicationID string `json:"application_id"`
+	Token         string `json:"token"`
+	ChannelID     string `json:"channel_id"`
+	Data          struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Name  string `json:"name"`
+			Value any    `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+	Member *struct {
+		User discordUser `json:"user"`
+	} `json:"member"`
+	User *discordUser `json:"user"`
+}
+
+type discordUser struct {
+	ID string `json:"id"`
+}
+
+func (h *DiscordHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	// Discord checks that bad signatures are rejected before enabling the
+	// endpoint.
+	if !h.verify(r.Header, body) {
+		http.Error(w, "invalid request signature", http.StatusUnauthorized)
+		return
+	}
+	var in discordInteraction
+	if err := json.Unmarshal(body, &in); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	switch in.Type {
+	case discordPing:
+		discordReply(w, map[string]any{"type": discordPong})
+		return
+	case discordApplicationCommand:
+	default:
+		http.Error(w, "unsupported interaction", http.StatusBadRequest)
+		return
+	}
+
+	var text []string
+	for _, name := range []string{"url", "preset"} {
+		for _, o := range in.Data.Options {
+			if o.Name == name {
+				text = append(text, fmt.Sprint(o.Value))
+			}
+		}
+	}
+	c, err := ParseCommand(strings.Join(text, " "))
+	if err != nil {
+		discordReply(w, map[string]any{
+			"type": discordChannelMessage,
+			"data": map[string]any{"content": err.Error(), "flags": discordEphemeral},
+		})
+		return
+	}
+
+	user := ""
+	if in.Member != nil {
+		user = in.Member.User.ID
+	} else if in.User != nil {
+		user = in.User.ID
+	}
+	content := fmt.Sprintf("<@%s> asked for a podcast of <%s>", user, c.URL)
+	if c.Preset != "" {
+		content += " (" + c.Preset + ")"
+	}
+	h.bot.Run(c, func(ctx context.Context) (Thread, error) {
+		return h.openThread(ctx, in, c)
+	})
+	discordReply(w, map[string]any{
+		"type": discordChannelMessage,
+		"data": map[string]any{"content": content, "allowed_mentions": map[string]any{"parse": []string{}}},
+	})
+}
+
+// verify checks the Ed25519 signature of timestamp + body.
+func (h *DiscordHandler) verify(header http.Header, body []byte) bool {
+	sig, err := hex.DecodeString(header.Get("X-Signature-Ed25519"))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	msg := append([]byte(header.Get("X-Signature-Timestamp")), body...)
+	return ed25519.Verify(h.publicKey, msg, sig)
+}
+
+// discordReply answers the interaction.
+func discordReply(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// openThread starts a thread on the command's reply, or falls back to
+// follow-ups without a bot token or if the thread can't be created.
+func (h *DiscordHandler) openThread(ctx context.Context, in discordInteraction, c Command) (Thread, error) {
+	webhook := discordAPI + "/webhooks/" + url.PathEscape(in.ApplicationID) + "/" + url.PathEscape(in.Token)
+	followUps := &discordThread{h: h, endpoint: webhook}
+	if h.botToken == "" {
+		return followUps, nil
+	}
+
+	// The reply is sent when ServeHTTP returns, which may not have
+	// happened yet.
+	var original struct {
+		ID string `json:"id"`
+	}
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		if err = h.bot.callPlatform(ctx, "GET", webhook+"/messages/@original", "", nil, &original); err == nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+	if err != nil {
+		h.bot.log.WarnContext(ctx, "Discord reply not found, using follow-ups", "error", err)
+		return followUps, nil
+	}
+
+	name := "Podcast"
+	if u, err := url.Parse(c.URL); err == nil {
+		name += ": " + u.Host
+	}
+	var thread struct {
+		ID string `json:"id"`
+	}
+	endpoint := discordAPI + "/channels/" + url.PathEscape(in.ChannelID) + "/messages/" + url.PathEscape(original.ID) + "/threads"
+	req := map[string]any{"name": name, "auto_archive_duration": 1440}
+	if err := h.bot.callPlatform(ctx, "POST", endpoint, "Bot "+h.botToken, req, &thread); err != nil {
+		h.bot.log.WarnContext(ctx, "Discord thread failed, using follow-ups", "error", err)
+		return followUps, nil
+	}
+	return &discordThread{h: h, endpoint: discordAPI + "/channels/" + thread.ID + "/messages", auth: "Bot " + h.botToken}, nil
+}
+
+// discordThread posts to a thread (with the bot token) or as interaction
+// follow-ups (webhook, no auth).
+type discordThread struct {
+	h        *DiscordHandler
+	endpoint string
+	auth     string
+}
+
+func (t *discordThread) Post(ctx context.Context, text string) error {
+	msg := map[string]any{"content": text, "allowed_mentions": map[string]any{"parse": []string{}}}
+	return t.h.bot.callPlatform(ctx, "POST", t.endpoint, t.auth, msg, nil)
+}
+
+func (t *discordThread) Finish(ctx context.Context, text string) error {
+	return t.Post(ctx, text)
+}
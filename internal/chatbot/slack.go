@@ -0,0 +1,154 @@
+package chatbot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Slack: the slash command's request URL is /slack/commands. Requests are
+// verified with the app's signing secret. The bot posts a message for the
+// command with chat.postMessage (so it needs the chat:write scope and to be
+// in the channel), threads the updates under it, and broadcasts the final
+// one to the channel.
+
+const slackPostMessage = "https://slack.com/api/chat.postMessage"
+
+// maxSlackSkew is how old a request's timestamp may be, against replays.
+const maxSlackSkew = 5 * time.Minute
+
+// SlackHandler serves Slack slash commands.
+type SlackHandler struct {
+	bot           *Bot
+	signingSecret string
+	botToken      string // xoxb-...
+}
+
+// NewSlackHandler creates a handler for the app with signingSecret, posting
+// as the bot user with botToken.
+func NewSlackHandler(bot *Bot, signingSecret, botToken string) *SlackHandler {
+	return &SlackHandler{bot: bot, signingSecret: signingSecret, botToken: botToken}
+}
+
+func (h *SlackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if !h.verify(r.Header, body, time.Now()) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	c, err := ParseCommand(form.Get("text"))
+	if err != nil {
+		slackReply(w, err.Error())
+		return
+	}
+	channel, user, responseURL := form.Get("channel_id"), form.Get("user_id"), form.Get("response_url")
+	h.bot.Run(c, func(ctx context.Context) (Thread, error) {
+		t := &slackThread{h: h, channel: channel}
+		text := fmt.Sprintf("<@%s> asked for a podcast of %s", user, c.URL)
+		if c.Preset != "" {
+			text += " (" + c.Preset + ")"
+		}
+		ts, err := t.send(ctx, text, "", false)
+		if err != nil {
+			// Usually not_in_channel: say so to the user privately.
+			h.respond(ctx, responseURL, fmt.Sprintf("I couldn't post in this channel (%v). Invite me with /invite and try again.", err))
+			return nil, err
+		}
+		t.ts = ts
+		return t, nil
+	})
+	slackReply(w, "Starting a podcast from "+c.URL+"…")
+}
+
+// verify checks the request's v0 signature: HMAC-SHA256 of
+// "v0:<timestamp>:<body>" with the signing secret.
+func (h *SlackHandler) verify(header http.Header, body []byte, now time.Time) bool {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	secs, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || math.Abs(now.Sub(time.Unix(secs, 0)).Seconds()) > maxSlackSkew.Seconds() {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(h.signingSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(want), []byte(header.Get("X-Slack-Signature")))
+}
+
+// respond posts an ephemeral message to the command's response_url.
+func (h *SlackHandler) respond(ctx context.Context, responseURL, text string) {
+	if responseURL == "" {
+		return
+	}
+	msg := map[string]string{"response_type": "ephemeral", "text": text}
+	if err := h.bot.callPlatform(ctx, "POST", responseURL, "", msg, nil); err != nil {
+		h.bot.log.WarnContext(ctx, "Slack response_url failed", "error", err)
+	}
+}
+
+// slackReply answers the command itself, visible only to the user.
+func slackReply(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"response_type": "ephemeral", "text": text})
+}
+
+// slackThread is the thread under the bot's message for one command.
+type slackThread struct {
+	h       *SlackHandler
+	channel string
+	ts      string // parent message
+}
+
+func (t *slackThread) Post(ctx context.Context, text string) error {
+	_, err := t.send(ctx, text, t.ts, false)
+	return err
+}
+
+func (t *slackThread) Finish(ctx context.Context, text string) error {
+	_, err := t.send(ctx, text, t.ts, true)
+	return err
+}
+
+// send posts text to the channel, in threadTS's thread if set, and returns
+// the new message's ts.
+func (t *slackThread) send(ctx context.Context, text, threadTS string, broadcast bool) (string, error) {
+	msg := map[string]any{"channel": t.channel, "text": text, "unfurl_links": false}
+	if threadTS != "" {
+		msg["thread_ts"] = threadTS
+		msg["reply_broadcast"] = broadcast
+	}
+	var resp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+	if err := t.h.bot.callPlatform(ctx, "POST", slackPostMessage, "Bearer "+t.h.botToken, msg, &resp); err != nil {
+		return "", err
+	}
+	if !resp.OK {
+		return "", fmt.Errorf("chat.postMessage: %s", resp.Error)
+	}
+	return resp.TS, nil
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/apresai/podcaster/internal/tts"
+	"github.com/spf13/cobra"
+)
+
+const defaultBenchText = "Welcome back to the show. Today we're looking at how a small team shipped a feature " +
+	"that millions of people now use every day, without a single all-nighter. It started with a question: " +
+	"what if we measured everything before we changed anything? The answer surprised all of us, " +
+	"and honestly, it changed how I think about building software."
+
+var (
+	flagBenchProviders string
+	flagBenchText      string
+	flagBenchVoices    []string
+	flagBenchOutput    string
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmark TTS providers on the same sample text",
+	Long: "Synthesize one sample with each provider (its default host 1 voice, or the voices given with --voice) " +
+		"and report latency, estimated cost, duration, and loudness. The samples are saved as MP3s for listening side by side.",
+	Example: "  podcaster bench\n" +
+		"  podcaster bench --providers gemini,elevenlabs,google --text sample.txt\n" +
+		"  podcaster bench --providers gemini --voice gemini:Kore --voice gemini:Puck --voice elevenlabs:JBFqnCBsd6RMkjVDRZzb",
+	Args: cobra.NoArgs,
+	RunE: runBench,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().StringVar(&flagBenchProviders, "providers", "gemini,elevenlabs,google", "Comma-separated TTS providers to benchmark")
+	benchCmd.Flags().StringVar(&flagBenchText, "text", "", "File with the sample text (default: a built-in paragraph)")
+	benchCmd.Flags().StringArrayVar(&flagBenchVoices, "voice", nil, "Voice to benchmark as provider:voiceID (repeatable; replaces that provider's default voice)")
+	benchCmd.Flags().StringVarP(&flagBenchOutput, "output", "o", "", "Directory for the samples (default: podcaster-output/bench/<timestamp>)")
+	addTTSKeyFlags(benchCmd)
+}
+
+// benchTarget is one provider/voice pair to benchmark.
+type benchTarget struct {
+	provider string
+	voiceID  string // "" = provider default
+}
+
+// benchResult is the measured outcome for one target.
+type benchResult struct {
+	benchTarget
+	voice    string
+	latency  time.Duration
+	cost     float64
+	duration float64 // seconds of audio
+	loudness assembly.Loudness
+	file     string
+	err      error
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	text := defaultBenchText
+	if flagBenchText != "" {
+		data, err := os.ReadFile(flagBenchText)
+		if err != nil {
+			return fmt.Errorf("read sample text: %w", err)
+		}
+		text = strings.TrimSpace(string(data))
+		if text == "" {
+			return fmt.Errorf("sample text file %s is empty", flagBenchText)
+		}
+	}
+
+	targets, err := benchTargets(flagBenchProviders, flagBenchVoices)
+	if err != nil {
+		return err
+	}
+	if err := checkFFmpeg(); err != nil {
+		return err
+	}
+
+	dir := flagBenchOutput
+	if dir == "" {
+		dir = filepath.Join(pipeline.OutputBaseDir, "bench", time.Now().Format("20060102-150405"))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	fmt.Printf("Benchmarking %d voice(s) on a %d-character sample\n\n", len(targets), len(text))
+	results := make([]benchResult, 0, len(targets))
+	for _, t := range targets {
+		label := t.provider
+		if t.voiceID != "" {
+			label += ":" + t.voiceID
+		}
+		fmt.Printf("  %-40s", label)
+		r := benchOne(cmd, t, text, dir)
+		if r.err != nil {
+			fmt.Println(" failed")
+		} else {
+			fmt.Printf(" %s\n", r.latency.Round(time.Millisecond))
+		}
+		results = append(results, r)
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tVOICE\tLATENCY\tAUDIO\tCOST\tLUFS\tPEAK\tFILE")
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(w, "%s\t%s\terror: %v\t\t\t\t\t\n", r.provider, r.voice, r.err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.1fs\t$%.5f\t%.1f\t%.1f dBTP\t%s\n",
+			r.provider, r.voice, r.latency.Round(time.Millisecond), r.duration, r.cost,
+			r.loudness.Integrated, r.loudness.TruePeak, filepath.Base(r.file))
+	}
+	w.Flush()
+	fmt.Printf("\nSamples written to %s\n", dir)
+	fmt.Println("Cost is an estimate from list prices; loudness is EBU R128 integrated (podcasts usually target -16 LUFS).")
+	return nil
+}
+
+// benchTargets expands --providers and --voice into the list to benchmark,
+// in flag order. A provider with explicit --voice entries is benchmarked
+// with those voices only.
+func benchTargets(providers string, voices []string) ([]benchTarget, error) {
+	valid := map[string]bool{"elevenlabs": true, "google": true, "gemini": true, "gemini-vertex": true, "vertex-express": true, "polly": true, "cartesia": true, "hume": true, "deepgram": true}
+
+	explicit := map[string][]string{}
+	var order []string
+	for _, spec := range voices {
+		provider, voiceID := tts.ParseVoiceSpec(spec)
+		if provider == "" || voiceID == "" {
+			return nil, fmt.Errorf("invalid --voice %q: use provider:voiceID (e.g. gemini:Kore)", spec)
+		}
+		if _, ok := explicit[provider]; !ok {
+			order = append(order, provider)
+		}
+		explicit[provider] = append(explicit[provider], tts.ResolveVoiceName(provider, voiceID))
+	}
+
+	var targets []benchTarget
+	seen := map[string]bool{}
+	add := func(provider string) {
+		if seen[provider] {
+			return
+		}
+		seen[provider] = true
+		if ids := explicit[provider]; len(ids) > 0 {
+			for _, id := range ids {
+				targets = append(targets, benchTarget{provider: provider, voiceID: id})
+			}
+			return
+		}
+		targets = append(targets, benchTarget{provider: provider})
+	}
+
+	for _, p := range strings.Split(providers, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !valid[p] {
+			return nil, fmt.Errorf("invalid provider %q: must be gemini, gemini-vertex, vertex-express, elevenlabs, google, polly, cartesia, hume, or deepgram", p)
+		}
+		add(p)
+	}
+	for _, p := range order {
+		add(p)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no providers to benchmark")
+	}
+	return targets, nil
+}
+
+// benchOne synthesizes the sample for one target and measures the result.
+// Synthesis is not retried so the latency reflects a single request.
+func benchOne(cmd *cobra.Command, t benchTarget, text, dir string) benchResult {
+	r := benchResult{benchTarget: t, voice: t.voiceID}
+	if err := checkAPIKeys([]string{t.provider}, ""); err != nil {
+		r.err = errors.New(strings.SplitN(err.Error(), "\n", 2)[0])
+		return r
+	}
+
+	provider, err := tts.NewProvider(t.provider, t.voiceID, "", "", tts.ProviderConfig{APIKey: ttsKeyFlag(t.provider)})
+	if err != nil {
+		r.err = err
+		return r
+	}
+	defer provider.Close()
+
+	voice := tts.Voice{ID: t.voiceID, Name: t.voiceID, Provider: t.provider}
+	if voice.ID == "" {
+		voice = provider.DefaultVoices().Host1
+	}
+	r.voice = voice.ID
+
+	start := time.Now()
+	result, err := provider.Synthesize(cmd.Context(), text, voice)
+	r.latency = time.Since(start)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	r.cost = tts.EstimateCost(t.provider, len(text))
+
+	r.file = filepath.Join(dir, t.provider+"-"+sanitizeFileName(voice.ID)+".mp3")
+	if err := writeSample(cmd, result, r.file, assembly.Effects{}); err != nil {
+		r.err = err
+		return r
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+	defer cancel()
+	if r.duration, err = assembly.ProbeSeconds(ctx, r.file); err != nil {
+		r.err = err
+		return r
+	}
+	if r.loudness, err = assembly.MeasureLoudness(ctx, r.file); err != nil {
+		r.err = err
+	}
+	return r
+}
@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apresai/podcaster/internal/ingest"
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/apresai/podcaster/internal/script"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagBenchModelsInput       string
+	flagBenchModelsModels      string
+	flagBenchModelsFormats     string
+	flagBenchModelsDuration    string
+	flagBenchModelsVoices      int
+	flagBenchModelsConcurrency int
+	flagBenchModelsOutput      string
+)
+
+var benchModelsCmd = &cobra.Command{
+	Use:   "bench-models",
+	Short: "Benchmark script models on the same input",
+	Long: "Generate a script from one input with each model (and each --formats entry) in parallel, score every " +
+		"script with the reviewer's heuristic checks (segment count, speaker balance, filler phrases), and print a " +
+		"comparison with token usage and estimated cost. The best model per format is the highest score, then the cheapest. " +
+		"Scripts are saved for reading side by side.",
+	Example: "  podcaster bench-models -i article.md\n" +
+		"  podcaster bench-models -i https://example.com/post --models haiku,gemini-flash --formats conversation,interview,debate",
+	Args: cobra.NoArgs,
+	RunE: runBenchModels,
+}
+
+func init() {
+	rootCmd.AddCommand(benchModelsCmd)
+	benchModelsCmd.Flags().StringVarP(&flagBenchModelsInput, "input", "i", "", "Input source (URL, PDF, or text file)")
+	benchModelsCmd.Flags().StringVar(&flagBenchModelsModels, "models", "haiku,sonnet,gemini-flash,gemini-pro,nova-lite", "Comma-separated script models to compare")
+	benchModelsCmd.Flags().StringVar(&flagBenchModelsFormats, "formats", "conversation", "Comma-separated show formats to generate")
+	benchModelsCmd.Flags().StringVarP(&flagBenchModelsDuration, "duration", "d", "standard", "Target duration: short, standard, long, deep")
+	benchModelsCmd.Flags().IntVar(&flagBenchModelsVoices, "voices", 2, "Number of hosts (1-3)")
+	benchModelsCmd.Flags().IntVar(&flagBenchModelsConcurrency, "concurrency", 4, "Scripts generated at once")
+	benchModelsCmd.Flags().StringVarP(&flagBenchModelsOutput, "output", "o", "", "Directory for the scripts (default: podcaster-output/bench/models-<timestamp>)")
+	benchModelsCmd.MarkFlagRequired("input")
+}
+
+// modelBenchResult is one model/format run.
+type modelBenchResult struct {
+	model   string
+	format  string
+	elapsed time.Duration
+	script  *script.Script
+	issues  []script.ReviewIssue
+	score   int
+	cost    float64
+	file    string
+	err     error
+}
+
+func runBenchModels(cmd *cobra.Command, args []string) error {
+	models, err := splitChoices(flagBenchModelsModels, "model", map[string]bool{"haiku": true, "sonnet": true, "gemini-flash": true, "gemini-pro": true, "nova-lite": true})
+	if err != nil {
+		return err
+	}
+	validFormats := make(map[string]bool)
+	for _, f := range script.FormatNames() {
+		validFormats[f] = true
+	}
+	formats, err := splitChoices(flagBenchModelsFormats, "format", validFormats)
+	if err != nil {
+		return err
+	}
+	switch flagBenchModelsDuration {
+	case "short", "standard", "long", "deep":
+	default:
+		return fmt.Errorf("invalid duration %q: must be short, standard, long, or deep", flagBenchModelsDuration)
+	}
+	if flagBenchModelsVoices < 1 || flagBenchModelsVoices > 3 {
+		return fmt.Errorf("--voices must be 1, 2, or 3 (got %d)", flagBenchModelsVoices)
+	}
+	concurrency := max(flagBenchModelsConcurrency, 1)
+
+	fmt.Printf("Ingesting %s...\n", flagBenchModelsInput)
+	content, err := ingest.NewIngester(flagBenchModelsInput).Ingest(cmd.Context(), flagBenchModelsInput)
+	if err != nil {
+		return fmt.Errorf("ingest: %w", err)
+	}
+	if content.WordCount < ingest.MinWordCount {
+		return fmt.Errorf("input too short (%d words, need at least %d)", content.WordCount, ingest.MinWordCount)
+	}
+
+	dir := flagBenchModelsOutput
+	if dir == "" {
+		dir = filepath.Join(pipeline.OutputBaseDir, "bench", "models-"+time.Now().Format("20060102-150405"))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	var runs []*modelBenchResult
+	for _, format := range formats {
+		for _, model := range models {
+			runs = append(runs, &modelBenchResult{model: model, format: format})
+		}
+	}
+	fmt.Printf("Generating %d script(s) from %d words (%d at a time)...\n", len(runs), content.WordCount, concurrency)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sem := make(chan struct{}, concurrency)
+	for _, r := range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			benchModel(cmd, r, content.Text, dir)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if r.err != nil {
+				fmt.Printf("  %-13s %-13s failed: %v\n", r.model, r.format, r.err)
+			} else {
+				fmt.Printf("  %-13s %-13s done (%s)\n", r.model, r.format, r.elapsed.Round(time.Second))
+			}
+		}()
+	}
+	wg.Wait()
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FORMAT\tMODEL\tTIME\tSEGMENTS\tWORDS\tSCORE\tERRORS\tWARNINGS\tIN TOKENS\tOUT TOKENS\tCOST")
+	for _, r := range runs {
+		if r.err != nil {
+			fmt.Fprintf(w, "%s\t%s\terror\t\t\t\t\t\t\t\t\n", r.format, r.model)
+			continue
+		}
+		stats := script.Stats(r.script)
+		errs, warns := countSeverities(r.issues)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t$%.4f\n",
+			r.format, r.model, r.elapsed.Round(time.Second), stats.Segments, stats.Words, r.score, errs, warns,
+			r.script.Usage.InputTokens, r.script.Usage.OutputTokens, r.cost)
+	}
+	w.Flush()
+
+	fmt.Println("\nBest model per format (highest score, then lowest cost):")
+	for _, format := range formats {
+		var best *modelBenchResult
+		for _, r := range runs {
+			if r.format != format || r.err != nil {
+				continue
+			}
+			if best == nil || r.score > best.score || (r.score == best.score && r.cost < best.cost) {
+				best = r
+			}
+		}
+		if best == nil {
+			fmt.Printf("  %-13s no successful runs\n", format)
+			continue
+		}
+		fmt.Printf("  %-13s %s (score %d, $%.4f)\n", format, best.model, best.score, best.cost)
+	}
+
+	fmt.Printf("\nScripts written to %s\n", dir)
+	fmt.Println("Cost is an estimate from list prices. Scores are heuristic; read the scripts before switching defaults.")
+	return nil
+}
+
+// benchModel generates and scores one script, filling in r.
+func benchModel(cmd *cobra.Command, r *modelBenchResult, content, dir string) {
+	if err := checkAPIKeys(nil, r.model); err != nil {
+		r.err = errors.New(strings.SplitN(err.Error(), "\n", 2)[0])
+		return
+	}
+	gen, err := script.NewGenerator(r.model, "")
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	opts := script.GenerateOptions{
+		Duration: flagBenchModelsDuration,
+		Model:    r.model,
+		Voices:   flagBenchModelsVoices,
+		Format:   r.format,
+	}
+	start := time.Now()
+	s, err := gen.Generate(cmd.Context(), content, opts)
+	r.elapsed = time.Since(start)
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	r.script = s
+	r.issues = script.CheckScript(s, opts.Duration, opts.Voices)
+	r.score = script.ReviewScore(r.issues)
+	r.cost = s.Usage.CostUSD(r.model)
+	r.file = filepath.Join(dir, r.format+"-"+r.model+".json")
+	if err := script.SaveScript(s, r.file); err != nil {
+		r.err = err
+	}
+}
+
+// splitChoices parses a comma-separated flag value, rejecting entries not in
+// valid and dropping duplicates.
+func splitChoices(value, what string, valid map[string]bool) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		if !valid[v] {
+			names := make([]string, 0, len(valid))
+			for n := range valid {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("invalid %s %q: must be one of %s", what, v, strings.Join(names, ", "))
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no %ss given", what)
+	}
+	return out, nil
+}
+
+func countSeverities(issues []script.ReviewIssue) (errs, warns int) {
+	for _, issue := range issues {
+		if issue.Severity == "error" {
+			errs++
+		} else {
+			warns++
+		}
+	}
+	return errs, warns
+}
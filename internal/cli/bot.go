@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/apresai/podcaster/internal/chatbot"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagBotAddr   string
+	flagBotAPIURL string
+)
+
+var botCmd = &cobra.Command{
+	Use:   "bot",
+	Short: "Serve /podcast slash commands for Slack and Discord",
+	Long: "Serve /podcast <url> [preset] for Slack (/slack/commands) and Discord (/discord/interactions). " +
+		"Each command generates on the hosted server with PODCASTER_MCP_API_KEY and posts progress in a " +
+		"thread, then the audio link.\n\n" +
+		"Slack: SLACK_SIGNING_SECRET and SLACK_BOT_TOKEN (chat:write; invite the app to the channel).\n" +
+		"Discord: DISCORD_PUBLIC_KEY, and optionally DISCORD_BOT_TOKEN for threads.\n" +
+		"Either platform may be left unconfigured. The server must be reachable over HTTPS, e.g. behind a reverse proxy.",
+	Args: cobra.NoArgs,
+	RunE: runBot,
+}
+
+func init() {
+	rootCmd.AddCommand(botCmd)
+	botCmd.Flags().StringVar(&flagBotAddr, "addr", ":8080", "Address to listen on")
+	botCmd.Flags().StringVar(&flagBotAPIURL, "api-url", chatbot.DefaultAPIURL, "Hosted MCP endpoint to generate through")
+}
+
+func runBot(cmd *cobra.Command, args []string) error {
+	apiKey := os.Getenv("PODCASTER_MCP_API_KEY")
+	if apiKey == "" {
+		return fmt.Errorf("PODCASTER_MCP_API_KEY is not set (a pk_... key from https://podcasts.apresai.dev)")
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	bot := chatbot.NewBot(ctx, chatbot.NewClient(flagBotAPIURL, apiKey), nil)
+
+	mux := http.NewServeMux()
+	var platforms []string
+	if secret := os.Getenv("SLACK_SIGNING_SECRET"); secret != "" {
+		token := os.Getenv("SLACK_BOT_TOKEN")
+		if token == "" {
+			return fmt.Errorf("SLACK_BOT_TOKEN is required with SLACK_SIGNING_SECRET")
+		}
+		mux.Handle("/slack/commands", chatbot.NewSlackHandler(bot, secret, token))
+		platforms = append(platforms, "Slack at /slack/commands")
+	}
+	if key := os.Getenv("DISCORD_PUBLIC_KEY"); key != "" {
+		h, err := chatbot.NewDiscordHandler(bot, key, os.Getenv("DISCORD_BOT_TOKEN"))
+		if err != nil {
+			return err
+		}
+		mux.Handle("/discord/interactions", h)
+		platforms = append(platforms, "Discord at /discord/interactions")
+	}
+	if len(platforms) == 0 {
+		return fmt.Errorf("no platform configured: set SLACK_SIGNING_SECRET or DISCORD_PUBLIC_KEY")
+	}
+
+	srv := &http.Server{Addr: flagBotAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	for _, p := range platforms {
+		fmt.Printf("Serving %s\n", p)
+	}
+	fmt.Printf("Bot listening on %s\n", flagBotAddr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	fmt.Println("Bot stopped.")
+	return nil
+}
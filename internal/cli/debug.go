@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/ingest"
+	"github.com/apresai/podcaster/internal/pipeline"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagDebugBucket       string
+	flagDebugDir          string
+	flagDebugDownloadOnly bool
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Debug hosted jobs locally",
+}
+
+var debugReplayCmd = &cobra.Command{
+	Use:   "replay <podcast-id> [-- generate flags...]",
+	Short: "Re-run a failed hosted job's failing stage locally",
+	Long: "A failed hosted job keeps a debug bundle in the audio bucket under debug/<id>/: its options, text input, " +
+		"ingested source content, and script, as far as it got. replay downloads the bundle with your AWS credentials " +
+		"and re-runs the failing stage with verbose logging: ingest on its own, script generation from the ingested " +
+		"content (--script-only), or synthesis and assembly from the script. The bundle holds no credentials; " +
+		"providers use your environment's keys or flags after --, and nothing is written back to the hosted service.",
+	Example: "  podcaster debug replay 01JAB3KZ7Q2W8X5V9N4M6R1T0Y\n" +
+		"  podcaster debug replay 01JAB3KZ7Q2W8X5V9N4M6R1T0Y --download-only\n" +
+		"  podcaster debug replay 01JAB3KZ7Q2W8X5V9N4M6R1T0Y -- --tts elevenlabs",
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDebugReplay,
+}
+
+func init() {
+	rootCmd.AddCommand(debugCmd)
+	debugCmd.AddCommand(debugReplayCmd)
+	debugReplayCmd.Flags().StringVar(&flagDebugBucket, "bucket", os.Getenv("S3_BUCKET"), "Audio bucket holding the bundle (default $S3_BUCKET)")
+	debugReplayCmd.Flags().StringVar(&flagDebugDir, "dir", "", "Directory to download the bundle into (default "+pipeline.OutputBaseDir+"/debug/<id>)")
+	debugReplayCmd.Flags().BoolVar(&flagDebugDownloadOnly, "download-only", false, "Download and describe the bundle without re-running anything")
+}
+
+func runDebugReplay(cmd *cobra.Command, args []string) error {
+	if cmd.ArgsLenAtDash() == 0 {
+		return fmt.Errorf("the podcast ID goes before --")
+	}
+	if cmd.ArgsLenAtDash() > 1 || cmd.ArgsLenAtDash() < 0 && len(args) > 1 {
+		return fmt.Errorf("replay takes one podcast ID")
+	}
+	if flagDebugBucket == "" {
+		return fmt.Errorf("no bucket: set --bucket or S3_BUCKET")
+	}
+	id := args[0]
+	dir := flagDebugDir
+	if dir == "" {
+		dir = filepath.Join(pipeline.OutputBaseDir, "debug", id)
+	}
+
+	ctx := cmd.Context()
+	b, err := downloadDebugBundle(ctx, flagDebugBucket, id, dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Job %s failed %s", b.PodcastID, b.FailedAt.Local().Format("2006-01-02 15:04"))
+	if b.Stage != "" {
+		fmt.Printf(" in the %s stage", b.Stage)
+	}
+	fmt.Printf(" (%s):\n  %s\n", b.ErrorKind, b.Error)
+	fmt.Printf("Command: %s\n", b.Command)
+	fmt.Printf("Bundle:  %s (%s)\n", dir, strings.Join(append([]string{pipeline.DebugManifestFile}, b.Files...), ", "))
+	if flagDebugDownloadOnly {
+		return nil
+	}
+
+	if b.Stage == "ingest" || !b.Has(pipeline.DebugContentFile) && !b.Has(pipeline.DebugScriptFile) {
+		return replayIngest(ctx, b, dir)
+	}
+	genArgs, err := b.ReplayArgs(dir)
+	if err != nil {
+		return err
+	}
+	if err := generateCmd.ParseFlags(genArgs); err != nil {
+		return fmt.Errorf("job %s's flags: %w", id, err)
+	}
+	if err := checkGenerateArgs(args[1:], "changed when replaying; the bundle sets them"); err != nil {
+		return err
+	}
+	fmt.Printf("\nReplaying: podcaster generate %s\n\n", strings.Join(genArgs, " "))
+	generateCmd.SetContext(ctx)
+	return runGenerate(generateCmd, nil)
+}
+
+// downloadDebugBundle downloads podcast id's debug bundle from bucket into
+// dir and returns its manifest.
+func downloadDebugBundle(ctx context.Context, bucket, id, dir string) (*pipeline.DebugBundle, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(cfg)
+	prefix := pipeline.DebugPrefix(id)
+	get := func(name string) ([]byte, error) {
+		key := prefix + name
+		out, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: &bucket, Key: &key})
+		if err != nil {
+			return nil, fmt.Errorf("download s3://%s/%s: %w", bucket, key, err)
+		}
+		defer out.Body.Close()
+		return io.ReadAll(out.Body)
+	}
+
+	data, err := get(pipeline.DebugManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("no debug bundle for %s (jobs keep one only when they fail): %w", id, err)
+	}
+	var b pipeline.DebugBundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse %s's debug manifest: %w", id, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, pipeline.DebugManifestFile), data, 0644); err != nil {
+		return nil, err
+	}
+	for _, name := range b.Files {
+		data, err := get(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return &b, nil
+}
+
+// replayIngest re-ingests a job's input, reporting what came out and
+// saving it as the bundle's content for a script replay.
+func replayIngest(ctx context.Context, b *pipeline.DebugBundle, dir string) error {
+	input := b.Input
+	if input == pipeline.DebugInputFile {
+		input = filepath.Join(dir, pipeline.DebugInputFile)
+	}
+	if input == "" {
+		return fmt.Errorf("job %s's bundle has no input to replay", b.PodcastID)
+	}
+	fmt.Printf("\nReplaying ingest of %s (%s)\n", input, ingest.DetectSource(input))
+	start := time.Now()
+	content, err := ingest.NewIngester(input).Ingest(ctx, input)
+	if err != nil {
+		fmt.Printf("Ingest failed after %s\n", time.Since(start).Round(time.Millisecond))
+		return err
+	}
+	fmt.Printf("Ingested %d words in %s\n  Title:  %s\n  Source: %s\n", content.WordCount,
+		time.Since(start).Round(time.Millisecond), content.Title, content.Source)
+	path := filepath.Join(dir, pipeline.DebugContentFile)
+	if err := os.WriteFile(path, []byte(content.Text), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("  Saved to %s\n", path)
+	if content.WordCount < ingest.MinWordCount {
+		return fmt.Errorf("input too short (%d words, need at least %d)", content.WordCount, ingest.MinWordCount)
+	}
+	fmt.Println("Ingest succeeds locally; the failure may depend on the server's network (blocking, rate limits).")
+	return nil
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apresai/podcaster/internal/tts"
+	"github.com/spf13/cobra"
+)
+
+const anthropicModelsURL = "https://api.anthropic.com/v1/models?limit=1"
+
+var flagDoctorProviders string
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check tools, API keys, and TTS provider access before a long run",
+	Long: "Verify that ffmpeg and ffprobe are installed, that the Anthropic key works, and that each TTS provider " +
+		"accepts its credentials, using free calls (model lookups, token counts, voice lists) that synthesize nothing. " +
+		"Providers without credentials are skipped unless named in --providers. Exits non-zero if any check fails.",
+	Example: "  podcaster doctor\n" +
+		"  podcaster doctor --providers gemini,elevenlabs",
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringVar(&flagDoctorProviders, "providers", "", "Comma-separated TTS providers to check (default: all, skipping unconfigured ones)")
+	doctorCmd.Flags().StringVar(&flagAnthropicAPIKey, "anthropic-api-key", "", "Anthropic API key (overrides ANTHROPIC_API_KEY env var)")
+	addTTSKeyFlags(doctorCmd)
+}
+
+// doctorCheck is one line of the doctor report.
+type doctorCheck struct {
+	name    string
+	status  string // ok, FAIL, skip
+	latency time.Duration
+	detail  string
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	providers := tts.ProviderNames
+	explicit := flagDoctorProviders != ""
+	if explicit {
+		providers = nil
+		for _, p := range strings.Split(flagDoctorProviders, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				providers = append(providers, p)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+	defer cancel()
+
+	checks := []doctorCheck{
+		checkTool(ctx, "ffmpeg"),
+		checkTool(ctx, "ffprobe"),
+		checkAnthropicKey(ctx),
+	}
+
+	// Provider checks are independent network calls; run them together.
+	results := make([]tts.Health, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = tts.CheckHealth(ctx, p, tts.ProviderConfig{APIKey: ttsKeyFlag(p)})
+		}()
+	}
+	wg.Wait()
+	for _, h := range results {
+		c := doctorCheck{name: "tts:" + h.Provider, status: "ok", latency: h.Latency, detail: h.Detail}
+		switch {
+		case h.Err != nil:
+			c.status = "FAIL"
+			c.detail = strings.TrimPrefix(c.detail+"; "+h.Err.Error(), "; ")
+		case !h.Configured:
+			c.status, c.latency = "skip", 0
+			if explicit {
+				c.status = "FAIL"
+			}
+		}
+		checks = append(checks, c)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tLATENCY\tDETAIL")
+	failed := 0
+	for _, c := range checks {
+		latency := ""
+		if c.latency > 0 {
+			latency = c.latency.Round(time.Millisecond).String()
+		}
+		if c.status == "FAIL" {
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.name, c.status, latency, strings.ReplaceAll(c.detail, "\n", "; "))
+	}
+	w.Flush()
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	fmt.Println("\nAll checks passed.")
+	return nil
+}
+
+// checkTool verifies an executable is on PATH and reports its version line.
+func checkTool(ctx context.Context, name string) doctorCheck {
+	c := doctorCheck{name: name, status: "ok"}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		c.status, c.detail = "FAIL", "not found on PATH (install with: brew install ffmpeg)"
+		return c
+	}
+	start := time.Now()
+	out, err := exec.CommandContext(ctx, path, "-version").Output()
+	c.latency = time.Since(start)
+	if err != nil {
+		c.status, c.detail = "FAIL", fmt.Sprintf("%s -version: %v", path, err)
+		return c
+	}
+	c.detail, _, _ = strings.Cut(string(out), "\n")
+	return c
+}
+
+// checkAnthropicKey lists one model with the Anthropic key, which is free.
+// A missing key is skipped: only the haiku and sonnet script models need it.
+func checkAnthropicKey(ctx context.Context) doctorCheck {
+	c := doctorCheck{name: "anthropic", status: "ok", detail: "key valid"}
+	apiKey := flagAnthropicAPIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("ANTHROPIC_API_KEY")
+	}
+	if apiKey == "" {
+		c.status, c.detail = "skip", "ANTHROPIC_API_KEY not set"
+		return c
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, anthropicModelsURL, nil)
+	if err != nil {
+		c.status, c.detail = "FAIL", err.Error()
+		return c
+	}
+	req.Header.Set("x-api-key", apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	start := time.Now()
+	res, err := (&http.Client{Timeout: 15 * time.Second}).Do(req)
+	c.latency = time.Since(start)
+	if err != nil {
+		c.status, c.detail = "FAIL", err.Error()
+		return c
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 200))
+		c.status, c.detail = "FAIL", fmt.Sprintf("status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return c
+}
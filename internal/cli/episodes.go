@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/spf13/cobra"
+)
+
+var flagEpisodesLimit int
+
+var episodesCmd = &cobra.Command{
+	Use:   "episodes",
+	Short: "List generated episodes and the commands that made them",
+	Long: "Every successful generate run is recorded in " + pipeline.OutputBaseDir + "/" + pipeline.HistoryFile +
+		" with the command that reproduces it. With no subcommand, lists the most recent episodes.",
+	Args: cobra.NoArgs,
+	RunE: runEpisodesList,
+}
+
+var episodesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recent episodes, newest first",
+	Args:  cobra.NoArgs,
+	RunE:  runEpisodesList,
+}
+
+var episodesReproCmd = &cobra.Command{
+	Use:   "repro <id>",
+	Short: "Print the command that regenerates an episode",
+	Long: "Print the generate command recorded for an episode (by ID, ID prefix, or file name). Running it " +
+		"repeats the episode's options exactly; the script will differ unless it used --from-script, since " +
+		"script generation isn't deterministic.",
+	Example: "  podcaster episodes repro the-future-of-batteries-20261016-0930\n" +
+		"  eval \"$(podcaster episodes repro the-future-of-batteries)\"",
+	Args: cobra.ExactArgs(1),
+	RunE: runEpisodesRepro,
+}
+
+func init() {
+	rootCmd.AddCommand(episodesCmd)
+	episodesCmd.AddCommand(episodesListCmd, episodesReproCmd)
+	for _, c := range []*cobra.Command{episodesCmd, episodesListCmd} {
+		c.Flags().IntVar(&flagEpisodesLimit, "limit", 20, "Maximum number of episodes to list (0 = all)")
+	}
+}
+
+func runEpisodesList(cmd *cobra.Command, args []string) error {
+	entries, err := pipeline.LoadHistory()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No episodes recorded yet (%s/%s).\n", pipeline.OutputBaseDir, pipeline.HistoryFile)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCREATED\tTITLE")
+	for i, n := len(entries)-1, 0; i >= 0 && (flagEpisodesLimit <= 0 || n < flagEpisodesLimit); i, n = i-1, n+1 {
+		e := entries[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\n", e.ID, e.CreatedAt.Local().Format("2006-01-02 15:04"), e.Title)
+	}
+	w.Flush()
+	fmt.Println("\nRegenerate one with: podcaster episodes repro <id>")
+	return nil
+}
+
+func runEpisodesRepro(cmd *cobra.Command, args []string) error {
+	entries, err := pipeline.LoadHistory()
+	if err != nil {
+		return err
+	}
+	e, err := pipeline.FindHistory(entries, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(e.Command)
+	return nil
+}
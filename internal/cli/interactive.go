@@ -166,24 +166,38 @@ func parseFloat(s string) float64 {
 	return f
 }
 
-// buildVoiceOptionsForProvider returns voice options filtered by provider.
-// If provider is "auto" or empty, returns all providers with prefixes.
+// pickerVoices returns the provider's voices that speak --language. If none
+// do, it returns them all rather than leave the picker empty.
+func pickerVoices(provider string) ([]tts.VoiceInfo, error) {
+	voices, err := tts.AvailableVoices(provider)
+	if err != nil {
+		return nil, err
+	}
+	if filtered := tts.FilterVoicesByLanguage(voices, flagLanguage); len(filtered) > 0 {
+		return filtered, nil
+	}
+	return voices, nil
+}
+
+// buildVoiceOptionsForProvider returns voice options filtered by provider
+// and --language. If provider is "auto" or empty, returns all providers
+// with prefixes.
 func buildVoiceOptionsForProvider(provider string) (opts []menuOption, defaultV1, defaultV2, defaultV3 string) {
 	if provider == "auto" || provider == "" {
 		return buildAllVoiceOptions()
 	}
 
 	// Single provider — no prefix needed
-	voices, err := tts.AvailableVoices(provider)
+	voices, err := pickerVoices(provider)
 	if err != nil {
 		return buildAllVoiceOptions()
 	}
 
-	prefixMap := map[string]string{"gemini": "GEM", "elevenlabs": "ELV", "google": "GOO", "polly": "POL"}
+	prefixMap := map[string]string{"gemini": "GEM", "elevenlabs": "ELV", "google": "GOO", "polly": "POL", "cartesia": "CAR", "hume": "HUM", "deepgram": "DPG"}
 	prefix := prefixMap[provider]
 
 	for _, v := range voices {
-		label := fmt.Sprintf("[%s] %s - %s (%s)", prefix, v.Name, v.Description, v.Gender)
+		label := fmt.Sprintf("[%s] %s - %s (%s)", prefix, v.Name, v.Summary(), v.Gender)
 		value := provider + ":" + v.ID
 		opts = append(opts, menuOption{label: label, value: value})
 		if v.DefaultFor == "Voice 1" {
@@ -196,9 +210,35 @@ func buildVoiceOptionsForProvider(provider string) (opts []menuOption, defaultV1
 			defaultV3 = value
 		}
 	}
+	if flagLanguage != "" {
+		// A default filtered out by --language would fall back to the
+		// provider's default voice, which may not speak the language.
+		defaultV1, defaultV2, defaultV3 = fillVoiceDefaults(opts, defaultV1, defaultV2, defaultV3)
+	}
 	return
 }
 
+// fillVoiceDefaults sets each empty default to the first option not already
+// a default.
+func fillVoiceDefaults(opts []menuOption, defaults ...string) (v1, v2, v3 string) {
+	used := map[string]bool{}
+	for _, d := range defaults {
+		used[d] = true
+	}
+	for i, d := range defaults {
+		if d != "" {
+			continue
+		}
+		for _, o := range opts {
+			if !used[o.value] {
+				defaults[i], used[o.value] = o.value, true
+				break
+			}
+		}
+	}
+	return defaults[0], defaults[1], defaults[2]
+}
+
 // buildAllVoiceOptions returns voice options from all TTS providers, grouped
 // by provider with [GEM]/[ELV]/[GOO] prefixes. Values use provider:voiceID format.
 func buildAllVoiceOptions() (opts []menuOption, defaultV1, defaultV2, defaultV3 string) {
@@ -211,6 +251,9 @@ func buildAllVoiceOptions() (opts []menuOption, defaultV1, defaultV2, defaultV3
 		{"elevenlabs", "ELV"},
 		{"google", "GOO"},
 		{"polly", "POL"},
+		{"cartesia", "CAR"},
+		{"hume", "HUM"},
+		{"deepgram", "DPG"},
 	}
 
 	effectiveTTS := flagTTS
@@ -223,8 +266,8 @@ func buildAllVoiceOptions() (opts []menuOption, defaultV1, defaultV2, defaultV3
 		if err != nil {
 			continue
 		}
-		for _, v := range voices {
-			label := fmt.Sprintf("[%s] %s - %s (%s)", p.prefix, v.Name, v.Description, v.Gender)
+		for _, v := range tts.FilterVoicesByLanguage(voices, flagLanguage) {
+			label := fmt.Sprintf("[%s] %s - %s (%s)", p.prefix, v.Name, v.Summary(), v.Gender)
 			value := p.name + ":" + v.ID
 			opts = append(opts, menuOption{label: label, value: value})
 			if v.DefaultFor == "Voice 1" && p.name == effectiveTTS {
@@ -268,6 +311,22 @@ func ttsModelOptions(provider string) []menuOption {
 		return []menuOption{
 			{label: "Generative (fixed)", value: ""},
 		}
+	case "cartesia":
+		return []menuOption{
+			{label: "Sonic 2 (best quality) (default)", value: "sonic-2"},
+			{label: "Sonic Turbo (lowest latency)", value: "sonic-turbo"},
+			{label: "Sonic (original)", value: "sonic"},
+		}
+	case "hume":
+		return []menuOption{
+			{label: "Octave 1 (acting instructions) (default)", value: "octave-1"},
+			{label: "Octave 2 (faster, multilingual)", value: "octave-2"},
+		}
+	case "deepgram":
+		return []menuOption{
+			{label: "Aura 2 (natural, more voices) (default)", value: "aura-2"},
+			{label: "Aura (cheapest)", value: "aura"},
+		}
 	default:
 		return []menuOption{
 			{label: "Chirp 3 HD (fixed)", value: ""},
@@ -287,6 +346,12 @@ func defaultTTSModel(provider string) string {
 		return "gemini-2.5-pro-preview-tts"
 	case "gemini-vertex", "vertex-express":
 		return "gemini-2.5-flash-tts"
+	case "cartesia":
+		return "sonic-2"
+	case "hume":
+		return "octave-1"
+	case "deepgram":
+		return "aura-2"
 	default:
 		return ""
 	}
@@ -506,6 +571,9 @@ func buildMenuItems(voiceCount int) []menuItem {
 			{label: "ElevenLabs (premium voices)", value: "elevenlabs"},
 			{label: "Google Cloud TTS (Chirp 3 HD)", value: "google"},
 			{label: "AWS Polly (Generative voices)", value: "polly"},
+			{label: "Cartesia (Sonic, low latency)", value: "cartesia"},
+			{label: "Hume AI (Octave, emotionally expressive)", value: "hume"},
+			{label: "Deepgram (Aura, low cost for long episodes)", value: "deepgram"},
 		},
 	})
 
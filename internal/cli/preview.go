@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/apresai/podcaster/internal/tts"
+	"github.com/spf13/cobra"
+)
+
+const defaultPreviewText = "Welcome back to the show! Today we're digging into something I've been curious about for a while. Ready? Let's get into it."
+
+var (
+	flagPreviewTTS       string
+	flagPreviewText      string
+	flagPreviewOutput    string
+	flagPreviewTTSModel  string
+	flagPreviewSpeed     float64
+	flagPreviewStability float64
+	flagPreviewPitch     float64
+	flagPreviewAll       bool
+)
+
+var previewVoiceCmd = &cobra.Command{
+	Use:   "preview-voice <provider:voiceID | voiceID>[@key=value,...]",
+	Short: "Synthesize a short sample to audition a voice",
+	Long: "Synthesize a sample sentence with one voice and play it (afplay on macOS, ffplay elsewhere), " +
+		"or write it to a file with --output (MP3 unless its extension names another format). Voices use the same provider:voiceID format as --voice1; " +
+		"a plain voice ID uses the --tts provider. With --all, every voice in the --tts provider's catalog " +
+		"is written to <output>/<provider>/<voice>.mp3, the layout list_voices expects under " + tts.VoiceSamplesURLEnv + ".",
+	Example: "  podcaster preview-voice gemini:Kore\n" +
+		"  podcaster preview-voice elevenlabs:rachel@stability=0.3 --text \"Testing, one two three.\"\n" +
+		"  podcaster preview-voice Puck --tts vertex-express -o puck.mp3\n" +
+		"  podcaster preview-voice --all --tts deepgram -o samples",
+	Args: cobra.RangeArgs(0, 1),
+	RunE: runPreviewVoice,
+}
+
+func init() {
+	rootCmd.AddCommand(previewVoiceCmd)
+	previewVoiceCmd.Flags().StringVarP(&flagPreviewTTS, "tts", "T", "gemini", "TTS provider for a voice given without a provider: prefix")
+	previewVoiceCmd.Flags().StringVar(&flagPreviewText, "text", defaultPreviewText, "Sample text to speak")
+	previewVoiceCmd.Flags().StringVarP(&flagPreviewOutput, "output", "o", "", "Write the sample to this file instead of playing it (MP3, or .m4a/.opus/.wav by extension)")
+	previewVoiceCmd.Flags().StringVar(&flagPreviewTTSModel, "tts-model", "", "TTS model ID (e.g., eleven_v3, gemini-2.5-flash-preview-tts)")
+	previewVoiceCmd.Flags().Float64Var(&flagPreviewSpeed, "tts-speed", 0, "Speech speed (ElevenLabs: 0.7-1.2, Google: 0.25-2.0, others: 0.5-2.0 applied with FFmpeg)")
+	previewVoiceCmd.Flags().Float64Var(&flagPreviewStability, "tts-stability", 0, "Voice stability, ElevenLabs only (0.0-1.0)")
+	previewVoiceCmd.Flags().Float64Var(&flagPreviewPitch, "tts-pitch", 0, "Pitch adjustment in semitones (Google: -20.0 to 20.0, others: -12.0 to 12.0 applied with FFmpeg)")
+	previewVoiceCmd.Flags().BoolVar(&flagPreviewAll, "all", false, "Write a sample of every voice in the --tts catalog under the --output directory")
+	addTTSKeyFlags(previewVoiceCmd)
+}
+
+func runPreviewVoice(cmd *cobra.Command, args []string) error {
+	if flagPreviewAll {
+		if len(args) != 0 || flagPreviewOutput == "" {
+			return fmt.Errorf("--all takes no voice argument and needs --output <directory>")
+		}
+		return writeAllSamples(cmd)
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("a voice is required (or --all)")
+	}
+	spec, settings, err := tts.ParseVoiceSettings(args[0])
+	if err != nil {
+		return err
+	}
+	providerName, voiceID := tts.ParseVoiceSpec(spec)
+	if providerName == "" {
+		providerName = flagPreviewTTS
+	}
+	if err := settings.Validate(providerName); err != nil {
+		return err
+	}
+	voiceID = tts.ResolveVoiceName(providerName, voiceID)
+	if voiceID == "" {
+		return fmt.Errorf("voice ID is required")
+	}
+	if strings.TrimSpace(flagPreviewText) == "" {
+		return fmt.Errorf("--text must not be empty")
+	}
+
+	if err := checkAPIKeys([]string{providerName}, ""); err != nil {
+		return err
+	}
+	if flagPreviewTTSModel != "" {
+		if err := tts.ValidateModel(providerName, flagPreviewTTSModel); err != nil {
+			return err
+		}
+	}
+
+	cfg := tts.ProviderConfig{
+		Model:     flagPreviewTTSModel,
+		Speed:     flagPreviewSpeed,
+		Stability: flagPreviewStability,
+		Pitch:     flagPreviewPitch,
+		APIKey:    ttsKeyFlag(providerName),
+	}
+	provider, err := tts.NewProvider(providerName, voiceID, "", "", cfg)
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	voice := tts.Voice{ID: voiceID, Name: voiceID, Provider: providerName, Settings: settings}
+
+	fmt.Printf("Synthesizing sample with %s:%s...", providerName, voice.ID)
+	start := time.Now()
+	var result tts.AudioResult
+	err = tts.WithRetry(cmd.Context(), func() error {
+		var err error
+		result, err = provider.Synthesize(cmd.Context(), flagPreviewText, voice)
+		return err
+	})
+	if err != nil {
+		fmt.Println(" failed")
+		return fmt.Errorf("synthesize sample: %w", err)
+	}
+	fmt.Printf(" done (%s)\n", time.Since(start).Round(time.Millisecond))
+
+	out := flagPreviewOutput
+	if out == "" {
+		dir := filepath.Join(pipeline.OutputBaseDir, "previews")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create previews directory: %w", err)
+		}
+		out = filepath.Join(dir, providerName+"-"+sanitizeFileName(voice.ID)+".mp3")
+	}
+	speed, pitch := tts.Emulated(providerName, cfg.ForVoice(voice))
+	if err := writeSample(cmd, result, out, assembly.Effects{Speed: speed, Pitch: pitch}); err != nil {
+		return err
+	}
+
+	if flagPreviewOutput != "" {
+		fmt.Printf("Sample written to %s\n", out)
+		return nil
+	}
+
+	player, playerArgs := audioPlayer()
+	if player == "" {
+		fmt.Printf("No audio player found (afplay or ffplay); sample saved to %s\n", out)
+		return nil
+	}
+	fmt.Printf("Playing %s (saved to %s)\n", voice.ID, out)
+	play := exec.CommandContext(cmd.Context(), player, append(playerArgs, out)...)
+	play.Stderr = os.Stderr
+	if err := play.Run(); err != nil {
+		return fmt.Errorf("play sample with %s: %w", player, err)
+	}
+	return nil
+}
+
+// writeAllSamples writes a sample of every voice in the --tts provider's
+// catalog to <output>/<provider>/<tts.SampleFileName>, continuing past
+// failures.
+func writeAllSamples(cmd *cobra.Command) error {
+	providerName := flagPreviewTTS
+	if err := checkAPIKeys([]string{providerName}, ""); err != nil {
+		return err
+	}
+	voices, err := tts.AvailableVoices(providerName)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(flagPreviewOutput, providerName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create samples directory: %w", err)
+	}
+
+	cfg := tts.ProviderConfig{Model: flagPreviewTTSModel, APIKey: ttsKeyFlag(providerName)}
+	var failed int
+	for _, v := range voices {
+		out := filepath.Join(dir, tts.SampleFileName(v.ID))
+		fmt.Printf("  %-28s", v.ID)
+		if err := synthesizeSample(cmd, providerName, tts.Voice{ID: v.ID, Name: v.Name, Provider: providerName}, cfg, out); err != nil {
+			fmt.Printf(" failed: %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf(" %s\n", out)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d samples failed", failed, len(voices))
+	}
+	return nil
+}
+
+// synthesizeSample synthesizes the sample text with voice and writes it to
+// out.
+func synthesizeSample(cmd *cobra.Command, providerName string, voice tts.Voice, cfg tts.ProviderConfig, out string) error {
+	provider, err := tts.NewProvider(providerName, voice.ID, "", "", cfg)
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	var result tts.AudioResult
+	err = tts.WithRetry(cmd.Context(), func() error {
+		var err error
+		result, err = provider.Synthesize(cmd.Context(), flagPreviewText, voice)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("synthesize sample: %w", err)
+	}
+	speed, pitch := tts.Emulated(providerName, cfg.ForVoice(voice))
+	return writeSample(cmd, result, out, assembly.Effects{Speed: speed, Pitch: pitch})
+}
+
+// writeSample writes a synthesis result to path in the format its extension
+// names (MP3 unless it is .m4a, .opus, or .wav), converting with FFmpeg
+// unless the provider returned MP3 for an MP3 path and fx is zero.
+func writeSample(cmd *cobra.Command, result tts.AudioResult, path string, fx assembly.Effects) error {
+	if result.Format == tts.FormatMP3 && assembly.FormatOf(path) == assembly.FormatMP3 && fx.IsZero() {
+		if err := os.WriteFile(path, result.Data, 0644); err != nil {
+			return fmt.Errorf("write sample: %w", err)
+		}
+		return nil
+	}
+
+	if err := checkFFmpeg(); err != nil {
+		return err
+	}
+	raw, err := os.CreateTemp("", "podcaster-preview-*."+string(result.Format))
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(raw.Name())
+	if _, err := raw.Write(result.Data); err != nil {
+		raw.Close()
+		return fmt.Errorf("write raw sample: %w", err)
+	}
+	raw.Close()
+
+	if err := assembly.ConvertWithEffects(cmd.Context(), raw.Name(), string(result.Format), path, fx, assembly.Encoding{}); err != nil {
+		return fmt.Errorf("convert sample: %w", err)
+	}
+	return nil
+}
+
+// audioPlayer returns a command-line player for the current platform, or ""
+// if none is installed.
+func audioPlayer() (string, []string) {
+	if runtime.GOOS == "darwin" {
+		if _, err := exec.LookPath("afplay"); err == nil {
+			return "afplay", nil
+		}
+	}
+	if _, err := exec.LookPath("ffplay"); err == nil {
+		return "ffplay", []string{"-nodisp", "-autoexit", "-loglevel", "error"}
+	}
+	return "", nil
+}
+
+// sanitizeFileName replaces characters that are awkward in file names.
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/anthropics/anthropic-sdk-go"
 	sdk "github.com/apresai/apresai.dev/sdk"
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/itunes"
 	"github.com/apresai/podcaster/internal/pipeline"
 	"github.com/apresai/podcaster/internal/script"
 	"github.com/spf13/cobra"
@@ -23,14 +25,20 @@ var (
 	flagPublishOwner     string
 	flagPublishSourceURL string
 	flagPublishAPIURL    string
+	flagPublishExplicit  bool
+	flagPublishCheck     bool
+	flagPublishNoCheck   bool
 )
 
 var publishCmd = &cobra.Command{
 	Use:   "publish <mp3-file>",
 	Short: "Publish a podcast episode to the Apres AI platform",
-	Long:  "Upload an MP3 file and publish it to apresai.dev. Metadata is auto-detected from the companion script JSON if available.",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runPublish,
+	Long: "Upload an MP3 file and publish it to apresai.dev. Metadata is auto-detected from the companion script JSON if available.\n\n" +
+		"Before uploading, the episode is checked against Apple Podcasts' requirements: title and summary length, " +
+		"embedded artwork (square JPEG or PNG, 1400-3000px, RGB), an explicit-content flag (--explicit, or the file's " +
+		"ITUNESADVISORY tag), and MP3 or AAC audio at 44.1/48 kHz and 64-320 kbps. Any failure stops the upload.",
+	Args: cobra.ExactArgs(1),
+	RunE: runPublish,
 }
 
 func init() {
@@ -44,6 +52,10 @@ func init() {
 	publishCmd.Flags().StringVar(&flagPublishOwner, "owner", defaultOwner, "Episode owner")
 	publishCmd.Flags().StringVar(&flagPublishSourceURL, "source-url", "", "Original source URL")
 	publishCmd.Flags().StringVar(&flagPublishAPIURL, "api-url", "https://apresai.dev", "API base URL")
+	publishCmd.Flags().BoolVar(&flagPublishExplicit, "explicit", false, "Mark the episode explicit (--explicit=false marks it clean); tagged into the uploaded file")
+	publishCmd.Flags().BoolVar(&flagPublishCheck, "check", false, "Run the Apple Podcasts preflight only; don't upload")
+	publishCmd.Flags().BoolVar(&flagPublishNoCheck, "skip-preflight", false, "Upload without the Apple Podcasts preflight")
+	publishCmd.MarkFlagsMutuallyExclusive("check", "skip-preflight")
 }
 
 func runPublish(cmd *cobra.Command, args []string) error {
@@ -79,6 +91,43 @@ func runPublish(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	var explicit *bool
+	if cmd.Flags().Changed("explicit") {
+		explicit = &flagPublishExplicit
+	}
+	if !flagPublishNoCheck {
+		problems, err := itunes.Check(cmd.Context(), itunes.Episode{Path: mp3Path, Title: title, Summary: summary, Explicit: explicit})
+		if err != nil {
+			return fmt.Errorf("preflight: %w", err)
+		}
+		if len(problems) > 0 {
+			fmt.Println("Apple Podcasts preflight failed:")
+			for _, p := range problems {
+				fmt.Printf("  - %s\n", p)
+			}
+			return fmt.Errorf("%d preflight problem(s): fix them, or pass --skip-preflight to publish anyway", len(problems))
+		}
+		fmt.Println("Apple Podcasts preflight: passed")
+	}
+	if flagPublishCheck {
+		return nil
+	}
+
+	// Upload a copy carrying the advisory, so feeds built from the file
+	// agree with what was checked.
+	if explicit != nil {
+		dir, err := os.MkdirTemp("", "podcaster-publish-")
+		if err != nil {
+			return fmt.Errorf("create temp directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		tagged := filepath.Join(dir, filepath.Base(mp3Path))
+		if err := assembly.SetAdvisory(cmd.Context(), mp3Path, *explicit, tagged); err != nil {
+			return fmt.Errorf("set explicit flag: %w", err)
+		}
+		mp3Path = tagged
+	}
+
 	// Resolve API key
 	apiKey, keySource, err := resolveAPIKey()
 	if err != nil {
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/spf13/cobra"
+)
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume [run-id] [-- generate flags...]",
+	Short: "Resume an interrupted generate run from its first missing segment",
+	Long: "Per-segment generate runs record their progress in " + pipeline.OutputBaseDir + "/" + pipeline.RunsDir +
+		" as each segment is synthesized. resume skips ingest and the script, reuses the audio already made, and " +
+		"synthesizes the rest with the run's original flags, then assembles the episode at its original path. " +
+		"Flags after -- are added to them (API keys, for instance). With no run ID, lists the runs that can be resumed.",
+	Example: "  podcaster resume\n" +
+		"  podcaster resume run-2841937465\n" +
+		"  podcaster resume run-2841937465 -- --elevenlabs-api-key $KEY",
+	RunE: runResume,
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return listResumableRuns()
+	}
+	if cmd.ArgsLenAtDash() == 0 {
+		return fmt.Errorf("the run ID goes before --")
+	}
+	st, err := pipeline.LoadRunState(args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(st.TempDir); err != nil {
+		return fmt.Errorf("run %s's segments are gone (%s); regenerate from its script with --from-script %s", st.ID, st.TempDir, st.Script)
+	}
+	genArgs, err := st.ResumeArgs()
+	if err != nil {
+		return err
+	}
+	if err := generateCmd.ParseFlags(genArgs); err != nil {
+		return fmt.Errorf("run %s's flags: %w", st.ID, err)
+	}
+	if err := checkGenerateArgs(args[1:], "changed when resuming; the run sets them"); err != nil {
+		return err
+	}
+
+	fmt.Printf("Resuming %s: %d of %d segments done, missing %s\n", st.ID, len(st.Completed), st.Total, pipeline.SegmentRanges(st.Missing()))
+	generateCmd.SetContext(cmd.Context())
+	return runGenerate(generateCmd, nil)
+}
+
+func listResumableRuns() error {
+	states, err := pipeline.ListRunStates()
+	if err != nil {
+		return err
+	}
+	if len(states) == 0 {
+		fmt.Println("No interrupted runs to resume.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN ID\tUPDATED\tSEGMENTS\tOUTPUT")
+	for _, st := range states {
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%s\n", st.ID, st.UpdatedAt.Local().Format("2006-01-02 15:04"), len(st.Completed), st.Total, st.Output)
+	}
+	w.Flush()
+	fmt.Println("\nResume one with: podcaster resume <run-id>")
+	return nil
+}
@@ -6,10 +6,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/ingest"
 	"github.com/apresai/podcaster/internal/pipeline"
 	"github.com/apresai/podcaster/internal/progress"
 	"github.com/apresai/podcaster/internal/script"
+	"github.com/apresai/podcaster/internal/transcribe"
 	"github.com/apresai/podcaster/internal/tts"
 	"github.com/spf13/cobra"
 )
@@ -42,11 +46,16 @@ var generateCmd = &cobra.Command{
 var listVoicesCmd = &cobra.Command{
 	Use:   "list-voices",
 	Short: "List available voices for all TTS providers",
-	RunE:  runListVoices,
+	Example: "  podcaster list-voices\n" +
+		"  podcaster list-voices --language es",
+	RunE: runListVoices,
 }
 
 var (
 	flagInput            string
+	flagInputs           []string // every -i value; flagInput is the first input
+	flagExtraInputs      []string // the rest, merged into one episode
+	flagChapters         string
 	flagOutput           string
 	flagTopic            string
 	flagTone             string
@@ -70,36 +79,134 @@ var (
 	flagAnthropicAPIKey  string
 	flagGeminiAPIKey     string
 	flagElevenLabsAPIKey string
+	flagNoTTSCache       bool
+	flagNoBatch          bool
+	flagBatch            bool
+	flagNoKeepAlive      bool
+	flagTTSConcurrency   int
+	flagTTSMaxRetries    int
+	flagTTSBackoff       time.Duration
+	flagTTSMaxBackoff    time.Duration
+	flagTTSBreaker       int
+	flagTTSFallback      string
+	flagSSMLHints        bool
+	flagLexicon          string
+	flagDeliveryHints    bool
+	flagSFX              bool
+	flagTTSStylePrompts  bool
+	flagStageTimeouts    string
+	flagStageBudgets     string
+	flagTranscriber      string
+	flagWhisperModel     string
+	flagOpenAIAPIKey     string
+	flagHeaders          []string
+	flagCookieJar        string
+	flagResumeTTS        string
+	flagPreview          int
+	flagEscalateModel    string
+	flagVerifySpeakers   bool
+	flagProfile          bool
+	flagMusic            string
+	flagMusicVolume      float64
+	flagIntro            string
+	flagOutro            string
+	flagShow             string
+	flagCover            string
+	flagNoLoudnorm       bool
+	flagQC               string
+	flagQCBounds         string
+	flagOutputFormat     string
+	flagBitrate          string
+	flagChannels         string
+	flagGap              time.Duration
+	flagCrossfade        time.Duration
+	flagTrimSilence      string
+	flagExplicit         bool
+
+	// BYOK keys for the remaining key-based TTS providers.
+	flagCartesiaAPIKey      string
+	flagVertexExpressAPIKey string
+	flagHumeAPIKey          string
+	flagDeepgramAPIKey      string
+
+	// flagLanguage filters voices in list-voices and the interactive picker.
+	flagLanguage string
 )
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(listVoicesCmd)
-	generateCmd.Flags().StringVarP(&flagInput, "input", "i", "", "Source content (URL, PDF path, or text file path)")
-	generateCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "Output file path (MP3)")
+	listVoicesCmd.Flags().StringVar(&flagLanguage, "language", "", "Only list voices that speak this language (BCP 47, e.g. es, pt-BR); multilingual voices always match")
+	generateCmd.Flags().StringArrayVarP(&flagInputs, "input", "i", nil, "Source content (URL, arXiv ID, Hacker News or Reddit thread, PDF, EPUB, DOCX or ODT path, text file path, a directory or quoted glob of Markdown docs, or an audio or video file to transcribe); repeat it or list several with commas to compare sources in one episode")
+	generateCmd.Flags().StringArrayVar(&flagHeaders, "header", nil, "Request header for fetching URL inputs behind a login or SSO, e.g. \"Authorization: Bearer ...\" (repeatable; sent only to the inputs' hosts, never logged)")
+	generateCmd.Flags().StringVar(&flagCookieJar, "cookie-jar", "", "Netscape-format cookies.txt (browser export or curl -c) sent when fetching URL inputs, by cookie domain")
+	generateCmd.Flags().StringVar(&flagChapters, "chapters", "", "EPUB chapters to use, 1-based: 3, 2-4, or 1,3,5-7 (default: the whole book; applies to every EPUB input)")
+	generateCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "Output file path (extension set by --output-format)")
 	generateCmd.Flags().StringVarP(&flagTopic, "topic", "p", "", "Focus the conversation on a specific topic")
 	generateCmd.Flags().StringVarP(&flagTone, "tone", "n", "casual", "Conversation tone: casual, technical, educational")
 	generateCmd.Flags().StringVarP(&flagDuration, "duration", "d", "standard", "Target duration: short (~3-4min), standard (~8-10min), long (~15min), deep (~30-35min)")
 	generateCmd.Flags().StringVarP(&flagStyle, "style", "s", "", "Conversation styles (comma-separated): humor, wow, serious, debate, storytelling")
 	generateCmd.Flags().StringVarP(&flagFormat, "format", "F", "conversation", "Show format: conversation, interview, deep-dive, explainer, debate, news, storytelling, challenger")
-	generateCmd.Flags().StringVarP(&flagVoice1, "voice1", "1", "", "Voice for host 1 / Alex (provider:voiceID or plain voiceID)")
-	generateCmd.Flags().StringVarP(&flagVoice2, "voice2", "2", "", "Voice for host 2 / Sam (provider:voiceID or plain voiceID)")
-	generateCmd.Flags().StringVarP(&flagVoice3, "voice3", "3", "", "Voice for host 3 / Jordan (provider:voiceID or plain voiceID)")
+	generateCmd.Flags().StringVarP(&flagVoice1, "voice1", "1", "", "Voice for host 1 / Alex (provider:voiceID or plain voiceID, optional @speed=,stability=,pitch=)")
+	generateCmd.Flags().StringVarP(&flagVoice2, "voice2", "2", "", "Voice for host 2 / Sam (provider:voiceID or plain voiceID, optional @speed=,stability=,pitch=)")
+	generateCmd.Flags().StringVarP(&flagVoice3, "voice3", "3", "", "Voice for host 3 / Jordan (provider:voiceID or plain voiceID, optional @speed=,stability=,pitch=)")
 	generateCmd.Flags().IntVarP(&flagVoices, "voices", "V", 2, "Number of podcast hosts (1-3)")
 	generateCmd.Flags().BoolVarP(&flagScriptOnly, "script-only", "S", false, "Output script JSON only, skip TTS and assembly")
 	generateCmd.Flags().StringVarP(&flagFromScript, "from-script", "f", "", "Generate audio from an existing script JSON file")
 	generateCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Enable detailed logging")
 	generateCmd.Flags().BoolVarP(&flagTUI, "tui", "t", false, "Interactive setup wizard for generation options")
-	generateCmd.Flags().StringVarP(&flagTTS, "tts", "T", "gemini", "Text-to-speech audio provider (synthesizes voices): gemini (default), gemini-vertex, vertex-express, elevenlabs, google, polly")
+	generateCmd.Flags().StringVar(&flagLanguage, "language", "", "Episode language for the --tui voice picker, which hides voices that can't speak it (BCP 47, e.g. es, pt-BR)")
+	generateCmd.Flags().StringVarP(&flagTTS, "tts", "T", "gemini", "Text-to-speech audio provider (synthesizes voices): gemini (default), gemini-vertex, vertex-express, elevenlabs, google, polly, cartesia, hume, deepgram")
 	generateCmd.Flags().StringVarP(&flagModel, "model", "m", "haiku", "Script generation LLM (writes the conversation): haiku (default, Claude Haiku 4.5), sonnet, gemini-flash, gemini-pro, nova-lite")
 	generateCmd.Flags().StringVar(&flagTTSModel, "tts-model", "", "TTS model ID (e.g., eleven_v3, gemini-2.5-flash-preview-tts)")
-	generateCmd.Flags().Float64Var(&flagTTSSpeed, "tts-speed", 0, "Speech speed (ElevenLabs: 0.7-1.2, Google: 0.25-2.0)")
+	generateCmd.Flags().Float64Var(&flagTTSSpeed, "tts-speed", 0, "Speech speed (ElevenLabs: 0.7-1.2, Google: 0.25-2.0, others: 0.5-2.0 applied with FFmpeg)")
 	generateCmd.Flags().Float64Var(&flagTTSStability, "tts-stability", 0, "Voice stability, ElevenLabs only (0.0-1.0)")
-	generateCmd.Flags().Float64Var(&flagTTSPitch, "tts-pitch", 0, "Pitch adjustment in semitones, Google only (-20.0 to 20.0)")
+	generateCmd.Flags().Float64Var(&flagTTSPitch, "tts-pitch", 0, "Pitch adjustment in semitones (Google: -20.0 to 20.0, others: -12.0 to 12.0 applied with FFmpeg)")
+	generateCmd.Flags().IntVar(&flagTTSConcurrency, "tts-concurrency", pipeline.DefaultTTSConcurrency, "Parallel per-segment TTS requests (Gemini AI Studio is always limited to 1)")
+	generateCmd.Flags().IntVar(&flagTTSMaxRetries, "tts-max-retries", 4, "Retries per TTS request on rate limits, 5xx, and timeouts (0 = no retries)")
+	generateCmd.Flags().DurationVar(&flagTTSBackoff, "tts-backoff", 2*time.Second, "Wait before the first TTS retry; doubles each retry, with jitter")
+	generateCmd.Flags().DurationVar(&flagTTSMaxBackoff, "tts-max-backoff", 30*time.Second, "Longest wait between TTS retries (a server's Retry-After can exceed it)")
+	generateCmd.Flags().IntVar(&flagTTSBreaker, "tts-breaker", tts.DefaultBreakerThreshold, "Consecutive failed TTS requests (5xx, timeouts) before a provider is skipped (fails over to --tts-fallback, or fails fast) until a probe after 30s succeeds; 0 disables")
+	generateCmd.Flags().StringVar(&flagTTSFallback, "tts-fallback", "", "Providers to switch to if the TTS provider's daily quota runs out (comma-separated, e.g. gemini-vertex,elevenlabs)")
+	generateCmd.Flags().BoolVar(&flagSSMLHints, "ssml-hints", false, "Have the script include pause/emphasis hints, sent as SSML to providers that support it (Google)")
+	generateCmd.Flags().BoolVar(&flagDeliveryHints, "delivery-hints", false, "Have the script include per-line delivery directions and audio tags like [laughs], performed by ElevenLabs (stripped for other providers)")
+	generateCmd.Flags().BoolVar(&flagSFX, "sfx", false, "Let the script mark sound effects like [SFX:whoosh] at transitions, spliced in from the bundled library ("+strings.Join(assembly.SFXNames(), ", ")+")")
+	generateCmd.Flags().StringVar(&flagStageTimeouts, "stage-timeouts", "", "Per-stage time limits, e.g. script=15m,tts-batch=45m (stages: transcribe, ingest, script, tts-segment, tts-batch, assembly)")
+	generateCmd.Flags().StringVar(&flagStageBudgets, "stage-budgets", "", "Per-stage latency targets that warn, not stop, e.g. script=5m,script:haiku=3m,tts:gemini=8m (stages: transcribe, ingest, script, tts, assembly, finishing)")
+	generateCmd.Flags().StringVar(&flagTranscriber, "transcriber", "", "Transcription engine for audio and video inputs: openai (Whisper API) or whisper-cpp (local); default openai with an OpenAI key, else whisper-cpp with a model")
+	generateCmd.Flags().StringVar(&flagWhisperModel, "whisper-model", "", "whisper.cpp ggml model file, e.g. ggml-base.en.bin (overrides WHISPER_CPP_MODEL env var)")
+	generateCmd.Flags().StringVar(&flagOpenAIAPIKey, "openai-api-key", "", "OpenAI API key for Whisper transcription (overrides OPENAI_API_KEY env var)")
+	generateCmd.Flags().StringVar(&flagMusic, "music", "", "Background music bed mixed under the voices with ducking: an audio file or a built-in bed ("+strings.Join(assembly.MusicBeds(), ", ")+")")
+	generateCmd.Flags().Float64Var(&flagMusicVolume, "music-volume", 0, "Music bed gain in dB before ducking (default -18)")
+	generateCmd.Flags().StringVar(&flagIntro, "intro", "", "Audio file crossfaded onto the start of the episode")
+	generateCmd.Flags().StringVar(&flagOutro, "outro", "", "Audio file crossfaded onto the end of the episode")
+	generateCmd.Flags().StringVar(&flagShow, "show", "", "Show name written to the episode's ID3 album tag (default \"Podcaster\")")
+	generateCmd.Flags().StringVar(&flagCover, "cover", "", "JPEG or PNG embedded in the episode as cover art")
+	generateCmd.Flags().StringVar(&flagOutputFormat, "output-format", "", "Episode audio format: mp3 (default), aac (.m4a), opus, wav; defaults to -o's extension")
+	generateCmd.Flags().StringVar(&flagBitrate, "bitrate", "", "Episode bitrate in kbps, 32-320 (e.g. 64k; default 192k, 96k for opus; not for wav)")
+	generateCmd.Flags().StringVar(&flagChannels, "channels", "", "Episode channels: 1 (mono) or 2 (stereo, default); mono is normalized to -19 LUFS")
+	generateCmd.Flags().DurationVar(&flagGap, "gap", 0, "Silence between segments, 50ms-1s (default 200ms; script beats get 800ms)")
+	generateCmd.Flags().DurationVar(&flagCrossfade, "crossfade", 0, "Overlap each segment with the next by this much, 10ms-300ms, instead of a gap (default off)")
+	generateCmd.Flags().StringVar(&flagTrimSilence, "trim-silence", "", "Trim each segment's leading and trailing silence: off, or overrides like threshold=-45,keep=100ms (default threshold=-50 dBFS, keep=50ms)")
+	generateCmd.Flags().BoolVar(&flagExplicit, "explicit", false, "Mark the episode explicit (--explicit=false marks it clean); default detects profanity and sexual content in the script")
+	generateCmd.Flags().BoolVar(&flagNoLoudnorm, "no-loudnorm", false, "Skip normalizing the episode's loudness (-16 LUFS stereo, EBU R128)")
+	generateCmd.Flags().StringVar(&flagQC, "qc", pipeline.QCWarn, "Check the finished episode's loudness, true peak, and silence: warn, fail (fail the run if out of bounds), or off")
+	generateCmd.Flags().StringVar(&flagQCBounds, "qc-bounds", "", "Override quality bounds, e.g. loudness=-18:-14,peak=-1,silence=0.1 (default: loudness target ±2 LU, -1 dBTP, 10% silence)")
+	generateCmd.Flags().StringVar(&flagEscalateModel, "escalate-model", "", "Model to retry the script with once if review rejects it and its revision (default: sonnet for haiku, gemini-pro for gemini-flash; off to disable)")
+	generateCmd.Flags().BoolVar(&flagVerifySpeakers, "verify-speakers", false, "Transcribe the finished episode with Deepgram diarization and warn about segments spoken in the wrong host's voice (needs a Deepgram API key)")
+	generateCmd.Flags().BoolVar(&flagProfile, "profile", false, "Write CPU and per-stage heap profiles (pprof) and a time/memory summary per stage to podcaster-output/profiles/")
+	generateCmd.Flags().IntVar(&flagPreview, "preview", 0, "Synthesize only the first N segments as a short sample to check voices and tone; the full script is saved for --from-script")
+	generateCmd.Flags().StringVar(&flagResumeTTS, "resume-tts", "", "Resume a run that failed during per-segment TTS from its temp directory (printed on failure); implies --from-script of the saved script")
+	generateCmd.Flags().BoolVar(&flagTTSStylePrompts, "tts-style-prompts", false, "Prefix each segment with a spoken style instruction for Gemini TTS (\"Say in a playful, witty tone:\"), from --style and delivery hints")
+	generateCmd.Flags().StringVar(&flagLexicon, "lexicon", "", "Pronunciation lexicon YAML mapping terms to respellings or IPA (e.g. kubectl: cube control)")
+	generateCmd.Flags().BoolVar(&flagNoBatch, "no-batch", false, "Synthesize per segment instead of one multi-speaker batch request (Gemini providers); default when PODCASTER_NO_BATCH=1")
+	generateCmd.Flags().BoolVar(&flagBatch, "batch", false, "Use batch synthesis where supported, overriding PODCASTER_NO_BATCH")
+	generateCmd.MarkFlagsMutuallyExclusive("batch", "no-batch")
+	generateCmd.Flags().BoolVar(&flagNoKeepAlive, "no-keepalive", false, "Open a new TTS connection per request instead of reusing connections (debugging)")
+	generateCmd.Flags().BoolVar(&flagNoTTSCache, "no-tts-cache", false, "Disable the per-segment TTS cache (podcaster-output/cache)")
 	generateCmd.Flags().StringVar(&flagAnthropicAPIKey, "anthropic-api-key", "", "Anthropic API key (overrides ANTHROPIC_API_KEY env var)")
-	generateCmd.Flags().StringVar(&flagGeminiAPIKey, "gemini-api-key", "", "Gemini API key (overrides GEMINI_API_KEY env var)")
-	generateCmd.Flags().StringVar(&flagElevenLabsAPIKey, "elevenlabs-api-key", "", "ElevenLabs API key (overrides ELEVENLABS_API_KEY env var)")
+	addTTSKeyFlags(generateCmd)
 }
 
 func Execute() error {
@@ -107,6 +214,14 @@ func Execute() error {
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
+	var inputs []string
+	for _, v := range flagInputs {
+		inputs = append(inputs, ingest.SplitInputs(v)...)
+	}
+	if len(inputs) > 0 {
+		flagInput, flagExtraInputs = inputs[0], inputs[1:]
+	}
+
 	// Run interactive setup if requested
 	if flagTUI {
 		if err := runInteractiveSetup(); err != nil {
@@ -115,12 +230,50 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate flags
-	if flagFromScript == "" && flagInput == "" {
+	if flagFromScript == "" && flagInput == "" && flagResumeTTS == "" {
 		return fmt.Errorf("either --input (-i) or --from-script (-f) is required")
 	}
 	if flagFromScript != "" && flagInput != "" {
 		return fmt.Errorf("--input and --from-script are mutually exclusive")
 	}
+	if flagChapters != "" {
+		hasEPUB := false
+		for _, in := range append([]string{flagInput}, flagExtraInputs...) {
+			hasEPUB = hasEPUB || ingest.DetectSource(in) == ingest.SourceEPUB
+		}
+		if !hasEPUB {
+			return fmt.Errorf("--chapters needs an EPUB --input")
+		}
+	}
+	if hasMediaInput() {
+		// Audio and video are transcribed before ingest; check the
+		// engine is set up before anything runs.
+		if err := checkFFmpeg(); err != nil {
+			return fmt.Errorf("transcribing audio and video inputs: %w", err)
+		}
+		if _, err := transcribe.New(transcribeOptions()); err != nil {
+			return err
+		}
+	} else if flagTranscriber != "" || flagWhisperModel != "" {
+		return fmt.Errorf("--transcriber and --whisper-model need an audio or video --input")
+	}
+	if len(flagHeaders) > 0 || flagCookieJar != "" {
+		if flagInput == "" {
+			return fmt.Errorf("--header and --cookie-jar need an --input URL")
+		}
+		if _, err := ingest.NewAuth(flagHeaders, flagCookieJar, nil); err != nil {
+			return err
+		}
+	}
+	if flagResumeTTS != "" && (flagInput != "" || flagScriptOnly) {
+		return fmt.Errorf("--resume-tts can't be combined with --input or --script-only")
+	}
+	if flagPreview < 0 {
+		return fmt.Errorf("--preview must be a number of segments, got %d", flagPreview)
+	}
+	if flagPreview > 0 && (flagScriptOnly || flagResumeTTS != "") {
+		return fmt.Errorf("--preview can't be combined with --script-only or --resume-tts")
+	}
 
 	// Validate format
 	if !script.IsValidFormat(flagFormat) {
@@ -161,9 +314,36 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate TTS provider name
-	validProviders := map[string]bool{"elevenlabs": true, "google": true, "gemini": true, "gemini-vertex": true, "vertex-express": true, "polly": true}
+	validProviders := map[string]bool{"elevenlabs": true, "google": true, "gemini": true, "gemini-vertex": true, "vertex-express": true, "polly": true, "cartesia": true, "hume": true, "deepgram": true}
 	if !validProviders[flagTTS] {
-		return fmt.Errorf("invalid TTS provider %q: must be gemini, gemini-vertex, vertex-express, elevenlabs, google, or polly", flagTTS)
+		return fmt.Errorf("invalid TTS provider %q: must be gemini, gemini-vertex, vertex-express, elevenlabs, google, polly, cartesia, hume, or deepgram", flagTTS)
+	}
+
+	// Validate fallback chain
+	var ttsFallback []string
+	if flagTTSFallback != "" {
+		for _, p := range strings.Split(flagTTSFallback, ",") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			if !validProviders[p] {
+				return fmt.Errorf("invalid --tts-fallback provider %q: must be gemini, gemini-vertex, vertex-express, elevenlabs, google, polly, cartesia, hume, or deepgram", p)
+			}
+			if p == flagTTS {
+				return fmt.Errorf("--tts-fallback must not include the primary provider %q", p)
+			}
+			ttsFallback = append(ttsFallback, p)
+		}
+	}
+
+	stageTimeouts, err := pipeline.ParseTimeouts(flagStageTimeouts)
+	if err != nil {
+		return fmt.Errorf("invalid --stage-timeouts: %w", err)
+	}
+	stageBudgets, err := pipeline.ParseBudgets(flagStageBudgets)
+	if err != nil {
+		return fmt.Errorf("invalid --stage-budgets: %w", err)
 	}
 
 	// Validate model
@@ -171,6 +351,9 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	if !validModels[flagModel] {
 		return fmt.Errorf("invalid model %q: must be haiku, sonnet, gemini-flash, gemini-pro, or nova-lite", flagModel)
 	}
+	if err := pipeline.ValidateEscalateModel(flagEscalateModel); err != nil {
+		return fmt.Errorf("--escalate-model: %w", err)
+	}
 
 	// Validate TTS model if specified
 	if flagTTSModel != "" {
@@ -179,7 +362,8 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Validate TTS speed per provider
+	// Validate TTS speed per provider. Providers without native speed get
+	// it applied with FFmpeg, in a narrower range.
 	if flagTTSSpeed != 0 {
 		switch flagTTS {
 		case "elevenlabs":
@@ -190,10 +374,10 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 			if flagTTSSpeed < 0.25 || flagTTSSpeed > 2.0 {
 				return fmt.Errorf("--tts-speed for Google must be between 0.25 and 2.0 (got %.2f)", flagTTSSpeed)
 			}
-		case "gemini", "gemini-vertex", "vertex-express":
-			return fmt.Errorf("--tts-speed is not supported by Gemini TTS")
-		case "polly":
-			return fmt.Errorf("--tts-speed is not supported by AWS Polly")
+		default:
+			if flagTTSSpeed < tts.EmulatedSpeedMin || flagTTSSpeed > tts.EmulatedSpeedMax {
+				return fmt.Errorf("--tts-speed for %s must be between %.1f and %.1f (got %.2f)", flagTTS, tts.EmulatedSpeedMin, tts.EmulatedSpeedMax, flagTTSSpeed)
+			}
 		}
 	}
 
@@ -207,20 +391,80 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Validate TTS pitch (Google only)
+	// Validate TTS pitch (native on Google, emulated with FFmpeg elsewhere)
 	if flagTTSPitch != 0 {
-		if flagTTS != "google" {
-			return fmt.Errorf("--tts-pitch is only supported by Google Cloud TTS")
+		if tts.NativePitch(flagTTS) {
+			if flagTTSPitch < -20.0 || flagTTSPitch > 20.0 {
+				return fmt.Errorf("--tts-pitch must be between -20.0 and 20.0 (got %.2f)", flagTTSPitch)
+			}
+		} else if flagTTSPitch < -tts.EmulatedPitchMax || flagTTSPitch > tts.EmulatedPitchMax {
+			return fmt.Errorf("--tts-pitch for %s must be between %.1f and %.1f (got %.2f)", flagTTS, -tts.EmulatedPitchMax, tts.EmulatedPitchMax, flagTTSPitch)
+		}
+	}
+
+	if flagTTSConcurrency < 1 || flagTTSConcurrency > 16 {
+		return fmt.Errorf("--tts-concurrency must be between 1 and 16 (got %d)", flagTTSConcurrency)
+	}
+
+	// Retry policy: only flags the user set are passed on, so the defaults
+	// stay in one place (tts.RetryPolicy) and out of the equivalent CLI.
+	var ttsRetry tts.RetryPolicy
+	if cmd.Flags().Changed("tts-max-retries") {
+		if flagTTSMaxRetries < 0 || flagTTSMaxRetries > 20 {
+			return fmt.Errorf("--tts-max-retries must be between 0 and 20 (got %d)", flagTTSMaxRetries)
+		}
+		ttsRetry.MaxAttempts = flagTTSMaxRetries + 1
+	}
+	if cmd.Flags().Changed("tts-backoff") {
+		if flagTTSBackoff < 100*time.Millisecond {
+			return fmt.Errorf("--tts-backoff must be at least 100ms (got %s)", flagTTSBackoff)
+		}
+		ttsRetry.InitialBackoff = flagTTSBackoff
+	}
+	if cmd.Flags().Changed("tts-max-backoff") {
+		if flagTTSMaxBackoff < flagTTSBackoff {
+			return fmt.Errorf("--tts-max-backoff (%s) must not be less than --tts-backoff (%s)", flagTTSMaxBackoff, flagTTSBackoff)
+		}
+		ttsRetry.MaxBackoff = flagTTSMaxBackoff
+	}
+	// Batch mode: --no-batch/--batch, else PODCASTER_NO_BATCH.
+	disableBatch := flagNoBatch
+	if !flagNoBatch && !flagBatch {
+		switch os.Getenv("PODCASTER_NO_BATCH") {
+		case "1", "true":
+			disableBatch = true
+		}
+	}
+
+	var ttsBreaker int
+	if cmd.Flags().Changed("tts-breaker") {
+		if flagTTSBreaker < 0 {
+			return fmt.Errorf("--tts-breaker must be 0 (disabled) or more (got %d)", flagTTSBreaker)
 		}
-		if flagTTSPitch < -20.0 || flagTTSPitch > 20.0 {
-			return fmt.Errorf("--tts-pitch must be between -20.0 and 20.0 (got %.2f)", flagTTSPitch)
+		ttsBreaker = flagTTSBreaker
+		if ttsBreaker == 0 {
+			ttsBreaker = -1
 		}
 	}
 
+	// Split off per-voice settings ("elevenlabs:rachel@stability=0.3")
+	voice1, v1Settings, err := tts.ParseVoiceSettings(flagVoice1)
+	if err != nil {
+		return fmt.Errorf("--voice1: %w", err)
+	}
+	voice2, v2Settings, err := tts.ParseVoiceSettings(flagVoice2)
+	if err != nil {
+		return fmt.Errorf("--voice2: %w", err)
+	}
+	voice3, v3Settings, err := tts.ParseVoiceSettings(flagVoice3)
+	if err != nil {
+		return fmt.Errorf("--voice3: %w", err)
+	}
+
 	// Parse provider:voiceID syntax for each voice flag
-	v1Provider, v1ID := tts.ParseVoiceSpec(flagVoice1)
-	v2Provider, v2ID := tts.ParseVoiceSpec(flagVoice2)
-	v3Provider, v3ID := tts.ParseVoiceSpec(flagVoice3)
+	v1Provider, v1ID := tts.ParseVoiceSpec(voice1)
+	v2Provider, v2ID := tts.ParseVoiceSpec(voice2)
+	v3Provider, v3ID := tts.ParseVoiceSpec(voice3)
 
 	// Default to --tts provider when no prefix
 	if v1Provider == "" {
@@ -238,31 +482,75 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	v2ID = tts.ResolveVoiceName(v2Provider, v2ID)
 	v3ID = tts.ResolveVoiceName(v3Provider, v3ID)
 
+	if err := v1Settings.Validate(v1Provider); err != nil {
+		return fmt.Errorf("--voice1: %w", err)
+	}
+	if err := v2Settings.Validate(v2Provider); err != nil {
+		return fmt.Errorf("--voice2: %w", err)
+	}
+	if err := v3Settings.Validate(v3Provider); err != nil {
+		return fmt.Errorf("--voice3: %w", err)
+	}
+
 	// Check API keys for all providers in use
 	ttsProviders := []string{v1Provider, v2Provider}
 	if flagVoices >= 3 {
 		ttsProviders = append(ttsProviders, v3Provider)
 	}
+	ttsProviders = append(ttsProviders, ttsFallback...)
 	if err := checkAPIKeys(ttsProviders, flagModel); err != nil {
 		return err
 	}
 
-	// Check FFmpeg (not needed for script-only)
-	if !flagScriptOnly {
-		if err := checkFFmpeg(); err != nil {
-			return err
-		}
+	// Without FFmpeg (not needed for script-only), segments are joined in
+	// Go, which rules out options that mix or re-encode; they are checked
+	// once the options are built.
+	noFFmpeg := !flagScriptOnly && !assembly.HasFFmpeg()
+
+	// Output format: --output-format, else -o's extension, else MP3.
+	outputFormat, err := assembly.ParseFormat(flagOutputFormat)
+	if err != nil {
+		return fmt.Errorf("--output-format: %w", err)
+	}
+	if flagOutputFormat == "" && flagOutput != "" {
+		outputFormat = assembly.FormatOf(flagOutput)
+	}
+	bitrate, err := assembly.ParseBitrate(flagBitrate)
+	if err != nil {
+		return fmt.Errorf("--bitrate: %w", err)
+	}
+	if bitrate != "" && outputFormat == assembly.FormatWAV {
+		return fmt.Errorf("--bitrate doesn't apply to wav output")
+	}
+	channels, err := assembly.ParseChannels(flagChannels)
+	if err != nil {
+		return fmt.Errorf("--channels: %w", err)
+	}
+	pacing := assembly.Pacing{Gap: flagGap, Crossfade: flagCrossfade}
+	if err := pacing.Validate(); err != nil {
+		return fmt.Errorf("--gap/--crossfade: %w", err)
+	}
+	if pacing.Trim, err = assembly.ParseSilenceTrim(flagTrimSilence); err != nil {
+		return fmt.Errorf("--trim-silence: %w", err)
+	}
+	if err := pipeline.ValidateQCMode(flagQC); err != nil {
+		return fmt.Errorf("--qc: %w", err)
+	}
+	if _, err := assembly.ParseQCBounds(flagQCBounds, assembly.QCBounds{}); err != nil {
+		return fmt.Errorf("--qc-bounds: %w", err)
 	}
 
 	// Route output to podcaster-output/episodes/ (empty = auto-name after script gen)
 	var outputPath, logFile string
 	if flagOutput != "" {
-		outputPath = filepath.Join(pipeline.OutputBaseDir, "episodes", filepath.Base(flagOutput))
+		outputPath = filepath.Join(pipeline.OutputBaseDir, "episodes", outputFormat.WithExt(filepath.Base(flagOutput)))
 		logFile = pipeline.LogFilePath(flagOutput)
 	}
 
 	opts := pipeline.Options{
 		Input:            flagInput,
+		ExtraInputs:      flagExtraInputs,
+		Chapters:         flagChapters,
 		Output:           outputPath,
 		Topic:            flagTopic,
 		Tone:             flagTone,
@@ -276,6 +564,9 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		Voice3:           v3ID,
 		Voice3Provider:   v3Provider,
 		Voices:           flagVoices,
+		Voice1Settings:   v1Settings,
+		Voice2Settings:   v2Settings,
+		Voice3Settings:   v3Settings,
 		ScriptOnly:       flagScriptOnly,
 		FromScript:       flagFromScript,
 		Verbose:          flagVerbose,
@@ -286,13 +577,71 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		TTSSpeed:         flagTTSSpeed,
 		TTSStability:     flagTTSStability,
 		TTSPitch:         flagTTSPitch,
+		NoTTSCache:       flagNoTTSCache,
+		DisableBatch:     disableBatch,
+		TTSConcurrency:   flagTTSConcurrency,
+		TTSRetry:         ttsRetry,
+		TTSFallback:      ttsFallback,
+		SSMLHints:        flagSSMLHints,
+		Lexicon:          flagLexicon,
+		DeliveryHints:    flagDeliveryHints,
+		SFX:              flagSFX,
 		AnthropicAPIKey:  flagAnthropicAPIKey,
 		GeminiAPIKey:     flagGeminiAPIKey,
 		ElevenLabsAPIKey: flagElevenLabsAPIKey,
 	}
+	opts.TTSBreakerThreshold = ttsBreaker
+	opts.TTSStylePrompts = flagTTSStylePrompts
+	opts.ResumeTTS = flagResumeTTS
+	opts.Preview = flagPreview
+	opts.EscalateModel = flagEscalateModel
+	opts.VerifySpeakers = flagVerifySpeakers
+	if flagProfile {
+		opts.ProfileDir = pipeline.ProfileDir()
+	}
+	opts.Music = flagMusic
+	opts.MusicVolume = flagMusicVolume
+	opts.History = true
+	opts.Checkpoint = true
+	opts.Intro = flagIntro
+	opts.Outro = flagOutro
+	opts.NoLoudnorm = flagNoLoudnorm
+	opts.QC = flagQC
+	opts.QCBounds = flagQCBounds
+	opts.OutputFormat = outputFormat
+	opts.Encoding = assembly.Encoding{Bitrate: bitrate, Channels: channels}
+	opts.Pacing = pacing
+	if cmd.Flags().Changed("explicit") {
+		opts.Explicit = &flagExplicit
+	}
+	opts.Show = flagShow
+	opts.Cover = flagCover
+	opts.Timeouts = stageTimeouts
+	opts.Budgets = stageBudgets
+	opts.Transcriber = flagTranscriber
+	opts.WhisperModel = flagWhisperModel
+	opts.OpenAIAPIKey = flagOpenAIAPIKey
+	opts.FetchHeaders = flagHeaders
+	opts.CookieJar = flagCookieJar
+	opts.DisableKeepAlives = flagNoKeepAlive
+	opts.CartesiaAPIKey = flagCartesiaAPIKey
+	opts.VertexExpressAPIKey = flagVertexExpressAPIKey
+	opts.HumeAPIKey = flagHumeAPIKey
+	opts.DeepgramAPIKey = flagDeepgramAPIKey
+
+	if noFFmpeg {
+		if err := pipeline.CheckNative(opts); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "WARNING: FFmpeg not found; joining segments without it, so the episode won't be loudness-normalized or tagged (install with: brew install ffmpeg)")
+	}
 
-	// Wire up progress bar when not in verbose mode
+	// Wire up progress bar when not in verbose mode. The TTS summary is
+	// logged in verbose mode; otherwise it is printed after the bar's final
+	// summary (defers run last-first).
+	opts.TTSMetrics = tts.NewMetrics()
 	if !flagVerbose {
+		defer printTTSSummary(opts.TTSMetrics)
 		r := progress.NewBarRenderer(os.Stdout)
 		defer r.Finish()
 		opts.OnProgress = r.Handle
@@ -301,6 +650,20 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	return pipeline.Run(cmd.Context(), opts)
 }
 
+// printTTSSummary prints the run's per-provider TTS table, if any requests
+// were made.
+func printTTSSummary(m *tts.Metrics) {
+	var b strings.Builder
+	m.WriteSummary(&b)
+	if b.Len() == 0 {
+		return
+	}
+	fmt.Println("\n  TTS requests:")
+	for _, line := range strings.Split(strings.TrimRight(b.String(), "\n"), "\n") {
+		fmt.Println("  " + line)
+	}
+}
+
 func runListVoices(cmd *cobra.Command, args []string) error {
 	providers := []struct {
 		name  string
@@ -311,6 +674,9 @@ func runListVoices(cmd *cobra.Command, args []string) error {
 		{"elevenlabs", "ELEVENLABS"},
 		{"google", "GOOGLE CLOUD TTS"},
 		{"polly", "AWS POLLY (Generative)"},
+		{"cartesia", "CARTESIA (Sonic)"},
+		{"hume", "HUME AI (Octave)"},
+		{"deepgram", "DEEPGRAM (Aura)"},
 	}
 
 	fmt.Println("\nAvailable voices:")
@@ -320,22 +686,51 @@ func runListVoices(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
+		voices = tts.FilterVoicesByLanguage(voices, flagLanguage)
 
 		fmt.Printf("\n  %s\n", p.label)
 		fmt.Printf("  %s\n", strings.Repeat("\u2500", 50))
-		fmt.Printf("  %-28s %-12s %-8s %s\n", "ID", "NAME", "GENDER", "DESCRIPTION")
+		if len(voices) == 0 {
+			fmt.Printf("  (no %s voices)\n", flagLanguage)
+			continue
+		}
+		fmt.Printf("  %-28s %-12s %-8s %-13s %s\n", "ID", "NAME", "GENDER", "LANGUAGE", "DESCRIPTION")
 		for _, v := range voices {
 			def := ""
 			if v.DefaultFor != "" {
 				def = fmt.Sprintf(" (default %s)", v.DefaultFor)
 			}
-			fmt.Printf("  %-28s %-12s %-8s %s%s\n", v.ID, v.Name, v.Gender, v.Description, def)
+			fmt.Printf("  %-28s %-12s %-8s %-13s %s%s\n", v.ID, v.Name, v.Gender, v.Language, v.Summary(), def)
 		}
 	}
 	fmt.Println()
 	return nil
 }
 
+// addTTSKeyFlags registers the BYOK flags for key-based TTS providers on
+// cmd. Every command binds the same variables, read back by ttsKeyFlag.
+func addTTSKeyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&flagGeminiAPIKey, "gemini-api-key", "", "Gemini API key (overrides GEMINI_API_KEY env var)")
+	cmd.Flags().StringVar(&flagElevenLabsAPIKey, "elevenlabs-api-key", "", "ElevenLabs API key (overrides ELEVENLABS_API_KEY env var)")
+	cmd.Flags().StringVar(&flagCartesiaAPIKey, "cartesia-api-key", "", "Cartesia API key (overrides CARTESIA_API_KEY env var)")
+	cmd.Flags().StringVar(&flagVertexExpressAPIKey, "vertex-express-api-key", "", "Vertex AI Express API key (overrides VERTEX_AI_API_KEY env var)")
+	cmd.Flags().StringVar(&flagHumeAPIKey, "hume-api-key", "", "Hume AI API key (overrides HUME_API_KEY env var)")
+	cmd.Flags().StringVar(&flagDeepgramAPIKey, "deepgram-api-key", "", "Deepgram API key (overrides DEEPGRAM_API_KEY env var)")
+}
+
+// ttsKeyFlag returns the --<provider>-api-key value for a TTS provider, or
+// "" if it has none or wasn't set.
+func ttsKeyFlag(provider string) string {
+	return pipeline.Options{
+		GeminiAPIKey:        flagGeminiAPIKey,
+		ElevenLabsAPIKey:    flagElevenLabsAPIKey,
+		CartesiaAPIKey:      flagCartesiaAPIKey,
+		VertexExpressAPIKey: flagVertexExpressAPIKey,
+		HumeAPIKey:          flagHumeAPIKey,
+		DeepgramAPIKey:      flagDeepgramAPIKey,
+	}.TTSAPIKey(provider)
+}
+
 func checkAPIKeys(ttsProviders []string, model string) error {
 	needed := map[string]bool{}
 
@@ -344,7 +739,7 @@ func checkAPIKeys(ttsProviders []string, model string) error {
 		return flagVal != "" || os.Getenv(envVar) != ""
 	}
 
-	if flagFromScript == "" {
+	if flagFromScript == "" && flagResumeTTS == "" {
 		switch {
 		case model == "haiku" || model == "sonnet":
 			if !hasKey("ANTHROPIC_API_KEY", flagAnthropicAPIKey) {
@@ -367,15 +762,15 @@ func checkAPIKeys(ttsProviders []string, model string) error {
 			seen[p] = true
 			switch p {
 			case "elevenlabs":
-				if !hasKey("ELEVENLABS_API_KEY", flagElevenLabsAPIKey) {
+				if !hasKey("ELEVENLABS_API_KEY", ttsKeyFlag(p)) {
 					needed["ELEVENLABS_API_KEY"] = true
 				}
 			case "gemini":
-				if !hasKey("GEMINI_API_KEY", flagGeminiAPIKey) {
+				if !hasKey("GEMINI_API_KEY", ttsKeyFlag(p)) && len(tts.EnvKeys("GEMINI_API_KEY")) == 0 {
 					needed["GEMINI_API_KEY"] = true
 				}
 			case "vertex-express":
-				if !hasKey("VERTEX_AI_API_KEY", "") {
+				if ttsKeyFlag(p) == "" && len(tts.EnvKeys("VERTEX_AI_API_KEY")) == 0 {
 					needed["VERTEX_AI_API_KEY"] = true
 				}
 			case "gemini-vertex":
@@ -385,6 +780,18 @@ func checkAPIKeys(ttsProviders []string, model string) error {
 				// Uses Application Default Credentials
 			case "polly":
 				// Uses AWS default credentials chain (no API key needed)
+			case "cartesia":
+				if !hasKey("CARTESIA_API_KEY", ttsKeyFlag(p)) {
+					needed["CARTESIA_API_KEY"] = true
+				}
+			case "hume":
+				if !hasKey("HUME_API_KEY", ttsKeyFlag(p)) {
+					needed["HUME_API_KEY"] = true
+				}
+			case "deepgram":
+				if !hasKey("DEEPGRAM_API_KEY", ttsKeyFlag(p)) {
+					needed["DEEPGRAM_API_KEY"] = true
+				}
 			}
 		}
 	}
@@ -394,11 +801,26 @@ func checkAPIKeys(ttsProviders []string, model string) error {
 		for k := range needed {
 			missing = append(missing, k)
 		}
-		return fmt.Errorf("missing required environment variable(s): %s\nYou can also pass these via --anthropic-api-key, --gemini-api-key, --elevenlabs-api-key flags", strings.Join(missing, ", "))
+		return fmt.Errorf("missing required environment variable(s): %s\nYou can also pass these via --anthropic-api-key, --gemini-api-key, --elevenlabs-api-key, --cartesia-api-key, --vertex-express-api-key, --hume-api-key, --deepgram-api-key flags", strings.Join(missing, ", "))
 	}
 	return nil
 }
 
+// hasMediaInput reports whether any --input is an audio or video file.
+func hasMediaInput() bool {
+	for _, in := range append([]string{flagInput}, flagExtraInputs...) {
+		if in != "" && ingest.DetectSource(in) == ingest.SourceMedia {
+			return true
+		}
+	}
+	return false
+}
+
+// transcribeOptions are the transcription flags.
+func transcribeOptions() transcribe.Options {
+	return transcribe.Options{Engine: flagTranscriber, OpenAIAPIKey: flagOpenAIAPIKey, WhisperModel: flagWhisperModel}
+}
+
 func checkFFmpeg() error {
 	_, err := exec.LookPath("ffmpeg")
 	if err != nil {
@@ -0,0 +1,326 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/apresai/podcaster/internal/schedule"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagShowSource    string
+	flagShowFeed      bool
+	flagShowFeedItems int
+	flagShowCron      string
+	flagShowTimezone  string
+	flagShowPublish   bool
+	flagShowOwner     string
+	flagWorkerPoll    time.Duration
+)
+
+var showsCmd = &cobra.Command{
+	Use:   "shows",
+	Short: "Manage recurring shows generated on a schedule",
+	Long: "A show is a source plus generate flags, generated on a cron schedule by `podcaster shows worker` " +
+		"and optionally published. Shows are saved in " + pipeline.OutputBaseDir + "/" + schedule.ShowsFile + ".",
+}
+
+var showsAddCmd = &cobra.Command{
+	Use:   "add <name> [-- generate flags...]",
+	Short: "Save a recurring show",
+	Long: "Save a show. Flags after -- are passed to every generate run (not --input or --output, which the " +
+		"worker sets). With --feed, the source is an RSS or Atom feed and each episode covers the items " +
+		"published since the last one, with their articles.",
+	Example: "  podcaster shows add ai-daily --feed --source https://example.com/ai/rss.xml \\\n" +
+		"    --cron \"0 7 * * 1-5\" --publish -- --format news --duration short --tts gemini",
+	Args: cobra.MinimumNArgs(1),
+	RunE: runShowsAdd,
+}
+
+var showsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved shows and when they next run",
+	Args:  cobra.NoArgs,
+	RunE:  runShowsList,
+}
+
+var showsRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Delete a saved show (its episodes are kept)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runShowsRemove,
+}
+
+var showsRunCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: "Generate a show's next episode now",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runShowsRun,
+}
+
+var showsWorkerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run shows on their schedules until interrupted",
+	Long: "Check the saved shows every --poll and generate (and publish) each one that is due, one at a time. " +
+		"A show missed while the worker was down runs once when it starts. Run it under systemd, launchd, " +
+		"or a container restart policy.",
+	Args: cobra.NoArgs,
+	RunE: runShowsWorker,
+}
+
+func init() {
+	rootCmd.AddCommand(showsCmd)
+	showsCmd.AddCommand(showsAddCmd, showsListCmd, showsRemoveCmd, showsRunCmd, showsWorkerCmd)
+	showsAddCmd.Flags().StringVar(&flagShowSource, "source", "", "URL or file to generate from (required)")
+	showsAddCmd.Flags().BoolVar(&flagShowFeed, "feed", false, "Source is an RSS/Atom feed; each episode covers its new items")
+	showsAddCmd.Flags().IntVar(&flagShowFeedItems, "feed-items", schedule.DefaultFeedItems, "Maximum feed items per episode")
+	showsAddCmd.Flags().StringVar(&flagShowCron, "cron", "", "Schedule: 5-field cron expression or @daily/@weekly/@hourly (required)")
+	showsAddCmd.Flags().StringVar(&flagShowTimezone, "timezone", "", "IANA timezone for the schedule (default: local time)")
+	showsAddCmd.Flags().BoolVar(&flagShowPublish, "publish", false, "Publish each episode with podcaster publish")
+	showsAddCmd.Flags().StringVar(&flagShowOwner, "owner", "", "Owner for published episodes (default: publish's)")
+	showsAddCmd.MarkFlagRequired("source")
+	showsAddCmd.MarkFlagRequired("cron")
+	showsWorkerCmd.Flags().DurationVar(&flagWorkerPoll, "poll", 30*time.Second, "How often to check for due shows")
+}
+
+// checkGenerateArgs parses generate flags passed through after --, for
+// commands that run generate themselves and set its input and output.
+// reason ends the error for a flag they set.
+func checkGenerateArgs(genArgs []string, reason string) error {
+	reserved := map[string]bool{"input": true, "output": true, "tui": true, "from-script": true, "resume-tts": true}
+	for _, a := range genArgs {
+		if long, ok := strings.CutPrefix(a, "--"); ok {
+			long, _, _ = strings.Cut(long, "=")
+			if reserved[long] {
+				return fmt.Errorf("--%s can't be %s", long, reason)
+			}
+			continue
+		}
+		// A shorthand group: -v, -oX, or -vt. A flag that takes a value
+		// ends it; the rest of the group is that value.
+		short, ok := strings.CutPrefix(a, "-")
+		for ok && short != "" {
+			f := generateCmd.Flags().ShorthandLookup(short[:1])
+			if f == nil {
+				break
+			}
+			if reserved[f.Name] {
+				return fmt.Errorf("-%s/--%s can't be %s", f.Shorthand, f.Name, reason)
+			}
+			if f.NoOptDefVal == "" {
+				break
+			}
+			short = short[1:]
+		}
+	}
+	if err := generateCmd.ParseFlags(genArgs); err != nil {
+		return fmt.Errorf("generate flags: %w", err)
+	}
+	if rest := generateCmd.Flags().Args(); len(rest) > 0 {
+		return fmt.Errorf("unexpected arguments after --: %s", strings.Join(rest, " "))
+	}
+	return nil
+}
+
+func showStore() *schedule.Store {
+	return schedule.NewStore(pipeline.OutputBaseDir)
+}
+
+func runShowsAdd(cmd *cobra.Command, args []string) error {
+	name, genArgs := args[0], args[1:]
+	// Parse the generate flags now so a typo fails here, not at 7am.
+	if err := checkGenerateArgs(genArgs, "saved with a show; the worker sets the input and output"); err != nil {
+		return err
+	}
+
+	show := schedule.Show{
+		Name:      name,
+		Source:    flagShowSource,
+		Feed:      flagShowFeed,
+		Cron:      flagShowCron,
+		Timezone:  flagShowTimezone,
+		Args:      genArgs,
+		Publish:   flagShowPublish,
+		Owner:     flagShowOwner,
+		CreatedAt: time.Now().UTC(),
+	}
+	if flagShowFeed {
+		show.FeedItems = flagShowFeedItems
+	}
+	if err := show.Validate(); err != nil {
+		return err
+	}
+
+	st := showStore()
+	shows, err := st.Load()
+	if err != nil {
+		return err
+	}
+	if _, err := schedule.Find(shows, name); err == nil {
+		return fmt.Errorf("show %q already exists; remove it first", name)
+	}
+	if err := st.Save(append(shows, show)); err != nil {
+		return err
+	}
+	next, _ := show.NextRun()
+	fmt.Printf("Saved show %s; next run %s.\n", name, next.Format("Mon Jan 2 15:04 MST"))
+	fmt.Println("Start the scheduler with: podcaster shows worker")
+	return nil
+}
+
+func runShowsList(cmd *cobra.Command, args []string) error {
+	shows, err := showStore().Load()
+	if err != nil {
+		return err
+	}
+	if len(shows) == 0 {
+		fmt.Println("No shows saved. Add one with: podcaster shows add <name> --source <url> --cron <schedule>")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSCHEDULE\tNEXT RUN\tLAST RUN\tSOURCE")
+	for _, s := range shows {
+		next := "-"
+		if t, err := s.NextRun(); err == nil {
+			next = t.Format("2006-01-02 15:04")
+		}
+		last := "never"
+		if !s.LastRun.IsZero() {
+			last = s.LastRun.Local().Format("2006-01-02 15:04")
+			if s.LastError != "" {
+				last += " (failed)"
+			}
+		}
+		source := s.Source
+		if s.Feed {
+			source = "feed " + source
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Name, s.Cron, next, last, source)
+	}
+	return w.Flush()
+}
+
+func runShowsRemove(cmd *cobra.Command, args []string) error {
+	st := showStore()
+	shows, err := st.Load()
+	if err != nil {
+		return err
+	}
+	kept := shows[:0]
+	for _, s := range shows {
+		if s.Name != args[0] {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) == len(shows) {
+		return fmt.Errorf("no show %q", args[0])
+	}
+	if err := st.Save(kept); err != nil {
+		return err
+	}
+	fmt.Printf("Removed show %s.\n", args[0])
+	return nil
+}
+
+func runShowsRun(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	shows, err := showStore().Load()
+	if err != nil {
+		return err
+	}
+	s, err := schedule.Find(shows, args[0])
+	if err != nil {
+		return err
+	}
+	runner, err := newShowRunner()
+	if err != nil {
+		return err
+	}
+	return runShow(ctx, runner, s)
+}
+
+func runShowsWorker(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	runner, err := newShowRunner()
+	if err != nil {
+		return err
+	}
+	st := showStore()
+	fmt.Printf("Show worker started (%s); checking every %s.\n", st.Path(), flagWorkerPoll)
+
+	ticker := time.NewTicker(flagWorkerPoll)
+	defer ticker.Stop()
+	for {
+		// Reload each time so shows added or removed while running apply.
+		shows, err := st.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+		}
+		for _, s := range shows {
+			if ctx.Err() != nil {
+				break
+			}
+			if s.Due(time.Now()) {
+				runShow(ctx, runner, s)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Println("Show worker stopped.")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func newShowRunner() (schedule.Runner, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return schedule.Runner{}, fmt.Errorf("find podcaster binary: %w", err)
+	}
+	return schedule.Runner{Exe: exe, OutputDir: pipeline.OutputBaseDir, Log: os.Stdout}, nil
+}
+
+// runShow runs one episode of s and records the outcome on the show.
+func runShow(ctx context.Context, runner schedule.Runner, s schedule.Show) error {
+	fmt.Printf("[%s] Running show %s\n", time.Now().Format("15:04:05"), s.Name)
+	started := time.Now().UTC()
+	episode, err := runner.Run(ctx, s)
+	switch {
+	case errors.Is(err, schedule.ErrNothingNew):
+		fmt.Printf("[%s] %s: no new feed items, skipped\n", time.Now().Format("15:04:05"), s.Name)
+		err = nil
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "[%s] %s failed: %v\n", time.Now().Format("15:04:05"), s.Name, err)
+	default:
+		fmt.Printf("[%s] %s: %s\n", time.Now().Format("15:04:05"), s.Name, episode)
+	}
+	uerr := showStore().Update(s.Name, func(sh *schedule.Show) {
+		sh.LastRun = started
+		sh.LastError = ""
+		if err != nil {
+			sh.LastError = err.Error()
+		}
+		if episode != "" {
+			sh.LastEpisode = episode
+			// A failed publish still made the episode; don't cover its
+			// items again.
+			sh.LastSuccess = started
+		}
+	})
+	if uerr != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: failed to record run of %s: %v\n", s.Name, uerr)
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/apresai/podcaster/internal/vault"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagWatchDir    string
+	flagWatchTag    string
+	flagWatchPoll   time.Duration
+	flagWatchSettle time.Duration
+	flagWatchOnce   bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch --dir <vault> [-- generate flags...]",
+	Short: "Generate episodes from flagged notes in a Markdown vault",
+	Long: "Watch a directory of Markdown notes (an Obsidian vault, say) and generate an episode for each note " +
+		"whose front matter is flagged with --tag (\"tags: [podcast]\" or \"podcast: true\"), one at a time. " +
+		"When the episode is done a link to it is appended to the note, which marks it done; delete the link " +
+		"to generate again. Flags after -- are passed to every generate run (not --input or --output).",
+	Example: "  podcaster watch --dir ~/Notes --tag podcast -- --format explainer --duration short",
+	Args:    cobra.ArbitraryArgs,
+	RunE:    runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringVar(&flagWatchDir, "dir", "", "Vault directory to watch, including subdirectories (required)")
+	watchCmd.Flags().StringVar(&flagWatchTag, "tag", "podcast", "Front matter tag that flags a note for an episode")
+	watchCmd.Flags().DurationVar(&flagWatchPoll, "poll", 30*time.Second, "How often to scan the vault")
+	watchCmd.Flags().DurationVar(&flagWatchSettle, "settle", vault.DefaultSettle, "How long a note must go unedited before it is used")
+	watchCmd.Flags().BoolVar(&flagWatchOnce, "once", false, "Scan once and exit")
+	watchCmd.MarkFlagRequired("dir")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if err := checkGenerateArgs(args, "passed to watch; it sets the input and output"); err != nil {
+		return err
+	}
+	if info, err := os.Stat(flagWatchDir); err != nil {
+		return fmt.Errorf("vault: %w", err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("vault: %s is not a directory", flagWatchDir)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	runner, err := newShowRunner()
+	if err != nil {
+		return err
+	}
+	w := &vault.Watcher{
+		Dir:    flagWatchDir,
+		Tag:    flagWatchTag,
+		Args:   args,
+		Settle: flagWatchSettle,
+		Runner: runner,
+		Log:    os.Stdout,
+	}
+
+	if flagWatchOnce {
+		n, err := w.Scan(ctx)
+		fmt.Printf("Generated %d episode(s).\n", n)
+		return err
+	}
+
+	fmt.Printf("Watching %s for notes tagged %q; scanning every %s.\n", flagWatchDir, flagWatchTag, flagWatchPoll)
+	ticker := time.NewTicker(flagWatchPoll)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Scan(ctx); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Println("Watch stopped.")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
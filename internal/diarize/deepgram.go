@@ -0,0 +1,119 @@
+package diarize
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
+)
+
+// deepgramListenURL transcribes the audio in the request body.
+const (
+	deepgramListenURL = "https://api.deepgram.com/v1/listen"
+	deepgramModel     = "nova-3"
+)
+
+// contentTypes are the request types for the episode formats.
+var contentTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".opus": "audio/ogg",
+	".ogg":  "audio/ogg",
+	".wav":  "audio/wav",
+}
+
+// Deepgram transcribes episodes with Deepgram's diarizing speech-to-text.
+type Deepgram struct {
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewDeepgram returns a client using apiKey, else DEEPGRAM_API_KEY.
+func NewDeepgram(apiKey string) (*Deepgram, error) {
+	if apiKey == "" {
+		apiKey = os.Getenv("DEEPGRAM_API_KEY")
+	}
+	if apiKey == "" {
+		return nil, errkind.New(errkind.UserInput, "speaker check needs a Deepgram API key (DEEPGRAM_API_KEY or --deepgram-api-key)")
+	}
+	return &Deepgram{apiKey: apiKey, httpClient: &http.Client{Timeout: 10 * time.Minute}}, nil
+}
+
+type deepgramResponse struct {
+	Results struct {
+		Channels []struct {
+			Alternatives []struct {
+				Words []Word `json:"words"`
+			} `json:"alternatives"`
+		} `json:"channels"`
+	} `json:"results"`
+}
+
+// Words transcribes the audio at path and returns its words with their
+// diarized speakers.
+func (d *Deepgram) Words(ctx context.Context, path string) ([]Word, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open episode: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat episode: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("model", deepgramModel)
+	params.Set("diarize", "true")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, deepgramListenURL+"?"+params.Encode(), f)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Authorization", "Token "+d.apiKey)
+	contentType, ok := contentTypes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		err := fmt.Errorf("Deepgram API error (status %d): %s", res.StatusCode, body)
+		switch {
+		case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+			return nil, errkind.Wrap(errkind.ProviderAuth, "Deepgram rejected the API key", err)
+		case res.StatusCode == http.StatusPaymentRequired || res.StatusCode == http.StatusTooManyRequests:
+			return nil, errkind.Wrap(errkind.ProviderQuota, "Deepgram quota or rate limit reached", err)
+		case res.StatusCode >= http.StatusInternalServerError:
+			return nil, errkind.Wrap(errkind.ProviderUnavailable, "Deepgram is unavailable", err)
+		}
+		return nil, err
+	}
+
+	var parsed deepgramResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	if len(parsed.Results.Channels) == 0 || len(parsed.Results.Channels[0].Alternatives) == 0 {
+		return nil, fmt.Errorf("Deepgram returned no transcript")
+	}
+	return parsed.Results.Channels[0].Alternatives[0].Words, nil
+}
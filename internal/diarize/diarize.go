@@ -0,0 +1,220 @@
+// Package diarize checks who speaks when in a finished episode against its
+// script. A speech-to-text service with speaker diarization transcribes the
+// episode; its words are aligned to the script's by text, which stays
+// reliable where timing is only estimated (batch TTS), and each segment's
+// words vote for the voice that spoke them. A segment mostly heard in
+// another host's voice is a mismatch, the kind of error multi-speaker batch
+// synthesis makes when it swaps voices for a stretch of dialogue.
+package diarize
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// Check thresholds. A segment needs minWords aligned words to be judged,
+// and is a mismatch when at least mismatchShare of them came from a voice
+// mapped to another speaker. alignWindow is how far ahead in the transcript
+// a script word is looked for, so a misheard word costs little.
+const (
+	minWords      = 5
+	mismatchShare = 0.6
+	alignWindow   = 6
+	minAligned    = 0.5 // below this, the check is noted as unreliable
+	snippetChars  = 60
+)
+
+// Word is one transcribed word and the diarized voice that spoke it.
+type Word struct {
+	Text    string  `json:"word"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Speaker int     `json:"speaker"`
+}
+
+// Report is the outcome of Check.
+type Report struct {
+	SpeakersDetected int     `json:"speakers_detected"`
+	SpeakersExpected int     `json:"speakers_expected"`
+	AlignedRatio     float64 `json:"aligned_ratio"` // script words found in the transcript
+	SegmentsChecked  int     `json:"segments_checked"`
+
+	// Voices maps each detected voice to the script speaker it spoke for
+	// most.
+	Voices map[string]string `json:"voices"`
+
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+
+	// Notes say why the check may be unreliable.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Mismatch is a segment heard mostly in another speaker's voice.
+type Mismatch struct {
+	Segment  int     `json:"segment"` // 1-based
+	Expected string  `json:"expected"`
+	Heard    string  `json:"heard"`
+	Share    float64 `json:"share"` // of the segment's aligned words
+	Start    float64 `json:"start"` // seconds, where its first aligned word was heard
+	Text     string  `json:"text"`
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("segment %d at %s: written for %s, heard as %s (%.0f%%): %q",
+		m.Segment, clock(m.Start), m.Expected, m.Heard, m.Share*100, m.Text)
+}
+
+// Passed reports whether no segment was heard in the wrong voice.
+func (r Report) Passed() bool {
+	return len(r.Mismatches) == 0
+}
+
+// Check aligns the transcribed words to s and reports segments spoken in
+// the wrong voice.
+func Check(s *script.Script, words []Word) Report {
+	r := Report{Voices: map[string]string{}}
+
+	// The script's words, each tagged with its segment.
+	type scriptWord struct {
+		text    string
+		segment int
+	}
+	var written []scriptWord
+	expected := map[string]bool{}
+	for i, seg := range s.Segments {
+		expected[seg.Speaker] = true
+		for _, w := range strings.Fields(seg.Text) {
+			if w = normalize(w); w != "" {
+				written = append(written, scriptWord{w, i})
+			}
+		}
+	}
+	r.SpeakersExpected = len(expected)
+
+	heard := make([]string, len(words))
+	detected := map[int]bool{}
+	for i, w := range words {
+		heard[i] = normalize(w.Text)
+		detected[w.Speaker] = true
+	}
+	r.SpeakersDetected = len(detected)
+
+	// Greedy alignment: each script word takes the next matching
+	// transcript word within alignWindow.
+	match := make([]int, len(written)) // transcript index, or -1
+	aligned := 0
+	next := 0
+	for i, w := range written {
+		match[i] = -1
+		for k := next; k < len(heard) && k < next+alignWindow; k++ {
+			if heard[k] == w.text {
+				match[i] = k
+				next = k + 1
+				aligned++
+				break
+			}
+		}
+	}
+	if len(written) > 0 {
+		r.AlignedRatio = float64(aligned) / float64(len(written))
+	}
+
+	// Map each detected voice to the speaker it spoke the most words for.
+	votes := map[int]map[string]int{}
+	for i, k := range match {
+		if k < 0 {
+			continue
+		}
+		v := words[k].Speaker
+		if votes[v] == nil {
+			votes[v] = map[string]int{}
+		}
+		votes[v][s.Segments[written[i].segment].Speaker]++
+	}
+	voiceOf := map[int]string{}
+	for v, counts := range votes {
+		best, n := "", 0
+		for speaker, c := range counts {
+			if c > n || (c == n && speaker < best) {
+				best, n = speaker, c
+			}
+		}
+		voiceOf[v] = best
+		r.Voices[fmt.Sprintf("speaker_%d", v)] = best
+	}
+
+	// Judge each segment by the voices its aligned words were heard in.
+	type tally struct {
+		counts map[string]int
+		total  int
+		start  float64
+	}
+	tallies := make([]tally, len(s.Segments))
+	for i, k := range match {
+		if k < 0 {
+			continue
+		}
+		t := &tallies[written[i].segment]
+		if t.counts == nil {
+			t.counts = map[string]int{}
+			t.start = words[k].Start
+		}
+		t.counts[voiceOf[words[k].Speaker]]++
+		t.total++
+	}
+	for i, t := range tallies {
+		if t.total < minWords {
+			continue
+		}
+		r.SegmentsChecked++
+		want := s.Segments[i].Speaker
+		for speaker, c := range t.counts {
+			share := float64(c) / float64(t.total)
+			if speaker != want && share >= mismatchShare {
+				r.Mismatches = append(r.Mismatches, Mismatch{
+					Segment:  i + 1,
+					Expected: want,
+					Heard:    speaker,
+					Share:    share,
+					Start:    t.start,
+					Text:     snippet(s.Segments[i].Text),
+				})
+			}
+		}
+	}
+
+	if r.AlignedRatio < minAligned {
+		r.Notes = append(r.Notes, fmt.Sprintf("only %.0f%% of the script was found in the transcript", r.AlignedRatio*100))
+	}
+	if r.SpeakersDetected < r.SpeakersExpected {
+		r.Notes = append(r.Notes, fmt.Sprintf("heard %d voices for %d speakers; similar voices may have been merged", r.SpeakersDetected, r.SpeakersExpected))
+	}
+	return r
+}
+
+// normalize lowercases w and drops everything but letters and digits, so
+// script text compares with a transcript's words.
+func normalize(w string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, w)
+}
+
+func snippet(text string) string {
+	r := []rune(text)
+	if len(r) <= snippetChars {
+		return text
+	}
+	return strings.TrimSpace(string(r[:snippetChars])) + "…"
+}
+
+func clock(secs float64) string {
+	s := int(secs)
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
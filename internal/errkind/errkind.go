@@ -0,0 +1,130 @@
+// Package errkind classifies errors by who can fix them, so the hosted
+// service can tell a client "your URL is bad" apart from "our provider is
+// down" without parsing messages. Errors get a Kind either by implementing
+// Classified (tts.QuotaExhaustedError, pipeline.PipelineError, ...) or by
+// being wrapped in an *Error where they are created.
+package errkind
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind is an error category. Its string form is what clients see (the MCP
+// error prefix and get_podcast's error_kind).
+type Kind string
+
+const (
+	// UserInput: the request itself is the problem (unreachable URL, empty
+	// or unreadable document, unknown voice). Retrying won't help; changing
+	// the input will.
+	UserInput Kind = "user_input"
+	// ProviderQuota: a script or TTS provider's rate limit or quota ran out.
+	// Retrying later, or with another provider, may succeed.
+	ProviderQuota Kind = "provider_quota"
+	// ProviderAuth: a provider rejected the API key or credentials. With a
+	// BYOK key that's the caller's key; otherwise it's ours.
+	ProviderAuth Kind = "provider_auth"
+	// ProviderUnavailable: a provider kept failing (5xx, timeouts) or its
+	// circuit breaker is open.
+	ProviderUnavailable Kind = "provider_unavailable"
+	// Internal: anything else, including unclassified errors. Details are
+	// logged, not shown.
+	Internal Kind = "internal"
+)
+
+// HTTPStatus returns the HTTP status that corresponds to k.
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case UserInput:
+		return http.StatusBadRequest
+	case ProviderQuota:
+		return http.StatusTooManyRequests
+	case ProviderAuth:
+		return http.StatusBadGateway
+	case ProviderUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Classified is implemented by errors that know their Kind. The text of a
+// non-Internal Classified error is safe to show to the user.
+type Classified interface {
+	error
+	ErrorKind() Kind
+}
+
+// Error attaches a Kind and a user-facing message to an underlying error.
+type Error struct {
+	Kind    Kind
+	Message string // shown to the user, whatever the Kind
+	Err     error  // the cause; shown only for non-Internal kinds
+}
+
+// New returns an *Error with no underlying cause.
+func New(kind Kind, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap returns an *Error with err as its cause, or nil if err is nil.
+func Wrap(kind Kind, message string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func (e *Error) ErrorKind() Kind { return e.Kind }
+
+// Of returns the Kind of the outermost Classified error in err's chain, or
+// Internal if there is none. Of(nil) is "".
+func Of(err error) Kind {
+	if err == nil {
+		return ""
+	}
+	var c Classified
+	if errors.As(err, &c) {
+		return c.ErrorKind()
+	}
+	return Internal
+}
+
+// internalMessage stands in for the details of an Internal error.
+const internalMessage = "internal error; please try again, and contact support if it persists"
+
+// UserMessage returns the text of err that is safe to show the user: the
+// outermost Classified error's message for non-Internal kinds, an *Error's
+// own Message for Internal ones, and a generic message otherwise.
+func UserMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	var c Classified
+	if !errors.As(err, &c) {
+		return internalMessage
+	}
+	if e, ok := c.(*Error); ok && e.Kind == Internal {
+		return e.Message
+	}
+	if c.ErrorKind() == Internal {
+		return internalMessage
+	}
+	return c.Error()
+}
+
+// Format returns the client-facing error string for err: its Kind, then its
+// user message ("user_input: could not fetch URL ...: HTTP 404").
+func Format(err error) string {
+	return string(Of(err)) + ": " + UserMessage(err)
+}
@@ -0,0 +1,132 @@
+// Package feature gates changes being rolled out gradually. A flag is on
+// for a percentage of keys (hosted jobs, by podcast ID): the MCP server
+// decides per job from its configured rollouts, optionally overridden in
+// DynamoDB without a redeploy, and hands the pipeline the Set that is on.
+// Remove a flag once its change is fully rolled out.
+package feature
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Flag names.
+const (
+	// ParallelTTS doubles the default number of per-segment TTS workers
+	// (pipeline.DefaultTTSConcurrency); per-provider caps still apply.
+	ParallelTTS = "parallel-tts"
+)
+
+// Known describes each flag.
+var Known = map[string]string{
+	ParallelTTS: "twice the default per-segment TTS workers",
+}
+
+// Rollouts is the percentage (0-100) of keys each flag is on for.
+type Rollouts map[string]int
+
+// ParseRollouts parses "name=percent,..." (a bare name is 100%), as in
+// PODCASTER_FEATURES.
+func ParseRollouts(spec string) (Rollouts, error) {
+	r := Rollouts{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		pct := 100
+		if ok {
+			n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid rollout %q: want name=percent", part)
+			}
+			pct = n
+		}
+		r[strings.TrimSpace(name)] = pct
+	}
+	return r, r.Validate()
+}
+
+// Validate reports unknown flags and percentages outside 0-100.
+func (r Rollouts) Validate() error {
+	var errs []error
+	for _, name := range r.names() {
+		if _, ok := Known[name]; !ok {
+			errs = append(errs, fmt.Errorf("unknown feature flag %q", name))
+		}
+		if pct := r[name]; pct < 0 || pct > 100 {
+			errs = append(errs, fmt.Errorf("feature flag %s: rollout %d%% must be 0-100", name, pct))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// String returns r in the form ParseRollouts accepts, sorted by name.
+func (r Rollouts) String() string {
+	var parts []string
+	for _, name := range r.names() {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, r[name]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Merge returns r with over's rollouts replacing its own.
+func (r Rollouts) Merge(over Rollouts) Rollouts {
+	out := Rollouts{}
+	for name, pct := range r {
+		out[name] = pct
+	}
+	for name, pct := range over {
+		out[name] = pct
+	}
+	return out
+}
+
+// Enabled returns the flags on for key. Each flag hashes the key with its
+// name into one of 100 buckets, so a key's flags are stable across
+// processes and raising a rollout only adds keys.
+func (r Rollouts) Enabled(key string) Set {
+	var on Set
+	for _, name := range r.names() {
+		if pct := r[name]; pct >= 100 || (pct > 0 && bucket(name, key) < pct) {
+			on = append(on, name)
+		}
+	}
+	return on
+}
+
+func (r Rollouts) names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func bucket(name, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name + "\x00" + key))
+	return int(h.Sum32() % 100)
+}
+
+// Set is the flags on for one run, sorted.
+type Set []string
+
+// On reports whether flag name is on.
+func (s Set) On(name string) bool {
+	return slices.Contains(s, name)
+}
+
+// String returns the flags comma-separated, or "none".
+func (s Set) String() string {
+	if len(s) == 0 {
+		return "none"
+	}
+	return strings.Join(s, ",")
+}
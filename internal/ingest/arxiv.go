@@ -0,0 +1,418 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/apresai/podcaster/internal/observability"
+	"github.com/ledongthuc/pdf"
+	"golang.org/x/net/html"
+)
+
+// An arXiv paper ("2401.04088", "arXiv:2401.04088v2", or an arxiv.org
+// abs/pdf/html URL) is read from its LaTeXML HTML rendering rather than the
+// PDF, whose extracted equations come out as symbol soup the script writer
+// then reads aloud. Title, authors, and abstract come from the arXiv API;
+// the body leaves out references, figures, tables, display equations,
+// footnotes, citation marks, and appendices. Papers without an HTML
+// rendering (older ones, or LaTeX that LaTeXML couldn't convert) fall back
+// to the PDF with references, captions, and garbled lines stripped.
+
+// ArXivIngester reads an arXiv paper by ID or URL.
+type ArXivIngester struct{}
+
+// arXiv endpoints, variables so a mirror can stand in.
+var (
+	arxivAPI  = "https://export.arxiv.org/api/query"
+	arxivBase = "https://arxiv.org"
+)
+
+var (
+	// arxivNewID matches IDs since 2007 (YYMM.NNNNN) with an optional
+	// version; arxivOldID the archive/YYMMNNN IDs before them.
+	arxivNewID = regexp.MustCompile(`^(\d{4}\.\d{4,5})(v\d+)?$`)
+	arxivOldID = regexp.MustCompile(`^([a-z-]+(?:\.[A-Z]{2})?/\d{7})(v\d+)?$`)
+
+	// arxivVersion is an ID's version suffix.
+	arxivVersion = regexp.MustCompile(`v\d+$`)
+
+	// arxivPath matches the ID in an arxiv.org URL path.
+	arxivPath = regexp.MustCompile(`^/(?:abs|pdf|html)/(.+?)(?:\.pdf)?/?$`)
+)
+
+// errArXivNotFound is a 404 from arXiv: no such paper, or no HTML for it.
+var errArXivNotFound = errors.New("not found")
+
+// ParseArXivID returns the arXiv ID (with its version, if given) that input
+// names: an ID with or without an "arXiv:" prefix, or an abs, pdf, or html
+// URL on arxiv.org. A bare ID that is also an existing file's name is the
+// file.
+func ParseArXivID(input string) (string, bool) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		u, err := url.Parse(input)
+		if err != nil {
+			return "", false
+		}
+		switch strings.ToLower(u.Host) {
+		case "arxiv.org", "www.arxiv.org", "export.arxiv.org":
+		default:
+			return "", false
+		}
+		m := arxivPath.FindStringSubmatch(u.Path)
+		if m == nil {
+			return "", false
+		}
+		// html URLs can go on to a page of the paper: /html/2401.04088v1/S2
+		input, _, _ = strings.Cut(m[1], "/S")
+	} else if id, ok := strings.CutPrefix(strings.ToLower(input), "arxiv:"); ok {
+		input = input[len(input)-len(id):]
+	} else if fileExists(input) {
+		return "", false
+	}
+	if arxivNewID.MatchString(input) || arxivOldID.MatchString(input) {
+		return input, true
+	}
+	return "", false
+}
+
+func (a *ArXivIngester) Ingest(ctx context.Context, source string) (*Content, error) {
+	id, ok := ParseArXivID(source)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an arXiv ID or URL", source)
+	}
+	meta, err := fetchArXivMeta(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := fetchArXivHTML(ctx, id)
+	if err != nil {
+		slog.Warn("arXiv HTML unavailable, falling back to the PDF", "id", id, "error", err)
+		var pdfErr error
+		if body, pdfErr = fetchArXivPDF(ctx, id); pdfErr != nil {
+			return nil, fmt.Errorf("could not fetch arXiv %s: html=%v, pdf=%v", id, err, pdfErr)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", meta.Title)
+	if len(meta.Authors) > 0 {
+		fmt.Fprintf(&sb, "By %s\n\n", strings.Join(meta.Authors, ", "))
+	}
+	if meta.Abstract != "" {
+		fmt.Fprintf(&sb, "## Abstract\n\n%s\n\n", meta.Abstract)
+	}
+	sb.WriteString(body)
+	text := strings.TrimSpace(sb.String())
+	return &Content{
+		Text:      text,
+		Title:     meta.Title,
+		Source:    "arXiv:" + id,
+		WordCount: wordCount(text),
+	}, nil
+}
+
+// arxivMeta is what the arXiv API says about a paper.
+type arxivMeta struct {
+	Title    string
+	Authors  []string
+	Abstract string
+}
+
+// fetchArXivMeta looks the paper up in the arXiv API (an Atom feed).
+func fetchArXivMeta(ctx context.Context, id string) (*arxivMeta, error) {
+	data, err := arxivGet(ctx, arxivAPI+"?id_list="+url.QueryEscape(id))
+	if err != nil {
+		return nil, fmt.Errorf("could not look up arXiv %s: %w", id, err)
+	}
+	var feed struct {
+		Entries []struct {
+			ID      string `xml:"id"`
+			Title   string `xml:"title"`
+			Summary string `xml:"summary"`
+			Authors []struct {
+				Name string `xml:"name"`
+			} `xml:"author"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("could not parse arXiv API response for %s: %w", id, err)
+	}
+	// A malformed ID comes back as an entry whose id is an error page.
+	if len(feed.Entries) == 0 || strings.Contains(feed.Entries[0].ID, "/api/errors") || feed.Entries[0].Title == "" {
+		return nil, fmt.Errorf("arXiv paper %s not found", id)
+	}
+	e := feed.Entries[0]
+	meta := &arxivMeta{
+		Title:    collapseSpace(e.Title),
+		Abstract: collapseSpace(e.Summary),
+	}
+	for _, a := range e.Authors {
+		meta.Authors = append(meta.Authors, collapseSpace(a.Name))
+	}
+	return meta, nil
+}
+
+// arxivGet fetches url from arXiv, up to maxInputSize.
+func arxivGet(ctx context.Context, url string) ([]byte, error) {
+	client := &http.Client{Timeout: 60 * time.Second, Transport: observability.Transport(nil)}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Podcaster/1.0; +https://podcasts.apresai.dev)")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errArXivNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxInputSize))
+}
+
+// fetchArXivHTML returns the body of the paper's HTML rendering.
+func fetchArXivHTML(ctx context.Context, id string) (string, error) {
+	data, err := arxivGet(ctx, arxivBase+"/html/"+id)
+	if err != nil {
+		return "", err
+	}
+	text, err := arxivHTMLText(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	// arXiv answers some papers it couldn't convert with a stub page.
+	if wordCount(text) < MinWordCount {
+		return "", fmt.Errorf("HTML rendering has no body text")
+	}
+	return text, nil
+}
+
+// fetchArXivPDF returns the paper's PDF text, cleaned by cleanArXivPDF.
+func fetchArXivPDF(ctx context.Context, id string) (string, error) {
+	data, err := arxivGet(ctx, arxivBase+"/pdf/"+id)
+	if err != nil {
+		return "", err
+	}
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("could not read PDF: %w", err)
+	}
+	text := cleanArXivPDF(pdfText(r))
+	if text == "" {
+		return "", fmt.Errorf("could not extract text from the PDF")
+	}
+	return text, nil
+}
+
+// arxivSkipClasses are the LaTeXML classes of elements left out of the
+// text, with everything in them. The title, authors, and abstract come
+// from the API instead.
+var arxivSkipClasses = []string{
+	"ltx_bibliography", "ltx_appendix", "ltx_acknowledgements",
+	"ltx_figure", "ltx_table", "ltx_float",
+	"ltx_equation", "ltx_equationgroup", "ltx_eqn_table",
+	"ltx_note", "ltx_ERROR", "ltx_tag_item",
+	"ltx_title_document", "ltx_authors", "ltx_abstract", "ltx_dates", "ltx_keywords", "ltx_classification",
+}
+
+// arxivSkipTags are elements left out wherever they appear. cite is a
+// citation mark ("[12]", "Smith et al., 2020").
+var arxivSkipTags = map[string]bool{
+	"head": true, "script": true, "style": true, "nav": true, "header": true, "footer": true,
+	"figure": true, "cite": true, "svg": true, "button": true,
+}
+
+// arxivHTMLText extracts a LaTeXML document's body a paragraph at a time,
+// with section headings as Markdown headings and inline math as speakable
+// text (see speakableMath).
+func arxivHTMLText(r io.Reader) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", err
+	}
+	root := doc
+	if article := findElement(doc, func(n *html.Node) bool { return hasClass(n, "ltx_document") }); article != nil {
+		root = article // not arXiv's page chrome around it
+	}
+	var p paragraphs
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			p.cur.WriteString(n.Data)
+			return
+		case html.ElementNode:
+		default:
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c)
+			}
+			return
+		}
+		if arxivSkipTags[n.Data] {
+			return
+		}
+		for _, class := range arxivSkipClasses {
+			if hasClass(n, class) {
+				return
+			}
+		}
+		switch n.Data {
+		case "math":
+			if attr(n, "display") != "block" {
+				p.cur.WriteString(" " + speakableMath(attr(n, "alttext")) + " ")
+			}
+			return
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			p.end()
+			p.level = int(n.Data[1] - '0')
+		case "br":
+			p.cur.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		switch n.Data {
+		case "p", "li", "dd", "dt", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote":
+			p.cur.WriteString(" ")
+			// Left-out citations and footnotes leave "work ." behind.
+			line := spaceBeforePunct.ReplaceAllString(collapseSpace(p.cur.String()), "$1")
+			p.cur.Reset()
+			p.cur.WriteString(line)
+			p.end()
+		}
+	}
+	walk(root)
+	p.end()
+	return strings.TrimSpace(p.String()), nil
+}
+
+func findElement(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if n.Type == html.ElementNode && match(n) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// maxSpokenMath is the longest inline formula kept as text; longer ones
+// are replaced by "[equation]".
+const maxSpokenMath = 30
+
+var (
+	// texFontCmd are TeX commands that only style their argument.
+	texFontCmd = regexp.MustCompile(`\\(?:math[a-z]+|text[a-z]*|operatorname|bm|boldsymbol|left|right|big|Big|bigg|Bigg)\b\*?`)
+	texCmd     = regexp.MustCompile(`\\([a-zA-Z]+)`)
+	texSpacing = regexp.MustCompile(`\\[,;:! ]`)
+
+	// spaceBeforePunct is a space before closing punctuation; mathOpSpace
+	// spaces around an operator in a formula.
+	spaceBeforePunct = regexp.MustCompile(` +([.,;:!?)])`)
+	mathOpSpace      = regexp.MustCompile(` *([=<>+^_]) *`)
+)
+
+// speakableMath turns an inline formula's TeX (LaTeXML's alttext) into
+// something the script writer can read: "\mathcal{O}(n^{2})" becomes
+// "O(n^2)" and "\alpha" "alpha". Long formulas become "[equation]".
+func speakableMath(tex string) string {
+	s := texFontCmd.ReplaceAllString(tex, "")
+	s = texSpacing.ReplaceAllString(s, " ")
+	s = texCmd.ReplaceAllString(s, "$1 ")
+	s = strings.NewReplacer("{", "", "}", "", "~", " ", "$", "").Replace(s)
+	s = mathOpSpace.ReplaceAllString(collapseSpace(s), "$1")
+	s = spaceBeforePunct.ReplaceAllString(s, "$1")
+	if s == "" || len(s) > maxSpokenMath {
+		return "[equation]"
+	}
+	return s
+}
+
+var (
+	// arxivRefHeading is the heading the references start at in PDF text.
+	arxivRefHeading = regexp.MustCompile(`^(?:\d+\.?\s*)?(?:References|REFERENCES|Bibliography|BIBLIOGRAPHY)$`)
+	// arxivCaption starts a figure or table caption.
+	arxivCaption = regexp.MustCompile(`^(?:Figure|Fig\.|Table)\s+\d+[.:]`)
+	// arxivStamp is the ID stamp arXiv puts in the margin of the first page.
+	arxivStamp = regexp.MustCompile(`^arXiv:\S+\s+\[`)
+)
+
+// cleanArXivPDF strips what PDF extraction of a paper gets wrong or the
+// hosts shouldn't read: everything from the last references heading on,
+// figure and table captions, the arXiv stamp, and lines that are mostly
+// symbols (equations, table cells, page numbers).
+func cleanArXivPDF(text string) string {
+	lines := strings.Split(text, "\n")
+	for i := len(lines) - 1; i > len(lines)/3; i-- {
+		if arxivRefHeading.MatchString(strings.TrimSpace(lines[i])) {
+			lines = lines[:i]
+			break
+		}
+	}
+	var kept []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || arxivCaption.MatchString(line) || arxivStamp.MatchString(line) || !mostlyWords(line) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// mostlyWords reports whether at least half of line's non-space characters
+// are letters, and it has a word of three or more letters.
+func mostlyWords(line string) bool {
+	letters, others, run, longest := 0, 0, 0, 0
+	for _, r := range line {
+		switch {
+		case r == ' ' || r == '\t':
+			run = 0
+		case unicode.IsLetter(r):
+			letters++
+			run++
+			longest = max(longest, run)
+		default:
+			others++
+			run = 0
+		}
+	}
+	return longest >= 3 && letters >= others
+}
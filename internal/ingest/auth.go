@@ -0,0 +1,242 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/textproto"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/observability"
+	"golang.org/x/net/http/httpguts"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Auth holds credentials for URL inputs behind a login wall or internal
+// SSO: request headers (--header) and browser cookies (--cookie-jar). Only
+// ingest's own requests carry them. Headers go only to the hosts of the URL
+// inputs, so an article linked from a thread or a feed's items on other
+// sites don't see them. Cookies go wherever their domain matches, as in a
+// browser. Nothing is sent to the Jina Reader fallback.
+type Auth struct {
+	Header http.Header
+	Hosts  []string // hosts Header is sent to
+	Jar    http.CookieJar
+}
+
+type authKey struct{}
+
+// ContextWithAuth returns ctx carrying auth for ingest's requests.
+func ContextWithAuth(ctx context.Context, auth *Auth) context.Context {
+	return context.WithValue(ctx, authKey{}, auth)
+}
+
+func authFrom(ctx context.Context) *Auth {
+	a, _ := ctx.Value(authKey{}).(*Auth)
+	return a
+}
+
+// NewAuth builds the credentials for inputs from "Name: value" headers and
+// a Netscape-format cookies.txt (as exported by browser extensions or
+// written by curl -c). It returns nil when there are neither.
+func NewAuth(headers []string, cookieJar string, inputs []string) (*Auth, error) {
+	if len(headers) == 0 && cookieJar == "" {
+		return nil, nil
+	}
+	a := &Auth{}
+	if len(headers) > 0 {
+		h, err := ParseHeaders(headers)
+		if err != nil {
+			return nil, err
+		}
+		a.Header = h
+		for _, in := range inputs {
+			if host := inputHost(in); host != "" && !slices.Contains(a.Hosts, host) {
+				a.Hosts = append(a.Hosts, host)
+			}
+		}
+	}
+	if cookieJar != "" {
+		jar, err := LoadCookieJar(cookieJar)
+		if err != nil {
+			return nil, err
+		}
+		a.Jar = jar
+	}
+	return a, nil
+}
+
+// ParseHeaders parses "Name: value" request headers.
+func ParseHeaders(values []string) (http.Header, error) {
+	h := http.Header{}
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("header %q: want \"Name: value\"", v)
+		}
+		if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) {
+			return nil, fmt.Errorf("header %q: invalid name or value", name)
+		}
+		switch name = textproto.CanonicalMIMEHeaderKey(name); name {
+		case "Host", "Content-Length", "Transfer-Encoding", "Connection":
+			return nil, fmt.Errorf("header %s can't be set", name)
+		}
+		h.Add(name, value)
+	}
+	return h, nil
+}
+
+// LoadCookieJar reads a Netscape-format cookies.txt: one cookie per line,
+// with tab-separated domain, include-subdomains flag, path, secure flag,
+// expiry (Unix seconds, 0 for a session cookie), name, and value. Lines
+// starting with # are comments, except #HttpOnly_ cookies. Expired cookies
+// are skipped.
+func LoadCookieJar(path string) (http.CookieJar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cookie jar: %w", err)
+	}
+	defer f.Close()
+
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	n := 0
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimRight(sc.Text(), "\r")
+		httpOnly := strings.HasPrefix(text, "#HttpOnly_")
+		if httpOnly {
+			text = strings.TrimPrefix(text, "#HttpOnly_")
+		} else if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("cookie jar %s:%d: want 7 tab-separated fields, got %d", path, line, len(fields))
+		}
+		expiry, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cookie jar %s:%d: invalid expiry %q", path, line, fields[4])
+		}
+		c := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+		}
+		if expiry > 0 {
+			c.Expires = time.Unix(expiry, 0)
+			if c.Expires.Before(now) {
+				continue
+			}
+		}
+		host := strings.TrimPrefix(fields[0], ".")
+		if strings.EqualFold(fields[1], "TRUE") {
+			c.Domain = host
+		}
+		scheme := "http"
+		if c.Secure {
+			scheme = "https"
+		}
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: c.Path}, []*http.Cookie{c})
+		n++
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("cookie jar: %w", err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("cookie jar %s has no unexpired cookies", path)
+	}
+	return jar, nil
+}
+
+// HeaderNames lists the header names, for logging without the values.
+func (a *Auth) HeaderNames() []string {
+	if a == nil {
+		return nil
+	}
+	var names []string
+	for name := range a.Header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// client returns an HTTP client for ingest's requests, with a's cookies.
+// A redirect away from a's hosts loses a's headers (net/http would keep
+// all but Authorization and Cookie). Under ContextPublicOnly it only
+// connects to public addresses.
+func (a *Auth) client(ctx context.Context, timeout time.Duration) *http.Client {
+	var base http.RoundTripper
+	if publicOnly(ctx) {
+		base = PublicTransport
+	}
+	c := &http.Client{Timeout: timeout, Transport: observability.Transport(base)}
+	if a == nil {
+		return c
+	}
+	c.Jar = a.Jar
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		if !slices.Contains(a.Hosts, strings.ToLower(req.URL.Hostname())) {
+			for name := range a.Header {
+				req.Header.Del(name)
+			}
+		}
+		return nil
+	}
+	return c
+}
+
+// apply sets a's headers on req if it goes to one of a's hosts.
+func (a *Auth) apply(req *http.Request) {
+	if a == nil || !slices.Contains(a.Hosts, strings.ToLower(req.URL.Hostname())) {
+		return
+	}
+	for name, values := range a.Header {
+		req.Header[name] = values
+	}
+}
+
+// covers reports whether a sends credentials with a request to rawURL.
+func (a *Auth) covers(rawURL string) bool {
+	if a == nil {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if len(a.Header) > 0 && slices.Contains(a.Hosts, strings.ToLower(u.Hostname())) {
+		return true
+	}
+	return a.Jar != nil && len(a.Jar.Cookies(u)) > 0
+}
+
+// inputHost returns the host of a URL input, or "" for other inputs.
+func inputHost(input string) string {
+	if !strings.HasPrefix(input, "http://") && !strings.HasPrefix(input, "https://") {
+		return ""
+	}
+	u, err := url.Parse(input)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
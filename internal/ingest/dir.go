@@ -0,0 +1,305 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// A directory or glob input ("docs", "docs/**/*.md") turns a set of files,
+// typically a project's Markdown docs, into one episode. Files are read in
+// path order, README and index files first in each directory, each under a
+// "=== FILE: path ===" header. A .podcasterignore in the directory (or the
+// glob's base directory) leaves files out, in .gitignore syntax.
+
+// DirIngester reads a directory's Markdown and text files, or the files a
+// glob matches.
+type DirIngester struct{}
+
+// dirExts are the files a directory input reads. A glob reads whatever it
+// matches, each file with its own ingester.
+var dirExts = map[string]bool{".md": true, ".markdown": true, ".mdx": true, ".txt": true}
+
+// maxDirFiles caps how many files one directory or glob input may read.
+const maxDirFiles = 500
+
+// ignoreFile is the per-input ignore list.
+const ignoreFile = ".podcasterignore"
+
+// isGlob reports whether input is a file pattern rather than a path.
+func isGlob(input string) bool {
+	return strings.ContainsAny(input, "*?[")
+}
+
+func isDir(input string) bool {
+	info, err := os.Stat(input)
+	return err == nil && info.IsDir()
+}
+
+func (d *DirIngester) Ingest(ctx context.Context, source string) (*Content, error) {
+	root, pattern := source, ""
+	if isGlob(source) {
+		root, pattern = globBase(source)
+	}
+	if !isDir(root) {
+		return nil, fmt.Errorf("cannot access directory %s", root)
+	}
+	ignore, err := readIgnore(filepath.Join(root, ignoreFile))
+	if err != nil {
+		return nil, err
+	}
+
+	// Without "**", a pattern can't match below its own depth.
+	maxDepth := -1
+	if pattern != "" && !strings.Contains(pattern, "**") {
+		maxDepth = strings.Count(pattern, "/") + 1
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		// Hidden files and directories (.git, .github) never count.
+		if strings.HasPrefix(e.Name(), ".") || ignore.ignored(rel, e.IsDir()) {
+			if e.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if e.IsDir() {
+			if maxDepth > 0 && strings.Count(rel, "/")+1 >= maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !e.Type().IsRegular() {
+			return nil
+		}
+		if pattern != "" && !globMatch(pattern, rel) || pattern == "" && !dirExts[strings.ToLower(path.Ext(rel))] {
+			return nil
+		}
+		if files = append(files, rel); len(files) > maxDirFiles {
+			return fmt.Errorf("%s matches more than %d files; narrow it down or add a %s", source, maxDirFiles, ignoreFile)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		if pattern != "" {
+			return nil, fmt.Errorf("no files match %s", source)
+		}
+		return nil, fmt.Errorf("no Markdown or text files in %s", source)
+	}
+	sortDocFiles(files)
+
+	var parts []string
+	var title string
+	size := 0
+	for _, rel := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		c, err := NewIngester(p).Ingest(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		text := strings.TrimSpace(stripFrontMatter(c.Text))
+		if text == "" {
+			continue
+		}
+		if size += len(text); size > maxInputSize {
+			return nil, fmt.Errorf("%s is too large (over %d MB of text)", source, maxInputSize/(1024*1024))
+		}
+		if title == "" {
+			title = markdownTitle(text)
+		}
+		parts = append(parts, fmt.Sprintf("=== FILE: %s ===\n\n%s", rel, text))
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("the files of %s are empty", source)
+	}
+	if title == "" {
+		title = filepath.Base(filepath.Clean(root))
+	}
+	text := strings.Join(parts, "\n\n")
+	return &Content{
+		Text:      text,
+		Title:     title,
+		Source:    fmt.Sprintf("%s (%d files)", filepath.Base(filepath.Clean(root)), len(parts)),
+		WordCount: wordCount(text),
+	}, nil
+}
+
+// globBase splits a glob into the directory before its first wildcard and
+// the rest of the pattern, relative to that directory.
+func globBase(glob string) (root, pattern string) {
+	glob = filepath.ToSlash(glob)
+	parts := strings.Split(glob, "/")
+	i := 0
+	for i < len(parts)-1 && !isGlob(parts[i]) {
+		i++
+	}
+	root = strings.Join(parts[:i], "/")
+	if root == "" {
+		root = "."
+		if strings.HasPrefix(glob, "/") {
+			root = "/"
+		}
+	}
+	return filepath.FromSlash(root), strings.Join(parts[i:], "/")
+}
+
+// globMatch matches a slash-separated path against a pattern in which "**"
+// stands for any number of directories and other parts are path.Match
+// patterns.
+func globMatch(pattern, name string) bool {
+	return matchParts(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchParts(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchParts(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// sortDocFiles orders files by directory, with a directory's README or
+// index ahead of its other files and its subdirectories.
+func sortDocFiles(files []string) {
+	key := func(rel string) string {
+		dir, base := path.Split(rel)
+		stem := strings.ToLower(strings.TrimSuffix(base, path.Ext(base)))
+		if stem == "readme" || stem == "index" {
+			base = "\x00" + base
+		}
+		// "\x01" sorts a directory's files ahead of its subdirectories.
+		return strings.ReplaceAll(dir, "/", "\x02") + "\x01" + base
+	}
+	sort.Slice(files, func(i, j int) bool { return key(files[i]) < key(files[j]) })
+}
+
+// stripFrontMatter removes a leading YAML front matter block (--- ... ---)
+// from Markdown.
+func stripFrontMatter(text string) string {
+	if !strings.HasPrefix(text, "---\n") && !strings.HasPrefix(text, "---\r\n") {
+		return text
+	}
+	_, rest, _ := strings.Cut(text, "\n")
+	for {
+		line, next, ok := strings.Cut(rest, "\n")
+		if strings.TrimSpace(line) == "---" {
+			return next
+		}
+		if !ok {
+			return text // unterminated: not front matter
+		}
+		rest = next
+	}
+}
+
+// markdownTitle returns the first "# " heading of Markdown text, or "".
+func markdownTitle(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if t, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			return strings.TrimSpace(t)
+		}
+	}
+	return ""
+}
+
+// ignoreRule is one .podcasterignore line.
+type ignoreRule struct {
+	pattern  string
+	negate   bool // "!pattern" re-includes
+	dirOnly  bool // "pattern/" matches directories only
+	anchored bool // contains a slash: matched against the whole path
+}
+
+type ignoreRules []ignoreRule
+
+// readIgnore parses a .podcasterignore: one pattern per line, "#"
+// comments, "!" to re-include, a trailing "/" for directories only, and a
+// leading or inner "/" to match the path from the ignore file's directory
+// rather than a name at any depth. A missing file ignores nothing.
+func readIgnore(file string) (ignoreRules, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", file, err)
+	}
+	defer f.Close()
+	var rules ignoreRules
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var r ignoreRule
+		if r.negate = strings.HasPrefix(line, "!"); r.negate {
+			line = line[1:]
+		}
+		if r.dirOnly = strings.HasSuffix(line, "/"); r.dirOnly {
+			line = strings.TrimSuffix(line, "/")
+		}
+		r.anchored = strings.Contains(line, "/")
+		r.pattern = strings.TrimPrefix(line, "/")
+		if r.pattern != "" {
+			rules = append(rules, r)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", file, err)
+	}
+	return rules, nil
+}
+
+// ignored reports whether rel (slash-separated, relative to the ignore
+// file's directory) is ignored. The last matching rule wins.
+func (rules ignoreRules) ignored(rel string, dir bool) bool {
+	ignored := false
+	for _, r := range rules {
+		if r.dirOnly && !dir {
+			continue
+		}
+		name := rel
+		if !r.anchored {
+			name = path.Base(rel)
+		}
+		if globMatch(r.pattern, name) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,471 @@
+package ingest
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EPUBIngester reads an EPUB 2 or 3 book. Chapters are the book's top-level
+// table of contents entries (the EPUB 3 nav document, else the EPUB 2 NCX),
+// each running from its document up to the next entry's (front matter
+// before the first entry is left out); a book without a usable table of
+// contents has a chapter per reading-order document.
+type EPUBIngester struct {
+	// Chapters selects the chapters to ingest (--chapters), 1-based:
+	// "3", "2-4", "1,3,5-7". Empty ingests the whole book.
+	Chapters string
+}
+
+// Chapter is one chapter of an EPUB book.
+type Chapter struct {
+	Title string
+	Text  string
+}
+
+// epubContainer is META-INF/container.xml, which locates the package
+// document.
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the parts of the package (OPF) document the ingester reads.
+type epubPackage struct {
+	Title    []string `xml:"metadata>title"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		TOC      string `xml:"toc,attr"`
+		ItemRefs []struct {
+			IDRef  string `xml:"idref,attr"`
+			Linear string `xml:"linear,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+// epubNCX is the top level of an EPUB 2 table of contents.
+type epubNCX struct {
+	Points []struct {
+		Label   string `xml:"navLabel>text"`
+		Content struct {
+			Src string `xml:"src,attr"`
+		} `xml:"content"`
+	} `xml:"navMap>navPoint"`
+}
+
+// tocEntry is a table of contents entry: its title and the document it
+// starts in, as a path in the archive.
+type tocEntry struct {
+	title, doc string
+}
+
+func (e *EPUBIngester) Ingest(ctx context.Context, source string) (*Content, error) {
+	if err := validateFile(source); err != nil {
+		return nil, err
+	}
+	title, chapters, err := ReadEPUB(source)
+	if err != nil {
+		return nil, err
+	}
+	selected := chapters
+	if e.Chapters != "" {
+		indexes, err := parseChapterSelector(e.Chapters, len(chapters))
+		if err != nil {
+			return nil, fmt.Errorf("--chapters %s: %w\n%s", e.Chapters, err, chapterList(chapters))
+		}
+		selected = nil
+		for _, i := range indexes {
+			selected = append(selected, chapters[i])
+		}
+		if len(selected) == 1 {
+			title += ": " + selected[0].Title
+		}
+	}
+
+	var sb strings.Builder
+	for i, ch := range selected {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "## %s\n\n%s", ch.Title, ch.Text)
+	}
+	text := strings.TrimSpace(sb.String())
+	if len(text) == 0 {
+		return nil, fmt.Errorf("could not extract text from EPUB %s — it may be image-based or DRM-protected", source)
+	}
+	return &Content{
+		Text:      text,
+		Title:     title,
+		Source:    filepath.Base(source),
+		WordCount: wordCount(text),
+	}, nil
+}
+
+// ReadEPUB returns an EPUB book's title (the file name if it has none) and
+// its chapters.
+func ReadEPUB(source string) (string, []Chapter, error) {
+	zr, err := zip.OpenReader(source)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not read EPUB %s: %w", source, err)
+	}
+	defer zr.Close()
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	readXML := func(name string, v any) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("EPUB %s is missing %s", source, name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		dec := newXHTMLDecoder(io.LimitReader(rc, maxInputSize))
+		if err := dec.Decode(v); err != nil {
+			return fmt.Errorf("EPUB %s: parse %s: %w", source, name, err)
+		}
+		return nil
+	}
+
+	var container epubContainer
+	if err := readXML("META-INF/container.xml", &container); err != nil {
+		return "", nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return "", nil, fmt.Errorf("EPUB %s has no package document", source)
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err := readXML(opfPath, &pkg); err != nil {
+		return "", nil, err
+	}
+
+	hrefs := map[string]string{} // manifest ID → archive path
+	var navPath, ncxPath string
+	for _, item := range pkg.Manifest {
+		p := epubPath(opfPath, item.Href)
+		hrefs[item.ID] = p
+		if strings.Contains(" "+item.Properties+" ", " nav ") {
+			navPath = p
+		}
+		if item.ID == pkg.Spine.TOC || (ncxPath == "" && item.MediaType == "application/x-dtbncx+xml") {
+			ncxPath = p
+		}
+	}
+
+	// The reading order and each document's text.
+	var docs []string
+	texts := map[string]string{}
+	headings := map[string]string{}
+	for _, ref := range pkg.Spine.ItemRefs {
+		p, ok := hrefs[ref.IDRef]
+		if !ok || ref.Linear == "no" {
+			continue
+		}
+		if _, seen := texts[p]; seen {
+			continue
+		}
+		f, ok := files[p]
+		if !ok {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", nil, fmt.Errorf("EPUB %s: open %s: %w", source, p, err)
+		}
+		text, heading := xhtmlText(io.LimitReader(rc, maxInputSize))
+		rc.Close()
+		docs = append(docs, p)
+		texts[p], headings[p] = text, heading
+	}
+	if len(docs) == 0 {
+		return "", nil, fmt.Errorf("EPUB %s has no readable documents", source)
+	}
+
+	var toc []tocEntry
+	if navPath != "" {
+		if f, ok := files[navPath]; ok {
+			if rc, err := f.Open(); err == nil {
+				toc = navTOC(navPath, io.LimitReader(rc, maxInputSize))
+				rc.Close()
+			}
+		}
+	}
+	if len(toc) == 0 && ncxPath != "" {
+		var ncx epubNCX
+		if readXML(ncxPath, &ncx) == nil {
+			for _, pt := range ncx.Points {
+				toc = append(toc, tocEntry{title: collapseSpace(pt.Label), doc: epubPath(ncxPath, pt.Content.Src)})
+			}
+		}
+	}
+
+	title := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	if len(pkg.Title) > 0 && strings.TrimSpace(pkg.Title[0]) != "" {
+		title = collapseSpace(pkg.Title[0])
+	}
+	return title, epubChapters(docs, texts, headings, toc), nil
+}
+
+// epubChapters splits the reading order into chapters at the table of
+// contents entries, or at each document if none of them is in the reading
+// order. Entries pointing into the same document as the one before are
+// part of its chapter, and empty chapters are dropped.
+func epubChapters(docs []string, texts, headings map[string]string, toc []tocEntry) []Chapter {
+	index := make(map[string]int, len(docs))
+	for i, d := range docs {
+		index[d] = i
+	}
+	type start struct {
+		doc   int
+		title string
+	}
+	var starts []start
+	for _, e := range toc {
+		i, ok := index[e.doc]
+		if !ok || (len(starts) > 0 && i <= starts[len(starts)-1].doc) {
+			continue
+		}
+		starts = append(starts, start{i, e.title})
+	}
+	if len(starts) == 0 {
+		for i, d := range docs {
+			starts = append(starts, start{i, headings[d]})
+		}
+	}
+
+	var chapters []Chapter
+	for n, s := range starts {
+		end := len(docs)
+		if n+1 < len(starts) {
+			end = starts[n+1].doc
+		}
+		var parts []string
+		for _, d := range docs[s.doc:end] {
+			if t := texts[d]; t != "" {
+				parts = append(parts, t)
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		title := s.title
+		if title == "" {
+			title = "Chapter " + strconv.Itoa(len(chapters)+1)
+		}
+		chapters = append(chapters, Chapter{Title: title, Text: strings.Join(parts, "\n\n")})
+	}
+	return chapters
+}
+
+// parseChapterSelector returns the 0-based indexes of the chapters
+// selected by spec ("3", "2-4", "1,3,5-7") out of n, in book order.
+func parseChapterSelector(spec string, n int) ([]int, error) {
+	selected := make([]bool, n)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(lo))
+		last := first
+		if err == nil && isRange {
+			last, err = strconv.Atoi(strings.TrimSpace(hi))
+		}
+		if err != nil || first < 1 || last < first {
+			return nil, fmt.Errorf("invalid chapter selection %q: want N, N-M, or a comma-separated list", part)
+		}
+		if last > n {
+			return nil, fmt.Errorf("the book has %d chapters", n)
+		}
+		for i := first; i <= last; i++ {
+			selected[i-1] = true
+		}
+	}
+	var indexes []int
+	for i, ok := range selected {
+		if ok {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
+// chapterList lists chapters by number, for selection errors.
+func chapterList(chapters []Chapter) string {
+	var sb strings.Builder
+	sb.WriteString("Chapters:")
+	for i, ch := range chapters {
+		fmt.Fprintf(&sb, "\n  %3d  %s (%d words)", i+1, ch.Title, wordCount(ch.Text))
+	}
+	return sb.String()
+}
+
+// epubPath resolves href, relative to the archive file base, to an archive
+// path without its fragment.
+func epubPath(base, href string) string {
+	href, _, _ = strings.Cut(href, "#")
+	if u, err := url.PathUnescape(href); err == nil {
+		href = u
+	}
+	return path.Join(path.Dir(base), href)
+}
+
+func newXHTMLDecoder(r io.Reader) *xml.Decoder {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	return dec
+}
+
+// xhtmlBlocks are the elements that start a new line of text.
+var xhtmlBlocks = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "blockquote": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"li": true, "tr": true, "br": true, "hr": true, "pre": true, "dt": true, "dd": true,
+}
+
+// xhtmlText returns a document's body text, a line per block, and its
+// first heading.
+func xhtmlText(r io.Reader) (text, heading string) {
+	dec := newXHTMLDecoder(r)
+	var lines []string
+	var line, head strings.Builder
+	skip, inBody, inHeading := 0, false, false
+	flush := func() {
+		if s := collapseSpace(line.String()); s != "" {
+			lines = append(lines, s)
+		}
+		line.Reset()
+	}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "body":
+				inBody = true
+			case name == "script" || name == "style":
+				skip++
+			case xhtmlBlocks[name]:
+				flush()
+			}
+			if isHeading(name) && heading == "" {
+				inHeading = true
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "script" || name == "style":
+				skip = max(skip-1, 0)
+			case xhtmlBlocks[name]:
+				flush()
+			}
+			if inHeading && isHeading(name) {
+				inHeading = false
+				heading = collapseSpace(head.String())
+			}
+		case xml.CharData:
+			if inBody && skip == 0 {
+				line.Write(t)
+				if inHeading {
+					head.Write(t)
+				}
+			}
+		}
+	}
+	flush()
+	return strings.Join(lines, "\n"), heading
+}
+
+func isHeading(name string) bool {
+	return len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6'
+}
+
+// navTOC returns the top-level entries of an EPUB 3 nav document's table of
+// contents.
+func navTOC(navPath string, r io.Reader) []tocEntry {
+	dec := newXHTMLDecoder(r)
+	var toc []tocEntry
+	var title strings.Builder
+	var href string
+	inTOC, depth, inLink := false, 0, false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "nav":
+				for _, a := range t.Attr {
+					if a.Name.Local == "type" && strings.Contains(" "+a.Value+" ", " toc ") {
+						inTOC = true
+					}
+				}
+			case "ol", "ul":
+				if inTOC {
+					depth++
+				}
+			case "a":
+				if inTOC && depth == 1 {
+					inLink, href = true, ""
+					title.Reset()
+					for _, a := range t.Attr {
+						if a.Name.Local == "href" {
+							href = a.Value
+						}
+					}
+				}
+			}
+		case xml.EndElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "nav":
+				if inTOC {
+					return toc
+				}
+			case "ol", "ul":
+				if inTOC {
+					depth--
+				}
+			case "a":
+				if inLink {
+					inLink = false
+					if href != "" {
+						toc = append(toc, tocEntry{title: collapseSpace(title.String()), doc: epubPath(navPath, href)})
+					}
+				}
+			}
+		case xml.CharData:
+			if inLink {
+				title.Write(t)
+			}
+		}
+	}
+	return toc
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,181 @@
+package ingest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/observability/logctx"
+)
+
+// FeedItem is one entry of an RSS 2.0 or Atom feed.
+type FeedItem struct {
+	Title     string
+	Link      string
+	Summary   string // description or summary, with markup stripped
+	Published time.Time
+}
+
+// rssDoc and atomDoc are the parts of RSS 2.0 and Atom documents FetchFeed
+// reads.
+type rssDoc struct {
+	Items []struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		PubDate     string `xml:"pubDate"`
+	} `xml:"channel>item"`
+}
+
+type atomDoc struct {
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+var feedTagRe = regexp.MustCompile(`<[^>]*>`)
+
+// feedTimeLayouts are the date formats seen in RSS pubDate and Atom
+// published/updated elements.
+var feedTimeLayouts = []string{
+	time.RFC1123Z, time.RFC1123, time.RFC3339, time.RFC3339Nano,
+	"Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+// FetchFeed fetches an RSS 2.0 or Atom feed and returns its items, newest
+// first. Items without a parseable date keep their feed order after the
+// dated ones.
+func FetchFeed(ctx context.Context, source string) ([]FeedItem, error) {
+	auth := authFrom(ctx)
+	client := auth.client(ctx, 30*time.Second)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request for %s: %w", source, err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Podcaster/1.0; +https://podcasts.apresai.dev)")
+	auth.apply(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch feed %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch feed %s: HTTP %d", source, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxInputSize))
+	if err != nil {
+		return nil, fmt.Errorf("read feed %s: %w", source, err)
+	}
+	items, err := ParseFeed(data)
+	if err != nil {
+		return nil, fmt.Errorf("feed %s: %w", source, err)
+	}
+	return items, nil
+}
+
+// ParseFeed parses an RSS 2.0 or Atom document (see FetchFeed).
+func ParseFeed(data []byte) ([]FeedItem, error) {
+	var items []FeedItem
+	var rss rssDoc
+	if err := xml.Unmarshal(data, &rss); err == nil && len(rss.Items) > 0 {
+		for _, it := range rss.Items {
+			items = append(items, FeedItem{
+				Title:     feedText(it.Title),
+				Link:      strings.TrimSpace(it.Link),
+				Summary:   feedText(it.Description),
+				Published: parseFeedTime(it.PubDate),
+			})
+		}
+	} else {
+		var atom atomDoc
+		if err := xml.Unmarshal(data, &atom); err != nil {
+			return nil, fmt.Errorf("not an RSS or Atom feed: %w", err)
+		}
+		for _, e := range atom.Entries {
+			item := FeedItem{Title: feedText(e.Title), Summary: feedText(e.Summary)}
+			if item.Summary == "" {
+				item.Summary = feedText(e.Content)
+			}
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					item.Link = strings.TrimSpace(l.Href)
+					break
+				}
+			}
+			if item.Published = parseFeedTime(e.Published); item.Published.IsZero() {
+				item.Published = parseFeedTime(e.Updated)
+			}
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("feed has no items")
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Published, items[j].Published
+		return !a.IsZero() && (b.IsZero() || a.After(b))
+	})
+	return items, nil
+}
+
+// feedText strips markup and entities from a feed field.
+func feedText(s string) string {
+	s = html.UnescapeString(feedTagRe.ReplaceAllString(s, " "))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func parseFeedTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// FeedDigest ingests the linked article of each item and returns them as one
+// document for a briefing episode: a heading per item, then the article
+// text, or the feed's summary if the article can't be fetched.
+func FeedDigest(ctx context.Context, items []FeedItem) string {
+	var sb strings.Builder
+	for i, it := range items {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "## %s\n", it.Title)
+		if !it.Published.IsZero() {
+			fmt.Fprintf(&sb, "Published %s\n", it.Published.Format("January 2, 2006"))
+		}
+		if it.Link != "" {
+			fmt.Fprintf(&sb, "Source: %s\n", it.Link)
+		}
+		sb.WriteString("\n")
+		text := it.Summary
+		if it.Link != "" {
+			content, err := (&URLIngester{}).Ingest(ctx, it.Link)
+			if err != nil {
+				logctx.From(ctx).WarnContext(ctx, "feed item fetch failed, using its summary", "url", it.Link, "error", err)
+			} else {
+				text = content.Text
+			}
+		}
+		sb.WriteString(strings.TrimSpace(text))
+	}
+	return sb.String()
+}
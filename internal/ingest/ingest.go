@@ -4,15 +4,23 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 type SourceType string
 
 const (
-	SourceURL  SourceType = "url"
-	SourcePDF  SourceType = "pdf"
-	SourceText SourceType = "text"
+	SourceURL    SourceType = "url"
+	SourcePDF    SourceType = "pdf"
+	SourceText   SourceType = "text"
+	SourceEPUB   SourceType = "epub"
+	SourceDOCX   SourceType = "docx"
+	SourceODT    SourceType = "odt"
+	SourceDir    SourceType = "dir"    // a directory or glob of files (dir.go)
+	SourceArXiv  SourceType = "arxiv"  // an arXiv ID or arxiv.org URL (arxiv.go)
+	SourceThread SourceType = "thread" // a Hacker News or Reddit thread URL (thread.go)
+	SourceMedia  SourceType = "media"  // an audio or video file, transcribed first (media.go)
 
 	// maxInputSize is the maximum allowed size for input content (25 MB).
 	maxInputSize = 25 * 1024 * 1024
@@ -30,6 +38,8 @@ type Content struct {
 	Title     string
 	Source    string
 	WordCount int
+	Sources   int  // labeled sources Merge joined; 0 for a single input
+	Community bool // has a COMMUNITY DISCUSSION section of readers' comments (thread.go)
 }
 
 type Ingester interface {
@@ -37,12 +47,33 @@ type Ingester interface {
 }
 
 func DetectSource(input string) SourceType {
+	if _, ok := ParseArXivID(input); ok {
+		return SourceArXiv
+	}
+	if _, _, ok := ParseThreadURL(input); ok {
+		return SourceThread
+	}
 	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
 		return SourceURL
 	}
+	// A file whose name has glob characters is still a file.
+	if isDir(input) || isGlob(input) && !fileExists(input) {
+		return SourceDir
+	}
 	if strings.HasSuffix(strings.ToLower(input), ".pdf") {
 		return SourcePDF
 	}
+	if IsMedia(input) {
+		return SourceMedia
+	}
+	switch strings.ToLower(filepath.Ext(input)) {
+	case ".epub":
+		return SourceEPUB
+	case ".docx":
+		return SourceDOCX
+	case ".odt":
+		return SourceODT
+	}
 	return SourceText
 }
 
@@ -52,6 +83,20 @@ func NewIngester(input string) Ingester {
 		return &URLIngester{}
 	case SourcePDF:
 		return &PDFIngester{}
+	case SourceEPUB:
+		return &EPUBIngester{}
+	case SourceDOCX:
+		return &DOCXIngester{}
+	case SourceODT:
+		return &ODTIngester{}
+	case SourceDir:
+		return &DirIngester{}
+	case SourceArXiv:
+		return &ArXivIngester{}
+	case SourceThread:
+		return &ThreadIngester{}
+	case SourceMedia:
+		return &MediaIngester{}
 	default:
 		return &TextIngester{}
 	}
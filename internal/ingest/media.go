@@ -0,0 +1,28 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// mediaExts are the audio and video files DetectSource reports as
+// SourceMedia.
+var mediaExts = map[string]bool{
+	".mp3": true, ".m4a": true, ".wav": true, ".ogg": true, ".opus": true, ".flac": true,
+	".mp4": true, ".mov": true, ".webm": true, ".mkv": true,
+}
+
+// IsMedia reports whether path names an audio or video file.
+func IsMedia(path string) bool {
+	return mediaExts[strings.ToLower(filepath.Ext(path))]
+}
+
+// MediaIngester rejects audio and video: the pipeline transcribes them
+// (internal/transcribe) and ingests the transcript instead.
+type MediaIngester struct{}
+
+func (m *MediaIngester) Ingest(ctx context.Context, source string) (*Content, error) {
+	return nil, fmt.Errorf("%s is audio or video: it must be transcribed before it can be ingested", source)
+}
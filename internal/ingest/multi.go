@@ -0,0 +1,142 @@
+package ingest
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Several inputs can make one episode (repeated -i). Each is ingested on
+// its own, then Merge joins them into one text with a labeled header per
+// source, which the script prompt asks the hosts to compare.
+
+// minDupParagraphWords is the shortest paragraph Merge drops as a repeat
+// of an earlier source's; shorter ones ("Photo: Reuters") repeat by chance.
+// It is also the least a source must add to be kept.
+const minDupParagraphWords = 8
+
+// SplitInputs splits an --input value listing several inputs with commas.
+// The value is kept whole unless every part is a URL, an arXiv ID, an
+// existing file or directory, or a glob, so URLs and file names that
+// contain commas still work on their own.
+func SplitInputs(value string) []string {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, ",") || fileExists(value) {
+		return []string{value}
+	}
+	var parts []string
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if src := DetectSource(p); src != SourceURL && src != SourceArXiv && src != SourceThread && src != SourceDir && !fileExists(p) {
+			return []string{value}
+		}
+		parts = append(parts, p)
+	}
+	return parts
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// DedupeInputs returns inputs without repeats, keeping the first of each:
+// arXiv papers compare by ID whatever the form or version, URLs without
+// their fragment or trailing slash, files by cleaned path. dropped lists
+// the repeats.
+func DedupeInputs(inputs []string) (unique, dropped []string) {
+	seen := make(map[string]bool)
+	for _, in := range inputs {
+		key := inputKey(in)
+		if seen[key] {
+			dropped = append(dropped, in)
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, in)
+	}
+	return unique, dropped
+}
+
+func inputKey(input string) string {
+	input = strings.TrimSpace(input)
+	if id, ok := ParseArXivID(input); ok {
+		return "arxiv:" + arxivVersion.ReplaceAllString(id, "")
+	}
+	if DetectSource(input) != SourceURL {
+		return "file:" + filepath.Clean(input)
+	}
+	u, err := url.Parse(input)
+	if err != nil {
+		return input
+	}
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
+
+// Merge joins the contents of several inputs into one, each under a
+// "=== SOURCE n: title (source) ===" header. Paragraphs (lines) already
+// seen in an earlier source are left out, and a source with nothing new
+// is dropped entirely (a syndicated copy, or the same file twice);
+// dropped lists those by Source. A single content, or the only one left,
+// is returned as is.
+func Merge(contents []*Content) (merged *Content, dropped []string) {
+	if len(contents) == 1 {
+		return contents[0], nil
+	}
+	seen := make(map[string]bool)
+	var kept []*Content
+	var parts []string
+	words := 0
+	community := false
+	for _, c := range contents {
+		var lines []string
+		for _, line := range strings.Split(c.Text, "\n") {
+			key := strings.ToLower(collapseSpace(line))
+			if wordCount(key) >= minDupParagraphWords {
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			lines = append(lines, line)
+		}
+		text := strings.TrimSpace(strings.Join(lines, "\n"))
+		for strings.Contains(text, "\n\n\n") { // left by removed lines
+			text = strings.ReplaceAll(text, "\n\n\n", "\n\n")
+		}
+		if wordCount(text) < minDupParagraphWords {
+			dropped = append(dropped, c.Source)
+			continue
+		}
+		kept = append(kept, c)
+		community = community || c.Community
+		parts = append(parts, fmt.Sprintf("=== SOURCE %d: %s (%s) ===\n\n%s", len(kept), c.Title, c.Source, text))
+		words += wordCount(text)
+	}
+	switch len(kept) {
+	case 0:
+		return contents[0], nil
+	case 1:
+		return kept[0], dropped
+	}
+	sources := make([]string, 0, len(kept))
+	for _, c := range kept {
+		sources = append(sources, c.Source)
+	}
+	return &Content{
+		Text:      strings.Join(parts, "\n\n"),
+		Title:     fmt.Sprintf("%s (and %d more)", kept[0].Title, len(kept)-1),
+		Source:    strings.Join(sources, ", "),
+		WordCount: words,
+		Sources:   len(parts),
+		Community: community,
+	}, dropped
+}
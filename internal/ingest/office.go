@@ -0,0 +1,280 @@
+package ingest
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Word (.docx) and OpenDocument (.odt) files are zip archives of XML. Their
+// text is extracted a paragraph at a time, with headings kept as Markdown
+// headings ("## Results" at level 2) so the script writer sees the
+// document's structure.
+
+// DOCXIngester reads Word documents (word/document.xml). Paragraphs styled
+// Title or Heading N become headings.
+type DOCXIngester struct{}
+
+// ODTIngester reads OpenDocument text (content.xml). text:h elements become
+// headings at their outline level.
+type ODTIngester struct{}
+
+func (d *DOCXIngester) Ingest(ctx context.Context, source string) (*Content, error) {
+	return ingestOffice(source, "Word", "word/document.xml", "docProps/core.xml", docxText)
+}
+
+func (o *ODTIngester) Ingest(ctx context.Context, source string) (*Content, error) {
+	return ingestOffice(source, "OpenDocument", "content.xml", "meta.xml", odtText)
+}
+
+// ingestOffice extracts the text of body, and the title from the dc:title
+// of meta, from the archive at source. Without a title the first heading,
+// then the first line, is used.
+func ingestOffice(source, kind, body, meta string, extract func(io.Reader) (string, error)) (*Content, error) {
+	if err := validateFile(source); err != nil {
+		return nil, err
+	}
+	zr, err := zip.OpenReader(source)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s file %s: %w", kind, source, err)
+	}
+	defer zr.Close()
+
+	var text, title string
+	for _, f := range zr.File {
+		switch f.Name {
+		case body, meta:
+			rc, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("could not read %s file %s: %w", kind, source, err)
+			}
+			r := io.LimitReader(rc, maxInputSize)
+			if f.Name == body {
+				text, err = extract(r)
+			} else {
+				title = officeTitle(r)
+			}
+			rc.Close()
+			if err != nil {
+				return nil, fmt.Errorf("could not parse %s file %s: %w", kind, source, err)
+			}
+		}
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("could not extract text from %s file %s — it may be empty or contain only images", kind, source)
+	}
+	if title == "" {
+		for _, line := range strings.Split(text, "\n") {
+			if strings.HasPrefix(line, "#") {
+				title = strings.TrimSpace(strings.TrimLeft(line, "#"))
+				break
+			}
+		}
+	}
+	if title == "" {
+		title = titleFromText(text, 80)
+	}
+	return &Content{
+		Text:      text,
+		Title:     title,
+		Source:    filepath.Base(source),
+		WordCount: wordCount(text),
+	}, nil
+}
+
+// officeTitle returns the dc:title of a docProps/core.xml or meta.xml.
+func officeTitle(r io.Reader) string {
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "title" {
+			var title string
+			if dec.DecodeElement(&title, &se) == nil {
+				return collapseSpace(title)
+			}
+			return ""
+		}
+	}
+}
+
+// paragraphs collects extracted text a paragraph at a time.
+type paragraphs struct {
+	lines []string
+	cur   strings.Builder
+	level int // heading level of the current paragraph; 0 for body text
+}
+
+func (p *paragraphs) end() {
+	line := strings.TrimSpace(p.cur.String())
+	if line != "" {
+		if p.level > 0 {
+			line = strings.Repeat("#", min(p.level, 6)) + " " + line
+		}
+		p.lines = append(p.lines, line)
+	}
+	p.cur.Reset()
+	p.level = 0
+}
+
+func (p *paragraphs) String() string {
+	return strings.Join(p.lines, "\n\n")
+}
+
+// docxText extracts word/document.xml: w:t runs, w:tab and w:br, and each
+// w:p's heading level from its style (Title is 1, Heading N is N; custom
+// styles based on headings aren't followed).
+func docxText(r io.Reader) (string, error) {
+	dec := xml.NewDecoder(r)
+	var p paragraphs
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !docxNS[t.Name.Space] {
+				continue // DrawingML and other embedded markup
+			}
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				p.cur.WriteByte('\t')
+			case "br", "cr":
+				p.cur.WriteByte(' ')
+			case "pStyle":
+				p.level = docxHeadingLevel(xmlAttr(t, "val"))
+			case "outlineLvl":
+				if n, err := strconv.Atoi(xmlAttr(t, "val")); err == nil && n < 9 && p.level == 0 {
+					p.level = n + 1
+				}
+			}
+		case xml.EndElement:
+			if !docxNS[t.Name.Space] {
+				continue
+			}
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				p.end()
+			}
+		case xml.CharData:
+			if inText {
+				p.cur.Write(t)
+			}
+		}
+	}
+	return p.String(), nil
+}
+
+// docxNS are the WordprocessingML namespaces (transitional and strict).
+var docxNS = map[string]bool{
+	"http://schemas.openxmlformats.org/wordprocessingml/2006/main": true,
+	"http://purl.oclc.org/ooxml/wordprocessingml/main":             true,
+}
+
+// docxHeadingLevel returns the heading level of a paragraph style ID, or 0.
+func docxHeadingLevel(style string) int {
+	s := strings.ToLower(strings.ReplaceAll(style, " ", ""))
+	if s == "title" {
+		return 1
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(s, "heading")); err == nil && strings.HasPrefix(s, "heading") && n >= 1 {
+		return n
+	}
+	return 0
+}
+
+// odtText extracts content.xml's office:text: text:p and text:h paragraphs
+// (including those in lists and tables), text:s spaces, text:tab, and
+// text:line-break. Notes and tracked deletions are left out.
+func odtText(r io.Reader) (string, error) {
+	dec := xml.NewDecoder(r)
+	var p paragraphs
+	depth := 0 // nesting of text:p/text:h (frames can hold paragraphs of their own)
+	inBody := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "text":
+				if t.Name.Space == odfOfficeNS {
+					inBody = true
+				}
+			case "p", "h":
+				if depth > 0 {
+					p.cur.WriteByte(' ')
+				}
+				depth++
+				if t.Name.Local == "h" && depth == 1 {
+					p.level = 1
+					if n, err := strconv.Atoi(xmlAttr(t, "outline-level")); err == nil && n >= 1 {
+						p.level = n
+					}
+				}
+			case "s":
+				n := 1
+				if c, err := strconv.Atoi(xmlAttr(t, "c")); err == nil && c > 0 {
+					n = c
+				}
+				p.cur.WriteString(strings.Repeat(" ", n))
+			case "tab":
+				p.cur.WriteByte('\t')
+			case "line-break":
+				p.cur.WriteByte(' ')
+			case "note", "tracked-changes":
+				// Footnotes and deleted text would land mid-sentence.
+				if err := dec.Skip(); err != nil {
+					return "", err
+				}
+			}
+		case xml.EndElement:
+			if (t.Name.Local == "p" || t.Name.Local == "h") && depth > 0 {
+				if depth--; depth == 0 {
+					p.end()
+				}
+			}
+		case xml.CharData:
+			if inBody && depth > 0 {
+				p.cur.Write(t)
+			}
+		}
+	}
+	return p.String(), nil
+}
+
+// odfOfficeNS is the OpenDocument office namespace; office:text is the
+// body of a text document.
+const odfOfficeNS = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+
+// xmlAttr returns the value of the attribute named local, in any namespace.
+func xmlAttr(se xml.StartElement, local string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -22,6 +22,21 @@ func (p *PDFIngester) Ingest(ctx context.Context, source string) (*Content, erro
 	}
 	defer f.Close()
 
+	text := pdfText(r)
+	if len(text) == 0 {
+		return nil, fmt.Errorf("could not extract text from PDF %s — it may be scanned or image-based", source)
+	}
+
+	return &Content{
+		Text:      text,
+		Title:     titleFromText(text, 80),
+		Source:    filepath.Base(source),
+		WordCount: wordCount(text),
+	}, nil
+}
+
+// pdfText returns the plain text of every page of r that extracts.
+func pdfText(r *pdf.Reader) string {
 	var sb strings.Builder
 	numPages := r.NumPage()
 
@@ -37,16 +52,5 @@ func (p *PDFIngester) Ingest(ctx context.Context, source string) (*Content, erro
 		sb.WriteString(text)
 		sb.WriteString("\n")
 	}
-
-	text := strings.TrimSpace(sb.String())
-	if len(text) == 0 {
-		return nil, fmt.Errorf("could not extract text from PDF %s — it may be scanned or image-based", source)
-	}
-
-	return &Content{
-		Text:      text,
-		Title:     titleFromText(text, 80),
-		Source:    filepath.Base(source),
-		WordCount: wordCount(text),
-	}, nil
+	return strings.TrimSpace(sb.String())
 }
@@ -0,0 +1,61 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+type publicOnlyKey struct{}
+
+// ContextPublicOnly returns ctx under which ingest's requests only connect
+// to public addresses: the hosted server fetches URLs for anyone, and must
+// not be a way into the network it runs in. The CLI doesn't set it, so a
+// local run can still fetch intranet pages.
+func ContextPublicOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, publicOnlyKey{}, true)
+}
+
+func publicOnly(ctx context.Context) bool {
+	v, _ := ctx.Value(publicOnlyKey{}).(bool)
+	return v
+}
+
+// PublicTransport dials like http.DefaultTransport but refuses private,
+// loopback, link-local, shared (CGNAT), and unspecified addresses. The
+// check runs on the resolved address, so a public name resolving to an
+// internal one (or a redirect to one) is refused too. The hosted server
+// fetches every user-supplied URL through it: inputs here (under
+// ContextPublicOnly), stingers and cover art in mcpserver.
+var PublicTransport = func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	d := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: dialPublic}
+	t.DialContext = d.DialContext
+	return t
+}()
+
+// sharedAddressSpace is 100.64.0.0/10 (RFC 6598), carrier-grade NAT space
+// used inside VPCs, which netip.Addr.IsPrivate doesn't cover.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// dialPublic is PublicTransport's net.Dialer Control function.
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("refusing to connect to non-public address %s", ip)
+	}
+	return nil
+}
@@ -0,0 +1,435 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// A Hacker News item or Reddit thread URL is read as the submission plus
+// its top-level comments, so the hosts can discuss what the commenters
+// think as well as what the article says. The linked article, if any, is
+// ingested as an ordinary URL; the comments follow it under a
+// "=== COMMUNITY DISCUSSION ===" header that the script prompt treats as
+// readers' opinions, not facts. Comments are taken best first (Reddit by
+// score, Hacker News in its ranked order), capped in number, and given a
+// share of the comment word budget in proportion to their score.
+
+// ThreadIngester reads a Hacker News or Reddit discussion thread.
+type ThreadIngester struct{}
+
+// Discussion site endpoints, variables so a mirror can stand in.
+var (
+	hnAPI      = "https://hacker-news.firebaseio.com/v0"
+	redditBase = "https://www.reddit.com"
+)
+
+const (
+	// maxThreadComments is the most top-level comments kept.
+	maxThreadComments = 25
+
+	// threadCommentWords is the comment word budget, shared by score;
+	// each comment gets between minCommentWords and maxCommentWords.
+	threadCommentWords = 3000
+	minCommentWords    = 40
+	maxCommentWords    = 300
+)
+
+var (
+	// redditPath matches the thread ID in a reddit.com path:
+	// /r/golang/comments/abc123/title_slug/ or /comments/abc123.
+	redditPath = regexp.MustCompile(`^/(?:r/[^/]+/)?comments/([a-z0-9]+)`)
+
+	// markdownLink is a Reddit comment's [text](url).
+	markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+
+	// markdownEmphasis is Reddit's bold, italic, strikethrough, and
+	// superscript markers.
+	markdownEmphasis = regexp.MustCompile(`\*\*|__|~~|\^`)
+)
+
+// Thread sites.
+const (
+	siteHackerNews = "Hacker News"
+	siteReddit     = "Reddit"
+)
+
+// ParseThreadURL returns the site and ID of the discussion thread input
+// links to: a news.ycombinator.com item, or a Reddit comments page (any
+// reddit.com subdomain, or a redd.it short link).
+func ParseThreadURL(input string) (site, id string, ok bool) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "http://") && !strings.HasPrefix(input, "https://") {
+		return "", "", false
+	}
+	u, err := url.Parse(input)
+	if err != nil {
+		return "", "", false
+	}
+	host := strings.ToLower(u.Host)
+	switch {
+	case host == "news.ycombinator.com":
+		id := u.Query().Get("id")
+		if u.Path != "/item" || id == "" || strings.Trim(id, "0123456789") != "" {
+			return "", "", false
+		}
+		return siteHackerNews, id, true
+	case host == "redd.it":
+		id := strings.Trim(u.Path, "/")
+		if id == "" || strings.Contains(id, "/") {
+			return "", "", false
+		}
+		return siteReddit, strings.ToLower(id), true
+	case host == "reddit.com" || strings.HasSuffix(host, ".reddit.com"):
+		m := redditPath.FindStringSubmatch(strings.ToLower(u.Path))
+		if m == nil {
+			return "", "", false
+		}
+		return siteReddit, m[1], true
+	}
+	return "", "", false
+}
+
+// thread is a discussion as fetched from its site.
+type thread struct {
+	Site     string
+	Title    string
+	Author   string
+	Points   int
+	Link     string // the submitted URL; empty for a text post
+	Text     string // the submission's own text, if any
+	Comments []threadComment
+}
+
+// threadComment is a top-level comment. Score orders and weights comments;
+// Points is shown only where the site publishes it (Reddit).
+type threadComment struct {
+	Author string
+	Text   string
+	Score  int
+	Points int
+}
+
+func (t *ThreadIngester) Ingest(ctx context.Context, source string) (*Content, error) {
+	site, id, ok := ParseThreadURL(source)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a Hacker News or Reddit thread URL", source)
+	}
+	var th *thread
+	var err error
+	if site == siteHackerNews {
+		th, err = fetchHNThread(ctx, id)
+	} else {
+		th, err = fetchRedditThread(ctx, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s thread %s: %w", site, id, err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\nPosted to %s by %s, %d points\n\n", th.Title, th.Site, th.Author, th.Points)
+	if article := threadArticle(ctx, th.Link); article != nil {
+		fmt.Fprintf(&sb, "=== THE ARTICLE: %s (%s) ===\n\n%s\n\n", article.Title, th.Link, strings.TrimSpace(article.Text))
+	}
+	if th.Text != "" {
+		fmt.Fprintf(&sb, "=== THE SUBMISSION ===\n\n%s\n\n", th.Text)
+	}
+	comments := selectComments(th.Comments, maxThreadComments, threadCommentWords)
+	if len(comments) > 0 {
+		fmt.Fprintf(&sb, "=== COMMUNITY DISCUSSION: %d top-level comments from %s, best first ===\n\n", len(comments), th.Site)
+		fmt.Fprintf(&sb, "These are readers' opinions about the submission, not part of it.\n\n")
+		for i, c := range comments {
+			if c.Points != 0 {
+				fmt.Fprintf(&sb, "[Comment %d, by %s, %d points]\n%s\n\n", i+1, c.Author, c.Points, c.Text)
+			} else {
+				fmt.Fprintf(&sb, "[Comment %d, by %s]\n%s\n\n", i+1, c.Author, c.Text)
+			}
+		}
+	}
+	text := strings.TrimSpace(sb.String())
+	return &Content{
+		Text:      text,
+		Title:     th.Title,
+		Source:    source,
+		WordCount: wordCount(text),
+		Community: len(comments) > 0,
+	}, nil
+}
+
+// threadArticle ingests the page a link submission points to, or returns
+// nil (logging why) if there is none or it can't be read; the discussion
+// is still worth an episode.
+func threadArticle(ctx context.Context, link string) *Content {
+	if link == "" {
+		return nil
+	}
+	switch DetectSource(link) {
+	case SourceURL, SourceArXiv:
+	default:
+		return nil // a link to another thread, or not a web page
+	}
+	if u, err := url.Parse(link); err == nil {
+		switch strings.ToLower(u.Host) {
+		case "i.redd.it", "v.redd.it", "i.imgur.com", "preview.redd.it":
+			return nil // images and video
+		}
+	}
+	content, err := NewIngester(link).Ingest(ctx, link)
+	if err != nil {
+		slog.Warn("could not fetch the thread's linked article, using the discussion only", "url", link, "error", err)
+		return nil
+	}
+	return content
+}
+
+// selectComments returns up to limit of comments, best first, each trimmed
+// to its share of budget words by score.
+func selectComments(comments []threadComment, limit, budget int) []threadComment {
+	var kept []threadComment
+	for _, c := range comments {
+		if c.Score > 0 && wordCount(c.Text) > 0 {
+			kept = append(kept, c)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Score > kept[j].Score })
+	if len(kept) > limit {
+		kept = kept[:limit]
+	}
+	total := 0
+	for _, c := range kept {
+		total += c.Score
+	}
+	for i := range kept {
+		share := budget * kept[i].Score / total
+		share = min(max(share, minCommentWords), maxCommentWords)
+		kept[i].Text = truncateWords(kept[i].Text, share)
+	}
+	return kept
+}
+
+// truncateWords cuts text after n words, keeping its line breaks, and
+// marks the cut with an ellipsis.
+func truncateWords(text string, n int) string {
+	if wordCount(text) <= n {
+		return text
+	}
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		words := strings.Fields(line)
+		if len(words) >= n {
+			lines = append(lines, strings.Join(words[:n], " ")+" …")
+			break
+		}
+		lines = append(lines, line)
+		n -= len(words)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// threadGet fetches url as JSON into v.
+func threadGet(ctx context.Context, url string, v any) error {
+	auth := authFrom(ctx)
+	client := auth.client(ctx, 30*time.Second)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	// Reddit rejects generic user agents.
+	req.Header.Set("User-Agent", "podcaster/1.0 (+https://podcasts.apresai.dev)")
+	auth.apply(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("not found")
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxInputSize)).Decode(v)
+}
+
+// hnItem is a Hacker News API item: a story, Ask HN post, or comment.
+type hnItem struct {
+	ID      int    `json:"id"`
+	Type    string `json:"type"`
+	By      string `json:"by"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Text    string `json:"text"` // HTML
+	Score   int    `json:"score"`
+	Kids    []int  `json:"kids"` // replies, in ranked order
+	Deleted bool   `json:"deleted"`
+	Dead    bool   `json:"dead"`
+}
+
+// fetchHNThread fetches an item and its best-ranked top-level comments.
+// The API publishes no comment scores, so each comment's rank stands in.
+func fetchHNThread(ctx context.Context, id string) (*thread, error) {
+	var item *hnItem
+	if err := threadGet(ctx, hnAPI+"/item/"+id+".json", &item); err != nil {
+		return nil, err
+	}
+	if item == nil || item.Deleted || item.Dead {
+		return nil, fmt.Errorf("not found")
+	}
+	th := &thread{
+		Site:   siteHackerNews,
+		Title:  item.Title,
+		Author: item.By,
+		Points: item.Score,
+		Link:   item.URL,
+		Text:   hnText(item.Text),
+	}
+	if th.Title == "" {
+		th.Title = "Hacker News discussion by " + item.By
+	}
+
+	// Fetch a few extra for deleted and flagged comments.
+	kids := item.Kids
+	if len(kids) > maxThreadComments*3/2 {
+		kids = kids[:maxThreadComments*3/2]
+	}
+	comments := make([]*hnItem, len(kids))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 8)
+	for i, kid := range kids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			var c *hnItem
+			if err := threadGet(ctx, fmt.Sprintf("%s/item/%d.json", hnAPI, kid), &c); err != nil {
+				slog.Warn("could not fetch Hacker News comment", "id", kid, "error", err)
+				return
+			}
+			comments[i] = c
+		}()
+	}
+	wg.Wait()
+	for i, c := range comments {
+		if c == nil || c.Deleted || c.Dead || c.Text == "" {
+			continue
+		}
+		th.Comments = append(th.Comments, threadComment{
+			Author: c.By,
+			Text:   hnText(c.Text),
+			Score:  len(kids) - i,
+		})
+	}
+	return th, nil
+}
+
+// hnText converts Hacker News item HTML (<p> between paragraphs, links,
+// <i>, <pre><code>) to plain text.
+func hnText(s string) string {
+	if s == "" {
+		return ""
+	}
+	var sb strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(sb.String())
+		case html.TextToken:
+			sb.Write(z.Text())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "p":
+				sb.WriteString("\n\n")
+			case "br":
+				sb.WriteString("\n")
+			}
+		}
+	}
+}
+
+// redditListing is one of the two listings a Reddit thread's JSON is: the
+// submission, then its comments.
+type redditListing struct {
+	Data struct {
+		Children []struct {
+			Kind string `json:"kind"` // t3 submission, t1 comment, "more"
+			Data struct {
+				Title         string `json:"title"`
+				Author        string `json:"author"`
+				Subreddit     string `json:"subreddit_name_prefixed"`
+				Score         int    `json:"score"`
+				URL           string `json:"url"`
+				IsSelf        bool   `json:"is_self"`
+				Selftext      string `json:"selftext"`
+				Body          string `json:"body"`
+				Stickied      bool   `json:"stickied"`
+				Distinguished string `json:"distinguished"`
+			} `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+// fetchRedditThread fetches a submission and its top-level comments, top
+// scored first.
+func fetchRedditThread(ctx context.Context, id string) (*thread, error) {
+	var listings []redditListing
+	u := fmt.Sprintf("%s/comments/%s.json?sort=top&depth=1&limit=100&raw_json=1", redditBase, id)
+	if err := threadGet(ctx, u, &listings); err != nil {
+		return nil, err
+	}
+	if len(listings) < 2 || len(listings[0].Data.Children) == 0 {
+		return nil, fmt.Errorf("not found")
+	}
+	post := listings[0].Data.Children[0].Data
+	th := &thread{
+		Site:   siteReddit,
+		Title:  post.Title,
+		Author: "u/" + post.Author,
+		Points: post.Score,
+		Text:   redditText(post.Selftext),
+	}
+	if post.Subreddit != "" {
+		th.Site = post.Subreddit + " on Reddit"
+	}
+	if !post.IsSelf {
+		th.Link = post.URL
+	}
+	for _, child := range listings[1].Data.Children {
+		c := child.Data
+		// Pinned and moderator comments are rules and announcements.
+		if child.Kind != "t1" || c.Stickied || c.Distinguished != "" {
+			continue
+		}
+		if c.Author == "[deleted]" || c.Body == "[deleted]" || c.Body == "[removed]" {
+			continue
+		}
+		th.Comments = append(th.Comments, threadComment{
+			Author: "u/" + c.Author,
+			Text:   redditText(c.Body),
+			Score:  c.Score,
+			Points: c.Score,
+		})
+	}
+	return th, nil
+}
+
+// redditText strips Reddit markdown that shouldn't be read aloud: links
+// keep their text, emphasis markers go.
+func redditText(s string) string {
+	s = markdownLink.ReplaceAllString(s, "$1")
+	s = markdownEmphasis.ReplaceAllString(s, "")
+	return strings.TrimSpace(s)
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/apresai/podcaster/internal/observability"
 	readability "github.com/go-shiori/go-readability"
 )
 
@@ -18,6 +19,11 @@ type URLIngester struct{}
 
 func (u *URLIngester) Ingest(ctx context.Context, source string) (*Content, error) {
 	result, err := u.directFetch(ctx, source)
+	if err != nil && authFrom(ctx).covers(source) {
+		// Jina can't log in, and the credentials and internal URL must
+		// not leave this process.
+		return nil, fmt.Errorf("authenticated fetch of %s failed (check the headers and cookies): %w", source, err)
+	}
 	if err != nil {
 		slog.Warn("direct fetch failed, trying Jina Reader", "url", source, "error", err)
 		result, jinaErr := u.jinaFetch(ctx, source)
@@ -36,12 +42,14 @@ func (u *URLIngester) directFetch(ctx context.Context, source string) (*Content,
 		return nil, fmt.Errorf("invalid URL %s: %w", source, err)
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	auth := authFrom(ctx)
+	client := auth.client(ctx, 30*time.Second)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not create request for %s: %w", source, err)
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Podcaster/1.0; +https://podcasts.apresai.dev)")
+	auth.apply(req)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("could not fetch URL %s: %w", source, err)
@@ -81,7 +89,7 @@ func (u *URLIngester) directFetch(ctx context.Context, source string) (*Content,
 func (u *URLIngester) jinaFetch(ctx context.Context, source string) (*Content, error) {
 	jinaURL := "https://r.jina.ai/" + source
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: observability.Transport(nil)}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jinaURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not create Jina request for %s: %w", source, err)
@@ -126,10 +134,15 @@ func (u *URLIngester) jinaFetch(ctx context.Context, source string) (*Content, e
 	}, nil
 }
 
-// ValidateURL fetches the URL and checks that it has enough readable content
-// for podcast generation. Returns nil if valid, or an error describing the problem.
+// ValidateURL fetches the URL (or arXiv paper, or discussion thread) and
+// checks that it has enough readable content for podcast generation.
+// Returns nil if valid, or an error describing the problem.
 func ValidateURL(ctx context.Context, rawURL string) error {
-	ing := &URLIngester{}
+	var ing Ingester = &URLIngester{}
+	switch DetectSource(rawURL) {
+	case SourceArXiv, SourceThread:
+		ing = NewIngester(rawURL)
+	}
 	content, err := ing.Ingest(ctx, rawURL)
 	if err != nil {
 		return fmt.Errorf("could not fetch content from %s: %w", rawURL, err)
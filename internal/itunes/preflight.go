@@ -0,0 +1,161 @@
+// Package itunes checks an episode against Apple Podcasts' requirements
+// before it is published, so a title, description, cover, or encoding that
+// Apple would reject fails on the command line instead of after upload.
+package itunes
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/apresai/podcaster/internal/assembly"
+)
+
+// Limits from Apple Podcasts' episode and artwork requirements.
+const (
+	MaxTitleChars   = 255
+	MaxSummaryChars = 4000 // <description>
+
+	MinArtworkPixels = 1400
+	MaxArtworkPixels = 3000
+
+	MinBitrateKbps = 64
+	MaxBitrateKbps = 320
+)
+
+// Episode is what publish is about to send.
+type Episode struct {
+	Path    string
+	Title   string
+	Summary string
+
+	// Explicit is the content advisory given at publish (--explicit), nil
+	// if none was. The file's own advisory tag counts too.
+	Explicit *bool
+}
+
+// Problem is one failed requirement and what to do about it.
+type Problem struct {
+	Field   string // "title", "summary", "artwork", "explicit", or "audio"
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Field + ": " + p.Message
+}
+
+// Check probes ep's file and returns every requirement it fails; none
+// means it can be published. The error is for a file that can't be probed.
+func Check(ctx context.Context, ep Episode) ([]Problem, error) {
+	info, err := assembly.ProbeAudio(ctx, ep.Path)
+	if err != nil {
+		return nil, err
+	}
+	var problems []Problem
+	problems = append(problems, checkText(ep)...)
+	problems = append(problems, checkExplicit(ep, info)...)
+	problems = append(problems, checkAudio(info)...)
+	problems = append(problems, checkArtwork(info.Cover)...)
+	levels, err := checkLevels(ctx, ep.Path, info)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, levels...)
+	return problems, nil
+}
+
+func checkText(ep Episode) []Problem {
+	var problems []Problem
+	title := strings.TrimSpace(ep.Title)
+	switch n := utf8.RuneCountInString(title); {
+	case n == 0:
+		problems = append(problems, Problem{"title", "missing; pass --title or keep the episode's script JSON next to it"})
+	case n > MaxTitleChars:
+		problems = append(problems, Problem{"title", fmt.Sprintf("%d characters, over Apple's %d; shorten it with --title", n, MaxTitleChars)})
+	}
+	summary := strings.TrimSpace(ep.Summary)
+	switch n := utf8.RuneCountInString(summary); {
+	case n == 0:
+		problems = append(problems, Problem{"summary", "missing; Apple shows it on the episode page, so pass --summary"})
+	case n > MaxSummaryChars:
+		problems = append(problems, Problem{"summary", fmt.Sprintf("%d characters, over Apple's %d; shorten it with --summary", n, MaxSummaryChars)})
+	}
+	return problems
+}
+
+func checkExplicit(ep Episode, info assembly.AudioInfo) []Problem {
+	if ep.Explicit != nil {
+		return nil
+	}
+	if _, ok := assembly.AdvisoryOf(info.Tags); ok {
+		return nil
+	}
+	return []Problem{{"explicit", "not set; Apple requires every episode to say, so pass --explicit or --explicit=false"}}
+}
+
+func checkAudio(info assembly.AudioInfo) []Problem {
+	var problems []Problem
+	if info.Codec != "mp3" && info.Codec != "aac" {
+		problems = append(problems, Problem{"audio", fmt.Sprintf("%s isn't accepted; Apple takes MP3 or AAC, so regenerate with --output-format mp3 or aac", info.Codec)})
+	}
+	if info.SampleRate != 44100 && info.SampleRate != 48000 {
+		problems = append(problems, Problem{"audio", fmt.Sprintf("sample rate is %d Hz; use 44.1 kHz (podcaster generate's default)", info.SampleRate)})
+	}
+	if info.Channels < 1 || info.Channels > 2 {
+		problems = append(problems, Problem{"audio", fmt.Sprintf("%d channels; use mono or stereo (--channels 1 or 2)", info.Channels)})
+	}
+	if kbps := info.Bitrate / 1000; info.Bitrate > 0 {
+		switch {
+		case kbps < MinBitrateKbps:
+			problems = append(problems, Problem{"audio", fmt.Sprintf("bitrate is %d kbps, under %d; regenerate with --bitrate %dk or higher", kbps, MinBitrateKbps, MinBitrateKbps)})
+		case kbps > MaxBitrateKbps:
+			problems = append(problems, Problem{"audio", fmt.Sprintf("bitrate is %d kbps, over %d; regenerate with --bitrate 192k", kbps, MaxBitrateKbps)})
+		}
+	}
+	if info.Seconds <= 0 {
+		problems = append(problems, Problem{"audio", "has no duration; the file may be truncated, so regenerate it"})
+	}
+	return problems
+}
+
+// checkLevels measures loudness and true peak against the defaults the
+// generate quality check uses (assembly.DefaultQCBounds), so a clipped or
+// too-quiet episode isn't published. Silence isn't Apple's concern here.
+func checkLevels(ctx context.Context, path string, info assembly.AudioInfo) ([]Problem, error) {
+	bounds := assembly.DefaultQCBounds(strconv.Itoa(info.Channels))
+	bounds.MaxSilence = 1
+	r, err := assembly.MeasureQC(ctx, path, bounds)
+	if err != nil {
+		return nil, err
+	}
+	var problems []Problem
+	for _, p := range r.Problems {
+		problems = append(problems, Problem{"audio", p + "; regenerate it with loudness normalization on (FFmpeg installed and no --no-loudnorm), or normalize the file yourself"})
+	}
+	return problems, nil
+}
+
+// checkArtwork checks embedded cover art. An episode without any uses the
+// show's artwork, which is fine.
+func checkArtwork(c *assembly.CoverInfo) []Problem {
+	if c == nil {
+		return nil
+	}
+	var problems []Problem
+	if c.Codec != "mjpeg" && c.Codec != "png" {
+		problems = append(problems, Problem{"artwork", fmt.Sprintf("embedded as %s; Apple takes JPEG or PNG, so regenerate with a .jpg or .png --cover", c.Codec)})
+	}
+	switch {
+	case c.Width != c.Height:
+		problems = append(problems, Problem{"artwork", fmt.Sprintf("%dx%d isn't square; crop the --cover image to 1:1", c.Width, c.Height)})
+	case c.Width < MinArtworkPixels || c.Width > MaxArtworkPixels:
+		problems = append(problems, Problem{"artwork", fmt.Sprintf("%dx%d; Apple requires %dx%d to %dx%d, so resize the --cover image",
+			c.Width, c.Height, MinArtworkPixels, MinArtworkPixels, MaxArtworkPixels, MaxArtworkPixels)})
+	}
+	if strings.HasPrefix(c.PixFmt, "cmyk") || strings.HasPrefix(c.PixFmt, "gray") {
+		problems = append(problems, Problem{"artwork", fmt.Sprintf("uses the %s color space; save the --cover image as RGB", c.PixFmt)})
+	}
+	return problems
+}
@@ -0,0 +1,527 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Account export and deletion (GDPR access, portability, and erasure).
+// Both cover everything tied to a user ID:
+//
+//	USER#<id>/*        profile, monthly usage, and any other per-user records
+//	APIKEY#<prefix>    keys whose userId matches (key hashes are never exported)
+//	PODCAST#<id>       podcasts whose userId matches
+//	audio/, scripts/,
+//	transcripts/,
+//	pages/             the podcasts' S3 objects (and trash/ copies)
+//
+// Deletion runs as a single job and ends with a verification pass that
+// re-reads every source; the report lists whatever is still present. It is
+// idempotent, so a partial run can simply be repeated.
+
+// batchWriteMax is DynamoDB's BatchWriteItem request limit.
+const batchWriteMax = 25
+
+// AccountExport is the document returned by export_account.
+type AccountExport struct {
+	UserID     string           `json:"user_id"`
+	ExportedAt string           `json:"exported_at"`
+	Records    []map[string]any `json:"records"`
+	APIKeys    []map[string]any `json:"api_keys"`
+	Podcasts   []map[string]any `json:"podcasts"`
+}
+
+// DeletionReport summarizes a delete_account run. Counts are keyed by
+// category: user_records, api_keys, podcasts, s3_objects.
+type DeletionReport struct {
+	UserID      string         `json:"user_id"`
+	StartedAt   string         `json:"started_at"`
+	CompletedAt string         `json:"completed_at"`
+	Cancelled   []string       `json:"cancelled_tasks,omitempty"`
+	Deleted     map[string]int `json:"deleted"`
+	Remaining   map[string]int `json:"remaining"`
+	Errors      []string       `json:"errors,omitempty"`
+	Verified    bool           `json:"verified"`
+}
+
+// accountData is every DynamoDB item belonging to one user.
+type accountData struct {
+	records  []map[string]types.AttributeValue
+	apiKeys  []map[string]types.AttributeValue
+	podcasts []map[string]types.AttributeValue
+}
+
+// collectAccount reads every item belonging to userID.
+func (s *Store) collectAccount(ctx context.Context, userID string) (*accountData, error) {
+	var data accountData
+	var err error
+	if data.records, err = s.queryUserRecords(ctx, userID); err != nil {
+		return nil, err
+	}
+	if data.apiKeys, err = s.scanUserItems(ctx, "APIKEY#", userID); err != nil {
+		return nil, err
+	}
+	if data.podcasts, err = s.scanUserItems(ctx, "PODCAST#", userID); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// queryUserRecords returns every item in the USER#<id> partition.
+func (s *Store) queryUserRecords(ctx context.Context, userID string) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	p := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		},
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("query user records: %w", err)
+		}
+		items = append(items, page.Items...)
+	}
+	return items, nil
+}
+
+// scanUserItems returns every item under pkPrefix whose userId matches. Like
+// ListAPIKeys this scans; unlike it, it follows every page so nothing is missed.
+func (s *Store) scanUserItems(ctx context.Context, pkPrefix, userID string) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	p := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName:        &s.tableName,
+		FilterExpression: aws.String("begins_with(PK, :prefix) AND userId = :uid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prefix": &types.AttributeValueMemberS{Value: pkPrefix},
+			":uid":    &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("scan %s items: %w", pkPrefix, err)
+		}
+		items = append(items, page.Items...)
+	}
+	return items, nil
+}
+
+// deleteItems removes items by primary key, retrying unprocessed writes.
+// Returns the number deleted before any error.
+func (s *Store) deleteItems(ctx context.Context, items []map[string]types.AttributeValue) (int, error) {
+	reqs := make([]types.WriteRequest, 0, len(items))
+	for _, item := range items {
+		reqs = append(reqs, types.WriteRequest{DeleteRequest: &types.DeleteRequest{
+			Key: map[string]types.AttributeValue{"PK": item["PK"], "SK": item["SK"]},
+		}})
+	}
+	n, err := s.batchWrite(ctx, reqs)
+	if err != nil {
+		return n, fmt.Errorf("batch delete: %w", err)
+	}
+	return n, nil
+}
+
+// batchWrite sends write requests in BatchWriteItem-sized chunks (fewer
+// while throttled), retrying unprocessed writes, which count as throttles.
+// It returns the number written before any error.
+func (s *Store) batchWrite(ctx context.Context, reqs []types.WriteRequest) (int, error) {
+	written := 0
+	for start := 0; start < len(reqs); {
+		// Smaller batches while DynamoDB is throttling (capacity.go).
+		size := s.capacity.BatchSize()
+		chunk := reqs[start:min(start+size, len(reqs))]
+		start += len(chunk)
+		pending := map[string][]types.WriteRequest{s.tableName: chunk}
+		for attempt := 0; len(pending[s.tableName]) > 0; attempt++ {
+			if attempt == 5 {
+				return written, fmt.Errorf("%d items unprocessed after retries", len(pending[s.tableName]))
+			}
+			if attempt > 0 {
+				select {
+				case <-ctx.Done():
+					return written, ctx.Err()
+				case <-time.After(time.Duration(attempt*200) * time.Millisecond):
+				}
+			}
+			out, err := s.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return written, err
+			}
+			pending = out.UnprocessedItems
+			if len(pending[s.tableName]) > 0 && s.capacity != nil {
+				s.capacity.throttle(ctx, "BatchWriteItem")
+			}
+		}
+		written += len(chunk)
+	}
+	return written, nil
+}
+
+// podcastObjectKeys returns the S3 keys for a user's podcasts: the recorded
+// audio/script/transcript/page keys plus the conventional ones, in case a
+// record was never completed but an upload happened, and their trash/
+// copies.
+func podcastObjectKeys(podcasts []PodcastItem) []string {
+	seen := make(map[string]bool)
+	for _, p := range podcasts {
+		for _, key := range podcastKeys(p) {
+			seen[key] = true
+			seen[trashPrefix+key] = true
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// podcastKeys returns one podcast's live S3 keys, recorded and conventional.
+func podcastKeys(p PodcastItem) []string {
+	if p.PodcastID == "" {
+		return nil
+	}
+	var keys []string
+	for _, key := range []string{p.AudioKey, p.ScriptKey, p.TranscriptKey, p.PageKey, p.PeaksKey, "audio/" + p.PodcastID + ".mp3", "audio/" + p.PodcastID + ".peaks.json", "scripts/" + p.PodcastID + ".json", "transcripts/" + p.PodcastID + ".vtt", "pages/" + p.PodcastID + ".html"} {
+		if key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// podcastPrefixes returns the S3 prefixes holding a podcast's job files:
+// the debug bundle (and profile) of a failed job, and its partial TTS
+// results, at the conventional prefix and the recorded one.
+func podcastPrefixes(p PodcastItem) []string {
+	if p.PodcastID == "" {
+		return nil
+	}
+	prefixes := []string{pipeline.DebugPrefix(p.PodcastID), partialPrefix(p.PodcastID)}
+	if p.PartialPrefix != "" && !slices.Contains(prefixes, p.PartialPrefix) {
+		prefixes = append(prefixes, p.PartialPrefix)
+	}
+	return prefixes
+}
+
+// accountPrefixes returns the S3 prefixes of userID's account exports and
+// every podcast's job files.
+func accountPrefixes(userID string, podcasts []PodcastItem) []string {
+	prefixes := []string{exportPrefix(userID)}
+	for _, p := range podcasts {
+		for _, prefix := range podcastPrefixes(p) {
+			if !slices.Contains(prefixes, prefix) {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+	return prefixes
+}
+
+// exportAccount builds the export document for userID.
+func (h *Handlers) exportAccount(ctx context.Context, userID string) (*AccountExport, error) {
+	data, err := h.store.collectAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &AccountExport{
+		UserID:     userID,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	// The transcript search index is derived from the podcasts below.
+	records := slices.DeleteFunc(data.records, isSearchIndexItem)
+	if err := attributevalue.UnmarshalListOfMaps(records, &export.Records); err != nil {
+		return nil, fmt.Errorf("unmarshal user records: %w", err)
+	}
+	if err := attributevalue.UnmarshalListOfMaps(data.apiKeys, &export.APIKeys); err != nil {
+		return nil, fmt.Errorf("unmarshal API keys: %w", err)
+	}
+	for _, k := range export.APIKeys {
+		delete(k, "keyHash")
+	}
+	if err := attributevalue.UnmarshalListOfMaps(data.podcasts, &export.Podcasts); err != nil {
+		return nil, fmt.Errorf("unmarshal podcasts: %w", err)
+	}
+	return export, nil
+}
+
+// deleteAccount erases everything tied to userID and verifies the result.
+// Steps continue past errors so one failure doesn't strand the rest; the
+// report records each error and what the verification pass still found.
+func (h *Handlers) deleteAccount(ctx context.Context, userID string) (*DeletionReport, error) {
+	report := &DeletionReport{
+		UserID:    userID,
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+		Deleted:   make(map[string]int),
+	}
+
+	data, err := h.store.collectAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var podcasts []PodcastItem
+	if err := attributevalue.UnmarshalListOfMaps(data.podcasts, &podcasts); err != nil {
+		return nil, fmt.Errorf("unmarshal podcasts: %w", err)
+	}
+
+	// Stop in-flight generations on this instance so they don't write the
+	// podcast back after it's deleted. Tasks on other instances are caught by
+	// the verification pass.
+	for _, p := range podcasts {
+		if p.Status != string(JobStatusComplete) && p.Status != string(JobStatusFailed) {
+			h.tasks.CancelTask(p.PodcastID)
+			report.Cancelled = append(report.Cancelled, p.PodcastID)
+		}
+	}
+
+	fail := func(step string, err error) {
+		h.log.Error("Account deletion step failed", "user_id", userID, "step", step, "error", err)
+		report.Errors = append(report.Errors, step+": "+err.Error())
+	}
+
+	// Keys first, so the account can't be used while it's being erased.
+	n, err := h.store.deleteItems(ctx, data.apiKeys)
+	report.Deleted["api_keys"] = n
+	if err != nil {
+		fail("api_keys", err)
+	}
+
+	objectKeys := podcastObjectKeys(podcasts)
+	for _, key := range objectKeys {
+		if err := h.storage.Delete(ctx, key); err != nil {
+			fail("s3_objects", err)
+			continue
+		}
+		report.Deleted["s3_objects"]++
+	}
+	prefixes := accountPrefixes(userID, podcasts)
+	for _, prefix := range prefixes {
+		n, err := h.storage.DeletePrefix(ctx, prefix)
+		report.Deleted["s3_objects"] += n
+		if err != nil {
+			fail("s3_objects", err)
+		}
+	}
+	for _, p := range podcasts {
+		if p.PartialPrefix == "" {
+			continue
+		}
+		if err := h.store.ClearPartial(ctx, p.PodcastID); err != nil {
+			fail("podcasts", err)
+		}
+	}
+
+	n, err = h.store.deleteItems(ctx, data.podcasts)
+	report.Deleted["podcasts"] = n
+	if err != nil {
+		fail("podcasts", err)
+	}
+
+	n, err = h.store.deleteItems(ctx, data.records)
+	report.Deleted["user_records"] = n
+	if err != nil {
+		fail("user_records", err)
+	}
+
+	report.Remaining, err = h.verifyDeleted(ctx, userID, objectKeys, prefixes)
+	if err != nil {
+		fail("verify", err)
+	}
+	report.Verified = err == nil && len(report.Remaining) == 0
+	report.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+
+	h.log.Info("Account deletion finished",
+		"user_id", userID,
+		"verified", report.Verified,
+		"deleted", report.Deleted,
+		"remaining", report.Remaining,
+		"errors", len(report.Errors),
+	)
+	return report, nil
+}
+
+// verifyDeleted re-reads every source and counts what is still present,
+// objectKeys and anything under prefixes included. Categories with nothing
+// left are omitted.
+func (h *Handlers) verifyDeleted(ctx context.Context, userID string, objectKeys, prefixes []string) (map[string]int, error) {
+	remaining := make(map[string]int)
+	data, err := h.store.collectAccount(ctx, userID)
+	if err != nil {
+		return remaining, err
+	}
+	if n := len(data.records); n > 0 {
+		remaining["user_records"] = n
+	}
+	if n := len(data.apiKeys); n > 0 {
+		remaining["api_keys"] = n
+	}
+	if n := len(data.podcasts); n > 0 {
+		remaining["podcasts"] = n
+	}
+	for _, key := range objectKeys {
+		exists, err := h.storage.Exists(ctx, key)
+		if err != nil {
+			return remaining, err
+		}
+		if exists {
+			remaining["s3_objects"]++
+		}
+	}
+	for _, prefix := range prefixes {
+		keys, err := h.storage.List(ctx, prefix)
+		if err != nil {
+			return remaining, err
+		}
+		if len(keys) > 0 {
+			remaining["s3_objects"] += len(keys)
+		}
+	}
+	return remaining, nil
+}
+
+// caller resolves the authenticated user and their role, from either the
+// HTTP auth context or the proxy-injected _user_id (see proxyArg).
+func (h *Handlers) caller(ctx context.Context, req mcp.CallToolRequest) (userID, role string) {
+	auth := AuthFromContext(ctx)
+	if auth.Authenticated {
+		return auth.UserID, auth.Role
+	}
+	if uid := h.proxyArg(req, "_user_id"); uid != "" {
+		// Proxy flow: the proxy validated the key but doesn't forward the role.
+		if user, err := h.store.GetUser(ctx, uid); err == nil && user != nil {
+			role = user.Role
+		}
+		return uid, role
+	}
+	return "", ""
+}
+
+// proxyArg returns the proxy-injected tool argument name (_user_id,
+// _key_id, _trial_ip_hash), or "" when the server isn't behind the proxy
+// (Config.TrustProxy off): then anyone could pass them and act as any user.
+func (h *Handlers) proxyArg(req mcp.CallToolRequest, name string) string {
+	if !h.trustProxy {
+		return ""
+	}
+	v, _ := req.GetArguments()[name].(string)
+	return v
+}
+
+// accountTarget resolves whose account an export/delete call acts on.
+// Users act on themselves; admins may name another user with user_id.
+// Returns a user-facing error message when the call isn't allowed.
+func (h *Handlers) accountTarget(ctx context.Context, req mcp.CallToolRequest) (string, string) {
+	callerID, role := h.caller(ctx, req)
+	if callerID == "" {
+		return "", "Authentication required. Provide your API key as: Authorization: Bearer <your-api-key>."
+	}
+
+	target := mcp.ParseString(req, "user_id", "")
+	if target == "" || target == callerID {
+		return callerID, ""
+	}
+	if role != "admin" {
+		return "", "Only admins can act on another user's account."
+	}
+	return target, ""
+}
+
+// HandleExportAccount exports everything stored about a user to a private
+// S3 object and returns a time-limited download link.
+func (h *Handlers) HandleExportAccount(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, span := tracer.Start(ctx, "tool.export_account")
+	defer span.End()
+
+	userID, msg := h.accountTarget(ctx, req)
+	if msg != "" {
+		span.SetStatus(codes.Error, "not allowed")
+		return mcp.NewToolResultError(msg), nil
+	}
+	span.SetAttributes(attribute.String("user_id", userID))
+
+	export, err := h.exportAccount(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "export failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to export account: %v", err)), nil
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshal export: %v", err)), nil
+	}
+	key, url, err := h.storage.UploadExport(ctx, userID, data)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "upload failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to store export: %v", err)), nil
+	}
+
+	h.log.Info("Account exported", "user_id", userID, "key", key,
+		"records", len(export.Records), "api_keys", len(export.APIKeys), "podcasts", len(export.Podcasts))
+
+	return jsonResult(map[string]any{
+		"user_id":      userID,
+		"download_url": url,
+		"expires_in":   exportURLExpiry.String(),
+		"counts": map[string]int{
+			"user_records": len(export.Records),
+			"api_keys":     len(export.APIKeys),
+			"podcasts":     len(export.Podcasts),
+		},
+	})
+}
+
+// HandleDeleteAccount permanently erases a user's account and data.
+func (h *Handlers) HandleDeleteAccount(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, span := tracer.Start(ctx, "tool.delete_account")
+	defer span.End()
+
+	userID, msg := h.accountTarget(ctx, req)
+	if msg != "" {
+		span.SetStatus(codes.Error, "not allowed")
+		return mcp.NewToolResultError(msg), nil
+	}
+	span.SetAttributes(attribute.String("user_id", userID))
+
+	if mcp.ParseString(req, "confirm", "") != userID {
+		span.SetStatus(codes.Error, "not confirmed")
+		return mcp.NewToolResultError(fmt.Sprintf("This permanently deletes the account, API keys, and all podcasts. To proceed, call again with confirm set to %q.", userID)), nil
+	}
+
+	// Run detached from the request so a client disconnect can't leave the
+	// account half-deleted.
+	delCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Minute)
+	defer cancel()
+
+	report, err := h.deleteAccount(delCtx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "delete failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete account: %v", err)), nil
+	}
+	span.SetAttributes(attribute.Bool("verified", report.Verified))
+	if !report.Verified {
+		span.SetStatus(codes.Error, "verification failed")
+	}
+	return jsonResult(report)
+}
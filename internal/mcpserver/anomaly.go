@@ -0,0 +1,70 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Per-key daily usage feeds the usage monitor (cmd/usage-monitor), which
+// compares each key's usage today against its trailing baseline and alerts
+// on or suspends keys with runaway usage — typically a leaked key or a
+// client stuck in a retry loop.
+
+// keyUsageTTL is how long daily key usage is kept; it must exceed the
+// longest baseline window.
+const keyUsageTTL = 60 * 24 * time.Hour
+
+// KeyUsageRecord is one API key's usage for one UTC day.
+// PK=APIKEY#{prefix}, SK=USAGE#{YYYY-MM-DD}. It carries userId so account
+// export/deletion picks it up with the key.
+type KeyUsageRecord struct {
+	PK        string  `dynamodbav:"PK"`
+	SK        string  `dynamodbav:"SK"`
+	UserID    string  `dynamodbav:"userId"`
+	Requests  int     `dynamodbav:"requests"`
+	CostUSD   float64 `dynamodbav:"costUSD"`
+	FlaggedAt string  `dynamodbav:"flaggedAt,omitempty"` // set by the usage monitor once reported
+	TTL       int64   `dynamodbav:"ttl"`
+}
+
+// RecordKeyRequest counts one generation request against a key for today.
+func (s *Store) RecordKeyRequest(ctx context.Context, keyPrefix, userID string) error {
+	return s.addKeyUsage(ctx, keyPrefix, userID, "requests", "1")
+}
+
+// RecordKeyCost adds a completed generation's estimated cost to a key's
+// usage for today.
+func (s *Store) RecordKeyCost(ctx context.Context, keyPrefix, userID string, costUSD float64) error {
+	return s.addKeyUsage(ctx, keyPrefix, userID, "costUSD", fmt.Sprintf("%.6f", costUSD))
+}
+
+func (s *Store) addKeyUsage(ctx context.Context, keyPrefix, userID, attr, value string) error {
+	now := time.Now().UTC()
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "APIKEY#" + keyPrefix},
+			"SK": &types.AttributeValueMemberS{Value: "USAGE#" + now.Format("2006-01-02")},
+		},
+		UpdateExpression: aws.String("ADD #attr :v SET userId = :uid, #ttl = :ttl"),
+		ExpressionAttributeNames: map[string]string{
+			"#attr": attr,
+			"#ttl":  "ttl",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":v":   &types.AttributeValueMemberN{Value: value},
+			":uid": &types.AttributeValueMemberS{Value: userID},
+			":ttl": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(keyUsageTTL).Unix(), 10)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("record key usage: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Episode archival. With Config.ArchiveAfterDays set, episode audio is
+// uploaded with the archiveTag, and the bucket's lifecycle rule for that tag
+// (deploy/infrastructure, with the same number of days) moves it to Glacier
+// Flexible Retrieval. An archived episode can't be played until
+// restore_podcast has S3 bring back a temporary copy, which takes minutes to
+// hours depending on the tier; get_podcast reports where that stands.
+// Scripts, transcripts, and pages stay in S3 Standard: they're small.
+
+// archiveTag is the S3 object tag the lifecycle rule transitions.
+const archiveTag = "archive=true"
+
+// archiveRestoreDays is how long a restored copy of archived audio stays
+// playable before S3 removes it again.
+const archiveRestoreDays = 7
+
+// Archive states of an episode's audio, as get_podcast reports them.
+const (
+	archiveArchived  = "archived"  // in Glacier; restore_podcast to play it
+	archiveRestoring = "restoring" // restore requested, not done yet
+	archiveRestored  = "restored"  // temporary copy playable until archiveState.Until
+)
+
+// archiveTiers maps restore_podcast's tier argument to S3's retrieval tiers.
+var archiveTiers = map[string]s3types.Tier{
+	"expedited": s3types.TierExpedited, // 1-5 minutes
+	"standard":  s3types.TierStandard,  // 3-5 hours
+	"bulk":      s3types.TierBulk,      // 5-12 hours
+}
+
+// archiveState is where an object stands in archival. Status is empty for
+// objects that aren't archived.
+type archiveState struct {
+	Status string
+	Until  time.Time // when a restored copy expires
+}
+
+// ArchiveState reports whether an object is archived and, if so, whether a
+// restored copy is on its way or ready.
+func (s *Storage) ArchiveState(ctx context.Context, key string) (archiveState, error) {
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return archiveState{}, fmt.Errorf("head %s: %w", key, err)
+	}
+	switch out.StorageClass {
+	case s3types.StorageClassGlacier, s3types.StorageClassDeepArchive:
+	default:
+		return archiveState{}, nil
+	}
+	// x-amz-restore: ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"
+	restore := aws.ToString(out.Restore)
+	switch {
+	case restore == "":
+		return archiveState{Status: archiveArchived}, nil
+	case strings.Contains(restore, `ongoing-request="true"`):
+		return archiveState{Status: archiveRestoring}, nil
+	}
+	st := archiveState{Status: archiveRestored}
+	if _, expiry, ok := strings.Cut(restore, `expiry-date="`); ok {
+		expiry, _, _ = strings.Cut(expiry, `"`)
+		st.Until, _ = time.Parse(http.TimeFormat, expiry)
+	}
+	return st, nil
+}
+
+// RestoreArchived asks S3 for a temporary copy of an archived object, kept
+// for archiveRestoreDays.
+func (s *Storage) RestoreArchived(ctx context.Context, key string, tier s3types.Tier) error {
+	_, err := s.client.RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+		RestoreRequest: &s3types.RestoreRequest{
+			Days:                 aws.Int32(archiveRestoreDays),
+			GlacierJobParameters: &s3types.GlacierJobParameters{Tier: tier},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("restore %s: %w", key, err)
+	}
+	return nil
+}
+
+// SetArchiveRestore records a restore_podcast request for archived audio:
+// when, and at which tier.
+func (s *Store) SetArchiveRestore(ctx context.Context, id string, at time.Time, tier string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "PODCAST#" + id},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		UpdateExpression: aws.String("SET archiveRestoreAt = :at, archiveRestoreTier = :tier"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":at":   &types.AttributeValueMemberS{Value: at.UTC().Format(time.RFC3339)},
+			":tier": &types.AttributeValueMemberS{Value: tier},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("set archive restore: %w", err)
+	}
+	return nil
+}
+
+// mayBeArchived reports whether the lifecycle rule could have archived the
+// podcast's audio yet, sparing younger episodes the HeadObject.
+func (h *Handlers) mayBeArchived(item *PodcastItem) bool {
+	days := h.storage.archiveAfterDays
+	if days <= 0 || item.AudioKey == "" || item.DeletedAt != "" || item.Status != string(JobStatusComplete) {
+		return false
+	}
+	created, err := time.Parse(time.RFC3339, item.CreatedAt)
+	return err == nil && time.Since(created) >= time.Duration(days)*24*time.Hour
+}
+
+// addArchiveState adds the audio's archive state to a get_podcast result.
+// Archived or restoring audio can't be played, so its audio_url is dropped.
+func (h *Handlers) addArchiveState(ctx context.Context, item *PodcastItem, result map[string]any) {
+	if !h.mayBeArchived(item) {
+		return
+	}
+	st, err := h.storage.ArchiveState(ctx, item.AudioKey)
+	if err != nil {
+		h.log.Warn("Check archive state failed", "podcast_id", item.PodcastID, "error", err)
+		return
+	}
+	if st.Status == "" {
+		return
+	}
+	result["archive_status"] = st.Status
+	if item.ArchiveRestoreAt != "" {
+		result["restore_requested_at"] = item.ArchiveRestoreAt
+		result["restore_tier"] = item.ArchiveRestoreTier
+	}
+	switch st.Status {
+	case archiveArchived:
+		delete(result, "audio_url")
+		result["restore_hint"] = "The audio is archived. Call restore_podcast to make it playable again."
+	case archiveRestoring:
+		delete(result, "audio_url")
+	case archiveRestored:
+		if !st.Until.IsZero() {
+			result["available_until"] = st.Until.UTC().Format(time.RFC3339)
+		}
+	}
+}
+
+// restoreArchived serves restore_podcast for a podcast that isn't in the
+// trash: it starts retrieving archived audio, or reports a retrieval
+// already under way.
+func (h *Handlers) restoreArchived(ctx context.Context, req mcp.CallToolRequest, item *PodcastItem) (*mcp.CallToolResult, error) {
+	span := trace.SpanFromContext(ctx)
+	if !h.mayBeArchived(item) {
+		span.SetStatus(codes.Error, "not deleted or archived")
+		return mcp.NewToolResultError(fmt.Sprintf("podcast %s is not deleted or archived", item.PodcastID)), nil
+	}
+	tierName := strings.ToLower(mcp.ParseString(req, "tier", "standard"))
+	tier, ok := archiveTiers[tierName]
+	if !ok {
+		span.SetStatus(codes.Error, "bad tier")
+		return mcp.NewToolResultError(fmt.Sprintf("unknown tier %q: must be expedited, standard, or bulk", tierName)), nil
+	}
+
+	st, err := h.storage.ArchiveState(ctx, item.AudioKey)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "archive state failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check archived audio: %v", err)), nil
+	}
+	result := map[string]any{
+		"podcast_id":     item.PodcastID,
+		"archive_status": st.Status,
+	}
+	switch st.Status {
+	case "":
+		span.SetStatus(codes.Error, "not deleted or archived")
+		return mcp.NewToolResultError(fmt.Sprintf("podcast %s is not deleted or archived", item.PodcastID)), nil
+	case archiveRestoring:
+		result["restore_requested_at"] = item.ArchiveRestoreAt
+		result["message"] = "The audio is already being restored. Use get_podcast to check when it's playable."
+		return jsonResult(result)
+	case archiveRestored:
+		if !st.Until.IsZero() {
+			result["available_until"] = st.Until.UTC().Format(time.RFC3339)
+		}
+		result["audio_url"] = item.AudioURL
+		result["message"] = "The audio is already restored and playable."
+		return jsonResult(result)
+	}
+
+	if err := h.storage.RestoreArchived(ctx, item.AudioKey, tier); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "restore archived failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to restore archived audio: %v", err)), nil
+	}
+	now := time.Now().UTC()
+	if err := h.store.SetArchiveRestore(ctx, item.PodcastID, now, tierName); err != nil {
+		// The restore is under way regardless; only the tracking is lost.
+		h.log.Warn("Record archive restore failed", "podcast_id", item.PodcastID, "error", err)
+	}
+
+	h.log.Info("Archived podcast restore requested", "podcast_id", item.PodcastID, "user_id", item.UserID, "tier", tierName)
+
+	result["archive_status"] = archiveRestoring
+	result["restore_requested_at"] = now.Format(time.RFC3339)
+	result["restore_tier"] = tierName
+	result["message"] = fmt.Sprintf("Restoring the archived audio (%s tier). Use get_podcast to check when it's playable; it then stays playable for %d days.", tierName, archiveRestoreDays)
+	return jsonResult(result)
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/apresai/podcaster/internal/script"
+	"github.com/apresai/podcaster/internal/tts"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -34,7 +36,7 @@ type APIKeyRecord struct {
 	UserID     string `dynamodbav:"userId"`
 	KeyHash    string `dynamodbav:"keyHash"`    // SHA-256 hex
 	Name       string `dynamodbav:"name"`       // user-given name
-	Status     string `dynamodbav:"status"`     // active, revoked
+	Status     string `dynamodbav:"status"`     // active, revoked, suspended
 	CreatedAt  string `dynamodbav:"createdAt"`
 	LastUsedAt string `dynamodbav:"lastUsedAt,omitempty"`
 }
@@ -252,9 +254,10 @@ func (s *Store) ListAPIKeys(ctx context.Context, userID string) ([]APIKeyRecord,
 	// Scan for keys belonging to this user (small table, acceptable)
 	result, err := s.client.Scan(ctx, &dynamodb.ScanInput{
 		TableName:        &s.tableName,
-		FilterExpression: aws.String("begins_with(PK, :prefix) AND userId = :uid"),
+		FilterExpression: aws.String("begins_with(PK, :prefix) AND SK = :meta AND userId = :uid"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":prefix": &types.AttributeValueMemberS{Value: "APIKEY#"},
+			":meta":   &types.AttributeValueMemberS{Value: "METADATA"},
 			":uid":    &types.AttributeValueMemberS{Value: userID},
 		},
 	})
@@ -417,41 +420,21 @@ func (s *Store) ListUsers(ctx context.Context) ([]UserRecord, error) {
 func EstimateCost(model, ttsProvider string, inputChars, ttsChars, durationSec int) float64 {
 	var cost float64
 
-	// Script generation cost (rough estimates based on API pricing)
-	inputTokens := float64(inputChars) / 4 // ~4 chars per token
-	switch model {
-	case "haiku":
-		cost += inputTokens * 0.80 / 1_000_000  // input
-		cost += inputTokens * 4.00 / 1_000_000   // output (assume ~1:1 ratio)
-	case "sonnet":
-		cost += inputTokens * 3.00 / 1_000_000
-		cost += inputTokens * 15.00 / 1_000_000
-	case "gemini-flash":
-		cost += inputTokens * 0.075 / 1_000_000
-		cost += inputTokens * 0.30 / 1_000_000
-	case "gemini-pro":
-		cost += inputTokens * 1.25 / 1_000_000
-		cost += inputTokens * 10.00 / 1_000_000
-	}
+	// Script generation cost: ~4 chars per token, output assumed ~1:1
+	tokens := inputChars / 4
+	cost += script.Usage{InputTokens: tokens, OutputTokens: tokens}.CostUSD(model)
 
 	// TTS cost
-	ttsCharsF := float64(ttsChars)
-	switch ttsProvider {
-	case "gemini":
-		// Gemini TTS is included in the API pricing, minimal additional cost
-		cost += ttsCharsF * 0.000016 // ~$16 per 1M chars
-	case "elevenlabs":
-		cost += ttsCharsF * 0.00018 // ~$180 per 1M chars (Creator plan rate)
-	case "google":
-		cost += ttsCharsF * 0.000016 // Google Cloud TTS standard
-	}
+	cost += tts.EstimateCost(ttsProvider, ttsChars)
 
 	return cost
 }
 
-// RecordUsage updates the podcast item with usage data and increments the monthly rollup.
-func (s *Store) RecordUsage(ctx context.Context, podcastID, userID, model, ttsProvider string, inputChars, ttsChars, durationSec int) error {
-	cost := EstimateCost(model, ttsProvider, inputChars, ttsChars, durationSec)
+// RecordUsage updates the podcast item with usage data and increments the
+// monthly rollup. extraCostUSD is added to the estimate (a script retried
+// with a stronger model).
+func (s *Store) RecordUsage(ctx context.Context, podcastID, userID, model, ttsProvider string, inputChars, ttsChars, durationSec int, extraCostUSD float64) error {
+	cost := EstimateCost(model, ttsProvider, inputChars, ttsChars, durationSec) + extraCostUSD
 
 	// Update podcast record with usage data
 	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
@@ -0,0 +1,172 @@
+package mcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// maxBatchSize bounds how many JSON-RPC messages one batch may carry.
+const maxBatchSize = 50
+
+// maxRequestBytes bounds the body of a POST to /mcp, which is read into
+// memory whole. Inline source text is the largest thing a request carries.
+const maxRequestBytes = 10 << 20
+
+// batchHandler adds JSON-RPC batch support in front of mcp-go's streamable
+// HTTP handler, which only accepts a single message per POST. A batch array
+// is split into individual requests, each served by next in order, and the
+// responses are merged back into one array. Notifications produce no entry;
+// a batch of only notifications returns 202 Accepted, per the spec.
+func batchHandler(next http.Handler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+		r.Body.Close()
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeBatchError(w, http.StatusRequestEntityTooLarge, -32600, "invalid request: body too large")
+				return
+			}
+			writeBatchError(w, http.StatusBadRequest, -32700, "failed to read request body")
+			return
+		}
+
+		trimmed := bytes.TrimSpace(body)
+		if len(trimmed) == 0 || trimmed[0] != '[' {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var entries []json.RawMessage
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			writeBatchError(w, http.StatusBadRequest, -32700, "parse error: invalid JSON-RPC batch")
+			return
+		}
+		if len(entries) == 0 {
+			writeBatchError(w, http.StatusBadRequest, -32600, "invalid request: empty batch")
+			return
+		}
+		if len(entries) > maxBatchSize {
+			writeBatchError(w, http.StatusBadRequest, -32600, "invalid request: batch too large")
+			return
+		}
+
+		logger.Info("JSON-RPC batch", "entries", len(entries))
+
+		responses := make([]json.RawMessage, 0, len(entries))
+		var sessionID string
+		for _, entry := range entries {
+			sub := r.Clone(r.Context())
+			sub.Body = io.NopCloser(bytes.NewReader(entry))
+			sub.ContentLength = int64(len(entry))
+			// mcp-go rejects a POST that doesn't accept both types; SSE
+			// responses are unwrapped by extractJSONRPC.
+			sub.Header.Set("Accept", "application/json, text/event-stream")
+			if sessionID != "" && sub.Header.Get("Mcp-Session-Id") == "" {
+				sub.Header.Set("Mcp-Session-Id", sessionID)
+			}
+
+			rec := newBufferedResponse()
+			next.ServeHTTP(rec, sub)
+
+			if id := rec.header.Get("Mcp-Session-Id"); id != "" {
+				sessionID = id
+			}
+			responses = append(responses, extractJSONRPC(rec.header.Get("Content-Type"), rec.body.Bytes())...)
+		}
+
+		if sessionID != "" {
+			w.Header().Set("Mcp-Session-Id", sessionID)
+		}
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		out, err := json.Marshal(responses)
+		if err != nil {
+			writeBatchError(w, http.StatusInternalServerError, -32603, "failed to encode batch response")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(out)
+	})
+}
+
+// extractJSONRPC pulls JSON-RPC messages out of a response body, which may be
+// plain JSON (object or array) or an SSE stream of "data:" lines.
+func extractJSONRPC(contentType string, body []byte) []json.RawMessage {
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		var out []json.RawMessage
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			out = append(out, extractJSONRPC("application/json", []byte(strings.TrimSpace(line[5:])))...)
+		}
+		return out
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	if trimmed[0] == '[' {
+		var arr []json.RawMessage
+		if json.Unmarshal(trimmed, &arr) == nil {
+			return arr
+		}
+		return nil
+	}
+	if json.Valid(trimmed) {
+		return []json.RawMessage{json.RawMessage(trimmed)}
+	}
+	return nil
+}
+
+// bufferedResponse captures a sub-request's response for merging.
+type bufferedResponse struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+}
+
+func (b *bufferedResponse) Header() http.Header         { return b.header }
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *bufferedResponse) WriteHeader(status int)      { b.status = status }
+func (b *bufferedResponse) Flush()                      {}
+
+// writeBatchError writes a single JSON-RPC error with a null id.
+func writeBatchError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      nil,
+		"error": map[string]any{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,305 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// DynamoDB capacity telemetry and write backoff. capacityMonitor is client
+// middleware: it asks every call for its consumed capacity and adds it up
+// (OTEL metrics and server_info), and counts throttled attempts, which the
+// SDK retries on its own. Each throttle raises a backoff level that
+// stretches the interval between a job's progress writes and shrinks
+// BatchWriteItem batches; a minute without throttles lowers it again.
+
+const (
+	// baseProgressInterval is the interval between a job's progress
+	// writes while nothing is throttled. Stage changes are always written.
+	baseProgressInterval = 2 * time.Second
+
+	// maxBackoffLevel caps the backoff: progress writes every 32s at most,
+	// batches of 25>>4 = 1 item.
+	maxBackoffLevel = 4
+
+	// backoffDecay is how long without throttles lowers the level by one.
+	backoffDecay = time.Minute
+
+	// backoffStep is the least time between raises, so the retries of one
+	// throttled burst count once.
+	backoffStep = time.Second
+)
+
+// OTEL instruments, as in tts/metrics.go.
+var (
+	meter            = otel.Meter("podcaster-mcp")
+	consumedCapacity metric.Float64Counter
+	throttledCalls   metric.Int64Counter
+	capacityInstErr  error
+)
+
+func init() {
+	var errs [2]error
+	consumedCapacity, errs[0] = meter.Float64Counter("dynamodb.consumed_capacity",
+		metric.WithUnit("{capacity_unit}"), metric.WithDescription("DynamoDB capacity units consumed, by operation and kind (read or write)"))
+	throttledCalls, errs[1] = meter.Int64Counter("dynamodb.throttles",
+		metric.WithDescription("Throttled DynamoDB attempts by operation, including ones the SDK retried"))
+	capacityInstErr = errors.Join(errs[:]...)
+}
+
+// capacityMonitor tracks DynamoDB consumption and throttling for the
+// process. A nil *capacityMonitor reports no backoff.
+type capacityMonitor struct {
+	log *slog.Logger
+
+	mu           sync.Mutex
+	rcu, wcu     float64
+	throttles    int64
+	level        int       // backoff level as of lastRaise
+	lastRaise    time.Time // when level was last raised
+	lastThrottle time.Time
+}
+
+func newCapacityMonitor(logger *slog.Logger) *capacityMonitor {
+	return &capacityMonitor{log: logger}
+}
+
+// addTo installs the monitor's middleware on a DynamoDB client.
+func (c *capacityMonitor) addTo(o *dynamodb.Options) {
+	o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+		if err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ConsumedCapacity", c.consumed), middleware.After); err != nil {
+			return err
+		}
+		// Before the deserializer's peers but inside the retry loop, so
+		// every attempt's error is seen.
+		return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("ThrottleDetection", c.throttled), middleware.Before)
+	})
+}
+
+// consumed asks for the call's total consumed capacity and records it.
+func (c *capacityMonitor) consumed(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	in.Parameters = withConsumedCapacity(in.Parameters)
+	out, md, err := next.HandleInitialize(ctx, in)
+	if err == nil {
+		if units, write, ok := capacityOf(out.Result); ok {
+			c.record(ctx, awsmiddleware.GetOperationName(ctx), units, write)
+		}
+	}
+	return out, md, err
+}
+
+// throttled counts attempts DynamoDB rejected for capacity.
+func (c *capacityMonitor) throttled(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (middleware.DeserializeOutput, middleware.Metadata, error) {
+	out, md, err := next.HandleDeserialize(ctx, in)
+	if isThrottle(err) {
+		c.throttle(ctx, awsmiddleware.GetOperationName(ctx))
+	}
+	return out, md, err
+}
+
+// isThrottle reports whether err is DynamoDB refusing a request for
+// capacity rather than for the request itself.
+func isThrottle(err error) bool {
+	var pte *types.ProvisionedThroughputExceededException
+	var rle *types.RequestLimitExceeded
+	var te *types.ThrottlingException
+	return errors.As(err, &pte) || errors.As(err, &rle) || errors.As(err, &te)
+}
+
+// withConsumedCapacity returns a copy of an operation's input asking for
+// ReturnConsumedCapacity=TOTAL; the caller's input is left alone.
+func withConsumedCapacity(params any) any {
+	total := types.ReturnConsumedCapacityTotal
+	switch p := params.(type) {
+	case *dynamodb.GetItemInput:
+		cp := *p
+		cp.ReturnConsumedCapacity = total
+		return &cp
+	case *dynamodb.QueryInput:
+		cp := *p
+		cp.ReturnConsumedCapacity = total
+		return &cp
+	case *dynamodb.ScanInput:
+		cp := *p
+		cp.ReturnConsumedCapacity = total
+		return &cp
+	case *dynamodb.BatchGetItemInput:
+		cp := *p
+		cp.ReturnConsumedCapacity = total
+		return &cp
+	case *dynamodb.PutItemInput:
+		cp := *p
+		cp.ReturnConsumedCapacity = total
+		return &cp
+	case *dynamodb.UpdateItemInput:
+		cp := *p
+		cp.ReturnConsumedCapacity = total
+		return &cp
+	case *dynamodb.DeleteItemInput:
+		cp := *p
+		cp.ReturnConsumedCapacity = total
+		return &cp
+	case *dynamodb.BatchWriteItemInput:
+		cp := *p
+		cp.ReturnConsumedCapacity = total
+		return &cp
+	case *dynamodb.TransactWriteItemsInput:
+		cp := *p
+		cp.ReturnConsumedCapacity = total
+		return &cp
+	}
+	return params
+}
+
+// capacityOf returns the capacity units an operation's output reports
+// consuming, and whether they were writes.
+func capacityOf(result any) (units float64, write, ok bool) {
+	sum := func(cc []types.ConsumedCapacity) float64 {
+		var n float64
+		for _, c := range cc {
+			n += unitsOf(&c)
+		}
+		return n
+	}
+	switch r := result.(type) {
+	case *dynamodb.GetItemOutput:
+		return unitsOf(r.ConsumedCapacity), false, true
+	case *dynamodb.QueryOutput:
+		return unitsOf(r.ConsumedCapacity), false, true
+	case *dynamodb.ScanOutput:
+		return unitsOf(r.ConsumedCapacity), false, true
+	case *dynamodb.BatchGetItemOutput:
+		return sum(r.ConsumedCapacity), false, true
+	case *dynamodb.PutItemOutput:
+		return unitsOf(r.ConsumedCapacity), true, true
+	case *dynamodb.UpdateItemOutput:
+		return unitsOf(r.ConsumedCapacity), true, true
+	case *dynamodb.DeleteItemOutput:
+		return unitsOf(r.ConsumedCapacity), true, true
+	case *dynamodb.BatchWriteItemOutput:
+		return sum(r.ConsumedCapacity), true, true
+	case *dynamodb.TransactWriteItemsOutput:
+		return sum(r.ConsumedCapacity), true, true
+	}
+	return 0, false, false
+}
+
+func unitsOf(c *types.ConsumedCapacity) float64 {
+	if c == nil || c.CapacityUnits == nil {
+		return 0
+	}
+	return *c.CapacityUnits
+}
+
+func (c *capacityMonitor) record(ctx context.Context, op string, units float64, write bool) {
+	kind := "read"
+	if write {
+		kind = "write"
+	}
+	if capacityInstErr == nil {
+		consumedCapacity.Add(ctx, units, metric.WithAttributes(
+			attribute.String("operation", op),
+			attribute.String("kind", kind),
+		))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if write {
+		c.wcu += units
+	} else {
+		c.rcu += units
+	}
+}
+
+// throttle counts a throttled attempt and raises the backoff level.
+func (c *capacityMonitor) throttle(ctx context.Context, op string) {
+	if capacityInstErr == nil {
+		throttledCalls.Add(ctx, 1, metric.WithAttributes(attribute.String("operation", op)))
+	}
+	c.mu.Lock()
+	now := time.Now()
+	c.throttles++
+	c.lastThrottle = now
+	raised := false
+	if now.Sub(c.lastRaise) >= backoffStep {
+		if level := c.levelAt(now); level < maxBackoffLevel {
+			c.level, c.lastRaise, raised = level+1, now, true
+		} else {
+			c.level, c.lastRaise = level, now
+		}
+	}
+	level := c.level
+	c.mu.Unlock()
+	if raised {
+		c.log.Warn("DynamoDB throttled; slowing progress writes",
+			"operation", op, "backoff_level", level, "progress_interval", baseProgressInterval<<level)
+	}
+}
+
+// levelAt is the backoff level at t, after decay. c.mu must be held.
+func (c *capacityMonitor) levelAt(t time.Time) int {
+	if c.level == 0 {
+		return 0
+	}
+	return max(c.level-int(t.Sub(c.lastRaise)/backoffDecay), 0)
+}
+
+// backoff returns the current backoff level.
+func (c *capacityMonitor) backoff() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.levelAt(time.Now())
+}
+
+// ProgressInterval is the least time between a job's progress writes.
+func (c *capacityMonitor) ProgressInterval() time.Duration {
+	return baseProgressInterval << c.backoff()
+}
+
+// BatchSize is how many writes to send per BatchWriteItem.
+func (c *capacityMonitor) BatchSize() int {
+	return max(batchWriteMax>>c.backoff(), 1)
+}
+
+// capacityStats is the server_info view of the monitor.
+type capacityStats struct {
+	ConsumedRCU      float64 `json:"consumed_rcu"`
+	ConsumedWCU      float64 `json:"consumed_wcu"`
+	Throttles        int64   `json:"throttles"`
+	LastThrottle     string  `json:"last_throttle,omitempty"`
+	BackoffLevel     int     `json:"backoff_level"`
+	ProgressInterval string  `json:"progress_interval"`
+	BatchSize        int     `json:"batch_size"`
+}
+
+// stats returns the totals since startup and the current backoff.
+func (c *capacityMonitor) stats() capacityStats {
+	level := c.backoff()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := capacityStats{
+		ConsumedRCU:      c.rcu,
+		ConsumedWCU:      c.wcu,
+		Throttles:        c.throttles,
+		BackoffLevel:     level,
+		ProgressInterval: (baseProgressInterval << level).String(),
+		BatchSize:        max(batchWriteMax>>level, 1),
+	}
+	if !c.lastThrottle.IsZero() {
+		s.LastThrottle = c.lastThrottle.UTC().Format(time.RFC3339)
+	}
+	return s
+}
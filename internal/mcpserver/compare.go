@@ -0,0 +1,206 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/apresai/podcaster/internal/script"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// maxDiffLines caps the changed script lines returned by compare_podcasts.
+const maxDiffLines = 40
+
+// HandleComparePodcasts reports how two podcasts differ: settings, duration,
+// cost, heuristic review scores, and a segment-level script diff. It is meant
+// for episodes generated from the same source with different options.
+func (h *Handlers) HandleComparePodcasts(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, span := tracer.Start(ctx, "tool.compare_podcasts")
+	defer span.End()
+
+	var items [2]*PodcastItem
+	for i, param := range []string{"podcast_id_a", "podcast_id_b"} {
+		item, msg, err := h.ownedPodcast(ctx, req, param)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "get podcast failed")
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get podcast: %v", err)), nil
+		}
+		if msg != "" {
+			span.SetStatus(codes.Error, msg)
+			return mcp.NewToolResultError(msg), nil
+		}
+		items[i] = item
+	}
+	a, b := items[0], items[1]
+	if a.PodcastID == b.PodcastID {
+		span.SetStatus(codes.Error, "same podcast")
+		return mcp.NewToolResultError("podcast_id_a and podcast_id_b must be different podcasts"), nil
+	}
+	span.SetAttributes(attribute.String("podcast_id_a", a.PodcastID), attribute.String("podcast_id_b", b.PodcastID))
+
+	sumA, sumB := compareSummary(a), compareSummary(b)
+	sameSource, sourceKnown := sameSource(a, b)
+	result := map[string]any{
+		"a":           sumA,
+		"b":           sumB,
+		"settings":    settingsDiff(a, b),
+		"same_source": sameSource,
+	}
+	if !sourceKnown {
+		result["same_source_note"] = "Source could not be determined for at least one podcast (created before settings were recorded)."
+	} else if !sameSource {
+		result["same_source_note"] = "These podcasts were generated from different sources; script differences reflect the content as well as the settings."
+	}
+
+	durA, durB := parseDurationSec(a.Duration), parseDurationSec(b.Duration)
+	if durA > 0 && durB > 0 {
+		result["duration_delta_sec"] = durB - durA
+	}
+	if a.EstimatedCostUSD > 0 && b.EstimatedCostUSD > 0 {
+		result["cost_delta_usd"] = math.Round((b.EstimatedCostUSD-a.EstimatedCostUSD)*10000) / 10000
+	}
+
+	scA, okA := a.script()
+	scB, okB := b.script()
+	if okA {
+		sumA["review"] = scriptReview(scA, a)
+	}
+	if okB {
+		sumB["review"] = scriptReview(scB, b)
+	}
+	if okA && okB {
+		result["script_diff"] = script.Diff(scA, scB, maxDiffLines)
+	} else {
+		result["script_diff_note"] = "Script diff unavailable: at least one podcast has no stored script."
+	}
+
+	return jsonResult(result)
+}
+
+// compareSummary is one side of a comparison.
+func compareSummary(p *PodcastItem) map[string]any {
+	m := map[string]any{
+		"podcast_id": p.PodcastID,
+		"title":      p.Title,
+		"status":     p.Status,
+		"created_at": p.CreatedAt,
+	}
+	if p.Duration != "" {
+		m["duration"] = p.Duration
+		m["duration_sec"] = parseDurationSec(p.Duration)
+	}
+	if p.FileSizeMB > 0 {
+		m["file_size_mb"] = p.FileSizeMB
+	}
+	if p.EstimatedCostUSD > 0 {
+		m["estimated_cost_usd"] = p.EstimatedCostUSD
+	}
+	if p.TTSCharCount > 0 {
+		m["tts_chars"] = p.TTSCharCount
+	}
+	return m
+}
+
+// sameSource reports whether two podcasts share an input URL or input text
+// hash. known is false when either record lacks both.
+func sameSource(a, b *PodcastItem) (same, known bool) {
+	if a.SourceURL != "" && b.SourceURL != "" {
+		return a.SourceURL == b.SourceURL, true
+	}
+	ha, hb := a.Settings["input_sha256"], b.Settings["input_sha256"]
+	if ha != "" && hb != "" {
+		return ha == hb, true
+	}
+	if (a.SourceURL != "" || ha != "") && (b.SourceURL != "" || hb != "") {
+		return false, true // one URL, one text input
+	}
+	return false, false
+}
+
+// settingsDiff lists every generation setting with the values on each side,
+// split into those that differ and those shared.
+func settingsDiff(a, b *PodcastItem) map[string]any {
+	va, vb := comparableSettings(a), comparableSettings(b)
+	var keys []string
+	for k := range va {
+		keys = append(keys, k)
+	}
+	for k := range vb {
+		if _, ok := va[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	changed := make(map[string]any)
+	same := make(map[string]string)
+	for _, k := range keys {
+		if va[k] == vb[k] {
+			same[k] = va[k]
+		} else {
+			changed[k] = map[string]string{"a": va[k], "b": vb[k]}
+		}
+	}
+	return map[string]any{"changed": changed, "same": same}
+}
+
+func comparableSettings(p *PodcastItem) map[string]string {
+	m := map[string]string{
+		"model":        p.Model,
+		"tts_provider": p.TTSProvider,
+		"format":       p.Format,
+	}
+	for k, v := range p.Settings {
+		if k != "input_sha256" {
+			m[k] = v
+		}
+	}
+	for k, v := range m {
+		if v == "" {
+			delete(m, k)
+		}
+	}
+	return m
+}
+
+// scriptReview runs the heuristic script checks against the settings the
+// podcast was generated with.
+func scriptReview(s *script.Script, p *PodcastItem) map[string]any {
+	voices, err := strconv.Atoi(p.Settings["voices"])
+	if err != nil {
+		voices = 2
+	}
+	duration := p.Settings["duration"]
+	if duration == "" {
+		duration = "standard"
+	}
+	issues := script.CheckScript(s, duration, voices)
+
+	list := make([]map[string]string, 0, len(issues))
+	errs, warns := 0, 0
+	for _, issue := range issues {
+		if issue.Severity == "error" {
+			errs++
+		} else {
+			warns++
+		}
+		list = append(list, map[string]string{
+			"category": issue.Category,
+			"severity": issue.Severity,
+			"message":  issue.Message,
+		})
+	}
+	return map[string]any{
+		"score":    script.ReviewScore(issues),
+		"errors":   errs,
+		"warnings": warns,
+		"issues":   list,
+		"stats":    script.Stats(s),
+	}
+}
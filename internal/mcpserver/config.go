@@ -0,0 +1,381 @@
+package mcpserver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/feature"
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/apresai/podcaster/internal/tts"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds server configuration. LoadConfig builds it from the
+// defaults, then an optional YAML file (keys as in the yaml tags), then the
+// environment variables named on each field, which override the file.
+type Config struct {
+	Port         int    `yaml:"port"`          // MCP_PORT
+	TableName    string `yaml:"table_name"`    // DYNAMODB_TABLE
+	S3Bucket     string `yaml:"s3_bucket"`     // S3_BUCKET (required)
+	CDNBaseURL   string `yaml:"cdn_base_url"`  // CDN_BASE_URL
+	AWSRegion    string `yaml:"aws_region"`    // AWS_REGION
+	MaxTasks     int    `yaml:"max_tasks"`     // MCP_MAX_TASKS
+	SecretPrefix string `yaml:"secret_prefix"` // SECRET_PREFIX, e.g. "/podcaster/mcp/"
+
+	// S3PathStyle addresses the bucket in the path rather than the host
+	// name, as MinIO and other S3 emulators need. S3_PATH_STYLE. Endpoints
+	// themselves come from the SDK's AWS_ENDPOINT_URL_S3 and
+	// AWS_ENDPOINT_URL_DYNAMODB.
+	S3PathStyle bool `yaml:"s3_path_style"`
+
+	// CreateTable creates the table at startup if it doesn't exist, for a
+	// fresh local DynamoDB (see deploy/selfhost). DYNAMODB_CREATE_TABLE.
+	CreateTable bool `yaml:"create_table"`
+
+	// ArchiveAfterDays tags episode audio for the bucket's archival
+	// lifecycle rule, which must move tagged objects to Glacier after this
+	// many days (archive.go). 0 disables archival. MCP_ARCHIVE_AFTER_DAYS.
+	ArchiveAfterDays int `yaml:"archive_after_days"`
+
+	// SessionStore selects where MCP session IDs live: "" (stateless, AgentCore
+	// only) or "dynamodb" (persisted with SessionTTL so sessions survive
+	// runtime recycling). MCP_SESSION_STORE, MCP_SESSION_TTL.
+	SessionStore string        `yaml:"session_store"`
+	SessionTTL   time.Duration `yaml:"session_ttl"`
+
+	// TrialDailyLimit is how many trial episodes one IP may generate per
+	// day without an API key (0 = trial mode disabled). TRIAL_DAILY_LIMIT.
+	TrialDailyLimit int `yaml:"trial_daily_limit"`
+
+	// WarmProviders are the TTS providers whose clients and tokens are set
+	// up at startup (see warmUp). Empty disables warm-up.
+	// MCP_WARM_PROVIDERS (comma-separated; "none" for empty).
+	WarmProviders []string `yaml:"warm_providers"`
+
+	// Timeouts bounds each stage of a generation, including the S3 upload
+	// (stage_timeouts or PODCASTER_STAGE_TIMEOUTS, e.g.
+	// "script=15m,upload=10m"). Zero fields use pipeline.DefaultTimeouts.
+	Timeouts pipeline.Timeouts `yaml:"-"`
+
+	// Budgets are each stage's latency target (stage_budgets or
+	// PODCASTER_STAGE_BUDGETS, e.g. "script=5m,script:haiku=3m,tts=10m").
+	// A stage past its budget is logged and counted, not stopped. None by
+	// default.
+	Budgets pipeline.Budgets `yaml:"-"`
+
+	// ShutdownTimeout is how long SIGTERM may take: running jobs get until
+	// shutdownCheckpointTime before it to finish, then are cancelled and
+	// record their partial results (Server.Shutdown). AgentCore kills the
+	// container about 10s after SIGTERM. MCP_SHUTDOWN_TIMEOUT.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// Profile runs every generation with pipeline profiling
+	// (PODCASTER_PROFILE=true), logging each stage's time and memory to
+	// the job log for get_podcast_logs.
+	Profile bool `yaml:"profile"`
+
+	// Features are the feature flag rollouts, percent of jobs by flag
+	// (internal/feature), e.g. {parallel-tts: 10}. Entries in the table's
+	// CONFIG/FEATURES item override them without a redeploy (see
+	// podcaster-admin features). PODCASTER_FEATURES ("parallel-tts=10").
+	Features feature.Rollouts `yaml:"features"`
+
+	// RequireAuth rejects unauthenticated calls to tools that act on a
+	// user's podcasts (trials aside). MCP_REQUIRE_AUTH; on by default, so a
+	// deployment missing its environment fails closed. Local and self-hosted
+	// setups turn it off explicitly.
+	RequireAuth bool `yaml:"require_auth"`
+
+	// TrustProxy accepts the identity the MCP proxy injects into tool
+	// arguments (_user_id, _key_id, _trial_ip_hash). MCP_TRUST_PROXY; on by
+	// default for the hosted runtime, which only the proxy can reach. A
+	// server anyone can call directly must turn it off, or any caller could
+	// claim to be any user.
+	TrustProxy bool `yaml:"trust_proxy"`
+
+	// APIKeys are provider API keys by provider (anthropic, gemini,
+	// elevenlabs, vertex-express, cartesia, hume, deepgram). Each is used
+	// only if its environment variable (apiKeyEnv) is unset, and before
+	// Secrets Manager.
+	APIKeys map[string]string `yaml:"api_keys"`
+
+	// File is the YAML file the config was read from, if any.
+	File string `yaml:"-"`
+}
+
+// apiKeyEnv maps Config.APIKeys' providers to the environment variables the
+// script and TTS providers read, which are also the Secrets Manager names
+// under SecretPrefix.
+var apiKeyEnv = map[string]string{
+	"anthropic":      "ANTHROPIC_API_KEY",
+	"gemini":         "GEMINI_API_KEY",
+	"elevenlabs":     "ELEVENLABS_API_KEY",
+	"vertex-express": "VERTEX_AI_API_KEY",
+	"cartesia":       "CARTESIA_API_KEY",
+	"hume":           "HUME_API_KEY",
+	"deepgram":       "DEEPGRAM_API_KEY",
+}
+
+// DefaultConfig returns the configuration with no file and no environment.
+func DefaultConfig() Config {
+	return Config{
+		Port:            8000,
+		TableName:       "podcaster-prod",
+		CDNBaseURL:      "https://podcasts.apresai.dev",
+		AWSRegion:       "us-east-1",
+		MaxTasks:        5,
+		SecretPrefix:    "/podcaster/mcp/",
+		SessionTTL:      DefaultSessionTTL,
+		ShutdownTimeout: 9 * time.Second,
+		WarmProviders:   []string{"gemini-vertex", "google", "polly"},
+		RequireAuth:     true,
+		TrustProxy:      true,
+	}
+}
+
+// LoadConfig returns DefaultConfig overlaid with the YAML file at path
+// (skipped if path is empty) and then the environment, and validates the
+// result. Unknown file keys and unparseable values are errors.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return cfg, err
+		}
+	}
+	if err := cfg.readEnv(); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	file := struct {
+		Config        `yaml:",inline"`
+		StageTimeouts string `yaml:"stage_timeouts"`
+		StageBudgets  string `yaml:"stage_budgets"`
+	}{Config: *c}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		var te *yaml.TypeError
+		if errors.As(err, &te) {
+			// "line 3: field foo not found in type struct {...}"
+			for i, e := range te.Errors {
+				if j := strings.Index(e, " not found in type"); j >= 0 {
+					te.Errors[i] = strings.Replace(e[:j], "field ", "unknown key ", 1)
+				}
+			}
+		}
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	*c = file.Config
+	if file.StageTimeouts != "" {
+		t, err := pipeline.ParseTimeouts(file.StageTimeouts)
+		if err != nil {
+			return fmt.Errorf("config %s: stage_timeouts: %w", path, err)
+		}
+		c.Timeouts = t
+	}
+	if file.StageBudgets != "" {
+		b, err := pipeline.ParseBudgets(file.StageBudgets)
+		if err != nil {
+			return fmt.Errorf("config %s: stage_budgets: %w", path, err)
+		}
+		c.Budgets = b
+	}
+	c.File = path
+	return nil
+}
+
+// readEnv applies the environment variables that are set.
+func (c *Config) readEnv() error {
+	var errs []error
+	str := func(key string, dst *string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+	parse := func(key string, fn func(string) error) {
+		if v := os.Getenv(key); v != "" {
+			if err := fn(v); err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q: %w", key, v, err))
+			}
+		}
+	}
+	integer := func(key string, dst *int) {
+		parse(key, func(v string) (err error) { *dst, err = strconv.Atoi(v); return err })
+	}
+	boolean := func(key string, dst *bool) {
+		parse(key, func(v string) (err error) { *dst, err = strconv.ParseBool(v); return err })
+	}
+
+	integer("MCP_PORT", &c.Port)
+	str("DYNAMODB_TABLE", &c.TableName)
+	str("S3_BUCKET", &c.S3Bucket)
+	str("CDN_BASE_URL", &c.CDNBaseURL)
+	str("AWS_REGION", &c.AWSRegion)
+	integer("MCP_MAX_TASKS", &c.MaxTasks)
+	str("SECRET_PREFIX", &c.SecretPrefix)
+	boolean("S3_PATH_STYLE", &c.S3PathStyle)
+	boolean("DYNAMODB_CREATE_TABLE", &c.CreateTable)
+	integer("MCP_ARCHIVE_AFTER_DAYS", &c.ArchiveAfterDays)
+	str("MCP_SESSION_STORE", &c.SessionStore)
+	parse("MCP_SESSION_TTL", func(v string) (err error) { c.SessionTTL, err = time.ParseDuration(v); return err })
+	integer("TRIAL_DAILY_LIMIT", &c.TrialDailyLimit)
+	if v, ok := os.LookupEnv("MCP_WARM_PROVIDERS"); ok {
+		c.WarmProviders = nil
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" && p != "none" {
+				c.WarmProviders = append(c.WarmProviders, p)
+			}
+		}
+	}
+	parse("PODCASTER_STAGE_TIMEOUTS", func(v string) (err error) { c.Timeouts, err = pipeline.ParseTimeouts(v); return err })
+	parse("PODCASTER_STAGE_BUDGETS", func(v string) (err error) { c.Budgets, err = pipeline.ParseBudgets(v); return err })
+	parse("MCP_SHUTDOWN_TIMEOUT", func(v string) (err error) { c.ShutdownTimeout, err = time.ParseDuration(v); return err })
+	boolean("PODCASTER_PROFILE", &c.Profile)
+	parse("PODCASTER_FEATURES", func(v string) (err error) { c.Features, err = feature.ParseRollouts(v); return err })
+	boolean("MCP_REQUIRE_AUTH", &c.RequireAuth)
+	boolean("MCP_TRUST_PROXY", &c.TrustProxy)
+	return errors.Join(errs...)
+}
+
+// Validate reports every problem with the configuration.
+func (c Config) Validate() error {
+	var errs []error
+	bad := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+	if c.Port < 1 || c.Port > 65535 {
+		bad("port %d: must be 1-65535", c.Port)
+	}
+	if c.TableName == "" {
+		bad("table_name (DYNAMODB_TABLE) is required")
+	}
+	if c.S3Bucket == "" {
+		bad("s3_bucket (S3_BUCKET) is required")
+	}
+	if u, err := url.Parse(c.CDNBaseURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		bad("cdn_base_url %q: must be an http(s) URL", c.CDNBaseURL)
+	}
+	if c.AWSRegion == "" {
+		bad("aws_region (AWS_REGION) is required")
+	}
+	if c.MaxTasks < 1 {
+		bad("max_tasks %d: must be at least 1", c.MaxTasks)
+	}
+	if c.ArchiveAfterDays < 0 {
+		bad("archive_after_days %d: must be 0 (off) or more", c.ArchiveAfterDays)
+	}
+	switch c.SessionStore {
+	case "", "dynamodb":
+	default:
+		bad("session_store %q: must be empty or dynamodb", c.SessionStore)
+	}
+	if c.SessionTTL <= 0 {
+		bad("session_ttl %s: must be positive", c.SessionTTL)
+	}
+	if c.TrialDailyLimit < 0 {
+		bad("trial_daily_limit %d: must be 0 (off) or more", c.TrialDailyLimit)
+	}
+	if c.ShutdownTimeout <= 0 {
+		bad("shutdown_timeout %s: must be positive", c.ShutdownTimeout)
+	}
+	for _, p := range c.WarmProviders {
+		if !slices.Contains(tts.ProviderNames, p) {
+			bad("warm_providers: unknown TTS provider %q", p)
+		}
+	}
+	if err := c.Features.Validate(); err != nil {
+		bad("features: %w", err)
+	}
+	for provider := range c.APIKeys {
+		if _, ok := apiKeyEnv[provider]; !ok {
+			bad("api_keys: unknown provider %q", provider)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyAPIKeys sets the environment variable of each configured API key
+// that the environment doesn't already have.
+func (c Config) applyAPIKeys() {
+	for provider, key := range c.APIKeys {
+		if env := apiKeyEnv[provider]; key != "" && os.Getenv(env) == "" {
+			os.Setenv(env, key)
+		}
+	}
+}
+
+// LogValue implements slog.LogValuer: the effective configuration, with
+// API keys reduced to where each comes from.
+func (c Config) LogValue() slog.Value {
+	warm := strings.Join(c.WarmProviders, ",")
+	if warm == "" {
+		warm = "none"
+	}
+	session := c.SessionStore
+	if session == "" {
+		session = "stateless"
+	}
+	file := c.File
+	if file == "" {
+		file = "none"
+	}
+	attrs := []slog.Attr{
+		slog.String("file", file),
+		slog.Int("port", c.Port),
+		slog.String("table_name", c.TableName),
+		slog.String("s3_bucket", c.S3Bucket),
+		slog.String("cdn_base_url", c.CDNBaseURL),
+		slog.String("aws_region", c.AWSRegion),
+		slog.Int("max_tasks", c.MaxTasks),
+		slog.String("secret_prefix", c.SecretPrefix),
+		slog.Bool("s3_path_style", c.S3PathStyle),
+		slog.Bool("create_table", c.CreateTable),
+		slog.Int("archive_after_days", c.ArchiveAfterDays),
+		slog.String("session_store", session),
+		slog.String("session_ttl", c.SessionTTL.String()),
+		slog.Int("trial_daily_limit", c.TrialDailyLimit),
+		slog.String("warm_providers", warm),
+		slog.String("stage_timeouts", c.Timeouts.WithDefaults().String()),
+		slog.String("stage_budgets", c.Budgets.String()),
+		slog.String("shutdown_timeout", c.ShutdownTimeout.String()),
+		slog.Bool("profile", c.Profile),
+		slog.String("features", c.Features.String()),
+		slog.Bool("require_auth", c.RequireAuth),
+		slog.Bool("trust_proxy", c.TrustProxy),
+	}
+	providers := make([]string, 0, len(apiKeyEnv))
+	for p := range apiKeyEnv {
+		providers = append(providers, p)
+	}
+	slices.Sort(providers)
+	var keys []slog.Attr
+	for _, p := range providers {
+		source := "unset"
+		switch {
+		case os.Getenv(apiKeyEnv[p]) != "":
+			source = "env"
+		case c.APIKeys[p] != "":
+			source = "file"
+		case c.SecretPrefix != "":
+			source = "secrets manager (if present)"
+		}
+		keys = append(keys, slog.String(p, source))
+	}
+	attrs = append(attrs, slog.Attr{Key: "api_keys", Value: slog.GroupValue(keys...)})
+	return slog.GroupValue(attrs...)
+}
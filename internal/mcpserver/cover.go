@@ -0,0 +1,33 @@
+package mcpserver
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/errkind"
+)
+
+// Cover art. generate_podcast takes it as an https URL to a JPEG or PNG;
+// the task downloads it into its work directory (keeping the extension,
+// which assembly.ValidateCover checks) and passes the local copy to
+// pipeline.Options.Cover.
+
+// maxCoverBytes bounds downloaded cover art. Apple's 3000x3000 maximum
+// fits comfortably.
+const maxCoverBytes = 10 << 20
+
+// validateCoverURL checks that the cover param is an https URL to a .jpg
+// or .png image, and returns its extension.
+func validateCoverURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return "", errkind.New(errkind.UserInput, "cover must be an https URL to a JPEG or PNG image")
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	switch ext {
+	case ".jpg", ".jpeg", ".png":
+		return ext, nil
+	}
+	return "", errkind.New(errkind.UserInput, "cover must be a .jpg or .png image URL")
+}
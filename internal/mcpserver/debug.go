@@ -0,0 +1,151 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
+	"github.com/apresai/podcaster/internal/ingest"
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Debug bundles (pipeline/replay.go). When a job fails, the task keeps its
+// manifest, text input, ingested content, and script under debug/<id>/
+// (not served by the CDN; expired by a lifecycle rule), for `podcaster
+// debug replay <id>` to re-run the failing stage locally.
+
+// UploadDebugBundle uploads files (bundle name to contents) and then the
+// manifest, whose presence means the rest is complete.
+func (s *Storage) UploadDebugBundle(ctx context.Context, b pipeline.DebugBundle, files map[string][]byte) error {
+	prefix := pipeline.DebugPrefix(b.PodcastID)
+	for _, name := range b.Files {
+		if err := s.putBytes(ctx, prefix+name, files[name], contentTypeOf(name)); err != nil {
+			return err
+		}
+	}
+	manifest, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal debug manifest: %w", err)
+	}
+	return s.putBytes(ctx, prefix+pipeline.DebugManifestFile, manifest, "application/json")
+}
+
+// debugProfileDir is where a profiled job's pprof files and summary go,
+// under its debug/<id>/ prefix.
+const debugProfileDir = "profile/"
+
+// UploadProfile uploads the files of a job's profile directory
+// (Options.ProfileDir) to debug/<id>/profile/.
+func (s *Storage) UploadProfile(ctx context.Context, podcastID, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read profile directory: %w", err)
+	}
+	prefix := pipeline.DebugPrefix(podcastID) + debugProfileDir
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("read profile: %w", err)
+		}
+		contentType := "application/octet-stream"
+		if filepath.Ext(e.Name()) == ".json" {
+			contentType = "application/json"
+		}
+		if err := s.putBytes(ctx, prefix+e.Name(), data, contentType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putBytes uploads data to key.
+func (s *Storage) putBytes(ctx context.Context, key string, data []byte, contentType string) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &s.bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("upload %s to s3: %w", key, err)
+	}
+	return nil
+}
+
+func contentTypeOf(name string) string {
+	if name == pipeline.DebugScriptFile {
+		return "application/json"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// debugCapture collects what a job's debug bundle needs as the pipeline
+// runs (Options.OnIngest and OnStageTimings).
+type debugCapture struct {
+	content *ingest.Content
+	timings []pipeline.StageTiming
+}
+
+// saveDebugBundle keeps a failed job's debug bundle. Best effort, like
+// savePartial: failures are logged, and the job is failed by the caller
+// either way.
+func (tm *TaskManager) saveDebugBundle(ctx context.Context, id string, req GenerateRequest, command, output string, capture *debugCapture, err error) {
+	b := pipeline.DebugBundle{
+		PodcastID: id,
+		FailedAt:  time.Now().UTC(),
+		Stage:     pipeline.FailedStage(err, capture.timings),
+		Error:     redactSecrets(err.Error()),
+		ErrorKind: string(errkind.Of(err)),
+		Command:   command,
+		Input:     req.InputURL,
+		Stages:    capture.timings,
+	}
+	files := make(map[string][]byte)
+	if req.InputText != "" {
+		b.Input = pipeline.DebugInputFile
+		files[pipeline.DebugInputFile] = []byte(req.InputText)
+	}
+	if capture.content != nil {
+		files[pipeline.DebugContentFile] = []byte(capture.content.Text)
+	}
+	if data, rerr := os.ReadFile(pipeline.ScriptPath(output)); rerr == nil {
+		files[pipeline.DebugScriptFile] = data
+	}
+	for _, name := range []string{pipeline.DebugInputFile, pipeline.DebugContentFile, pipeline.DebugScriptFile} {
+		if _, ok := files[name]; ok {
+			b.Files = append(b.Files, name)
+		}
+	}
+
+	log := tm.log.With("podcast_id", id)
+	uploadCtx, cancel := context.WithTimeout(ctx, tm.timeouts.WithDefaults().Upload)
+	defer cancel()
+	if uerr := tm.storage.UploadDebugBundle(uploadCtx, b, files); uerr != nil {
+		log.WarnContext(ctx, "Upload debug bundle failed", "error", uerr)
+		return
+	}
+	log.InfoContext(ctx, "Debug bundle saved", "stage", b.Stage, "files", b.Files)
+}
+
+// saveProfile uploads a profiled job's profile before its work directory
+// is removed. Best effort, like the debug bundle.
+func (tm *TaskManager) saveProfile(ctx context.Context, id, dir string) {
+	log := tm.log.With("podcast_id", id)
+	uploadCtx, cancel := context.WithTimeout(ctx, tm.timeouts.WithDefaults().Upload)
+	defer cancel()
+	if err := tm.storage.UploadProfile(uploadCtx, id, dir); err != nil {
+		log.WarnContext(ctx, "Upload profile failed", "error", err)
+		return
+	}
+	log.InfoContext(ctx, "Profile saved", "prefix", pipeline.DebugPrefix(id)+debugProfileDir)
+}
@@ -0,0 +1,80 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apresai/podcaster/internal/feature"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// featureRefresh is how long the table's rollout overrides are cached, and
+// so how long a change made with podcaster-admin features takes to apply.
+const featureRefresh = time.Minute
+
+// featureFlags decides each job's feature flags from the configured
+// rollouts (Config.Features) with the table's overrides on top.
+type featureFlags struct {
+	base  feature.Rollouts
+	store *Store
+	log   *slog.Logger
+
+	mu      sync.Mutex
+	over    feature.Rollouts // last good read of the table
+	fetched time.Time
+}
+
+// forJob returns the flags on for job id. If the table can't be read, the
+// last overrides read are kept.
+func (f *featureFlags) forJob(ctx context.Context, id string) feature.Set {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if time.Since(f.fetched) >= featureRefresh {
+		f.fetched = time.Now()
+		over, err := f.store.GetFeatureRollouts(ctx)
+		if err != nil {
+			f.log.WarnContext(ctx, "Read feature flag overrides failed; keeping the last ones", "error", err)
+		} else {
+			f.over = over
+		}
+	}
+	return f.base.Merge(f.over).Enabled(id)
+}
+
+// GetFeatureRollouts reads the feature flag overrides (CONFIG/FEATURES,
+// percent by flag in its rollouts map). Unknown flags and bad percentages
+// are dropped, so a stale entry can't break generation.
+func (s *Store) GetFeatureRollouts(ctx context.Context) (feature.Rollouts, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "CONFIG"},
+			"SK": &types.AttributeValueMemberS{Value: "FEATURES"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get feature rollouts: %w", err)
+	}
+	m, _ := result.Item["rollouts"].(*types.AttributeValueMemberM)
+	if m == nil {
+		return nil, nil
+	}
+	r := feature.Rollouts{}
+	for name, v := range m.Value {
+		n, ok := v.(*types.AttributeValueMemberN)
+		if !ok {
+			continue
+		}
+		pct, err := strconv.Atoi(n.Value)
+		if err != nil || (feature.Rollouts{name: pct}).Validate() != nil {
+			continue
+		}
+		r[name] = pct
+	}
+	return r, nil
+}
@@ -0,0 +1,215 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Live job logs. Each task's pipeline log (the same lines a local
+// --verbose run prints) is kept in memory on the server running it, so
+// get_podcast_logs can tail a running job. Lines are numbered from 0; a
+// caller passes the next_cursor it was given to read only newer lines.
+const (
+	// maxJobLogLines bounds a job's buffer; older lines are dropped and
+	// reported as skipped.
+	maxJobLogLines = 5000
+
+	// jobLogRetention is how long a finished job's log stays readable.
+	jobLogRetention = 30 * time.Minute
+
+	defaultLogPage = 200
+	maxLogPage     = 1000
+)
+
+var (
+	// logSecretParam matches a credential passed as a URL query value.
+	logSecretParam = regexp.MustCompile(`(?i)([?&](?:key|api_key|apikey|access_token|token)=)[^&\s"']+`)
+
+	// logURL matches a URL in a log line; provider URLs are cut to their
+	// host, since paths can carry project IDs and signed parameters.
+	logURL = regexp.MustCompile(`https?://[^\s"'<>]+`)
+)
+
+// providerHosts are the API hosts whose URLs are cut to the host in a job
+// log. Regional Vertex hosts ("us-central1-aiplatform...") match by suffix.
+var providerHosts = []string{
+	"googleapis.com",
+	"api.elevenlabs.io",
+	"api.cartesia.ai",
+	"api.hume.ai",
+	"api.deepgram.com",
+	"api.openai.com",
+	"api.anthropic.com",
+}
+
+// redactSecrets removes credentials and provider URLs from text that leaves
+// the server: a log line before get_podcast_logs can return it, or a failed
+// job's error in its debug bundle.
+func redactSecrets(text string) string {
+	text = logSecretParam.ReplaceAllString(text, "${1}REDACTED")
+	return logURL.ReplaceAllStringFunc(text, func(u string) string {
+		scheme, rest, _ := strings.Cut(u, "://")
+		host, _, _ := strings.Cut(rest, "/")
+		host, _, _ = strings.Cut(host, "?")
+		for _, h := range providerHosts {
+			if host == h || strings.HasSuffix(host, "."+h) || strings.HasSuffix(host, "-"+h) {
+				return scheme + "://" + host + "/…"
+			}
+		}
+		return u
+	})
+}
+
+// jobLog is an io.Writer collecting a task's log lines.
+type jobLog struct {
+	mu       sync.Mutex
+	lines    []string
+	first    int    // number of lines[0]
+	partial  []byte // unterminated tail of the last write
+	done     bool
+	finished time.Time
+}
+
+// Write appends the complete lines in p, holding back a trailing partial
+// line until the rest of it arrives.
+func (l *jobLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.partial = append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(l.partial, '\n')
+		if i < 0 {
+			break
+		}
+		l.append(string(l.partial[:i]))
+		l.partial = l.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// append adds a line, redacted, dropping the oldest past maxJobLogLines.
+// Callers hold l.mu.
+func (l *jobLog) append(line string) {
+	l.lines = append(l.lines, redactSecrets(line))
+	if over := len(l.lines) - maxJobLogLines; over > 0 {
+		l.lines = append(l.lines[:0:0], l.lines[over:]...)
+		l.first += over
+	}
+}
+
+// finish flushes any partial line and marks the log complete.
+func (l *jobLog) finish() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.partial) > 0 {
+		l.append(string(l.partial))
+		l.partial = nil
+	}
+	l.done = true
+	l.finished = time.Now()
+}
+
+// read returns up to limit lines from cursor on, the cursor to pass next,
+// how many lines before the buffer's start were skipped, and whether the
+// job has finished and every line has been read.
+func (l *jobLog) read(cursor, limit int) (lines []string, next, skipped int, done bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if cursor < l.first {
+		skipped = l.first - cursor
+		cursor = l.first
+	}
+	start := min(cursor-l.first, len(l.lines))
+	end := min(start+limit, len(l.lines))
+	lines = append([]string(nil), l.lines[start:end]...)
+	next = l.first + end
+	return lines, next, skipped, l.done && end == len(l.lines)
+}
+
+// expired reports whether a finished log is past jobLogRetention.
+func (l *jobLog) expired(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.done && now.Sub(l.finished) > jobLogRetention
+}
+
+// newJobLog registers a log for task id and drops expired ones.
+func (tm *TaskManager) newJobLog(id string) *jobLog {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	now := time.Now()
+	for other, l := range tm.logs {
+		if l.expired(now) {
+			delete(tm.logs, other)
+		}
+	}
+	l := &jobLog{}
+	tm.logs[id] = l
+	return l
+}
+
+// jobLog returns task id's log, or nil if this server doesn't hold it.
+func (tm *TaskManager) jobLog(id string) *jobLog {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	l := tm.logs[id]
+	if l != nil && l.expired(time.Now()) {
+		delete(tm.logs, id)
+		return nil
+	}
+	return l
+}
+
+// HandleGetPodcastLogs returns a job's pipeline log lines after a cursor.
+func (h *Handlers) HandleGetPodcastLogs(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, span := tracer.Start(ctx, "tool.get_podcast_logs")
+	defer span.End()
+
+	item, msg, err := h.ownedPodcast(ctx, req, "podcast_id")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "get podcast failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get podcast: %v", err)), nil
+	}
+	if msg != "" {
+		span.SetStatus(codes.Error, "not allowed")
+		return mcp.NewToolResultError(msg), nil
+	}
+	span.SetAttributes(attribute.String("podcast_id", item.PodcastID))
+
+	l := h.tasks.jobLog(item.PodcastID)
+	if l == nil {
+		span.SetStatus(codes.Error, "log unavailable")
+		return mcp.NewToolResultError(fmt.Sprintf("logs for podcast %s are not available: they are kept on the server running the job, for %s after it finishes. Use get_podcast for its status.",
+			item.PodcastID, jobLogRetention)), nil
+	}
+
+	cursor := max(parseIntParam(req, "cursor", 0), 0)
+	limit := parseIntParam(req, "limit", defaultLogPage)
+	if limit <= 0 || limit > maxLogPage {
+		limit = maxLogPage
+	}
+	lines, next, skipped, done := l.read(cursor, limit)
+	span.SetAttributes(attribute.Int("lines", len(lines)))
+
+	result := map[string]any{
+		"podcast_id":  item.PodcastID,
+		"status":      item.Status,
+		"lines":       lines,
+		"next_cursor": next,
+		"done":        done,
+	}
+	if skipped > 0 {
+		result["skipped_lines"] = skipped
+	}
+	return jsonResult(result)
+}
@@ -0,0 +1,208 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Partial TTS results. When a job's per-segment synthesis fails partway
+// (pipeline.PartialTTSError), the finished segments, the script, and the
+// audio plan are kept under partial/<id>/ (not served by the CDN; expired
+// by a lifecycle rule) and the podcast records which segments are missing.
+// generate_podcast with resume_from=<id> downloads them and synthesizes
+// only the rest.
+
+// partialScriptFile is the script's name under the partial prefix.
+const partialScriptFile = "script.json"
+
+// partialPrefix is the S3 prefix holding a podcast's partial TTS results.
+func partialPrefix(podcastID string) string {
+	return "partial/" + podcastID + "/"
+}
+
+// UploadPartial uploads the audio plan, its script, and its finished
+// segments from a failed run's directory, and returns the prefix they were
+// stored under.
+func (s *Storage) UploadPartial(ctx context.Context, podcastID string, partial *pipeline.PartialTTSError) (string, error) {
+	prefix := partialPrefix(podcastID)
+	if err := s.putFile(ctx, prefix+partialScriptFile, partial.Plan.Script, "application/json"); err != nil {
+		return "", err
+	}
+	for _, seg := range partial.Plan.Segments {
+		if err := s.putFile(ctx, prefix+seg.File, filepath.Join(partial.Dir, seg.File), "audio/mpeg"); err != nil {
+			return "", err
+		}
+	}
+	// The plan goes last: its presence means the rest is complete.
+	if err := s.putFile(ctx, prefix+pipeline.PlanFile, filepath.Join(partial.Dir, pipeline.PlanFile), "application/json"); err != nil {
+		return "", err
+	}
+	return prefix, nil
+}
+
+// DownloadPartial downloads the partial results under prefix into dir, ready
+// for pipeline.Options.ResumeTTS, and returns the path of their script.
+func (s *Storage) DownloadPartial(ctx context.Context, prefix, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create resume dir: %w", err)
+	}
+	if err := s.getFile(ctx, prefix+pipeline.PlanFile, filepath.Join(dir, pipeline.PlanFile)); err != nil {
+		return "", err
+	}
+	plan, err := pipeline.LoadPlan(dir)
+	if err != nil {
+		return "", err
+	}
+	scriptPath := filepath.Join(dir, partialScriptFile)
+	if err := s.getFile(ctx, prefix+partialScriptFile, scriptPath); err != nil {
+		return "", err
+	}
+	for _, seg := range plan.Segments {
+		if err := s.getFile(ctx, prefix+seg.File, filepath.Join(dir, filepath.Base(seg.File))); err != nil {
+			return "", err
+		}
+	}
+	// The plan recorded the failed run's script path; point it here.
+	plan.Script = scriptPath
+	if err := pipeline.WritePlan(dir, plan); err != nil {
+		return "", err
+	}
+	return scriptPath, nil
+}
+
+// putFile uploads a local file to key.
+func (s *Storage) putFile(ctx context.Context, key, path, contentType string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", filepath.Base(path), err)
+	}
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        &s.bucket,
+		Key:           &key,
+		Body:          f,
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(info.Size()),
+	})
+	if err != nil {
+		return fmt.Errorf("upload %s to s3: %w", key, err)
+	}
+	return nil
+}
+
+// getFile downloads key to a local file.
+func (s *Storage) getFile(ctx context.Context, key, path string) error {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return fmt.Errorf("download %s from s3: %w", key, err)
+	}
+	defer out.Body.Close()
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Base(path), err)
+	}
+	if _, err := io.Copy(f, out.Body); err != nil {
+		f.Close()
+		return fmt.Errorf("download %s from s3: %w", key, err)
+	}
+	return f.Close()
+}
+
+// SavePartial records how far a failed job's TTS got and where its partial
+// results are stored (empty if they couldn't be uploaded).
+func (s *Store) SavePartial(ctx context.Context, id string, plan pipeline.AudioPlan, prefix string) error {
+	missing, err := attributevalue.Marshal(plan.Missing)
+	if err != nil {
+		return fmt.Errorf("marshal missing segments: %w", err)
+	}
+	expr := "SET segmentsDone = :done, segmentsTotal = :total, missingSegments = :missing"
+	values := map[string]types.AttributeValue{
+		":done":    &types.AttributeValueMemberN{Value: strconv.Itoa(len(plan.Segments))},
+		":total":   &types.AttributeValueMemberN{Value: strconv.Itoa(plan.Total)},
+		":missing": missing,
+	}
+	if prefix != "" {
+		expr += ", partialPrefix = :prefix"
+		values[":prefix"] = &types.AttributeValueMemberS{Value: prefix}
+	}
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "PODCAST#" + id},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		UpdateExpression:          aws.String(expr),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		return fmt.Errorf("save partial: %w", err)
+	}
+	return nil
+}
+
+// ClearPartial removes a podcast's partial TTS record, once its partial
+// results are deleted, so it no longer offers resume_from. A podcast that
+// no longer exists is left alone rather than recreated.
+func (s *Store) ClearPartial(ctx context.Context, id string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "PODCAST#" + id},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		UpdateExpression:    aws.String("REMOVE segmentsDone, segmentsTotal, missingSegments, partialPrefix"),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return nil
+		}
+		return fmt.Errorf("clear partial: %w", err)
+	}
+	return nil
+}
+
+// savePartial keeps a failed job's partial TTS results for resume_from, if
+// err carries any, and reports whether they can be resumed. Best effort:
+// failures are logged, and the job is failed by the caller either way.
+func (tm *TaskManager) savePartial(ctx context.Context, id string, err error) bool {
+	var partial *pipeline.PartialTTSError
+	if !errors.As(err, &partial) {
+		return false
+	}
+	log := tm.log.With("podcast_id", id)
+	uploadCtx, cancel := context.WithTimeout(ctx, tm.timeouts.WithDefaults().Upload)
+	defer cancel()
+	prefix, uerr := tm.storage.UploadPartial(uploadCtx, id, partial)
+	if uerr != nil {
+		log.WarnContext(ctx, "Upload partial results failed", "error", uerr)
+		prefix = ""
+	}
+	if serr := tm.store.SavePartial(ctx, id, partial.Plan, prefix); serr != nil {
+		log.WarnContext(ctx, "Save partial results failed", "error", serr)
+		return false
+	}
+	log.InfoContext(ctx, "Partial results saved", "segments_done", len(partial.Plan.Segments),
+		"segments_total", partial.Plan.Total, "resumable", prefix != "")
+	return prefix != ""
+}
@@ -0,0 +1,71 @@
+package mcpserver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/errkind"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Preset is a named bundle of generate_podcast arguments, for callers that
+// only send a source and a name: the proxy's inbound webhook (Zapier, Make)
+// and MCP clients passing preset=. Arguments given explicitly win.
+type Preset struct {
+	Description string
+	Args        map[string]any
+}
+
+// presets are the built-in presets, listed by list_options.
+var presets = map[string]Preset{
+	"quick-summary": {
+		Description: "Short casual two-host conversation (~3-4 min)",
+		Args:        map[string]any{"format": "conversation", "duration": "short", "tone": "casual"},
+	},
+	"daily-brief": {
+		Description: "Short single-story news briefing (~3-4 min)",
+		Args:        map[string]any{"format": "news", "duration": "short", "tone": "casual", "style": "serious"},
+	},
+	"explainer": {
+		Description: "Educational explainer building up from the basics (~8-10 min)",
+		Args:        map[string]any{"format": "explainer", "duration": "standard", "tone": "educational"},
+	},
+	"deep-dive": {
+		Description: "Long investigative deep dive (~15 min)",
+		Args:        map[string]any{"format": "deep-dive", "duration": "long", "tone": "technical"},
+	},
+	"debate": {
+		Description: "Point-counterpoint debate (~8-10 min)",
+		Args:        map[string]any{"format": "debate", "duration": "standard", "style": "debate"},
+	},
+}
+
+// presetNames returns the preset names, sorted.
+func presetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyPreset fills req's missing arguments from the named preset.
+func applyPreset(req *mcp.CallToolRequest, name string) error {
+	p, ok := presets[name]
+	if !ok {
+		return errkind.New(errkind.UserInput, fmt.Sprintf("unknown preset %q: must be one of %s", name, strings.Join(presetNames(), ", ")))
+	}
+	args := req.GetArguments()
+	if args == nil {
+		args = make(map[string]any)
+		req.Params.Arguments = args
+	}
+	for k, v := range p.Args {
+		if _, set := args[k]; !set {
+			args[k] = v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/apresai/podcaster/internal/tts"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// recommendProviders are the catalogs recommend_voices considers by default.
+// vertex-express and gemini-vertex are left out: they share Gemini's voices.
+var recommendProviders = []string{"gemini", "elevenlabs", "google", "polly", "cartesia", "hume", "deepgram"}
+
+// recommendCandidates is how many of each provider's best-ranked voices the
+// LLM chooses between.
+const recommendCandidates = 8
+
+// HandleRecommendVoices suggests a host pairing per provider for a show
+// brief. tts.RecommendPairing ranks each catalog by its metadata; a small
+// Haiku call then picks the pair from the top candidates and explains it.
+// Without ANTHROPIC_API_KEY, or if the call fails, the heuristic pairings
+// are returned as-is.
+func (h *Handlers) HandleRecommendVoices(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, span := tracer.Start(ctx, "tool.recommend_voices")
+	defer span.End()
+
+	brief := tts.VoiceBrief{
+		Format:   mcp.ParseString(req, "format", "conversation"),
+		Tone:     mcp.ParseString(req, "tone", "casual"),
+		Language: mcp.ParseString(req, "language", ""),
+		Vibe:     strings.TrimSpace(mcp.ParseString(req, "vibe", "")),
+	}
+	providers := recommendProviders
+	if raw := mcp.ParseString(req, "providers", ""); raw != "" {
+		providers = nil
+		for _, p := range strings.Split(raw, ",") {
+			if p = strings.TrimSpace(p); p != "" && !slices.Contains(providers, p) {
+				providers = append(providers, p)
+			}
+		}
+	}
+	span.SetAttributes(
+		attribute.String("format", brief.Format),
+		attribute.String("tone", brief.Tone),
+		attribute.String("language", brief.Language),
+	)
+
+	var pairings []tts.VoicePairing
+	candidates := make(map[string][]tts.VoiceInfo)
+	var skipped []string
+	for _, p := range providers {
+		voices, err := tts.AvailableVoices(p)
+		if err != nil {
+			span.SetStatus(codes.Error, "unknown provider")
+			return mcp.NewToolResultError(fmt.Sprintf("unknown provider %q: must be gemini, vertex-express, gemini-vertex, elevenlabs, google, polly, cartesia, hume, or deepgram", p)), nil
+		}
+		pairing, err := tts.RecommendPairing(p, voices, brief)
+		if err != nil {
+			skipped = append(skipped, err.Error())
+			continue
+		}
+		pairings = append(pairings, pairing)
+		ranked := tts.RankVoices(voices, brief)
+		top := ranked[:min(len(ranked), recommendCandidates)]
+		if !slices.ContainsFunc(top, func(v tts.VoiceInfo) bool { return v.ID == pairing.Host2.ID }) {
+			top = append(slices.Clip(top), pairing.Host2)
+		}
+		candidates[p] = top
+	}
+	if len(pairings) == 0 {
+		span.SetStatus(codes.Error, "no voices")
+		return mcp.NewToolResultError(fmt.Sprintf("no provider has two voices for language %q", brief.Language)), nil
+	}
+
+	var refined map[string]bool
+	if picks, err := llmVoicePicks(ctx, brief, candidates); err != nil {
+		h.log.Debug("recommend_voices: using metadata ranking", "reason", err)
+	} else {
+		refined = applyVoicePicks(pairings, picks, candidates)
+	}
+	span.SetAttributes(attribute.Int("providers", len(pairings)), attribute.Int("refined", len(refined)))
+
+	out := make([]map[string]any, 0, len(pairings))
+	for _, p := range pairings {
+		source := "metadata"
+		if refined[p.Provider] {
+			source = "llm"
+		}
+		out = append(out, map[string]any{
+			"provider":  p.Provider,
+			"voice1":    recommendedVoice(p.Host1),
+			"voice2":    recommendedVoice(p.Host2),
+			"rationale": p.Rationale,
+			"source":    source,
+			"generate_params": map[string]any{
+				"tts":    p.Provider,
+				"voice1": p.Host1.ID,
+				"voice2": p.Host2.ID,
+			},
+		})
+	}
+	result := map[string]any{
+		"format":   brief.Format,
+		"tone":     brief.Tone,
+		"pairings": out,
+	}
+	if brief.Language != "" {
+		result["language"] = brief.Language
+	}
+	if brief.Vibe != "" {
+		result["vibe"] = brief.Vibe
+	}
+	if len(skipped) > 0 {
+		result["skipped"] = skipped
+	}
+	return jsonResult(result)
+}
+
+// recommendedVoice is a voice as recommend_voices reports it.
+func recommendedVoice(v tts.VoiceInfo) map[string]any {
+	entry := map[string]any{
+		"id":      v.ID,
+		"name":    v.Name,
+		"gender":  v.Gender,
+		"summary": v.Summary(),
+	}
+	if v.SampleURL != "" {
+		entry["sample_url"] = v.SampleURL
+	}
+	return entry
+}
+
+// voicePick is the LLM's choice for one provider.
+type voicePick struct {
+	Provider  string `json:"provider"`
+	Voice1    string `json:"voice1"`
+	Voice2    string `json:"voice2"`
+	Rationale string `json:"rationale"`
+}
+
+// llmVoicePicks asks Haiku to pick a pairing per provider from candidates.
+func llmVoicePicks(ctx context.Context, brief tts.VoiceBrief, candidates map[string][]tts.VoiceInfo) ([]voicePick, error) {
+	if os.Getenv("ANTHROPIC_API_KEY") == "" {
+		return nil, fmt.Errorf("no ANTHROPIC_API_KEY")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Format: %s\nTone: %s\n", brief.Format, brief.Tone)
+	if brief.Language != "" {
+		fmt.Fprintf(&sb, "Language: %s\n", brief.Language)
+	}
+	if brief.Vibe != "" {
+		fmt.Fprintf(&sb, "Vibe: %s\n", brief.Vibe)
+	}
+	providers := make([]string, 0, len(candidates))
+	for p := range candidates {
+		providers = append(providers, p)
+	}
+	slices.Sort(providers)
+	for _, p := range providers {
+		fmt.Fprintf(&sb, "\nProvider %s:\n", p)
+		for _, v := range candidates[p] {
+			fmt.Fprintf(&sb, "- id=%s name=%s gender=%s: %s\n", v.ID, v.Name, v.Gender, v.Summary())
+		}
+	}
+
+	client := anthropic.NewClient()
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	msg, err := client.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     anthropic.Model("claude-haiku-4-5-20251001"),
+		MaxTokens: 1024,
+		System: []anthropic.TextBlockParam{
+			{Text: "You cast voices for a two-host podcast. Given a show brief and candidate voices per TTS provider, pick a host 1 and host 2 for each provider from that provider's candidates: voices that suit the brief and contrast enough to tell apart. Return a JSON object {\"pairings\": [{\"provider\", \"voice1\", \"voice2\", \"rationale\"}]} where voice1 and voice2 are candidate ids and rationale is one sentence (max 200 chars). Return ONLY the JSON object, no markdown fences."},
+		},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(sb.String())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("haiku API call: %w", err)
+	}
+
+	var text string
+	for _, block := range msg.Content {
+		if tb, ok := block.AsAny().(anthropic.TextBlock); ok {
+			text += tb.Text
+		}
+	}
+
+	var result struct {
+		Pairings []voicePick `json:"pairings"`
+	}
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("no JSON in response")
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("parse pairings JSON: %w", err)
+	}
+	return result.Pairings, nil
+}
+
+// applyVoicePicks replaces heuristic pairings with the LLM's picks where
+// both voices are distinct candidates for that provider, and returns the
+// providers it replaced. Anything else the model returned is ignored.
+func applyVoicePicks(pairings []tts.VoicePairing, picks []voicePick, candidates map[string][]tts.VoiceInfo) map[string]bool {
+	find := func(provider, id string) (tts.VoiceInfo, bool) {
+		i := slices.IndexFunc(candidates[provider], func(v tts.VoiceInfo) bool { return v.ID == id })
+		if i < 0 {
+			return tts.VoiceInfo{}, false
+		}
+		return candidates[provider][i], true
+	}
+
+	refined := make(map[string]bool)
+	for _, pick := range picks {
+		i := slices.IndexFunc(pairings, func(p tts.VoicePairing) bool { return p.Provider == pick.Provider })
+		if i < 0 || pick.Voice1 == pick.Voice2 || strings.TrimSpace(pick.Rationale) == "" {
+			continue
+		}
+		v1, ok1 := find(pick.Provider, pick.Voice1)
+		v2, ok2 := find(pick.Provider, pick.Voice2)
+		if !ok1 || !ok2 {
+			continue
+		}
+		pairings[i].Host1, pairings[i].Host2 = v1, v2
+		pairings[i].Rationale = strings.TrimSpace(pick.Rationale)
+		refined[pick.Provider] = true
+	}
+	return refined
+}
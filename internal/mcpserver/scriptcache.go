@@ -0,0 +1,117 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/apresai/podcaster/internal/script"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// scriptCacheTTL is how long a generated script can be reused.
+const scriptCacheTTL = 30 * 24 * time.Hour
+
+// ScriptCacheItem is a reusable generated script.
+// PK=SCRIPTCACHE#<key>, SK=SCRIPT, where key is pipeline.ScriptCacheKey
+// (a hash of the source content and script options, never the user).
+type ScriptCacheItem struct {
+	PK         string `dynamodbav:"PK"`
+	SK         string `dynamodbav:"SK"`
+	ScriptJSON string `dynamodbav:"scriptJson"`
+	Model      string `dynamodbav:"model"`
+	CreatedAt  string `dynamodbav:"createdAt"`
+	TTL        int64  `dynamodbav:"ttl"`
+}
+
+// GetCachedScript returns the cached script for key, or nil if there is none.
+func (s *Store) GetCachedScript(ctx context.Context, key string) (*script.Script, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "SCRIPTCACHE#" + key},
+			"SK": &types.AttributeValueMemberS{Value: "SCRIPT"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get cached script: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item ScriptCacheItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("unmarshal cached script: %w", err)
+	}
+	// DynamoDB deletes expired items lazily; don't serve them meanwhile.
+	if item.TTL > 0 && time.Now().Unix() > item.TTL {
+		return nil, nil
+	}
+	var sc script.Script
+	if err := json.Unmarshal([]byte(item.ScriptJSON), &sc); err != nil {
+		return nil, fmt.Errorf("decode cached script: %w", err)
+	}
+	return &sc, nil
+}
+
+// PutCachedScript stores a generated script under key.
+func (s *Store) PutCachedScript(ctx context.Context, key, model string, sc *script.Script) error {
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return fmt.Errorf("encode script: %w", err)
+	}
+	now := time.Now().UTC()
+	item, err := attributevalue.MarshalMap(ScriptCacheItem{
+		PK:         "SCRIPTCACHE#" + key,
+		SK:         "SCRIPT",
+		ScriptJSON: string(data),
+		Model:      model,
+		CreatedAt:  now.Format(time.RFC3339),
+		TTL:        now.Add(scriptCacheTTL).Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal cached script: %w", err)
+	}
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put cached script: %w", err)
+	}
+	return nil
+}
+
+// taskScriptCache adapts the store to pipeline.ScriptCache for one task.
+// Store errors are logged and treated as misses so the cache never fails a
+// generation. hit records whether the task's script came from the cache.
+type taskScriptCache struct {
+	store *Store
+	model string
+	log   *slog.Logger
+	hit   bool
+}
+
+func (c *taskScriptCache) GetScript(ctx context.Context, key string) (*script.Script, bool) {
+	sc, err := c.store.GetCachedScript(ctx, key)
+	if err != nil {
+		c.log.WarnContext(ctx, "Script cache lookup failed", "error", err)
+		return nil, false
+	}
+	if sc == nil {
+		return nil, false
+	}
+	c.hit = true
+	c.log.InfoContext(ctx, "Script cache hit", "key", key)
+	return sc, true
+}
+
+func (c *taskScriptCache) PutScript(ctx context.Context, key string, sc *script.Script) {
+	if err := c.store.PutCachedScript(ctx, key, c.model, sc); err != nil {
+		c.log.WarnContext(ctx, "Script cache store failed", "error", err)
+	}
+}
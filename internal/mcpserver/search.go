@@ -0,0 +1,456 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/apresai/podcaster/internal/script"
+	"github.com/apresai/podcaster/internal/tts"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Transcript search. Each user's completed podcasts are indexed into an
+// inverted index in their own partition:
+//
+//	PK=USER#<id>  SK=TERM#<term>#<podcastId>  segments=[3, 17, 42]
+//	PK=USER#<id>  SK=SEARCHINDEX              version, indexedAt
+//
+// Podcasts are indexed when they complete. The SEARCHINDEX marker records
+// that a user's older podcasts were backfilled; the first search without it
+// (or with an older version) indexes the whole library. Keeping the index
+// under USER#<id> means account deletion removes it with everything else.
+//
+// search_transcripts matches podcasts containing every query term and
+// returns segment-level snippets. Timestamps are estimates: each segment's
+// share of the episode duration is proportional to its length.
+
+// searchIndexVersion is bumped when tokenization changes, forcing a rebuild.
+const searchIndexVersion = 1
+
+const (
+	maxQueryTerms   = 8
+	maxSnippets     = 3
+	snippetBefore   = 60
+	snippetAfter    = 120
+	defaultHitLimit = 10
+)
+
+// stopWords are too common to be worth indexing or searching for.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"i": true, "in": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"or": true, "so": true, "that": true, "the": true, "this": true, "to": true,
+	"was": true, "we": true, "were": true, "with": true, "you": true,
+	// contraction fragments ("isn't" splits into "isn" and "t")
+	"aren": true, "didn": true, "doesn": true, "don": true, "isn": true, "ll": true,
+	"re": true, "ve": true, "wasn": true, "won": true,
+}
+
+// searchIndexEntry is one posting in the inverted index.
+type searchIndexEntry struct {
+	PK        string `dynamodbav:"PK"`
+	SK        string `dynamodbav:"SK"`
+	PodcastID string `dynamodbav:"podcastId"`
+	Segments  []int  `dynamodbav:"segments"`
+}
+
+// isSearchIndexItem reports whether a USER# item belongs to the search index.
+func isSearchIndexItem(item map[string]types.AttributeValue) bool {
+	sk, ok := item["SK"].(*types.AttributeValueMemberS)
+	return ok && (strings.HasPrefix(sk.Value, "TERM#") || sk.Value == "SEARCHINDEX")
+}
+
+// searchText is a segment's text as spoken: prosody hints and audio tags
+// removed.
+func searchText(seg script.Segment) string {
+	return tts.StripAudioTags(tts.StripHints(seg.Text))
+}
+
+// searchTerms splits text into lowercase index terms, dropping stop words
+// and single characters. Terms are unique, in order of first appearance.
+func searchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var terms []string
+	seen := make(map[string]bool)
+	for _, w := range words {
+		if len([]rune(w)) < 2 || stopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+	}
+	return terms
+}
+
+// transcriptPostings maps each term in a script to the segments using it.
+func transcriptPostings(s *script.Script) map[string][]int {
+	postings := make(map[string][]int)
+	for i, seg := range s.Segments {
+		for _, term := range searchTerms(searchText(seg)) {
+			postings[term] = append(postings[term], i)
+		}
+	}
+	return postings
+}
+
+func searchIndexKey(userID, term, podcastID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		"SK": &types.AttributeValueMemberS{Value: "TERM#" + term + "#" + podcastID},
+	}
+}
+
+// IndexTranscript adds a podcast's script to its owner's search index.
+func (s *Store) IndexTranscript(ctx context.Context, userID, podcastID string, sc *script.Script) error {
+	postings := transcriptPostings(sc)
+	reqs := make([]types.WriteRequest, 0, len(postings))
+	for term, segments := range postings {
+		av, err := attributevalue.MarshalMap(searchIndexEntry{
+			PK:        "USER#" + userID,
+			SK:        "TERM#" + term + "#" + podcastID,
+			PodcastID: podcastID,
+			Segments:  segments,
+		})
+		if err != nil {
+			return fmt.Errorf("marshal index entry: %w", err)
+		}
+		reqs = append(reqs, types.WriteRequest{PutRequest: &types.PutRequest{Item: av}})
+	}
+	if _, err := s.batchWrite(ctx, reqs); err != nil {
+		return fmt.Errorf("index transcript %s: %w", podcastID, err)
+	}
+	return nil
+}
+
+// RemoveTranscript removes a podcast's script from its owner's search index.
+func (s *Store) RemoveTranscript(ctx context.Context, userID, podcastID string, sc *script.Script) error {
+	postings := transcriptPostings(sc)
+	keys := make([]map[string]types.AttributeValue, 0, len(postings))
+	for term := range postings {
+		keys = append(keys, searchIndexKey(userID, term, podcastID))
+	}
+	if _, err := s.deleteItems(ctx, keys); err != nil {
+		return fmt.Errorf("unindex transcript %s: %w", podcastID, err)
+	}
+	return nil
+}
+
+// ensureSearchIndex backfills a user's search index from their existing
+// podcasts unless the current version has already been built.
+func (s *Store) ensureSearchIndex(ctx context.Context, userID string) error {
+	marker := map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		"SK": &types.AttributeValueMemberS{Value: "SEARCHINDEX"},
+	}
+	out, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.tableName,
+		Key:       marker,
+	})
+	if err != nil {
+		return fmt.Errorf("get search index marker: %w", err)
+	}
+	if v, ok := out.Item["version"].(*types.AttributeValueMemberN); ok && v.Value == strconv.Itoa(searchIndexVersion) {
+		return nil
+	}
+
+	p := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		IndexName:              aws.String("GSI1"),
+		KeyConditionExpression: aws.String("GSI1PK = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: podcastsGSI1PK(userID)},
+		},
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("list podcasts for indexing: %w", err)
+		}
+		var items []PodcastItem
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return fmt.Errorf("unmarshal podcasts for indexing: %w", err)
+		}
+		for _, item := range items {
+			sc, ok := item.script()
+			if !ok || item.Status != string(JobStatusComplete) {
+				continue
+			}
+			if err := s.IndexTranscript(ctx, userID, item.PodcastID, sc); err != nil {
+				return err
+			}
+		}
+	}
+
+	marker["version"] = &types.AttributeValueMemberN{Value: strconv.Itoa(searchIndexVersion)}
+	marker["indexedAt"] = &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)}
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      marker,
+	}); err != nil {
+		return fmt.Errorf("put search index marker: %w", err)
+	}
+	return nil
+}
+
+// lookupTerm returns, per podcast, the segments that use term.
+func (s *Store) lookupTerm(ctx context.Context, userID, term string) (map[string][]int, error) {
+	hits := make(map[string][]int)
+	p := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :term)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":   &types.AttributeValueMemberS{Value: "USER#" + userID},
+			":term": &types.AttributeValueMemberS{Value: "TERM#" + term + "#"},
+		},
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("query search index: %w", err)
+		}
+		var entries []searchIndexEntry
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &entries); err != nil {
+			return nil, fmt.Errorf("unmarshal search index: %w", err)
+		}
+		for _, e := range entries {
+			hits[e.PodcastID] = e.Segments
+		}
+	}
+	return hits, nil
+}
+
+// script parses the podcast's inline script JSON.
+func (p *PodcastItem) script() (*script.Script, bool) {
+	if p.ScriptJSON == "" {
+		return nil, false
+	}
+	var s script.Script
+	if err := json.Unmarshal([]byte(p.ScriptJSON), &s); err != nil {
+		return nil, false
+	}
+	return &s, true
+}
+
+// segmentOffsets estimates each segment's start time in seconds by
+// spreading the episode duration over segments in proportion to length.
+func segmentOffsets(s *script.Script, durationSec int) []int {
+	total := 0
+	for _, seg := range s.Segments {
+		total += len(searchText(seg))
+	}
+	offsets := make([]int, len(s.Segments))
+	if total == 0 || durationSec <= 0 {
+		return offsets
+	}
+	chars := 0
+	for i, seg := range s.Segments {
+		offsets[i] = chars * durationSec / total
+		chars += len(searchText(seg))
+	}
+	return offsets
+}
+
+// snippet returns the part of text around the first query term.
+func snippet(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	at := -1
+	for _, t := range terms {
+		if i := strings.Index(lower, t); i >= 0 && (at < 0 || i < at) {
+			at = i
+		}
+	}
+	at = min(max(at, 0), len(text))
+	start, end := max(0, at-snippetBefore), min(len(text), at+snippetAfter)
+	// Widen to word boundaries (this also keeps multi-byte runes whole).
+	for start > 0 && text[start-1] != ' ' {
+		start--
+	}
+	for end < len(text) && text[end] != ' ' {
+		end++
+	}
+	out := strings.TrimSpace(text[start:end])
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(text) {
+		out += "…"
+	}
+	return out
+}
+
+func formatOffset(sec int) string {
+	return fmt.Sprintf("%d:%02d", sec/60, sec%60)
+}
+
+// searchMatch is one podcast matching every query term.
+type searchMatch struct {
+	podcastID string
+	segments  map[int]int // segment index -> number of query terms it contains
+	hits      int
+}
+
+// rankMatches intersects per-term postings into podcasts containing every
+// term, best first: most hits, then newest (IDs are ULIDs).
+func rankMatches(postings []map[string][]int) []searchMatch {
+	var matches []searchMatch
+	for id := range postings[0] {
+		m := searchMatch{podcastID: id, segments: make(map[int]int)}
+		for _, byPodcast := range postings {
+			segs, ok := byPodcast[id]
+			if !ok {
+				m.hits = -1
+				break
+			}
+			m.hits += len(segs)
+			for _, seg := range segs {
+				m.segments[seg]++
+			}
+		}
+		if m.hits > 0 {
+			matches = append(matches, m)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].hits != matches[j].hits {
+			return matches[i].hits > matches[j].hits
+		}
+		return matches[i].podcastID > matches[j].podcastID
+	})
+	return matches
+}
+
+// bestSegments picks up to maxSnippets segments, preferring those with the
+// most query terms, returned in script order.
+func bestSegments(segments map[int]int) []int {
+	idx := make([]int, 0, len(segments))
+	for i := range segments {
+		idx = append(idx, i)
+	}
+	sort.Slice(idx, func(a, b int) bool {
+		if segments[idx[a]] != segments[idx[b]] {
+			return segments[idx[a]] > segments[idx[b]]
+		}
+		return idx[a] < idx[b]
+	})
+	idx = idx[:min(len(idx), maxSnippets)]
+	slices.Sort(idx)
+	return idx
+}
+
+// HandleSearchTranscripts searches the caller's podcast transcripts.
+func (h *Handlers) HandleSearchTranscripts(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, span := tracer.Start(ctx, "tool.search_transcripts")
+	defer span.End()
+
+	userID, _ := h.caller(ctx, req)
+	if userID == "" {
+		span.SetStatus(codes.Error, "unauthenticated")
+		msg := "Authentication required. Provide your API key as: Authorization: Bearer <your-api-key>."
+		if !h.requireAuth {
+			msg = "Transcript search covers a user's library and needs an authenticated caller."
+		}
+		return mcp.NewToolResultError(msg), nil
+	}
+
+	query := mcp.ParseString(req, "query", "")
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		span.SetStatus(codes.Error, "empty query")
+		return mcp.NewToolResultError("query must contain at least one searchable word"), nil
+	}
+	terms = terms[:min(len(terms), maxQueryTerms)]
+	limit := parseIntParam(req, "limit", defaultHitLimit)
+	if limit <= 0 {
+		limit = defaultHitLimit
+	}
+	span.SetAttributes(attribute.String("query", query), attribute.Int("terms", len(terms)))
+
+	if err := h.store.ensureSearchIndex(ctx, userID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "index build failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to build search index: %v", err)), nil
+	}
+
+	postings := make([]map[string][]int, 0, len(terms))
+	for _, term := range terms {
+		hits, err := h.store.lookupTerm(ctx, userID, term)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "search failed")
+			return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
+		}
+		postings = append(postings, hits)
+	}
+
+	results := make([]map[string]any, 0, limit)
+	for _, m := range rankMatches(postings) {
+		if len(results) == limit {
+			break
+		}
+		item, err := h.store.GetPodcast(ctx, m.podcastID)
+		if err != nil {
+			h.log.WarnContext(ctx, "Search: get podcast failed", "podcast_id", m.podcastID, "error", err)
+			continue
+		}
+		// Trashed podcasts stay indexed until purged; skip them here.
+		if item == nil || item.DeletedAt != "" || item.UserID != userID {
+			continue
+		}
+		sc, ok := item.script()
+		if !ok {
+			continue
+		}
+
+		offsets := segmentOffsets(sc, parseDurationSec(item.Duration))
+		snippets := make([]map[string]any, 0, maxSnippets)
+		for _, i := range bestSegments(m.segments) {
+			if i >= len(sc.Segments) {
+				continue
+			}
+			snippets = append(snippets, map[string]any{
+				"segment":   i,
+				"speaker":   sc.Segments[i].Speaker,
+				"text":      snippet(searchText(sc.Segments[i]), terms),
+				"start_sec": offsets[i],
+				"timestamp": formatOffset(offsets[i]),
+			})
+		}
+
+		r := map[string]any{
+			"podcast_id": item.PodcastID,
+			"title":      item.Title,
+			"created_at": item.CreatedAt,
+			"matches":    m.hits,
+			"snippets":   snippets,
+		}
+		if item.AudioURL != "" {
+			r["audio_url"] = item.AudioURL
+		}
+		results = append(results, r)
+	}
+
+	span.SetAttributes(attribute.Int("result_count", len(results)))
+	return jsonResult(map[string]any{
+		"query":   query,
+		"terms":   terms,
+		"results": results,
+		"count":   len(results),
+		"note":    "Timestamps are estimated from each segment's position in the script.",
+	})
+}
@@ -0,0 +1,109 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Self-hosting support: the /healthz endpoint container health checks poll,
+// and creating the table on a fresh DynamoDB (Config.CreateTable) such as
+// the DynamoDB Local of deploy/selfhost/docker-compose.yml.
+
+// Health is the /healthz response.
+type Health struct {
+	Status  string `json:"status"` // "ok", or "draining" during shutdown
+	FFmpeg  bool   `json:"ffmpeg"` // false: episodes use the native assembler and FFmpeg-only options fail
+	Running int    `json:"running"`
+}
+
+// healthz reports whether the server takes new jobs: 200 when it does, 503
+// while it drains for shutdown.
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	running, draining := s.handlers.tasks.status()
+	h := Health{Status: "ok", FFmpeg: assembly.HasFFmpeg(), Running: running}
+	code := http.StatusOK
+	if draining {
+		h.Status, code = "draining", http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(h)
+}
+
+// status returns the number of running tasks and whether Drain has begun.
+func (tm *TaskManager) status() (running int, draining bool) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.running, tm.draining
+}
+
+// tableCreateTimeout bounds creating the table and waiting for it.
+const tableCreateTimeout = 2 * time.Minute
+
+// EnsureTable creates the table, with the keys, indexes, and TTL attribute
+// of deploy/infrastructure's, if it doesn't exist.
+func (s *Store) EnsureTable(ctx context.Context) (created bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, tableCreateTimeout)
+	defer cancel()
+	_, err = s.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: &s.tableName})
+	var notFound *types.ResourceNotFoundException
+	if err == nil {
+		return false, nil
+	} else if !errors.As(err, &notFound) {
+		return false, fmt.Errorf("describe table: %w", err)
+	}
+
+	str := func(name string) types.AttributeDefinition {
+		return types.AttributeDefinition{AttributeName: aws.String(name), AttributeType: types.ScalarAttributeTypeS}
+	}
+	keys := func(pk, sk string) []types.KeySchemaElement {
+		return []types.KeySchemaElement{
+			{AttributeName: aws.String(pk), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String(sk), KeyType: types.KeyTypeRange},
+		}
+	}
+	index := func(name string) types.GlobalSecondaryIndex {
+		return types.GlobalSecondaryIndex{
+			IndexName:  aws.String(name),
+			KeySchema:  keys(name+"PK", name+"SK"),
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}
+	}
+	_, err = s.client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: &s.tableName,
+		AttributeDefinitions: []types.AttributeDefinition{
+			str("PK"), str("SK"), str("GSI1PK"), str("GSI1SK"), str("GSI2PK"), str("GSI2SK"),
+		},
+		KeySchema:              keys("PK", "SK"),
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{index("GSI1"), index("GSI2")},
+		BillingMode:            types.BillingModePayPerRequest,
+	})
+	if err != nil {
+		return false, fmt.Errorf("create table: %w", err)
+	}
+	waiter := dynamodb.NewTableExistsWaiter(s.client)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: &s.tableName}, tableCreateTimeout); err != nil {
+		return true, fmt.Errorf("wait for table: %w", err)
+	}
+	// Expires SESSION# records (MCP_SESSION_STORE=dynamodb).
+	_, err = s.client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: &s.tableName,
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String("ttl"),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return true, fmt.Errorf("enable ttl: %w", err)
+	}
+	return true, nil
+}
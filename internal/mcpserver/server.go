@@ -8,7 +8,10 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/observability"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -18,36 +21,12 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
 )
 
-// Config holds server configuration.
-type Config struct {
-	Port                 int
-	TableName            string
-	S3Bucket             string
-	CDNBaseURL           string
-	AWSRegion            string
-	MaxTasks     int
-	SecretPrefix string // e.g. "/podcaster/mcp/"
-}
-
-// DefaultConfig returns a Config populated from environment variables.
-func DefaultConfig() Config {
-	cfg := Config{
-		Port:                 8000,
-		TableName:            envOr("DYNAMODB_TABLE", "podcaster-prod"),
-		S3Bucket:             envOr("S3_BUCKET", ""),
-		CDNBaseURL:           envOr("CDN_BASE_URL", "https://podcasts.apresai.dev"),
-		AWSRegion:            envOr("AWS_REGION", "us-east-1"),
-		MaxTasks:     5,
-		SecretPrefix: envOr("SECRET_PREFIX", "/podcaster/mcp/"),
-	}
-	return cfg
-}
-
 // Server is the MCP server for podcast generation.
 type Server struct {
 	cfg      Config
 	mcp      *server.MCPServer
 	handlers *Handlers
+	sessions *SessionStore // nil = stateless
 	log      *slog.Logger
 }
 
@@ -68,33 +47,72 @@ func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Server, error)
 	// Auto-instrument AWS SDK calls (DynamoDB, S3, Secrets Manager)
 	otelaws.AppendMiddlewares(&awsCfg.APIOptions)
 
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	cfg.applyAPIKeys()
+	logger.Info("Effective config", "config", cfg)
+
+	// Create AWS clients
+	capacity := newCapacityMonitor(logger)
+	ddbClient := dynamodb.NewFromConfig(awsCfg, capacity.addTo)
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = cfg.S3PathStyle
+	})
+
+	// Create store, storage, task manager
+	store := NewStore(ddbClient, cfg.TableName)
+	store.capacity = capacity
+	if cfg.CreateTable {
+		created, err := store.EnsureTable(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			logger.Info("Created table", "table", cfg.TableName)
+		}
+	}
+	if !assembly.HasFFmpeg() {
+		logger.Warn("FFmpeg not found: episodes use the native assembler, and options that need FFmpeg fail")
+	}
+	storage := NewStorage(s3Client, cfg.S3Bucket, cfg.CDNBaseURL)
+	storage.archiveAfterDays = cfg.ArchiveAfterDays
+	// Jobs outlive the shutdown signal; Shutdown drains them.
+	taskMgr := NewTaskManager(store, storage, cfg.MaxTasks, logger, context.WithoutCancel(ctx))
+	taskMgr.timeouts = cfg.Timeouts.WithDefaults()
+	taskMgr.budgets = cfg.Budgets
+	taskMgr.profile = cfg.Profile
+	taskMgr.features = &featureFlags{base: cfg.Features, store: store, log: logger}
+
 	// Fetch secrets asynchronously — don't block server startup.
 	// AgentCore sends the first HTTP request immediately after the container
 	// starts, so we must be listening on :8000 ASAP. Secrets are only needed
 	// when generate_podcast actually runs the pipeline.
-	if cfg.SecretPrefix != "" {
-		go func() {
+	// Warm-up runs after secrets so it sees the same environment as a job.
+	go func() {
+		if cfg.SecretPrefix != "" {
 			if err := loadSecrets(ctx, awsCfg, cfg.SecretPrefix, logger); err != nil {
 				logger.Warn("Failed to load secrets from Secrets Manager, falling back to env vars",
 					"error", err)
 			}
-		}()
-	}
-
-	if cfg.S3Bucket == "" {
-		return nil, fmt.Errorf("S3_BUCKET environment variable is required")
-	}
-
-	// Create AWS clients
-	ddbClient := dynamodb.NewFromConfig(awsCfg)
-	s3Client := s3.NewFromConfig(awsCfg)
-
-	// Create store, storage, task manager
-	store := NewStore(ddbClient, cfg.TableName)
-	storage := NewStorage(s3Client, cfg.S3Bucket, cfg.CDNBaseURL)
-	taskMgr := NewTaskManager(store, storage, cfg.MaxTasks, logger, ctx)
+		}
+		if len(cfg.WarmProviders) > 0 {
+			warmUp(ctx, awsCfg, store, cfg.WarmProviders, logger)
+		}
+	}()
 
 	handlers := NewHandlers(taskMgr, store, logger)
+	handlers.trialDailyLimit = cfg.TrialDailyLimit
+	handlers.requireAuth = cfg.RequireAuth
+	handlers.trustProxy = cfg.TrustProxy
+
+	var sessions *SessionStore
+	switch cfg.SessionStore {
+	case "":
+	case "dynamodb":
+		sessions = NewSessionStore(ddbClient, cfg.TableName, cfg.SessionTTL, logger)
+		logger.Info("MCP sessions persisted in DynamoDB", "ttl", cfg.SessionTTL)
+	}
 
 	// Create MCP server
 	mcpServer := server.NewMCPServer(
@@ -111,11 +129,21 @@ func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Server, error)
 	mcpServer.AddTool(tools[3], handlers.HandleListPodcasts)
 	mcpServer.AddTool(tools[4], handlers.HandleListVoices)
 	mcpServer.AddTool(tools[5], handlers.HandleListOptions)
+	mcpServer.AddTool(tools[6], handlers.HandleExportAccount)
+	mcpServer.AddTool(tools[7], handlers.HandleDeleteAccount)
+	mcpServer.AddTool(tools[8], handlers.HandleDeletePodcast)
+	mcpServer.AddTool(tools[9], handlers.HandleRestorePodcast)
+	mcpServer.AddTool(tools[10], handlers.HandlePurgePodcast)
+	mcpServer.AddTool(tools[11], handlers.HandleSearchTranscripts)
+	mcpServer.AddTool(tools[12], handlers.HandleComparePodcasts)
+	mcpServer.AddTool(tools[13], handlers.HandleGetPodcastLogs)
+	mcpServer.AddTool(tools[14], handlers.HandleRecommendVoices)
 
 	return &Server{
 		cfg:      cfg,
 		mcp:      mcpServer,
 		handlers: handlers,
+		sessions: sessions,
 		log:      logger,
 	}, nil
 }
@@ -129,7 +157,7 @@ func (s *Server) Start() error {
 
 	store := s.handlers.store
 
-	mcpHandler := server.NewStreamableHTTPServer(s.mcp,
+	opts := []server.StreamableHTTPOption{
 		server.WithStateLess(true), // AgentCore manages session IDs
 		server.WithHTTPContextFunc(func(ctx context.Context, r *http.Request) context.Context {
 			authHeader := r.Header.Get("Authorization")
@@ -158,13 +186,22 @@ func (s *Server) Start() error {
 				KeyID:         info.KeyID,
 			})
 		}),
-	)
+	}
+	if s.sessions != nil {
+		// Applied after WithStateLess, so it takes precedence.
+		opts = append(opts, server.WithSessionIdManagerResolver(s.sessions))
+	}
+	mcpHandler := server.NewStreamableHTTPServer(s.mcp, opts...)
+
+	// mcp-go handles one JSON-RPC message per POST; split batch arrays here.
+	batched := batchHandler(mcpHandler, s.log)
 
 	mux := http.NewServeMux()
 	// Register both /mcp and /mcp/ — AgentCore sends POST to /mcp/ (trailing
 	// slash) and Go's ServeMux won't match /mcp for /mcp/ POST requests.
-	mux.Handle("/mcp", mcpHandler)
-	mux.Handle("/mcp/", mcpHandler)
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.Handle("/mcp", batched)
+	mux.Handle("/mcp/", batched)
 
 	// Wrap with middleware that ensures Content-Type is set. AgentCore may not
 	// send Content-Type: application/json, which causes mcp-go to reject with
@@ -183,29 +220,40 @@ func (s *Server) Start() error {
 		mux.ServeHTTP(w, r)
 	})
 
+	// Trace each request, continuing the proxy's trace from the traceparent
+	// AgentCore forwards, so tool and job spans join it.
 	httpSrv := &http.Server{
 		Addr:    addr,
-		Handler: handler,
+		Handler: observability.Handler(handler, "mcp", "/healthz"),
 	}
 	return httpSrv.ListenAndServe()
 }
 
+// shutdownCheckpointTime is the part of Config.ShutdownTimeout kept for
+// cancelled jobs to save their partial results and record their failure.
+const shutdownCheckpointTime = 4 * time.Second
+
+// Shutdown drains the task manager within cfg.ShutdownTimeout: no new jobs
+// are accepted, running jobs may finish until shutdownCheckpointTime before
+// the deadline, and the rest are cancelled and left resumable. The HTTP
+// server keeps answering meanwhile, so clients can still poll their jobs.
+func (s *Server) Shutdown() {
+	wait := max(s.cfg.ShutdownTimeout-shutdownCheckpointTime, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+	s.handlers.tasks.Drain(ctx, s.cfg.ShutdownTimeout-wait)
+}
+
 // loadSecrets fetches API keys from Secrets Manager and sets them as env vars.
 func loadSecrets(ctx context.Context, cfg aws.Config, prefix string, logger *slog.Logger) error {
 	client := secretsmanager.NewFromConfig(cfg)
 
-	secrets := map[string]string{
-		"ANTHROPIC_API_KEY":  prefix + "ANTHROPIC_API_KEY",
-		"GEMINI_API_KEY":     prefix + "GEMINI_API_KEY",
-		"ELEVENLABS_API_KEY": prefix + "ELEVENLABS_API_KEY",
-		"VERTEX_AI_API_KEY":  prefix + "VERTEX_AI_API_KEY",
-	}
-
-	for envVar, secretID := range secrets {
-		// Skip if already set in environment
+	for _, envVar := range apiKeyEnv {
+		// Skip if already set in environment (or the config file)
 		if os.Getenv(envVar) != "" {
 			continue
 		}
+		secretID := prefix + envVar
 
 		result, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
 			SecretId: &secretID,
@@ -262,10 +310,3 @@ func loadSecrets(ctx context.Context, cfg aws.Config, prefix string, logger *slo
 
 	return nil
 }
-
-func envOr(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
-}
@@ -0,0 +1,256 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// DefaultSessionTTL is how long an idle MCP session survives.
+	DefaultSessionTTL = 24 * time.Hour
+
+	// sessionTouchInterval limits how often an active session's TTL is
+	// refreshed, so steady traffic doesn't cost a DynamoDB write per request.
+	sessionTouchInterval = 5 * time.Minute
+
+	// sessionOpTimeout bounds each DynamoDB call. The SessionIdManager
+	// interface has no error path for "store unavailable", so slow calls
+	// fail open rather than stall the request.
+	sessionOpTimeout = 3 * time.Second
+)
+
+// SessionItem is the DynamoDB record for an MCP session.
+// PK=SESSION#<id>, SK=METADATA. The table's TTL attribute removes idle sessions.
+type SessionItem struct {
+	PK         string `dynamodbav:"PK"`
+	SK         string `dynamodbav:"SK"`
+	SessionID  string `dynamodbav:"sessionId"`
+	CreatedAt  string `dynamodbav:"createdAt"`
+	LastSeenAt string `dynamodbav:"lastSeenAt"`
+	Terminated bool   `dynamodbav:"terminated,omitempty"`
+	TTL        int64  `dynamodbav:"ttl"`
+}
+
+// SessionStore persists MCP session IDs in DynamoDB so sessions outlive the
+// container that created them. AgentCore recycles runtimes freely; with an
+// in-memory session manager every recycle would invalidate live clients.
+//
+// It implements server.SessionIdManagerResolver so each request's manager can
+// see the incoming Mcp-Session-Id: AgentCore assigns session IDs itself, and
+// an initialize request that already carries one adopts it instead of
+// minting a new ID.
+type SessionStore struct {
+	client    *dynamodb.Client
+	tableName string
+	ttl       time.Duration
+	log       *slog.Logger
+
+	mu      sync.Mutex
+	touched map[string]time.Time // session ID → last TTL refresh
+}
+
+// NewSessionStore creates a DynamoDB-backed session store.
+// ttl <= 0 uses DefaultSessionTTL.
+func NewSessionStore(client *dynamodb.Client, tableName string, ttl time.Duration, logger *slog.Logger) *SessionStore {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &SessionStore{
+		client:    client,
+		tableName: tableName,
+		ttl:       ttl,
+		log:       logger,
+		touched:   make(map[string]time.Time),
+	}
+}
+
+// ResolveSessionIdManager returns a session manager bound to one request.
+func (s *SessionStore) ResolveSessionIdManager(r *http.Request) server.SessionIdManager {
+	return &sessionManager{
+		store:    s,
+		ctx:      context.WithoutCancel(r.Context()),
+		assigned: r.Header.Get(server.HeaderKeySessionID),
+	}
+}
+
+// sessionManager adapts SessionStore to mcp-go's per-request SessionIdManager.
+type sessionManager struct {
+	store    *SessionStore
+	ctx      context.Context
+	assigned string // Mcp-Session-Id sent with the request, if any
+}
+
+// Generate creates the session record for an initialize request.
+func (m *sessionManager) Generate() string {
+	id := m.assigned
+	if id == "" {
+		ulid, err := NewPodcastID()
+		if err != nil {
+			m.store.log.Error("Failed to generate session ID", "error", err)
+			return ""
+		}
+		id = "mcp-session-" + ulid
+	}
+	if err := m.store.create(m.ctx, id); err != nil {
+		m.store.log.Warn("Failed to persist MCP session", "session_id", id, "error", err)
+	}
+	return id
+}
+
+// Validate accepts known sessions and reports expired or terminated ones as
+// terminated, which mcp-go turns into 404 so the client re-initializes.
+func (m *sessionManager) Validate(sessionID string) (bool, error) {
+	if sessionID == "" {
+		// Clients that never initialized (or AgentCore health checks).
+		return false, nil
+	}
+	return m.store.validate(m.ctx, sessionID)
+}
+
+// Terminate marks the session ended (client sent DELETE).
+func (m *sessionManager) Terminate(sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+	return false, m.store.terminate(m.ctx, sessionID)
+}
+
+func (s *SessionStore) create(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, sessionOpTimeout)
+	defer cancel()
+
+	now := time.Now().UTC()
+	item := SessionItem{
+		PK:         "SESSION#" + id,
+		SK:         "METADATA",
+		SessionID:  id,
+		CreatedAt:  now.Format(time.RFC3339),
+		LastSeenAt: now.Format(time.RFC3339),
+		TTL:        now.Add(s.ttl).Unix(),
+	}
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("marshal session item: %w", err)
+	}
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      av,
+	}); err != nil {
+		return fmt.Errorf("put session: %w", err)
+	}
+
+	s.markTouched(id, now)
+	s.log.Info("MCP session created", "session_id", id, "ttl", s.ttl)
+	return nil
+}
+
+func (s *SessionStore) validate(ctx context.Context, id string) (bool, error) {
+	now := time.Now()
+	if s.recentlyTouched(id, now) {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sessionOpTimeout)
+	defer cancel()
+
+	// Refresh the TTL only if the session exists, is live, and hasn't
+	// expired (DynamoDB TTL deletion can lag by hours).
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "SESSION#" + id},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		UpdateExpression:    aws.String("SET lastSeenAt = :now, #ttl = :ttl"),
+		ConditionExpression: aws.String("attribute_exists(PK) AND (attribute_not_exists(terminated) OR terminated = :false) AND #ttl > :nowUnix"),
+		ExpressionAttributeNames: map[string]string{
+			"#ttl": "ttl",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":     &types.AttributeValueMemberS{Value: now.UTC().Format(time.RFC3339)},
+			":ttl":     &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", now.Add(s.ttl).Unix())},
+			":nowUnix": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", now.Unix())},
+			":false":   &types.AttributeValueMemberBOOL{Value: false},
+		},
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			s.forget(id)
+			s.log.Info("MCP session expired or terminated", "session_id", id)
+			return true, nil
+		}
+		// Fail open: a DynamoDB blip shouldn't take down every session.
+		s.log.Warn("MCP session lookup failed, allowing request", "session_id", id, "error", err)
+		return false, nil
+	}
+
+	s.markTouched(id, now)
+	return false, nil
+}
+
+func (s *SessionStore) terminate(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, sessionOpTimeout)
+	defer cancel()
+
+	s.forget(id)
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "SESSION#" + id},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		UpdateExpression:    aws.String("SET terminated = :true"),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":true": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return nil // unknown session — nothing to end
+		}
+		return fmt.Errorf("terminate session: %w", err)
+	}
+	s.log.Info("MCP session terminated", "session_id", id)
+	return nil
+}
+
+func (s *SessionStore) recentlyTouched(id string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.touched[id]
+	return ok && now.Sub(t) < sessionTouchInterval
+}
+
+func (s *SessionStore) markTouched(id string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Drop stale entries opportunistically so the map tracks only active sessions.
+	if len(s.touched) > 1000 {
+		for k, t := range s.touched {
+			if now.Sub(t) >= sessionTouchInterval {
+				delete(s.touched, k)
+			}
+		}
+	}
+	s.touched[id] = now
+}
+
+func (s *SessionStore) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.touched, id)
+}
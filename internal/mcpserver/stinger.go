@@ -0,0 +1,74 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
+	"github.com/apresai/podcaster/internal/ingest"
+	"github.com/apresai/podcaster/internal/observability"
+)
+
+// Intro/outro stingers. generate_podcast takes them as https URLs (a path
+// would name a file on the server); the task downloads each into its work
+// directory and passes the local copy to pipeline.Options.Intro/Outro.
+
+// maxStingerBytes bounds a downloaded stinger. A jingle is seconds long;
+// anything this large is almost certainly not one.
+const maxStingerBytes = 20 << 20
+
+// validateStingerURL checks that a stinger param is an https URL.
+func validateStingerURL(param, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return errkind.New(errkind.UserInput, fmt.Sprintf("%s must be an https URL to an audio file", param))
+	}
+	return nil
+}
+
+// downloadStinger fetches a stinger URL to path.
+func downloadStinger(ctx context.Context, source, path string) error {
+	return downloadAsset(ctx, source, path, maxStingerBytes)
+}
+
+// downloadAsset fetches source to path, failing if it is larger than
+// maxBytes. source comes from the caller, so it is fetched through
+// ingest.PublicTransport: never from a private or internal address.
+func downloadAsset(ctx context.Context, source, path string, maxBytes int64) error {
+	ctx = ingest.ContextPublicOnly(ctx)
+	client := &http.Client{Timeout: 30 * time.Second, Transport: observability.Transport(ingest.PublicTransport)}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return fmt.Errorf("could not create request for %s: %w", source, err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Podcaster/1.0; +https://podcasts.apresai.dev)")
+	resp, err := client.Do(req)
+	if err != nil {
+		return errkind.Wrap(errkind.UserInput, "could not fetch "+source, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errkind.New(errkind.UserInput, fmt.Sprintf("could not fetch %s: HTTP %d", source, resp.StatusCode))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	n, err := io.Copy(f, io.LimitReader(resp.Body, maxBytes+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("download %s: %w", source, err)
+	}
+	if n > maxBytes {
+		return errkind.New(errkind.UserInput, fmt.Sprintf("%s is larger than %d MB", source, maxBytes>>20))
+	}
+	return nil
+}
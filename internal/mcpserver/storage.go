@@ -1,20 +1,30 @@
 package mcpserver
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/apresai/podcaster/internal/assembly"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
-// Storage handles S3 uploads for podcast audio files.
+// Storage handles S3 objects for podcast audio, scripts, transcripts, and
+// account exports.
 type Storage struct {
 	client      *s3.Client
 	bucket      string
 	cdnBaseURL  string // e.g. "https://podcasts.apresai.dev"
+
+	// archiveAfterDays is Config.ArchiveAfterDays: audio is tagged for
+	// the archival lifecycle rule when positive (archive.go).
+	archiveAfterDays int
 }
 
 // NewStorage creates an S3 storage handler.
@@ -40,28 +50,106 @@ func (s *Storage) UploadScript(ctx context.Context, podcastID, scriptJSON string
 	return key, url, nil
 }
 
-// Upload uploads an MP3 file to S3 and returns the S3 key and public URL.
-func (s *Storage) Upload(ctx context.Context, podcastID, mp3Path string) (key, url string, err error) {
-	key = "audio/" + podcastID + ".mp3"
+// UploadTranscript uploads an episode's WebVTT transcript to S3 and returns
+// the S3 key and public URL.
+func (s *Storage) UploadTranscript(ctx context.Context, podcastID, vttPath string) (key, url string, err error) {
+	key = "transcripts/" + podcastID + ".vtt"
+
+	data, err := os.ReadFile(vttPath)
+	if err != nil {
+		return "", "", fmt.Errorf("read transcript: %w", err)
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &s.bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("text/vtt"),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("upload transcript to s3: %w", err)
+	}
+
+	url = s.cdnBaseURL + "/" + key
+	return key, url, nil
+}
+
+// UploadPeaks uploads an episode's waveform peaks JSON next to its audio and
+// returns the S3 key and public URL.
+func (s *Storage) UploadPeaks(ctx context.Context, podcastID, peaksPath string) (key, url string, err error) {
+	key = "audio/" + podcastID + ".peaks.json"
+
+	data, err := os.ReadFile(peaksPath)
+	if err != nil {
+		return "", "", fmt.Errorf("read waveform peaks: %w", err)
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &s.bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("upload waveform peaks to s3: %w", err)
+	}
+
+	url = s.cdnBaseURL + "/" + key
+	return key, url, nil
+}
+
+// UploadPage uploads an episode's listening page to S3 and returns the S3
+// key and public URL.
+func (s *Storage) UploadPage(ctx context.Context, podcastID, htmlPath string) (key, url string, err error) {
+	key = "pages/" + podcastID + ".html"
+
+	data, err := os.ReadFile(htmlPath)
+	if err != nil {
+		return "", "", fmt.Errorf("read listening page: %w", err)
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &s.bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("text/html; charset=utf-8"),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("upload listening page to s3: %w", err)
+	}
+
+	url = s.cdnBaseURL + "/" + key
+	return key, url, nil
+}
+
+// Upload uploads an episode to S3 and returns the S3 key and public URL.
+// The key and content type follow the file's format (assembly.FormatOf).
+func (s *Storage) Upload(ctx context.Context, podcastID, audioPath string) (key, url string, err error) {
+	format := assembly.FormatOf(audioPath)
+	key = "audio/" + podcastID + format.Ext()
 
-	f, err := os.Open(mp3Path)
+	f, err := os.Open(audioPath)
 	if err != nil {
-		return "", "", fmt.Errorf("open mp3: %w", err)
+		return "", "", fmt.Errorf("open audio: %w", err)
 	}
 	defer f.Close()
 
 	info, err := f.Stat()
 	if err != nil {
-		return "", "", fmt.Errorf("stat mp3: %w", err)
+		return "", "", fmt.Errorf("stat audio: %w", err)
 	}
 
-	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+	in := &s3.PutObjectInput{
 		Bucket:        &s.bucket,
 		Key:           &key,
 		Body:          f,
-		ContentType:   aws.String("audio/mpeg"),
+		ContentType:   aws.String(format.ContentType()),
 		ContentLength: aws.Int64(info.Size()),
-	})
+	}
+	if s.archiveAfterDays > 0 {
+		in.Tagging = aws.String(archiveTag)
+	}
+	_, err = s.client.PutObject(ctx, in)
 	if err != nil {
 		return "", "", fmt.Errorf("upload to s3: %w", err)
 	}
@@ -69,3 +157,116 @@ func (s *Storage) Upload(ctx context.Context, podcastID, mp3Path string) (key, u
 	url = s.cdnBaseURL + "/" + key
 	return key, url, nil
 }
+
+// exportURLExpiry is how long a presigned account-export link stays valid.
+const exportURLExpiry = 24 * time.Hour
+
+// exportPrefix is the S3 prefix holding a user's account exports.
+func exportPrefix(userID string) string {
+	return "exports/" + userID + "/"
+}
+
+// UploadExport stores an account export under exports/, which the CDN does
+// not serve, and returns the key and a presigned download URL.
+func (s *Storage) UploadExport(ctx context.Context, userID string, data []byte) (key, url string, err error) {
+	key = exportPrefix(userID) + time.Now().UTC().Format("20060102T150405Z") + ".json"
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &s.bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("upload export to s3: %w", err)
+	}
+
+	presigned, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+	}, s3.WithPresignExpires(exportURLExpiry))
+	if err != nil {
+		return "", "", fmt.Errorf("presign export: %w", err)
+	}
+	return key, presigned.URL, nil
+}
+
+// Delete removes an object. Deleting a missing key is not an error.
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return fmt.Errorf("delete %s from s3: %w", key, err)
+	}
+	return nil
+}
+
+// List returns the keys of the objects under prefix.
+func (s *Storage) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	p := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: &s.bucket,
+		Prefix: &prefix,
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return keys, fmt.Errorf("list %s in s3: %w", prefix, err)
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+	}
+	return keys, nil
+}
+
+// DeletePrefix removes every object under prefix and returns how many it
+// deleted. It stops at the first failure; rerunning picks up the rest.
+func (s *Storage) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	keys, err := s.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	for i, key := range keys {
+		if err := s.Delete(ctx, key); err != nil {
+			return i, err
+		}
+	}
+	return len(keys), nil
+}
+
+// Exists reports whether an object is present.
+func (s *Storage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		var notFound *s3types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("head %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// Move copies an object to a new key and deletes the original. A missing
+// source is not an error, so a partially completed move can be repeated.
+func (s *Storage) Move(ctx context.Context, src, dst string) error {
+	exists, err := s.Exists(ctx, src)
+	if err != nil || !exists {
+		return err
+	}
+	_, err = s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     &s.bucket,
+		Key:        &dst,
+		CopySource: aws.String(s.bucket + "/" + src),
+	})
+	if err != nil {
+		return fmt.Errorf("copy %s to %s: %w", src, dst, err)
+	}
+	return s.Delete(ctx, src)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/apresai/podcaster/internal/errkind"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -49,6 +50,7 @@ type PodcastItem struct {
 	ProgressPercent float64 `dynamodbav:"progressPercent,omitempty"`
 	StageMessage    string  `dynamodbav:"stageMessage,omitempty"`
 	ErrorMessage    string  `dynamodbav:"errorMessage,omitempty"`
+	ErrorKind       string  `dynamodbav:"errorKind,omitempty"` // errkind.Kind of the failure
 	Model           string  `dynamodbav:"model,omitempty"`
 	TTSProvider     string  `dynamodbav:"ttsProvider,omitempty"`
 	Format          string  `dynamodbav:"format,omitempty"`
@@ -56,8 +58,55 @@ type PodcastItem struct {
 	ScriptJSON      string  `dynamodbav:"scriptJson,omitempty"`
 	ScriptKey       string  `dynamodbav:"scriptKey,omitempty"`
 	ScriptURL       string  `dynamodbav:"scriptUrl,omitempty"`
+	TranscriptKey   string  `dynamodbav:"transcriptKey,omitempty"`
+	TranscriptURL   string  `dynamodbav:"transcriptUrl,omitempty"`
+	PageKey         string  `dynamodbav:"pageKey,omitempty"`
+	PageURL         string  `dynamodbav:"pageUrl,omitempty"`
+	PeaksKey        string  `dynamodbav:"peaksKey,omitempty"`
+	PeaksURL        string  `dynamodbav:"peaksUrl,omitempty"`
+	Explicit        bool    `dynamodbav:"explicit,omitempty"` // detected or given at generation
 	CreatedAt       string  `dynamodbav:"createdAt"`
 
+	// Soft delete (see trash.go): set while the podcast is in the trash.
+	DeletedAt string `dynamodbav:"deletedAt,omitempty"`
+	TTL       int64  `dynamodbav:"ttl,omitempty"`
+
+	// Generation options beyond model/TTS/format, for compare_podcasts.
+	Settings map[string]string `dynamodbav:"settings,omitempty"`
+
+	// Set when TTS failed partway (see partial.go): how far it got, and
+	// where the finished segments are kept for generate_podcast's
+	// resume_from.
+	SegmentsDone    int    `dynamodbav:"segmentsDone,omitempty"`
+	SegmentsTotal   int    `dynamodbav:"segmentsTotal,omitempty"`
+	MissingSegments []int  `dynamodbav:"missingSegments,omitempty"`
+	PartialPrefix   string `dynamodbav:"partialPrefix,omitempty"`
+
+	// CLICommand is the local command that reproduces the job
+	// (reproCommand).
+	CLICommand string `dynamodbav:"cliCommand,omitempty"`
+
+	// QCReport is the episode's quality check (assembly.QCReport) as JSON,
+	// set whether or not the job failed it.
+	QCReport string `dynamodbav:"qcReport,omitempty"`
+
+	// ScriptEscalation is the retry with a stronger script model
+	// (pipeline.ScriptEscalation) as JSON, if the review forced one.
+	ScriptEscalation string `dynamodbav:"scriptEscalation,omitempty"`
+
+	// Set by restore_podcast for archived audio (archive.go): when the
+	// retrieval was requested, and at which tier.
+	ArchiveRestoreAt   string `dynamodbav:"archiveRestoreAt,omitempty"`
+	ArchiveRestoreTier string `dynamodbav:"archiveRestoreTier,omitempty"`
+
+	// SpeakerCheck is the diarized speaker check (diarize.Report) as JSON,
+	// if the job asked for one (verify_speakers).
+	SpeakerCheck string `dynamodbav:"speakerCheck,omitempty"`
+
+	// StageTimings is the run's stage report ([]pipeline.StageTiming) as
+	// JSON: each stage's time against its latency budget.
+	StageTimings string `dynamodbav:"stageTimings,omitempty"`
+
 	// Usage tracking fields (set after pipeline completion)
 	UserID           string  `dynamodbav:"userId,omitempty"`
 	InputCharCount   int     `dynamodbav:"inputCharCount,omitempty"`
@@ -70,6 +119,7 @@ type PodcastItem struct {
 type Store struct {
 	client    *dynamodb.Client
 	tableName string
+	capacity  *capacityMonitor // the client's; nil without one (capacity.go)
 }
 
 // NewStore creates a DynamoDB store.
@@ -87,20 +137,14 @@ func NewPodcastID() (string, error) {
 }
 
 // CreateJob inserts a new podcast job with status=submitted.
-func (s *Store) CreateJob(ctx context.Context, id, owner, userID, sourceURL, model, ttsProvider, format string) error {
+func (s *Store) CreateJob(ctx context.Context, id, owner, userID, sourceURL, model, ttsProvider, format string, settings map[string]string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	sortVal := now + "#" + id
 
-	// GSI1: per-user index for authenticated users, global fallback for anonymous
-	gsi1pk := "PODCASTS"
-	if userID != "" {
-		gsi1pk = "USER#" + userID + "#PODCASTS"
-	}
-
 	item := PodcastItem{
 		PK:          "PODCAST#" + id,
 		SK:          "METADATA",
-		GSI1PK:      gsi1pk,
+		GSI1PK:      podcastsGSI1PK(userID), // per-user index; global fallback for anonymous
 		GSI1SK:      sortVal,
 		GSI2PK:      "PODCASTS",
 		GSI2SK:      sortVal,
@@ -112,6 +156,7 @@ func (s *Store) CreateJob(ctx context.Context, id, owner, userID, sourceURL, mod
 		Model:       model,
 		TTSProvider: ttsProvider,
 		Format:      format,
+		Settings:    settings,
 		CreatedAt:   now,
 	}
 
@@ -155,29 +200,77 @@ func (s *Store) UpdateProgress(ctx context.Context, id string, status JobStatus,
 	return nil
 }
 
+// setAttribute sets one string attribute on podcast id's record, for the
+// reports and details a job records as it runs (cliCommand, qcReport,
+// speakerCheck, stageTimings, scriptEscalation).
+func (s *Store) setAttribute(ctx context.Context, id, name, value string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "PODCAST#" + id},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		UpdateExpression:         aws.String("SET #attr = :value"),
+		ExpressionAttributeNames: map[string]string{"#attr": name},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":value": &types.AttributeValueMemberS{Value: value},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("set %s: %w", name, err)
+	}
+	return nil
+}
+
+// CompletedJob is a finished job's metadata, recorded by CompleteJob. The
+// S3 keys and URLs other than the audio's are empty when their upload was
+// skipped or failed, and are then left off the record.
+type CompletedJob struct {
+	Title, Summary string
+	Duration       string
+	FileSizeMB     float64
+	Explicit       bool
+	ScriptJSON     string
+
+	AudioKey, AudioURL           string
+	ScriptKey, ScriptURL         string
+	TranscriptKey, TranscriptURL string
+	PageKey, PageURL             string
+	PeaksKey, PeaksURL           string
+}
+
 // CompleteJob marks the job as complete with final metadata.
-func (s *Store) CompleteJob(ctx context.Context, id, title, summary, audioKey, audioURL, duration, scriptJSON, scriptKey, scriptURL string, fileSizeMB float64) error {
-	updateExpr := "SET #status = :status, progressPercent = :pct, stageMessage = :msg, title = :title, summary = :summary, audioKey = :akey, audioUrl = :aurl, #dur = :dur, fileSizeMB = :sz, scriptJson = :sj"
+func (s *Store) CompleteJob(ctx context.Context, id string, job CompletedJob) error {
+	updateExpr := "SET #status = :status, progressPercent = :pct, stageMessage = :msg, title = :title, summary = :summary, audioKey = :akey, audioUrl = :aurl, #dur = :dur, fileSizeMB = :sz, scriptJson = :sj, explicit = :exp"
 	exprValues := map[string]types.AttributeValue{
 		":status":  &types.AttributeValueMemberS{Value: string(JobStatusComplete)},
 		":pct":     &types.AttributeValueMemberN{Value: "1.00"},
 		":msg":     &types.AttributeValueMemberS{Value: "Complete"},
-		":title":   &types.AttributeValueMemberS{Value: title},
-		":summary": &types.AttributeValueMemberS{Value: summary},
-		":akey":    &types.AttributeValueMemberS{Value: audioKey},
-		":aurl":    &types.AttributeValueMemberS{Value: audioURL},
-		":dur":     &types.AttributeValueMemberS{Value: duration},
-		":sz":      &types.AttributeValueMemberN{Value: fmt.Sprintf("%.2f", fileSizeMB)},
-		":sj":      &types.AttributeValueMemberS{Value: scriptJSON},
+		":title":   &types.AttributeValueMemberS{Value: job.Title},
+		":summary": &types.AttributeValueMemberS{Value: job.Summary},
+		":akey":    &types.AttributeValueMemberS{Value: job.AudioKey},
+		":aurl":    &types.AttributeValueMemberS{Value: job.AudioURL},
+		":dur":     &types.AttributeValueMemberS{Value: job.Duration},
+		":sz":      &types.AttributeValueMemberN{Value: fmt.Sprintf("%.2f", job.FileSizeMB)},
+		":sj":      &types.AttributeValueMemberS{Value: job.ScriptJSON},
+		":exp":     &types.AttributeValueMemberBOOL{Value: job.Explicit},
 	}
 
-	if scriptKey != "" {
-		updateExpr += ", scriptKey = :skey"
-		exprValues[":skey"] = &types.AttributeValueMemberS{Value: scriptKey}
+	optional := []struct{ attr, placeholder, value string }{
+		{"scriptKey", ":skey", job.ScriptKey},
+		{"scriptUrl", ":surl", job.ScriptURL},
+		{"transcriptKey", ":tkey", job.TranscriptKey},
+		{"transcriptUrl", ":turl", job.TranscriptURL},
+		{"pageKey", ":pkey", job.PageKey},
+		{"pageUrl", ":purl", job.PageURL},
+		{"peaksKey", ":wkey", job.PeaksKey},
+		{"peaksUrl", ":wurl", job.PeaksURL},
 	}
-	if scriptURL != "" {
-		updateExpr += ", scriptUrl = :surl"
-		exprValues[":surl"] = &types.AttributeValueMemberS{Value: scriptURL}
+	for _, o := range optional {
+		if o.value != "" {
+			updateExpr += ", " + o.attr + " = " + o.placeholder
+			exprValues[o.placeholder] = &types.AttributeValueMemberS{Value: o.value}
+		}
 	}
 
 	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
@@ -199,21 +292,24 @@ func (s *Store) CompleteJob(ctx context.Context, id, title, summary, audioKey, a
 	return nil
 }
 
-// FailJob marks the job as failed with an error message.
-func (s *Store) FailJob(ctx context.Context, id, errMsg string) error {
+// FailJob marks the job as failed. The record gets cause's user-facing
+// message and kind (see errkind); log the full error separately.
+func (s *Store) FailJob(ctx context.Context, id string, cause error) error {
+	errMsg := errkind.UserMessage(cause)
 	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
 		TableName: &s.tableName,
 		Key: map[string]types.AttributeValue{
 			"PK": &types.AttributeValueMemberS{Value: "PODCAST#" + id},
 			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
 		},
-		UpdateExpression: aws.String("SET #status = :status, errorMessage = :err, stageMessage = :msg"),
+		UpdateExpression: aws.String("SET #status = :status, errorMessage = :err, errorKind = :kind, stageMessage = :msg"),
 		ExpressionAttributeNames: map[string]string{
 			"#status": "status",
 		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":status": &types.AttributeValueMemberS{Value: string(JobStatusFailed)},
 			":err":    &types.AttributeValueMemberS{Value: errMsg},
+			":kind":   &types.AttributeValueMemberS{Value: string(errkind.Of(cause))},
 			":msg":    &types.AttributeValueMemberS{Value: "Failed: " + errMsg},
 		},
 	})
@@ -2,15 +2,24 @@ package mcpserver
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/errkind"
+	"github.com/apresai/podcaster/internal/ingest"
 	"github.com/apresai/podcaster/internal/observability"
+	"github.com/apresai/podcaster/internal/observability/logctx"
 	"github.com/apresai/podcaster/internal/pipeline"
 	"github.com/apresai/podcaster/internal/progress"
 	"github.com/apresai/podcaster/internal/script"
@@ -33,6 +42,8 @@ type GenerateRequest struct {
 	Topic     string
 	Owner     string
 	UserID    string // authenticated user ID (empty for anonymous)
+	KeyID     string // API key prefix the request came in on (empty for anonymous)
+	Trial     bool   // unauthenticated trial tier (see trial.go)
 
 	// Voice and style options
 	Style        string  // comma-separated styles: humor, wow, serious, debate, storytelling
@@ -40,14 +51,116 @@ type GenerateRequest struct {
 	Voice2       string
 	Voice3       string
 	TTSModel     string  // TTS model override (e.g. eleven_v3, gemini-2.5-pro-tts)
-	TTSSpeed     float64 // speech speed (ElevenLabs: 0.7-1.2, Google: 0.25-2.0)
+	TTSSpeed     float64 // speech speed (ElevenLabs: 0.7-1.2, Google: 0.25-2.0, others 0.5-2.0 via FFmpeg)
 	TTSStability float64 // voice stability, ElevenLabs only (0.0-1.0)
-	TTSPitch     float64 // pitch in semitones, Google only (-20.0 to 20.0)
+	TTSPitch     float64 // pitch in semitones (Google: -20.0 to 20.0, others -12.0 to 12.0 via FFmpeg)
+
+	// NoScriptCache always generates a fresh script instead of reusing one
+	// cached for identical content and options (see scriptcache.go).
+	NoScriptCache bool
+
+	// Music is a built-in music bed (assembly.MusicBeds) mixed under the
+	// episode; empty for none.
+	Music string
+
+	// Intro and Outro are https URLs of audio clips crossfaded onto the
+	// episode's start and end (see stinger.go); empty for none.
+	Intro string
+	Outro string
+
+	// Show is the show name for the episode's album tag, and Cover an https
+	// URL of cover art to embed (see cover.go); empty for the defaults.
+	Show  string
+	Cover string
+
+	// OutputFormat is the episode's audio format (assembly.OutputFormats);
+	// empty for MP3.
+	OutputFormat string
+
+	// Explicit overrides the explicit-content flag; nil detects it from
+	// the script (pipeline.Options.Explicit).
+	Explicit *bool
+
+	// QC is the quality check mode (pipeline.QCWarn, QCFail, or QCOff);
+	// empty warns.
+	QC string
+
+	// Preview synthesizes only the script's first Preview segments
+	// (pipeline.Options.Preview); 0 is the whole episode.
+	Preview int
+
+	// VerifySpeakers checks the finished episode's voices against the
+	// script (pipeline.Options.VerifySpeakers).
+	VerifySpeakers bool
+
+	// ResumeFrom is a failed podcast whose partial TTS results (see
+	// partial.go) this run resumes: its script is reused and only its
+	// missing segments are synthesized. No input is needed.
+	ResumeFrom string
+
+	// FetchHeaders ("Name: value", from the headers and cookies params)
+	// authenticate fetching InputURL (pipeline.Options.FetchHeaders). Like
+	// the API keys, they are never stored.
+	FetchHeaders []string
 
 	// Per-request API key overrides (BYOK). Empty = use server defaults.
-	AnthropicAPIKey  string
-	GeminiAPIKey     string
-	ElevenLabsAPIKey string
+	AnthropicAPIKey     string
+	GeminiAPIKey        string
+	ElevenLabsAPIKey    string
+	CartesiaAPIKey      string
+	VertexExpressAPIKey string
+	HumeAPIKey          string
+	DeepgramAPIKey      string
+}
+
+// settings returns the generation options stored on the podcast record so
+// runs can be compared later (compare_podcasts). API keys are never
+// included; text input is recorded as a hash so identical sources match.
+func (r GenerateRequest) settings() map[string]string {
+	m := map[string]string{
+		"tone":          r.Tone,
+		"duration":      r.Duration,
+		"voices":        strconv.Itoa(r.Voices),
+		"topic":         r.Topic,
+		"style":         r.Style,
+		"voice1":        r.Voice1,
+		"voice2":        r.Voice2,
+		"voice3":        r.Voice3,
+		"tts_model":     r.TTSModel,
+		"music":         r.Music,
+		"intro":         r.Intro,
+		"outro":         r.Outro,
+		"show":          r.Show,
+		"cover":         r.Cover,
+		"output_format": r.OutputFormat,
+	}
+	for k, v := range map[string]float64{"tts_speed": r.TTSSpeed, "tts_stability": r.TTSStability, "tts_pitch": r.TTSPitch} {
+		if v != 0 {
+			m[k] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	if r.Explicit != nil {
+		m["explicit"] = strconv.FormatBool(*r.Explicit)
+	}
+	if r.QC != "" {
+		m["qc"] = r.QC
+	}
+	if r.Preview > 0 {
+		m["preview"] = strconv.Itoa(r.Preview)
+	}
+	if r.VerifySpeakers {
+		m["verify_speakers"] = "true"
+	}
+	if r.InputText != "" {
+		sum := sha256.Sum256([]byte(r.InputText))
+		m["input_sha256"] = hex.EncodeToString(sum[:])
+	}
+	for k, v := range m {
+		if v == "" {
+			delete(m, k)
+		}
+	}
+	return m
 }
 
 // TaskManager manages async podcast generation tasks.
@@ -55,16 +168,32 @@ type TaskManager struct {
 	store   *Store
 	storage *Storage
 	log     *slog.Logger
-	baseCtx context.Context // cancelled on SIGTERM for graceful shutdown
+	baseCtx context.Context // parent of every task's context
 
 	mu       sync.Mutex
-	cancels  map[string]context.CancelFunc
+	cancels  map[string]context.CancelCauseFunc
 	maxTasks int
 	running  int
+	draining bool               // set by Drain: no new tasks
+	wg       sync.WaitGroup     // running tasks, for Drain
+	logs     map[string]*jobLog // live pipeline logs (joblog.go)
+
+	// timeouts bounds each generation's stages and upload (Config.Timeouts).
+	timeouts pipeline.Timeouts
+
+	// budgets are each generation's stage latency targets (Config.Budgets).
+	budgets pipeline.Budgets
+
+	// profile enables pipeline profiling for every job (Config.Profile).
+	profile bool
+
+	// features decides each job's feature flags (features.go).
+	features *featureFlags
 }
 
 // NewTaskManager creates a task manager.
-// baseCtx should be cancelled on SIGTERM so pipeline goroutines can clean up.
+// Tasks run until they finish or Drain cancels them, so baseCtx should not
+// be cancelled on SIGTERM.
 func NewTaskManager(store *Store, storage *Storage, maxTasks int, logger *slog.Logger, baseCtx context.Context) *TaskManager {
 	if maxTasks <= 0 {
 		maxTasks = 5
@@ -74,7 +203,8 @@ func NewTaskManager(store *Store, storage *Storage, maxTasks int, logger *slog.L
 		storage:  storage,
 		log:      logger,
 		baseCtx:  baseCtx,
-		cancels:  make(map[string]context.CancelFunc),
+		cancels:  make(map[string]context.CancelCauseFunc),
+		logs:     make(map[string]*jobLog),
 		maxTasks: maxTasks,
 	}
 }
@@ -88,30 +218,36 @@ func (tm *TaskManager) StartTask(ctx context.Context, req GenerateRequest) (stri
 	}
 
 	tm.mu.Lock()
+	if tm.draining {
+		tm.mu.Unlock()
+		return "", errkind.New(errkind.Internal, "server is shutting down; try again shortly")
+	}
 	if tm.running >= tm.maxTasks {
 		tm.mu.Unlock()
-		return "", fmt.Errorf("max concurrent tasks reached (%d)", tm.maxTasks)
+		return "", errkind.New(errkind.Internal, fmt.Sprintf("server busy: max concurrent tasks reached (%d); try again shortly", tm.maxTasks))
 	}
 	tm.running++
+	tm.wg.Add(1)
 
-	// Derive goroutine context from baseCtx (cancelled on SIGTERM) rather than
+	// Derive goroutine context from baseCtx (cancelled only by Drain) rather than
 	// the HTTP request context (cancelled when the response is sent).
 	// Carry trace span from the HTTP request for observability linking.
 	taskCtx := observability.DetachTraceContextFrom(ctx, tm.baseCtx)
-	taskCtx, cancel := context.WithCancel(taskCtx)
+	taskCtx, cancel := context.WithCancelCause(taskCtx)
 	tm.cancels[id] = cancel
 	tm.mu.Unlock()
 
-	if err := tm.store.CreateJob(ctx, id, req.Owner, req.UserID, req.InputURL, req.Model, req.TTS, req.Format); err != nil {
-		cancel()
+	if err := tm.store.CreateJob(ctx, id, req.Owner, req.UserID, req.InputURL, req.Model, req.TTS, req.Format, req.settings()); err != nil {
+		cancel(nil)
 		tm.mu.Lock()
 		delete(tm.cancels, id)
 		tm.running--
 		tm.mu.Unlock()
+		tm.wg.Done()
 		return "", fmt.Errorf("create job: %w", err)
 	}
 
-	go tm.runPipeline(taskCtx, id, req)
+	go tm.runPipeline(taskCtx, id, req, tm.newJobLog(id))
 
 	return id, nil
 }
@@ -121,41 +257,104 @@ func (tm *TaskManager) CancelTask(id string) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 	if cancel, ok := tm.cancels[id]; ok {
-		cancel()
+		cancel(nil)
+	}
+}
+
+// errShutdown is the cancellation cause of the tasks Drain cuts off.
+var errShutdown = errors.New("server shutdown")
+
+// Drain prepares for exit: it refuses new tasks and waits for the running
+// ones to finish until ctx is done. Tasks still running then are cancelled
+// and given up to grace to save their partial TTS results for resume_from
+// and record their failure.
+func (tm *TaskManager) Drain(ctx context.Context, grace time.Duration) {
+	tm.mu.Lock()
+	tm.draining = true
+	running := tm.running
+	tm.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		tm.wg.Wait()
+		close(done)
+	}()
+	tm.log.Info("Draining tasks", "running", running)
+	select {
+	case <-done:
+		tm.log.Info("All tasks finished")
+		return
+	case <-ctx.Done():
+	}
+
+	tm.mu.Lock()
+	tm.log.Warn("Drain deadline reached; cancelling remaining tasks", "running", tm.running)
+	for _, cancel := range tm.cancels {
+		cancel(errShutdown)
+	}
+	tm.mu.Unlock()
+	select {
+	case <-done:
+		tm.log.Info("Cancelled tasks recorded")
+	case <-time.After(grace):
+		tm.log.Warn("Cancelled tasks did not finish recording in time")
+	}
+}
+
+// failShutdown records a job Drain cut off, with runErr the pipeline's
+// error if it got that far. Segments it had synthesized are kept for
+// resume_from, as after any partial TTS failure.
+func (tm *TaskManager) failShutdown(ctx context.Context, id string, runErr error) {
+	ctx = context.WithoutCancel(ctx)
+	resumable := runErr != nil && tm.savePartial(ctx, id, runErr)
+	msg := "server shutdown during processing; please try again"
+	if resumable {
+		msg = fmt.Sprintf("server shutdown during processing; resume with resume_from=%q to keep the finished segments", id)
+	}
+	failCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := tm.store.FailJob(failCtx, id, errkind.New(errkind.Internal, msg)); err != nil {
+		tm.log.Error("Record shutdown failure failed", "podcast_id", id, "error", err)
+		return
 	}
+	tm.log.Info("Marked job as failed due to shutdown", "podcast_id", id, "resumable", resumable)
 }
 
-func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateRequest) {
+func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateRequest, jl *jobLog) {
 	ctx, span := tracer.Start(ctx, "pipeline.run",
 		trace.WithAttributes(attribute.String("podcast_id", id)),
 	)
 	defer span.End()
 
+	// runErr is the pipeline's error, for failShutdown.
+	var runErr error
 	defer func() {
-		// On shutdown (SIGTERM), mark any in-progress job as failed so it doesn't
-		// appear stuck in "synthesizing" forever.
-		if ctx.Err() != nil {
-			failCtx, failCancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer failCancel()
-			tm.store.FailJob(failCtx, id, "server shutdown during processing")
-			tm.log.Info("Marked job as failed due to shutdown", "podcast_id", id)
+		// A job Drain cut off is marked failed (keeping its partial results)
+		// so it doesn't appear stuck in "synthesizing" forever.
+		if errors.Is(context.Cause(ctx), errShutdown) {
+			tm.failShutdown(ctx, id, runErr)
 		}
+		jl.finish()
 		tm.mu.Lock()
 		delete(tm.cancels, id)
 		tm.running--
 		tm.mu.Unlock()
+		tm.wg.Done()
 	}()
 
 	log := tm.log.With("podcast_id", id)
+	// Pipeline code that logs on its own (FFmpeg runs) picks this up.
+	ctx = logctx.With(ctx, log)
 
-	// Throttle DynamoDB writes: max 1 per 2 seconds except on stage transitions.
+	// Throttle DynamoDB writes except on stage transitions: one per 2
+	// seconds, stretched while DynamoDB is throttling (capacity.go).
 	var lastWrite time.Time
 	var lastStage progress.Stage
 
 	progressCb := func(evt progress.Event) {
 		now := time.Now()
 		stageChanged := evt.Stage != lastStage
-		throttled := now.Sub(lastWrite) < 2*time.Second
+		throttled := now.Sub(lastWrite) < tm.store.capacity.ProgressInterval()
 
 		if throttled && !stageChanged {
 			return
@@ -184,7 +383,8 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "create work dir failed")
-		tm.store.FailJob(ctx, id, fmt.Sprintf("create work dir: %v", err))
+		log.ErrorContext(ctx, "Create work dir failed", "error", err)
+		tm.store.FailJob(ctx, id, fmt.Errorf("create work dir: %w", err))
 		return
 	}
 	defer os.RemoveAll(workDir)
@@ -197,18 +397,21 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 		if err := os.WriteFile(inputPath, []byte(req.InputText), 0644); err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "write input failed")
-			tm.store.FailJob(ctx, id, fmt.Sprintf("write input text: %v", err))
+			log.ErrorContext(ctx, "Write input text failed", "error", err)
+			tm.store.FailJob(ctx, id, fmt.Errorf("write input text: %w", err))
 			return
 		}
 		input = inputPath
 	}
-	if input == "" {
+	if input == "" && req.ResumeFrom == "" {
 		span.SetStatus(codes.Error, "no input")
-		tm.store.FailJob(ctx, id, "no input provided")
+		tm.store.FailJob(ctx, id, errkind.New(errkind.UserInput, "no input provided"))
 		return
 	}
 
-	outputPath := workDir + "/" + id + ".mp3"
+	// Validated by HandleGeneratePodcast.
+	outputFormat, _ := assembly.ParseFormat(req.OutputFormat)
+	outputPath := workDir + "/" + id + outputFormat.Ext()
 	scriptPath := workDir + "/" + id + ".json"
 
 	model := req.Model
@@ -232,19 +435,11 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 		voices = 2
 	}
 
-	// Parse voice specs (provider:voiceID or plain voiceID)
-	v1Provider, v1ID := tts.ParseVoiceSpec(req.Voice1)
-	v2Provider, v2ID := tts.ParseVoiceSpec(req.Voice2)
-	v3Provider, v3ID := tts.ParseVoiceSpec(req.Voice3)
-	if v1Provider == "" {
-		v1Provider = ttsProvider
-	}
-	if v2Provider == "" {
-		v2Provider = ttsProvider
-	}
-	if v3Provider == "" {
-		v3Provider = ttsProvider
-	}
+	// Parse voice specs (provider:voiceID or plain voiceID, optional
+	// @settings). HandleGenerate has already rejected malformed specs.
+	v1Provider, v1ID, v1Settings, _ := voiceSpec(req.Voice1, ttsProvider)
+	v2Provider, v2ID, v2Settings, _ := voiceSpec(req.Voice2, ttsProvider)
+	v3Provider, v3ID, v3Settings, _ := voiceSpec(req.Voice3, ttsProvider)
 
 	// Resolve voice display names to provider-specific IDs
 	v1ID = tts.ResolveVoiceName(v1Provider, v1ID)
@@ -262,6 +457,11 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 		}
 	}
 
+	var disclaimer string
+	if req.Trial {
+		disclaimer = trialDisclaimer
+	}
+
 	opts := pipeline.Options{
 		Input:            input,
 		Output:           outputPath,
@@ -277,6 +477,9 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 		Voice3:           v3ID,
 		Voice3Provider:   v3Provider,
 		Voices:           voices,
+		Voice1Settings:   v1Settings,
+		Voice2Settings:   v2Settings,
+		Voice3Settings:   v3Settings,
 		DefaultTTS:       ttsProvider,
 		Model:            model,
 		TTSModel:         req.TTSModel,
@@ -285,9 +488,127 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 		TTSPitch:         req.TTSPitch,
 		OnProgress:       progressCb,
 		DisableBatch:     true, // Per-segment with rate limiting for AI Studio Gemini TTS 10 RPM limit
+		NoTTSCache:       true, // Ephemeral container disk; don't share audio across users
+		Disclaimer:       disclaimer,
 		AnthropicAPIKey:  req.AnthropicAPIKey,
 		GeminiAPIKey:     req.GeminiAPIKey,
 		ElevenLabsAPIKey: req.ElevenLabsAPIKey,
+		CartesiaAPIKey:   req.CartesiaAPIKey,
+	}
+	opts.VertexExpressAPIKey = req.VertexExpressAPIKey
+	opts.HumeAPIKey = req.HumeAPIKey
+	opts.DeepgramAPIKey = req.DeepgramAPIKey
+	opts.FetchHeaders = req.FetchHeaders
+	opts.Timeouts = tm.timeouts
+	opts.Budgets = tm.budgets
+	opts.Music = req.Music
+	for _, st := range []struct {
+		url  string
+		dest *string
+		name string
+	}{{req.Intro, &opts.Intro, "intro"}, {req.Outro, &opts.Outro, "outro"}} {
+		if st.url == "" {
+			continue
+		}
+		path := workDir + "/" + st.name + ".audio"
+		if err := downloadStinger(ctx, st.url, path); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "download "+st.name+" failed")
+			log.ErrorContext(ctx, "Download stinger failed", "stinger", st.name, "error", err)
+			tm.store.FailJob(ctx, id, fmt.Errorf("download %s: %w", st.name, err))
+			return
+		}
+		*st.dest = path
+	}
+	opts.Show = req.Show
+	opts.OutputFormat = outputFormat
+	opts.Explicit = req.Explicit
+	opts.QC = req.QC
+	opts.Preview = req.Preview
+	opts.VerifySpeakers = req.VerifySpeakers
+	if tm.features != nil {
+		opts.Features = tm.features.forJob(ctx, id)
+	}
+	if tm.profile {
+		// Uploaded to debug/<id>/profile/ when the run ends (saveProfile);
+		// the summary is in the job log too.
+		opts.ProfileDir = workDir + "/profile"
+	}
+	// The listening page is served from pages/, next to audio/ and transcripts/.
+	opts.PageAudio = "../audio/" + path.Base(outputPath)
+	opts.PageTranscript = "../transcripts/" + id + ".vtt"
+	if req.Cover != "" {
+		ext, _ := validateCoverURL(req.Cover)
+		coverPath := workDir + "/cover" + ext
+		if err := downloadAsset(ctx, req.Cover, coverPath, maxCoverBytes); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "download cover failed")
+			log.ErrorContext(ctx, "Download cover failed", "error", err)
+			tm.store.FailJob(ctx, id, fmt.Errorf("download cover: %w", err))
+			return
+		}
+		opts.Cover = coverPath
+	}
+	// Keep the full detail a local --verbose run prints for get_podcast_logs.
+	opts.Verbose = true
+	opts.LogWriter = jl
+
+	if req.ResumeFrom != "" {
+		resumeDir := workDir + "/resume"
+		scriptFile, err := tm.storage.DownloadPartial(ctx, partialPrefix(req.ResumeFrom), resumeDir)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "download partial failed")
+			log.ErrorContext(ctx, "Download partial results failed", "resume_from", req.ResumeFrom, "error", err)
+			tm.store.FailJob(ctx, id, fmt.Errorf("download partial results of %s: %w", req.ResumeFrom, err))
+			return
+		}
+		opts.Input = ""
+		opts.FromScript = scriptFile
+		opts.ResumeTTS = resumeDir
+		log.InfoContext(ctx, "Resuming partial TTS", "resume_from", req.ResumeFrom)
+	}
+
+	cliCommand := reproCommand(opts, req, id)
+	if err := tm.store.setAttribute(ctx, id, "cliCommand", cliCommand); err != nil {
+		log.WarnContext(ctx, "Save CLI command failed", "error", err)
+	}
+
+	// A script the review can't fix is retried with a stronger model;
+	// record that and bill the retry.
+	var escalation *pipeline.ScriptEscalation
+	opts.OnEscalation = func(e pipeline.ScriptEscalation) {
+		escalation = &e
+		data, _ := json.Marshal(e)
+		if err := tm.store.setAttribute(ctx, id, "scriptEscalation", string(data)); err != nil {
+			log.WarnContext(ctx, "Save script escalation failed", "error", err)
+		}
+		log.InfoContext(ctx, "Script escalated", "from", e.From, "to", e.To, "passed", e.Passed, "used", e.Used, "cost_usd", e.CostUSD)
+	}
+
+	// Keep the stage report with the episode; overruns are logged for
+	// alerting on systemic slowdowns.
+	capture := &debugCapture{}
+	opts.OnIngest = func(c *ingest.Content) { capture.content = c }
+	opts.OnStageTimings = func(timings []pipeline.StageTiming) {
+		capture.timings = timings
+		for _, t := range timings {
+			if t.OverBudget {
+				log.WarnContext(ctx, "Stage over budget", "stage", t.Stage, "qualifier", t.Qualifier, "seconds", t.Seconds, "budget_seconds", t.Budget)
+			}
+		}
+		data, _ := json.Marshal(timings)
+		if err := tm.store.setAttribute(ctx, id, "stageTimings", string(data)); err != nil {
+			log.WarnContext(ctx, "Save stage timings failed", "error", err)
+		}
+	}
+
+	// Reuse scripts across users for identical content and options. Trial
+	// runs get a disclaimer added after caching, so they can share too.
+	var scriptCache *taskScriptCache
+	if !req.NoScriptCache {
+		scriptCache = &taskScriptCache{store: tm.store, model: model, log: log}
+		opts.ScriptCache = scriptCache
 	}
 
 	// Run the pipeline
@@ -297,24 +618,39 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 	log.InfoContext(ctx, "Pipeline starting",
 		"model", model, "tts", ttsProvider, "duration", duration,
 		"batch", !opts.DisableBatch, "voices", voices, "input_url", opts.Input)
-	if err := pipeline.Run(ctx, opts); err != nil {
+	// Inputs are fetched for any caller, so never from the internal network.
+	err = pipeline.Run(ingest.ContextPublicOnly(ctx), opts)
+	tm.saveReport(ctx, id, "qcReport", pipeline.QCPath(outputPath))
+	tm.saveReport(ctx, id, "speakerCheck", pipeline.SpeakersPath(outputPath))
+	if opts.ProfileDir != "" {
+		tm.saveProfile(ctx, id, opts.ProfileDir)
+	}
+	if err != nil {
 		elapsed := time.Since(pipelineStart).Round(time.Second)
 		fmt.Fprintf(os.Stderr, "[%s] Pipeline FAILED after %s: %v\n", id, elapsed, err)
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "pipeline failed")
-		log.ErrorContext(ctx, "Pipeline failed", "error", err, "elapsed", elapsed.String())
-		tm.store.FailJob(ctx, id, err.Error())
+		log.ErrorContext(ctx, "Pipeline failed", "error", err, "error_kind", errkind.Of(err), "elapsed", elapsed.String())
+		if errors.Is(context.Cause(ctx), errShutdown) {
+			runErr = err
+			return
+		}
+		tm.savePartial(ctx, id, err)
+		tm.saveDebugBundle(ctx, id, req, cliCommand, outputPath, capture, err)
+		tm.store.FailJob(ctx, id, err)
 		return
 	}
 
 	// Read script metadata
 	var title, summary, scriptJSON string
+	var explicit bool
 	if data, err := os.ReadFile(pipeline.ScriptPath(outputPath)); err == nil {
 		scriptJSON = string(data)
 		var s script.Script
 		if json.Unmarshal(data, &s) == nil {
 			title = s.Title
 			summary = s.Summary
+			explicit = s.Explicit
 		}
 	}
 	// Fallback: try the workdir script path
@@ -325,6 +661,7 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 			if json.Unmarshal(data, &s) == nil {
 				title = s.Title
 				summary = s.Summary
+				explicit = s.Explicit
 			}
 		}
 	}
@@ -334,33 +671,96 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 	if info, err := os.Stat(outputPath); err == nil {
 		fileSizeMB = float64(info.Size()) / (1024 * 1024)
 	}
-	audioDuration := pipeline.ProbeDuration(outputPath)
+	audioDuration := pipeline.ProbeDuration(ctx, outputPath)
 
 	// Upload to S3
 	tm.store.UpdateProgress(ctx, id, JobStatusUploading, 0.95, "Uploading to S3...")
-	audioKey, audioURL, err := tm.storage.Upload(ctx, id, outputPath)
+	upload := tm.timeouts.WithDefaults().Upload
+	uploadCtx, uploadCancel := context.WithTimeout(ctx, upload)
+	defer uploadCancel()
+	audioKey, audioURL, err := tm.storage.Upload(uploadCtx, id, outputPath)
 	if err != nil {
+		if ctx.Err() == nil && errors.Is(uploadCtx.Err(), context.DeadlineExceeded) {
+			err = &pipeline.StageTimeoutError{Stage: "upload", Limit: upload, Err: err}
+		}
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "upload failed")
 		log.ErrorContext(ctx, "S3 upload failed", "error", err)
-		tm.store.FailJob(ctx, id, fmt.Sprintf("upload to S3: %v", err))
+		tm.store.FailJob(ctx, id, fmt.Errorf("upload to S3: %w", err))
 		return
 	}
 
 	// Upload script JSON to S3 (non-fatal — inline scriptJson in DDB is authoritative)
 	var scriptKey, scriptURL string
 	if scriptJSON != "" {
-		scriptKey, scriptURL, err = tm.storage.UploadScript(ctx, id, scriptJSON)
+		scriptKey, scriptURL, err = tm.storage.UploadScript(uploadCtx, id, scriptJSON)
 		if err != nil {
 			log.WarnContext(ctx, "Script upload failed (non-fatal)", "error", err)
 		}
 	}
 
+	// Upload the WebVTT transcript (non-fatal; the pipeline skips it if the
+	// episode couldn't be measured)
+	var transcriptKey, transcriptURL string
+	if _, err := os.Stat(pipeline.VTTPath(outputPath)); err == nil {
+		transcriptKey, transcriptURL, err = tm.storage.UploadTranscript(uploadCtx, id, pipeline.VTTPath(outputPath))
+		if err != nil {
+			log.WarnContext(ctx, "Transcript upload failed (non-fatal)", "error", err)
+		}
+	}
+
+	// Upload the listening page (non-fatal; written alongside the transcript)
+	var pageKey, pageURL string
+	if _, err := os.Stat(pipeline.PagePath(outputPath)); err == nil {
+		pageKey, pageURL, err = tm.storage.UploadPage(uploadCtx, id, pipeline.PagePath(outputPath))
+		if err != nil {
+			log.WarnContext(ctx, "Listening page upload failed (non-fatal)", "error", err)
+		}
+	}
+
+	// Upload the waveform peaks next to the audio (non-fatal; skipped by the
+	// pipeline if it couldn't measure them)
+	var peaksKey, peaksURL string
+	if _, err := os.Stat(pipeline.PeaksPath(outputPath)); err == nil {
+		peaksKey, peaksURL, err = tm.storage.UploadPeaks(uploadCtx, id, pipeline.PeaksPath(outputPath))
+		if err != nil {
+			log.WarnContext(ctx, "Waveform peaks upload failed (non-fatal)", "error", err)
+		}
+	}
+
 	// Mark complete
-	if err := tm.store.CompleteJob(ctx, id, title, summary, audioKey, audioURL, audioDuration, scriptJSON, scriptKey, scriptURL, fileSizeMB); err != nil {
+	if err := tm.store.CompleteJob(ctx, id, CompletedJob{
+		Title:         title,
+		Summary:       summary,
+		Duration:      audioDuration,
+		FileSizeMB:    fileSizeMB,
+		Explicit:      explicit,
+		ScriptJSON:    scriptJSON,
+		AudioKey:      audioKey,
+		AudioURL:      audioURL,
+		ScriptKey:     scriptKey,
+		ScriptURL:     scriptURL,
+		TranscriptKey: transcriptKey,
+		TranscriptURL: transcriptURL,
+		PageKey:       pageKey,
+		PageURL:       pageURL,
+		PeaksKey:      peaksKey,
+		PeaksURL:      peaksURL,
+	}); err != nil {
 		log.ErrorContext(ctx, "Complete job failed", "error", err)
 	}
 
+	// Index the transcript for search_transcripts (best effort; a missed
+	// podcast is picked up by the next index rebuild)
+	if req.UserID != "" && scriptJSON != "" {
+		var s script.Script
+		if json.Unmarshal([]byte(scriptJSON), &s) == nil {
+			if err := tm.store.IndexTranscript(ctx, req.UserID, id, &s); err != nil {
+				log.WarnContext(ctx, "Index transcript failed", "error", err)
+			}
+		}
+	}
+
 	// Record usage metrics if authenticated
 	if req.UserID != "" {
 		inputChars := len(req.InputText)
@@ -382,11 +782,25 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 		// Parse duration to seconds
 		durationSec := parseDurationSec(audioDuration)
 
-		if err := tm.store.RecordUsage(ctx, id, req.UserID, req.Model, req.TTS, inputChars, ttsChars, durationSec); err != nil {
+		// A cached or resumed script cost nothing to generate; bill TTS only.
+		scriptModel := req.Model
+		if (scriptCache != nil && scriptCache.hit) || req.ResumeFrom != "" {
+			scriptModel = ""
+		}
+		var extraCost float64
+		if escalation != nil {
+			extraCost = escalation.CostUSD
+		}
+		if err := tm.store.RecordUsage(ctx, id, req.UserID, scriptModel, req.TTS, inputChars, ttsChars, durationSec, extraCost); err != nil {
 			log.WarnContext(ctx, "Record usage failed", "error", err)
 		} else {
-			cost := EstimateCost(req.Model, req.TTS, inputChars, ttsChars, durationSec)
+			cost := EstimateCost(scriptModel, req.TTS, inputChars, ttsChars, durationSec) + extraCost
 			log.InfoContext(ctx, "Usage recorded", "user_id", req.UserID, "cost_usd", cost)
+			if req.KeyID != "" {
+				if err := tm.store.RecordKeyCost(ctx, req.KeyID, req.UserID, cost); err != nil {
+					log.WarnContext(ctx, "Record key usage failed", "key_id", req.KeyID, "error", err)
+				}
+			}
 		}
 	}
 
@@ -401,6 +815,63 @@ func (tm *TaskManager) runPipeline(ctx context.Context, id string, req GenerateR
 	log.InfoContext(ctx, "Pipeline complete", "title", title, "audio_url", audioURL)
 }
 
+// saveReport records a report the pipeline wrote (the quality check at
+// QCPath, the speaker check at SpeakersPath) as attribute name, if it ran.
+// Best effort, like savePartial: a run that failed its check (qc=fail)
+// keeps the report that explains why.
+func (tm *TaskManager) saveReport(ctx context.Context, id, name, path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if err := tm.store.setAttribute(ctx, id, name, string(data)); err != nil {
+		tm.log.With("podcast_id", id).WarnContext(ctx, "Save report failed", "attribute", name, "error", err)
+	}
+}
+
+// reproCommand returns the local CLI command equivalent to a hosted job.
+// Server paths mean nothing to the caller, so text input becomes
+// input.txt, a resumed job's script is the <id>.json at its script_url,
+// and stingers are named after their URLs' files.
+func reproCommand(opts pipeline.Options, req GenerateRequest, id string) string {
+	opts.Input = req.InputURL
+	if req.InputText != "" {
+		opts.Input = "input.txt"
+	}
+	if req.ResumeFrom != "" {
+		opts.FromScript = id + ".json"
+	}
+	if req.Intro != "" {
+		opts.Intro = path.Base(req.Intro)
+	}
+	if req.Outro != "" {
+		opts.Outro = path.Base(req.Outro)
+	}
+	opts.Show = req.Show
+	if req.Cover != "" {
+		opts.Cover = path.Base(req.Cover)
+	}
+	return opts.ReproCommand()
+}
+
+// voiceSpec splits a voice parameter ("elevenlabs:rachel@stability=0.3")
+// into provider, voice ID, and per-voice settings. The provider defaults to
+// defaultTTS; the settings are validated against it.
+func voiceSpec(spec, defaultTTS string) (provider, id string, settings tts.VoiceSettings, err error) {
+	spec, settings, err = tts.ParseVoiceSettings(spec)
+	if err != nil {
+		return "", "", tts.VoiceSettings{}, err
+	}
+	provider, id = tts.ParseVoiceSpec(spec)
+	if provider == "" {
+		provider = defaultTTS
+	}
+	if err := settings.Validate(provider); err != nil {
+		return "", "", tts.VoiceSettings{}, err
+	}
+	return provider, id, settings, nil
+}
+
 // parseDurationSec converts a duration string like "12m34s" or "12:34" to seconds.
 func parseDurationSec(d string) int {
 	if d == "" {
@@ -424,7 +895,7 @@ func parseDurationSec(d string) int {
 // mapStage maps a pipeline progress stage to a job status.
 func mapStage(stage progress.Stage) JobStatus {
 	switch stage {
-	case progress.StageIngest:
+	case progress.StageTranscribe, progress.StageIngest:
 		return JobStatusIngesting
 	case progress.StageScript:
 		return JobStatusScripting
@@ -3,15 +3,22 @@ package mcpserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"os"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/diarize"
+	"github.com/apresai/podcaster/internal/errkind"
 	"github.com/apresai/podcaster/internal/ingest"
+	"github.com/apresai/podcaster/internal/pipeline"
 	"github.com/apresai/podcaster/internal/tts"
 	"github.com/mark3labs/mcp-go/mcp"
 	"go.opentelemetry.io/otel"
@@ -40,12 +47,25 @@ func ToolDefs() []mcp.Tool {
 				Properties: map[string]any{
 					"input_url": map[string]any{
 						"type":        "string",
-						"description": "URL of content to convert into a podcast",
+						"description": "URL of content to convert into a podcast, or an arXiv paper ID (e.g. 2401.04088); arXiv papers are read from their HTML rendering, without references or figures; Hacker News and Reddit thread URLs read the linked article plus the thread's top-level comments as community discussion",
 					},
 					"input_text": map[string]any{
 						"type":        "string",
 						"description": "Raw text to convert into a podcast (alternative to input_url)",
 					},
+					"headers": map[string]any{
+						"type":                 "object",
+						"additionalProperties": map[string]any{"type": "string"},
+						"description":          "Request headers for fetching input_url behind a login wall, e.g. {\"Authorization\": \"Bearer ...\"}. Sent only to input_url's host, never stored or logged. The server only connects to public addresses, never private, loopback, or link-local ones.",
+					},
+					"cookies": map[string]any{
+						"type":        "string",
+						"description": "Cookies for fetching input_url behind a login wall, as a Cookie header (\"session=abc; token=xyz\"). Sent only to input_url's host, never stored or logged.",
+					},
+					"preset": map[string]any{
+						"type":        "string",
+						"description": "Named bundle of format, duration, tone, and style (see list_options presets): quick-summary, daily-brief, explainer, deep-dive, debate. Options passed explicitly override the preset.",
+					},
 					"model": map[string]any{
 						"type":        "string",
 						"description": "Script generation LLM that writes the conversation. Always use haiku unless the user specifically asks for a different model. Options: haiku (default, Claude Haiku 4.5), sonnet (Claude Sonnet 4.5), gemini-flash (Gemini 3 Flash), gemini-pro (Gemini 3 Pro), nova-lite (Amazon Nova 2 Lite, cheapest)",
@@ -53,7 +73,7 @@ func ToolDefs() []mcp.Tool {
 					},
 					"tts": map[string]any{
 						"type":        "string",
-						"description": "Text-to-speech provider that synthesizes audio: gemini (default), gemini-vertex, vertex-express, elevenlabs, google, polly",
+						"description": "Text-to-speech provider that synthesizes audio: gemini (default), gemini-vertex, vertex-express, elevenlabs, google, polly, cartesia, hume, deepgram",
 						"default":     "gemini",
 					},
 					"tone": map[string]any{
@@ -84,9 +104,45 @@ func ToolDefs() []mcp.Tool {
 						"type":        "string",
 						"description": "Conversation styles (comma-separated): humor, wow, serious, debate, storytelling",
 					},
+					"music": map[string]any{
+						"type":        "string",
+						"description": "Background music bed mixed under the voices, ducked while anyone speaks, with a short intro and outro: ambient, pulse, or drone. Omit for no music.",
+					},
+					"intro": map[string]any{
+						"type":        "string",
+						"description": "https URL of an audio clip (jingle, stinger) crossfaded onto the start of the episode",
+					},
+					"outro": map[string]any{
+						"type":        "string",
+						"description": "https URL of an audio clip crossfaded onto the end of the episode",
+					},
+					"show": map[string]any{
+						"type":        "string",
+						"description": "Show name written to the episode's album tag (default 'Podcaster')",
+					},
+					"cover": map[string]any{
+						"type":        "string",
+						"description": "https URL of a JPEG or PNG (max 10 MB) embedded in the episode as cover art (MP3 and AAC only)",
+					},
+					"output_format": map[string]any{
+						"type":        "string",
+						"description": "Episode audio format: mp3 (default), aac (.m4a), opus, or wav. audio_url points at a file of this type.",
+					},
+					"explicit": map[string]any{
+						"type":        "boolean",
+						"description": "Mark the episode explicit (true) or clean (false) in its tags and record. Omit to detect profanity and sexual content in the final script.",
+					},
+					"qc": map[string]any{
+						"type":        "string",
+						"description": "Quality check of the finished audio's loudness, true peak, and silence: warn (default) records problems in get_podcast's qc, fail fails the job on any, off skips the check.",
+					},
+					"verify_speakers": map[string]any{
+						"type":        "boolean",
+						"description": "Transcribe the finished episode with speaker diarization (Deepgram) and report segments spoken in the wrong host's voice in get_podcast's speaker_check. Adds Deepgram speech-to-text cost; needs a Deepgram key. Default false.",
+					},
 					"voice1": map[string]any{
 						"type":        "string",
-						"description": "Voice ID for host 1. Use list_voices to see available IDs. Format: plain ID (e.g. 'Kore') or 'provider:ID' for cross-provider mixing (e.g. 'elevenlabs:rachel').",
+						"description": "Voice ID for host 1. Use list_voices to see available IDs. Format: plain ID (e.g. 'Kore') or 'provider:ID' for cross-provider mixing (e.g. 'elevenlabs:rachel'). Append '@key=value,...' to give this host its own speed, stability, or pitch (e.g. 'elevenlabs:rachel@stability=0.3,speed=1.1'); these override tts_speed/tts_stability/tts_pitch.",
 					},
 					"voice2": map[string]any{
 						"type":        "string",
@@ -102,7 +158,7 @@ func ToolDefs() []mcp.Tool {
 					},
 					"tts_speed": map[string]any{
 						"type":        "number",
-						"description": "Speech speed (ElevenLabs: 0.7-1.2, Google: 0.25-2.0). Not supported by Gemini providers.",
+						"description": "Speech speed (ElevenLabs: 0.7-1.2, Google: 0.25-2.0; other providers 0.5-2.0, applied with FFmpeg after synthesis).",
 					},
 					"tts_stability": map[string]any{
 						"type":        "number",
@@ -110,7 +166,19 @@ func ToolDefs() []mcp.Tool {
 					},
 					"tts_pitch": map[string]any{
 						"type":        "number",
-						"description": "Pitch in semitones, Google Cloud TTS only (-20.0 to 20.0).",
+						"description": "Pitch in semitones (Google: -20.0 to 20.0; other providers -12.0 to 12.0, applied with FFmpeg after synthesis).",
+					},
+					"no_script_cache": map[string]any{
+						"type":        "boolean",
+						"description": "Always generate a fresh script. By default a script generated earlier from identical content and options is reused, which skips script generation cost.",
+					},
+					"preview": map[string]any{
+						"type":        "integer",
+						"description": "Synthesize only the first N script segments as a short sample, to check voices and tone before a full run. The full script is still written and returned at script_url.",
+					},
+					"resume_from": map[string]any{
+						"type":        "string",
+						"description": "podcast_id of a failed podcast whose get_podcast result has resumable: true. Reuses its script and finished audio and synthesizes only the missing segments; input_url/input_text are not needed. Pass the same tts and voice options as the original, or changed segments are re-synthesized.",
 					},
 					"anthropic_api_key": map[string]any{
 						"type":        "string",
@@ -124,6 +192,22 @@ func ToolDefs() []mcp.Tool {
 						"type":        "string",
 						"description": "Your ElevenLabs API key (required for elevenlabs TTS if server has no default key)",
 					},
+					"cartesia_api_key": map[string]any{
+						"type":        "string",
+						"description": "Your Cartesia API key (required for cartesia TTS if server has no default key)",
+					},
+					"vertex_express_api_key": map[string]any{
+						"type":        "string",
+						"description": "Your Vertex AI Express (Google Cloud) API key for vertex-express TTS",
+					},
+					"hume_api_key": map[string]any{
+						"type":        "string",
+						"description": "Your Hume AI API key (required for hume TTS if server has no default key)",
+					},
+					"deepgram_api_key": map[string]any{
+						"type":        "string",
+						"description": "Your Deepgram API key (required for deepgram TTS or verify_speakers if server has no default key)",
+					},
 				},
 			},
 		},
@@ -156,18 +240,26 @@ func ToolDefs() []mcp.Tool {
 						"type":        "string",
 						"description": "Pagination cursor from a previous list_podcasts call",
 					},
+					"deleted": map[string]any{
+						"type":        "boolean",
+						"description": "List your deleted podcasts (the trash) with their restore deadlines instead",
+					},
 				},
 			},
 		},
 		{
 			Name:        "list_voices",
-			Description: "List available TTS voices for a provider. Returns voice IDs that can be used with voice1/voice2/voice3 params in generate_podcast.",
+			Description: "List available TTS voices for a provider. Returns voice IDs that can be used with voice1/voice2/voice3 params in generate_podcast, with gender, language, accent, age, style tags, and (when available) a sample_url clip to listen to.",
 			InputSchema: mcp.ToolInputSchema{
 				Type: "object",
 				Properties: map[string]any{
 					"provider": map[string]any{
 						"type":        "string",
-						"description": "TTS provider name: gemini, vertex-express, gemini-vertex, elevenlabs, google, polly",
+						"description": "TTS provider name: gemini, vertex-express, gemini-vertex, elevenlabs, google, polly, cartesia, hume, deepgram",
+					},
+					"language": map[string]any{
+						"type":        "string",
+						"description": "Only return voices that speak this language, as a BCP 47 tag (e.g. 'es', 'pt-BR'). 'en' matches every English region. Multilingual voices (all Gemini voices) always match.",
 					},
 				},
 				Required: []string{"provider"},
@@ -181,19 +273,193 @@ func ToolDefs() []mcp.Tool {
 				Properties: map[string]any{},
 			},
 		},
+		{
+			Name:        "export_account",
+			Description: "Export everything stored about your account (profile, usage, API key metadata, podcasts) as a JSON file. Returns a download_url valid for 24 hours. Admins may pass user_id to export another user's account.",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]any{
+					"user_id": map[string]any{
+						"type":        "string",
+						"description": "Account to export (admins only). Defaults to the caller.",
+					},
+				},
+			},
+		},
+		{
+			Name:        "delete_account",
+			Description: "Permanently delete your account and all associated data: profile, usage records, API keys, podcasts, and their audio and script files. Returns a report with per-category counts and a verification result. Cannot be undone; offer export_account first. Admins may pass user_id to delete another user's account.",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]any{
+					"user_id": map[string]any{
+						"type":        "string",
+						"description": "Account to delete (admins only). Defaults to the caller.",
+					},
+					"confirm": map[string]any{
+						"type":        "string",
+						"description": "Must equal the user ID being deleted. Call without it to learn the expected value.",
+					},
+				},
+			},
+		},
+		{
+			Name:        "delete_podcast",
+			Description: "Delete one of your podcasts. It moves to the trash: hidden from listings, audio no longer served, and restorable with restore_podcast for 30 days, after which it is erased automatically. Use purge_podcast to erase it immediately.",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]any{
+					"podcast_id": map[string]any{
+						"type":        "string",
+						"description": "The podcast to delete",
+					},
+				},
+				Required: []string{"podcast_id"},
+			},
+		},
+		{
+			Name:        "restore_podcast",
+			Description: "Restore a deleted podcast from the trash, within 30 days of deletion, or retrieve an archived podcast's audio (get_podcast reports archive_status \"archived\"). Retrieval takes minutes to hours by tier; get_podcast shows when it's playable, after which it stays playable for 7 days. Use list_podcasts with deleted=true to find deleted podcasts.",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]any{
+					"podcast_id": map[string]any{
+						"type":        "string",
+						"description": "The deleted or archived podcast to restore",
+					},
+					"tier": map[string]any{
+						"type":        "string",
+						"enum":        []string{"expedited", "standard", "bulk"},
+						"description": "For archived audio, how fast to retrieve it: expedited (1-5 minutes), standard (3-5 hours, default), or bulk (5-12 hours, cheapest)",
+					},
+				},
+				Required: []string{"podcast_id"},
+			},
+		},
+		{
+			Name:        "purge_podcast",
+			Description: "Permanently erase a podcast that is already in the trash, including its audio and script files. Cannot be undone; only call this when the user explicitly asks for permanent deletion.",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]any{
+					"podcast_id": map[string]any{
+						"type":        "string",
+						"description": "The deleted podcast to erase",
+					},
+				},
+				Required: []string{"podcast_id"},
+			},
+		},
+		{
+			Name:        "search_transcripts",
+			Description: "Search the transcripts of your podcasts. Returns episodes containing every query word, best match first, each with up to 3 segment-level snippets (speaker, text, and an estimated timestamp into the audio).",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "Words to search for (all must appear in an episode)",
+					},
+					"limit": map[string]any{
+						"type":        "integer",
+						"description": "Maximum number of episodes (default 10)",
+						"default":     10,
+					},
+				},
+				Required: []string{"query"},
+			},
+		},
+		{
+			Name:        "compare_podcasts",
+			Description: "Compare two of your podcasts, typically generated from the same source with different settings. Reports which settings differ, duration and estimated cost deltas, a heuristic review score for each script (segment count, speaker balance, filler phrases), and a segment-level script diff.",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]any{
+					"podcast_id_a": map[string]any{
+						"type":        "string",
+						"description": "The baseline podcast",
+					},
+					"podcast_id_b": map[string]any{
+						"type":        "string",
+						"description": "The podcast to compare against the baseline",
+					},
+				},
+				Required: []string{"podcast_id_a", "podcast_id_b"},
+			},
+		},
+		{
+			Name:        "get_podcast_logs",
+			Description: "Tail the pipeline log of one of your podcasts while it generates (the detail a local --verbose run prints: stage timings, per-segment TTS, retries). Pass the returned next_cursor to get only newer lines; done is true once the job has finished and every line has been read. Logs are kept for 30 minutes after a job finishes.",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]any{
+					"podcast_id": map[string]any{
+						"type":        "string",
+						"description": "The podcast ID returned from generate_podcast",
+					},
+					"cursor": map[string]any{
+						"type":        "integer",
+						"description": "Line number to read from: 0 for the start, or next_cursor from a previous call",
+						"default":     0,
+					},
+					"limit": map[string]any{
+						"type":        "integer",
+						"description": "Maximum number of lines (default 200, max 1000)",
+						"default":     200,
+					},
+				},
+				Required: []string{"podcast_id"},
+			},
+		},
+		{
+			Name:        "recommend_voices",
+			Description: "Suggest a host 1 / host 2 voice pairing per TTS provider for a show, with a one-line rationale for each. Describe the show with format, tone, language, and a free-text vibe (e.g. 'energetic morning-show duo'). Each pairing includes generate_params (tts, voice1, voice2) to pass straight to generate_podcast, and sample_url links where voice samples are hosted.",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]any{
+					"format": map[string]any{
+						"type":        "string",
+						"description": "Script format the voices are for: conversation, interview, deep-dive, explainer, debate, news, storytelling, challenger",
+						"default":     "conversation",
+					},
+					"tone": map[string]any{
+						"type":        "string",
+						"description": "Conversation tone: casual, technical, educational",
+						"default":     "casual",
+					},
+					"language": map[string]any{
+						"type":        "string",
+						"description": "Only consider voices for this language (BCP 47 tag, e.g. 'es' or 'pt-BR')",
+					},
+					"vibe": map[string]any{
+						"type":        "string",
+						"description": "Free-text description of how the hosts should sound, e.g. 'energetic morning-show duo' or 'calm late-night storytellers'",
+					},
+					"providers": map[string]any{
+						"type":        "string",
+						"description": "Comma-separated TTS providers to recommend for (default: gemini, elevenlabs, google, polly, cartesia, hume, deepgram)",
+					},
+				},
+			},
+		},
 	}
 }
 
 // Handlers contains tool handler implementations.
 type Handlers struct {
-	tasks *TaskManager
-	store *Store
-	log   *slog.Logger
+	tasks   *TaskManager
+	store   *Store
+	storage *Storage
+	log     *slog.Logger
+
+	trialDailyLimit int  // 0 = no unauthenticated trials
+	requireAuth     bool // Config.RequireAuth
+	trustProxy      bool // Config.TrustProxy
 }
 
 // NewHandlers creates tool handlers.
 func NewHandlers(tasks *TaskManager, store *Store, logger *slog.Logger) *Handlers {
-	return &Handlers{tasks: tasks, store: store, log: logger}
+	return &Handlers{tasks: tasks, store: store, storage: tasks.storage, log: logger}
 }
 
 // HandleGeneratePodcast starts a podcast generation task.
@@ -213,27 +479,38 @@ func (h *Handlers) HandleGeneratePodcast(ctx context.Context, req mcp.CallToolRe
 		keyID = auth.KeyID
 	} else {
 		// Check for proxy-injected auth in arguments
-		args := req.GetArguments()
-		if uid, ok := args["_user_id"].(string); ok && uid != "" {
-			userID = uid
-		}
-		if kid, ok := args["_key_id"].(string); ok && kid != "" {
-			keyID = kid
-		}
+		userID = h.proxyArg(req, "_user_id")
+		keyID = h.proxyArg(req, "_key_id")
+	}
+
+	// Unauthenticated callers coming through the proxy's trial path carry a
+	// hashed IP instead of a user ID.
+	trialIPHash := ""
+	if userID == "" && h.trialDailyLimit > 0 {
+		trialIPHash = h.proxyArg(req, "_trial_ip_hash")
 	}
 
-	// Require auth when running on AWS (SECRET_PREFIX is set)
-	if userID == "" && os.Getenv("SECRET_PREFIX") != "" {
+	// Require auth when deployed (Config.RequireAuth)
+	if userID == "" && trialIPHash == "" && h.requireAuth {
 		if auth.Error != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v. Provide your API key as: Authorization: Bearer <your-api-key>. Get an API key at https://podcasts.apresai.dev", auth.Error)), nil
 		}
 		return mcp.NewToolResultError("Authentication required. Provide your API key as: Authorization: Bearer <your-api-key>. Get an API key at https://podcasts.apresai.dev"), nil
 	}
 
-	_ = keyID // used for logging if needed
 	owner := "anonymous"
 	if userID != "" {
 		owner = userID
+	} else if trialIPHash != "" {
+		owner = "trial"
+	}
+
+	if name := mcp.ParseString(req, "preset", ""); name != "" {
+		if err := applyPreset(&req, name); err != nil {
+			span.SetStatus(codes.Error, "invalid preset")
+			return toolError(err), nil
+		}
+		span.SetAttributes(attribute.String("preset", name))
 	}
 
 	genReq := GenerateRequest{
@@ -254,12 +531,37 @@ func (h *Handlers) HandleGeneratePodcast(ctx context.Context, req mcp.CallToolRe
 		TTSSpeed:         parseFloatParam(req, "tts_speed", 0),
 		TTSStability:     parseFloatParam(req, "tts_stability", 0),
 		TTSPitch:         parseFloatParam(req, "tts_pitch", 0),
+		NoScriptCache:    mcp.ParseBoolean(req, "no_script_cache", false),
 		AnthropicAPIKey:  mcp.ParseString(req, "anthropic_api_key", ""),
 		GeminiAPIKey:     mcp.ParseString(req, "gemini_api_key", ""),
 		ElevenLabsAPIKey: mcp.ParseString(req, "elevenlabs_api_key", ""),
+		CartesiaAPIKey:   mcp.ParseString(req, "cartesia_api_key", ""),
 		Owner:            owner,
 		UserID:           userID,
+		KeyID:            keyID,
+	}
+	genReq.VertexExpressAPIKey = mcp.ParseString(req, "vertex_express_api_key", "")
+	genReq.HumeAPIKey = mcp.ParseString(req, "hume_api_key", "")
+	genReq.DeepgramAPIKey = mcp.ParseString(req, "deepgram_api_key", "")
+	genReq.ResumeFrom = mcp.ParseString(req, "resume_from", "")
+	fetchHeaders, err := parseFetchHeaders(req)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid headers")
+		return toolError(errkind.Wrap(errkind.UserInput, "headers", err)), nil
 	}
+	genReq.FetchHeaders = fetchHeaders
+	genReq.Music = mcp.ParseString(req, "music", "")
+	genReq.Intro = mcp.ParseString(req, "intro", "")
+	genReq.Outro = mcp.ParseString(req, "outro", "")
+	genReq.Show = mcp.ParseString(req, "show", "")
+	genReq.Cover = mcp.ParseString(req, "cover", "")
+	genReq.OutputFormat = mcp.ParseString(req, "output_format", "")
+	if explicit, ok := req.GetArguments()["explicit"].(bool); ok {
+		genReq.Explicit = &explicit
+	}
+	genReq.QC = mcp.ParseString(req, "qc", "")
+	genReq.Preview = parseIntParam(req, "preview", 0)
+	genReq.VerifySpeakers = mcp.ParseBoolean(req, "verify_speakers", false)
 
 	span.SetAttributes(
 		attribute.String("input_url", genReq.InputURL),
@@ -270,27 +572,121 @@ func (h *Handlers) HandleGeneratePodcast(ctx context.Context, req mcp.CallToolRe
 		attribute.Int("voices", genReq.Voices),
 	)
 
-	if genReq.InputURL == "" && genReq.InputText == "" {
+	if genReq.Preview < 0 {
+		span.SetStatus(codes.Error, "invalid preview")
+		return toolError(errkind.New(errkind.UserInput, "preview must be a number of segments")), nil
+	}
+	if genReq.Preview > 0 && genReq.ResumeFrom != "" {
+		span.SetStatus(codes.Error, "invalid preview")
+		return toolError(errkind.New(errkind.UserInput, "preview can't be combined with resume_from")), nil
+	}
+	if genReq.ResumeFrom != "" {
+		if trialIPHash != "" {
+			span.SetStatus(codes.Error, "trial resume")
+			return mcp.NewToolResultError("resume_from is not available in the trial. Get an API key at https://podcasts.apresai.dev."), nil
+		}
+		item, msg, err := h.ownedPodcast(ctx, req, "resume_from")
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "get podcast failed")
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get podcast: %v", err)), nil
+		}
+		if msg == "" && (item.Status != string(JobStatusFailed) || item.PartialPrefix == "") {
+			msg = fmt.Sprintf("podcast %s has no partial audio to resume", genReq.ResumeFrom)
+		}
+		if msg != "" {
+			span.SetStatus(codes.Error, "invalid resume_from")
+			return toolError(errkind.New(errkind.UserInput, msg)), nil
+		}
+		genReq.InputURL, genReq.InputText = "", ""
+	} else if genReq.InputURL == "" && genReq.InputText == "" {
 		span.SetStatus(codes.Error, "missing input")
-		return mcp.NewToolResultError("either input_url or input_text is required"), nil
+		return toolError(errkind.New(errkind.UserInput, "either input_url or input_text is required")), nil
+	}
+
+	// Only built-in beds: a path would name a file on the server.
+	if genReq.Music != "" && !assembly.IsBuiltinMusic(genReq.Music) {
+		span.SetStatus(codes.Error, "invalid music")
+		return toolError(errkind.New(errkind.UserInput, fmt.Sprintf("unknown music bed %q: must be one of %s", genReq.Music, strings.Join(assembly.MusicBeds(), ", ")))), nil
+	}
+
+	for param, u := range map[string]string{"intro": genReq.Intro, "outro": genReq.Outro} {
+		if u == "" {
+			continue
+		}
+		if err := validateStingerURL(param, u); err != nil {
+			span.SetStatus(codes.Error, "invalid "+param)
+			return toolError(err), nil
+		}
+	}
+	if genReq.Cover != "" {
+		if _, err := validateCoverURL(genReq.Cover); err != nil {
+			span.SetStatus(codes.Error, "invalid cover")
+			return toolError(err), nil
+		}
+	}
+	if _, err := assembly.ParseFormat(genReq.OutputFormat); err != nil {
+		span.SetStatus(codes.Error, "invalid output_format")
+		return toolError(errkind.New(errkind.UserInput, err.Error())), nil
+	}
+	if err := pipeline.ValidateQCMode(genReq.QC); err != nil {
+		span.SetStatus(codes.Error, "invalid qc")
+		return toolError(errkind.New(errkind.UserInput, err.Error())), nil
+	}
+
+	defaultTTS := genReq.TTS
+	if defaultTTS == "" {
+		defaultTTS = "gemini"
+	}
+	for i, spec := range []string{genReq.Voice1, genReq.Voice2, genReq.Voice3} {
+		if _, _, _, err := voiceSpec(spec, defaultTTS); err != nil {
+			span.SetStatus(codes.Error, "invalid voice")
+			return toolError(errkind.Wrap(errkind.UserInput, fmt.Sprintf("voice%d", i+1), err)), nil
+		}
 	}
 
 	// Validate URL content synchronously before starting async task.
 	// This catches unfetchable URLs and insufficient content immediately,
 	// so the LLM client can ask the user for input_text or a different URL.
 	if genReq.InputURL != "" {
-		valCtx, valCancel := context.WithTimeout(ctx, 60*time.Second)
+		valCtx, valCancel := context.WithTimeout(ingest.ContextPublicOnly(ctx), 60*time.Second)
 		defer valCancel()
-		if err := ingest.ValidateURL(valCtx, genReq.InputURL); err != nil {
+		auth, err := ingest.NewAuth(genReq.FetchHeaders, "", []string{genReq.InputURL})
+		if err != nil {
+			span.SetStatus(codes.Error, "invalid headers")
+			return toolError(errkind.Wrap(errkind.UserInput, "headers", err)), nil
+		}
+		if err := ingest.ValidateURL(ingest.ContextWithAuth(valCtx, auth), genReq.InputURL); err != nil {
 			span.SetStatus(codes.Error, "url validation failed")
 			span.RecordError(err)
 			h.log.WarnContext(ctx, "URL validation failed", "url", genReq.InputURL, "error", err)
-			return mcp.NewToolResultError(fmt.Sprintf(
+			return toolError(errkind.New(errkind.UserInput, fmt.Sprintf(
 				"Could not use this URL for podcast generation. %v. "+
 					"Please provide the content directly using input_text, or try a different URL.",
 				err,
-			)), nil
+			))), nil
+		}
+	}
+
+	trialRemaining := -1
+	if trialIPHash != "" {
+		if msg := applyTrialLimits(req, &genReq); msg != "" {
+			span.SetStatus(codes.Error, "trial limits")
+			return mcp.NewToolResultError(msg), nil
+		}
+		remaining, err := h.store.ConsumeTrial(ctx, trialIPHash, h.trialDailyLimit)
+		if errors.Is(err, ErrTrialLimit) {
+			span.SetStatus(codes.Error, "trial limit reached")
+			return mcp.NewToolResultError(fmt.Sprintf("Free trial limit reached (%d per day). Get an API key at https://podcasts.apresai.dev to keep generating.", h.trialDailyLimit)), nil
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "trial check failed")
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to check trial limit: %v", err)), nil
 		}
+		trialRemaining = remaining
+		span.SetAttributes(attribute.Bool("trial", true))
+		h.log.InfoContext(ctx, "Trial generation", "trial_remaining", remaining)
 	}
 
 	h.log.InfoContext(ctx, "Starting podcast generation", "model", genReq.Model, "tts", genReq.TTS)
@@ -299,17 +695,28 @@ func (h *Handlers) HandleGeneratePodcast(ctx context.Context, req mcp.CallToolRe
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "start task failed")
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to start generation: %v", err)), nil
+		return toolError(err), nil
 	}
 
 	span.SetAttributes(attribute.String("podcast_id", id))
 	h.log.InfoContext(ctx, "Podcast generation started", "podcast_id", id)
 
+	// Per-key daily counts feed the usage monitor (see anomaly.go).
+	if keyID != "" {
+		if err := h.store.RecordKeyRequest(ctx, keyID, userID); err != nil {
+			h.log.WarnContext(ctx, "Record key usage failed", "key_id", keyID, "error", err)
+		}
+	}
+
 	result := map[string]any{
 		"podcast_id": id,
 		"status":     "submitted",
 		"message":    "Podcast generation started. Use get_podcast to check progress.",
 	}
+	if trialRemaining >= 0 {
+		result["trial"] = true
+		result["trial_remaining_today"] = trialRemaining
+	}
 	return jsonResult(result)
 }
 
@@ -357,6 +764,18 @@ func (h *Handlers) HandleGetPodcast(ctx context.Context, req mcp.CallToolRequest
 	if item.ScriptURL != "" {
 		result["script_url"] = item.ScriptURL
 	}
+	if item.TranscriptURL != "" {
+		result["transcript_url"] = item.TranscriptURL
+	}
+	if item.PageURL != "" {
+		result["page_url"] = item.PageURL
+	}
+	if item.PeaksURL != "" {
+		result["peaks_url"] = item.PeaksURL
+	}
+	if item.Status == string(JobStatusComplete) {
+		result["explicit"] = item.Explicit
+	}
 	if item.Duration != "" {
 		result["duration"] = item.Duration
 	}
@@ -366,6 +785,19 @@ func (h *Handlers) HandleGetPodcast(ctx context.Context, req mcp.CallToolRequest
 	if item.ErrorMessage != "" {
 		result["error"] = item.ErrorMessage
 	}
+	if item.ErrorKind != "" {
+		result["error_kind"] = item.ErrorKind
+		result["error_status"] = errkind.Kind(item.ErrorKind).HTTPStatus()
+	}
+	if item.SegmentsTotal > 0 {
+		result["segments_done"] = item.SegmentsDone
+		result["segments_total"] = item.SegmentsTotal
+		result["missing_segments"] = pipeline.SegmentRanges(item.MissingSegments)
+		if item.PartialPrefix != "" && item.Status == string(JobStatusFailed) {
+			result["resumable"] = true
+			result["resume_hint"] = fmt.Sprintf("Call generate_podcast with resume_from=%q and the same tts and voice options to synthesize only the missing segments.", item.PodcastID)
+		}
+	}
 	if item.Model != "" {
 		result["model"] = item.Model
 	}
@@ -378,6 +810,49 @@ func (h *Handlers) HandleGetPodcast(ctx context.Context, req mcp.CallToolRequest
 	if item.PlayCount > 0 {
 		result["play_count"] = item.PlayCount
 	}
+	if item.CLICommand != "" {
+		result["cli_command"] = item.CLICommand
+	}
+	if n, err := strconv.Atoi(item.Settings["preview"]); err == nil && n > 0 {
+		result["preview"] = n
+	}
+	if item.ScriptEscalation != "" {
+		var esc pipeline.ScriptEscalation
+		if json.Unmarshal([]byte(item.ScriptEscalation), &esc) == nil {
+			result["script_escalation"] = esc
+		}
+	}
+	if item.StageTimings != "" {
+		var timings []pipeline.StageTiming
+		if json.Unmarshal([]byte(item.StageTimings), &timings) == nil {
+			result["stage_timings"] = timings
+		}
+	}
+	if item.SpeakerCheck != "" {
+		var check diarize.Report
+		if json.Unmarshal([]byte(item.SpeakerCheck), &check) == nil {
+			result["speaker_check"] = check
+			result["speaker_check_passed"] = check.Passed()
+		}
+	}
+	if item.QCReport != "" {
+		var qc assembly.QCReport
+		if json.Unmarshal([]byte(item.QCReport), &qc) == nil {
+			result["qc"] = qc
+			result["qc_passed"] = qc.Passed()
+		}
+	}
+	if item.DeletedAt != "" {
+		// Files live under trash/ until restored.
+		delete(result, "audio_url")
+		delete(result, "script_url")
+		delete(result, "transcript_url")
+		delete(result, "page_url")
+		delete(result, "peaks_url")
+		result["deleted_at"] = item.DeletedAt
+		result["restore_until"] = restoreDeadline(item).Format(time.RFC3339)
+	}
+	h.addArchiveState(ctx, item, result)
 
 	return jsonResult(result)
 }
@@ -387,6 +862,10 @@ func (h *Handlers) HandleListPodcasts(ctx context.Context, req mcp.CallToolReque
 	ctx, span := tracer.Start(ctx, "tool.list_podcasts")
 	defer span.End()
 
+	if mcp.ParseBoolean(req, "deleted", false) {
+		return h.listDeletedPodcasts(ctx, req)
+	}
+
 	limit := parseIntParam(req, "limit", 20)
 	cursor := mcp.ParseString(req, "cursor", "")
 
@@ -420,6 +899,18 @@ func (h *Handlers) HandleListPodcasts(ctx context.Context, req mcp.CallToolReque
 		if item.ScriptURL != "" {
 			p["script_url"] = item.ScriptURL
 		}
+		if item.TranscriptURL != "" {
+			p["transcript_url"] = item.TranscriptURL
+		}
+		if item.PageURL != "" {
+			p["page_url"] = item.PageURL
+		}
+		if item.PeaksURL != "" {
+			p["peaks_url"] = item.PeaksURL
+		}
+		if item.Explicit {
+			p["explicit"] = true
+		}
 		if item.Duration != "" {
 			p["duration"] = item.Duration
 		}
@@ -489,9 +980,26 @@ func (h *Handlers) HandleServerInfo(ctx context.Context, req mcp.CallToolRequest
 		"env_vars":      otelVars,
 		"otel_ports":    portStatus,
 	}
+	if h.store.capacity != nil {
+		result["dynamodb"] = h.store.capacity.stats()
+	}
 	return jsonResult(result)
 }
 
+// toolError is the tool result for a classified failure. The text is
+// prefixed with its kind ("user_input: ...") and the structured content
+// carries the kind and matching HTTP status, so clients can tell bad input
+// from a provider outage without parsing the message.
+func toolError(err error) *mcp.CallToolResult {
+	kind := errkind.Of(err)
+	res := mcp.NewToolResultError(errkind.Format(err))
+	res.StructuredContent = map[string]any{
+		"error_kind":   kind,
+		"error_status": kind.HTTPStatus(),
+	}
+	return res
+}
+
 func jsonResult(v any) (*mcp.CallToolResult, error) {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -500,6 +1008,33 @@ func jsonResult(v any) (*mcp.CallToolResult, error) {
 	return mcp.NewToolResultText(string(data)), nil
 }
 
+// parseFetchHeaders returns the headers and cookies params as "Name: value"
+// headers (pipeline.Options.FetchHeaders).
+func parseFetchHeaders(req mcp.CallToolRequest) ([]string, error) {
+	var headers []string
+	switch raw := req.GetArguments()["headers"].(type) {
+	case nil:
+	case map[string]any:
+		for name, v := range raw {
+			value, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("header %s: value must be a string", name)
+			}
+			headers = append(headers, name+": "+value)
+		}
+		sort.Strings(headers)
+	default:
+		return nil, fmt.Errorf("must be an object of header names to values")
+	}
+	if cookies := mcp.ParseString(req, "cookies", ""); cookies != "" {
+		headers = append(headers, "Cookie: "+cookies)
+	}
+	if _, err := ingest.ParseHeaders(headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
 func parseIntParam(req mcp.CallToolRequest, key string, defaultVal int) int {
 	args := req.GetArguments()
 	if args == nil {
@@ -547,8 +1082,10 @@ func (h *Handlers) HandleListVoices(ctx context.Context, req mcp.CallToolRequest
 
 	voices, err := tts.AvailableVoices(provider)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("unknown provider %q: must be gemini, vertex-express, gemini-vertex, elevenlabs, google, or polly", provider)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("unknown provider %q: must be gemini, vertex-express, gemini-vertex, elevenlabs, google, polly, cartesia, hume, or deepgram", provider)), nil
 	}
+	language := mcp.ParseString(req, "language", "")
+	voices = tts.FilterVoicesByLanguage(voices, language)
 
 	voiceList := make([]map[string]any, 0, len(voices))
 	for _, v := range voices {
@@ -557,10 +1094,23 @@ func (h *Handlers) HandleListVoices(ctx context.Context, req mcp.CallToolRequest
 			"name":        v.Name,
 			"gender":      v.Gender,
 			"description": v.Description,
+			"language":    v.Language,
 		}
 		if v.DefaultFor != "" {
 			entry["default_for"] = v.DefaultFor
 		}
+		if v.Accent != "" {
+			entry["accent"] = v.Accent
+		}
+		if v.Age != "" {
+			entry["age"] = v.Age
+		}
+		if len(v.Tags) > 0 {
+			entry["tags"] = v.Tags
+		}
+		if v.SampleURL != "" {
+			entry["sample_url"] = v.SampleURL
+		}
 		voiceList = append(voiceList, entry)
 	}
 
@@ -569,6 +1119,9 @@ func (h *Handlers) HandleListVoices(ctx context.Context, req mcp.CallToolRequest
 		"voices":   voiceList,
 		"count":    len(voiceList),
 	}
+	if language != "" {
+		result["language"] = language
+	}
 	return jsonResult(result)
 }
 
@@ -599,6 +1152,9 @@ func (h *Handlers) HandleListOptions(ctx context.Context, req mcp.CallToolReques
 			{"name": "elevenlabs", "auth": "API key (ELEVENLABS_API_KEY)", "rate_limit": "Varies by plan", "voices": "10+ ElevenLabs voices"},
 			{"name": "google", "auth": "GCP ADC/service account", "rate_limit": "150 RPM", "voices": "8 Chirp 3 HD voices"},
 			{"name": "polly", "auth": "AWS default credentials", "rate_limit": "Standard AWS limits", "voices": "7 Generative voices"},
+			{"name": "cartesia", "auth": "API key (CARTESIA_API_KEY)", "rate_limit": "Varies by plan (concurrency-based)", "voices": "Sonic voice library"},
+			{"name": "hume", "auth": "API key (HUME_API_KEY)", "rate_limit": "Varies by plan", "voices": "Octave voice library; acts out delivery hints (good for storytelling)"},
+			{"name": "deepgram", "auth": "API key (DEEPGRAM_API_KEY)", "rate_limit": "Varies by plan (concurrency-based)", "voices": "Aura 2 English voices; lowest cost per character for long episodes"},
 		},
 		"models": []map[string]any{
 			{"name": "haiku", "provider": "Anthropic", "description": "Claude Haiku 4.5 (fastest, default)"},
@@ -613,6 +1169,13 @@ func (h *Handlers) HandleListOptions(ctx context.Context, req mcp.CallToolReques
 			{"name": "long", "description": "~15 minutes, ~65 segments"},
 			{"name": "deep", "description": "~30-35 minutes, ~150 segments"},
 		},
+		"music":          assembly.MusicBeds(),
+		"output_formats": assembly.OutputFormats(),
+	}
+	var presetList []map[string]any
+	for _, name := range presetNames() {
+		presetList = append(presetList, map[string]any{"name": name, "description": presets[name].Description, "options": presets[name].Args})
 	}
+	result["presets"] = presetList
 	return jsonResult(result)
 }
@@ -0,0 +1,424 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Podcast soft delete. delete_podcast moves a podcast to the trash instead
+// of erasing it:
+//
+//   - the item moves from the owner's GSI1 partition to USER#<id>#TRASH and
+//     drops its GSI2 keys, so every listing (MCP and portal) stops showing it
+//   - the item gets a ttl of deletedAt + podcastRestoreWindow
+//   - its transcript leaves the search index, which the ttl wouldn't reach
+//   - audio/, scripts/, transcripts/, and pages/ objects move under trash/, which
+//     the CDN doesn't serve and a lifecycle rule expires a day after the
+//     restore window
+//
+// restore_podcast reverses all four within the window; purge_podcast erases
+// a trashed podcast immediately. Each step is idempotent, so a call that
+// fails partway can be repeated.
+
+// podcastRestoreWindow is how long a deleted podcast can be restored.
+const podcastRestoreWindow = 30 * 24 * time.Hour
+
+// trashPrefix is where a deleted podcast's S3 objects wait out the window.
+const trashPrefix = "trash/"
+
+// podcastsGSI1PK is the GSI1 partition listing a user's podcasts.
+func podcastsGSI1PK(userID string) string {
+	if userID == "" {
+		return "PODCASTS"
+	}
+	return "USER#" + userID + "#PODCASTS"
+}
+
+// trashGSI1PK is the GSI1 partition listing a user's deleted podcasts.
+func trashGSI1PK(userID string) string {
+	if userID == "" {
+		return "TRASH"
+	}
+	return "USER#" + userID + "#TRASH"
+}
+
+// restoreDeadline returns when a deleted podcast stops being restorable.
+func restoreDeadline(item *PodcastItem) time.Time {
+	deletedAt, err := time.Parse(time.RFC3339, item.DeletedAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return deletedAt.Add(podcastRestoreWindow)
+}
+
+// TrashPodcast marks a podcast deleted and hides it from listings.
+func (s *Store) TrashPodcast(ctx context.Context, item *PodcastItem, at time.Time) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "PODCAST#" + item.PodcastID},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		UpdateExpression:    aws.String("SET deletedAt = :at, #ttl = :ttl, GSI1PK = :trash REMOVE GSI2PK, GSI2SK"),
+		ConditionExpression: aws.String("attribute_exists(PK) AND attribute_not_exists(deletedAt)"),
+		ExpressionAttributeNames: map[string]string{
+			"#ttl": "ttl",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":at":    &types.AttributeValueMemberS{Value: at.UTC().Format(time.RFC3339)},
+			":ttl":   &types.AttributeValueMemberN{Value: strconv.FormatInt(at.Add(podcastRestoreWindow).Unix(), 10)},
+			":trash": &types.AttributeValueMemberS{Value: trashGSI1PK(item.UserID)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("trash podcast: %w", err)
+	}
+	return nil
+}
+
+// RestorePodcast clears a podcast's deleted state and puts it back in
+// listings at its original position.
+func (s *Store) RestorePodcast(ctx context.Context, item *PodcastItem) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "PODCAST#" + item.PodcastID},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		UpdateExpression:    aws.String("SET GSI1PK = :pk, GSI2PK = :all, GSI2SK = :sk REMOVE deletedAt, #ttl"),
+		ConditionExpression: aws.String("attribute_exists(deletedAt)"),
+		ExpressionAttributeNames: map[string]string{
+			"#ttl": "ttl",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":  &types.AttributeValueMemberS{Value: podcastsGSI1PK(item.UserID)},
+			":all": &types.AttributeValueMemberS{Value: "PODCASTS"},
+			":sk":  &types.AttributeValueMemberS{Value: item.GSI1SK},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("restore podcast: %w", err)
+	}
+	return nil
+}
+
+// PurgePodcast deletes a trashed podcast's record. Podcasts that aren't in
+// the trash are left alone.
+func (s *Store) PurgePodcast(ctx context.Context, id string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "PODCAST#" + id},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		ConditionExpression: aws.String("attribute_exists(deletedAt)"),
+	})
+	if err != nil {
+		return fmt.Errorf("purge podcast: %w", err)
+	}
+	return nil
+}
+
+// ListDeletedPodcasts returns a user's trashed podcasts, most recently
+// created first.
+func (s *Store) ListDeletedPodcasts(ctx context.Context, userID string) ([]PodcastItem, error) {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		IndexName:              aws.String("GSI1"),
+		KeyConditionExpression: aws.String("GSI1PK = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: trashGSI1PK(userID)},
+		},
+		ScanIndexForward: aws.Bool(false),
+	})
+
+	var items []PodcastItem
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list deleted podcasts: %w", err)
+		}
+		var pageItems []PodcastItem
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &pageItems); err != nil {
+			return nil, fmt.Errorf("unmarshal deleted podcasts: %w", err)
+		}
+		items = append(items, pageItems...)
+	}
+	return items, nil
+}
+
+// ownedPodcast loads a podcast the caller may delete, restore, purge, or
+// compare: their own, or any podcast for admins. Without auth (local runs)
+// only anonymous podcasts qualify. param names the request argument holding
+// the ID. Returns a user-facing error message otherwise.
+func (h *Handlers) ownedPodcast(ctx context.Context, req mcp.CallToolRequest, param string) (*PodcastItem, string, error) {
+	id := mcp.ParseString(req, param, "")
+	if id == "" {
+		return nil, param + " is required", nil
+	}
+
+	callerID, role := h.caller(ctx, req)
+	if callerID == "" && h.requireAuth {
+		return nil, "Authentication required. Provide your API key as: Authorization: Bearer <your-api-key>.", nil
+	}
+
+	item, err := h.store.GetPodcast(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	// Someone else's podcast reads as missing rather than forbidden.
+	if item == nil || (role != "admin" && item.UserID != callerID) {
+		return nil, fmt.Sprintf("podcast %s not found", id), nil
+	}
+	return item, "", nil
+}
+
+// moveObjects moves each key from one prefix to another, continuing past
+// failures and returning them joined.
+func (h *Handlers) moveObjects(ctx context.Context, keys []string, from, to string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := h.storage.Move(ctx, from+key, to+key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HandleDeletePodcast moves a podcast to the trash.
+func (h *Handlers) HandleDeletePodcast(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, span := tracer.Start(ctx, "tool.delete_podcast")
+	defer span.End()
+
+	item, msg, err := h.ownedPodcast(ctx, req, "podcast_id")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "get podcast failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get podcast: %v", err)), nil
+	}
+	if msg != "" {
+		span.SetStatus(codes.Error, "not allowed")
+		return mcp.NewToolResultError(msg), nil
+	}
+	span.SetAttributes(attribute.String("podcast_id", item.PodcastID))
+
+	if item.Status != string(JobStatusComplete) && item.Status != string(JobStatusFailed) {
+		span.SetStatus(codes.Error, "still generating")
+		return mcp.NewToolResultError(fmt.Sprintf("podcast %s is still generating (%s). Delete it once it completes or fails.", item.PodcastID, item.Status)), nil
+	}
+
+	// S3 can't copy archived audio to trash/ until it's restored.
+	if h.mayBeArchived(item) {
+		if st, err := h.storage.ArchiveState(ctx, item.AudioKey); err == nil && (st.Status == archiveArchived || st.Status == archiveRestoring) {
+			span.SetStatus(codes.Error, "archived")
+			return mcp.NewToolResultError(fmt.Sprintf("podcast %s's audio is archived (%s). Restore it with restore_podcast, then delete it once get_podcast shows it restored.", item.PodcastID, st.Status)), nil
+		}
+	}
+
+	// A repeat call on a trashed podcast just finishes moving its files.
+	if item.DeletedAt == "" {
+		now := time.Now().UTC()
+		if err := h.store.TrashPodcast(ctx, item, now); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "trash failed")
+			return mcp.NewToolResultError(fmt.Sprintf("failed to delete podcast: %v", err)), nil
+		}
+		item.DeletedAt = now.Format(time.RFC3339)
+	}
+
+	if err := h.moveObjects(ctx, podcastKeys(*item), "", trashPrefix); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "move to trash failed")
+		h.log.Error("Move podcast files to trash failed", "podcast_id", item.PodcastID, "error", err)
+		return mcp.NewToolResultError(fmt.Sprintf("Podcast deleted, but some files could not be moved to the trash: %v. Call delete_podcast again to retry.", err)), nil
+	}
+	if sc, ok := item.script(); ok && item.UserID != "" {
+		if err := h.store.RemoveTranscript(ctx, item.UserID, item.PodcastID, sc); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "unindex failed")
+			return mcp.NewToolResultError(fmt.Sprintf("Podcast deleted, but it could not be removed from search: %v. Call delete_podcast again to retry.", err)), nil
+		}
+	}
+
+	h.log.Info("Podcast moved to trash", "podcast_id", item.PodcastID, "user_id", item.UserID)
+
+	restoreUntil := restoreDeadline(item).Format(time.RFC3339)
+	return jsonResult(map[string]any{
+		"podcast_id":    item.PodcastID,
+		"deleted_at":    item.DeletedAt,
+		"restore_until": restoreUntil,
+		"message":       "Podcast moved to the trash. Use restore_podcast to undo before " + restoreUntil + ", or purge_podcast to delete it permanently now.",
+	})
+}
+
+// HandleRestorePodcast brings a trashed podcast back, or starts retrieving
+// an archived one's audio (archive.go).
+func (h *Handlers) HandleRestorePodcast(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, span := tracer.Start(ctx, "tool.restore_podcast")
+	defer span.End()
+
+	item, msg, err := h.ownedPodcast(ctx, req, "podcast_id")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "get podcast failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get podcast: %v", err)), nil
+	}
+	if msg != "" {
+		span.SetStatus(codes.Error, "not allowed")
+		return mcp.NewToolResultError(msg), nil
+	}
+	span.SetAttributes(attribute.String("podcast_id", item.PodcastID))
+
+	if item.DeletedAt == "" {
+		return h.restoreArchived(ctx, req, item)
+	}
+	// The record lingers until DynamoDB's TTL sweep; the files may not.
+	if time.Now().After(restoreDeadline(item)) {
+		span.SetStatus(codes.Error, "restore window ended")
+		return mcp.NewToolResultError(fmt.Sprintf("podcast %s can no longer be restored: the %d-day restore window has ended", item.PodcastID, int(podcastRestoreWindow.Hours()/24))), nil
+	}
+
+	// Files first, so a restored record never points at missing audio.
+	if err := h.moveObjects(ctx, podcastKeys(*item), trashPrefix, ""); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "move from trash failed")
+		h.log.Error("Move podcast files from trash failed", "podcast_id", item.PodcastID, "error", err)
+		return mcp.NewToolResultError(fmt.Sprintf("failed to restore podcast files: %v. Call restore_podcast again to retry.", err)), nil
+	}
+	if sc, ok := item.script(); ok && item.UserID != "" {
+		if err := h.store.IndexTranscript(ctx, item.UserID, item.PodcastID, sc); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "index failed")
+			return mcp.NewToolResultError(fmt.Sprintf("failed to add podcast back to search: %v. Call restore_podcast again to retry.", err)), nil
+		}
+	}
+	if err := h.store.RestorePodcast(ctx, item); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "restore failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to restore podcast: %v", err)), nil
+	}
+
+	h.log.Info("Podcast restored", "podcast_id", item.PodcastID, "user_id", item.UserID)
+
+	result := map[string]any{
+		"podcast_id": item.PodcastID,
+		"status":     item.Status,
+		"message":    "Podcast restored.",
+	}
+	if item.AudioURL != "" {
+		result["audio_url"] = item.AudioURL
+	}
+	return jsonResult(result)
+}
+
+// HandlePurgePodcast permanently deletes a trashed podcast and its files.
+func (h *Handlers) HandlePurgePodcast(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, span := tracer.Start(ctx, "tool.purge_podcast")
+	defer span.End()
+
+	item, msg, err := h.ownedPodcast(ctx, req, "podcast_id")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "get podcast failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get podcast: %v", err)), nil
+	}
+	if msg != "" {
+		span.SetStatus(codes.Error, "not allowed")
+		return mcp.NewToolResultError(msg), nil
+	}
+	span.SetAttributes(attribute.String("podcast_id", item.PodcastID))
+
+	if item.DeletedAt == "" {
+		span.SetStatus(codes.Error, "not deleted")
+		return mcp.NewToolResultError(fmt.Sprintf("podcast %s is not in the trash. Call delete_podcast first.", item.PodcastID)), nil
+	}
+
+	// Delete both locations in case an earlier move stopped partway.
+	var errs []string
+	for _, key := range podcastObjectKeys([]PodcastItem{*item}) {
+		if err := h.storage.Delete(ctx, key); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	for _, prefix := range podcastPrefixes(*item) {
+		if _, err := h.storage.DeletePrefix(ctx, prefix); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		span.SetStatus(codes.Error, "delete files failed")
+		h.log.Error("Purge podcast files failed", "podcast_id", item.PodcastID, "errors", errs)
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete podcast files: %s. Call purge_podcast again to retry.", strings.Join(errs, "; "))), nil
+	}
+	if item.PartialPrefix != "" {
+		if err := h.store.ClearPartial(ctx, item.PodcastID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "clear partial failed")
+			return mcp.NewToolResultError(fmt.Sprintf("failed to clear partial results: %v. Call purge_podcast again to retry.", err)), nil
+		}
+	}
+	if sc, ok := item.script(); ok && item.UserID != "" {
+		if err := h.store.RemoveTranscript(ctx, item.UserID, item.PodcastID, sc); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "unindex failed")
+			return mcp.NewToolResultError(fmt.Sprintf("failed to remove podcast from search: %v. Call purge_podcast again to retry.", err)), nil
+		}
+	}
+	if err := h.store.PurgePodcast(ctx, item.PodcastID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "purge failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to purge podcast: %v", err)), nil
+	}
+
+	h.log.Info("Podcast purged", "podcast_id", item.PodcastID, "user_id", item.UserID)
+
+	return jsonResult(map[string]any{
+		"podcast_id": item.PodcastID,
+		"message":    "Podcast permanently deleted.",
+	})
+}
+
+// listDeletedPodcasts serves list_podcasts with deleted=true: the caller's
+// trash, with each podcast's restore deadline.
+func (h *Handlers) listDeletedPodcasts(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	callerID, _ := h.caller(ctx, req)
+	if callerID == "" && h.requireAuth {
+		return mcp.NewToolResultError("Authentication required. Provide your API key as: Authorization: Bearer <your-api-key>."), nil
+	}
+
+	items, err := h.store.ListDeletedPodcasts(ctx, callerID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list deleted podcasts: %v", err)), nil
+	}
+
+	podcasts := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		p := map[string]any{
+			"podcast_id":    item.PodcastID,
+			"status":        item.Status,
+			"created_at":    item.CreatedAt,
+			"deleted_at":    item.DeletedAt,
+			"restore_until": restoreDeadline(&item).Format(time.RFC3339),
+		}
+		if item.Title != "" {
+			p["title"] = item.Title
+		}
+		podcasts = append(podcasts, p)
+	}
+	return jsonResult(map[string]any{
+		"podcasts": podcasts,
+		"count":    len(podcasts),
+	})
+}
@@ -0,0 +1,113 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Trial mode lets prospective users try generate_podcast without an API key.
+// The proxy admits unauthenticated requests when TRIAL_ENABLED is set and
+// injects _trial_ip_hash (a salted SHA-256 of the caller's IP) into tool
+// arguments; the raw IP never reaches the server. Trial episodes are limited
+// to short, cheap configurations and end with a spoken disclaimer.
+
+// trialDisclaimer is appended as the final spoken line of every trial episode.
+const trialDisclaimer = "This episode was generated with a free trial of Podcaster. Get an API key at podcasts dot apres A I dot dev to create full-length episodes."
+
+// trialAllowedModels and trialAllowedTTS restrict trials to low-cost providers.
+var (
+	trialAllowedModels = map[string]bool{"haiku": true, "gemini-flash": true}
+	trialAllowedTTS    = map[string]bool{"gemini": true, "gemini-vertex": true, "vertex-express": true, "google": true, "polly": true}
+)
+
+// TrialRecord is the per-IP daily trial counter.
+// PK=TRIAL#{ipHash}, SK=DAY#{YYYY-MM-DD}; expires via the table TTL.
+type TrialRecord struct {
+	PK    string `dynamodbav:"PK"`
+	SK    string `dynamodbav:"SK"`
+	Count int    `dynamodbav:"count"`
+	TTL   int64  `dynamodbav:"ttl"`
+}
+
+// ErrTrialLimit is returned when an IP has used all of today's trial episodes.
+var ErrTrialLimit = errors.New("trial limit reached")
+
+// ConsumeTrial atomically counts one trial generation for ipHash today.
+// Returns the number of trials remaining after this one, or ErrTrialLimit.
+func (s *Store) ConsumeTrial(ctx context.Context, ipHash string, limit int) (int, error) {
+	now := time.Now().UTC()
+	out, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "TRIAL#" + ipHash},
+			"SK": &types.AttributeValueMemberS{Value: "DAY#" + now.Format("2006-01-02")},
+		},
+		UpdateExpression:    aws.String("ADD #count :one SET #ttl = :ttl"),
+		ConditionExpression: aws.String("attribute_not_exists(#count) OR #count < :limit"),
+		ExpressionAttributeNames: map[string]string{
+			"#count": "count",
+			"#ttl":   "ttl",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one":   &types.AttributeValueMemberN{Value: "1"},
+			":limit": &types.AttributeValueMemberN{Value: strconv.Itoa(limit)},
+			":ttl":   &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(48*time.Hour).Unix(), 10)},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return 0, ErrTrialLimit
+		}
+		return 0, fmt.Errorf("consume trial: %w", err)
+	}
+
+	used := limit
+	if n, ok := out.Attributes["count"].(*types.AttributeValueMemberN); ok {
+		if v, err := strconv.Atoi(n.Value); err == nil {
+			used = v
+		}
+	}
+	return limit - used, nil
+}
+
+// applyTrialLimits validates a trial request against the trial tier and
+// pins the settings it doesn't let callers choose. Returns a user-facing
+// message when the request is outside the tier.
+func applyTrialLimits(req mcp.CallToolRequest, genReq *GenerateRequest) string {
+	args := req.GetArguments()
+
+	if d, ok := args["duration"].(string); ok && d != "" && d != "short" {
+		return "Trial episodes are limited to duration \"short\". Get an API key at https://podcasts.apresai.dev for longer episodes."
+	}
+	genReq.Duration = "short"
+
+	if !trialAllowedModels[genReq.Model] {
+		return fmt.Sprintf("Model %q is not available in the trial. Use haiku or gemini-flash, or get an API key at https://podcasts.apresai.dev.", genReq.Model)
+	}
+	if !trialAllowedTTS[genReq.TTS] {
+		return fmt.Sprintf("TTS provider %q is not available in the trial. Use gemini, gemini-vertex, vertex-express, google, or polly, or get an API key at https://podcasts.apresai.dev.", genReq.TTS)
+	}
+	if genReq.Voices > 2 {
+		return "Trial episodes support at most 2 voices."
+	}
+
+	// Trials always run on server keys and the default voices.
+	genReq.Voice1, genReq.Voice2, genReq.Voice3 = "", "", ""
+	genReq.AnthropicAPIKey, genReq.GeminiAPIKey, genReq.ElevenLabsAPIKey = "", "", ""
+	genReq.CartesiaAPIKey, genReq.VertexExpressAPIKey, genReq.HumeAPIKey = "", "", ""
+	genReq.DeepgramAPIKey = ""
+	// The speaker check's transcription is a paid extra.
+	genReq.VerifySpeakers = false
+	genReq.Trial = true
+	return ""
+}
@@ -0,0 +1,59 @@
+package mcpserver
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/apresai/podcaster/internal/tts"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// warmupTimeout bounds the whole warm-up; anything slower is left to the
+// first job.
+const warmupTimeout = 30 * time.Second
+
+// warmUp does at startup, in the background, the setup the first job would
+// otherwise pay for: resolving AWS credentials, opening the DynamoDB
+// connection, and initializing the shared TTS clients and tokens (see
+// tts.Warm). Failures are logged and otherwise ignored.
+func warmUp(ctx context.Context, awsCfg aws.Config, store *Store, providers []string, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+	defer cancel()
+	start := time.Now()
+
+	step := func(name string, fn func() error) {
+		stepStart := time.Now()
+		if err := fn(); err != nil {
+			logger.Warn("Warm-up step failed", "step", name, "error", err)
+			return
+		}
+		logger.Info("Warm-up step done", "step", name, "elapsed", time.Since(stepStart).Round(time.Millisecond).String())
+	}
+
+	step("aws-credentials", func() error {
+		_, err := awsCfg.Credentials.Retrieve(ctx)
+		return err
+	})
+	step("dynamodb", func() error { return store.ping(ctx) })
+	if len(providers) > 0 {
+		step("tts", func() error { return tts.Warm(ctx, providers...) })
+	}
+
+	logger.Info("Warm-up complete", "elapsed", time.Since(start).Round(time.Millisecond).String())
+}
+
+// ping reads a key that never exists, which opens and authenticates the
+// client's connection to the table without touching real data.
+func (s *Store) ping(ctx context.Context) error {
+	_, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "WARMUP"},
+			"SK": &types.AttributeValueMemberS{Value: "WARMUP"},
+		},
+	})
+	return err
+}
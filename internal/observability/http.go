@@ -0,0 +1,44 @@
+package observability
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Transport wraps base (http.DefaultTransport if nil) so every request made
+// with a traced context gets a client span, named for its method and host,
+// under the caller's span: a provider call shows up inside the pipeline
+// stage that made it. Outbound requests go to third parties, so no trace
+// headers are sent with them. Spans record the full URL, query included,
+// so API keys must go in headers (Google's x-goog-api-key), never in the
+// query. Without a tracer provider (the CLI) the spans are no-ops.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base,
+		otelhttp.WithPropagators(propagation.NewCompositeTextMapPropagator()),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Host
+		}),
+	)
+}
+
+// Handler wraps an HTTP handler so each request gets a server span,
+// continuing the caller's trace when the request carries a W3C traceparent
+// (the proxy's, through AgentCore). Requests for the paths in skip
+// (health checks) aren't traced.
+func Handler(h http.Handler, operation string, skip ...string) http.Handler {
+	return otelhttp.NewHandler(h, operation,
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			for _, p := range skip {
+				if r.URL.Path == p {
+					return false
+				}
+			}
+			return true
+		}),
+	)
+}
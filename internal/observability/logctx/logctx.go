@@ -0,0 +1,28 @@
+// Package logctx carries a request-scoped slog.Logger in a context, so
+// code deep in the pipeline (FFmpeg runs, for example) logs with the
+// caller's fields, such as podcast_id, without threading a logger through
+// every signature. It is separate from observability so that the CLI
+// doesn't link the CloudWatch and OTLP exporters.
+package logctx
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerKey struct{}
+
+// With returns a copy of ctx that carries logger.
+func With(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// From returns the logger carried by ctx, or slog.Default() if there is
+// none. Log with the *Context methods so handlers also see ctx (the
+// observability trace handler adds trace and span IDs from it).
+func From(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
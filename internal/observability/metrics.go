@@ -0,0 +1,40 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// metricInterval is how often metrics are exported. Jobs run for minutes,
+// so a minute is fine-grained enough.
+const metricInterval = time.Minute
+
+// InitMeter sets up an OTEL MeterProvider with an OTLP gRPC exporter, read
+// from the same OTEL_EXPORTER_OTLP_* env vars as InitTracer. Instruments
+// created earlier from otel.Meter (e.g. the TTS request metrics) start
+// exporting once it is set. Caller must defer mp.Shutdown(ctx), which also
+// flushes the last interval.
+func InitMeter(ctx context.Context, serviceName, version string) (*sdkmetric.MeterProvider, error) {
+	exporter, err := otlpmetricgrpc.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp metric exporter: %w", err)
+	}
+
+	res, err := serviceResource(serviceName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(metricInterval))),
+		sdkmetric.WithResource(res),
+	)
+
+	otel.SetMeterProvider(mp)
+	return mp, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
@@ -21,6 +22,34 @@ func InitTracer(ctx context.Context, serviceName, version string) (*sdktrace.Tra
 		return nil, fmt.Errorf("create otlp exporter: %w", err)
 	}
 
+	res, err := serviceResource(serviceName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tp)
+	InitPropagator()
+	return tp, nil
+}
+
+// InitPropagator makes W3C traceparent/tracestate and baggage the way trace
+// context crosses process boundaries: proxy → AgentCore → MCP server, and
+// the AWS SDK calls otelaws instruments.
+func InitPropagator() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// serviceResource describes the service to the OTLP backend, shared by
+// traces and metrics.
+func serviceResource(serviceName, version string) (*resource.Resource, error) {
 	res, err := resource.Merge(
 		resource.Default(),
 		resource.NewWithAttributes(
@@ -33,12 +62,5 @@ func InitTracer(ctx context.Context, serviceName, version string) (*sdktrace.Tra
 	if err != nil {
 		return nil, fmt.Errorf("create resource: %w", err)
 	}
-
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(res),
-	)
-
-	otel.SetTracerProvider(tp)
-	return tp, nil
+	return res, nil
 }
@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Budgets are latency targets for the stages of a run. Unlike Timeouts, a
+// stage past its budget is not stopped: the overrun is logged as a warning,
+// counted in the pipeline.stage.over_budget metric and marked in the run's
+// stage report, so alerts can catch a systemic slowdown (every haiku script
+// taking five minutes) before it turns into timeouts.
+//
+// Keys are a stage name, optionally narrowed to the script model or TTS
+// provider after a colon ("script:haiku", "tts:gemini"); the narrower
+// budget wins. A stage with no budget is still timed and reported.
+type Budgets map[string]time.Duration
+
+// budgetStages are the stage names ParseBudgets accepts, in pipeline order.
+var budgetStages = []string{"transcribe", "ingest", "script", "tts", "assembly", "finishing"}
+
+// ParseBudgets parses a comma-separated list of stage[:qualifier]=duration
+// pairs ("script=5m,script:haiku=3m,tts=10m"), as taken by --stage-budgets
+// and PODCASTER_STAGE_BUDGETS.
+func ParseBudgets(spec string) (Budgets, error) {
+	b := Budgets{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("stage budget %q: want stage=duration", part)
+		}
+		stage, qualifier, _ := strings.Cut(strings.ToLower(strings.TrimSpace(key)), ":")
+		if !slices.Contains(budgetStages, stage) {
+			return nil, fmt.Errorf("unknown stage %q: must be one of %s", stage, strings.Join(budgetStages, ", "))
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("stage budget %q: invalid duration %q", key, value)
+		}
+		b[budgetKey(stage, qualifier)] = d
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return b, nil
+}
+
+// For returns the budget of stage run with qualifier, 0 if it has none.
+func (b Budgets) For(stage, qualifier string) time.Duration {
+	if d, ok := b[budgetKey(stage, strings.ToLower(qualifier))]; ok && qualifier != "" {
+		return d
+	}
+	return b[stage]
+}
+
+// String formats b as ParseBudgets input, in stage order with each stage's
+// plain budget before its qualified ones.
+func (b Budgets) String() string {
+	var parts []string
+	for _, stage := range budgetStages {
+		if d, ok := b[stage]; ok {
+			parts = append(parts, stage+"="+d.String())
+		}
+		var qualified []string
+		for key, d := range b {
+			if strings.HasPrefix(key, stage+":") {
+				qualified = append(qualified, key+"="+d.String())
+			}
+		}
+		slices.Sort(qualified)
+		parts = append(parts, qualified...)
+	}
+	return strings.Join(parts, ",")
+}
+
+func budgetKey(stage, qualifier string) string {
+	if qualifier == "" {
+		return stage
+	}
+	return stage + ":" + qualifier
+}
+
+// StageTiming is one stage of a run in its stage report: how long it took
+// and how that compares with its budget.
+type StageTiming struct {
+	Stage      string  `json:"stage"`
+	Qualifier  string  `json:"qualifier,omitempty"` // script model or TTS provider
+	Seconds    float64 `json:"seconds"`
+	Budget     float64 `json:"budget_seconds,omitempty"`
+	OverBudget bool    `json:"over_budget,omitempty"`
+	Failed     bool    `json:"failed,omitempty"`
+}
+
+var (
+	meter = otel.Meter("podcaster-pipeline")
+
+	stageDuration    metric.Float64Histogram
+	stageOverBudget  metric.Int64Counter
+	instrumentsError error
+)
+
+func init() {
+	var errs [2]error
+	stageDuration, errs[0] = meter.Float64Histogram("pipeline.stage.duration",
+		metric.WithUnit("s"), metric.WithDescription("Pipeline stage duration by stage, qualifier, and status"))
+	stageOverBudget, errs[1] = meter.Int64Counter("pipeline.stage.over_budget",
+		metric.WithDescription("Pipeline stages that ran past their latency budget"))
+	instrumentsError = errors.Join(errs[:]...)
+}
+
+// recordStage adds a finished stage to the OTEL instruments.
+func recordStage(ctx context.Context, t StageTiming) {
+	if instrumentsError != nil {
+		return
+	}
+	status := "ok"
+	if t.Failed {
+		status = "error"
+	}
+	attrs := metric.WithAttributes(
+		attribute.String("stage", t.Stage),
+		attribute.String("qualifier", t.Qualifier),
+	)
+	stageDuration.Record(ctx, t.Seconds, attrs, metric.WithAttributes(attribute.String("status", status)))
+	if t.OverBudget {
+		stageOverBudget.Add(ctx, 1, attrs)
+	}
+}
+
+// logStageReport logs each stage's time against its budget.
+func logStageReport(timings []StageTiming, logf func(string, ...interface{})) {
+	if len(timings) == 0 {
+		return
+	}
+	logf("Stage timings:")
+	for _, t := range timings {
+		name := t.Stage
+		if t.Qualifier != "" {
+			name += " (" + t.Qualifier + ")"
+		}
+		took := time.Duration(t.Seconds * float64(time.Second)).Round(100 * time.Millisecond)
+		line := fmt.Sprintf("  %-22s %10s", name, took)
+		if t.Budget > 0 {
+			budget := time.Duration(t.Budget * float64(time.Second))
+			status := "ok"
+			if t.OverBudget {
+				status = "OVER"
+			}
+			line += fmt.Sprintf("  budget %-8s %s", budget, status)
+		}
+		if t.Failed {
+			line += "  (failed)"
+		}
+		logf("%s", line)
+	}
+}
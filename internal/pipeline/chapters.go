@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// Chapters come from the script: the generator marks the first segment of
+// each part of its planned arc with a "chapter" title. Start times are
+// estimated by spreading the voice track's duration over the segments by
+// text length, then shifted by whatever the music bed and intro put in
+// front of the voices. They are embedded as ID3 chapters and written next
+// to the episode as a Podcasting 2.0 chapters file.
+
+// ChaptersPath returns the chapters file written next to an episode.
+func ChaptersPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".chapters.json"
+}
+
+// HasChapters reports whether any segment of s starts a chapter.
+func HasChapters(s *script.Script) bool {
+	for _, seg := range s.Segments {
+		if seg.Chapter != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// scriptChapters returns s's chapters in an episode whose voices run for
+// voiceSecs starting lead seconds in, and which ends at totalSecs. The
+// first chapter starts at 0, so it also covers any intro; segments before
+// the first marker belong to it.
+func scriptChapters(s *script.Script, voiceSecs, lead, totalSecs float64) []assembly.Chapter {
+	var total int
+	for _, seg := range s.Segments {
+		total += len(seg.Text)
+	}
+	if total == 0 || voiceSecs <= 0 {
+		return nil
+	}
+
+	var chapters []assembly.Chapter
+	chars := 0
+	for _, seg := range s.Segments {
+		if title := strings.TrimSpace(seg.Chapter); title != "" {
+			start := lead + float64(chars)/float64(total)*voiceSecs
+			if len(chapters) == 0 {
+				start = 0
+			}
+			// Round to the second: the estimate is no finer than that.
+			start = math.Round(start)
+			if n := len(chapters); n > 0 {
+				if start <= chapters[n-1].Start {
+					// Markers on adjacent short segments: keep the first.
+					continue
+				}
+				chapters[n-1].End = start
+			}
+			chapters = append(chapters, assembly.Chapter{Title: title, Start: start})
+		}
+		chars += len(seg.Text)
+	}
+	if n := len(chapters); n > 0 {
+		chapters[n-1].End = math.Max(totalSecs, chapters[n-1].Start+1)
+	}
+	return chapters
+}
+
+// WriteChapters writes chapters to path in the Podcasting 2.0 JSON chapters
+// format.
+func WriteChapters(path string, chapters []assembly.Chapter) error {
+	type jsonChapter struct {
+		StartTime float64 `json:"startTime"`
+		EndTime   float64 `json:"endTime,omitempty"`
+		Title     string  `json:"title"`
+	}
+	doc := struct {
+		Version  string        `json:"version"`
+		Chapters []jsonChapter `json:"chapters"`
+	}{Version: "1.2.0"}
+	for _, c := range chapters {
+		doc.Chapters = append(doc.Chapters, jsonChapter{StartTime: c.Start, EndTime: c.End, Title: c.Title})
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal chapters: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write chapters: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// EscalateOff turns off retrying with a stronger model (--escalate-model).
+const EscalateOff = "off"
+
+// ScriptEscalation records a script retried with a stronger model because
+// the review rejected the selected model's script and couldn't fix it.
+type ScriptEscalation struct {
+	From   string   `json:"from"`
+	To     string   `json:"to"`
+	Issues []string `json:"issues"` // review errors the selected model left
+
+	// Passed reports whether the stronger model's script passed review, and
+	// Used whether it replaced the selected model's script (it scored at least as well).
+	Passed bool `json:"passed"`
+	Used   bool `json:"used"`
+
+	// CostUSD estimates what the retry added (script.Usage.CostUSD).
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// ValidateEscalateModel checks an --escalate-model value: "", EscalateOff,
+// or a script model.
+func ValidateEscalateModel(model string) error {
+	if model == "" || model == EscalateOff || script.IsValidModel(model) {
+		return nil
+	}
+	return fmt.Errorf("unknown model %q: must be %s, haiku, sonnet, gemini-flash, gemini-pro, or nova-lite", model, EscalateOff)
+}
+
+// reviewScript reviews s, written by model, and returns the script to keep:
+// s if it passed or couldn't be revised, else the reviewer's revision.
+// remaining are the errors the kept script still has (a revision is checked
+// again heuristically), so any means the review rejected the script and
+// couldn't fix it. A review that can't run keeps s with no remaining errors.
+func reviewScript(ctx context.Context, model, apiKey string, s *script.Script, content string, genOpts script.GenerateOptions, logf func(string, ...interface{})) (kept *script.Script, remaining []script.ReviewIssue) {
+	reviewer, err := script.NewReviewer(model, apiKey)
+	if err != nil {
+		logf("WARNING: could not create reviewer: %v", err)
+		return s, nil
+	}
+	result, err := reviewer.Review(ctx, s, content, genOpts)
+	if err != nil {
+		logf("WARNING: script review failed: %v", err)
+		return s, nil
+	}
+	for _, issue := range result.Issues {
+		logf("  Review [%s] %s: %s", issue.Severity, issue.Category, issue.Message)
+	}
+	switch {
+	case result.Approved:
+		logf("Script review passed")
+		return s, nil
+	case result.Revised == nil:
+		logf("Script review found issues but revision was not possible")
+		return s, script.ReviewErrors(result.Issues)
+	}
+	logf("Script revised: %d → %d segments", len(s.Segments), len(result.Revised.Segments))
+	remaining = script.ReviewErrors(script.CheckScript(result.Revised, genOpts.Duration, genOpts.Voices))
+	for _, issue := range remaining {
+		logf("  Revision still fails review [%s]: %s", issue.Category, issue.Message)
+	}
+	return result.Revised, remaining
+}
+
+// escalateScript regenerates and reviews the script once with
+// opts.EscalateModel (else script.EscalationModel) after s, the selected
+// model's script as revised, still failed review with issues. It returns the better
+// scoring script (script.ReviewScore) and a record of the retry, or s and
+// nil if there is no stronger model or the retry fails.
+func escalateScript(ctx context.Context, timeout time.Duration, opts Options, s *script.Script, issues []script.ReviewIssue, content string, genOpts script.GenerateOptions, logf func(string, ...interface{})) (*script.Script, *ScriptEscalation) {
+	to := opts.EscalateModel
+	if to == "" {
+		to = script.EscalationModel(opts.Model)
+	}
+	if to == "" || to == EscalateOff || to == opts.Model {
+		logf("WARNING: script still fails review; keeping it (no stronger model to retry with)")
+		return s, nil
+	}
+	logf("Script still fails review with %s; retrying once with %s...", opts.Model, script.ModelDisplayName(to))
+
+	apiKey := opts.ScriptAPIKey(to)
+	gen, err := script.NewGenerator(to, apiKey)
+	if err != nil {
+		logf("WARNING: could not retry with %s: %v; keeping the current script", to, err)
+		return s, nil
+	}
+	escCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	genOpts.Model = to
+	retry, err := gen.Generate(escCtx, content, genOpts)
+	if err != nil {
+		logf("WARNING: retry with %s failed: %v; keeping the current script", to, err)
+		return s, nil
+	}
+	cost := retry.Usage.CostUSD(to)
+	kept, remaining := reviewScript(escCtx, to, apiKey, retry, content, genOpts, logf)
+	if kept != retry {
+		cost += kept.Usage.CostUSD(to)
+	}
+
+	esc := &ScriptEscalation{
+		From:    opts.Model,
+		To:      to,
+		Passed:  len(remaining) == 0,
+		Used:    script.ReviewScore(remaining) >= script.ReviewScore(issues),
+		CostUSD: cost,
+	}
+	for _, issue := range issues {
+		esc.Issues = append(esc.Issues, fmt.Sprintf("%s: %s", issue.Category, issue.Message))
+	}
+	if opts.OnEscalation != nil {
+		opts.OnEscalation(*esc)
+	}
+	if !esc.Used {
+		logf("Escalated to %s (~$%.4f extra), but its script scored lower; keeping the %s script", to, cost, opts.Model)
+		return s, esc
+	}
+	logf("Escalated to %s (~$%.4f extra): %d segments, ~%d min", to, cost, len(kept.Segments), estimateMinutes(kept))
+	return kept, esc
+}
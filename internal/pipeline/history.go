@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/diarize"
+)
+
+// HistoryFile is the generation history, one JSON object per line, under
+// OutputBaseDir.
+const HistoryFile = "history.jsonl"
+
+// HistoryEntry records a finished episode and the command that made it
+// (podcaster episodes).
+type HistoryEntry struct {
+	ID        string    `json:"id"` // episode file name without extension
+	CreatedAt time.Time `json:"createdAt"`
+	Title     string    `json:"title,omitempty"`
+	Output    string    `json:"output"`
+	Command   string    `json:"command"`
+
+	// QC is the episode's quality report, if it was checked.
+	QC *assembly.QCReport `json:"qc,omitempty"`
+
+	// Escalation records a script retried with a stronger model.
+	Escalation *ScriptEscalation `json:"escalation,omitempty"`
+
+	// Speakers is the speaker check's report, if it ran.
+	Speakers *diarize.Report `json:"speakers,omitempty"`
+
+	// Stages is the run's stage timings against their budgets.
+	Stages []StageTiming `json:"stages,omitempty"`
+}
+
+// ReproCommand returns the CLI command that regenerates an episode with
+// these options: CLICommand without the output path (a rerun gets its own)
+// or a resume directory (it won't exist any more).
+func (o Options) ReproCommand() string {
+	o.Output = ""
+	o.ResumeTTS = ""
+	return o.CLICommand()
+}
+
+// EpisodeID returns the history ID of the episode at output.
+func EpisodeID(output string) string {
+	base := filepath.Base(output)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// historyPath returns the history file's path.
+func historyPath() string {
+	return filepath.Join(OutputBaseDir, HistoryFile)
+}
+
+// AppendHistory adds e to the history file.
+func AppendHistory(e HistoryEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal history entry: %w", err)
+	}
+	if err := os.MkdirAll(OutputBaseDir, 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+	f, err := os.OpenFile(historyPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write history: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadHistory reads the history file, oldest first. A missing file is an
+// empty history; unparseable lines are skipped.
+func LoadHistory() ([]HistoryEntry, error) {
+	f, err := os.Open(historyPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var e HistoryEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil && e.ID != "" {
+			entries = append(entries, e)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	return entries, nil
+}
+
+// FindHistory returns the latest entry whose ID is id, or whose ID starts
+// with id if that matches exactly one episode.
+func FindHistory(entries []HistoryEntry, id string) (HistoryEntry, error) {
+	id = EpisodeID(id)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].ID == id {
+			return entries[i], nil
+		}
+	}
+	var matches []HistoryEntry
+	seen := make(map[string]bool)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if strings.HasPrefix(entries[i].ID, id) && !seen[entries[i].ID] {
+			seen[entries[i].ID] = true
+			matches = append(matches, entries[i])
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return HistoryEntry{}, fmt.Errorf("no episode %q in %s", id, historyPath())
+	case 1:
+		return matches[0], nil
+	default:
+		return HistoryEntry{}, fmt.Errorf("%q matches %d episodes; use more of the ID", id, len(matches))
+	}
+}
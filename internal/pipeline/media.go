@@ -0,0 +1,146 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/errkind"
+	"github.com/apresai/podcaster/internal/ingest"
+	"github.com/apresai/podcaster/internal/transcribe"
+)
+
+// Audio and video inputs (ingest.SourceMedia) are transcribed before
+// ingest: FFmpeg extracts the speech, the transcriber turns it into text,
+// and the transcript, saved under TranscriptsDir, takes the recording's
+// place among the inputs. A transcript newer than its recording is reused,
+// so re-running with other options doesn't transcribe again.
+
+// TranscriptsDir holds the transcripts of audio and video inputs, under
+// OutputBaseDir.
+const TranscriptsDir = "transcripts"
+
+// mediaInputs returns the inputs that are audio or video files.
+func mediaInputs(inputs []string) []string {
+	var media []string
+	for _, in := range inputs {
+		if ingest.DetectSource(in) == ingest.SourceMedia {
+			media = append(media, in)
+		}
+	}
+	return media
+}
+
+// transcriptPath is where media's transcript by engine is kept. The name
+// carries a hash of media's absolute path, so recordings with the same name
+// in different directories, or transcribed by different engines, don't
+// share a transcript.
+func transcriptPath(media, engine string) string {
+	abs, err := filepath.Abs(media)
+	if err != nil {
+		abs = media
+	}
+	sum := sha256.Sum256([]byte(abs))
+	base := filepath.Base(media)
+	name := fmt.Sprintf("%s-%s-%s.txt", strings.TrimSuffix(base, filepath.Ext(base)), hex.EncodeToString(sum[:4]), engine)
+	return filepath.Join(OutputBaseDir, TranscriptsDir, name)
+}
+
+// transcribeInputs replaces the audio and video files among inputs with
+// their transcripts. progress is called with a message and the fraction
+// done across all of them.
+func transcribeInputs(ctx context.Context, inputs []string, t transcribe.Transcriber, progress func(msg string, done float64), logf func(string, ...interface{})) ([]string, error) {
+	media := mediaInputs(inputs)
+	out := make([]string, len(inputs))
+	copy(out, inputs)
+	n := 0
+	for i, in := range inputs {
+		if ingest.DetectSource(in) != ingest.SourceMedia {
+			continue
+		}
+		share := func(done float64) float64 { return (float64(n) + done) / float64(len(media)) }
+		path := transcriptPath(in, t.Name())
+		if transcriptFresh(path, in) {
+			logf("Transcript of %s reused from %s", in, path)
+			out[i] = path
+			progress(fmt.Sprintf("Transcript of %s reused", filepath.Base(in)), share(1))
+			n++
+			continue
+		}
+
+		start := time.Now()
+		text, err := transcribeMedia(ctx, in, t, func(msg string, done float64) { progress(msg, share(done)) })
+		if err != nil {
+			if len(media) > 1 {
+				err = fmt.Errorf("%s: %w", in, err)
+			}
+			return nil, err
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, errkind.New(errkind.UserInput, fmt.Sprintf("no speech found in %s", in))
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		// The first line is the transcript's title when it is ingested.
+		body := fmt.Sprintf("Transcript of %s\n\n%s\n", filepath.Base(in), text)
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			return nil, fmt.Errorf("save transcript: %w", err)
+		}
+		logf("Transcribed %s with %s: %d words in %s, saved to %s", in, t.Name(),
+			len(strings.Fields(text)), time.Since(start).Round(time.Millisecond), path)
+		out[i] = path
+		n++
+	}
+	return out, nil
+}
+
+// transcribeMedia extracts input's speech into a temporary directory and
+// transcribes it.
+func transcribeMedia(ctx context.Context, input string, t transcribe.Transcriber, progress func(msg string, done float64)) (string, error) {
+	if _, err := os.Stat(input); err != nil {
+		return "", errkind.Wrap(errkind.UserInput, fmt.Sprintf("cannot access %s", input), err)
+	}
+	hasAudio, err := assembly.HasAudioTrack(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("probe %s: %w", input, err)
+	}
+	if !hasAudio {
+		return "", errkind.New(errkind.UserInput, fmt.Sprintf("%s has no audio track", input))
+	}
+
+	dir, err := os.MkdirTemp("", "podcaster-speech-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Base(input)
+	progress(fmt.Sprintf("Extracting speech from %s...", name), 0)
+	files, err := assembly.ExtractSpeech(ctx, input, dir, t.WAV(), t.Chunk())
+	if err != nil {
+		return "", fmt.Errorf("extract speech: %w", err)
+	}
+
+	msg := fmt.Sprintf("Transcribing %s (%s)...", name, t.Name())
+	if len(files) > 1 {
+		msg = fmt.Sprintf("Transcribing %s (%s, %d chunks)...", name, t.Name(), len(files))
+	}
+	progress(msg, 0)
+	return t.Transcribe(ctx, files, func(done float64) { progress(msg, done) })
+}
+
+// transcriptFresh reports whether path exists and is newer than src.
+func transcriptFresh(path, src string) bool {
+	p, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	s, err := os.Stat(src)
+	return err == nil && p.ModTime().After(s.ModTime())
+}
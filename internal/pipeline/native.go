@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/tts"
+)
+
+// Episodes without FFmpeg. Segments are joined by assembly.NativeAssembler,
+// MP3 into MP3 or provider PCM into WAV; loudness normalization and tags
+// are skipped, and options that mix or re-encode are refused up front.
+
+// segmentAssembler is an Assembler that reports where each segment landed,
+// for the transcript.
+type segmentAssembler interface {
+	assembly.Assembler
+	SegmentSeconds() []float64
+	SegmentStarts() []float64
+}
+
+// newAssembler returns the FFmpeg assembler, or the native one if FFmpeg
+// isn't installed.
+func newAssembler(enc assembly.Encoding, pacing assembly.Pacing) segmentAssembler {
+	if !assembly.HasFFmpeg() {
+		return assembly.NewNativeAssembler(pacing)
+	}
+	return assembly.NewFFmpegAssembler(enc, pacing)
+}
+
+// CheckNative returns an error naming the options in opts that need FFmpeg,
+// for a run without it. Output's extension, else OutputFormat, must be MP3
+// or WAV, and WAV if a provider returns raw PCM.
+func CheckNative(opts Options) error {
+	var need []string
+	if opts.Music != "" {
+		need = append(need, "--music")
+	}
+	if opts.Intro != "" {
+		need = append(need, "--intro")
+	}
+	if opts.Outro != "" {
+		need = append(need, "--outro")
+	}
+	if opts.Encoding.Bitrate != "" {
+		need = append(need, "--bitrate")
+	}
+	if opts.Encoding.Channels != "" {
+		need = append(need, "--channels")
+	}
+	if opts.Pacing.Crossfade != 0 {
+		need = append(need, "--crossfade")
+	}
+	if opts.SFX {
+		need = append(need, "--sfx")
+	}
+	format := opts.OutputFormat
+	if opts.Output != "" {
+		format = assembly.FormatOf(opts.Output)
+	}
+	if format != "" && format != assembly.FormatMP3 && format != assembly.FormatWAV {
+		need = append(need, "--output-format "+string(format))
+	}
+	if len(need) > 0 {
+		return fmt.Errorf("FFmpeg not found, and %s need it; install it (brew install ffmpeg) or drop them", strings.Join(need, ", "))
+	}
+	// The Gemini family returns raw PCM, which is wrapped as WAV and can't
+	// become an MP3 without an encoder.
+	if format != assembly.FormatWAV {
+		if pcm := pcmProviders(opts); len(pcm) > 0 {
+			return fmt.Errorf("FFmpeg not found, and %s return raw PCM, which can only be joined into a WAV without it; install it (brew install ffmpeg), pass --output-format wav, or use an MP3 provider such as --tts elevenlabs", strings.Join(pcm, ", "))
+		}
+	}
+	return nil
+}
+
+// pcmProviders returns the providers opts may synthesize with, fallbacks
+// included, that return raw PCM.
+func pcmProviders(opts Options) []string {
+	providers := []string{opts.Voice1Provider, opts.Voice2Provider}
+	if opts.Voices == 3 {
+		providers = append(providers, opts.Voice3Provider)
+	}
+	def := opts.DefaultTTS
+	if def == "" {
+		def = "gemini"
+	}
+	for i, p := range providers {
+		if p == "" {
+			providers[i] = def
+		}
+	}
+	providers = append(providers, opts.TTSFallback...)
+	var pcm []string
+	for _, p := range providers {
+		if tts.GeminiFamily(p) && !slices.Contains(pcm, p) {
+			pcm = append(pcm, p)
+		}
+	}
+	return pcm
+}
+
+// writeSegmentNative is writeSegment without FFmpeg: MP3 is written as is
+// and PCM as WAV, which NativeAssembler can only join into a WAV episode.
+func writeSegmentNative(result tts.AudioResult, tmpDir string, i int, fx assembly.Effects) (string, error) {
+	if !fx.IsZero() {
+		return "", fmt.Errorf("segment %d: emulated speed and pitch need FFmpeg", i+1)
+	}
+	var filename string
+	var err error
+	switch result.Format {
+	case tts.FormatMP3:
+		filename = filepath.Join(tmpDir, fmt.Sprintf("segment_%03d.mp3", i))
+		err = os.WriteFile(filename, result.Data, 0644)
+	case tts.FormatPCM:
+		filename = filepath.Join(tmpDir, fmt.Sprintf("segment_%03d.wav", i))
+		err = assembly.WriteRawPCM(filename, result.Data)
+	case tts.FormatWAV:
+		filename = filepath.Join(tmpDir, fmt.Sprintf("segment_%03d.wav", i))
+		err = os.WriteFile(filename, result.Data, 0644)
+	default:
+		return "", fmt.Errorf("segment %d: converting %s audio needs FFmpeg", i+1, result.Format)
+	}
+	if err != nil {
+		return "", fmt.Errorf("write segment %d: %w", i+1, err)
+	}
+	return filename, nil
+}
+
+// convertNative writes a batch episode's raw audio to output without
+// FFmpeg: PCM becomes a WAV, and MP3 is used as is. Anything that would
+// re-encode is an error.
+func convertNative(input string, format tts.AudioFormat, output string, fx assembly.Effects) error {
+	if !fx.IsZero() {
+		return fmt.Errorf("emulated speed and pitch need FFmpeg")
+	}
+	to := assembly.FormatOf(output)
+	switch {
+	case format == tts.FormatPCM && to == assembly.FormatWAV:
+		data, err := os.ReadFile(input)
+		if err != nil {
+			return err
+		}
+		return assembly.WriteRawPCM(output, data)
+	case format == tts.FormatWAV && to == assembly.FormatWAV,
+		format == tts.FormatMP3 && to == assembly.FormatMP3:
+		data, err := os.ReadFile(input)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(output, data, 0644)
+	default:
+		return fmt.Errorf("converting %s audio to %s needs FFmpeg; use --output-format %s", format, to, nativeFormat(format))
+	}
+}
+
+// nativeFormat is the output format audio in format can be written as
+// without FFmpeg.
+func nativeFormat(format tts.AudioFormat) assembly.Format {
+	if format == tts.FormatMP3 {
+		return assembly.FormatMP3
+	}
+	return assembly.FormatWAV
+}
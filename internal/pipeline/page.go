@@ -0,0 +1,159 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// The listening page is a standalone HTML file next to the episode: the
+// audio player, then the transcript one paragraph per segment, each
+// starting where its first caption cue does. Clicking a paragraph seeks
+// the audio there, and #t=<seconds> links open the page at that point.
+
+// PagePath returns the listening page written next to an episode.
+func PagePath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".html"
+}
+
+// pageParagraph is one segment on the listening page.
+type pageParagraph struct {
+	Chapter string // heading before the paragraph, if a chapter starts here
+	Speaker string
+	Text    string
+	Start   float64
+}
+
+// Anchor is the paragraph's #t= link target, in whole seconds.
+func (p pageParagraph) Anchor() int {
+	return int(p.Start)
+}
+
+// Clock is the paragraph's start as m:ss or h:mm:ss.
+func (p pageParagraph) Clock() string {
+	secs := int(p.Start)
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// pageParagraphs joins cues back into s's segments. cues must come from
+// transcriptCues(s, ...), which makes one run of cues per segment with
+// spoken text, in order.
+func pageParagraphs(s *script.Script, cues []Cue) []pageParagraph {
+	var paras []pageParagraph
+	next := 0
+	for _, seg := range s.Segments {
+		n := len(splitCaption(captionText(seg.Text)))
+		if n == 0 || next+n > len(cues) {
+			continue
+		}
+		var text []string
+		for _, c := range cues[next : next+n] {
+			text = append(text, c.Text)
+		}
+		paras = append(paras, pageParagraph{
+			Chapter: seg.Chapter,
+			Speaker: seg.Speaker,
+			Text:    strings.Join(text, " "),
+			Start:   cues[next].Start,
+		})
+		next += n
+	}
+	return paras
+}
+
+// WritePage writes the listening page for s next to output. audio and vtt
+// are the episode's and its WebVTT transcript's URLs relative to the page;
+// empty means the files next to it.
+func WritePage(output string, s *script.Script, cues []Cue, audio, vtt string) error {
+	if audio == "" {
+		audio = filepath.Base(output)
+	}
+	if vtt == "" {
+		vtt = filepath.Base(VTTPath(output))
+	}
+	var buf bytes.Buffer
+	err := pageTemplate.Execute(&buf, map[string]any{
+		"Title":      s.Title,
+		"Summary":    s.Summary,
+		"Audio":      audio,
+		"Transcript": vtt,
+		"Paragraphs": pageParagraphs(s, cues),
+	})
+	if err != nil {
+		return fmt.Errorf("render listening page: %w", err)
+	}
+	if err := os.WriteFile(PagePath(output), buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write listening page: %w", err)
+	}
+	return nil
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font: 17px/1.6 -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 0; color: #1a1a1a; background: #fafafa; }
+main { max-width: 42rem; margin: 0 auto; padding: 2rem 1rem 6rem; }
+h1 { font-size: 1.6rem; line-height: 1.3; margin-bottom: .5rem; }
+.summary { color: #555; }
+audio { position: sticky; top: 0; width: 100%; margin: 1rem 0; z-index: 1; }
+h2 { font-size: 1.1rem; margin-top: 2rem; }
+p[data-start] { cursor: pointer; padding: .25rem .5rem; margin: .25rem -.5rem; border-radius: 6px; }
+p[data-start]:hover { background: #eee; }
+p.current { background: #e6f0ff; }
+.time { color: #888; font-size: .8rem; font-variant-numeric: tabular-nums; text-decoration: none; margin-right: .5rem; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+{{if .Summary}}<p class="summary">{{.Summary}}</p>
+{{end}}<audio id="audio" controls preload="metadata" src="{{.Audio}}">
+<track kind="captions" src="{{.Transcript}}" srclang="en" label="Transcript">
+</audio>
+<article>
+{{range .Paragraphs}}{{if .Chapter}}<h2>{{.Chapter}}</h2>
+{{end}}<p data-start="{{.Start}}"><a class="time" href="#t={{.Anchor}}">{{.Clock}}</a><b>{{.Speaker}}:</b> {{.Text}}</p>
+{{end}}</article>
+</main>
+<script>
+const audio = document.getElementById("audio");
+const paras = Array.from(document.querySelectorAll("p[data-start]"));
+for (const p of paras) {
+  p.addEventListener("click", (e) => {
+    e.preventDefault();
+    const t = parseFloat(p.dataset.start);
+    history.replaceState(null, "", "#t=" + Math.floor(t));
+    audio.currentTime = t;
+    audio.play();
+  });
+}
+function seekToHash() {
+  const m = location.hash.match(/^#t=(\d+(?:\.\d+)?)$/);
+  if (m) audio.currentTime = parseFloat(m[1]);
+}
+window.addEventListener("hashchange", seekToHash);
+if (audio.readyState >= 1) seekToHash(); else audio.addEventListener("loadedmetadata", seekToHash, { once: true });
+audio.addEventListener("timeupdate", () => {
+  let current = null;
+  for (const p of paras) {
+    if (parseFloat(p.dataset.start) > audio.currentTime) break;
+    current = p;
+  }
+  for (const p of paras) p.classList.toggle("current", p === current);
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/errkind"
+	"github.com/apresai/podcaster/internal/script"
+	"github.com/apresai/podcaster/internal/tts"
+)
+
+// PlanFile is the audio plan's file name in a run's temp directory.
+const PlanFile = "plan.json"
+
+// AudioPlan records which of a script's segments already have audio in a
+// failed per-segment run's temp directory, so the run can be resumed
+// (--resume-tts) without paying for them again.
+type AudioPlan struct {
+	Script   string        `json:"script"` // saved script path
+	Total    int           `json:"total"`
+	Segments []PlanSegment `json:"segments"` // completed segments
+	Missing  []int         `json:"missing"`  // 0-based indexes without audio
+}
+
+// PlanSegment is one completed segment of an AudioPlan.
+type PlanSegment struct {
+	Index    int    `json:"index"`
+	File     string `json:"file"` // MP3 name, relative to the plan's directory
+	Provider string `json:"provider"`
+	Voice    string `json:"voice"`
+	TextHash string `json:"textHash"` // segmentHash at synthesis time
+}
+
+// segmentHash identifies what a segment says and how, so a resumed run
+// only reuses audio for segments that are unchanged in the script.
+func segmentHash(seg script.Segment) string {
+	sum := sha256.Sum256([]byte(seg.Speaker + "\x00" + seg.Text + "\x00" + seg.SSML + "\x00" + seg.Delivery))
+	return hex.EncodeToString(sum[:8])
+}
+
+// newAudioPlan builds the plan for a per-segment run whose files (by script
+// index) are set for the segments that finished.
+func newAudioPlan(scriptPath string, s *script.Script, voices tts.VoiceMap, files []string) AudioPlan {
+	plan := AudioPlan{Script: scriptPath, Total: len(s.Segments)}
+	for i, seg := range s.Segments {
+		if i >= len(files) || files[i] == "" {
+			plan.Missing = append(plan.Missing, i)
+			continue
+		}
+		voice := tts.VoiceForSpeaker(seg.Speaker, voices)
+		plan.Segments = append(plan.Segments, PlanSegment{
+			Index:    i,
+			File:     filepath.Base(files[i]),
+			Provider: voice.Provider,
+			Voice:    voice.ID,
+			TextHash: segmentHash(seg),
+		})
+	}
+	return plan
+}
+
+// WritePlan writes plan to dir/PlanFile.
+func WritePlan(dir string, plan AudioPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal audio plan: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, PlanFile), data); err != nil {
+		return fmt.Errorf("write audio plan: %w", err)
+	}
+	return nil
+}
+
+// LoadPlan reads dir/PlanFile.
+func LoadPlan(dir string) (AudioPlan, error) {
+	var plan AudioPlan
+	data, err := os.ReadFile(filepath.Join(dir, PlanFile))
+	if err != nil {
+		return plan, fmt.Errorf("read audio plan: %w", err)
+	}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return plan, fmt.Errorf("parse audio plan: %w", err)
+	}
+	return plan, nil
+}
+
+// reusable returns the MP3 paths in dir of the plan's segments that can be
+// kept for s: same index, same text, same voice, and still on disk.
+func (plan AudioPlan) reusable(dir string, s *script.Script, voices tts.VoiceMap) map[int]string {
+	reuse := make(map[int]string)
+	for _, ps := range plan.Segments {
+		if ps.Index < 0 || ps.Index >= len(s.Segments) {
+			continue
+		}
+		seg := s.Segments[ps.Index]
+		voice := tts.VoiceForSpeaker(seg.Speaker, voices)
+		if ps.TextHash != segmentHash(seg) || ps.Provider != voice.Provider || ps.Voice != voice.ID {
+			continue
+		}
+		path := filepath.Join(dir, ps.File)
+		if _, err := os.Stat(path); err == nil {
+			reuse[ps.Index] = path
+		}
+	}
+	return reuse
+}
+
+// PartialTTSError reports a per-segment run that failed after synthesizing
+// some of its segments. Their audio and the plan stay in Dir; pass it to
+// --resume-tts to synthesize only the rest.
+type PartialTTSError struct {
+	Dir  string
+	Plan AudioPlan
+	Err  error
+}
+
+func (e *PartialTTSError) Error() string {
+	return fmt.Sprintf("synthesized %d/%d segments (missing %s): %v",
+		len(e.Plan.Segments), e.Plan.Total, SegmentRanges(e.Plan.Missing), e.Err)
+}
+
+func (e *PartialTTSError) Unwrap() error { return e.Err }
+
+// ErrorKind implements errkind.Classified with the cause's kind.
+func (e *PartialTTSError) ErrorKind() errkind.Kind { return errkind.Of(e.Err) }
+
+// partialFailure writes the audio plan of a per-segment run that failed
+// with err and returns a *PartialTTSError, or err itself if no segment
+// finished (there is nothing to resume).
+func partialFailure(dir, scriptPath string, s *script.Script, voices tts.VoiceMap, files []string, err error, logf func(string, ...interface{})) error {
+	plan := newAudioPlan(scriptPath, s, voices, files)
+	if len(plan.Segments) == 0 {
+		return err
+	}
+	if werr := WritePlan(dir, plan); werr != nil {
+		logf("  WARNING: %v", werr)
+		return err
+	}
+	logf("  Completed %d/%d segments; missing %s", len(plan.Segments), plan.Total, SegmentRanges(plan.Missing))
+	logf("  Resume with: podcaster generate --resume-tts %q (plus the same voice and TTS flags)", dir)
+	return &PartialTTSError{Dir: dir, Plan: plan, Err: err}
+}
+
+// SegmentRanges formats 0-based segment indexes as 1-based ranges:
+// "3, 141-150".
+func SegmentRanges(indexes []int) string {
+	var parts []string
+	for i := 0; i < len(indexes); {
+		j := i
+		for j+1 < len(indexes) && indexes[j+1] == indexes[j]+1 {
+			j++
+		}
+		if j == i {
+			parts = append(parts, strconv.Itoa(indexes[i]+1))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", indexes[i]+1, indexes[j]+1))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
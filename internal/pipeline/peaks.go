@@ -0,0 +1,28 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/assembly"
+)
+
+// PeaksPath returns the waveform peaks written next to an episode.
+func PeaksPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".peaks.json"
+}
+
+// WritePeaks writes p to path as JSON.
+func WritePeaks(path string, p assembly.Peaks) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal waveform peaks: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write waveform peaks: %w", err)
+	}
+	return nil
+}
@@ -2,23 +2,29 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/diarize"
+	"github.com/apresai/podcaster/internal/errkind"
+	"github.com/apresai/podcaster/internal/feature"
 	"github.com/apresai/podcaster/internal/ingest"
 	"github.com/apresai/podcaster/internal/progress"
 	"github.com/apresai/podcaster/internal/script"
+	"github.com/apresai/podcaster/internal/transcribe"
 	"github.com/apresai/podcaster/internal/tts"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // OutputBaseDir is the root directory for all podcaster output.
@@ -26,6 +32,8 @@ const OutputBaseDir = "podcaster-output"
 
 type Options struct {
 	Input          string
+	ExtraInputs    []string // further sources merged with Input into one episode (repeated -i)
+	Chapters       string   // EPUB chapter selection (--chapters), e.g. "3" or "2-4"
 	Output         string
 	Topic          string
 	Tone           string
@@ -48,18 +56,254 @@ type Options struct {
 	TTSModel       string  // --tts-model
 	TTSSpeed       float64 // --tts-speed
 	TTSStability   float64 // --tts-stability (ElevenLabs)
-	TTSPitch       float64 // --tts-pitch (Google)
+	TTSPitch       float64 // --tts-pitch
 	OnProgress     progress.Callback
 
+	// TTSRetry tunes TTS retries (--tts-max-retries, --tts-backoff,
+	// --tts-max-backoff). Zero fields use the defaults.
+	TTSRetry tts.RetryPolicy
+
+	// TTSBreakerThreshold is how many consecutive failed requests take a
+	// TTS provider out of the run (--tts-breaker). 0 uses
+	// tts.DefaultBreakerThreshold; negative disables the breaker.
+	TTSBreakerThreshold int
+
+	// Per-voice speed/stability/pitch overrides, from the "@key=value"
+	// voice spec suffix (see tts.ParseVoiceSettings).
+	Voice1Settings tts.VoiceSettings
+	Voice2Settings tts.VoiceSettings
+	Voice3Settings tts.VoiceSettings
+
+	// TTSConcurrency is the number of per-segment TTS workers
+	// (--tts-concurrency). 0 = DefaultTTSConcurrency. Providers with tight
+	// quotas are capped lower regardless (see providerMaxConcurrency).
+	TTSConcurrency int
+
+	// NoTTSCache disables the per-segment audio cache under
+	// podcaster-output/cache (--no-tts-cache).
+	NoTTSCache bool
+
+	// Lexicon is a YAML pronunciation dictionary (--lexicon) applied to
+	// segment text before TTS. See tts.Lexicon for the file format.
+	Lexicon string
+
+	// SSMLHints asks the script generator for inline pause/emphasis hints
+	// (--ssml-hints), which become SSML for providers that support it.
+	SSMLHints bool
+
+	// DeliveryHints asks the script generator for per-segment delivery
+	// directions and inline audio tags like [laughs] (--delivery-hints).
+	// ElevenLabs performs them; other providers get the tags stripped.
+	DeliveryHints bool
+
+	// SFX lets the script generator mark sound effects with [SFX:name]
+	// (--sfx); assembly splices the bundled effects (assembly.SFXNames) in
+	// before the marked segments. It needs FFmpeg and per-segment
+	// synthesis.
+	SFX bool
+
+	// TTSStylePrompts prefixes each segment's text with a natural-language
+	// style instruction for Gemini-family providers (--tts-style-prompts),
+	// built from the script styles and the segment's delivery direction.
+	TTSStylePrompts bool
+
+	// Timeouts bounds each stage (--stage-timeouts); zero fields use
+	// DefaultTimeouts. Upload is not used here; the MCP server applies it.
+	Timeouts Timeouts
+
+	// Budgets are the stages' latency targets (--stage-budgets,
+	// budgets.go); overruns are warned about, not stopped. OnStageTimings,
+	// if set, receives the run's stage report when it ends.
+	Budgets        Budgets
+	OnStageTimings func([]StageTiming)
+
+	// Transcriber is the engine that transcribes audio and video inputs
+	// (--transcriber, media.go): transcribe.EngineOpenAI or
+	// transcribe.EngineWhisperCPP; empty uses whichever is configured.
+	// WhisperModel is whisper.cpp's ggml model file (--whisper-model).
+	Transcriber  string
+	WhisperModel string
+
+	// FetchHeaders ("Name: value", --header) and CookieJar (a Netscape
+	// cookies.txt, --cookie-jar) let ingest read URL inputs behind a login
+	// (ingest.Auth). Only ingest's requests carry them, headers only to the
+	// inputs' hosts; header values are never logged or put in CLICommand.
+	FetchHeaders []string
+	CookieJar    string
+
+	// OnIngest, if set, receives the ingested (merged) source content
+	// before it is checked; the MCP server keeps it for debug replays
+	// (replay.go).
+	OnIngest func(*ingest.Content)
+
+	// TTSMetrics, if set, collects the run's TTS requests (the CLI prints it
+	// after the progress bar). Run uses its own otherwise; either way the
+	// table is logged when the run ends.
+	TTSMetrics *tts.Metrics
+
+	// Music is a background bed mixed under the finished episode (--music):
+	// an audio file or a built-in bed name (assembly.MusicBeds). Empty
+	// means no music. MusicVolume is its gain in dB (0 = default).
+	Music       string
+	MusicVolume float64
+
+	// Intro and Outro are audio files crossfaded onto the start and end of
+	// the episode (--intro, --outro), after any music bed.
+	Intro string
+	Outro string
+
+	// NoLoudnorm skips normalizing the finished episode to
+	// assembly.LoudnessTarget (--no-loudnorm).
+	NoLoudnorm bool
+
+	// OutputFormat is the episode's audio format (--output-format), used
+	// to name the episode when Output is empty. Every encoding step
+	// follows Output's extension (assembly.FormatOf), so the CLI keeps the
+	// two in agreement.
+	OutputFormat assembly.Format
+
+	// Encoding sets the episode's bitrate and channel count (--bitrate,
+	// --channels); zero fields keep the format's defaults.
+	Encoding assembly.Encoding
+
+	// QC is what an episode outside its quality bounds does (--qc, qc.go):
+	// QCWarn (also ""), QCFail, or QCOff. QCBounds overrides some of the
+	// bounds (--qc-bounds, assembly.ParseQCBounds); the rest are
+	// assembly.DefaultQCBounds for Encoding's channels.
+	QC       string
+	QCBounds string
+
+	// Pacing sets the gap between segments, any crossfade, and silence
+	// trimming (--gap, --crossfade, --trim-silence). Beats come from the
+	// script; batch synthesis, which returns one file, ignores it.
+	Pacing assembly.Pacing
+
+	// PageAudio and PageTranscript are the episode's and its WebVTT
+	// transcript's URLs relative to the listening page (page.go); empty
+	// means the files next to it, as the CLI leaves them.
+	PageAudio      string
+	PageTranscript string
+
+	// Explicit overrides the explicit-content flag (--explicit); nil
+	// detects it from the final script (script.ExplicitTerms).
+	Explicit *bool
+
+	// Show is the show name written as the episode's ID3 album (--show);
+	// empty uses assembly.DefaultArtist. Cover is a JPEG or PNG embedded as
+	// cover art (--cover).
+	Show  string
+	Cover string
+
+	// ResumeTTS is the temp directory of a per-segment run that failed
+	// partway (--resume-tts). Its audio plan's segments are reused where
+	// the script and voices still match, and the rest are synthesized into
+	// it. FromScript defaults to the plan's script.
+	ResumeTTS string
+
+	// ProfileDir, if set, receives the run's CPU profile, a heap profile
+	// per stage and summary.json with each stage's time and memory
+	// (--profile, profile.go); the summary is also logged.
+	ProfileDir string
+
+	// VerifySpeakers transcribes the finished episode with diarization and
+	// checks each segment was spoken in its host's voice (--verify-speakers,
+	// speakers.go). It needs a Deepgram key (DeepgramAPIKey or the
+	// environment).
+	VerifySpeakers bool
+
+	// EscalateModel is the stronger script model tried once when the
+	// review rejects both the script and its revision (--escalate-model,
+	// escalate.go); empty uses script.EscalationModel, EscalateOff none.
+	// OnEscalation, if set, is called when that happens.
+	EscalateModel string
+	OnEscalation  func(ScriptEscalation)
+
+	// Preview synthesizes only the script's first Preview segments
+	// (--preview, preview.go); 0 is the whole episode. The full script is
+	// still saved.
+	Preview int
+
+	// Checkpoint records per-segment runs' progress under RunsDir as they
+	// synthesize, so an interrupted run can be resumed (podcaster resume,
+	// runstate.go). The CLI sets it.
+	Checkpoint bool
+
+	// Features are the rollout flags on for this run (internal/feature);
+	// the hosted server decides them per job.
+	Features feature.Set
+
+	// History records the finished episode and its ReproCommand in
+	// HistoryFile (the CLI sets it; see podcaster episodes).
+	History bool
+
+	// LogWriter, if set, also receives every log line (the hosted server
+	// keeps them for get_podcast_logs).
+	LogWriter io.Writer
+
+	// Disclaimer, if set, is appended to the script as a final line spoken by
+	// the first host (e.g. the hosted trial tier's notice).
+	Disclaimer string
+
+	// TTSFallback is an ordered list of providers to switch to when the
+	// active provider's daily quota runs out mid-run (--tts-fallback).
+	// Remaining segments use the fallback's default voices.
+	TTSFallback []string
+
+	// ScriptCache, if set, is consulted after ingest and filled after review,
+	// so a repeat of the same content and options reuses the script. The
+	// hosted server sets it; the CLI doesn't.
+	ScriptCache ScriptCache
+
 	// DisableBatch forces per-segment TTS instead of batch mode.
 	// Use this when running on infrastructure with network idle timeouts
 	// that can't sustain long-running HTTP requests (e.g., AgentCore).
 	DisableBatch bool
 
+	// DisableKeepAlives makes TTS clients open a new connection per request
+	// (--no-keepalive), for debugging connection reuse problems.
+	DisableKeepAlives bool
+
 	// Per-request API key overrides (BYOK). Empty = use env vars.
-	AnthropicAPIKey  string
-	GeminiAPIKey     string
-	ElevenLabsAPIKey string
+	AnthropicAPIKey     string
+	GeminiAPIKey        string
+	ElevenLabsAPIKey    string
+	CartesiaAPIKey      string
+	VertexExpressAPIKey string
+	HumeAPIKey          string
+	DeepgramAPIKey      string
+	OpenAIAPIKey        string // Whisper API transcription
+}
+
+// ScriptAPIKey returns the BYOK key for a script model, or "" when the
+// model should use its environment variable (or needs no key).
+func (o Options) ScriptAPIKey(model string) string {
+	switch model {
+	case "haiku", "sonnet":
+		return o.AnthropicAPIKey
+	case "gemini-flash", "gemini-pro":
+		return o.GeminiAPIKey
+	}
+	return ""
+}
+
+// TTSAPIKey returns the BYOK key for a TTS provider, or "" when the provider
+// should use its environment variable (or needs no key).
+func (o Options) TTSAPIKey(provider string) string {
+	switch provider {
+	case "gemini":
+		return o.GeminiAPIKey
+	case "elevenlabs":
+		return o.ElevenLabsAPIKey
+	case "cartesia":
+		return o.CartesiaAPIKey
+	case "vertex-express":
+		return o.VertexExpressAPIKey
+	case "hume":
+		return o.HumeAPIKey
+	case "deepgram":
+		return o.DeepgramAPIKey
+	}
+	return ""
 }
 
 // CLICommand returns a reproducible CLI command for the current options.
@@ -69,12 +313,69 @@ func (o Options) CLICommand() string {
 	if o.Input != "" {
 		parts = append(parts, fmt.Sprintf("-i %q", o.Input))
 	}
+	for _, in := range o.ExtraInputs {
+		parts = append(parts, fmt.Sprintf("-i %q", in))
+	}
+	if o.Chapters != "" {
+		parts = append(parts, fmt.Sprintf("--chapters %q", o.Chapters))
+	}
 	if o.FromScript != "" {
 		parts = append(parts, fmt.Sprintf("--from-script %q", o.FromScript))
 	}
+	if o.ResumeTTS != "" {
+		parts = append(parts, fmt.Sprintf("--resume-tts %q", o.ResumeTTS))
+	}
+	if o.Music != "" {
+		parts = append(parts, fmt.Sprintf("--music %q", o.Music))
+		if o.MusicVolume != 0 {
+			parts = append(parts, fmt.Sprintf("--music-volume %.1f", o.MusicVolume))
+		}
+	}
+	if o.Intro != "" {
+		parts = append(parts, fmt.Sprintf("--intro %q", o.Intro))
+	}
+	if o.Outro != "" {
+		parts = append(parts, fmt.Sprintf("--outro %q", o.Outro))
+	}
+	if o.NoLoudnorm {
+		parts = append(parts, "--no-loudnorm")
+	}
+	if o.QC != "" && o.QC != QCWarn {
+		parts = append(parts, "--qc", o.QC)
+	}
+	if o.QCBounds != "" {
+		parts = append(parts, fmt.Sprintf("--qc-bounds %q", o.QCBounds))
+	}
+	if o.Show != "" {
+		parts = append(parts, fmt.Sprintf("--show %q", o.Show))
+	}
+	if o.Cover != "" {
+		parts = append(parts, fmt.Sprintf("--cover %q", o.Cover))
+	}
 	if o.Output != "" {
 		parts = append(parts, fmt.Sprintf("-o %q", o.Output))
 	}
+	if o.OutputFormat != "" && o.OutputFormat != assembly.FormatMP3 {
+		parts = append(parts, "--output-format", string(o.OutputFormat))
+	}
+	if o.Encoding.Bitrate != "" {
+		parts = append(parts, "--bitrate", o.Encoding.Bitrate)
+	}
+	if o.Encoding.Channels != "" {
+		parts = append(parts, "--channels", o.Encoding.Channels)
+	}
+	if o.Pacing.Gap != 0 {
+		parts = append(parts, "--gap", o.Pacing.Gap.String())
+	}
+	if o.Pacing.Crossfade != 0 {
+		parts = append(parts, "--crossfade", o.Pacing.Crossfade.String())
+	}
+	if o.Pacing.Trim != (assembly.SilenceTrim{}) {
+		parts = append(parts, "--trim-silence", o.Pacing.Trim.String())
+	}
+	if o.Explicit != nil {
+		parts = append(parts, fmt.Sprintf("--explicit=%t", *o.Explicit))
+	}
 	if o.Model != "" && o.Model != "haiku" {
 		parts = append(parts, "--model", o.Model)
 	}
@@ -102,26 +403,26 @@ func (o Options) CLICommand() string {
 	if o.Voices != 0 && o.Voices != 2 {
 		parts = append(parts, fmt.Sprintf("--voices %d", o.Voices))
 	}
-	if o.Voice1 != "" {
+	if o.Voice1 != "" || !o.Voice1Settings.IsZero() {
 		v := o.Voice1
-		if o.Voice1Provider != "" {
+		if o.Voice1Provider != "" && v != "" {
 			v = o.Voice1Provider + ":" + v
 		}
-		parts = append(parts, "--voice1", v)
+		parts = append(parts, "--voice1", v+o.Voice1Settings.String())
 	}
-	if o.Voice2 != "" {
+	if o.Voice2 != "" || !o.Voice2Settings.IsZero() {
 		v := o.Voice2
-		if o.Voice2Provider != "" {
+		if o.Voice2Provider != "" && v != "" {
 			v = o.Voice2Provider + ":" + v
 		}
-		parts = append(parts, "--voice2", v)
+		parts = append(parts, "--voice2", v+o.Voice2Settings.String())
 	}
-	if o.Voice3 != "" {
+	if o.Voice3 != "" || !o.Voice3Settings.IsZero() {
 		v := o.Voice3
-		if o.Voice3Provider != "" {
+		if o.Voice3Provider != "" && v != "" {
 			v = o.Voice3Provider + ":" + v
 		}
-		parts = append(parts, "--voice3", v)
+		parts = append(parts, "--voice3", v+o.Voice3Settings.String())
 	}
 	if o.TTSSpeed != 0 {
 		parts = append(parts, fmt.Sprintf("--tts-speed %.2f", o.TTSSpeed))
@@ -132,6 +433,72 @@ func (o Options) CLICommand() string {
 	if o.TTSPitch != 0 {
 		parts = append(parts, fmt.Sprintf("--tts-pitch %.2f", o.TTSPitch))
 	}
+	if o.TTSRetry.MaxAttempts > 0 {
+		parts = append(parts, fmt.Sprintf("--tts-max-retries %d", o.TTSRetry.MaxAttempts-1))
+	}
+	if o.TTSRetry.InitialBackoff > 0 {
+		parts = append(parts, fmt.Sprintf("--tts-backoff %s", o.TTSRetry.InitialBackoff))
+	}
+	if o.TTSRetry.MaxBackoff > 0 {
+		parts = append(parts, fmt.Sprintf("--tts-max-backoff %s", o.TTSRetry.MaxBackoff))
+	}
+	if o.TTSBreakerThreshold != 0 {
+		parts = append(parts, fmt.Sprintf("--tts-breaker %d", max(o.TTSBreakerThreshold, 0)))
+	}
+	if o.TTSConcurrency != 0 && o.TTSConcurrency != DefaultTTSConcurrency {
+		parts = append(parts, fmt.Sprintf("--tts-concurrency %d", o.TTSConcurrency))
+	}
+	if o.NoTTSCache {
+		parts = append(parts, "--no-tts-cache")
+	}
+	if o.DisableBatch {
+		parts = append(parts, "--no-batch")
+	}
+	if o.DisableKeepAlives {
+		parts = append(parts, "--no-keepalive")
+	}
+	if o.SSMLHints {
+		parts = append(parts, "--ssml-hints")
+	}
+	if o.DeliveryHints {
+		parts = append(parts, "--delivery-hints")
+	}
+	if o.SFX {
+		parts = append(parts, "--sfx")
+	}
+	if o.TTSStylePrompts {
+		parts = append(parts, "--tts-style-prompts")
+	}
+	if o.Lexicon != "" {
+		parts = append(parts, fmt.Sprintf("--lexicon %q", o.Lexicon))
+	}
+	if len(o.TTSFallback) > 0 {
+		parts = append(parts, fmt.Sprintf("--tts-fallback %s", strings.Join(o.TTSFallback, ",")))
+	}
+	if t := o.Timeouts.String(); t != "" {
+		parts = append(parts, fmt.Sprintf("--stage-timeouts %s", t))
+	}
+	if b := o.Budgets.String(); b != "" {
+		parts = append(parts, fmt.Sprintf("--stage-budgets %s", b))
+	}
+	if o.Transcriber != "" {
+		parts = append(parts, "--transcriber", o.Transcriber)
+	}
+	if o.CookieJar != "" {
+		parts = append(parts, fmt.Sprintf("--cookie-jar %q", o.CookieJar))
+	}
+	if o.WhisperModel != "" {
+		parts = append(parts, fmt.Sprintf("--whisper-model %q", o.WhisperModel))
+	}
+	if o.EscalateModel != "" {
+		parts = append(parts, "--escalate-model", o.EscalateModel)
+	}
+	if o.VerifySpeakers {
+		parts = append(parts, "--verify-speakers")
+	}
+	if o.Preview > 0 {
+		parts = append(parts, fmt.Sprintf("--preview %d", o.Preview))
+	}
 	if o.ScriptOnly {
 		parts = append(parts, "--script-only")
 	}
@@ -142,6 +509,10 @@ type PipelineError struct {
 	Stage   string
 	Message string
 	Err     error
+
+	// Kind classifies the failure when the stage knows it (bad input);
+	// otherwise the cause's kind is used (see ErrorKind).
+	Kind errkind.Kind
 }
 
 func (e *PipelineError) Error() string {
@@ -155,6 +526,14 @@ func (e *PipelineError) Unwrap() error {
 	return e.Err
 }
 
+// ErrorKind implements errkind.Classified.
+func (e *PipelineError) ErrorKind() errkind.Kind {
+	if e.Kind != "" {
+		return e.Kind
+	}
+	return errkind.Of(e.Err)
+}
+
 // EnsureOutputDirs creates the podcaster-output directory structure.
 func EnsureOutputDirs() error {
 	dirs := []string{
@@ -187,7 +566,15 @@ func LogFilePath(output string) string {
 	return filepath.Join(OutputBaseDir, "logs", name+".log")
 }
 
+// Run generates an episode (or only its script) as opts describe.
 func Run(ctx context.Context, opts Options) error {
+	stages := newStageSpans(ctx, opts.Budgets)
+	err := run(ctx, opts, stages)
+	stages.end(err)
+	return err
+}
+
+func run(ctx context.Context, opts Options, stages *stageSpans) (runErr error) {
 	pipelineStart := time.Now()
 
 	// Ensure output directories exist
@@ -214,11 +601,25 @@ func Run(ctx context.Context, opts Options) error {
 			logWriter = lf
 		}
 	}
+	if opts.LogWriter != nil {
+		logWriter = io.MultiWriter(logWriter, opts.LogWriter)
+	}
 	logger := log.New(logWriter, "", log.LstdFlags)
 	logf := func(format string, args ...interface{}) {
 		logger.Printf(format, args...)
 	}
 
+	// The stage report is logged and handed to OnStageTimings when the run
+	// ends, whether or not it succeeded.
+	stages.logf = logf
+	defer func() {
+		stages.end(runErr)
+		logStageReport(stages.timings, logf)
+		if opts.OnStageTimings != nil && len(stages.timings) > 0 {
+			opts.OnStageTimings(stages.timings)
+		}
+	}()
+
 	// Progress emit helper
 	emit := func(stage progress.Stage, msg string, pct float64) {
 		if opts.OnProgress != nil {
@@ -229,6 +630,25 @@ func Run(ctx context.Context, opts Options) error {
 	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// Every TTS request is recorded here; the per-provider table is logged
+	// when the run ends, whether or not it succeeded.
+	ttsMetrics := opts.TTSMetrics
+	if ttsMetrics == nil {
+		ttsMetrics = tts.NewMetrics()
+	}
+	ctx = tts.ContextWithMetrics(ctx, ttsMetrics)
+	defer logTTSSummary(ttsMetrics, logf)
+
+	var prof *profiler
+	if opts.ProfileDir != "" {
+		p, err := startProfile(opts.ProfileDir, logf)
+		if err != nil {
+			return err
+		}
+		prof = p
+		defer prof.finish(logf)
+	}
+
 	if opts.Output != "" {
 		logf("Pipeline started — output: %s", opts.Output)
 	} else {
@@ -243,6 +663,13 @@ func Run(ctx context.Context, opts Options) error {
 		logf("Config: styles=%s", strings.Join(opts.Styles, ","))
 	}
 	logf("Equivalent CLI: %s", opts.CLICommand())
+	timeouts := opts.Timeouts.WithDefaults()
+	if opts.Timeouts != (Timeouts{}) {
+		logf("Config: stage-timeouts=%s", timeouts)
+	}
+	if len(opts.Features) > 0 {
+		logf("Config: features=%s", opts.Features)
+	}
 
 	// Resolve voice map early so we can use voice names as speaker labels in scripts
 	ps := tts.NewProviderSet()
@@ -254,27 +681,79 @@ func Run(ctx context.Context, opts Options) error {
 		Speed:     opts.TTSSpeed,
 		Stability: opts.TTSStability,
 		Pitch:     opts.TTSPitch,
+		Retry:     opts.TTSRetry,
+
+		DisableKeepAlives: opts.DisableKeepAlives,
+	}
+	if opts.TTSStylePrompts {
+		ttsCfg.StylePrompts = true
+		ttsCfg.StyleTone = tts.StyleTone(opts.Styles)
+		logf("Config: tts-style-prompts tone=%q", ttsCfg.StyleTone)
 	}
 	// Set provider-specific API key overrides
 	setTTSConfigs := func() {
 		providers := []string{opts.Voice1Provider, opts.Voice2Provider, opts.Voice3Provider, opts.DefaultTTS}
+		primary := len(providers)
+		providers = append(providers, opts.TTSFallback...)
 		seen := map[string]bool{}
-		for _, p := range providers {
+		for i, p := range providers {
 			if p == "" || seen[p] {
 				continue
 			}
 			seen[p] = true
 			cfg := ttsCfg
-			switch p {
-			case "gemini":
-				cfg.APIKey = opts.GeminiAPIKey
-			case "elevenlabs":
-				cfg.APIKey = opts.ElevenLabsAPIKey
+			if i >= primary {
+				// Fallback-only providers use their own defaults; the model
+				// and tuning flags were chosen for the primary provider.
+				cfg = tts.ProviderConfig{
+					Retry:             opts.TTSRetry,
+					DisableKeepAlives: opts.DisableKeepAlives,
+					StylePrompts:      ttsCfg.StylePrompts,
+					StyleTone:         ttsCfg.StyleTone,
+				}
 			}
+			cfg.APIKey = opts.TTSAPIKey(p)
 			ps.SetConfig(p, cfg)
 		}
 	}
 	setTTSConfigs()
+	if opts.TTSBreakerThreshold != 0 {
+		ps.SetBreakerThreshold(opts.TTSBreakerThreshold)
+	}
+	ps.SetFallbacks(opts.TTSFallback)
+	if len(opts.TTSFallback) > 0 {
+		logf("Config: tts-fallback=%s", strings.Join(opts.TTSFallback, ","))
+	}
+
+	ttsConcurrency := opts.TTSConcurrency
+	if ttsConcurrency <= 0 {
+		ttsConcurrency = DefaultTTSConcurrency
+		if opts.Features.On(feature.ParallelTTS) {
+			ttsConcurrency = 2 * DefaultTTSConcurrency
+		}
+	}
+
+	// Per-segment TTS cache: lets a re-run (after a mid-run failure, or with
+	// --from-script) skip segments that were already synthesized.
+	var ttsCache *tts.Cache
+	if !opts.NoTTSCache {
+		c, err := tts.NewCache(filepath.Join(OutputBaseDir, "cache"), tts.DefaultCacheMaxBytes)
+		if err != nil {
+			logf("WARNING: TTS cache disabled: %v", err)
+		} else {
+			ttsCache = c
+		}
+	}
+
+	var lexicon *tts.Lexicon
+	if opts.Lexicon != "" {
+		l, err := tts.LoadLexicon(opts.Lexicon)
+		if err != nil {
+			return &PipelineError{Stage: "tts", Message: "failed to load pronunciation lexicon", Err: err, Kind: errkind.UserInput}
+		}
+		lexicon = l
+		logf("Config: lexicon=%s (%d terms)", opts.Lexicon, lexicon.Len())
+	}
 
 	voices := tts.VoiceMap{}
 	if opts.Voice1 != "" {
@@ -287,6 +766,7 @@ func Run(ctx context.Context, opts Options) error {
 		dv := p.DefaultVoices()
 		voices.Host1 = tts.Voice{ID: dv.Host1.ID, Name: dv.Host1.Name, Provider: opts.Voice1Provider}
 	}
+	voices.Host1.Settings = opts.Voice1Settings
 	if opts.Voice2 != "" {
 		voices.Host2 = tts.Voice{ID: opts.Voice2, Name: opts.Voice2, Provider: opts.Voice2Provider}
 	} else {
@@ -297,6 +777,7 @@ func Run(ctx context.Context, opts Options) error {
 		dv := p.DefaultVoices()
 		voices.Host2 = tts.Voice{ID: dv.Host2.ID, Name: dv.Host2.Name, Provider: opts.Voice2Provider}
 	}
+	voices.Host2.Settings = opts.Voice2Settings
 	if opts.Voice3 != "" {
 		voices.Host3 = tts.Voice{ID: opts.Voice3, Name: opts.Voice3, Provider: opts.Voice3Provider}
 	} else {
@@ -307,6 +788,16 @@ func Run(ctx context.Context, opts Options) error {
 		dv := p.DefaultVoices()
 		voices.Host3 = tts.Voice{ID: dv.Host3.ID, Name: dv.Host3.Name, Provider: opts.Voice3Provider}
 	}
+	voices.Host3.Settings = opts.Voice3Settings
+
+	// Check explicit ElevenLabs voice IDs (including cloned voices) against
+	// the account before spending anything on script generation.
+	if !opts.ScriptOnly {
+		if err := validateElevenLabsVoices(ctx, opts, logf); err != nil {
+			logf("ERROR: %v", err)
+			return &PipelineError{Stage: "tts", Message: "invalid ElevenLabs voice", Err: err}
+		}
+	}
 
 	// Set dynamic speaker names from voice names
 	voices.SpeakerNames = [3]string{voices.Host1.Name, voices.Host2.Name, voices.Host3.Name}
@@ -322,34 +813,170 @@ func Run(ctx context.Context, opts Options) error {
 		speakerNames = []string{voices.Host1.Name, voices.Host2.Name}
 	}
 
+	// Without FFmpeg, segments are joined in Go (native.go), which can't mix
+	// or re-encode.
+	native := !opts.ScriptOnly && !assembly.HasFFmpeg()
+	if native {
+		if err := CheckNative(opts); err != nil {
+			logf("ERROR: %v", err)
+			return &PipelineError{Stage: "assembly", Message: "FFmpeg not found", Err: err, Kind: errkind.UserInput}
+		}
+		logf("WARNING: FFmpeg not found; joining segments without it (no silence trimming, loudness normalization, tags, or cover art)")
+	}
+
+	if err := ValidateQCMode(opts.QC); err != nil {
+		return &PipelineError{Stage: "assembly", Message: "invalid quality check mode", Err: err, Kind: errkind.UserInput}
+	}
+	qcBounds, err := assembly.ParseQCBounds(opts.QCBounds, assembly.DefaultQCBounds(opts.Encoding.ChannelCount()))
+	if err != nil {
+		return &PipelineError{Stage: "assembly", Message: "invalid quality bounds", Err: err, Kind: errkind.UserInput}
+	}
+
+	if opts.Music != "" {
+		if err := assembly.ValidateMusic(opts.Music); err != nil {
+			return &PipelineError{Stage: "assembly", Message: "invalid music bed", Err: err, Kind: errkind.UserInput}
+		}
+	}
+	for _, path := range []string{opts.Intro, opts.Outro} {
+		if path == "" {
+			continue
+		}
+		if err := assembly.ValidateStinger(path); err != nil {
+			return &PipelineError{Stage: "assembly", Message: "invalid intro/outro", Err: err, Kind: errkind.UserInput}
+		}
+	}
+	if opts.Cover != "" {
+		if err := assembly.ValidateCover(opts.Cover); err != nil {
+			return &PipelineError{Stage: "assembly", Message: "invalid cover art", Err: err, Kind: errkind.UserInput}
+		}
+	}
+
+	if opts.VerifySpeakers && !opts.ScriptOnly {
+		if _, err := diarize.NewDeepgram(opts.DeepgramAPIKey); err != nil {
+			return &PipelineError{Stage: "assembly", Message: "cannot check speakers", Err: err, Kind: errkind.UserInput}
+		}
+	}
+	if opts.Preview < 0 {
+		return &PipelineError{Stage: "tts", Message: fmt.Sprintf("invalid preview %d: must be a number of segments", opts.Preview), Kind: errkind.UserInput}
+	}
+	if opts.Preview > 0 && opts.ResumeTTS != "" {
+		return &PipelineError{Stage: "tts", Message: "a preview can't resume a failed run", Kind: errkind.UserInput}
+	}
+
+	var resumePlan AudioPlan
+	if opts.ResumeTTS != "" {
+		plan, err := LoadPlan(opts.ResumeTTS)
+		if err != nil {
+			return &PipelineError{Stage: "tts", Message: "failed to load resume directory", Err: err, Kind: errkind.UserInput}
+		}
+		resumePlan = plan
+		if opts.FromScript == "" {
+			opts.FromScript = plan.Script
+		}
+	}
+
+	// Warm the TTS providers while ingest and script generation run.
+	var warmup *ttsWarmup
+	if !opts.ScriptOnly && opts.FromScript == "" {
+		warmup = startTTSWarmup(ctx, ps, ttsProviders(voices, opts.Voices))
+		defer warmup.stop()
+	}
+
 	var s *script.Script
+	var escalation *ScriptEscalation
+	var ingestHash string // for the run checkpoint
 
 	if opts.FromScript != "" {
+		prof.begin("script")
+		ctx = stages.begin(ctx, "script", "", attribute.Bool("from_script", true))
 		logf("Loading script from %s...", opts.FromScript)
 		loaded, err := script.LoadScript(opts.FromScript)
 		if err != nil {
 			logf("ERROR: failed to load script: %v", err)
-			return &PipelineError{Stage: "script", Message: "failed to load script", Err: err}
+			return &PipelineError{Stage: "script", Message: "failed to load script", Err: err, Kind: errkind.UserInput}
 		}
 		s = loaded
 		logf("Script loaded: %d segments", len(s.Segments))
 	} else {
+		inputs, repeated := ingest.DedupeInputs(append([]string{opts.Input}, opts.ExtraInputs...))
+		for _, in := range repeated {
+			logf("WARNING: input %s given more than once; using it once", in)
+		}
+
+		// Audio and video inputs are transcribed first (media.go); their
+		// transcripts are ingested in their place.
+		ingestPct := 0.0
+		if media := mediaInputs(inputs); len(media) > 0 {
+			if !assembly.HasFFmpeg() {
+				return &PipelineError{Stage: "transcribe", Message: "FFmpeg is needed to transcribe audio and video inputs", Kind: errkind.UserInput}
+			}
+			t, err := transcribe.New(transcribe.Options{Engine: opts.Transcriber, OpenAIAPIKey: opts.OpenAIAPIKey, WhisperModel: opts.WhisperModel})
+			if err != nil {
+				logf("ERROR: transcriber: %v", err)
+				return &PipelineError{Stage: "transcribe", Message: "no transcriber for audio and video inputs", Err: err}
+			}
+			prof.begin("transcribe")
+			ctx = stages.begin(ctx, "transcribe", t.Name(), attribute.Int("files", len(media)), attribute.String("engine", t.Name()))
+			logf("Transcribing %s with %s", strings.Join(media, ", "), t.Name())
+			emit(progress.StageTranscribe, "Transcribing audio...", 0.0)
+			transcribeCtx, transcribeCancel := context.WithTimeout(ctx, timeouts.Transcribe)
+			inputs, err = transcribeInputs(transcribeCtx, inputs, t, func(msg string, done float64) {
+				emit(progress.StageTranscribe, msg, 0.04*done)
+			}, logf)
+			transcribeCancel()
+			err = stageTimeout(ctx, transcribeCtx, "transcribe", timeouts.Transcribe, err)
+			if err != nil {
+				logf("ERROR: transcription failed: %v", err)
+				return &PipelineError{Stage: "transcribe", Message: "failed to transcribe audio", Err: err}
+			}
+			emit(progress.StageTranscribe, "Transcription complete", 0.04)
+			ingestPct = 0.04
+		}
+
 		// Stage 1: Ingest
+		prof.begin("ingest")
+		ctx = stages.begin(ctx, "ingest", "", attribute.Int("inputs", len(inputs)))
 		stageStart := time.Now()
-		emit(progress.StageIngest, "Ingesting content...", 0.0)
-		logf("Stage 1/4: Ingesting content from %s", opts.Input)
-		ingester := ingest.NewIngester(opts.Input)
-		content, err := ingester.Ingest(ctx, opts.Input)
+		emit(progress.StageIngest, "Ingesting content...", ingestPct)
+		auth, err := ingest.NewAuth(opts.FetchHeaders, opts.CookieJar, inputs)
+		if err != nil {
+			logf("ERROR: fetch credentials: %v", err)
+			return &PipelineError{Stage: "ingest", Message: "invalid --header or --cookie-jar", Err: err, Kind: errkind.UserInput}
+		}
+		switch {
+		case len(opts.FetchHeaders) > 0 && len(auth.Hosts) == 0:
+			logf("WARNING: --header given but no input is a URL; not sent")
+		case len(opts.FetchHeaders) > 0:
+			logf("Config: fetch headers %s sent to %s", strings.Join(auth.HeaderNames(), ","), strings.Join(auth.Hosts, ","))
+		}
+		if opts.CookieJar != "" {
+			logf("Config: cookie-jar=%s", opts.CookieJar)
+		}
+		logf("Stage 1/4: Ingesting content from %s", strings.Join(inputs, ", "))
+		ingestCtx, ingestCancel := context.WithTimeout(ctx, timeouts.Ingest)
+		contents, err := ingestAll(ingest.ContextWithAuth(ingestCtx, auth), inputs, opts.Chapters)
+		err = stageTimeout(ctx, ingestCtx, "ingest", timeouts.Ingest, err)
+		ingestCancel()
 		if err != nil {
 			logf("ERROR: ingest failed: %v", err)
-			return &PipelineError{Stage: "ingest", Message: "failed to extract content", Err: err}
+			return &PipelineError{Stage: "ingest", Message: "failed to extract content", Err: err, Kind: errkind.UserInput}
+		}
+		content, dropped := ingest.Merge(contents)
+		for _, src := range dropped {
+			logf("WARNING: %s repeats the other sources; left out", src)
+		}
+		if opts.OnIngest != nil {
+			opts.OnIngest(content)
 		}
 		logf("Ingest complete: %d words from %s (%s)", content.WordCount, content.Source, time.Since(stageStart).Round(time.Millisecond))
+		ingestHash = inputHash(content.Text)
 		emit(progress.StageIngest, "Ingest complete", 0.05)
 
 		if opts.Verbose {
 			logf("  Title: %s", content.Title)
-			logf("  Source type: %s", ingest.DetectSource(opts.Input))
+			for _, in := range inputs {
+				logf("  Source type: %s (%s)", ingest.DetectSource(in), in)
+			}
 			logf("  Content size: %d bytes", len(content.Text))
 		}
 
@@ -358,74 +985,123 @@ func Run(ctx context.Context, opts Options) error {
 			return &PipelineError{
 				Stage:   "ingest",
 				Message: fmt.Sprintf("input too short (%d words, need at least %d) — the content may be behind a paywall, require JavaScript, or be mostly images; try a different URL or provide text directly", content.WordCount, ingest.MinWordCount),
+				Kind:    errkind.UserInput,
 			}
 		}
 
 		// Stage 2: Script Generation
+		prof.begin("script")
+		ctx = stages.begin(ctx, "script", opts.Model, attribute.String("model", opts.Model))
 		stageStart = time.Now()
-		modelName := script.ModelDisplayName(opts.Model)
-		emit(progress.StageScript, fmt.Sprintf("Generating script (%s)...", modelName), 0.05)
-		logf("Stage 2/4: Generating script with %s...", modelName)
-		// Choose the right API key for the script generation model
-		var scriptAPIKey string
-		switch opts.Model {
-		case "haiku", "sonnet":
-			scriptAPIKey = opts.AnthropicAPIKey
-		case "gemini-flash", "gemini-pro":
-			scriptAPIKey = opts.GeminiAPIKey
-		}
-		gen, err := script.NewGenerator(opts.Model, scriptAPIKey)
-		if err != nil {
-			logf("ERROR: failed to create script generator: %v", err)
-			return &PipelineError{Stage: "script", Message: "failed to create script generator", Err: err}
-		}
 		genOpts := script.GenerateOptions{
-			Topic:        opts.Topic,
-			Tone:         opts.Tone,
-			Duration:     opts.Duration,
-			Styles:       opts.Styles,
-			Model:        opts.Model,
-			Voices:       opts.Voices,
-			Format:       opts.Format,
-			SpeakerNames: speakerNames,
-		}
-		s, err = gen.Generate(ctx, content.Text, genOpts)
-		if err != nil {
-			logf("ERROR: script generation failed: %v", err)
-			return &PipelineError{Stage: "script", Message: "failed to generate script", Err: err}
+			Topic:         opts.Topic,
+			Tone:          opts.Tone,
+			Duration:      opts.Duration,
+			Styles:        opts.Styles,
+			Model:         opts.Model,
+			Voices:        opts.Voices,
+			Format:        opts.Format,
+			SpeakerNames:  speakerNames,
+			ProsodyHints:  opts.SSMLHints,
+			DeliveryHints: opts.DeliveryHints,
+			Sources:       content.Sources,
+			Community:     content.Community,
+		}
+		if opts.SFX {
+			genOpts.SFX = assembly.SFXNames()
+		}
+		var cacheKey string
+		if opts.ScriptCache != nil {
+			cacheKey = ScriptCacheKey(content.Text, genOpts)
+			if cached, ok := opts.ScriptCache.GetScript(ctx, cacheKey); ok {
+				s = cached
+				logf("Stage 2/4: Reusing cached script for identical content and options: %d segments, ~%d min", len(s.Segments), estimateMinutes(s))
+				emit(progress.StageScript, "Script reused from cache", 0.20)
+			}
 		}
-		logf("Script complete: %d segments, ~%d min (%s)", len(s.Segments), estimateMinutes(s), time.Since(stageStart).Round(time.Millisecond))
-		emit(progress.StageScript, "Script complete", 0.18)
 
-		// Stage 2b: Script review (always-on)
-		logf("Stage 2b: Reviewing script quality...")
-		reviewer, revErr := script.NewReviewer(opts.Model, scriptAPIKey)
-		if revErr != nil {
-			logf("WARNING: could not create reviewer: %v", revErr)
-		} else {
-			result, revErr := reviewer.Review(ctx, s, content.Text, genOpts)
-			if revErr != nil {
-				logf("WARNING: script review failed: %v", revErr)
-			} else {
-				for _, issue := range result.Issues {
-					logf("  Review [%s] %s: %s", issue.Severity, issue.Category, issue.Message)
-				}
-				if result.Approved {
-					logf("Script review passed")
-				} else if result.Revised != nil {
-					logf("Script revised: %d → %d segments", len(s.Segments), len(result.Revised.Segments))
-					s = result.Revised
-				} else {
-					logf("Script review found issues but revision was not possible")
-				}
+		if s == nil {
+			modelName := script.ModelDisplayName(opts.Model)
+			emit(progress.StageScript, fmt.Sprintf("Generating script (%s)...", modelName), 0.05)
+			logf("Stage 2/4: Generating script with %s...", modelName)
+			scriptAPIKey := opts.ScriptAPIKey(opts.Model)
+			gen, err := script.NewGenerator(opts.Model, scriptAPIKey)
+			if err != nil {
+				logf("ERROR: failed to create script generator: %v", err)
+				return &PipelineError{Stage: "script", Message: "failed to create script generator", Err: err}
+			}
+			// One limit covers generation and review; a review cut short
+			// keeps the generated script.
+			scriptCtx, scriptCancel := context.WithTimeout(ctx, timeouts.Script)
+			defer scriptCancel()
+			s, err = gen.Generate(scriptCtx, content.Text, genOpts)
+			err = stageTimeout(ctx, scriptCtx, "script", timeouts.Script, err)
+			if err != nil {
+				logf("ERROR: script generation failed: %v", err)
+				return &PipelineError{Stage: "script", Message: "failed to generate script", Err: err}
 			}
+			logf("Script complete: %d segments, ~%d min (%s)", len(s.Segments), estimateMinutes(s), time.Since(stageStart).Round(time.Millisecond))
+			emit(progress.StageScript, "Script complete", 0.18)
+
+			// Stage 2b: Script review (always-on)
+			logf("Stage 2b: Reviewing script quality...")
+			var remaining []script.ReviewIssue
+			s, remaining = reviewScript(scriptCtx, opts.Model, scriptAPIKey, s, content.Text, genOpts, logf)
+			if len(remaining) > 0 {
+				emit(progress.StageScript, "Retrying script with a stronger model...", 0.19)
+				s, escalation = escalateScript(ctx, timeouts.Script, opts, s, remaining, content.Text, genOpts, logf)
+			}
+			emit(progress.StageScript, "Review complete", 0.20)
+
+			if cacheKey != "" {
+				opts.ScriptCache.PutScript(ctx, cacheKey, s)
+			}
+		}
+	}
+
+	// Markers are always stripped, so none is read aloud; the effects only
+	// play with --sfx.
+	sfxPlaced, sfxDropped := placeSFX(s)
+	if len(sfxDropped) > 0 {
+		logf("WARNING: dropped sound effects (unknown, repeated, or on the first segment): %s", strings.Join(sfxDropped, ", "))
+	}
+	switch {
+	case opts.SFX:
+		logf("SFX: %d sound effects placed", sfxPlaced)
+	case sfxPlaced > 0:
+		logf("SFX: %d sound effect markers ignored (no --sfx)", sfxPlaced)
+	}
+
+	// Without --ssml-hints, brackets and asterisks in the text are the
+	// writer's, not hints.
+	if opts.SSMLHints {
+		if n := prepareSSML(s); n > 0 {
+			logf("SSML: %d segments carry prosody hints", n)
 		}
-		emit(progress.StageScript, "Review complete", 0.20)
+	}
+
+	if opts.Disclaimer != "" {
+		s.Segments = append(s.Segments, script.Segment{Speaker: speakerNames[0], Text: opts.Disclaimer})
+		logf("Disclaimer appended as final segment")
+	}
+
+	if opts.Explicit != nil {
+		s.Explicit = *opts.Explicit
+		logf("Explicit: %t (--explicit)", s.Explicit)
+	} else if terms := script.ExplicitTerms(s); len(terms) > 0 {
+		s.Explicit = true
+		logf("Explicit: true (detected: %s)", strings.Join(terms, ", "))
+	} else {
+		s.Explicit = false
+		logf("Explicit: false (nothing detected)")
 	}
 
 	// Auto-name output from script title if output was not specified
 	if opts.Output == "" {
-		autoName := AutoOutputName(s.Title)
+		autoName := AutoOutputName(s.Title, opts.OutputFormat)
+		if opts.Preview > 0 && !opts.ScriptOnly {
+			autoName = previewName(autoName)
+		}
 		opts.Output = filepath.Join(OutputBaseDir, "episodes", autoName)
 		opts.LogFile = LogFilePath(autoName)
 
@@ -434,11 +1110,14 @@ func Run(ctx context.Context, opts Options) error {
 			lf2, err := os.Create(opts.LogFile)
 			if err == nil {
 				defer lf2.Close()
+				var w io.Writer = lf2
 				if opts.Verbose {
-					logger.SetOutput(io.MultiWriter(os.Stdout, lf2))
-				} else {
-					logger.SetOutput(lf2)
+					w = io.MultiWriter(os.Stdout, lf2)
 				}
+				if opts.LogWriter != nil {
+					w = io.MultiWriter(w, opts.LogWriter)
+				}
+				logger.SetOutput(w)
 			}
 		}
 		logf("Auto-named output: %s", opts.Output)
@@ -461,8 +1140,23 @@ func Run(ctx context.Context, opts Options) error {
 		return nil
 	}
 
+	if opts.Preview > 0 {
+		total := len(s.Segments)
+		s = previewScript(s, opts.Preview, opts.Disclaimer != "")
+		if len(s.Segments) < total {
+			logf("Preview: synthesizing %d of %d segments; generate the full episode with --from-script %s", len(s.Segments), total, scriptPath)
+		} else {
+			logf("Preview: the script has only %d segments; synthesizing all of them", total)
+		}
+	}
+
 	// Stage 3: TTS
+	prof.begin("tts")
+	ctx = stages.begin(ctx, "tts", voices.Host1.Provider, attribute.Int("segments", len(s.Segments)), attribute.String("provider", voices.Host1.Provider))
 	stageStart := time.Now()
+	if warmup != nil {
+		warmup.report(logf)
+	}
 	emit(progress.StageTTS, fmt.Sprintf("Synthesizing audio (%d segments)...", len(s.Segments)), 0.20)
 
 	// Log voice routing
@@ -494,6 +1188,9 @@ func Run(ctx context.Context, opts Options) error {
 		logf("  Voice 3 (%s): %s [%s]", voices.Host3.Name, voices.Host3.ID, voices.Host3.Provider)
 	}
 
+	// Per-segment assembly measures each segment, for the transcript.
+	var segmentSecs, segmentStarts []float64
+
 	if singleProvider {
 		provider, err := ps.Get(voices.Host1.Provider)
 		if err != nil {
@@ -503,58 +1200,123 @@ func Run(ctx context.Context, opts Options) error {
 		// Check if provider supports batch synthesis (e.g., Gemini multi-speaker)
 		// Batch mode sends all segments in one HTTP request — fast but requires
 		// sustained connections. DisableBatch forces per-segment synthesis.
-		if bp, ok := provider.(tts.BatchProvider); ok && !opts.DisableBatch {
-			result, err := bp.SynthesizeBatch(ctx, s.Segments, voices)
+		bp, useBatch := provider.(tts.BatchProvider)
+		useBatch = useBatch && !opts.DisableBatch
+		// Resuming picks up individual segments, which a batch can't.
+		useBatch = useBatch && opts.ResumeTTS == ""
+		// One batch stream can only take one set of emulated effects, so
+		// per-voice speed or pitch needs per-segment synthesis.
+		if useBatch && (!voices.Host1.Settings.IsZero() || !voices.Host2.Settings.IsZero() || !voices.Host3.Settings.IsZero()) {
+			logf("Per-voice settings set; using per-segment synthesis instead of batch")
+			useBatch = false
+		}
+		// Effects go between segments, which a batch doesn't return.
+		if useBatch && opts.SFX && hasSFX(s) {
+			logf("Sound effects placed; using per-segment synthesis instead of batch")
+			useBatch = false
+		}
+		if useBatch {
+			// The batch audio is streamed to disk as it is decoded (see
+			// tts.SynthesizeBatchToFile) rather than held in memory: a long
+			// episode is hundreds of MB of PCM.
+			tmpParent := filepath.Join(OutputBaseDir, "tempfiles")
+			os.MkdirAll(tmpParent, 0755)
+			tmpDir, err := os.MkdirTemp(tmpParent, "run-*")
 			if err != nil {
-				logf("ERROR: batch synthesis failed: %v", err)
-				return &PipelineError{Stage: "tts", Message: "batch synthesis failed", Err: err}
+				return &PipelineError{Stage: "tts", Message: "failed to create temp directory", Err: err}
+			}
+			rawPath := filepath.Join(tmpDir, "batch_output.raw")
+			rawFile, err := os.Create(rawPath)
+			if err != nil {
+				os.RemoveAll(tmpDir)
+				return &PipelineError{Stage: "tts", Message: "failed to create raw audio file", Err: err}
 			}
 
-			logf("TTS complete: format=%s (%s)", result.Format, time.Since(stageStart).Round(time.Millisecond))
-			emit(progress.StageTTS, "TTS complete", 0.90)
-
-			// Convert to MP3 if needed, or write directly
-			if result.Format != tts.FormatMP3 {
-				tmpParent := filepath.Join(OutputBaseDir, "tempfiles")
-				os.MkdirAll(tmpParent, 0755)
-				tmpDir, err := os.MkdirTemp(tmpParent, "run-*")
-				if err != nil {
-					return &PipelineError{Stage: "tts", Message: "failed to create temp directory", Err: err}
-				}
-
-				rawPath := filepath.Join(tmpDir, "batch_output.raw")
-				if err := os.WriteFile(rawPath, result.Data, 0644); err != nil {
-					return &PipelineError{Stage: "tts", Message: "failed to write raw audio", Err: err}
-				}
-				emit(progress.StageAssembly, "Assembling episode...", 0.90)
-				logf("Stage 4/4: Converting to MP3...")
-				if err := assembly.ConvertToMP3(ctx, rawPath, string(result.Format), opts.Output); err != nil {
-					logf("ERROR: MP3 conversion failed: %v", err)
-					logf("  Raw audio preserved in: %s", tmpDir)
-					return &PipelineError{Stage: "assembly", Message: "failed to convert audio to MP3", Err: err}
+			batchCtx, batchCancel := context.WithTimeout(ctx, timeouts.TTSBatch)
+			format, err := tts.SynthesizeBatchToFile(batchCtx, bp, plainSegments(s.Segments, lexicon), voices, ps.Config(provider.Name()).Retry, rawFile, func(done, total int) {
+				if total > 1 {
+					logf("  Batch chunk %d/%d complete", done, total)
+					emit(progress.StageTTS, fmt.Sprintf("Synthesized batch %d/%d", done, total), 0.20+0.70*float64(done)/float64(total))
 				}
+			})
+			err = stageTimeout(ctx, batchCtx, "tts-batch", timeouts.TTSBatch, err)
+			batchCancel()
+			if cerr := rawFile.Close(); err == nil && cerr != nil {
+				err = fmt.Errorf("write raw audio: %w", cerr)
+			}
+			if err != nil {
 				os.RemoveAll(tmpDir)
+				next, hasFallback := ps.Fallback()
+				if !tts.IsQuotaExhausted(err) || !hasFallback {
+					logf("ERROR: batch synthesis failed: %v", err)
+					return &PipelineError{Stage: "tts", Message: "batch synthesis failed", Err: err}
+				}
+				// Quota gone mid-batch: synthesize the whole script
+				// per-segment on the fallback provider instead.
+				logf("WARNING: %s quota exhausted; falling back to per-segment synthesis via %s", provider.Name(), next)
+				ps.MarkExhausted(provider.Name())
+				useBatch = false
 			} else {
-				if err := os.WriteFile(opts.Output, result.Data, 0644); err != nil {
-					return &PipelineError{Stage: "tts", Message: "failed to write output", Err: err}
+				logf("TTS complete: format=%s (%s)", format, time.Since(stageStart).Round(time.Millisecond))
+				emit(progress.StageTTS, "TTS complete", 0.90)
+
+				// Convert to the output format (applying emulated
+				// speed/pitch) if needed, or move into place directly
+				speed, pitch := tts.Emulated(provider.Name(), ps.Config(provider.Name()))
+				fx := assembly.Effects{Speed: speed, Pitch: pitch}
+				outFormat := assembly.FormatOf(opts.Output)
+				if format != tts.FormatMP3 || outFormat != assembly.FormatMP3 || !fx.IsZero() || !opts.Encoding.IsZero() {
+					prof.begin("assembly")
+					ctx = stages.begin(ctx, "assembly", "")
+					emit(progress.StageAssembly, "Assembling episode...", 0.90)
+					logf("Stage 4/4: Converting to %s...", strings.ToUpper(string(outFormat)))
+					var err error
+					if native {
+						err = convertNative(rawPath, format, opts.Output, fx)
+					} else {
+						asmCtx, asmCancel := context.WithTimeout(ctx, timeouts.Assembly)
+						err = assembly.ConvertWithEffects(asmCtx, rawPath, string(format), opts.Output, fx, opts.Encoding)
+						err = stageTimeout(ctx, asmCtx, "assembly", timeouts.Assembly, err)
+						asmCancel()
+					}
+					if err != nil {
+						logf("ERROR: %s conversion failed: %v", strings.ToUpper(string(outFormat)), err)
+						logf("  Raw audio preserved in: %s", tmpDir)
+						return &PipelineError{Stage: "assembly", Message: "failed to convert audio", Err: err}
+					}
+				} else if err := os.Rename(rawPath, opts.Output); err != nil {
+					// Rename fails across filesystems; MP3 is small enough to copy.
+					data, err := os.ReadFile(rawPath)
+					if err == nil {
+						err = os.WriteFile(opts.Output, data, 0644)
+					}
+					if err != nil {
+						os.RemoveAll(tmpDir)
+						return &PipelineError{Stage: "tts", Message: "failed to write output", Err: err}
+					}
 				}
+				os.RemoveAll(tmpDir)
 			}
+		}
 
+		if useBatch {
 			logf("Assembly skipped (batch provider)")
 		} else {
 			// Single provider, per-segment synthesis
-			tmpParent := filepath.Join(OutputBaseDir, "tempfiles")
-			os.MkdirAll(tmpParent, 0755)
-			tmpDir, err := os.MkdirTemp(tmpParent, "run-*")
+			tmpDir, reuse, err := segmentWorkDir(opts.ResumeTTS, resumePlan, s, voices, logf)
 			if err != nil {
 				return &PipelineError{Stage: "tts", Message: "failed to create temp directory", Err: err}
 			}
-			logf("  Temp directory: %s", tmpDir)
 
-			audioFiles, err := synthesizeSegments(ctx, provider, s.Segments, voices, tmpDir, logf, opts.OnProgress, pipelineStart)
+			var cp *checkpoint
+			if opts.Checkpoint && opts.Preview == 0 {
+				cp = startCheckpoint(tmpDir, scriptPath, ingestHash, s, voices, reuse, opts, logf)
+			}
+			audioFiles, err := synthesizeSegments(ctx, ps, ttsCache, lexicon, ttsConcurrency, timeouts.TTSSegment, s.Segments, reuse, voices, tmpDir, cp, logf, opts.OnProgress, pipelineStart)
 			if err != nil {
 				logf("ERROR: TTS synthesis failed: %v", err)
 				logf("  Segments preserved in: %s", tmpDir)
+				err = partialFailure(tmpDir, scriptPath, s, voices, audioFiles, err, logf)
 				return &PipelineError{Stage: "tts", Message: "failed to synthesize audio", Err: err}
 			}
 
@@ -571,11 +1333,23 @@ func Run(ctx context.Context, opts Options) error {
 			}
 
 			// Stage 4: Assembly
+			prof.begin("assembly")
+			ctx = stages.begin(ctx, "assembly", "")
 			stageStart = time.Now()
 			emit(progress.StageAssembly, "Assembling episode...", 0.90)
 			logf("Stage 4/4: Assembling episode...")
-			assembler := assembly.NewFFmpegAssembler()
-			if err := assembler.Assemble(ctx, audioFiles, tmpDir, opts.Output); err != nil {
+			pacing := opts.Pacing
+			pacing.Beats = s.Beats()
+			if opts.SFX {
+				pacing.SFX = s.SoundEffects()
+			}
+			assembler := newAssembler(opts.Encoding, pacing)
+			asmCtx, asmCancel := context.WithTimeout(ctx, timeouts.Assembly)
+			err = assembler.Assemble(asmCtx, audioFiles, tmpDir, opts.Output)
+			err = stageTimeout(ctx, asmCtx, "assembly", timeouts.Assembly, err)
+			asmCancel()
+			segmentSecs, segmentStarts = assembler.SegmentSeconds(), assembler.SegmentStarts()
+			if err != nil {
 				logf("ERROR: assembly failed: %v", err)
 				logf("  Segments preserved in: %s", tmpDir)
 				logf("  Script preserved in: %s", scriptPath)
@@ -584,21 +1358,24 @@ func Run(ctx context.Context, opts Options) error {
 			logf("Assembly complete (%s)", time.Since(stageStart).Round(time.Millisecond))
 
 			os.RemoveAll(tmpDir)
+			cp.finish()
 		}
 	} else {
 		// Mixed providers — per-segment with routing
-		tmpParent := filepath.Join(OutputBaseDir, "tempfiles")
-		os.MkdirAll(tmpParent, 0755)
-		tmpDir, err := os.MkdirTemp(tmpParent, "run-*")
+		tmpDir, reuse, err := segmentWorkDir(opts.ResumeTTS, resumePlan, s, voices, logf)
 		if err != nil {
 			return &PipelineError{Stage: "tts", Message: "failed to create temp directory", Err: err}
 		}
-		logf("  Temp directory: %s", tmpDir)
 
-		audioFiles, err := synthesizeSegmentsMixed(ctx, ps, s.Segments, voices, tmpDir, logf, opts.OnProgress, pipelineStart)
+		var cp *checkpoint
+		if opts.Checkpoint && opts.Preview == 0 {
+			cp = startCheckpoint(tmpDir, scriptPath, ingestHash, s, voices, reuse, opts, logf)
+		}
+		audioFiles, err := synthesizeSegments(ctx, ps, ttsCache, lexicon, ttsConcurrency, timeouts.TTSSegment, s.Segments, reuse, voices, tmpDir, cp, logf, opts.OnProgress, pipelineStart)
 		if err != nil {
 			logf("ERROR: TTS synthesis failed: %v", err)
 			logf("  Segments preserved in: %s", tmpDir)
+			err = partialFailure(tmpDir, scriptPath, s, voices, audioFiles, err, logf)
 			return &PipelineError{Stage: "tts", Message: "failed to synthesize audio", Err: err}
 		}
 
@@ -615,11 +1392,23 @@ func Run(ctx context.Context, opts Options) error {
 		}
 
 		// Stage 4: Assembly
+		prof.begin("assembly")
+		ctx = stages.begin(ctx, "assembly", "")
 		stageStart = time.Now()
 		emit(progress.StageAssembly, "Assembling episode...", 0.90)
 		logf("Stage 4/4: Assembling episode...")
-		assembler := assembly.NewFFmpegAssembler()
-		if err := assembler.Assemble(ctx, audioFiles, tmpDir, opts.Output); err != nil {
+		pacing := opts.Pacing
+		pacing.Beats = s.Beats()
+		if opts.SFX {
+			pacing.SFX = s.SoundEffects()
+		}
+		assembler := newAssembler(opts.Encoding, pacing)
+		asmCtx, asmCancel := context.WithTimeout(ctx, timeouts.Assembly)
+		err = assembler.Assemble(asmCtx, audioFiles, tmpDir, opts.Output)
+		err = stageTimeout(ctx, asmCtx, "assembly", timeouts.Assembly, err)
+		asmCancel()
+		segmentSecs, segmentStarts = assembler.SegmentSeconds(), assembler.SegmentStarts()
+		if err != nil {
 			logf("ERROR: assembly failed: %v", err)
 			logf("  Segments preserved in: %s", tmpDir)
 			logf("  Script preserved in: %s", scriptPath)
@@ -628,6 +1417,201 @@ func Run(ctx context.Context, opts Options) error {
 		logf("Assembly complete (%s)", time.Since(stageStart).Round(time.Millisecond))
 
 		os.RemoveAll(tmpDir)
+		cp.finish()
+	}
+
+	// Music, loudness, QC, checks and tags.
+	prof.begin("finishing")
+	ctx = stages.begin(ctx, "finishing", "")
+
+	// Chapter and transcript times are placed on the voice track, so
+	// measure it before music or stingers move it (see chapters.go and
+	// transcript.go).
+	var voiceSecs, voiceLead float64
+	if secs, err := assembly.ProbeSeconds(ctx, opts.Output); err != nil {
+		logf("WARNING: could not measure episode for chapters and transcript: %v", err)
+	} else {
+		voiceSecs = secs
+	}
+
+	if opts.Music != "" {
+		voiceLead += assembly.MusicLead
+		stageStart := time.Now()
+		emit(progress.StageAssembly, "Mixing music bed...", 0.95)
+		logf("Mixing music bed: %s", opts.Music)
+		err := rewriteOutput(ctx, opts.Output, "assembly", timeouts.Assembly, func(ctx context.Context, input string) error {
+			return assembly.MixMusic(ctx, input, assembly.Music{Source: opts.Music, Volume: opts.MusicVolume}, opts.Output, opts.Encoding)
+		})
+		if err != nil {
+			logf("ERROR: music mix failed: %v", err)
+			return &PipelineError{Stage: "assembly", Message: "failed to mix music bed", Err: err}
+		}
+		logf("Music mix complete (%s)", time.Since(stageStart).Round(time.Millisecond))
+	}
+
+	if stingers := (assembly.Stingers{Intro: opts.Intro, Outro: opts.Outro}); !stingers.IsZero() {
+		stageStart := time.Now()
+		emit(progress.StageAssembly, "Adding intro/outro...", 0.97)
+		logf("Adding stingers: intro=%q outro=%q", opts.Intro, opts.Outro)
+		if opts.Intro != "" && voiceSecs > 0 {
+			if lead, err := assembly.StingerLead(ctx, opts.Intro); err == nil {
+				voiceLead += lead
+			}
+		}
+		err := rewriteOutput(ctx, opts.Output, "assembly", timeouts.Assembly, func(ctx context.Context, input string) error {
+			return assembly.AddStingers(ctx, input, stingers, opts.Output, opts.Encoding)
+		})
+		if err != nil {
+			logf("ERROR: adding intro/outro failed: %v", err)
+			return &PipelineError{Stage: "assembly", Message: "failed to add intro/outro", Err: err}
+		}
+		logf("Stingers added (%s)", time.Since(stageStart).Round(time.Millisecond))
+	}
+
+	// Normalize last, so the music bed and stingers count toward loudness.
+	if !opts.NoLoudnorm && native {
+		logf("Loudness normalization skipped (needs FFmpeg)")
+	} else if !opts.NoLoudnorm {
+		stageStart := time.Now()
+		emit(progress.StageAssembly, "Normalizing loudness...", 0.98)
+		logf("Normalizing loudness to %.0f LUFS", assembly.LoudnessTarget(opts.Encoding.ChannelCount()))
+		if err := rewriteOutput(ctx, opts.Output, "assembly", timeouts.Assembly, func(ctx context.Context, input string) error {
+			return assembly.NormalizeLoudness(ctx, input, opts.Output, opts.Encoding)
+		}); err != nil {
+			if ctx.Err() != nil {
+				return &PipelineError{Stage: "assembly", Message: "loudness normalization interrupted", Err: err}
+			}
+			// The episode is intact, just not normalized.
+			logf("WARNING: loudness normalization failed, keeping the episode as assembled: %v", err)
+		} else {
+			logf("Loudness normalized (%s)", time.Since(stageStart).Round(time.Millisecond))
+		}
+	}
+
+	// Check the finished audio; tagging below doesn't change it.
+	var qcReport *assembly.QCReport
+	switch {
+	case opts.QC == QCOff:
+	case native:
+		logf("Quality check skipped (needs FFmpeg)")
+	default:
+		emit(progress.StageAssembly, "Checking quality...", 0.99)
+		qcCtx, qcCancel := context.WithTimeout(ctx, timeouts.Assembly)
+		report, err := assembly.MeasureQC(qcCtx, opts.Output, qcBounds)
+		err = stageTimeout(ctx, qcCtx, "assembly", timeouts.Assembly, err)
+		qcCancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return &PipelineError{Stage: "assembly", Message: "quality check interrupted", Err: err}
+			}
+			logf("WARNING: could not run the quality check: %v", err)
+			break
+		}
+		qcReport = &report
+		if err := WriteQC(QCPath(opts.Output), report); err != nil {
+			logf("WARNING: %v", err)
+		}
+		if report.Passed() {
+			logf("Quality check passed: %s (%s)", report, QCPath(opts.Output))
+			break
+		}
+		for _, p := range report.Problems {
+			logf("WARNING: quality check: %s", p)
+		}
+		if opts.QC == QCFail {
+			logf("ERROR: episode failed its quality check (--qc fail); kept for inspection at %s", opts.Output)
+			return &PipelineError{Stage: "assembly", Message: "episode failed quality check", Err: &QCError{Report: report}, Kind: errkind.UserInput}
+		}
+	}
+
+	// Waveform for web players, from the final audio.
+	if native {
+		logf("Waveform peaks skipped (needs FFmpeg)")
+	} else {
+		peaksCtx, peaksCancel := context.WithTimeout(ctx, timeouts.Assembly)
+		peaks, err := assembly.MeasurePeaks(peaksCtx, opts.Output, assembly.PeakBuckets)
+		peaksCancel()
+		if err == nil {
+			err = WritePeaks(PeaksPath(opts.Output), peaks)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return &PipelineError{Stage: "assembly", Message: "waveform peaks interrupted", Err: err}
+			}
+			logf("WARNING: could not write waveform peaks: %v", err)
+		} else {
+			logf("Waveform peaks: %d (%s)", len(peaks.Peaks), PeaksPath(opts.Output))
+		}
+	}
+
+	var speakerReport *diarize.Report
+	if opts.VerifySpeakers {
+		if scriptSpeakers(s) < 2 {
+			logf("Speaker check skipped (one speaker)")
+		} else {
+			emit(progress.StageAssembly, "Checking speakers...", 0.99)
+			verifyCtx, verifyCancel := context.WithTimeout(ctx, timeouts.Assembly)
+			report, err := verifySpeakers(verifyCtx, opts.Output, s, opts.DeepgramAPIKey, logf)
+			verifyCancel()
+			if err != nil {
+				if ctx.Err() != nil {
+					return &PipelineError{Stage: "assembly", Message: "speaker check interrupted", Err: err}
+				}
+				logf("WARNING: could not check speakers: %v", err)
+			} else {
+				speakerReport = &report
+			}
+		}
+	}
+
+	// Tag last: the steps above re-encode and would drop the cover art.
+	tags := episodeTags(s, opts)
+	if voiceSecs > 0 {
+		totalSecs, err := assembly.ProbeSeconds(ctx, opts.Output)
+		if err != nil {
+			totalSecs = voiceLead + voiceSecs
+		}
+		if HasChapters(s) {
+			tags.Chapters = scriptChapters(s, voiceSecs, voiceLead, totalSecs)
+			if err := WriteChapters(ChaptersPath(opts.Output), tags.Chapters); err != nil {
+				logf("WARNING: %v", err)
+			} else {
+				logf("Chapters: %d (%s)", len(tags.Chapters), ChaptersPath(opts.Output))
+			}
+		}
+		if cues := transcriptCues(s, segmentSecs, segmentStarts, voiceSecs, voiceLead); len(cues) > 0 {
+			if err := WriteTranscripts(opts.Output, cues); err != nil {
+				logf("WARNING: %v", err)
+			} else {
+				timing := "estimated"
+				if segmentSecs != nil {
+					timing = "measured"
+				}
+				logf("Transcript: %d cues, %s timing (%s, %s)", len(cues), timing, SRTPath(opts.Output), VTTPath(opts.Output))
+				if err := WritePage(opts.Output, s, cues, opts.PageAudio, opts.PageTranscript); err != nil {
+					logf("WARNING: %v", err)
+				} else {
+					logf("Listening page: %s", PagePath(opts.Output))
+				}
+			}
+		}
+	}
+	if native {
+		logf("Tags skipped (needs FFmpeg)")
+	} else if err := rewriteOutput(ctx, opts.Output, "assembly", timeouts.Assembly, func(ctx context.Context, input string) error {
+		return assembly.WriteTags(ctx, input, tags, opts.Output)
+	}); err != nil {
+		if ctx.Err() != nil {
+			return &PipelineError{Stage: "assembly", Message: "writing tags interrupted", Err: err}
+		}
+		logf("WARNING: writing tags failed, keeping the episode untagged: %v", err)
+	} else {
+		f := assembly.FormatOf(opts.Output)
+		chapters := len(tags.Chapters)
+		if !f.SupportsChapters() {
+			chapters = 0
+		}
+		logf("Tags written (title %q, show %q, cover %t, %d chapters)", tags.Title, tags.Album, tags.Cover != "" && f.SupportsCover(), chapters)
 	}
 
 	// Report final output
@@ -638,7 +1622,7 @@ func Run(ctx context.Context, opts Options) error {
 	info, err := os.Stat(opts.Output)
 	if err == nil {
 		sizeMB := float64(info.Size()) / (1024 * 1024)
-		duration := ProbeDuration(opts.Output)
+		duration := ProbeDuration(ctx, opts.Output)
 		absOutput, _ := filepath.Abs(opts.Output)
 		completionEvent.OutputFile = absOutput
 		completionEvent.SizeMB = sizeMB
@@ -658,6 +1642,24 @@ func Run(ctx context.Context, opts Options) error {
 		completionEvent.LogFile = absLog
 	}
 
+	stages.end(nil)
+	if opts.History {
+		entry := HistoryEntry{
+			ID:         EpisodeID(opts.Output),
+			CreatedAt:  time.Now().UTC(),
+			Title:      s.Title,
+			Output:     opts.Output,
+			Command:    opts.ReproCommand(),
+			QC:         qcReport,
+			Escalation: escalation,
+			Speakers:   speakerReport,
+			Stages:     stages.timings,
+		}
+		if err := AppendHistory(entry); err != nil {
+			logf("WARNING: failed to record history: %v", err)
+		}
+	}
+
 	logf("Total pipeline time: %s", time.Since(pipelineStart).Round(time.Millisecond))
 
 	if opts.OnProgress != nil {
@@ -667,195 +1669,112 @@ func Run(ctx context.Context, opts Options) error {
 	return nil
 }
 
-// synthesizeSegments runs per-segment TTS with progress output, converting
-// non-MP3 formats to MP3 as needed.
-func synthesizeSegments(ctx context.Context, provider tts.Provider, segments []script.Segment, voices tts.VoiceMap, tmpDir string, logf func(string, ...interface{}), onProgress progress.Callback, pipelineStart time.Time) ([]string, error) {
-	total := len(segments)
-	files := make([]string, 0, total)
-
-	for i, seg := range segments {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
-		}
-
-		// Throttle TTS requests to avoid rate limiting.
-		// Gemini AI Studio: 10 RPM limit → 1 req per 7s (with margin).
-		// Gemini Vertex AI: 30K RPM → 500ms (polite delay only).
-		// Others: 3s delay is sufficient.
-		if i > 0 {
-			delay := 3 * time.Second
-			switch provider.Name() {
-			case "gemini":
-				delay = 7 * time.Second // 10 RPM = 6s; use 7s for margin
-			case "gemini-vertex":
-				delay = 500 * time.Millisecond // 30K RPM; minimal polite delay
-			}
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
-			}
-		}
-
-		voice := tts.VoiceForSpeaker(seg.Speaker, voices)
-
-		logf("  Synthesizing segment %d/%d (%s, %d chars)", i+1, total, seg.Speaker, len(seg.Text))
-
-		if onProgress != nil {
-			pct := 0.20 + 0.70*float64(i)/float64(total)
-			onProgress(progress.Event{
-				Stage:        progress.StageTTS,
-				Message:      fmt.Sprintf("Synthesizing segment %d/%d (%s, %s)", i+1, total, seg.Speaker, voice.Provider),
-				Percent:      pct,
-				SegmentNum:   i + 1,
-				SegmentTotal: total,
-				Elapsed:      time.Since(pipelineStart),
-			})
-		}
-
-		var result tts.AudioResult
-		segStart := time.Now()
-		err := tts.WithRetry(ctx, func() error {
-			// Per-segment timeout: if a single TTS request hangs (e.g., due to
-			// network proxy dropping idle connections), fail fast and retry.
-			reqCtx, reqCancel := context.WithTimeout(ctx, 60*time.Second)
-			defer reqCancel()
-			var synthErr error
-			result, synthErr = provider.Synthesize(reqCtx, seg.Text, voice)
-			if synthErr != nil {
-				logf("  Segment %d/%d attempt failed (elapsed %s): %v", i+1, total, time.Since(segStart).Round(time.Millisecond), synthErr)
-			}
-			return synthErr
-		})
-		if err != nil {
-			logf("  Segment %d/%d FAILED after %s: %v", i+1, total, time.Since(segStart).Round(time.Millisecond), err)
-			return nil, fmt.Errorf("segment %d (%s): %w", i+1, seg.Speaker, err)
-		}
-		logf("  Segment %d/%d OK (%s, %d bytes, %s)", i+1, total, seg.Speaker, len(result.Data), time.Since(segStart).Round(time.Millisecond))
-
-		// If provider returns non-MP3, convert via FFmpeg
-		filename := filepath.Join(tmpDir, fmt.Sprintf("segment_%03d.mp3", i))
-		if result.Format != tts.FormatMP3 {
-			rawPath := filepath.Join(tmpDir, fmt.Sprintf("segment_%03d.raw", i))
-			if err := os.WriteFile(rawPath, result.Data, 0644); err != nil {
-				return nil, fmt.Errorf("write raw segment %d: %w", i+1, err)
-			}
-			if err := assembly.ConvertToMP3(ctx, rawPath, string(result.Format), filename); err != nil {
-				return nil, fmt.Errorf("convert segment %d: %w", i+1, err)
-			}
-		} else {
-			if err := os.WriteFile(filename, result.Data, 0644); err != nil {
-				return nil, fmt.Errorf("write segment %d: %w", i+1, err)
-			}
-		}
-
-		files = append(files, filename)
+// episodeTags returns the ID3 tags for the episode generated from s.
+func episodeTags(s *script.Script, opts Options) assembly.Tags {
+	show := opts.Show
+	if show == "" {
+		show = assembly.DefaultArtist
 	}
-
-	// Emit TTS complete
-	if onProgress != nil {
-		onProgress(progress.Event{
-			Stage:   progress.StageTTS,
-			Message: "TTS complete",
-			Percent: 0.90,
-			Elapsed: time.Since(pipelineStart),
-		})
+	return assembly.Tags{
+		Title:    s.Title,
+		Artist:   assembly.DefaultArtist,
+		Album:    show,
+		Comment:  s.Summary,
+		Date:     time.Now().Format("2006-01-02"),
+		Cover:    opts.Cover,
+		Explicit: &s.Explicit,
 	}
-
-	return files, nil
 }
 
-// synthesizeSegmentsMixed runs per-segment TTS with provider routing for
-// mixed-provider episodes. Each segment is routed to the provider specified
-// in the voice's Provider field via ProviderSet.
-func synthesizeSegmentsMixed(ctx context.Context, ps *tts.ProviderSet, segments []script.Segment, voices tts.VoiceMap, tmpDir string, logf func(string, ...interface{}), onProgress progress.Callback, pipelineStart time.Time) ([]string, error) {
-	total := len(segments)
-	files := make([]string, 0, total)
-
-	for i, seg := range segments {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
-		}
-
-		voice := tts.VoiceForSpeaker(seg.Speaker, voices)
-		provider, err := ps.Get(voice.Provider)
-		if err != nil {
-			return nil, fmt.Errorf("segment %d (%s): get provider %s: %w", i+1, seg.Speaker, voice.Provider, err)
-		}
+// rewriteOutput replaces the episode at output with what fn writes there
+// from the original, moved aside, within limit (reported as a timeout of
+// stage). If fn fails, the original is put back.
+func rewriteOutput(ctx context.Context, output, stage string, limit time.Duration, fn func(ctx context.Context, input string) error) error {
+	input := strings.TrimSuffix(output, filepath.Ext(output)) + ".pre" + filepath.Ext(output)
+	if err := os.Rename(output, input); err != nil {
+		return fmt.Errorf("move episode aside: %w", err)
+	}
+	defer os.Remove(input)
 
-		logf("  Synthesizing segment %d/%d (%s, %d chars, %s)", i+1, total, seg.Speaker, len(seg.Text), voice.Provider)
+	stepCtx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+	err := fn(stepCtx, input)
+	if err = stageTimeout(ctx, stepCtx, stage, limit, err); err != nil {
+		// Leave the episode without this step rather than without anything.
+		os.Rename(input, output)
+		return err
+	}
+	return nil
+}
 
-		if onProgress != nil {
-			pct := 0.20 + 0.70*float64(i)/float64(total)
-			onProgress(progress.Event{
-				Stage:        progress.StageTTS,
-				Message:      fmt.Sprintf("Synthesizing segment %d/%d (%s, %s)", i+1, total, seg.Speaker, voice.Provider),
-				Percent:      pct,
-				SegmentNum:   i + 1,
-				SegmentTotal: total,
-				Elapsed:      time.Since(pipelineStart),
-			})
-		}
+// segmentWorkDir returns the directory per-segment synthesis writes into and
+// the segments already there: resumeDir with whatever of plan still fits s
+// and voices when resuming, otherwise a fresh directory under tempfiles.
+func segmentWorkDir(resumeDir string, plan AudioPlan, s *script.Script, voices tts.VoiceMap, logf func(string, ...interface{})) (string, map[int]string, error) {
+	if resumeDir != "" {
+		reuse := plan.reusable(resumeDir, s, voices)
+		logf("  Resuming in %s: reusing %d/%d segments", resumeDir, len(reuse), len(s.Segments))
+		return resumeDir, reuse, nil
+	}
+	tmpParent := filepath.Join(OutputBaseDir, "tempfiles")
+	os.MkdirAll(tmpParent, 0755)
+	tmpDir, err := os.MkdirTemp(tmpParent, "run-*")
+	if err != nil {
+		return "", nil, err
+	}
+	logf("  Temp directory: %s", tmpDir)
+	return tmpDir, nil, nil
+}
 
-		var result tts.AudioResult
-		err = tts.WithRetry(ctx, func() error {
-			reqCtx, reqCancel := context.WithTimeout(ctx, 60*time.Second)
-			defer reqCancel()
-			var synthErr error
-			result, synthErr = provider.Synthesize(reqCtx, seg.Text, voice)
-			return synthErr
-		})
-		if err != nil {
-			return nil, fmt.Errorf("segment %d (%s): %w", i+1, seg.Speaker, err)
-		}
+// logTTSSummary logs the run's per-provider TTS table, if any requests were
+// made.
+func logTTSSummary(m *tts.Metrics, logf func(string, ...interface{})) {
+	var b strings.Builder
+	m.WriteSummary(&b)
+	if b.Len() == 0 {
+		return
+	}
+	logf("TTS requests:")
+	for _, line := range strings.Split(strings.TrimRight(b.String(), "\n"), "\n") {
+		logf("  %s", line)
+	}
+}
 
-		// If provider returns non-MP3, convert via FFmpeg
-		filename := filepath.Join(tmpDir, fmt.Sprintf("segment_%03d.mp3", i))
-		if result.Format != tts.FormatMP3 {
-			rawPath := filepath.Join(tmpDir, fmt.Sprintf("segment_%03d.raw", i))
-			if err := os.WriteFile(rawPath, result.Data, 0644); err != nil {
-				return nil, fmt.Errorf("write raw segment %d: %w", i+1, err)
-			}
-			if err := assembly.ConvertToMP3(ctx, rawPath, string(result.Format), filename); err != nil {
-				return nil, fmt.Errorf("convert segment %d: %w", i+1, err)
-			}
-		} else {
-			if err := os.WriteFile(filename, result.Data, 0644); err != nil {
-				return nil, fmt.Errorf("write segment %d: %w", i+1, err)
-			}
+// validateElevenLabsVoices checks the explicitly chosen ElevenLabs voices
+// that this run will use. A voice missing from the account is an error; if
+// the library can't be fetched at all the run continues with a warning and
+// synthesis reports any problem as before.
+func validateElevenLabsVoices(ctx context.Context, opts Options, logf func(string, ...interface{})) error {
+	n := opts.Voices
+	if n < 1 || n > 3 {
+		n = 2
+	}
+	var ids []string
+	for i, v := range []struct{ id, provider string }{
+		{opts.Voice1, opts.Voice1Provider},
+		{opts.Voice2, opts.Voice2Provider},
+		{opts.Voice3, opts.Voice3Provider},
+	} {
+		if i < n && v.id != "" && v.provider == "elevenlabs" {
+			ids = append(ids, v.id)
 		}
-
-		files = append(files, filename)
 	}
-
-	// Emit TTS complete
-	if onProgress != nil {
-		onProgress(progress.Event{
-			Stage:   progress.StageTTS,
-			Message: "TTS complete",
-			Percent: 0.90,
-			Elapsed: time.Since(pipelineStart),
-		})
+	err := tts.ValidateElevenLabsVoices(ctx, opts.ElevenLabsAPIKey, ids...)
+	var notFound *tts.VoiceNotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		logf("WARNING: could not verify ElevenLabs voices: %v", err)
+		return nil
 	}
-
-	return files, nil
+	return err
 }
 
-func ProbeDuration(path string) string {
-	out, err := exec.Command("ffprobe",
-		"-v", "quiet",
-		"-show_entries", "format=duration",
-		"-of", "default=noprint_wrappers=1:nokey=1",
-		path,
-	).Output()
+// ProbeDuration returns the audio file's duration as m:ss, or "" if it
+// can't be probed.
+func ProbeDuration(ctx context.Context, path string) string {
+	secs, err := assembly.ProbeSeconds(ctx, path)
 	if err != nil {
 		return ""
 	}
-	s := strings.TrimSpace(string(out))
-	var secs float64
-	if _, err := fmt.Sscanf(s, "%f", &secs); err != nil {
-		return ""
-	}
 	mins := int(secs) / 60
 	remainSecs := int(secs) % 60
 	return fmt.Sprintf("%d:%02d", mins, remainSecs)
@@ -904,12 +1823,37 @@ func slugify(title string) string {
 	return s
 }
 
-// AutoOutputName generates a filename from the script title + timestamp.
-func AutoOutputName(title string) string {
+// AutoOutputName generates a filename from the script title + timestamp,
+// with format's extension.
+func AutoOutputName(title string, format assembly.Format) string {
 	slug := slugify(title)
 	if slug == "" {
 		slug = "podcast"
 	}
 	ts := time.Now().Format("20060102-1504")
-	return slug + "-" + ts + ".mp3"
+	return slug + "-" + ts + format.Ext()
+}
+
+// ingestAll ingests each input concurrently. With several inputs, an
+// error names the input it came from.
+func ingestAll(ctx context.Context, inputs []string, chapters string) ([]*ingest.Content, error) {
+	contents := make([]*ingest.Content, len(inputs))
+	errs := make([]error, len(inputs))
+	var wg sync.WaitGroup
+	for i, in := range inputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ingester := ingest.NewIngester(in)
+			if epub, ok := ingester.(*ingest.EPUBIngester); ok {
+				epub.Chapters = chapters
+			}
+			contents[i], errs[i] = ingester.Ingest(ctx, in)
+			if errs[i] != nil && len(inputs) > 1 {
+				errs[i] = fmt.Errorf("%s: %w", in, errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return contents, errors.Join(errs...)
 }
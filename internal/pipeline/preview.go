@@ -0,0 +1,34 @@
+package pipeline
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// A preview (--preview N) writes and saves the whole script, then
+// synthesizes and assembles only its first N segments, so voices and tone
+// can be heard before paying for the full episode. The saved script
+// generates the rest with --from-script.
+
+// previewScript returns a copy of s cut to its first n segments. keepLast
+// also keeps the final segment (a trial run's disclaimer), which must be
+// heard in every episode.
+func previewScript(s *script.Script, n int, keepLast bool) *script.Script {
+	if n >= len(s.Segments) {
+		return s
+	}
+	preview := *s
+	preview.Segments = append([]script.Segment(nil), s.Segments[:n]...)
+	if keepLast {
+		preview.Segments = append(preview.Segments, s.Segments[len(s.Segments)-1])
+	}
+	return &preview
+}
+
+// previewName marks an auto-named episode file as a preview.
+func previewName(name string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-preview" + ext
+}
@@ -0,0 +1,251 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// Resource profiling (--profile). The run's CPU profile, a heap profile at
+// the end of each stage and every minute, and a summary of each stage's
+// wall time, CPU time and peak memory are written to Options.ProfileDir,
+// and the summary is logged. Figures come from the Go runtime
+// (runtime/metrics), so they cover the whole process: on the hosted server
+// they include any other job running at the time, and ffmpeg's own memory
+// is not counted.
+const (
+	profileSampleInterval = 50 * time.Millisecond
+
+	// profileHeapInterval spaces the heap snapshots taken between stage
+	// ends, so a long stage's growth shows up as it happens.
+	profileHeapInterval = time.Minute
+)
+
+// cpuProfiling is held by the run whose CPU profile is running; the Go
+// runtime allows one per process, and a second hosted job skips its own.
+var cpuProfiling sync.Mutex
+
+// ProfileDir returns a new directory for a CLI run's profiles.
+func ProfileDir() string {
+	return filepath.Join(OutputBaseDir, "profiles", time.Now().Format("20060102-150405"))
+}
+
+// StageUsage is the resources one pipeline stage used.
+type StageUsage struct {
+	Stage       string  `json:"stage"`
+	WallSeconds float64 `json:"wall_seconds"`
+	CPUSeconds  float64 `json:"cpu_seconds"`
+	// PeakBytes is the most memory the Go runtime held from the OS during
+	// the stage (sampled every profileSampleInterval); HeapBytes is the
+	// live heap after a GC at its end.
+	PeakBytes  uint64 `json:"peak_bytes"`
+	HeapBytes  uint64 `json:"heap_bytes"`
+	GCs        uint64 `json:"gcs"`
+	Goroutines int    `json:"goroutines"`
+}
+
+// profiler records StageUsage for each stage. A nil *profiler does nothing,
+// so Run calls it unconditionally.
+type profiler struct {
+	dir  string
+	cpu  *os.File // nil if the CPU profile couldn't start
+	stop chan struct{}
+	done chan struct{}
+
+	mu     sync.Mutex
+	peak   uint64 // since the current stage began
+	stages []StageUsage
+
+	stage     string
+	start     time.Time
+	startCPU  float64
+	startGCs  uint64
+	startedAt time.Time
+}
+
+// startProfile starts profiling into dir. The CPU profile is skipped, with a
+// warning, while another run in this process holds it (two hosted jobs
+// profiling at once); failing to start it is only a warning too.
+func startProfile(dir string, logf func(string, ...interface{})) (*profiler, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create profile directory: %w", err)
+	}
+	p := &profiler{dir: dir, stop: make(chan struct{}), done: make(chan struct{}), startedAt: time.Now()}
+	if !cpuProfiling.TryLock() {
+		logf("WARNING: no CPU profile: another run in this process is profiling")
+	} else if err := p.startCPUProfile(); err != nil {
+		cpuProfiling.Unlock()
+		logf("WARNING: no CPU profile: %v", err)
+	}
+	p.peak = readMetrics().total
+	go p.sample()
+	logf("Profiling to %s", dir)
+	return p, nil
+}
+
+// startCPUProfile starts the CPU profile into cpu.pprof. The caller holds
+// cpuProfiling.
+func (p *profiler) startCPUProfile() error {
+	f, err := os.Create(filepath.Join(p.dir, "cpu.pprof"))
+	if err != nil {
+		return err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	p.cpu = f
+	return nil
+}
+
+// sample tracks peak memory, and writes a heap snapshot every
+// profileHeapInterval, until stop is closed.
+func (p *profiler) sample() {
+	defer close(p.done)
+	t := time.NewTicker(profileSampleInterval)
+	defer t.Stop()
+	heap := time.NewTicker(profileHeapInterval)
+	defer heap.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-t.C:
+			total := readMetrics().total
+			p.mu.Lock()
+			p.peak = max(p.peak, total)
+			p.mu.Unlock()
+		case <-heap.C:
+			// As of the last GC; forcing one here would skew the stage's
+			// CPU and GC figures.
+			name := fmt.Sprintf("heap-%03dm.pprof", int(time.Since(p.startedAt).Minutes()))
+			if f, err := os.Create(filepath.Join(p.dir, name)); err == nil {
+				pprof.WriteHeapProfile(f)
+				f.Close()
+			}
+		}
+	}
+}
+
+// begin ends the current stage, if any, and starts the named one.
+func (p *profiler) begin(stage string) {
+	if p == nil {
+		return
+	}
+	if p.stage == "" {
+		runtime.GC() // refresh the CPU estimate, as end does
+	}
+	p.end()
+	m := readMetrics()
+	p.mu.Lock()
+	p.peak = m.total
+	p.mu.Unlock()
+	p.stage, p.start, p.startCPU, p.startGCs = stage, time.Now(), m.cpu, m.gcs
+}
+
+// end records the current stage and writes its heap profile.
+func (p *profiler) end() {
+	if p.stage == "" {
+		return
+	}
+	runtime.GC() // so the heap profile and HeapBytes are up to date
+	m := readMetrics()
+	p.mu.Lock()
+	peak := max(p.peak, m.total)
+	p.mu.Unlock()
+	p.stages = append(p.stages, StageUsage{
+		Stage:       p.stage,
+		WallSeconds: time.Since(p.start).Seconds(),
+		CPUSeconds:  m.cpu - p.startCPU,
+		PeakBytes:   peak,
+		HeapBytes:   m.heap,
+		GCs:         max(m.gcs-p.startGCs, 1) - 1, // not the GC above
+		Goroutines:  runtime.NumGoroutine(),
+	})
+	if f, err := os.Create(filepath.Join(p.dir, "heap-"+p.stage+".pprof")); err == nil {
+		pprof.WriteHeapProfile(f)
+		f.Close()
+	}
+	p.stage = ""
+}
+
+// finish ends the last stage, stops the CPU profile, writes summary.json
+// and logs the summary.
+func (p *profiler) finish(logf func(string, ...interface{})) {
+	if p == nil {
+		return
+	}
+	p.end()
+	close(p.stop)
+	<-p.done
+	if p.cpu != nil {
+		pprof.StopCPUProfile()
+		p.cpu.Close()
+		cpuProfiling.Unlock()
+	}
+
+	summary := struct {
+		WallSeconds float64      `json:"wall_seconds"`
+		PeakBytes   uint64       `json:"peak_bytes"`
+		Stages      []StageUsage `json:"stages"`
+	}{WallSeconds: time.Since(p.startedAt).Seconds(), Stages: p.stages}
+	for _, s := range p.stages {
+		summary.PeakBytes = max(summary.PeakBytes, s.PeakBytes)
+	}
+	if data, err := json.MarshalIndent(summary, "", "  "); err != nil {
+		logf("WARNING: marshal profile summary: %v", err)
+	} else if err := os.WriteFile(filepath.Join(p.dir, "summary.json"), data, 0644); err != nil {
+		logf("WARNING: write profile summary: %v", err)
+	}
+
+	logf("Resource profile (%s):", p.dir)
+	logf("  %-10s %9s %9s %10s %10s %5s", "stage", "wall", "cpu", "peak mem", "live heap", "gcs")
+	for _, s := range p.stages {
+		logf("  %-10s %8.1fs %8.1fs %10s %10s %5d", s.Stage, s.WallSeconds, s.CPUSeconds, formatBytes(s.PeakBytes), formatBytes(s.HeapBytes), s.GCs)
+	}
+	logf("  peak memory %s over %.1fs", formatBytes(summary.PeakBytes), summary.WallSeconds)
+}
+
+type runtimeMetrics struct {
+	total uint64  // memory mapped by the Go runtime
+	heap  uint64  // live heap objects
+	cpu   float64 // CPU seconds used by Go code and the runtime (estimated; refreshed at each GC)
+	gcs   uint64
+}
+
+func readMetrics() runtimeMetrics {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/objects:bytes"},
+		{Name: "/cpu/classes/total:cpu-seconds"},
+		{Name: "/cpu/classes/idle:cpu-seconds"},
+		{Name: "/gc/cycles/total:gc-cycles"},
+	}
+	metrics.Read(samples)
+	var m runtimeMetrics
+	if v := samples[0].Value; v.Kind() == metrics.KindUint64 {
+		m.total = v.Uint64()
+	}
+	if v := samples[1].Value; v.Kind() == metrics.KindUint64 {
+		m.heap = v.Uint64()
+	}
+	if total, idle := samples[2].Value, samples[3].Value; total.Kind() == metrics.KindFloat64 && idle.Kind() == metrics.KindFloat64 {
+		m.cpu = total.Float64() - idle.Float64()
+	}
+	if v := samples[4].Value; v.Kind() == metrics.KindUint64 {
+		m.gcs = v.Uint64()
+	}
+	return m
+}
+
+// formatBytes formats n in MiB.
+func formatBytes(n uint64) string {
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/assembly"
+)
+
+// Quality check modes (--qc): what an episode outside its QC bounds
+// (assembly.MeasureQC) does to the run.
+const (
+	QCWarn = "warn" // log the problems and keep going (the default)
+	QCFail = "fail" // fail the run; the episode is left for inspection
+	QCOff  = "off"  // don't measure
+)
+
+// ValidateQCMode checks a --qc value; "" is QCWarn.
+func ValidateQCMode(mode string) error {
+	switch mode {
+	case "", QCWarn, QCFail, QCOff:
+		return nil
+	}
+	return fmt.Errorf("qc must be %s, %s, or %s, got %q", QCWarn, QCFail, QCOff, mode)
+}
+
+// QCError is a run failed by its quality check (--qc fail).
+type QCError struct {
+	Report assembly.QCReport
+}
+
+func (e *QCError) Error() string {
+	return "quality check failed: " + strings.Join(e.Report.Problems, "; ")
+}
+
+// QCPath returns the quality report written next to an episode.
+func QCPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".qc.json"
+}
+
+// WriteQC writes r to path as JSON.
+func WriteQC(path string, r assembly.QCReport) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal quality report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write quality report: %w", err)
+	}
+	return nil
+}
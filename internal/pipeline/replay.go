@@ -0,0 +1,104 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// A failed hosted job leaves a debug bundle (the MCP server keeps it under
+// DebugPrefix) so `podcaster debug replay` can re-run the failing stage
+// locally: the manifest, the job's text input, the ingested source content,
+// and the script, each when the job got that far. Credentials are never in
+// it; a replay uses the local environment's.
+
+// Debug bundle file names.
+const (
+	DebugManifestFile = "job.json"
+	DebugInputFile    = "input.txt"   // the job's input_text, if it had one
+	DebugContentFile  = "content.txt" // the ingested source content
+	DebugScriptFile   = "script.json"
+)
+
+// DebugPrefix is the S3 prefix of a podcast's debug bundle.
+func DebugPrefix(podcastID string) string {
+	return "debug/" + podcastID + "/"
+}
+
+// DebugBundle is a debug bundle's manifest.
+type DebugBundle struct {
+	PodcastID string    `json:"podcast_id"`
+	FailedAt  time.Time `json:"failed_at"`
+	Stage     string    `json:"stage,omitempty"` // as in the stage report: ingest, script, tts, ...
+	Error     string    `json:"error"`
+	ErrorKind string    `json:"error_kind,omitempty"`
+	Command   string    `json:"command"`         // the job's CLI command (reproCommand)
+	Input     string    `json:"input,omitempty"` // the input URL, or DebugInputFile
+	Files     []string  `json:"files,omitempty"` // bundle files besides the manifest
+
+	Stages []StageTiming `json:"stages,omitempty"`
+}
+
+// Has reports whether the bundle includes file.
+func (b DebugBundle) Has(file string) bool {
+	return slices.Contains(b.Files, file)
+}
+
+// FailedStage returns the stage a run failed in: the last one its stage
+// report marks failed, or else the stage err names, or "".
+func FailedStage(err error, timings []StageTiming) string {
+	for i := len(timings) - 1; i >= 0; i-- {
+		if timings[i].Failed {
+			return timings[i].Stage
+		}
+	}
+	var pe *PipelineError
+	if errors.As(err, &pe) {
+		return pe.Stage
+	}
+	return ""
+}
+
+// ReplayArgs returns the generate flags that re-run the bundle's job, in
+// dir, from its failing stage: script generation from the ingested content,
+// or synthesis onward from the script. The job's input, chapter, script,
+// and output flags are replaced, and its intro, outro, and cover, which
+// aren't in the bundle, are dropped. Ingest failures are replayed without
+// generate (see cli's debug replay); for them ReplayArgs returns an error.
+func (b DebugBundle) ReplayArgs(dir string) ([]string, error) {
+	words, err := splitCommand(b.Command)
+	if err != nil {
+		return nil, fmt.Errorf("recorded command: %w", err)
+	}
+	if len(words) >= 2 && words[0] == "podcaster" && words[1] == "generate" {
+		words = words[2:]
+	}
+	var args []string
+	for i := 0; i < len(words); i++ {
+		switch words[i] {
+		case "-i", "--input", "--chapters", "--from-script", "-o", "--output", "--resume-tts":
+			i++ // and its value
+			continue
+		case "--intro", "--outro", "--cover":
+			// The command names these by their URLs' base names, and the
+			// bundle doesn't hold the files.
+			i++
+			continue
+		case "-S", "--script-only", "-v", "--verbose":
+			continue
+		}
+		args = append(args, words[i])
+	}
+
+	switch {
+	case b.Stage == "ingest" || !b.Has(DebugContentFile) && !b.Has(DebugScriptFile):
+		return nil, fmt.Errorf("job %s failed before script generation; replay its ingest instead", b.PodcastID)
+	case b.Stage == "script" || !b.Has(DebugScriptFile):
+		args = append(args, "--input", filepath.Join(dir, DebugContentFile), "--script-only")
+	default:
+		args = append(args, "--from-script", filepath.Join(dir, DebugScriptFile))
+	}
+	return append(args, "--output", "replay-"+b.PodcastID, "--verbose"), nil
+}
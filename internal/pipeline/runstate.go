@@ -0,0 +1,267 @@
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apresai/podcaster/internal/script"
+	"github.com/apresai/podcaster/internal/tts"
+)
+
+// Run checkpoints (podcaster resume). A per-segment run records its state
+// under RunsDir as each segment's audio lands in its temp directory, so a
+// run that dies without reporting a failure (killed, out of memory, a
+// closed laptop) can still be resumed from its first missing segment. The
+// temp directory's plan.json is kept current alongside it, since resuming is
+// --resume-tts on that directory.
+
+// RunsDir is where run-state files live, under OutputBaseDir.
+const RunsDir = "runs"
+
+// RunState is a per-segment run's checkpoint. It is removed when the run
+// succeeds.
+type RunState struct {
+	ID        string    `json:"id"` // the temp directory's name
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	InputHash string    `json:"inputHash,omitempty"` // of the ingested text; empty for --from-script runs
+	Script    string    `json:"script"`
+	Output    string    `json:"output"`
+	TempDir   string    `json:"tempDir"`
+	Total     int       `json:"total"`
+	Completed []int     `json:"completed"` // 0-based indexes with audio, sorted
+
+	// Command is the run's ReproCommand, whose flags the resumed run
+	// reuses so it synthesizes with the same voices.
+	Command string `json:"command"`
+}
+
+// Missing returns the indexes of the segments without audio.
+func (st RunState) Missing() []int {
+	var missing []int
+	for i := 0; i < st.Total; i++ {
+		if _, found := slices.BinarySearch(st.Completed, i); !found {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// RunStatePath returns the run-state file of run id.
+func RunStatePath(id string) string {
+	return filepath.Join(OutputBaseDir, RunsDir, id+".json")
+}
+
+// LoadRunState reads run id's state. The "run-" prefix may be left off.
+func LoadRunState(id string) (RunState, error) {
+	var st RunState
+	data, err := os.ReadFile(RunStatePath(id))
+	if os.IsNotExist(err) && !strings.HasPrefix(id, "run-") {
+		data, err = os.ReadFile(RunStatePath("run-" + id))
+	}
+	if os.IsNotExist(err) {
+		return st, fmt.Errorf("no interrupted run %q in %s", id, filepath.Join(OutputBaseDir, RunsDir))
+	}
+	if err != nil {
+		return st, fmt.Errorf("read run state: %w", err)
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, fmt.Errorf("parse run state: %w", err)
+	}
+	return st, nil
+}
+
+// ListRunStates returns the recorded runs, most recently updated first.
+// Unreadable files are skipped.
+func ListRunStates() ([]RunState, error) {
+	paths, err := filepath.Glob(filepath.Join(OutputBaseDir, RunsDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var states []RunState
+	for _, path := range paths {
+		if st, err := LoadRunState(strings.TrimSuffix(filepath.Base(path), ".json")); err == nil {
+			states = append(states, st)
+		}
+	}
+	slices.SortFunc(states, func(a, b RunState) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	return states, nil
+}
+
+// RemoveRunState deletes run id's state file.
+func RemoveRunState(id string) error {
+	if err := os.Remove(RunStatePath(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove run state: %w", err)
+	}
+	return nil
+}
+
+// writeRunState writes st, replacing the file whole so a run killed
+// mid-write leaves the previous checkpoint.
+func writeRunState(st RunState) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal run state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(OutputBaseDir, RunsDir), 0755); err != nil {
+		return fmt.Errorf("create runs directory: %w", err)
+	}
+	if err := writeFileAtomic(RunStatePath(st.ID), data); err != nil {
+		return fmt.Errorf("write run state: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames
+// it into place.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// inputHash identifies the ingested text a run's script was written from.
+func inputHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// checkpoint keeps a per-segment run's RunState and audio plan current as
+// segments finish. A nil *checkpoint does nothing.
+type checkpoint struct {
+	mu     sync.Mutex
+	state  RunState
+	s      *script.Script
+	voices tts.VoiceMap
+	files  []string
+	logf   func(string, ...interface{})
+	warned bool
+}
+
+// startCheckpoint records the run synthesizing s into dir, with the
+// segments in reuse already done. A resumed run keeps its original
+// state's ID, creation time, and input hash.
+func startCheckpoint(dir, scriptPath, hash string, s *script.Script, voices tts.VoiceMap, reuse map[int]string, opts Options, logf func(string, ...interface{})) *checkpoint {
+	id := filepath.Base(dir)
+	now := time.Now().UTC()
+	st := RunState{ID: id, CreatedAt: now, InputHash: hash}
+	if prev, err := LoadRunState(id); err == nil {
+		st.CreatedAt = prev.CreatedAt
+		if st.InputHash == "" {
+			st.InputHash = prev.InputHash
+		}
+	}
+	st.Script, st.Output, st.TempDir, st.Total = scriptPath, opts.Output, dir, len(s.Segments)
+	st.Command = opts.ReproCommand()
+
+	c := &checkpoint{state: st, s: s, voices: voices, files: make([]string, len(s.Segments)), logf: logf}
+	for i, path := range reuse {
+		c.files[i] = path
+	}
+	c.mu.Lock()
+	c.saveLocked()
+	c.mu.Unlock()
+	logf("  Run ID: %s (if interrupted: podcaster resume %s)", id, id)
+	return c
+}
+
+// done records segment i's audio at path.
+func (c *checkpoint) done(i int, path string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[i] = path
+	c.saveLocked()
+}
+
+// saveLocked writes the run state and the temp directory's plan. A failed
+// write is logged once; the run carries on without a current checkpoint.
+func (c *checkpoint) saveLocked() {
+	c.state.Completed = c.state.Completed[:0]
+	for i, f := range c.files {
+		if f != "" {
+			c.state.Completed = append(c.state.Completed, i)
+		}
+	}
+	c.state.UpdatedAt = time.Now().UTC()
+	err := writeRunState(c.state)
+	if err == nil {
+		err = WritePlan(c.state.TempDir, newAudioPlan(c.state.Script, c.s, c.voices, c.files))
+	}
+	if err != nil && !c.warned {
+		c.warned = true
+		c.logf("  WARNING: checkpoint: %v", err)
+	}
+}
+
+// finish removes the state of a run that completed.
+func (c *checkpoint) finish() {
+	if c == nil {
+		return
+	}
+	if err := RemoveRunState(c.state.ID); err != nil {
+		c.logf("WARNING: %v", err)
+	}
+}
+
+// ResumeArgs returns the generate flags that resume st: its recorded
+// command's flags without its input or script, then --resume-tts on its
+// temp directory and its output path.
+func (st RunState) ResumeArgs() ([]string, error) {
+	words, err := splitCommand(st.Command)
+	if err != nil {
+		return nil, fmt.Errorf("recorded command: %w", err)
+	}
+	if len(words) >= 2 && words[0] == "podcaster" && words[1] == "generate" {
+		words = words[2:]
+	}
+	var args []string
+	for i := 0; i < len(words); i++ {
+		switch words[i] {
+		case "-i", "--input", "--chapters", "--from-script", "-o", "--output", "--resume-tts":
+			i++ // and its value
+			continue
+		}
+		args = append(args, words[i])
+	}
+	args = append(args, "--resume-tts", st.TempDir)
+	if st.Output != "" {
+		args = append(args, "--output", st.Output)
+	}
+	return args, nil
+}
+
+// splitCommand splits a CLICommand string into words, unquoting the
+// Go-quoted (%q) ones.
+func splitCommand(cmd string) ([]string, error) {
+	var words []string
+	for cmd = strings.TrimSpace(cmd); cmd != ""; cmd = strings.TrimSpace(cmd) {
+		if cmd[0] == '"' {
+			q, err := strconv.QuotedPrefix(cmd)
+			if err != nil {
+				return nil, fmt.Errorf("unterminated quote in %q", cmd)
+			}
+			word, _ := strconv.Unquote(q)
+			words = append(words, word)
+			cmd = cmd[len(q):]
+			continue
+		}
+		word, rest, _ := strings.Cut(cmd, " ")
+		words = append(words, word)
+		cmd = rest
+	}
+	return words, nil
+}
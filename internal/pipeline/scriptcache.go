@@ -0,0 +1,35 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// scriptCacheVersion is part of every script cache key; bump it when prompt
+// or review changes should stop cached scripts from being reused.
+const scriptCacheVersion = "v3"
+
+// ScriptCache stores reviewed scripts by ScriptCacheKey so identical
+// requests (same source content and script options) skip generation.
+// Implementations treat errors as misses.
+type ScriptCache interface {
+	GetScript(ctx context.Context, key string) (*script.Script, bool)
+	PutScript(ctx context.Context, key string, s *script.Script)
+}
+
+// ScriptCacheKey hashes the ingested content with every option that shapes
+// the script. TTS settings are left out: they don't change the text.
+func ScriptCacheKey(content string, opts script.GenerateOptions) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%d\x00%s\x00%t\x00%t\x00%s\x00",
+		scriptCacheVersion, opts.Model, opts.Format, opts.Tone, opts.Duration, opts.Topic,
+		strings.Join(opts.Styles, ","), opts.Voices, strings.Join(opts.SpeakerNames, ","),
+		opts.ProsodyHints, opts.DeliveryHints, strings.Join(opts.SFX, ","))
+	h.Write([]byte(content))
+	return hex.EncodeToString(h.Sum(nil))
+}
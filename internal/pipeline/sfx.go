@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// sfxMarker matches an inline [SFX:name] marker.
+var sfxMarker = regexp.MustCompile(`(?i)\[SFX:\s*([a-z0-9_-]*)\s*\]`)
+
+// placeSFX moves [SFX:name] markers out of each segment's text into its
+// SFX field (the effect played before the segment), so markers are never
+// read aloud. Only a segment's first marker is kept; markers on the first
+// segment and names not in the library are dropped, as are such names set
+// directly in a script file. Returns the number of segments with an effect
+// and the names dropped.
+func placeSFX(s *script.Script) (int, []string) {
+	placed := 0
+	var dropped []string
+	for i := range s.Segments {
+		seg := &s.Segments[i]
+		for _, m := range sfxMarker.FindAllStringSubmatch(seg.Text, -1) {
+			if name := strings.ToLower(m[1]); seg.SFX == "" {
+				seg.SFX = name
+			} else {
+				dropped = append(dropped, name)
+			}
+		}
+		if sfxMarker.MatchString(seg.Text) {
+			seg.Text = strings.Join(strings.Fields(sfxMarker.ReplaceAllString(seg.Text, " ")), " ")
+		}
+		if seg.SFX == "" {
+			continue
+		}
+		if i == 0 || !assembly.IsSFX(seg.SFX) {
+			dropped = append(dropped, seg.SFX)
+			seg.SFX = ""
+			continue
+		}
+		placed++
+	}
+	return placed, dropped
+}
+
+// hasSFX reports whether any segment plays a sound effect.
+func hasSFX(s *script.Script) bool {
+	for _, seg := range s.Segments {
+		if seg.SFX != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/diarize"
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// SpeakersPath returns the speaker check report written next to an episode.
+func SpeakersPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".speakers.json"
+}
+
+// WriteSpeakers writes r to path as JSON.
+func WriteSpeakers(path string, r diarize.Report) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal speaker check: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write speaker check: %w", err)
+	}
+	return nil
+}
+
+// verifySpeakers transcribes the episode at output with diarization and
+// checks each segment was spoken by its own host's voice (--verify-speakers).
+// Mismatches are logged as warnings; the report is written to SpeakersPath.
+func verifySpeakers(ctx context.Context, output string, s *script.Script, apiKey string, logf func(string, ...interface{})) (diarize.Report, error) {
+	dg, err := diarize.NewDeepgram(apiKey)
+	if err != nil {
+		return diarize.Report{}, err
+	}
+	words, err := dg.Words(ctx, output)
+	if err != nil {
+		return diarize.Report{}, fmt.Errorf("transcribe episode: %w", err)
+	}
+	r := diarize.Check(s, words)
+	if err := WriteSpeakers(SpeakersPath(output), r); err != nil {
+		logf("WARNING: %v", err)
+	}
+	for _, note := range r.Notes {
+		logf("WARNING: speaker check: %s", note)
+	}
+	for _, m := range r.Mismatches {
+		logf("WARNING: speaker check: %s", m)
+	}
+	if r.Passed() {
+		logf("Speaker check passed: %d segments, %d voices heard (%s)", r.SegmentsChecked, r.SpeakersDetected, SpeakersPath(output))
+	} else {
+		logf("Speaker check: %d of %d segments in the wrong voice (%s)", len(r.Mismatches), r.SegmentsChecked, SpeakersPath(output))
+	}
+	return r, nil
+}
+
+// scriptSpeakers counts the distinct speakers in s.
+func scriptSpeakers(s *script.Script) int {
+	seen := map[string]bool{}
+	for _, seg := range s.Segments {
+		seen[seg.Speaker] = true
+	}
+	return len(seen)
+}
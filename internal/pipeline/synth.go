@@ -0,0 +1,581 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/progress"
+	"github.com/apresai/podcaster/internal/script"
+	"github.com/apresai/podcaster/internal/tts"
+)
+
+// DefaultTTSConcurrency is the per-segment worker count used when
+// Options.TTSConcurrency is zero. Per-provider caps still apply, so Gemini
+// AI Studio stays sequential.
+const DefaultTTSConcurrency = 4
+
+// providerMaxConcurrency caps in-flight requests per provider regardless of
+// --tts-concurrency. Gemini AI Studio's 10 RPM quota only tolerates one.
+var providerMaxConcurrency = map[string]int{
+	"gemini":         1,
+	"vertex-express": 2,
+}
+
+// providerDelay is the spacing between requests for a provider when running
+// one at a time. With more workers the spacing is divided among them so the
+// overall request rate scales with concurrency.
+//
+// Gemini AI Studio: 10 RPM limit → 1 req per 7s (with margin).
+// Gemini Vertex AI: 30K RPM → 500ms (polite delay only).
+// Others: 3s is sufficient.
+func providerDelay(name string) time.Duration {
+	switch name {
+	case "gemini":
+		return 7 * time.Second // 10 RPM = 6s; use 7s for margin
+	case "gemini-vertex":
+		return 500 * time.Millisecond // 30K RPM; minimal polite delay
+	default:
+		return 3 * time.Second
+	}
+}
+
+// providerGate bounds concurrency and spaces out request starts for one provider.
+type providerGate struct {
+	sem      chan struct{}
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newProviderGate(name string, concurrency int) *providerGate {
+	limit := concurrency
+	if max, ok := providerMaxConcurrency[name]; ok && max < limit {
+		limit = max
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	return &providerGate{
+		sem:      make(chan struct{}, limit),
+		interval: providerDelay(name) / time.Duration(limit),
+	}
+}
+
+// acquire takes a concurrency slot, then waits until this request's start slot.
+func (g *providerGate) acquire(ctx context.Context) error {
+	select {
+	case g.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	g.mu.Lock()
+	now := time.Now()
+	start := g.next
+	if start.Before(now) {
+		start = now
+	}
+	g.next = start.Add(g.interval)
+	g.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		select {
+		case <-ctx.Done():
+			<-g.sem
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return nil
+}
+
+func (g *providerGate) release() { <-g.sem }
+
+// segmentPool synthesizes segments with a bounded worker pool. Each provider
+// gets its own gate so a mixed episode can run ElevenLabs segments in
+// parallel while Gemini segments stay strictly sequential.
+type segmentPool struct {
+	ps            *tts.ProviderSet
+	cache         *tts.Cache
+	lex           *tts.Lexicon
+	concurrency   int
+	timeout       time.Duration  // per TTS request
+	reuse         map[int]string // segment audio kept from a failed run (--resume-tts)
+	tmpDir        string
+	logf          func(string, ...interface{})
+	onProgress    progress.Callback
+	pipelineStart time.Time
+	checkpoint    *checkpoint // nil unless the run is checkpointed (runstate.go)
+
+	gatesMu sync.Mutex
+	gates   map[string]*providerGate
+
+	progressMu sync.Mutex
+	done       int
+}
+
+func (p *segmentPool) gate(name string) *providerGate {
+	p.gatesMu.Lock()
+	defer p.gatesMu.Unlock()
+	g, ok := p.gates[name]
+	if !ok {
+		g = newProviderGate(name, p.concurrency)
+		p.gates[name] = g
+	}
+	return g
+}
+
+// emit serializes progress callbacks, which are not safe for concurrent use.
+func (p *segmentPool) emit(e progress.Event) {
+	if p.onProgress == nil {
+		return
+	}
+	p.progressMu.Lock()
+	defer p.progressMu.Unlock()
+	e.Elapsed = time.Since(p.pipelineStart)
+	p.onProgress(e)
+}
+
+// markDone records a finished segment and reports progress.
+func (p *segmentPool) markDone(total int) {
+	p.progressMu.Lock()
+	p.done++
+	done := p.done
+	p.progressMu.Unlock()
+
+	p.emit(progress.Event{
+		Stage:        progress.StageTTS,
+		Message:      fmt.Sprintf("Synthesized %d/%d segments", done, total),
+		Percent:      0.20 + 0.70*float64(done)/float64(total),
+		SegmentNum:   done,
+		SegmentTotal: total,
+	})
+}
+
+// segmentAudio is a synthesized segment waiting to be converted: the
+// provider's audio and the effects to apply to it.
+type segmentAudio struct {
+	i      int
+	result tts.AudioResult
+	fx     assembly.Effects
+}
+
+// run synthesizes all segments and returns their MP3 paths in script order.
+// TTS workers hand each segment's audio to a separate pool of converters,
+// one per CPU, so FFmpeg conversion overlaps the network-bound requests
+// instead of holding a TTS slot. The first failure cancels the remaining
+// synthesis; audio already synthesized is still converted, and the paths
+// of the segments that finished are returned with the error (empty for the
+// rest).
+func (p *segmentPool) run(ctx context.Context, segments []script.Segment, voices tts.VoiceMap) ([]string, error) {
+	total := len(segments)
+	files := make([]string, total)
+
+	workers := p.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > total {
+		workers = total
+	}
+	if workers > 1 {
+		p.logf("  TTS workers: %d", workers)
+	}
+	converters := max(min(runtime.NumCPU(), total), 1)
+
+	synthCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		errOnce  sync.Once
+		firstErr error
+		wg       sync.WaitGroup
+		convWG   sync.WaitGroup
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	jobs := make(chan int)
+	// A slot per worker lets each hand off one result without waiting on
+	// FFmpeg, while bounding the synthesized audio held in memory when
+	// conversion falls behind.
+	audio := make(chan segmentAudio, workers)
+
+	for c := 0; c < converters; c++ {
+		convWG.Add(1)
+		go func() {
+			defer convWG.Done()
+			for a := range audio {
+				// ctx, not synthCtx: a failed request elsewhere shouldn't
+				// throw away audio that was already synthesized.
+				filename, err := writeSegment(ctx, a.result, p.tmpDir, a.i, a.fx)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				files[a.i] = filename
+				p.checkpoint.done(a.i, filename)
+				p.markDone(total)
+			}
+		}()
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				a, err := p.synthesizeOne(synthCtx, i, total, segments[i], voices)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				audio <- a
+			}
+		}()
+	}
+
+feed:
+	for i := range segments {
+		if path, ok := p.reuse[i]; ok {
+			files[i] = path
+			p.markDone(total)
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-synthCtx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(audio)
+	convWG.Wait()
+
+	if firstErr != nil {
+		return files, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return files, err
+	}
+
+	p.emit(progress.Event{
+		Stage:   progress.StageTTS,
+		Message: "TTS complete",
+		Percent: 0.90,
+	})
+	return files, nil
+}
+
+func (p *segmentPool) synthesizeOne(ctx context.Context, i, total int, seg script.Segment, voices tts.VoiceMap) (segmentAudio, error) {
+	if ctx.Err() != nil {
+		return segmentAudio{}, ctx.Err()
+	}
+
+	voice := tts.VoiceForSpeaker(seg.Speaker, voices)
+	for {
+		voice = p.substitute(voice, voices)
+		a, err := p.synthesizeWith(ctx, i, total, seg, voice)
+		if err == nil {
+			return a, nil
+		}
+		quota := tts.IsQuotaExhausted(err)
+		if !quota && !p.ps.Tripped(voice.Provider) {
+			return segmentAudio{}, err
+		}
+
+		// Daily quota gone or the provider keeps failing: take it out of
+		// rotation and retry the segment on the next provider in the
+		// fallback chain, if any.
+		p.ps.MarkExhausted(voice.Provider)
+		next, ok := p.ps.Fallback()
+		if !ok {
+			return segmentAudio{}, err
+		}
+		reason := "circuit breaker tripped"
+		if quota {
+			reason = "quota exhausted"
+		}
+		p.logf("  WARNING: %s %s; switching remaining segments to %s", voice.Provider, reason, next)
+	}
+}
+
+// substitute swaps voice for its counterpart on the current fallback
+// provider when voice's own provider has run out of quota. The fallback uses
+// its default voice for the same host position.
+func (p *segmentPool) substitute(voice tts.Voice, voices tts.VoiceMap) tts.Voice {
+	if !p.ps.Exhausted(voice.Provider) {
+		return voice
+	}
+	name, ok := p.ps.Fallback()
+	if !ok {
+		return voice
+	}
+	fb, err := p.ps.Get(name)
+	if err != nil {
+		return voice
+	}
+	dv := fb.DefaultVoices()
+	sub := dv.Host1
+	switch voice {
+	case voices.Host2:
+		sub = dv.Host2
+	case voices.Host3:
+		sub = dv.Host3
+	}
+	sub.Provider = name
+	return sub
+}
+
+// synthesizeWith synthesizes segment i with voice, re-synthesizing audio
+// that looks truncated, and returns it for conversion.
+func (p *segmentPool) synthesizeWith(ctx context.Context, i, total int, seg script.Segment, voice tts.Voice) (segmentAudio, error) {
+	provider, err := p.ps.Get(voice.Provider)
+	if err != nil {
+		return segmentAudio{}, fmt.Errorf("segment %d (%s): get provider %s: %w", i+1, seg.Speaker, voice.Provider, err)
+	}
+	cfg := p.ps.Config(voice.Provider)
+
+	// Delivery-aware providers get the audio tags and direction; segments
+	// with SSML (or lexicon terms) go to providers that accept SSML;
+	// everyone else gets plain text with tags stripped and terms respelled.
+	text := p.lex.Respell(seg.Text)
+	_, useDelivery := provider.(tts.DeliveryProvider)
+	if !useDelivery {
+		text = tts.StripAudioTags(text)
+	}
+	// Gemini-family providers take style direction as a spoken-prompt
+	// prefix; it is part of the text, so the cache key follows it.
+	if cfg.StylePrompts && tts.GeminiFamily(provider.Name()) {
+		text = tts.StyleDirective(cfg.StyleTone, seg.Delivery) + text
+	}
+	_, useSSML := provider.(tts.SSMLProvider)
+	if useSSML {
+		switch {
+		case seg.SSML != "":
+			text = p.lex.ApplySSML(seg.SSML)
+		case p.lex.Matches(seg.Text):
+			text = p.lex.TextToSSML(tts.StripAudioTags(seg.Text))
+		default:
+			useSSML = false
+		}
+	}
+
+	// Speed and pitch the provider can't apply are applied to its audio with
+	// FFmpeg when the segment is converted; the cache holds the raw audio.
+	speed, pitch := tts.Emulated(provider.Name(), cfg.ForVoice(voice))
+	fx := assembly.Effects{Speed: speed, Pitch: pitch}
+
+	// A segment far shorter than its text predicts was cut off by the
+	// provider; it is re-synthesized rather than shipped (see truncation.go).
+	// The check measures the provider's audio, before any emulated speed.
+	rawSpeed := cfg.ForVoice(voice).Speed
+	if fx.Speed != 0 {
+		rawSpeed = 1
+	}
+	expected := expectedSeconds(seg.Text, rawSpeed)
+
+	var cacheKey string
+	if p.cache != nil {
+		keyText := text
+		if useDelivery && seg.Delivery != "" {
+			keyText = "[" + seg.Delivery + "]" + text
+		}
+		// The settings suffix tells an explicit stability of 0 from none.
+		cacheKey = p.cache.Key(provider.Name(), cfg.ForVoice(voice), voice.ID+voice.Settings.String(), keyText)
+		if cached, ok := p.cache.Get(cacheKey); ok {
+			p.logf("  Segment %d/%d cache hit (%s, %s, %d bytes)", i+1, total, seg.Speaker, provider.Name(), len(cached.Data))
+			short, secs := p.truncated(cached, expected)
+			if !short {
+				return segmentAudio{i, cached, fx}, nil
+			}
+			p.logf("  Segment %d/%d cached audio looks truncated (%.1fs, expected ~%.1fs); re-synthesizing", i+1, total, secs, expected)
+		}
+	}
+
+	for check := 0; ; check++ {
+		result, err := p.request(ctx, i, total, seg, provider, voice, text, useSSML, useDelivery)
+		if err != nil {
+			return segmentAudio{}, err
+		}
+
+		short, secs := p.truncated(result, expected)
+		if short && check < maxTruncationRetries {
+			p.logf("  Segment %d/%d looks truncated (%.1fs, expected ~%.1fs); re-synthesizing (%d/%d)", i+1, total, secs, expected, check+1, maxTruncationRetries)
+			continue
+		}
+		if short {
+			// Still short after the retries: the text may just be read
+			// fast. Keep it, but don't cache it.
+			p.logf("  WARNING: segment %d/%d still short after %d re-syntheses (%.1fs, expected ~%.1fs); keeping it", i+1, total, maxTruncationRetries, secs, expected)
+			return segmentAudio{i, result, fx}, nil
+		}
+
+		if p.cache != nil {
+			if err := p.cache.Put(cacheKey, result); err != nil {
+				p.logf("  WARNING: failed to cache segment %d: %v", i+1, err)
+			}
+		}
+		return segmentAudio{i, result, fx}, nil
+	}
+}
+
+// request makes one segment's TTS request, with retries, under the
+// provider's gate. text has already been prepared for the provider: SSML
+// when useSSML, tagged text plus seg.Delivery when useDelivery.
+func (p *segmentPool) request(ctx context.Context, i, total int, seg script.Segment, provider tts.Provider, voice tts.Voice, text string, useSSML, useDelivery bool) (tts.AudioResult, error) {
+	cfg := p.ps.Config(voice.Provider)
+	g := p.gate(provider.Name())
+	if err := g.acquire(ctx); err != nil {
+		return tts.AudioResult{}, err
+	}
+
+	p.logf("  Synthesizing segment %d/%d (%s, %d chars, %s)", i+1, total, seg.Speaker, len(seg.Text), provider.Name())
+	p.emit(progress.Event{
+		Stage:        progress.StageTTS,
+		Message:      fmt.Sprintf("Synthesizing segment %d/%d (%s, %s)", i+1, total, seg.Speaker, provider.Name()),
+		Percent:      0.20 + 0.70*float64(i)/float64(total),
+		SegmentNum:   i + 1,
+		SegmentTotal: total,
+	})
+
+	var result tts.AudioResult
+	attempts := 0
+	segStart := time.Now()
+	err := tts.WithRetryPolicy(ctx, cfg.Retry, func() error {
+		attempts++
+		// Per-segment timeout (Timeouts.TTSSegment): if a single TTS request
+		// hangs (e.g., due to network proxy dropping idle connections), fail
+		// fast and retry.
+		if err := p.ps.Allow(voice.Provider); err != nil {
+			return err
+		}
+		reqCtx, reqCancel := context.WithTimeout(ctx, p.timeout)
+		defer reqCancel()
+		var synthErr error
+		switch {
+		case useSSML:
+			result, synthErr = provider.(tts.SSMLProvider).SynthesizeSSML(reqCtx, text, voice)
+		case useDelivery:
+			result, synthErr = provider.(tts.DeliveryProvider).SynthesizeDelivery(reqCtx, text, seg.Delivery, voice)
+		default:
+			result, synthErr = provider.Synthesize(reqCtx, text, voice)
+		}
+		p.ps.Record(voice.Provider, synthErr)
+		if synthErr != nil {
+			p.logf("  Segment %d/%d attempt failed (elapsed %s): %v", i+1, total, time.Since(segStart).Round(time.Millisecond), synthErr)
+		}
+		return synthErr
+	})
+	g.release()
+	tts.MetricsFromContext(ctx).Record(ctx, tts.Call{
+		Provider: provider.Name(),
+		Op:       "segment",
+		Duration: time.Since(segStart),
+		Bytes:    int64(len(result.Data)),
+		Attempts: attempts,
+		Err:      err,
+	})
+	if err != nil {
+		p.logf("  Segment %d/%d FAILED after %s: %v", i+1, total, time.Since(segStart).Round(time.Millisecond), err)
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			err = &StageTimeoutError{Stage: "tts-segment", Limit: p.timeout, Err: err}
+		}
+		return tts.AudioResult{}, fmt.Errorf("segment %d (%s): %w", i+1, seg.Speaker, err)
+	}
+	p.logf("  Segment %d/%d OK (%s, %d bytes, %s)", i+1, total, seg.Speaker, len(result.Data), time.Since(segStart).Round(time.Millisecond))
+	return result, nil
+}
+
+// synthesizeSegments runs per-segment TTS, routing each segment to the
+// provider named in its voice's Provider field via ProviderSet (one provider
+// for single-provider episodes, several for mixed ones). Non-MP3 formats are
+// converted to MP3. Segments found in cache (may be nil) skip the API call
+// and the throttle delay. When a provider runs out of daily quota or trips its
+// circuit breaker, remaining segments move to the ProviderSet's fallback chain.
+// Segments in reuse (may be nil) already have audio and are skipped. Each
+// segment written is recorded in cp (may be nil). On failure the finished
+// segments' paths are returned with the error.
+func synthesizeSegments(ctx context.Context, ps *tts.ProviderSet, cache *tts.Cache, lex *tts.Lexicon, concurrency int, timeout time.Duration, segments []script.Segment, reuse map[int]string, voices tts.VoiceMap, tmpDir string, cp *checkpoint, logf func(string, ...interface{}), onProgress progress.Callback, pipelineStart time.Time) ([]string, error) {
+	pool := &segmentPool{
+		ps:            ps,
+		cache:         cache,
+		lex:           lex,
+		concurrency:   concurrency,
+		timeout:       timeout,
+		reuse:         reuse,
+		tmpDir:        tmpDir,
+		logf:          logf,
+		onProgress:    onProgress,
+		pipelineStart: pipelineStart,
+		checkpoint:    cp,
+		gates:         make(map[string]*providerGate),
+	}
+	return pool.run(ctx, segments, voices)
+}
+
+// prepareSSML converts inline prosody hints ([pause], *emphasis*) in each
+// segment to SSML and strips them from the plain text, so hints are never
+// read aloud by providers without SSML support. Segments that already carry
+// SSML are left alone. Returns the number of segments converted.
+func prepareSSML(s *script.Script) int {
+	n := 0
+	for i := range s.Segments {
+		seg := &s.Segments[i]
+		if seg.SSML != "" || !tts.HasHints(seg.Text) {
+			continue
+		}
+		// Audio tags stay in Text for delivery-aware providers but never
+		// reach SSML, where they'd be read aloud.
+		seg.SSML = tts.HintsToSSML(tts.StripAudioTags(seg.Text))
+		seg.Text = tts.StripHints(seg.Text)
+		n++
+	}
+	return n
+}
+
+// writeSegment writes segment i's audio into tmpDir as MP3, converting
+// non-MP3 formats via FFmpeg and applying fx (which re-encodes MP3 too).
+// Returns the MP3 path. Without FFmpeg, see writeSegmentNative.
+func writeSegment(ctx context.Context, result tts.AudioResult, tmpDir string, i int, fx assembly.Effects) (string, error) {
+	filename := filepath.Join(tmpDir, fmt.Sprintf("segment_%03d.mp3", i))
+	if result.Format != tts.FormatMP3 || !fx.IsZero() {
+		if !assembly.HasFFmpeg() {
+			return writeSegmentNative(result, tmpDir, i, fx)
+		}
+		rawPath := filepath.Join(tmpDir, fmt.Sprintf("segment_%03d.raw", i))
+		if err := os.WriteFile(rawPath, result.Data, 0644); err != nil {
+			return "", fmt.Errorf("write raw segment %d: %w", i+1, err)
+		}
+		if err := assembly.ConvertWithEffects(ctx, rawPath, string(result.Format), filename, fx, assembly.Encoding{}); err != nil {
+			return "", fmt.Errorf("convert segment %d: %w", i+1, err)
+		}
+		return filename, nil
+	}
+	if err := os.WriteFile(filename, result.Data, 0644); err != nil {
+		return "", fmt.Errorf("write segment %d: %w", i+1, err)
+	}
+	return filename, nil
+}
+
+// plainSegments prepares segments for a batch provider, which takes the whole
+// script as plain text: lexicon terms are respelled and audio tags stripped.
+func plainSegments(segments []script.Segment, lex *tts.Lexicon) []script.Segment {
+	out := append([]script.Segment(nil), segments...)
+	for i := range out {
+		out[i].Text = tts.StripAudioTags(lex.Respell(out[i].Text))
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
+)
+
+// Timeouts bounds each pipeline stage, so one stuck stage fails the run
+// instead of holding a hosted session until it is killed. Zero fields use
+// DefaultTimeouts.
+type Timeouts struct {
+	Transcribe time.Duration // transcribing audio and video inputs, all of them
+	Ingest     time.Duration // fetching and extracting the input
+	Script     time.Duration // script generation plus review
+	TTSSegment time.Duration // one per-segment TTS request (each retry gets its own)
+	TTSBatch   time.Duration // a whole batch synthesis, all chunks
+	Assembly   time.Duration // concatenation or MP3 conversion
+	Upload     time.Duration // MCP server: storing the episode and script
+}
+
+// DefaultTimeouts are generous enough for a "deep" episode on a slow
+// provider; a stage that runs past them is stuck, not slow.
+var DefaultTimeouts = Timeouts{
+	Transcribe: 30 * time.Minute,
+	Ingest:     2 * time.Minute,
+	Script:     10 * time.Minute,
+	TTSSegment: 60 * time.Second,
+	TTSBatch:   30 * time.Minute,
+	Assembly:   20 * time.Minute,
+	Upload:     5 * time.Minute,
+}
+
+// WithDefaults fills zero fields from DefaultTimeouts.
+func (t Timeouts) WithDefaults() Timeouts {
+	d := DefaultTimeouts
+	if t.Transcribe <= 0 {
+		t.Transcribe = d.Transcribe
+	}
+	if t.Ingest <= 0 {
+		t.Ingest = d.Ingest
+	}
+	if t.Script <= 0 {
+		t.Script = d.Script
+	}
+	if t.TTSSegment <= 0 {
+		t.TTSSegment = d.TTSSegment
+	}
+	if t.TTSBatch <= 0 {
+		t.TTSBatch = d.TTSBatch
+	}
+	if t.Assembly <= 0 {
+		t.Assembly = d.Assembly
+	}
+	if t.Upload <= 0 {
+		t.Upload = d.Upload
+	}
+	return t
+}
+
+// timeoutStages are the stage names ParseTimeouts accepts, in pipeline order.
+var timeoutStages = []string{"transcribe", "ingest", "script", "tts-segment", "tts-batch", "assembly", "upload"}
+
+// fields maps timeoutStages to t's fields.
+func (t *Timeouts) fields() map[string]*time.Duration {
+	return map[string]*time.Duration{
+		"transcribe":  &t.Transcribe,
+		"ingest":      &t.Ingest,
+		"script":      &t.Script,
+		"tts-segment": &t.TTSSegment,
+		"tts-batch":   &t.TTSBatch,
+		"assembly":    &t.Assembly,
+		"upload":      &t.Upload,
+	}
+}
+
+// ParseTimeouts parses a comma-separated list of stage=duration pairs
+// ("script=15m,tts-batch=45m"), as taken by --stage-timeouts and
+// PODCASTER_STAGE_TIMEOUTS. Stages not listed are left zero.
+func ParseTimeouts(spec string) (Timeouts, error) {
+	var t Timeouts
+	fields := t.fields()
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Timeouts{}, fmt.Errorf("stage timeout %q: want stage=duration", part)
+		}
+		field, ok := fields[strings.TrimSpace(name)]
+		if !ok {
+			return Timeouts{}, fmt.Errorf("unknown stage %q: must be one of %s", name, strings.Join(timeoutStages, ", "))
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return Timeouts{}, fmt.Errorf("stage timeout %q: invalid duration %q", name, value)
+		}
+		*field = d
+	}
+	return t, nil
+}
+
+// String formats the non-zero fields as ParseTimeouts input, in stage order.
+func (t Timeouts) String() string {
+	fields := t.fields()
+	var parts []string
+	for _, name := range timeoutStages {
+		if d := *fields[name]; d > 0 {
+			parts = append(parts, name+"="+d.String())
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// StageTimeoutError reports a stage that ran past its timeout.
+type StageTimeoutError struct {
+	Stage string // as in --stage-timeouts: "ingest", "script", ...
+	Limit time.Duration
+	Err   error
+}
+
+func (e *StageTimeoutError) Error() string {
+	return fmt.Sprintf("%s stage timed out after %s: %v", e.Stage, e.Limit, e.Err)
+}
+
+func (e *StageTimeoutError) Unwrap() error { return e.Err }
+
+// ErrorKind implements errkind.Classified. A source too slow to fetch is
+// the input's problem; a stuck transcription, script or TTS request is the
+// provider's; stuck assembly or upload is ours.
+func (e *StageTimeoutError) ErrorKind() errkind.Kind {
+	switch e.Stage {
+	case "ingest":
+		return errkind.UserInput
+	case "transcribe", "script", "tts-segment", "tts-batch":
+		return errkind.ProviderUnavailable
+	}
+	return errkind.Internal
+}
+
+// stageTimeout returns err as a *StageTimeoutError if stageCtx (derived from
+// ctx with the stage's limit) hit its deadline while ctx itself is still
+// live; a cancelled run is not a timeout. Other errors are returned as is.
+func stageTimeout(ctx, stageCtx context.Context, stage string, limit time.Duration, err error) error {
+	if err == nil || ctx.Err() != nil || !errors.Is(stageCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return &StageTimeoutError{Stage: stage, Limit: limit, Err: err}
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("podcaster-pipeline")
+
+// stageSpans gives each stage of a run a span (pipeline.ingest,
+// pipeline.script, ...) under the caller's, the MCP server's pipeline.run.
+// Stages begin where the profiler's do, and provider calls made with a
+// stage's context (HTTP through observability.Transport, AWS through
+// otelaws) nest under it, so a trace shows where an episode's time went.
+// Without a tracer provider (the CLI) the spans are no-ops.
+//
+// It also times each stage against its budget (budgets.go): an overrun is
+// logged as a warning when the stage ends, and timings holds the run's
+// stage report.
+type stageSpans struct {
+	parent  trace.Span
+	budgets Budgets
+	logf    func(string, ...interface{}) // nil until run's logger is set up
+
+	cur     trace.Span // nil before the first stage
+	curCtx  context.Context
+	timing  StageTiming
+	started time.Time
+
+	timings []StageTiming
+}
+
+func newStageSpans(ctx context.Context, budgets Budgets) *stageSpans {
+	return &stageSpans{parent: trace.SpanFromContext(ctx), budgets: budgets}
+}
+
+// begin ends the current stage, if any, and returns ctx carrying the named
+// stage's span instead. qualifier is the script model or TTS provider the
+// stage's budget may be narrowed to, or "".
+func (t *stageSpans) begin(ctx context.Context, stage, qualifier string, attrs ...attribute.KeyValue) context.Context {
+	t.end(nil)
+	ctx, t.cur = tracer.Start(trace.ContextWithSpan(ctx, t.parent), "pipeline."+stage,
+		trace.WithAttributes(attrs...))
+	t.curCtx = ctx
+	t.timing = StageTiming{Stage: stage, Qualifier: qualifier, Budget: t.budgets.For(stage, qualifier).Seconds()}
+	t.started = time.Now()
+	return ctx
+}
+
+// end ends the current stage, recording err as its failure.
+func (t *stageSpans) end(err error) {
+	if t.cur == nil {
+		return
+	}
+	timing := t.timing
+	timing.Seconds = time.Since(t.started).Seconds()
+	timing.OverBudget = timing.Budget > 0 && timing.Seconds > timing.Budget
+	timing.Failed = err != nil
+	if timing.OverBudget {
+		t.cur.SetAttributes(attribute.Bool("over_budget", true))
+		if t.logf != nil {
+			name := timing.Stage
+			if timing.Qualifier != "" {
+				name += " (" + timing.Qualifier + ")"
+			}
+			t.logf("WARNING: %s stage took %s, over its %s budget", name,
+				time.Since(t.started).Round(time.Second), t.budgets.For(timing.Stage, timing.Qualifier))
+		}
+	}
+	recordStage(t.curCtx, timing)
+	t.timings = append(t.timings, timing)
+
+	if err != nil {
+		t.cur.RecordError(err)
+		t.cur.SetStatus(codes.Error, err.Error())
+	}
+	t.cur.End()
+	t.cur = nil
+}
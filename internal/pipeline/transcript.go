@@ -0,0 +1,182 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/script"
+	"github.com/apresai/podcaster/internal/tts"
+)
+
+// Transcripts are timed from the segments' synthesized durations, measured
+// during assembly: segment i starts where the assembler placed it, after
+// the segments, gaps, beats, and crossfades before it, shifted by whatever
+// the music bed and intro put in front of the voices. Batch synthesis
+// produces one file, so there the voice track's duration is spread over
+// the segments by text length, as for chapters. Long segments are split
+// into several cues at sentence ends.
+
+// maxCueChars is the longest caption cue, about two lines on screen.
+const maxCueChars = 84
+
+// Cue is one caption: part of a segment's text and when it plays.
+type Cue struct {
+	Start, End float64 // seconds into the episode
+	Speaker    string
+	Text       string
+	Turn       bool // first cue of the segment
+}
+
+// SRTPath returns the SRT transcript written next to an episode.
+func SRTPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".srt"
+}
+
+// VTTPath returns the WebVTT transcript written next to an episode.
+func VTTPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".vtt"
+}
+
+// transcriptCues returns the captions for s. segmentSecs and starts are
+// the measured segment durations and start times (the assembler's
+// SegmentSeconds and SegmentStarts), or nil to estimate them from
+// voiceSecs, the voice track's length. The voices start lead seconds into
+// the episode.
+func transcriptCues(s *script.Script, segmentSecs, starts []float64, voiceSecs, lead float64) []Cue {
+	if len(segmentSecs) != len(s.Segments) || len(starts) != len(s.Segments) {
+		// Estimates spread the pauses over the segments, which then run
+		// back to back.
+		segmentSecs = estimateSegmentSeconds(s, voiceSecs)
+		if segmentSecs == nil {
+			return nil
+		}
+		starts = make([]float64, len(segmentSecs))
+		for i := 1; i < len(starts); i++ {
+			starts[i] = starts[i-1] + segmentSecs[i-1]
+		}
+	}
+
+	var cues []Cue
+	for i, seg := range s.Segments {
+		chunks := splitCaption(captionText(seg.Text))
+		var chars int
+		for _, chunk := range chunks {
+			chars += len(chunk)
+		}
+		secs := segmentSecs[i]
+		at := lead + starts[i]
+		for j, chunk := range chunks {
+			// Within a segment, time is shared out by text length.
+			d := secs * float64(len(chunk)) / float64(chars)
+			cues = append(cues, Cue{Start: at, End: at + d, Speaker: seg.Speaker, Text: chunk, Turn: j == 0})
+			at += d
+		}
+	}
+	return cues
+}
+
+// estimateSegmentSeconds spreads voiceSecs over s's segments by text
+// length. Returns nil if there is nothing to spread.
+func estimateSegmentSeconds(s *script.Script, voiceSecs float64) []float64 {
+	var total int
+	for _, seg := range s.Segments {
+		total += len(seg.Text)
+	}
+	if total == 0 || voiceSecs <= 0 {
+		return nil
+	}
+	secs := make([]float64, len(s.Segments))
+	for i, seg := range s.Segments {
+		secs[i] = float64(len(seg.Text)) / float64(total) * voiceSecs
+	}
+	return secs
+}
+
+// captionText returns a segment's text as it was spoken: audio tags and
+// prosody hints removed.
+func captionText(text string) string {
+	return tts.StripHints(tts.StripAudioTags(text))
+}
+
+// splitCaption breaks text into cues of at most maxCueChars, ending a cue
+// at a sentence end once it is half full. A single longer word gets a cue
+// to itself.
+func splitCaption(text string) []string {
+	var chunks []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, word := range strings.Fields(text) {
+		if cur.Len() > 0 && cur.Len()+1+len(word) > maxCueChars {
+			flush()
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte(' ')
+		}
+		cur.WriteString(word)
+		if cur.Len() >= maxCueChars/2 && strings.ContainsAny(word[len(word)-1:], ".!?") {
+			flush()
+		}
+	}
+	flush()
+	return chunks
+}
+
+// WriteTranscripts writes cues next to output as SRT and WebVTT.
+func WriteTranscripts(output string, cues []Cue) error {
+	if err := os.WriteFile(SRTPath(output), []byte(formatSRT(cues)), 0644); err != nil {
+		return fmt.Errorf("write srt transcript: %w", err)
+	}
+	if err := os.WriteFile(VTTPath(output), []byte(formatVTT(cues)), 0644); err != nil {
+		return fmt.Errorf("write vtt transcript: %w", err)
+	}
+	return nil
+}
+
+// formatSRT renders cues as SubRip. The speaker is named at the start of
+// each turn.
+func formatSRT(cues []Cue) string {
+	var b strings.Builder
+	for i, c := range cues {
+		text := c.Text
+		if c.Turn && c.Speaker != "" {
+			text = c.Speaker + ": " + text
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, cueTime(c.Start, ","), cueTime(c.End, ","), text)
+	}
+	return b.String()
+}
+
+// vttEscaper escapes the characters WebVTT cue text reserves.
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// formatVTT renders cues as WebVTT, with the speaker in a voice span on
+// every cue.
+func formatVTT(cues []Cue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, c := range cues {
+		text := vttEscaper.Replace(c.Text)
+		if c.Speaker != "" {
+			text = "<v " + vttEscaper.Replace(c.Speaker) + ">" + text
+		}
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", cueTime(c.Start, "."), cueTime(c.End, "."), text)
+	}
+	return b.String()
+}
+
+// cueTime formats secs as HH:MM:SS followed by sep and milliseconds (","
+// for SRT, "." for WebVTT).
+func cueTime(secs float64, sep string) string {
+	ms := int64(secs*1000 + 0.5)
+	if ms < 0 {
+		ms = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
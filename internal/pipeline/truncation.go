@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"strings"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/tts"
+)
+
+// Truncation check. Providers occasionally return a 200 with audio that
+// stops partway through the text (Gemini in particular), which would ship
+// as an episode with missing sentences. Each synthesized segment's duration
+// is compared against what its word count predicts.
+const (
+	// speechWordsPerSecond is a conversational speaking rate (~150 wpm),
+	// the basis for a segment's expected duration.
+	speechWordsPerSecond = 2.5
+
+	// minTruncationRatio is the fraction of the expected duration below
+	// which a segment counts as truncated. Fast speakers run well above
+	// 150 wpm, so only a segment far too short for its text is flagged.
+	minTruncationRatio = 0.4
+
+	// minCheckedSeconds skips the check for segments expected to be
+	// shorter than this ("Right.", "Wow!"), where word count says little.
+	minCheckedSeconds = 3.0
+
+	// maxTruncationRetries is how many times a truncated segment is
+	// re-synthesized before the last attempt is kept with a warning.
+	maxTruncationRetries = 2
+)
+
+// expectedSeconds estimates how long text takes to say at speed (0 = 1.0).
+// Audio tags and prosody hints aren't spoken, so they don't count.
+func expectedSeconds(text string, speed float64) float64 {
+	words := len(strings.Fields(tts.StripHints(tts.StripAudioTags(text))))
+	if speed <= 0 {
+		speed = 1
+	}
+	return float64(words) / speechWordsPerSecond / speed
+}
+
+// truncated measures a segment's audio as the provider returned it and
+// reports whether it is implausibly short for expected seconds of speech,
+// along with the measured duration. Measuring before conversion lets the
+// check run in the TTS worker while conversion happens elsewhere. Audio
+// that can't be measured is given the benefit of the doubt.
+func (p *segmentPool) truncated(result tts.AudioResult, expected float64) (bool, float64) {
+	if expected < minCheckedSeconds {
+		return false, 0
+	}
+	secs, err := rawSeconds(result)
+	if err != nil {
+		p.logf("  WARNING: could not check segment duration: %v", err)
+		return false, 0
+	}
+	return secs < expected*minTruncationRatio, secs
+}
+
+// rawSeconds returns the duration of provider audio: raw PCM is 16-bit
+// mono at assembly.RawPCMRate, and MP3 or WAV is read by its frames.
+func rawSeconds(result tts.AudioResult) (float64, error) {
+	if result.Format == tts.FormatPCM {
+		return float64(len(result.Data)) / (assembly.RawPCMRate * 2), nil
+	}
+	return assembly.DataSeconds(result.Data)
+}
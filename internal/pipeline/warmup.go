@@ -0,0 +1,101 @@
+package pipeline
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/apresai/podcaster/internal/tts"
+)
+
+// ttsWarmupTimeout bounds the TTS warm-up; whatever it hasn't finished is
+// left to the TTS stage.
+const ttsWarmupTimeout = 30 * time.Second
+
+// ttsWarmup is TTS setup done in the background while ingest and script
+// generation run, so the TTS stage doesn't pay provider setup latency
+// after the script completes: each provider is created (ps.Get), its shared
+// clients and tokens are initialized (tts.Warm), and its credentials are
+// checked with tts.CheckHealth, which synthesizes nothing.
+type ttsWarmup struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// Set before done is closed.
+	elapsed time.Duration
+	results []tts.Health
+}
+
+// startTTSWarmup starts warming the named providers. Stop it when Run
+// returns.
+func startTTSWarmup(ctx context.Context, ps *tts.ProviderSet, providers []string) *ttsWarmup {
+	ctx, cancel := context.WithTimeout(ctx, ttsWarmupTimeout)
+	w := &ttsWarmup{cancel: cancel, done: make(chan struct{}), results: make([]tts.Health, len(providers))}
+	go func() {
+		defer close(w.done)
+		start := time.Now()
+		var wg sync.WaitGroup
+		for i, name := range providers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := ps.Get(name); err != nil {
+					w.results[i] = tts.Health{Provider: name, Configured: true, Err: err}
+					return
+				}
+				tts.Warm(ctx, name) // a failure shows up in the check below
+				w.results[i] = tts.CheckHealth(ctx, name, ps.Config(name))
+			}()
+		}
+		wg.Wait()
+		w.elapsed = time.Since(start)
+	}()
+	return w
+}
+
+// stop cancels the warm-up if it is still running and waits for it.
+func (w *ttsWarmup) stop() {
+	w.cancel()
+	<-w.done
+}
+
+// report logs the warm-up's outcome when the TTS stage starts, without
+// waiting for it: a check still running is left to finish alongside
+// synthesis. Failed credential checks are warnings, since synthesis may
+// still succeed (or fall back) and reports its own errors.
+func (w *ttsWarmup) report(logf func(string, ...interface{})) {
+	select {
+	case <-w.done:
+	default:
+		logf("TTS warm-up still running; starting synthesis anyway")
+		return
+	}
+	ready := 0
+	for _, h := range w.results {
+		switch {
+		case h.Err != nil:
+			logf("WARNING: %s credential check failed during warm-up: %v", h.Provider, h.Err)
+		case !h.Configured:
+			logf("WARNING: %s is not configured: %s", h.Provider, h.Detail)
+		default:
+			ready++
+		}
+	}
+	logf("TTS warm-up: %d of %d providers ready (%s, during script generation)", ready, len(w.results), w.elapsed.Round(time.Millisecond))
+}
+
+// ttsProviders returns the distinct providers of the first n voices.
+func ttsProviders(voices tts.VoiceMap, n int) []string {
+	hosts := []tts.Voice{voices.Host1, voices.Host2, voices.Host3}
+	if n < 1 || n > 3 {
+		n = 2
+	}
+	var names []string
+	for _, v := range hosts[:n] {
+		if v.Provider != "" && !slices.Contains(names, v.Provider) {
+			names = append(names, v.Provider)
+		}
+	}
+	return names
+}
@@ -6,11 +6,12 @@ import "time"
 type Stage string
 
 const (
-	StageIngest   Stage = "ingest"
-	StageScript   Stage = "script"
-	StageTTS      Stage = "tts"
-	StageAssembly Stage = "assembly"
-	StageComplete Stage = "complete"
+	StageTranscribe Stage = "transcribe" // audio and video inputs, before ingest
+	StageIngest     Stage = "ingest"
+	StageScript     Stage = "script"
+	StageTTS        Stage = "tts"
+	StageAssembly   Stage = "assembly"
+	StageComplete   Stage = "complete"
 )
 
 // Event carries progress information from the pipeline to the renderer.
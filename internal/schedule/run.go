@@ -0,0 +1,134 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/assembly"
+	"github.com/apresai/podcaster/internal/ingest"
+	"github.com/apresai/podcaster/internal/observability/logctx"
+)
+
+// ErrNothingNew means a feed show had no items since its last episode, so
+// there was nothing to generate.
+var ErrNothingNew = errors.New("no new feed items")
+
+// Runner generates (and publishes) one episode of a show by running the
+// podcaster binary, so a scheduled run behaves exactly like the same
+// generate command typed by hand and lands in the generation history.
+type Runner struct {
+	Exe       string    // podcaster binary
+	OutputDir string    // pipeline.OutputBaseDir; feed digests go in OutputDir/<show>/
+	Log       io.Writer // child process output
+}
+
+// Run generates an episode of s and returns its path. It does not update
+// the show; the caller records the outcome.
+func (r Runner) Run(ctx context.Context, s Show) (string, error) {
+	now := time.Now()
+	dir := filepath.Join(r.OutputDir, s.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create show directory: %w", err)
+	}
+	stamp := now.Format("20060102-1504")
+	// generate writes every episode to episodes/ under the output dir,
+	// whatever directory --output names.
+	output := filepath.Join(r.OutputDir, "episodes", s.Name+"-"+stamp+OutputFormat(s.Args).Ext())
+
+	input := s.Source
+	if s.Feed {
+		digest, err := r.feedDigest(ctx, s, now)
+		if err != nil {
+			return "", err
+		}
+		input = filepath.Join(dir, s.Name+"-"+stamp+".txt")
+		if err := os.WriteFile(input, []byte(digest), 0644); err != nil {
+			return "", fmt.Errorf("write feed digest: %w", err)
+		}
+	}
+
+	args := append([]string{"generate", "--input", input, "--output", output}, s.Args...)
+	if err := r.Exec(ctx, args...); err != nil {
+		return "", fmt.Errorf("generate: %w", err)
+	}
+	if s.Publish {
+		pub := []string{"publish", output, "--source-url", s.Source}
+		if s.Owner != "" {
+			pub = append(pub, "--owner", s.Owner)
+		}
+		if err := r.Exec(ctx, pub...); err != nil {
+			return output, fmt.Errorf("publish: %w", err)
+		}
+	}
+	return output, nil
+}
+
+// feedDigest returns the briefing document for a feed show: the newest
+// FeedItems items published since its last episode, with their articles.
+func (r Runner) feedDigest(ctx context.Context, s Show, now time.Time) (string, error) {
+	if r.Log != nil {
+		// Skipped articles belong in the run's log, next to generate's output.
+		ctx = logctx.With(ctx, slog.New(slog.NewTextHandler(r.Log, nil)).With("show", s.Name))
+	}
+	items, err := ingest.FetchFeed(ctx, s.Source)
+	if err != nil {
+		return "", err
+	}
+	limit := s.FeedItems
+	if limit == 0 {
+		limit = DefaultFeedItems
+	}
+	var fresh []ingest.FeedItem
+	for _, it := range items {
+		if len(fresh) == limit {
+			break
+		}
+		// Undated items can't be placed; only the first run takes them.
+		if !s.LastSuccess.IsZero() && !it.Published.After(s.LastSuccess) {
+			continue
+		}
+		fresh = append(fresh, it)
+	}
+	if len(fresh) == 0 {
+		return "", ErrNothingNew
+	}
+	return fmt.Sprintf("# %s: %s\n\n", s.Name, now.Format("Monday, January 2, 2006")) + ingest.FeedDigest(ctx, fresh), nil
+}
+
+// OutputFormat returns the format generate flags ask for
+// (--output-format), so an episode path can be given the extension
+// generate will give it. An invalid format is left for generate to reject.
+func OutputFormat(args []string) assembly.Format {
+	for i, arg := range args {
+		v, ok := strings.CutPrefix(arg, "--output-format=")
+		if !ok && arg == "--output-format" && i+1 < len(args) {
+			v, ok = args[i+1], true
+		}
+		if ok {
+			if f, err := assembly.ParseFormat(v); err == nil {
+				return f
+			}
+		}
+	}
+	return assembly.FormatMP3
+}
+
+// Exec runs the podcaster binary with args, its output going to r.Log.
+func (r Runner) Exec(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, r.Exe, args...)
+	cmd.Stdout = r.Log
+	cmd.Stderr = r.Log
+	// Let a generate run finish its current step on shutdown rather than
+	// dying mid-write.
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 30 * time.Second
+	return cmd.Run()
+}
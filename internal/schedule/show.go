@@ -0,0 +1,188 @@
+// Package schedule runs saved show definitions on a cron schedule: a
+// source (a page, or an RSS/Atom feed for briefings) plus generate flags,
+// generated and optionally published automatically by `podcaster shows
+// worker`.
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// ShowsFile holds the saved shows, under the output directory.
+const ShowsFile = "shows.json"
+
+// DefaultFeedItems is how many new feed items a briefing covers by default.
+const DefaultFeedItems = 5
+
+// Show is a recurring generated show.
+type Show struct {
+	Name   string `json:"name"`
+	Source string `json:"source"` // URL or file passed to generate --input
+	Feed   bool   `json:"feed,omitempty"`
+
+	// FeedItems caps how many items newer than the last run a feed show
+	// covers (0 = DefaultFeedItems).
+	FeedItems int `json:"feedItems,omitempty"`
+
+	Cron     string   `json:"cron"`
+	Timezone string   `json:"timezone,omitempty"` // IANA name; empty = local time
+	Args     []string `json:"args,omitempty"`     // extra generate flags
+
+	// Publish uploads each episode with `podcaster publish`, which adds it
+	// to the owner's feed on the platform.
+	Publish bool   `json:"publish,omitempty"`
+	Owner   string `json:"owner,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	// LastRun is when the show last ran, successfully or not, and
+	// LastSuccess when it last produced an episode; a feed show covers
+	// items published since LastSuccess.
+	LastRun     time.Time `json:"lastRun,omitzero"`
+	LastSuccess time.Time `json:"lastSuccess,omitzero"`
+	LastError   string    `json:"lastError,omitempty"`
+	LastEpisode string    `json:"lastEpisode,omitempty"`
+}
+
+var showNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// Validate checks the show's name, schedule, and timezone.
+func (s Show) Validate() error {
+	if !showNameRe.MatchString(s.Name) {
+		return fmt.Errorf("show name %q must be lowercase letters, digits, and dashes", s.Name)
+	}
+	if s.Source == "" {
+		return fmt.Errorf("show %s has no source", s.Name)
+	}
+	if _, err := ParseCron(s.Cron); err != nil {
+		return err
+	}
+	if _, err := s.location(); err != nil {
+		return err
+	}
+	if s.FeedItems < 0 {
+		return fmt.Errorf("show %s: feed items must be positive", s.Name)
+	}
+	return nil
+}
+
+func (s Show) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("show %s: timezone %q: %w", s.Name, s.Timezone, err)
+	}
+	return loc, nil
+}
+
+// NextRun returns when the show next runs: the first scheduled time after
+// its last run (or its creation). A time in the past means it is due; a
+// worker that was down runs a missed show once, not once per missed slot.
+func (s Show) NextRun() (time.Time, error) {
+	c, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := s.location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	from := s.LastRun
+	if from.IsZero() {
+		from = s.CreatedAt
+	}
+	next := c.Next(from.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("show %s: cron %q never matches", s.Name, s.Cron)
+	}
+	return next, nil
+}
+
+// Due reports whether the show should run at now.
+func (s Show) Due(now time.Time) bool {
+	next, err := s.NextRun()
+	return err == nil && !next.After(now)
+}
+
+// Store reads and writes the shows file.
+type Store struct {
+	path string
+}
+
+// NewStore returns a store for the shows file in dir.
+func NewStore(dir string) *Store {
+	return &Store{path: filepath.Join(dir, ShowsFile)}
+}
+
+// Path returns the shows file's path.
+func (st *Store) Path() string { return st.path }
+
+// Load returns the saved shows, sorted by name. A missing file is no shows.
+func (st *Store) Load() ([]Show, error) {
+	data, err := os.ReadFile(st.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read shows: %w", err)
+	}
+	var shows []Show
+	if err := json.Unmarshal(data, &shows); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", st.path, err)
+	}
+	sort.Slice(shows, func(i, j int) bool { return shows[i].Name < shows[j].Name })
+	return shows, nil
+}
+
+// Save replaces the shows file, writing a temp file and renaming it so a
+// crash never leaves it half-written.
+func (st *Store) Save(shows []Show) error {
+	data, err := json.MarshalIndent(shows, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal shows: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(st.path), 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+	tmp := st.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write shows: %w", err)
+	}
+	if err := os.Rename(tmp, st.path); err != nil {
+		return fmt.Errorf("write shows: %w", err)
+	}
+	return nil
+}
+
+// Update applies fn to the named show and saves it.
+func (st *Store) Update(name string, fn func(*Show)) error {
+	shows, err := st.Load()
+	if err != nil {
+		return err
+	}
+	for i := range shows {
+		if shows[i].Name == name {
+			fn(&shows[i])
+			return st.Save(shows)
+		}
+	}
+	return fmt.Errorf("no show %q in %s", name, st.path)
+}
+
+// Find returns the named show.
+func Find(shows []Show, name string) (Show, error) {
+	for _, s := range shows {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return Show{}, fmt.Errorf("no show %q", name)
+}
@@ -3,13 +3,17 @@ package script
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/apresai/podcaster/internal/errkind"
+	"github.com/apresai/podcaster/internal/observability"
 )
 
 var claudeModels = map[string]string{
@@ -47,12 +51,11 @@ func NewClaudeGenerator(model, apiKey string) *ClaudeGenerator {
 }
 
 func (g *ClaudeGenerator) Generate(ctx context.Context, content string, opts GenerateOptions) (*Script, error) {
-	var client anthropic.Client
+	clientOpts := []option.RequestOption{option.WithHTTPClient(&http.Client{Transport: observability.Transport(nil)})}
 	if g.apiKey != "" {
-		client = anthropic.NewClient(option.WithAPIKey(g.apiKey))
-	} else {
-		client = anthropic.NewClient()
+		clientOpts = append(clientOpts, option.WithAPIKey(g.apiKey))
 	}
+	client := anthropic.NewClient(clientOpts...)
 
 	personas := buildPersonaSlice(opts.Voices, opts.SpeakerNames)
 	sysPrompt := buildSystemPrompt(personas)
@@ -64,6 +67,7 @@ func (g *ClaudeGenerator) Generate(ctx context.Context, content string, opts Gen
 	}
 
 	var lastErr error
+	var usage Usage
 	backoff := initialBackoff
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
@@ -83,7 +87,14 @@ func (g *ClaudeGenerator) Generate(ctx context.Context, content string, opts Gen
 			},
 		})
 		if err != nil {
+			var apiErr *anthropic.Error
+			if errors.As(err, &apiErr) {
+				err = apiError("Anthropic", apiErr.StatusCode, "", err)
+			}
 			lastErr = fmt.Errorf("Claude API error (attempt %d/%d): %w", attempt, maxRetries, err)
+			if errkind.Of(err) == errkind.ProviderAuth {
+				break // a rejected key stays rejected
+			}
 			if attempt < maxRetries {
 				select {
 				case <-ctx.Done():
@@ -95,6 +106,8 @@ func (g *ClaudeGenerator) Generate(ctx context.Context, content string, opts Gen
 			continue
 		}
 
+		usage.add(int(message.Usage.InputTokens), int(message.Usage.OutputTokens))
+
 		// Extract text from response
 		text := extractText(message)
 		if text == "" {
@@ -125,6 +138,7 @@ func (g *ClaudeGenerator) Generate(ctx context.Context, content string, opts Gen
 			continue
 		}
 
+		script.Usage = usage
 		return script, nil
 	}
 
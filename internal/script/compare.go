@@ -0,0 +1,128 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScriptStats summarizes a script's shape for side-by-side comparison.
+type ScriptStats struct {
+	Segments     int                `json:"segments"`
+	Words        int                `json:"words"`
+	SpeakerShare map[string]float64 `json:"speaker_share"` // fraction of segments per speaker
+}
+
+// Stats computes a script's segment, word, and speaker counts.
+func Stats(s *Script) ScriptStats {
+	st := ScriptStats{Segments: len(s.Segments), SpeakerShare: make(map[string]float64)}
+	for _, seg := range s.Segments {
+		st.Words += len(strings.Fields(seg.Text))
+		st.SpeakerShare[seg.Speaker]++
+	}
+	for speaker, n := range st.SpeakerShare {
+		st.SpeakerShare[speaker] = n / float64(st.Segments)
+	}
+	return st
+}
+
+// ScriptDiff is a segment-level diff between two scripts.
+type ScriptDiff struct {
+	Similarity  float64  `json:"similarity"`   // 0-1, share of segments the scripts have in common
+	WordOverlap float64  `json:"word_overlap"` // 0-1, Jaccard overlap of the scripts' vocabularies
+	Unchanged   int      `json:"unchanged"`
+	Removed     int      `json:"removed"` // segments only in the first script
+	Added       int      `json:"added"`   // segments only in the second script
+	Lines       []string `json:"lines"`   // "- Speaker: text" / "+ Speaker: text", in script order
+	Truncated   int      `json:"truncated,omitempty"`
+}
+
+// Diff compares two scripts segment by segment (speaker and text must both
+// match) using a longest-common-subsequence alignment. At most maxLines
+// changed lines are listed; the rest are counted in Truncated.
+func Diff(a, b *Script, maxLines int) ScriptDiff {
+	x, y := diffLines(a), diffLines(b)
+	n, m := len(x), len(y)
+
+	// lcs[i][j] is the LCS length of x[i:] and y[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var d ScriptDiff
+	emit := func(line string) {
+		if len(d.Lines) < maxLines {
+			d.Lines = append(d.Lines, line)
+		} else {
+			d.Truncated++
+		}
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && x[i] == y[j]:
+			d.Unchanged++
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+			d.Added++
+			emit("+ " + y[j])
+			j++
+		default:
+			d.Removed++
+			emit("- " + x[i])
+			i++
+		}
+	}
+	if n+m > 0 {
+		d.Similarity = float64(2*d.Unchanged) / float64(n+m)
+	}
+	d.WordOverlap = wordOverlap(a, b)
+	return d
+}
+
+// wordOverlap is the Jaccard index of two scripts' lowercase word sets.
+// Independently generated scripts rarely share whole segments, so this
+// shows how close they are in content.
+func wordOverlap(a, b *Script) float64 {
+	wa, wb := vocabulary(a), vocabulary(b)
+	if len(wa)+len(wb) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wa)+len(wb)-shared)
+}
+
+func vocabulary(s *Script) map[string]bool {
+	words := make(map[string]bool)
+	for _, seg := range s.Segments {
+		for _, w := range strings.Fields(strings.ToLower(seg.Text)) {
+			if w = strings.Trim(w, ".,;:!?\"'()[]*"); w != "" {
+				words[w] = true
+			}
+		}
+	}
+	return words
+}
+
+func diffLines(s *Script) []string {
+	lines := make([]string, len(s.Segments))
+	for i, seg := range s.Segments {
+		lines[i] = fmt.Sprintf("%s: %s", seg.Speaker, strings.Join(strings.Fields(seg.Text), " "))
+	}
+	return lines
+}
@@ -0,0 +1,36 @@
+package script
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// explicitRE matches profanity and explicit sexual content, the language
+// Apple Podcasts expects an episode to be marked explicit for. Words are
+// matched as prefixes ("fucking", "pornography") on word boundaries, and
+// left out where an ordinary word shares the stem ("cock", "dick").
+var explicitRE = regexp.MustCompile(`(?i)\b(?:mother)?(fuck\w*|shit\w*|bullshit\w*|cunt\w*|asshole\w*|bitch\w*|whore\w*|slut\w*|twat\w*|wanker\w*|porn\w*|orgasm\w*|masturbat\w*|blowjob\w*)`)
+
+// ExplicitTerms returns the distinct explicit words in s's title, summary,
+// and segment text, lowercased and sorted; none means the episode is clean.
+// Delivery directions aren't spoken and aren't checked.
+func ExplicitTerms(s *Script) []string {
+	seen := map[string]bool{}
+	check := func(text string) {
+		for _, m := range explicitRE.FindAllString(text, -1) {
+			seen[strings.ToLower(m)] = true
+		}
+	}
+	check(s.Title)
+	check(s.Summary)
+	for _, seg := range s.Segments {
+		check(seg.Text)
+	}
+	terms := make([]string, 0, len(seen))
+	for t := range seen {
+		terms = append(terms, t)
+	}
+	slices.Sort(terms)
+	return terms
+}
@@ -9,6 +9,9 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
+	"github.com/apresai/podcaster/internal/observability"
 )
 
 var geminiModels = map[string]string{
@@ -31,7 +34,7 @@ func NewGeminiGenerator(model, apiKey string) *GeminiGenerator {
 	return &GeminiGenerator{
 		model:      model,
 		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: 120 * time.Second},
+		httpClient: &http.Client{Timeout: 120 * time.Second, Transport: observability.Transport(nil)},
 	}
 }
 
@@ -57,7 +60,12 @@ type geminiTextGenCfg struct {
 
 // geminiTextResponse is the response from Gemini generateContent (text mode).
 type geminiTextResponse struct {
-	Candidates []geminiTextCandidate `json:"candidates"`
+	Candidates    []geminiTextCandidate `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		ThoughtsTokenCount   int `json:"thoughtsTokenCount"` // billed as output
+	} `json:"usageMetadata"`
 }
 
 type geminiTextCandidate struct {
@@ -106,6 +114,7 @@ func (g *GeminiGenerator) Generate(ctx context.Context, content string, opts Gen
 	}
 
 	var lastErr error
+	var usage Usage
 	backoff := initialBackoff
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
@@ -113,9 +122,12 @@ func (g *GeminiGenerator) Generate(ctx context.Context, content string, opts Gen
 			return nil, ctx.Err()
 		}
 
-		text, err := g.doRequest(ctx, modelID, reqBody)
+		text, err := g.doRequest(ctx, modelID, reqBody, &usage)
 		if err != nil {
 			lastErr = fmt.Errorf("Gemini API error (attempt %d/%d): %w", attempt, maxRetries, err)
+			if errkind.Of(err) == errkind.ProviderAuth {
+				break // a rejected key stays rejected
+			}
 			if attempt < maxRetries {
 				select {
 				case <-ctx.Done():
@@ -154,25 +166,29 @@ func (g *GeminiGenerator) Generate(ctx context.Context, content string, opts Gen
 			continue
 		}
 
+		script.Usage = usage
 		return script, nil
 	}
 
 	return nil, lastErr
 }
 
-func (g *GeminiGenerator) doRequest(ctx context.Context, modelID string, reqBody geminiTextRequest) (string, error) {
+// doRequest sends one generateContent call, adding the billed tokens to usage.
+func (g *GeminiGenerator) doRequest(ctx context.Context, modelID string, reqBody geminiTextRequest, usage *Usage) (string, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf(geminiGenerateEndpoint+"?key=%s", modelID, g.apiKey)
+	url := fmt.Sprintf(geminiGenerateEndpoint, modelID)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return "", fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	// In a header, not the query, so it stays out of span URLs and errors.
+	req.Header.Set("x-goog-api-key", g.apiKey)
 
 	res, err := g.httpClient.Do(req)
 	if err != nil {
@@ -183,12 +199,14 @@ func (g *GeminiGenerator) doRequest(ctx context.Context, modelID string, reqBody
 	if res.StatusCode == http.StatusTooManyRequests ||
 		res.StatusCode >= http.StatusInternalServerError {
 		errBody, _ := io.ReadAll(res.Body)
-		return "", fmt.Errorf("retryable error (status %d): %s", res.StatusCode, string(errBody))
+		return "", apiError("Gemini", res.StatusCode, string(errBody),
+			fmt.Errorf("retryable error (status %d): %s", res.StatusCode, string(errBody)))
 	}
 
 	if res.StatusCode != http.StatusOK {
 		errBody, _ := io.ReadAll(res.Body)
-		return "", fmt.Errorf("Gemini API error (status %d): %s", res.StatusCode, string(errBody))
+		return "", apiError("Gemini", res.StatusCode, string(errBody),
+			fmt.Errorf("Gemini API error (status %d): %s", res.StatusCode, string(errBody)))
 	}
 
 	respBody, err := io.ReadAll(res.Body)
@@ -200,6 +218,7 @@ func (g *GeminiGenerator) doRequest(ctx context.Context, modelID string, reqBody
 	if err := json.Unmarshal(respBody, &resp); err != nil {
 		return "", fmt.Errorf("parse response: %w", err)
 	}
+	usage.add(resp.UsageMetadata.PromptTokenCount, resp.UsageMetadata.CandidatesTokenCount+resp.UsageMetadata.ThoughtsTokenCount)
 
 	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
 		return "", fmt.Errorf("response contained no text")
@@ -2,13 +2,16 @@ package script
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/apresai/podcaster/internal/errkind"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
 )
 
 var novaModels = map[string]string{
@@ -25,6 +28,7 @@ func NewNovaGenerator(model string) (*NovaGenerator, error) {
 	if err != nil {
 		return nil, fmt.Errorf("load AWS config: %w", err)
 	}
+	otelaws.AppendMiddlewares(&cfg.APIOptions)
 	return &NovaGenerator{
 		model:  model,
 		client: bedrockruntime.NewFromConfig(cfg),
@@ -44,6 +48,7 @@ func (g *NovaGenerator) Generate(ctx context.Context, content string, opts Gener
 	maxTokens := int32(maxTokensForDuration(opts.Duration))
 
 	var lastErr error
+	var usage Usage
 	backoff := initialBackoff
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
@@ -70,7 +75,11 @@ func (g *NovaGenerator) Generate(ctx context.Context, content string, opts Gener
 			},
 		})
 		if err != nil {
+			err = bedrockError(err)
 			lastErr = fmt.Errorf("Bedrock Converse error (attempt %d/%d): %w", attempt, maxRetries, err)
+			if errkind.Of(err) == errkind.ProviderAuth {
+				break // a rejected key stays rejected
+			}
 			if attempt < maxRetries {
 				select {
 				case <-ctx.Done():
@@ -82,6 +91,10 @@ func (g *NovaGenerator) Generate(ctx context.Context, content string, opts Gener
 			continue
 		}
 
+		if resp.Usage != nil {
+			usage.add(int(aws.ToInt32(resp.Usage.InputTokens)), int(aws.ToInt32(resp.Usage.OutputTokens)))
+		}
+
 		text := extractNovaText(resp)
 		if text == "" {
 			lastErr = fmt.Errorf("empty response from Bedrock (attempt %d/%d)", attempt, maxRetries)
@@ -110,6 +123,7 @@ func (g *NovaGenerator) Generate(ctx context.Context, content string, opts Gener
 			continue
 		}
 
+		script.Usage = usage
 		return script, nil
 	}
 
@@ -131,3 +145,21 @@ func extractNovaText(resp *bedrockruntime.ConverseOutput) string {
 	}
 	return ""
 }
+
+// bedrockError classifies a Converse error by its exception type.
+func bedrockError(err error) error {
+	var (
+		denied      *types.AccessDeniedException
+		throttled   *types.ThrottlingException
+		unavailable *types.ServiceUnavailableException
+	)
+	switch {
+	case errors.As(err, &denied):
+		return errkind.Wrap(errkind.ProviderAuth, "Bedrock denied access to the model", err)
+	case errors.As(err, &throttled):
+		return errkind.Wrap(errkind.ProviderQuota, "Bedrock rate limit reached", err)
+	case errors.As(err, &unavailable):
+		return errkind.Wrap(errkind.ProviderUnavailable, "Bedrock is unavailable", err)
+	}
+	return err
+}
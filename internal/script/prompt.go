@@ -259,12 +259,38 @@ Convert the following content into a %s.
 		prompt += fmt.Sprintf("FOCUS: Center the conversation on: %s\n\n", opts.Topic)
 	}
 
+	if opts.Sources > 1 {
+		prompt += fmt.Sprintf("MULTIPLE SOURCES: The source material is %d separate sources, each headed \"=== SOURCE n: title (origin) ===\". Compare and contrast them rather than blending them into one account: say which source a claim comes from (by its title or publisher, never \"source 2\"), point out where they agree, where they disagree or contradict each other, and what one covers that the others leave out. Give every source a real share of the episode, and in the conclusion weigh the sources against each other.\n\n", opts.Sources)
+	}
+
+	if opts.Community {
+		prompt += "COMMUNITY DISCUSSION: Part of the source material is headed \"=== COMMUNITY DISCUSSION ===\": comments from a Hacker News or Reddit thread about the submission, best-received first. Treat them as readers' opinions, not facts, and keep them apart from what the article itself says. Give the discussion its own part of the episode: what the commenters think, where they agree with or push back on the article, recurring arguments, and standout comments (\"one commenter pointed out...\"; never read out usernames or point counts). Weigh a view by how well-received it was, and don't let the hosts adopt a commenter's claim as settled.\n\n"
+	}
+
 	prompt += fmt.Sprintf("TONE: %s\n\n", toneDescription(opts.Tone))
 
 	if styleDesc := styleDescription(opts.Styles, format); styleDesc != "" {
 		prompt += fmt.Sprintf("STYLE DIRECTIVES:\n%s\n\n", styleDesc)
 	}
 
+	if opts.ProsodyHints {
+		prompt += "DELIVERY HINTS: You may mark delivery inline in segment text. Use [pause short], [pause], or [pause long] for a beat of silence (e.g. before a reveal), and *word* to stress a word. Use them sparingly — at most one or two per segment, and only where a human host would naturally pause or stress."
+		if !opts.DeliveryHints {
+			prompt += " Do not use any other markup."
+		}
+		prompt += "\n\n"
+	}
+
+	if opts.DeliveryHints {
+		prompt += fmt.Sprintf("PERFORMANCE DIRECTION: A segment may include an optional \"delivery\" field with a one- or two-word direction for how the line is performed, e.g. {\"speaker\": \"...\", \"text\": \"...\", \"delivery\": \"whispering\"}. Good directions: whispering, excited, serious, thoughtful, sarcastic, curious, playful, calm. Inside text you may also place these non-verbal tags where the host would make the sound: %s. Leave delivery out for ordinary lines — use it on roughly one segment in five, where the emotion genuinely shifts.\n\n", audioTagList())
+	}
+
+	if len(opts.SFX) > 0 {
+		prompt += fmt.Sprintf("SOUND EFFECTS: You may begin a segment's text with one sound effect marker, [SFX:name], to play that effect just before the line. Available effects: %s. Use them only at real transitions (a new topic, a reveal, a punchline), 2-5 times in the episode, at most one per segment, and never on the first segment. The marker is not spoken.\n\n", strings.Join(opts.SFX, ", "))
+	}
+
+	prompt += "CHAPTERS: Mark the arc you planned. On the first segment of the introduction and of each key theme or section after it, add a \"chapter\" field with a short title (2-6 words) for the part that starts there, e.g. {\"speaker\": \"...\", \"text\": \"...\", \"chapter\": \"Why batteries degrade\"}. Use 3-8 chapters for the episode, in order; leave the field out of every other segment.\n\n"
+
 	prompt += fmt.Sprintf("TARGET LENGTH: %s\n\n", segmentGuidance)
 	prompt += fmt.Sprintf("SOURCE MATERIAL:\n%s", content)
 
@@ -362,3 +388,12 @@ func toneDescription(tone string) string {
 		return "Casual and conversational. Keep it light and engaging. Use everyday language. Make complex ideas approachable."
 	}
 }
+
+// audioTagList formats AudioTags for the prompt ("[laughs], [sighs], ...").
+func audioTagList() string {
+	tags := make([]string, len(AudioTags))
+	for i, t := range AudioTags {
+		tags[i] = "[" + t + "]"
+	}
+	return strings.Join(tags, ", ")
+}
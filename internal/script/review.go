@@ -36,22 +36,10 @@ func NewReviewer(model, apiKey string) (*Reviewer, error) {
 // Review runs Phase A (heuristic checks) and optionally Phase B (LLM review).
 func (r *Reviewer) Review(ctx context.Context, s *Script, content string, opts GenerateOptions) (*ReviewResult, error) {
 	// Phase A: fast heuristic checks
-	var issues []ReviewIssue
-	issues = append(issues, checkSegmentCount(s, opts.Duration)...)
-	issues = append(issues, checkSpeakerBalance(s, opts.Voices)...)
-	issues = append(issues, checkFillerPhrases(s)...)
-
-	// Determine if there are errors (not just warnings)
-	hasErrors := false
-	for _, issue := range issues {
-		if issue.Severity == "error" {
-			hasErrors = true
-			break
-		}
-	}
+	issues := CheckScript(s, opts.Duration, opts.Voices)
 
-	// If Phase A passes clean, skip LLM call
-	if !hasErrors {
+	// If Phase A finds no errors (warnings are fine), skip LLM call
+	if len(ReviewErrors(issues)) == 0 {
 		return &ReviewResult{
 			Approved: true,
 			Issues:   issues, // may contain warnings
@@ -85,6 +73,54 @@ func (r *Reviewer) Review(ctx context.Context, s *Script, content string, opts G
 	}, nil
 }
 
+// escalationModels is the stronger model tried for each script model whose
+// scripts keep failing review, within the same provider.
+var escalationModels = map[string]string{
+	"haiku":        "sonnet",
+	"gemini-flash": "gemini-pro",
+}
+
+// EscalationModel returns the model to retry with when model's script and
+// its revision both fail review, or "" if there is none.
+func EscalationModel(model string) string {
+	return escalationModels[model]
+}
+
+// ReviewErrors returns the issues of error severity.
+func ReviewErrors(issues []ReviewIssue) []ReviewIssue {
+	var errs []ReviewIssue
+	for _, issue := range issues {
+		if issue.Severity == "error" {
+			errs = append(errs, issue)
+		}
+	}
+	return errs
+}
+
+// CheckScript runs the heuristic review checks (segment count, speaker
+// balance, filler phrases) without calling an LLM.
+func CheckScript(s *Script, duration string, voices int) []ReviewIssue {
+	var issues []ReviewIssue
+	issues = append(issues, checkSegmentCount(s, duration)...)
+	issues = append(issues, checkSpeakerBalance(s, voices)...)
+	issues = append(issues, checkFillerPhrases(s)...)
+	return issues
+}
+
+// ReviewScore condenses review issues into a 0-100 score for comparing
+// scripts: 100 minus 25 per error and 5 per warning.
+func ReviewScore(issues []ReviewIssue) int {
+	score := 100
+	for _, issue := range issues {
+		if issue.Severity == "error" {
+			score -= 25
+		} else {
+			score -= 5
+		}
+	}
+	return max(score, 0)
+}
+
 func checkSegmentCount(s *Script, duration string) []ReviewIssue {
 	target := TargetSegments(duration)
 	actual := len(s.Segments)
@@ -219,6 +255,7 @@ INSTRUCTIONS:
 4. If segment count is wrong, add or remove segments to hit the target
 5. If speaker balance is off, redistribute segments more evenly
 6. Replace any filler phrases with specific, content-relevant reactions
+7. Mark beats: add "beat": true to the few segments that should follow a longer pause, such as the reply after a surprising fact lands or the first line of a new topic (at most one every ten segments; leave the field out elsewhere)
 
 SOURCE MATERIAL (for reference):
 %s`,
@@ -4,35 +4,112 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/errkind"
 )
 
 type Script struct {
 	Title    string    `json:"title"`
 	Summary  string    `json:"summary"`
 	Segments []Segment `json:"segments"`
+
+	// Explicit marks the episode explicit. The pipeline sets it from
+	// ExplicitTerms unless overridden (--explicit), before saving.
+	Explicit bool `json:"explicit,omitempty"`
+
+	// Usage is the generator's token usage for this script. It is not saved
+	// with the script.
+	Usage Usage `json:"-"`
 }
 
 type Segment struct {
 	Speaker string `json:"speaker"`
 	Text    string `json:"text"`
+	SSML    string `json:"ssml,omitempty"` // optional SSML for providers that support it; Text stays the plain fallback
+
+	// Delivery is an optional direction for how the line is performed
+	// ("whispering", "excited"). Providers that can't act on it ignore it.
+	Delivery string `json:"delivery,omitempty"`
+
+	// Chapter, if set, titles a chapter that starts at this segment (a
+	// topic transition in the episode's arc). It is not spoken.
+	Chapter string `json:"chapter,omitempty"`
+
+	// Beat marks a dramatic pause before this segment: assembly puts a
+	// longer silence (assembly.BeatGap) before it in place of the usual gap.
+	Beat bool `json:"beat,omitempty"`
+
+	// SFX names a sound effect (assembly.SFXNames) played just before this
+	// segment. The generator marks it with [SFX:name] in the text, which
+	// the pipeline moves here; it is not spoken.
+	SFX string `json:"sfx,omitempty"`
+}
+
+// Beats reports, by segment, which segments follow a beat.
+func (s *Script) Beats() []bool {
+	beats := make([]bool, len(s.Segments))
+	for i, seg := range s.Segments {
+		beats[i] = seg.Beat
+	}
+	return beats
+}
+
+// SoundEffects reports, by segment, the sound effect played before each.
+func (s *Script) SoundEffects() []string {
+	sfx := make([]string, len(s.Segments))
+	for i, seg := range s.Segments {
+		sfx[i] = seg.SFX
+	}
+	return sfx
+}
+
+// AudioTags are the inline non-verbal tags the generator may place in
+// segment text when GenerateOptions.DeliveryHints is set. Providers that
+// can't perform them strip them (tts.StripAudioTags).
+var AudioTags = []string{
+	"laughs", "chuckles", "sighs", "gasps", "whispers", "clears throat",
+	"exhales", "excited", "sarcastic", "curious", "thoughtful", "surprised",
 }
 
 type GenerateOptions struct {
-	Topic        string
-	Tone         string
-	Duration     string
-	Styles       []string
-	Model        string
-	Voices       int      // 1-3, defaults to 2 if 0
-	Format       string   // show format: conversation, interview, debate, etc.
-	SpeakerNames []string // override persona names with voice names (len must match Voices)
+	Topic         string
+	Tone          string
+	Duration      string
+	Styles        []string
+	Model         string
+	Voices        int      // 1-3, defaults to 2 if 0
+	Format        string   // show format: conversation, interview, debate, etc.
+	SpeakerNames  []string // override persona names with voice names (len must match Voices)
+	ProsodyHints  bool     // ask for inline [pause]/*emphasis* hints (converted to SSML by the pipeline)
+	DeliveryHints bool     // ask for per-segment "delivery" and inline audio tags like [laughs]
+	SFX           []string // sound effects the generator may mark with [SFX:name]; none if empty
+	Sources       int      // labeled sources in the content (ingest.Merge); 0 or 1 for one source
+	Community     bool     // the content has a COMMUNITY DISCUSSION section (ingest.ThreadIngester)
 }
 
 type Generator interface {
 	Generate(ctx context.Context, content string, opts GenerateOptions) (*Script, error)
 }
 
+// apiError classifies a model API error by its HTTP status: a rejected key
+// (401, 403, or Gemini's 400 with API_KEY_INVALID), a rate limit (429), or
+// the service failing (5xx). Other errors are returned unchanged.
+func apiError(service string, status int, body string, err error) error {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden ||
+		strings.Contains(body, "API_KEY_INVALID"):
+		return errkind.Wrap(errkind.ProviderAuth, service+" rejected the API key", err)
+	case status == http.StatusTooManyRequests:
+		return errkind.Wrap(errkind.ProviderQuota, service+" rate limit reached", err)
+	case status >= http.StatusInternalServerError:
+		return errkind.Wrap(errkind.ProviderUnavailable, service+" is unavailable", err)
+	}
+	return err
+}
+
 func SaveScript(s *Script, path string) error {
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
@@ -59,6 +136,15 @@ func NewGenerator(model, apiKey string) (Generator, error) {
 	}
 }
 
+// IsValidModel reports whether NewGenerator knows model.
+func IsValidModel(model string) bool {
+	switch model {
+	case "haiku", "sonnet", "gemini-flash", "gemini-pro", "nova-lite":
+		return true
+	}
+	return false
+}
+
 // ModelDisplayName returns a human-readable model name for verbose output.
 func ModelDisplayName(model string) string {
 	names := map[string]string{
@@ -0,0 +1,30 @@
+package script
+
+// Usage is the token count billed for generating a script, summed over
+// every attempt (failed parses are billed too).
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+func (u *Usage) add(input, output int) {
+	u.InputTokens += input
+	u.OutputTokens += output
+}
+
+// modelPricing is the approximate list price per 1M tokens (input, output)
+// for each script model, used for cost estimates only.
+var modelPricing = map[string][2]float64{
+	"haiku":        {0.80, 4.00},
+	"sonnet":       {3.00, 15.00},
+	"gemini-flash": {0.075, 0.30},
+	"gemini-pro":   {1.25, 10.00},
+	"nova-lite":    {0.06, 0.24},
+}
+
+// CostUSD estimates the cost of u at the model's list price (0 for unknown
+// models).
+func (u Usage) CostUSD(model string) float64 {
+	p := modelPricing[model]
+	return float64(u.InputTokens)*p[0]/1_000_000 + float64(u.OutputTokens)*p[1]/1_000_000
+}
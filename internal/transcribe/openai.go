@@ -0,0 +1,118 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
+	"github.com/apresai/podcaster/internal/observability"
+)
+
+const (
+	openAITranscriptionsURL = "https://api.openai.com/v1/audio/transcriptions"
+	openAIModel             = "whisper-1"
+
+	// openAIChunk keeps each upload well under the API's 25 MB limit: ten
+	// minutes of 32 kbps mono MP3 is about 2.4 MB.
+	openAIChunk = 10 * time.Minute
+)
+
+// OpenAI transcribes with OpenAI's Whisper API.
+type OpenAI struct {
+	apiKey     string
+	url        string
+	httpClient *http.Client
+}
+
+func newOpenAI(apiKey string) (*OpenAI, error) {
+	if apiKey == "" {
+		return nil, errkind.New(errkind.UserInput, "the Whisper API needs an OpenAI API key (OPENAI_API_KEY or --openai-api-key)")
+	}
+	return &OpenAI{
+		apiKey:     apiKey,
+		url:        openAITranscriptionsURL,
+		httpClient: &http.Client{Timeout: 5 * time.Minute, Transport: observability.Transport(nil)},
+	}, nil
+}
+
+func (o *OpenAI) Name() string         { return EngineOpenAI }
+func (o *OpenAI) WAV() bool            { return false }
+func (o *OpenAI) Chunk() time.Duration { return openAIChunk }
+
+// Transcribe sends the files one at a time, reporting progress after each.
+func (o *OpenAI) Transcribe(ctx context.Context, files []string, progress Progress) (string, error) {
+	parts := make([]string, 0, len(files))
+	for i, path := range files {
+		text, err := o.transcribeFile(ctx, path)
+		if err != nil {
+			if len(files) > 1 {
+				return "", fmt.Errorf("chunk %d/%d: %w", i+1, len(files), err)
+			}
+			return "", err
+		}
+		parts = append(parts, text)
+		if progress != nil {
+			progress(float64(i+1) / float64(len(files)))
+		}
+	}
+	return joinTranscripts(parts), nil
+}
+
+func (o *OpenAI) transcribeFile(ctx context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read speech: %w", err)
+	}
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("model", openAIModel)
+	w.WriteField("response_format", "text")
+	part, err := w.CreatePart(map[string][]string{
+		"Content-Disposition": {fmt.Sprintf(`form-data; name="file"; filename=%q`, filepath.Base(path))},
+		"Content-Type":        {contentType(path)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	part.Write(data)
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, &body)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	res, err := o.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+	text, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		err := fmt.Errorf("OpenAI API error (status %d): %s", res.StatusCode, text)
+		switch {
+		case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+			return "", errkind.Wrap(errkind.ProviderAuth, "OpenAI rejected the API key", err)
+		case res.StatusCode == http.StatusTooManyRequests:
+			return "", errkind.Wrap(errkind.ProviderQuota, "OpenAI quota or rate limit reached", err)
+		case res.StatusCode >= http.StatusInternalServerError:
+			return "", errkind.Wrap(errkind.ProviderUnavailable, "OpenAI is unavailable", err)
+		}
+		return "", err
+	}
+	return string(text), nil
+}
@@ -0,0 +1,118 @@
+// Package transcribe turns the speech in audio and video files into text,
+// so a recording (a webinar, a talk, another podcast) can be the source
+// content of an episode. Two engines are supported: OpenAI's Whisper API,
+// which takes uploads of up to 25 MB, so long recordings are sent in
+// chunks, and a local whisper.cpp, which needs no key and sends nothing
+// over the network. FFmpeg extracts the speech for both
+// (assembly.ExtractSpeech).
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
+)
+
+// Engine names, as taken by --transcriber.
+const (
+	EngineOpenAI     = "openai"
+	EngineWhisperCPP = "whisper-cpp"
+)
+
+// Engines lists the engine names.
+var Engines = []string{EngineOpenAI, EngineWhisperCPP}
+
+// Progress is called as transcription advances, with the fraction done.
+type Progress func(done float64)
+
+// Transcriber transcribes speech audio prepared by assembly.ExtractSpeech.
+type Transcriber interface {
+	// Name is the engine's name.
+	Name() string
+
+	// WAV reports whether the engine takes 16 kHz WAV (else MP3), and
+	// Chunk how long each file may be (0 for no limit).
+	WAV() bool
+	Chunk() time.Duration
+
+	// Transcribe returns the text spoken in files, in order.
+	Transcribe(ctx context.Context, files []string, progress Progress) (string, error)
+}
+
+// Options choose and configure the engine.
+type Options struct {
+	// Engine is EngineOpenAI, EngineWhisperCPP, or "" for the first one
+	// configured: OpenAI with a key, else whisper.cpp with a model.
+	Engine string
+
+	OpenAIAPIKey string // else OPENAI_API_KEY
+	WhisperModel string // a ggml model file; else WHISPER_CPP_MODEL
+}
+
+// New returns the engine opts choose.
+func New(opts Options) (Transcriber, error) {
+	if opts.OpenAIAPIKey == "" {
+		opts.OpenAIAPIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if opts.WhisperModel == "" {
+		opts.WhisperModel = os.Getenv("WHISPER_CPP_MODEL")
+	}
+	switch opts.Engine {
+	case EngineOpenAI:
+		return newOpenAI(opts.OpenAIAPIKey)
+	case EngineWhisperCPP:
+		return newWhisperCPP(opts.WhisperModel)
+	case "":
+		if opts.OpenAIAPIKey != "" {
+			return newOpenAI(opts.OpenAIAPIKey)
+		}
+		if opts.WhisperModel != "" {
+			return newWhisperCPP(opts.WhisperModel)
+		}
+		return nil, errkind.New(errkind.UserInput, "transcribing audio or video input needs OPENAI_API_KEY (Whisper API) "+
+			"or a whisper.cpp model (WHISPER_CPP_MODEL or --whisper-model)")
+	}
+	return nil, fmt.Errorf("unknown transcriber %q: must be %s", opts.Engine, strings.Join(Engines, " or "))
+}
+
+// whisperBinaries are the names whisper.cpp's CLI is installed under, newest
+// first; WHISPER_CPP_BIN overrides them.
+var whisperBinaries = []string{"whisper-cli", "whisper-cpp"}
+
+// findWhisperBinary returns the whisper.cpp CLI's path.
+func findWhisperBinary() (string, error) {
+	if bin := os.Getenv("WHISPER_CPP_BIN"); bin != "" {
+		return exec.LookPath(bin)
+	}
+	for _, name := range whisperBinaries {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", errkind.New(errkind.UserInput, "whisper.cpp not found: install it (whisper-cli on PATH) or set WHISPER_CPP_BIN")
+}
+
+// joinTranscripts joins chunk transcripts into paragraphs.
+func joinTranscripts(parts []string) string {
+	var kept []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+// contentType is the upload type of a speech file.
+func contentType(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".wav") {
+		return "audio/wav"
+	}
+	return "audio/mpeg"
+}
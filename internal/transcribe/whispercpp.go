@@ -0,0 +1,114 @@
+package transcribe
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
+)
+
+// whisperProgress matches the progress lines whisper.cpp prints with -pp:
+// "whisper_print_progress_callback: progress =  45%".
+var whisperProgress = regexp.MustCompile(`progress\s*=\s*(\d+)%`)
+
+// whisperWaitDelay bounds how long Wait blocks on output pipes after the
+// process is killed.
+const whisperWaitDelay = 5 * time.Second
+
+// WhisperCPP transcribes locally with whisper.cpp's CLI.
+type WhisperCPP struct {
+	bin   string
+	model string
+}
+
+func newWhisperCPP(model string) (*WhisperCPP, error) {
+	if model == "" {
+		return nil, errkind.New(errkind.UserInput, "whisper.cpp needs a model file (WHISPER_CPP_MODEL or --whisper-model), e.g. ggml-base.en.bin")
+	}
+	if _, err := os.Stat(model); err != nil {
+		return nil, errkind.Wrap(errkind.UserInput, "whisper.cpp model not found", err)
+	}
+	bin, err := findWhisperBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &WhisperCPP{bin: bin, model: model}, nil
+}
+
+func (w *WhisperCPP) Name() string         { return EngineWhisperCPP }
+func (w *WhisperCPP) WAV() bool            { return true }
+func (w *WhisperCPP) Chunk() time.Duration { return 0 }
+
+// Transcribe runs whisper.cpp on each file, passing on its progress.
+func (w *WhisperCPP) Transcribe(ctx context.Context, files []string, progress Progress) (string, error) {
+	parts := make([]string, 0, len(files))
+	for i, path := range files {
+		fileProgress := func(done float64) {
+			if progress != nil {
+				progress((float64(i) + done) / float64(len(files)))
+			}
+		}
+		text, err := w.transcribeFile(ctx, path, fileProgress)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, text)
+	}
+	return joinTranscripts(parts), nil
+}
+
+func (w *WhisperCPP) transcribeFile(ctx context.Context, path string, progress Progress) (string, error) {
+	// -nt: plain text without timestamps; -np: nothing else on stdout;
+	// -pp: progress on stderr; -l auto: detect the language.
+	cmd := exec.CommandContext(ctx, w.bin, "-m", w.model, "-f", path, "-l", "auto", "-nt", "-np", "-pp")
+	cmd.WaitDelay = whisperWaitDelay
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("start whisper.cpp: %w", err)
+	}
+
+	// Keep the end of stderr for the error, and report progress as it
+	// comes.
+	var errTail []string
+	sc := bufio.NewScanner(stderr)
+	for sc.Scan() {
+		line := sc.Text()
+		if m := whisperProgress.FindStringSubmatch(line); m != nil {
+			pct, _ := strconv.Atoi(m[1])
+			progress(float64(pct) / 100)
+			continue
+		}
+		errTail = append(errTail, line)
+		if len(errTail) > 20 {
+			errTail = errTail[1:]
+		}
+	}
+	io.Copy(io.Discard, stderr)
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("whisper.cpp cancelled: %w", ctx.Err())
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("whisper.cpp failed: %w\n%s", err, strings.Join(errTail, "\n"))
+		}
+		return "", fmt.Errorf("whisper.cpp failed: %w", err)
+	}
+	return out.String(), nil
+}
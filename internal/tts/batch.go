@@ -0,0 +1,169 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// BatchMaxChars bounds the dialogue sent in one batch request. Gemini TTS
+// caps both input tokens and output audio per request; a "deep" script
+// (~150 segments) exceeds either in one go. 4000 characters is roughly four
+// to five minutes of speech, comfortably under both limits.
+const BatchMaxChars = 4000
+
+// SplitBatch groups segments into consecutive chunks of at most maxChars
+// dialogue characters, splitting only between segments. A single segment
+// longer than maxChars gets a chunk of its own.
+func SplitBatch(segments []script.Segment, maxChars int) [][]script.Segment {
+	var chunks [][]script.Segment
+	var cur []script.Segment
+	size := 0
+	for _, seg := range segments {
+		n := len(seg.Speaker) + len(seg.Text) + 3 // "Speaker: text\n"
+		if len(cur) > 0 && size+n > maxChars {
+			chunks = append(chunks, cur)
+			cur, size = nil, 0
+		}
+		cur = append(cur, seg)
+		size += n
+	}
+	if len(cur) > 0 {
+		chunks = append(chunks, cur)
+	}
+	return chunks
+}
+
+// SynthesizeBatchChunked synthesizes a script through a BatchProvider in
+// chunks of at most BatchMaxChars, concatenating the PCM results into one
+// stream. onChunk (optional) is called after each chunk with the number
+// done and the total.
+//
+// When there is more than one chunk, each is retried on transient errors
+// under retry, so one bad response doesn't throw away the audio already
+// produced. Each chunk is recorded to the context's Metrics.
+func SynthesizeBatchChunked(ctx context.Context, bp BatchProvider, segments []script.Segment, voices VoiceMap, retry RetryPolicy, onChunk func(done, total int)) (AudioResult, error) {
+	metrics := MetricsFromContext(ctx)
+	chunks := SplitBatch(segments, BatchMaxChars)
+	if len(chunks) <= 1 {
+		start := time.Now()
+		result, err := bp.SynthesizeBatch(ctx, segments, voices)
+		metrics.Record(ctx, Call{Provider: bp.Name(), Op: "batch", Duration: time.Since(start), Bytes: int64(len(result.Data)), Attempts: 1, Err: err})
+		if err == nil && onChunk != nil {
+			onChunk(1, 1)
+		}
+		return result, err
+	}
+
+	fmt.Fprintf(os.Stderr, "[%s-batch] Script split into %d chunks (max %d chars each)\n", bp.Name(), len(chunks), BatchMaxChars)
+
+	var pcm []byte
+	for i, chunk := range chunks {
+		var result AudioResult
+		attempts := 0
+		start := time.Now()
+		err := WithRetryPolicy(ctx, retry, func() error {
+			attempts++
+			var err error
+			result, err = bp.SynthesizeBatch(ctx, chunk, voices)
+			return err
+		})
+		metrics.Record(ctx, Call{Provider: bp.Name(), Op: "batch", Duration: time.Since(start), Bytes: int64(len(result.Data)), Attempts: attempts, Err: err})
+		if err != nil {
+			return AudioResult{}, fmt.Errorf("batch chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		// Raw PCM from the same provider and model shares one sample format,
+		// so chunks join seamlessly by appending.
+		if result.Format != FormatPCM {
+			return AudioResult{}, fmt.Errorf("batch chunk %d/%d: cannot concatenate %s audio", i+1, len(chunks), result.Format)
+		}
+		pcm = append(pcm, result.Data...)
+		if onChunk != nil {
+			onChunk(i+1, len(chunks))
+		}
+	}
+	return AudioResult{Data: pcm, Format: FormatPCM}, nil
+}
+
+// SynthesizeBatchToFile is SynthesizeBatchChunked writing the audio to f
+// instead of returning it, so a long script never has to fit in memory.
+// Providers implementing StreamBatchProvider stream each chunk straight to
+// f as it is decoded; a retried chunk first truncates f back to where the
+// chunk started. Other providers are synthesized in memory and written out.
+// As there, each chunk is recorded to the context's Metrics.
+func SynthesizeBatchToFile(ctx context.Context, bp BatchProvider, segments []script.Segment, voices VoiceMap, retry RetryPolicy, f *os.File, onChunk func(done, total int)) (AudioFormat, error) {
+	sp, ok := bp.(StreamBatchProvider)
+	if !ok {
+		result, err := SynthesizeBatchChunked(ctx, bp, segments, voices, retry, onChunk)
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(result.Data); err != nil {
+			return "", fmt.Errorf("write batch audio: %w", err)
+		}
+		return result.Format, nil
+	}
+
+	metrics := MetricsFromContext(ctx)
+	chunks := SplitBatch(segments, BatchMaxChars)
+	if len(chunks) <= 1 {
+		off, start := fileSize(f), time.Now()
+		format, err := sp.StreamBatch(ctx, segments, voices, f)
+		metrics.Record(ctx, Call{Provider: bp.Name(), Op: "batch", Duration: time.Since(start), Bytes: fileSize(f) - off, Attempts: 1, Err: err})
+		if err == nil && onChunk != nil {
+			onChunk(1, 1)
+		}
+		return format, err
+	}
+
+	fmt.Fprintf(os.Stderr, "[%s-batch] Script split into %d chunks (max %d chars each), streaming to disk\n", bp.Name(), len(chunks), BatchMaxChars)
+
+	for i, chunk := range chunks {
+		start, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", fmt.Errorf("batch chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		var format AudioFormat
+		attempts := 0
+		chunkStart := time.Now()
+		err = WithRetryPolicy(ctx, retry, func() error {
+			attempts++
+			// Drop whatever a failed attempt managed to write.
+			if err := f.Truncate(start); err != nil {
+				return fmt.Errorf("truncate batch audio: %w", err)
+			}
+			if _, err := f.Seek(start, io.SeekStart); err != nil {
+				return fmt.Errorf("seek batch audio: %w", err)
+			}
+			var err error
+			format, err = sp.StreamBatch(ctx, chunk, voices, f)
+			return err
+		})
+		metrics.Record(ctx, Call{Provider: bp.Name(), Op: "batch", Duration: time.Since(chunkStart), Bytes: fileSize(f) - start, Attempts: attempts, Err: err})
+		if err != nil {
+			return "", fmt.Errorf("batch chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		// See SynthesizeBatchChunked: only raw PCM joins by appending.
+		if format != FormatPCM {
+			return "", fmt.Errorf("batch chunk %d/%d: cannot concatenate %s audio", i+1, len(chunks), format)
+		}
+		if onChunk != nil {
+			onChunk(i+1, len(chunks))
+		}
+	}
+	return FormatPCM, nil
+}
+
+// fileSize returns f's current write offset, which for the append-only
+// batch file is the audio written so far (0 if it can't be read).
+func fileSize(f *os.File) int64 {
+	off, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0
+	}
+	return off
+}
@@ -0,0 +1,123 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
+)
+
+// DefaultBreakerThreshold is how many consecutive requests to one provider
+// may fail with server errors or timeouts before its circuit breaker trips.
+// Rate limits and quota (429) and credential refreshes (401) don't count:
+// they are handled by backoff, key rotation, and the fallback chain.
+const DefaultBreakerThreshold = 5
+
+// breakerProbeAfter is how long a tripped breaker stays open before it lets
+// one request through as a probe (half-open). A successful probe closes it;
+// a failed one keeps it open for another breakerProbeAfter.
+const breakerProbeAfter = 30 * time.Second
+
+// CircuitOpenError is returned instead of calling a provider whose circuit
+// breaker has tripped. It is not retryable; the pipeline fails over to the
+// fallback chain if there is one, or fails fast.
+type CircuitOpenError struct {
+	Provider string
+	Failures int
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s circuit breaker open after %d consecutive failed requests; not retrying", e.Provider, e.Failures)
+}
+
+func (e *CircuitOpenError) ErrorKind() errkind.Kind { return errkind.ProviderUnavailable }
+
+// IsCircuitOpen reports whether err (or anything it wraps) is a
+// CircuitOpenError.
+func IsCircuitOpen(err error) bool {
+	var ce *CircuitOpenError
+	return errors.As(err, &ce)
+}
+
+// SetBreakerThreshold sets the consecutive failures that trip a provider's
+// breaker. n <= 0 disables the breaker.
+func (ps *ProviderSet) SetBreakerThreshold(n int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.breakerThreshold = n
+}
+
+// Allow returns a CircuitOpenError if the named provider's breaker has
+// tripped. Call it before each request attempt, so retries already in
+// flight on other segments stop as soon as the provider is declared dead.
+// Once breakerProbeAfter has passed, one caller is let through as a probe.
+func (ps *ProviderSet) Allow(name string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if !ps.tripped[name] {
+		return nil
+	}
+	if !ps.probing[name] && time.Since(ps.openedAt[name]) >= breakerProbeAfter {
+		ps.probing[name] = true
+		return nil
+	}
+	return &CircuitOpenError{Provider: name, Failures: ps.failures[name]}
+}
+
+// Record feeds one request attempt's outcome into the named provider's
+// breaker. A success resets the count and closes a tripped breaker; a
+// server error or timeout increments it and trips the breaker at the
+// threshold, or reopens it after a failed probe. Other errors (rate limits,
+// bad input, auth, cancellation) say nothing about the provider's health
+// and are ignored.
+func (ps *ProviderSet) Record(name string, err error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	probe := ps.probing[name]
+	ps.probing[name] = false
+	if err == nil {
+		if ps.tripped[name] {
+			ps.tripped[name] = false
+			fmt.Fprintf(os.Stderr, "[breaker] %s recovered; closing its breaker\n", name)
+		}
+		ps.failures[name] = 0
+		return
+	}
+	if !countsAgainstBreaker(err) || ps.breakerThreshold <= 0 {
+		return
+	}
+	if ps.tripped[name] {
+		if probe {
+			ps.openedAt[name] = time.Now()
+		}
+		return
+	}
+	ps.failures[name]++
+	if ps.failures[name] >= ps.breakerThreshold {
+		ps.tripped[name] = true
+		ps.openedAt[name] = time.Now()
+		fmt.Fprintf(os.Stderr, "[breaker] %s tripped after %d consecutive failed requests\n", name, ps.failures[name])
+	}
+}
+
+// Tripped reports whether the named provider's breaker has tripped.
+func (ps *ProviderSet) Tripped(name string) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.tripped[name]
+}
+
+// countsAgainstBreaker reports whether err says the provider is failing: a
+// retryable server error or a timeout. A 429 (rate limit, possibly already
+// rotated to another key) and a 401 (credentials being refreshed) don't.
+func countsAgainstBreaker(err error) bool {
+	var re *RetryableError
+	if errors.As(err, &re) {
+		return re.StatusCode != http.StatusTooManyRequests && re.StatusCode != http.StatusUnauthorized
+	}
+	return os.IsTimeout(err) || errors.Is(err, context.DeadlineExceeded)
+}
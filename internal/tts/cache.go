@@ -0,0 +1,191 @@
+package tts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheMaxBytes caps the on-disk segment cache. When a write pushes
+// the cache over the cap, least-recently-used entries are evicted.
+const DefaultCacheMaxBytes = 500 * 1024 * 1024
+
+// cacheVersion is mixed into every key so a change in how audio is produced
+// (not captured by the key fields) can invalidate old entries wholesale.
+const cacheVersion = "v1"
+
+// cacheFormats lists the extensions probed on lookup, most common first.
+var cacheFormats = []AudioFormat{FormatMP3, FormatPCM, FormatWAV}
+
+// Cache is a content-addressed store of synthesized segments. Entries are
+// keyed by everything that affects the audio (provider, model, voice,
+// speed/stability/pitch, text), so re-running a failed pipeline or a
+// --from-script run skips segments that were already synthesized.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu   sync.Mutex
+	size int64
+}
+
+// NewCache opens (creating if needed) a segment cache rooted at dir.
+// maxBytes <= 0 uses DefaultCacheMaxBytes.
+func NewCache(dir string, maxBytes int64) (*Cache, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultCacheMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+
+	c := &Cache{dir: dir, maxBytes: maxBytes}
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		c.size += e.size
+	}
+	return c, nil
+}
+
+// Key returns the cache key for a segment. cfg should be the ProviderConfig
+// the provider was constructed with.
+func (c *Cache) Key(provider string, cfg ProviderConfig, voiceID, text string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%g\x00%g\x00%g\x00",
+		cacheVersion, provider, cfg.Model, voiceID, cfg.Speed, cfg.Stability, cfg.Pitch)
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached audio for key, if present. A hit refreshes the
+// entry's modification time so eviction is least-recently-used.
+func (c *Cache) Get(key string) (AudioResult, bool) {
+	for _, format := range cacheFormats {
+		path := c.path(key, format)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if len(data) == 0 {
+			os.Remove(path)
+			continue
+		}
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		return AudioResult{Data: data, Format: format}, true
+	}
+	return AudioResult{}, false
+}
+
+// Put stores audio under key, evicting old entries if the cache is over its
+// size cap. The write is atomic so a crash never leaves a truncated entry.
+func (c *Cache) Put(key string, result AudioResult) error {
+	if len(result.Data) == 0 {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create cache temp file: %w", err)
+	}
+	if _, err := tmp.Write(result.Data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("close cache entry: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Overwriting an entry replaces its size rather than adding to it.
+	path := c.path(key, result.Format)
+	var old int64
+	if info, err := os.Stat(path); err == nil {
+		old = info.Size()
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("commit cache entry: %w", err)
+	}
+	c.size += int64(len(result.Data)) - old
+	if c.size > c.maxBytes {
+		return c.evictLocked()
+	}
+	return nil
+}
+
+// Dir returns the cache root directory.
+func (c *Cache) Dir() string { return c.dir }
+
+func (c *Cache) path(key string, format AudioFormat) string {
+	return filepath.Join(c.dir, key+"."+string(format))
+}
+
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (c *Cache) entries() ([]cacheEntry, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read cache dir: %w", err)
+	}
+	var out []cacheEntry
+	for _, de := range dirEntries {
+		if de.IsDir() || strings.HasSuffix(de.Name(), ".tmp") {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, cacheEntry{
+			path:    filepath.Join(c.dir, de.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+	return out, nil
+}
+
+// evictLocked removes least-recently-used entries until the cache is back
+// under 90% of its cap, leaving headroom so every write doesn't trigger a scan.
+func (c *Cache) evictLocked() error {
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+	target := c.maxBytes * 9 / 10
+	for _, e := range entries {
+		if total <= target {
+			break
+		}
+		if err := os.Remove(e.path); err == nil {
+			total -= e.size
+		}
+	}
+	c.size = total
+	return nil
+}
@@ -0,0 +1,256 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	cartesiaDefaultVoice1 = "228fca29-3a0a-435c-8728-5cb483251068" // Kiefer
+	cartesiaDefaultVoice2 = "f786b574-daa5-4673-aa0c-cbe3e8534c02" // Katie
+	cartesiaDefaultVoice3 = "694f9389-aac1-45b6-b726-9d9369183238" // Sarah
+
+	// cartesiaBaseURL is the bytes endpoint. It streams the encoded audio back
+	// as a chunked response, so first bytes arrive well before synthesis finishes.
+	cartesiaBaseURL      = "https://api.cartesia.ai/tts/bytes"
+	cartesiaVoicesURL    = "https://api.cartesia.ai/voices"
+	cartesiaAPIVersion   = "2025-04-16"
+	cartesiaDefaultModel = "sonic-2"
+	cartesiaSampleRate   = 44100
+	cartesiaBitRate      = 192000
+)
+
+type cartesiaRequest struct {
+	ModelID      string               `json:"model_id"`
+	Transcript   string               `json:"transcript"`
+	Voice        cartesiaVoiceSpec    `json:"voice"`
+	OutputFormat cartesiaOutputFormat `json:"output_format"`
+	Language     string               `json:"language,omitempty"`
+}
+
+type cartesiaVoiceSpec struct {
+	Mode string `json:"mode"`
+	ID   string `json:"id"`
+}
+
+type cartesiaOutputFormat struct {
+	Container  string `json:"container"`
+	SampleRate int    `json:"sample_rate"`
+	BitRate    int    `json:"bit_rate,omitempty"`
+}
+
+// CartesiaProvider implements Provider using the Cartesia Sonic TTS API.
+type CartesiaProvider struct {
+	voices     VoiceMap
+	apiKey     string
+	httpClient *http.Client
+	model      string
+}
+
+func NewCartesiaProvider(voice1, voice2, voice3 string, cfg ProviderConfig) *CartesiaProvider {
+	v1 := cartesiaDefaultVoice1
+	v2 := cartesiaDefaultVoice2
+	v3 := cartesiaDefaultVoice3
+	if voice1 != "" {
+		v1 = voice1
+	}
+	if voice2 != "" {
+		v2 = voice2
+	}
+	if voice3 != "" {
+		v3 = voice3
+	}
+
+	model := cartesiaDefaultModel
+	if cfg.Model != "" {
+		model = cfg.Model
+	}
+
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("CARTESIA_API_KEY")
+	}
+
+	return &CartesiaProvider{
+		voices: VoiceMap{
+			Host1: Voice{ID: v1, Name: "Kiefer"},
+			Host2: Voice{ID: v2, Name: "Katie"},
+			Host3: Voice{ID: v3, Name: "Sarah"},
+		},
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 60 * time.Second, Transport: ttsTransport(cfg, 0)},
+		model:      model,
+	}
+}
+
+func (p *CartesiaProvider) Name() string { return "cartesia" }
+
+func (p *CartesiaProvider) DefaultVoices() VoiceMap {
+	return VoiceMap{
+		Host1: Voice{ID: cartesiaDefaultVoice1, Name: "Kiefer"},
+		Host2: Voice{ID: cartesiaDefaultVoice2, Name: "Katie"},
+		Host3: Voice{ID: cartesiaDefaultVoice3, Name: "Sarah"},
+	}
+}
+
+func (p *CartesiaProvider) Synthesize(ctx context.Context, text string, voice Voice) (AudioResult, error) {
+	reqBody := cartesiaRequest{
+		ModelID:    p.model,
+		Transcript: text,
+		Voice:      cartesiaVoiceSpec{Mode: "id", ID: voice.ID},
+		OutputFormat: cartesiaOutputFormat{
+			Container:  "mp3",
+			SampleRate: cartesiaSampleRate,
+			BitRate:    cartesiaBitRate,
+		},
+		Language: "en",
+	}
+
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return AudioResult{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cartesiaBaseURL, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return AudioResult{}, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("X-API-Key", p.apiKey)
+	req.Header.Set("Cartesia-Version", cartesiaAPIVersion)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return AudioResult{}, fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusTooManyRequests ||
+		res.StatusCode >= http.StatusInternalServerError {
+		errBody, _ := io.ReadAll(res.Body)
+		return AudioResult{}, &RetryableError{
+			StatusCode: res.StatusCode,
+			Body:       string(errBody),
+		}
+	}
+
+	if res.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(res.Body)
+		return AudioResult{}, statusError("cartesia", res.StatusCode, string(errBody),
+			fmt.Errorf("Cartesia API error (status %d): %s", res.StatusCode, string(errBody)))
+	}
+
+	// Drain the chunked stream into memory; segments are short enough that
+	// buffering is simpler than piping straight to disk.
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return AudioResult{}, fmt.Errorf("read response: %w", err)
+	}
+	if len(data) == 0 {
+		return AudioResult{}, fmt.Errorf("Cartesia returned empty audio")
+	}
+
+	return AudioResult{Data: data, Format: FormatMP3}, nil
+}
+
+func (p *CartesiaProvider) Close() error { return nil }
+
+// cartesiaVoicesResponse is the API response from GET /voices.
+type cartesiaVoicesResponse struct {
+	Data []cartesiaVoice `json:"data"`
+}
+
+type cartesiaVoice struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Gender      string `json:"gender"`
+	Language    string `json:"language"`
+}
+
+// fetchCartesiaVoices calls the Cartesia API to list English voices visible to the key.
+func fetchCartesiaVoices(apiKey string) ([]VoiceInfo, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	req, err := http.NewRequest(http.MethodGet, cartesiaVoicesURL+"?limit=100", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("X-API-Key", apiKey)
+	req.Header.Set("Cartesia-Version", cartesiaAPIVersion)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch voices: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("Cartesia voices API error (status %d): %s", res.StatusCode, string(body))
+	}
+
+	var resp cartesiaVoicesResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	voices := make([]VoiceInfo, 0, len(resp.Data))
+	for _, v := range resp.Data {
+		if v.Language != "" && v.Language != "en" {
+			continue
+		}
+
+		info := VoiceInfo{
+			ID:          v.ID,
+			Name:        v.Name,
+			Description: v.Description,
+			Language:    "en",
+		}
+		switch strings.ToLower(v.Gender) {
+		case "masculine", "male":
+			info.Gender = "male"
+		case "feminine", "female":
+			info.Gender = "female"
+		}
+
+		switch v.ID {
+		case cartesiaDefaultVoice1:
+			info.DefaultFor = "Voice 1"
+		case cartesiaDefaultVoice2:
+			info.DefaultFor = "Voice 2"
+		case cartesiaDefaultVoice3:
+			info.DefaultFor = "Voice 3"
+		}
+
+		voices = append(voices, info)
+	}
+
+	return voices, nil
+}
+
+func cartesiaAvailableVoices() []VoiceInfo {
+	// Try live fetch if API key is available.
+	if apiKey := os.Getenv("CARTESIA_API_KEY"); apiKey != "" {
+		if voices, err := fetchCartesiaVoices(apiKey); err == nil && len(voices) > 0 {
+			return voices
+		}
+	}
+
+	// Fallback to hardcoded list.
+	return []VoiceInfo{
+		{ID: "228fca29-3a0a-435c-8728-5cb483251068", Name: "Kiefer", Gender: "male", Description: "Calm, steady American male", Language: "en-US", DefaultFor: "Voice 1"},
+		{ID: "f786b574-daa5-4673-aa0c-cbe3e8534c02", Name: "Katie", Gender: "female", Description: "Friendly, conversational female", Language: "en-US", DefaultFor: "Voice 2"},
+		{ID: "694f9389-aac1-45b6-b726-9d9369183238", Name: "Sarah", Gender: "female", Description: "Soft, clear American female", Language: "en-US", DefaultFor: "Voice 3"},
+		{ID: "a0e99841-438c-4a64-b679-ae501e7d6091", Name: "Barbershop Man", Gender: "male", Description: "Casual, warm American male", Language: "en-US"},
+		{ID: "79a125e8-cd45-4c13-8a67-188112f4dd22", Name: "British Lady", Gender: "female", Description: "Elegant British female", Language: "en-GB"},
+	}
+}
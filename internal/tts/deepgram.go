@@ -0,0 +1,374 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// deepgramBaseURL takes the text as JSON and returns the encoded audio
+	// as the response body. The voice is part of the model name
+	// (aura-2-thalia-en), passed as a query parameter.
+	deepgramBaseURL      = "https://api.deepgram.com/v1/speak"
+	deepgramModelsURL    = "https://api.deepgram.com/v1/models"
+	deepgramProjectsURL  = "https://api.deepgram.com/v1/projects"
+	deepgramDefaultModel = "aura-2"
+	deepgramBitRate      = 48000 // highest MP3 bit rate /v1/speak offers
+
+	// deepgramMaxChars is the API's limit on text per request. Longer
+	// segments are split at sentence ends and the MP3 parts concatenated.
+	deepgramMaxChars = 2000
+)
+
+// deepgramDefaultVoices are the host defaults per model. Aura 2 has a
+// larger catalog; Aura (1) lacks some of its voices, including apollo and
+// thalia.
+var deepgramDefaultVoices = map[string][3]string{
+	"aura-2": {"apollo", "thalia", "arcas"},
+	"aura":   {"orion", "asteria", "arcas"},
+}
+
+type deepgramRequest struct {
+	Text string `json:"text"`
+}
+
+// DeepgramProvider implements Provider using the Deepgram Aura TTS API.
+type DeepgramProvider struct {
+	voices     VoiceMap
+	apiKey     string
+	httpClient *http.Client
+	model      string
+
+	// done holds the audio of chunks of a split segment that succeeded
+	// before a later chunk failed with a retryable error, so the retry of
+	// the segment only requests the chunks still missing.
+	mu   sync.Mutex
+	done map[deepgramChunkKey][]byte
+}
+
+type deepgramChunkKey struct {
+	model, text string
+}
+
+func NewDeepgramProvider(voice1, voice2, voice3 string, cfg ProviderConfig) *DeepgramProvider {
+	model := deepgramDefaultModel
+	if cfg.Model != "" {
+		model = cfg.Model
+	}
+
+	defaults, ok := deepgramDefaultVoices[model]
+	if !ok {
+		defaults = deepgramDefaultVoices[deepgramDefaultModel]
+	}
+	v1, v2, v3 := defaults[0], defaults[1], defaults[2]
+	if voice1 != "" {
+		v1 = voice1
+	}
+	if voice2 != "" {
+		v2 = voice2
+	}
+	if voice3 != "" {
+		v3 = voice3
+	}
+
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("DEEPGRAM_API_KEY")
+	}
+
+	return &DeepgramProvider{
+		voices: VoiceMap{
+			Host1: Voice{ID: v1, Name: deepgramVoiceName(v1)},
+			Host2: Voice{ID: v2, Name: deepgramVoiceName(v2)},
+			Host3: Voice{ID: v3, Name: deepgramVoiceName(v3)},
+		},
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 60 * time.Second, Transport: ttsTransport(cfg, 0)},
+		model:      model,
+		done:       make(map[deepgramChunkKey][]byte),
+	}
+}
+
+func (p *DeepgramProvider) Name() string { return "deepgram" }
+
+func (p *DeepgramProvider) DefaultVoices() VoiceMap {
+	defaults, ok := deepgramDefaultVoices[p.model]
+	if !ok {
+		defaults = deepgramDefaultVoices[deepgramDefaultModel]
+	}
+	return VoiceMap{
+		Host1: Voice{ID: defaults[0], Name: deepgramVoiceName(defaults[0])},
+		Host2: Voice{ID: defaults[1], Name: deepgramVoiceName(defaults[1])},
+		Host3: Voice{ID: defaults[2], Name: deepgramVoiceName(defaults[2])},
+	}
+}
+
+// Synthesize requests each chunk of text in turn and concatenates the MP3
+// results; MP3 frames are self-contained, so the parts join cleanly. When a
+// chunk fails with a retryable error, the chunks before it are kept for the
+// retry.
+func (p *DeepgramProvider) Synthesize(ctx context.Context, text string, voice Voice) (AudioResult, error) {
+	chunks := splitSentences(text, deepgramMaxChars)
+	keys := make([]deepgramChunkKey, len(chunks))
+	model := deepgramModelName(p.model, voice.ID)
+	for i, chunk := range chunks {
+		keys[i] = deepgramChunkKey{model, chunk}
+	}
+	forget := func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for _, k := range keys {
+			delete(p.done, k)
+		}
+	}
+
+	var audio []byte
+	for i, chunk := range chunks {
+		p.mu.Lock()
+		data, ok := p.done[keys[i]]
+		p.mu.Unlock()
+		if !ok {
+			var err error
+			data, err = p.synthesizeChunk(ctx, chunk, voice)
+			if err != nil {
+				var re *RetryableError
+				if len(chunks) == 1 || !errors.As(err, &re) {
+					forget()
+				}
+				return AudioResult{}, err
+			}
+			if len(chunks) > 1 {
+				p.mu.Lock()
+				p.done[keys[i]] = data
+				p.mu.Unlock()
+			}
+		}
+		audio = append(audio, data...)
+	}
+	forget()
+	return AudioResult{Data: audio, Format: FormatMP3}, nil
+}
+
+func (p *DeepgramProvider) synthesizeChunk(ctx context.Context, text string, voice Voice) ([]byte, error) {
+	bodyBytes, err := json.Marshal(deepgramRequest{Text: text})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("model", deepgramModelName(p.model, voice.ID))
+	params.Set("encoding", "mp3")
+	params.Set("bit_rate", strconv.Itoa(deepgramBitRate))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, deepgramBaseURL+"?"+params.Encode(), bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Token "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusTooManyRequests ||
+		res.StatusCode >= http.StatusInternalServerError {
+		errBody, _ := io.ReadAll(res.Body)
+		var retryAfter time.Duration
+		if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && secs > 0 {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return nil, &RetryableError{
+			StatusCode: res.StatusCode,
+			Body:       string(errBody),
+			RetryAfter: retryAfter,
+		}
+	}
+
+	if res.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(res.Body)
+		return nil, statusError("deepgram", res.StatusCode, string(errBody),
+			fmt.Errorf("Deepgram API error (status %d): %s", res.StatusCode, string(errBody)))
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("Deepgram returned empty audio")
+	}
+	return data, nil
+}
+
+func (p *DeepgramProvider) Close() error { return nil }
+
+// deepgramModelName builds the API model name from the --tts-model and a
+// voice: "thalia" becomes "aura-2-thalia-en". A full model name
+// ("aura-2-draco-en") is used as is, so any catalog voice can be chosen.
+func deepgramModelName(model, voice string) string {
+	if strings.HasPrefix(voice, "aura-") {
+		return voice
+	}
+	return model + "-" + strings.ToLower(voice) + "-en"
+}
+
+// deepgramVoiceName returns a display name for a voice ID or full model
+// name: "thalia" and "aura-2-thalia-en" are both "Thalia".
+func deepgramVoiceName(id string) string {
+	name := strings.TrimSuffix(id, "-en")
+	name = strings.TrimPrefix(name, "aura-2-")
+	name = strings.TrimPrefix(name, "aura-")
+	if name == "" {
+		return id
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// splitSentences splits text into chunks of at most maxChars, breaking after
+// sentence-ending punctuation where possible, then at spaces. Text within
+// the limit is returned as one chunk.
+func splitSentences(text string, maxChars int) []string {
+	var chunks []string
+	for len(text) > maxChars {
+		cut := strings.LastIndexAny(text[:maxChars], ".!?")
+		if cut <= 0 {
+			cut = strings.LastIndex(text[:maxChars], " ")
+		}
+		if cut <= 0 {
+			// No break at all: cut at the limit, but not inside a
+			// multibyte character.
+			cut = maxChars - 1
+			for cut > 0 && !utf8.RuneStart(text[cut+1]) {
+				cut--
+			}
+		}
+		chunks = append(chunks, strings.TrimSpace(text[:cut+1]))
+		text = strings.TrimSpace(text[cut+1:])
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// deepgramModelsResponse is the API response from GET /v1/models.
+type deepgramModelsResponse struct {
+	TTS []struct {
+		CanonicalName string   `json:"canonical_name"`
+		Architecture  string   `json:"architecture"`
+		Languages     []string `json:"languages"`
+		Metadata      struct {
+			Accent string   `json:"accent"`
+			Age    string   `json:"age"`
+			Tags   []string `json:"tags"`
+			Sample string   `json:"sample"`
+		} `json:"metadata"`
+	} `json:"tts"`
+}
+
+// fetchDeepgramVoices lists the English Aura 2 voices from the models
+// endpoint.
+func fetchDeepgramVoices(apiKey string) ([]VoiceInfo, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	req, err := http.NewRequest(http.MethodGet, deepgramModelsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+apiKey)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch voices: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("Deepgram models API error (status %d): %s", res.StatusCode, string(body))
+	}
+
+	var resp deepgramModelsResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	// The listing has accents and tags but no gender; keep ours for the
+	// voices the fallback list knows.
+	known := make(map[string]VoiceInfo)
+	for _, v := range deepgramFallbackVoices() {
+		known[v.ID] = v
+	}
+	var voices []VoiceInfo
+	for _, m := range resp.TTS {
+		if m.Architecture != "aura-2" || !strings.HasSuffix(m.CanonicalName, "-en") {
+			continue
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(m.CanonicalName, "aura-2-"), "-en")
+		info, ok := known[id]
+		if !ok {
+			lang := "en"
+			if len(m.Languages) > 0 {
+				lang = m.Languages[0]
+			}
+			info = VoiceInfo{
+				ID:          id,
+				Name:        deepgramVoiceName(id),
+				Description: strings.TrimSpace(m.Metadata.Accent + " " + strings.Join(m.Metadata.Tags, ", ")),
+				Language:    lang,
+				Accent:      m.Metadata.Accent,
+				Tags:        m.Metadata.Tags,
+			}
+			info.Age = normalizeAge(m.Metadata.Age)
+		}
+		info.SampleURL = m.Metadata.Sample
+		voices = append(voices, info)
+	}
+	return voices, nil
+}
+
+func deepgramAvailableVoices() []VoiceInfo {
+	// Try live fetch if API key is available.
+	if apiKey := os.Getenv("DEEPGRAM_API_KEY"); apiKey != "" {
+		if voices, err := fetchDeepgramVoices(apiKey); err == nil && len(voices) > 0 {
+			return voices
+		}
+	}
+	return deepgramFallbackVoices()
+}
+
+// deepgramFallbackVoices is a selection of the English Aura 2 voices. The
+// IDs are short names; the provider expands them to model names.
+func deepgramFallbackVoices() []VoiceInfo {
+	return []VoiceInfo{
+		{ID: "apollo", Name: "Apollo", Gender: "male", Description: "Confident, casual American male", Language: "en-US", DefaultFor: "Voice 1"},
+		{ID: "thalia", Name: "Thalia", Gender: "female", Description: "Clear, energetic American female", Language: "en-US", DefaultFor: "Voice 2"},
+		{ID: "arcas", Name: "Arcas", Gender: "male", Description: "Natural, smooth American male", Language: "en-US", DefaultFor: "Voice 3"},
+		{ID: "andromeda", Name: "Andromeda", Gender: "female", Description: "Casual, expressive American female", Language: "en-US"},
+		{ID: "asteria", Name: "Asteria", Gender: "female", Description: "Clear, confident American female", Language: "en-US"},
+		{ID: "luna", Name: "Luna", Gender: "female", Description: "Friendly, natural American female", Language: "en-US"},
+		{ID: "orion", Name: "Orion", Gender: "male", Description: "Approachable, calm American male", Language: "en-US"},
+		{ID: "orpheus", Name: "Orpheus", Gender: "male", Description: "Professional, trustworthy American male", Language: "en-US"},
+		{ID: "zeus", Name: "Zeus", Gender: "male", Description: "Deep, trustworthy American male", Language: "en-US"},
+		{ID: "draco", Name: "Draco", Gender: "male", Description: "Warm, trustworthy British male", Language: "en-GB"},
+		{ID: "pandora", Name: "Pandora", Gender: "female", Description: "Smooth, calm British female", Language: "en-GB"},
+		{ID: "hyperion", Name: "Hyperion", Gender: "male", Description: "Caring, warm Australian male", Language: "en-AU"},
+		{ID: "theia", Name: "Theia", Gender: "female", Description: "Expressive, polite Australian female", Language: "en-AU"},
+	}
+}
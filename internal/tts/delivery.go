@@ -0,0 +1,44 @@
+package tts
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// DeliveryProvider is implemented by providers that can act on per-segment
+// delivery direction (script.Segment.Delivery) and inline audio tags such as
+// [laughs]. Other providers get the text with audio tags stripped.
+type DeliveryProvider interface {
+	Provider
+	SynthesizeDelivery(ctx context.Context, text, delivery string, voice Voice) (AudioResult, error)
+}
+
+// audioTagPattern matches script.AudioTags. ElevenLabs v3 performs them;
+// everyone else has them stripped so they're never read aloud.
+var audioTagPattern = regexp.MustCompile(`\s*\[(?i:` + strings.Join(script.AudioTags, "|") + `)\]\s*`)
+
+// StripAudioTags removes inline audio tags from text.
+func StripAudioTags(text string) string {
+	if !audioTagPattern.MatchString(text) {
+		return text
+	}
+	return strings.Join(strings.Fields(audioTagPattern.ReplaceAllString(text, " ")), " ")
+}
+
+// maxDeliveryLen bounds a delivery direction; anything longer is a sentence,
+// not a direction, and is ignored.
+const maxDeliveryLen = 32
+
+// NormalizeDelivery cleans a delivery direction from the script ("Whispering",
+// "[excited]") to a short lowercase phrase, or "" if it isn't usable.
+func NormalizeDelivery(delivery string) string {
+	d := strings.ToLower(strings.Trim(strings.TrimSpace(delivery), "[]"))
+	d = strings.Join(strings.Fields(d), " ")
+	if len(d) > maxDeliveryLen || strings.ContainsAny(d, "[]") {
+		return ""
+	}
+	return d
+}
@@ -8,8 +8,11 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
 )
 
 const (
@@ -45,6 +48,8 @@ type ElevenLabsProvider struct {
 	model      string
 	speed      float64
 	stability  float64
+
+	explicitStability bool // --tts-stability set; delivery styles don't override it
 }
 
 func NewElevenLabsProvider(voice1, voice2, voice3 string, cfg ProviderConfig) *ElevenLabsProvider {
@@ -86,10 +91,12 @@ func NewElevenLabsProvider(voice1, voice2, voice3 string, cfg ProviderConfig) *E
 			Host3: Voice{ID: v3, Name: "Burt Reynolds™"},
 		},
 		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: &http.Client{Timeout: 60 * time.Second, Transport: ttsTransport(cfg, 0)},
 		model:      model,
 		speed:      speed,
 		stability:  stability,
+
+		explicitStability: cfg.Stability != 0,
 	}
 }
 
@@ -104,16 +111,72 @@ func (p *ElevenLabsProvider) DefaultVoices() VoiceMap {
 }
 
 func (p *ElevenLabsProvider) Synthesize(ctx context.Context, text string, voice Voice) (AudioResult, error) {
+	return p.synthesize(ctx, text, p.voiceSettings(voice, ""), voice)
+}
+
+// SynthesizeDelivery implements DeliveryProvider. eleven_v3 performs audio
+// tags natively, so the delivery becomes a leading tag ("[whispers] ...").
+// Older models would read tags aloud; they get the tags stripped and the
+// delivery mapped onto voice_settings instead.
+func (p *ElevenLabsProvider) SynthesizeDelivery(ctx context.Context, text, delivery string, voice Voice) (AudioResult, error) {
+	delivery = NormalizeDelivery(delivery)
+	if p.model == "eleven_v3" {
+		if delivery != "" {
+			text = "[" + delivery + "] " + text
+		}
+		return p.synthesize(ctx, text, p.voiceSettings(voice, ""), voice)
+	}
+	return p.synthesize(ctx, StripAudioTags(text), p.voiceSettings(voice, delivery), voice)
+}
+
+// elevenLabsDeliveryStyles maps delivery directions to stability/style
+// overrides for models without audio tag support. Lower stability and higher
+// style give a more animated read.
+var elevenLabsDeliveryStyles = map[string]struct{ stability, style float64 }{
+	"whispering":   {0.8, 0.0},
+	"whispers":     {0.8, 0.0},
+	"calm":         {0.75, 0.1},
+	"serious":      {0.7, 0.2},
+	"thoughtful":   {0.65, 0.2},
+	"curious":      {0.45, 0.4},
+	"sarcastic":    {0.4, 0.5},
+	"playful":      {0.35, 0.5},
+	"laughing":     {0.3, 0.6},
+	"excited":      {0.3, 0.7},
+	"enthusiastic": {0.3, 0.7},
+}
+
+// voiceSettings returns the request's voice_settings: the voice's own
+// overrides over the provider's, adjusted for delivery when it names a known
+// style. An explicit stability (--tts-stability or per voice) always wins.
+func (p *ElevenLabsProvider) voiceSettings(voice Voice, delivery string) *elevenLabsVoiceParams {
+	vs := &elevenLabsVoiceParams{
+		Stability:       p.stability,
+		SimilarityBoost: 0.75,
+		Style:           0.0,
+		UseSpeakerBoost: p.model != "eleven_v3",
+		Speed:           p.speed,
+	}
+	if voice.Settings.Stability != nil {
+		vs.Stability = *voice.Settings.Stability
+	}
+	if voice.Settings.Speed != 0 {
+		vs.Speed = voice.Settings.Speed
+	}
+	if style, ok := elevenLabsDeliveryStyles[delivery]; ok {
+		if !p.explicitStability && voice.Settings.Stability == nil {
+			vs.Stability = style.stability
+		}
+		vs.Style = style.style
+	}
+	return vs
+}
+
+func (p *ElevenLabsProvider) synthesize(ctx context.Context, text string, settings *elevenLabsVoiceParams, voice Voice) (AudioResult, error) {
 	reqBody := elevenLabsRequest{
-		Text:    text,
-		ModelID: p.model,
-		VoiceSettings: &elevenLabsVoiceParams{
-			Stability:       p.stability,
-			SimilarityBoost: 0.75,
-			Style:           0.0,
-			UseSpeakerBoost: p.model != "eleven_v3",
-			Speed:           p.speed,
-		},
+		Text:          text,
+		ModelID:       p.model,
+		VoiceSettings: settings,
 	}
 
 	bodyBytes, err := json.Marshal(reqBody)
@@ -148,7 +211,8 @@ func (p *ElevenLabsProvider) Synthesize(ctx context.Context, text string, voice
 
 	if res.StatusCode != http.StatusOK {
 		errBody, _ := io.ReadAll(res.Body)
-		return AudioResult{}, fmt.Errorf("ElevenLabs API error (status %d): %s", res.StatusCode, string(errBody))
+		return AudioResult{}, statusError("elevenlabs", res.StatusCode, string(errBody),
+			fmt.Errorf("ElevenLabs API error (status %d): %s", res.StatusCode, string(errBody)))
 	}
 
 	data, err := io.ReadAll(res.Body)
@@ -172,13 +236,14 @@ type elevenLabsVoice struct {
 	Category    string            `json:"category"`
 	Description string            `json:"description"`
 	Labels      map[string]string `json:"labels"`
+	PreviewURL  string            `json:"preview_url"`
 }
 
 // fetchElevenLabsVoices calls the ElevenLabs API to get the user's voice library.
-func fetchElevenLabsVoices(apiKey string) ([]VoiceInfo, error) {
+func fetchElevenLabsVoices(ctx context.Context, apiKey string) ([]VoiceInfo, error) {
 	client := &http.Client{Timeout: 10 * time.Second}
 
-	req, err := http.NewRequest(http.MethodGet, elevenLabsVoicesURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, elevenLabsVoicesURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -203,13 +268,26 @@ func fetchElevenLabsVoices(apiKey string) ([]VoiceInfo, error) {
 	voices := make([]VoiceInfo, 0, len(resp.Voices))
 	for _, v := range resp.Voices {
 		info := VoiceInfo{
-			ID:     v.VoiceID,
-			Name:   v.Name,
-			Gender: v.Labels["gender"],
+			ID:        v.VoiceID,
+			Name:      v.Name,
+			Gender:    v.Labels["gender"],
+			Language:  LanguageMultilingual,
+			Accent:    capitalize(elevenLabsLabel(v.Labels["accent"])),
+			Age:       normalizeAge(v.Labels["age"]),
+			SampleURL: v.PreviewURL,
+		}
+		for _, key := range []string{"descriptive", "use_case"} {
+			if tag := elevenLabsLabel(v.Labels[key]); tag != "" {
+				info.Tags = append(info.Tags, tag)
+			}
 		}
 
-		// Build brief description from accent + description labels only.
+		// Build brief description from accent + description labels only,
+		// marking the account's own (cloned or designed) voices.
 		var parts []string
+		if v.Category != "" && v.Category != "premade" {
+			parts = append(parts, v.Category)
+		}
 		if accent := v.Labels["accent"]; accent != "" {
 			parts = append(parts, accent)
 		}
@@ -234,25 +312,100 @@ func fetchElevenLabsVoices(apiKey string) ([]VoiceInfo, error) {
 	return voices, nil
 }
 
+// VoiceNotFoundError reports voice IDs that aren't in the account's voice
+// library. Available lists the voices that are, for the error message.
+type VoiceNotFoundError struct {
+	Provider  string
+	VoiceIDs  []string
+	Available []VoiceInfo
+}
+
+func (e *VoiceNotFoundError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s voice %s not found in your account", e.Provider, strings.Join(quoteAll(e.VoiceIDs), ", "))
+	if len(e.Available) == 0 {
+		b.WriteString(" (the account has no voices)")
+		return b.String()
+	}
+	b.WriteString("; available voices:")
+	for _, v := range e.Available {
+		fmt.Fprintf(&b, "\n  %s  %s", v.ID, v.Name)
+		if v.Description != "" {
+			fmt.Fprintf(&b, " (%s)", v.Description)
+		}
+	}
+	return b.String()
+}
+
+func (e *VoiceNotFoundError) ErrorKind() errkind.Kind { return errkind.UserInput }
+
+func quoteAll(ss []string) []string {
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		out[i] = fmt.Sprintf("%q", s)
+	}
+	return out
+}
+
+// ValidateElevenLabsVoices checks voice IDs (premade, library, or cloned)
+// against the account's voice library, so a typo or a voice from another
+// account fails before any script is generated rather than mid-synthesis.
+// Returns a *VoiceNotFoundError listing the available voices when any ID is
+// missing; other errors mean the library couldn't be fetched (e.g. a key
+// without the voices_read permission) and callers may choose to continue.
+func ValidateElevenLabsVoices(ctx context.Context, apiKey string, voiceIDs ...string) error {
+	if len(voiceIDs) == 0 {
+		return nil
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv("ELEVENLABS_API_KEY")
+	}
+	voices, err := fetchElevenLabsVoices(ctx, apiKey)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(voices))
+	for _, v := range voices {
+		known[v.ID] = true
+	}
+	var missing []string
+	for _, id := range voiceIDs {
+		if !known[id] && !slices.Contains(missing, id) {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return &VoiceNotFoundError{Provider: "ElevenLabs", VoiceIDs: missing, Available: voices}
+	}
+	return nil
+}
+
+// elevenLabsLabel turns a label value ("narrative_story") into words.
+func elevenLabsLabel(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, "_", " "))
+}
+
 func elevenLabsAvailableVoices() []VoiceInfo {
 	// Try live fetch if API key is available.
 	if apiKey := os.Getenv("ELEVENLABS_API_KEY"); apiKey != "" {
-		if voices, err := fetchElevenLabsVoices(apiKey); err == nil {
+		if voices, err := fetchElevenLabsVoices(context.Background(), apiKey); err == nil {
 			return voices
 		}
 	}
 
-	// Fallback to hardcoded list.
+	// Fallback to hardcoded list. Every ElevenLabs voice speaks all the
+	// languages of the multilingual models (eleven_v3 by default); a voice's
+	// language label only names its native accent.
 	return []VoiceInfo{
-		{ID: "R1iO02imWa46t8ckcxFN", Name: "Chad", Gender: "male", Description: "en-american", DefaultFor: "Voice 1"},
-		{ID: "56bWURjYFHyYyVf490Dp", Name: "Emma", Gender: "female", Description: "Warm Australian female", DefaultFor: "Voice 2"},
-		{ID: "iWP0zWXsAkUmG0R4IMeO", Name: "Burt Reynolds™", Gender: "male", Description: "Masculine Iconic Storyteller", DefaultFor: "Voice 3"},
-		{ID: "4YYIPFl9wE5c4L2eu2Gb", Name: "Burt Reynolds™", Gender: "male", Description: "Deep, Smooth and clear"},
-		{ID: "UgBBYS2sOqTuMpoF3BR0", Name: "Mark", Gender: "male", Description: "Natural Conversations"},
-		{ID: "JBFqnCBsd6RMkjVDRZzb", Name: "George", Gender: "male", Description: "Warm British male"},
-		{ID: "EXAVITQu4vr4xnSDxMaL", Name: "Sarah", Gender: "female", Description: "Soft American female"},
-		{ID: "pNInz6obpgDQGcFmaJgB", Name: "Adam", Gender: "male", Description: "Deep American male"},
-		{ID: "onwK4e9ZLuTAKqWW03F9", Name: "Daniel", Gender: "male", Description: "Authoritative British male"},
-		{ID: "pFZP5JQG7iQjIQuC4Bku", Name: "Lily", Gender: "female", Description: "Warm British female"},
+		{ID: "R1iO02imWa46t8ckcxFN", Name: "Chad", Gender: "male", Description: "en-american", Language: LanguageMultilingual, DefaultFor: "Voice 1"},
+		{ID: "56bWURjYFHyYyVf490Dp", Name: "Emma", Gender: "female", Description: "Warm Australian female", Language: LanguageMultilingual, DefaultFor: "Voice 2"},
+		{ID: "iWP0zWXsAkUmG0R4IMeO", Name: "Burt Reynolds™", Gender: "male", Description: "Masculine Iconic Storyteller", Language: LanguageMultilingual, DefaultFor: "Voice 3"},
+		{ID: "4YYIPFl9wE5c4L2eu2Gb", Name: "Burt Reynolds™", Gender: "male", Description: "Deep, Smooth and clear", Language: LanguageMultilingual},
+		{ID: "UgBBYS2sOqTuMpoF3BR0", Name: "Mark", Gender: "male", Description: "Natural Conversations", Language: LanguageMultilingual},
+		{ID: "JBFqnCBsd6RMkjVDRZzb", Name: "George", Gender: "male", Description: "Warm British male", Language: LanguageMultilingual},
+		{ID: "EXAVITQu4vr4xnSDxMaL", Name: "Sarah", Gender: "female", Description: "Soft American female", Language: LanguageMultilingual},
+		{ID: "pNInz6obpgDQGcFmaJgB", Name: "Adam", Gender: "male", Description: "Deep American male", Language: LanguageMultilingual},
+		{ID: "onwK4e9ZLuTAKqWW03F9", Name: "Daniel", Gender: "male", Description: "Authoritative British male", Language: LanguageMultilingual},
+		{ID: "pFZP5JQG7iQjIQuC4Bku", Name: "Lily", Gender: "female", Description: "Warm British female", Language: LanguageMultilingual},
 	}
 }
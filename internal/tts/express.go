@@ -3,11 +3,9 @@ package tts
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"os"
 	"strconv"
@@ -28,9 +26,10 @@ const (
 type VertexExpressProvider struct {
 	voices          VoiceMap
 	model           string
-	apiKey          string
+	keys            *keyPool
 	httpClient      *http.Client
 	batchHTTPClient *http.Client
+	styleTone       string
 }
 
 func NewVertexExpressProvider(voice1, voice2, voice3 string, cfg ProviderConfig) (*VertexExpressProvider, error) {
@@ -52,12 +51,9 @@ func NewVertexExpressProvider(voice1, voice2, voice3 string, cfg ProviderConfig)
 		model = cfg.Model
 	}
 
-	apiKey := cfg.APIKey
-	if apiKey == "" {
-		apiKey = os.Getenv("VERTEX_AI_API_KEY")
-	}
-	if apiKey == "" {
-		return nil, fmt.Errorf("VERTEX_AI_API_KEY environment variable is required for vertex-express TTS provider (Google Cloud API key, not AI Studio key)")
+	keys := newKeyPool(cfg.APIKey, "VERTEX_AI_API_KEY")
+	if keys.size() == 0 {
+		return nil, fmt.Errorf("VERTEX_AI_API_KEY (or VERTEX_AI_API_KEY_1..N) environment variable is required for vertex-express TTS provider (Google Cloud API key, not AI Studio key)")
 	}
 
 	return &VertexExpressProvider{
@@ -66,32 +62,17 @@ func NewVertexExpressProvider(voice1, voice2, voice3 string, cfg ProviderConfig)
 			Host2: Voice{ID: v2, Name: v2},
 			Host3: Voice{ID: v3, Name: v3},
 		},
-		model:  model,
-		apiKey: apiKey,
+		model: model,
+		keys:  keys,
 		httpClient: &http.Client{
-			Timeout: 90 * time.Second,
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout: 10 * time.Second,
-				}).DialContext,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ResponseHeaderTimeout: 70 * time.Second,
-				IdleConnTimeout:       10 * time.Second,
-				DisableKeepAlives:     true,
-			},
+			Timeout:   90 * time.Second,
+			Transport: ttsTransport(cfg, 70*time.Second),
 		},
 		batchHTTPClient: &http.Client{
-			Timeout: 5 * time.Minute,
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout: 10 * time.Second,
-				}).DialContext,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ResponseHeaderTimeout: 4 * time.Minute,
-				IdleConnTimeout:       10 * time.Second,
-				DisableKeepAlives:     true,
-			},
+			Timeout:   5 * time.Minute,
+			Transport: ttsTransport(cfg, 4*time.Minute),
 		},
+		styleTone: cfg.StyleTone,
 	}, nil
 }
 
@@ -125,90 +106,74 @@ func (p *VertexExpressProvider) Synthesize(ctx context.Context, text string, voi
 		},
 	}
 
-	data, err := p.doRequest(ctx, req, p.httpClient)
-	if err != nil {
+	var buf bytes.Buffer
+	if _, err := p.doRequest(ctx, req, p.httpClient, &buf); err != nil {
 		return AudioResult{}, err
 	}
 
-	return AudioResult{Data: data, Format: FormatPCM}, nil
+	return AudioResult{Data: buf.Bytes(), Format: FormatPCM}, nil
 }
 
 // SynthesizeBatch sends the entire script as a multi-speaker dialogue.
+// Long scripts should go through SynthesizeBatchChunked.
 func (p *VertexExpressProvider) SynthesizeBatch(ctx context.Context, segments []script.Segment, voices VoiceMap) (AudioResult, error) {
-	var dialogue string
-	for _, seg := range segments {
-		dialogue += fmt.Sprintf("%s: %s\n", seg.Speaker, seg.Text)
+	var buf bytes.Buffer
+	format, err := p.StreamBatch(ctx, segments, voices, &buf)
+	if err != nil {
+		return AudioResult{}, err
 	}
+	return AudioResult{Data: buf.Bytes(), Format: format}, nil
+}
 
-	seen := map[string]bool{}
-	var speakerConfigs []geminiSpeakerVoiceConfig
-	for _, seg := range segments {
-		if seen[seg.Speaker] {
-			continue
-		}
-		seen[seg.Speaker] = true
-		voice := VoiceForSpeaker(seg.Speaker, voices)
-		speakerConfigs = append(speakerConfigs, geminiSpeakerVoiceConfig{
-			Speaker: seg.Speaker,
-			VoiceConfig: geminiVoiceConfig{
-				PrebuiltVoiceConfig: geminiPrebuiltVoice{VoiceName: voice.ID},
-			},
-		})
-	}
+// StreamBatch is SynthesizeBatch writing the audio to w as it is decoded.
+func (p *VertexExpressProvider) StreamBatch(ctx context.Context, segments []script.Segment, voices VoiceMap, w io.Writer) (AudioFormat, error) {
+	req, speakers, chars := batchRequest(segments, voices, "user", p.styleTone)
 
 	fmt.Fprintf(os.Stderr, "[vertex-express-batch] Starting batch TTS: segments=%d speakers=%d chars=%d model=%s\n",
-		len(segments), len(speakerConfigs), len(dialogue), p.model)
+		len(segments), speakers, chars, p.model)
 	start := time.Now()
 
-	req := geminiRequest{
-		Contents: []geminiContent{
-			{Role: "user", Parts: []geminiPart{{Text: dialogue}}},
-		},
-		GenerationConfig: geminiGenConfig{
-			ResponseModalities: []string{"AUDIO"},
-			SpeechConfig: geminiSpeechConfig{
-				MultiSpeakerVoiceConfig: &geminiMultiSpeakerConfig{
-					SpeakerVoiceConfigs: speakerConfigs,
-				},
-			},
-		},
-	}
-
-	data, err := p.doRequest(ctx, req, p.batchHTTPClient)
+	n, err := p.doRequest(ctx, req, p.batchHTTPClient, w)
 	elapsed := time.Since(start).Round(time.Millisecond)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[vertex-express-batch] FAILED after %s: %v\n", elapsed, err)
-		return AudioResult{}, err
+		return "", err
 	}
 
-	fmt.Fprintf(os.Stderr, "[vertex-express-batch] SUCCESS in %s: audio_bytes=%d\n", elapsed, len(data))
-	return AudioResult{Data: data, Format: FormatPCM}, nil
+	fmt.Fprintf(os.Stderr, "[vertex-express-batch] SUCCESS in %s: audio_bytes=%d\n", elapsed, n)
+	return FormatPCM, nil
 }
 
-func (p *VertexExpressProvider) doRequest(ctx context.Context, reqBody geminiRequest, client *http.Client) ([]byte, error) {
+func (p *VertexExpressProvider) doRequest(ctx context.Context, reqBody geminiRequest, client *http.Client, w io.Writer) (int64, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("marshal vertex-express request: %w", err)
+		return 0, fmt.Errorf("marshal vertex-express request: %w", err)
 	}
 
-	url := p.endpoint() + "?key=" + p.apiKey
+	keyIdx, apiKey := p.keys.pick()
 	reqSize := len(bodyBytes)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint(), bytes.NewReader(bodyBytes))
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return 0, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", apiKey)
 
-	fmt.Fprintf(os.Stderr, "[vertex-express] POST %s request_bytes=%d timeout=%s\n", p.model, reqSize, client.Timeout)
+	keyNote := ""
+	if p.keys.size() > 1 {
+		keyNote = " " + p.keys.label(keyIdx)
+	}
+	fmt.Fprintf(os.Stderr, "[vertex-express] POST %s%s request_bytes=%d timeout=%s\n", p.model, keyNote, reqSize, client.Timeout)
 	start := time.Now()
 
 	res, err := client.Do(req)
 	elapsed := time.Since(start).Round(time.Millisecond)
 	if err != nil {
+		err = transportCause(err)
 		fmt.Fprintf(os.Stderr, "[vertex-express] HTTP error after %s: %v\n", elapsed, err)
-		return nil, &RetryableError{StatusCode: 0, Body: fmt.Sprintf("network error after %s: %v", elapsed, err)}
+		return 0, &RetryableError{StatusCode: 0, Body: fmt.Sprintf("network error after %s: %v", elapsed, err)}
 	}
 	defer res.Body.Close()
 
@@ -225,8 +190,15 @@ func (p *VertexExpressProvider) doRequest(ctx context.Context, reqBody geminiReq
 			bodyLower := strings.ToLower(bodyStr)
 			if strings.Contains(bodyLower, "resource_exhausted") &&
 				(strings.Contains(bodyLower, "per day") || strings.Contains(bodyLower, "per_day") || strings.Contains(bodyLower, "rpd")) {
-				fmt.Fprintf(os.Stderr, "[vertex-express] Daily quota exhausted (RPD limit reached)\n")
-				return nil, fmt.Errorf("Vertex Express TTS daily quota exhausted (RPD limit). Try again tomorrow or switch to --tts gemini-vertex or --tts elevenlabs")
+				fmt.Fprintf(os.Stderr, "[vertex-express] Daily quota exhausted (RPD limit reached)%s\n", keyNote)
+				if p.keys.rateLimited(keyIdx, keyQuotaCooldown) {
+					fmt.Fprintf(os.Stderr, "[vertex-express] Rotating to another API key\n")
+					return 0, &RetryableError{StatusCode: res.StatusCode, Body: bodyStr}
+				}
+				return 0, &QuotaExhaustedError{
+					Provider: p.Name(),
+					Message:  "Vertex Express TTS daily quota exhausted (RPD limit). Try again tomorrow, switch to --tts gemini-vertex or --tts elevenlabs, or set --tts-fallback",
+				}
 			}
 		}
 
@@ -238,7 +210,14 @@ func (p *VertexExpressProvider) doRequest(ctx context.Context, reqBody geminiReq
 			}
 		}
 
-		return nil, &RetryableError{
+		// Another key can take the retry right away; this one sits out
+		// its Retry-After (or a default cooldown).
+		if res.StatusCode == http.StatusTooManyRequests && p.keys.rateLimited(keyIdx, retryAfter) {
+			fmt.Fprintf(os.Stderr, "[vertex-express] Rotating to another API key\n")
+			retryAfter = 0
+		}
+
+		return 0, &RetryableError{
 			StatusCode: res.StatusCode,
 			Body:       bodyStr,
 			RetryAfter: retryAfter,
@@ -249,35 +228,17 @@ func (p *VertexExpressProvider) doRequest(ctx context.Context, reqBody geminiReq
 		errBody, _ := io.ReadAll(res.Body)
 		bodyStr := string(errBody)
 		fmt.Fprintf(os.Stderr, "[vertex-express] API error %d: %s\n", res.StatusCode, bodyStr[:min(200, len(bodyStr))])
-		return nil, fmt.Errorf("Vertex Express API error (status %d): %s", res.StatusCode, bodyStr)
-	}
-
-	respBody, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read vertex-express response: %w", err)
-	}
-
-	fmt.Fprintf(os.Stderr, "[vertex-express] Response body read: %d bytes in %s\n", len(respBody), time.Since(start).Round(time.Millisecond))
-
-	var resp geminiResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("parse vertex-express response: %w", err)
-	}
-
-	if len(resp.Candidates) == 0 ||
-		len(resp.Candidates[0].Content.Parts) == 0 ||
-		resp.Candidates[0].Content.Parts[0].InlineData == nil {
-		return nil, &RetryableError{StatusCode: 200, Body: "vertex-express response contained no audio data"}
+		return 0, statusError("vertex-express", res.StatusCode, bodyStr,
+			fmt.Errorf("Vertex Express API error (status %d): %s", res.StatusCode, bodyStr))
 	}
 
-	audioB64 := resp.Candidates[0].Content.Parts[0].InlineData.Data
-	audioBytes, err := base64.StdEncoding.DecodeString(audioB64)
+	n, err := streamInlineAudio(res.Body, w)
 	if err != nil {
-		return nil, fmt.Errorf("decode vertex-express audio base64: %w", err)
+		return n, err
 	}
 
-	fmt.Fprintf(os.Stderr, "[vertex-express] Audio decoded: %d bytes (base64: %d)\n", len(audioBytes), len(audioB64))
-	return audioBytes, nil
+	fmt.Fprintf(os.Stderr, "[vertex-express] Audio decoded: %d bytes in %s\n", n, time.Since(start).Round(time.Millisecond))
+	return n, nil
 }
 
 func (p *VertexExpressProvider) Close() error { return nil }
@@ -3,11 +3,9 @@ package tts
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"os"
 	"strconv"
@@ -68,35 +66,14 @@ type geminiPrebuiltVoice struct {
 	VoiceName string `json:"voiceName"`
 }
 
-// geminiResponse is the generateContent response structure.
-type geminiResponse struct {
-	Candidates []geminiCandidate `json:"candidates"`
-}
-
-type geminiCandidate struct {
-	Content geminiRespContent `json:"content"`
-}
-
-type geminiRespContent struct {
-	Parts []geminiRespPart `json:"parts"`
-}
-
-type geminiRespPart struct {
-	InlineData *geminiInlineData `json:"inlineData,omitempty"`
-}
-
-type geminiInlineData struct {
-	MimeType string `json:"mimeType"`
-	Data     string `json:"data"` // base64-encoded PCM
-}
-
 // GeminiProvider implements both Provider and BatchProvider.
 type GeminiProvider struct {
 	voices          VoiceMap
-	apiKey          string
+	keys            *keyPool
 	httpClient      *http.Client
 	batchHttpClient *http.Client // longer timeouts for batch synthesis
 	model           string
+	styleTone       string
 }
 
 func NewGeminiProvider(voice1, voice2, voice3 string, cfg ProviderConfig) *GeminiProvider {
@@ -118,10 +95,7 @@ func NewGeminiProvider(voice1, voice2, voice3 string, cfg ProviderConfig) *Gemin
 		model = cfg.Model
 	}
 
-	apiKey := cfg.APIKey
-	if apiKey == "" {
-		apiKey = os.Getenv("GEMINI_API_KEY")
-	}
+	keys := newKeyPool(cfg.APIKey, "GEMINI_API_KEY")
 
 	return &GeminiProvider{
 		voices: VoiceMap{
@@ -129,35 +103,20 @@ func NewGeminiProvider(voice1, voice2, voice3 string, cfg ProviderConfig) *Gemin
 			Host2: Voice{ID: v2, Name: v2},
 			Host3: Voice{ID: v3, Name: v3},
 		},
-		apiKey: apiKey,
+		keys: keys,
 		httpClient: &http.Client{
-			Timeout: 90 * time.Second,
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout: 10 * time.Second,
-				}).DialContext,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ResponseHeaderTimeout: 70 * time.Second,
-				IdleConnTimeout:       10 * time.Second,
-				DisableKeepAlives:     true,
-			},
+			Timeout:   90 * time.Second,
+			Transport: ttsTransport(cfg, 70*time.Second),
 		},
 		// Batch synthesis: 30+ segments take longer to process server-side.
 		// Gemini TTS RPM limit is 10, so batch (1 request) is preferred over
 		// per-segment (30 requests) to avoid rate limiting.
 		batchHttpClient: &http.Client{
-			Timeout: 5 * time.Minute,
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout: 10 * time.Second,
-				}).DialContext,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ResponseHeaderTimeout: 4 * time.Minute,
-				IdleConnTimeout:       10 * time.Second,
-				DisableKeepAlives:     true,
-			},
+			Timeout:   5 * time.Minute,
+			Transport: ttsTransport(cfg, 4*time.Minute),
 		},
-		model: model,
+		model:     model,
+		styleTone: cfg.StyleTone,
 	}
 }
 
@@ -192,24 +151,52 @@ func (p *GeminiProvider) Synthesize(ctx context.Context, text string, voice Voic
 		},
 	}
 
-	data, err := p.doRequest(ctx, req, p.httpClient)
-	if err != nil {
+	var buf bytes.Buffer
+	if _, err := p.doRequest(ctx, req, p.httpClient, &buf); err != nil {
 		return AudioResult{}, err
 	}
 
-	return AudioResult{Data: data, Format: FormatPCM}, nil
+	return AudioResult{Data: buf.Bytes(), Format: FormatPCM}, nil
 }
 
 // SynthesizeBatch sends the entire script as a multi-speaker dialogue.
 // Gemini returns a single PCM audio stream for the whole conversation.
+// Long scripts should go through SynthesizeBatchChunked.
 func (p *GeminiProvider) SynthesizeBatch(ctx context.Context, segments []script.Segment, voices VoiceMap) (AudioResult, error) {
-	// Build the dialogue text with speaker labels (format: "Speaker: text\n")
-	var dialogue string
-	for _, seg := range segments {
-		dialogue += fmt.Sprintf("%s: %s\n", seg.Speaker, seg.Text)
+	var buf bytes.Buffer
+	format, err := p.StreamBatch(ctx, segments, voices, &buf)
+	if err != nil {
+		return AudioResult{}, err
+	}
+	return AudioResult{Data: buf.Bytes(), Format: format}, nil
+}
+
+// StreamBatch is SynthesizeBatch writing the audio to w as it is decoded.
+func (p *GeminiProvider) StreamBatch(ctx context.Context, segments []script.Segment, voices VoiceMap, w io.Writer) (AudioFormat, error) {
+	req, speakers, chars := batchRequest(segments, voices, "", p.styleTone)
+
+	fmt.Fprintf(os.Stderr, "[gemini-batch] Starting batch TTS: segments=%d speakers=%d chars=%d model=%s\n",
+		len(segments), speakers, chars, p.model)
+	start := time.Now()
+
+	n, err := p.doRequest(ctx, req, p.batchHttpClient, w)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[gemini-batch] FAILED after %s: %v\n", elapsed, err)
+		return "", err
 	}
 
-	// Dynamically build SpeakerVoiceConfigs from the speakers present in segments
+	fmt.Fprintf(os.Stderr, "[gemini-batch] SUCCESS in %s: audio_bytes=%d\n", elapsed, n)
+	return FormatPCM, nil
+}
+
+// batchRequest builds a generateContent request for a dialogue, shared by
+// the Gemini-family batch providers. Lines are labeled "Speaker: text" and
+// each speaker present gets a voice. A non-empty tone opens the prompt with a
+// style instruction (see batchStylePreamble). A chunk with a single speaker uses a
+// plain voice config instead, since multi-speaker mode requires two.
+// Returns the request, the number of speakers, and the prompt length.
+func batchRequest(segments []script.Segment, voices VoiceMap, role, tone string) (geminiRequest, int, int) {
 	seen := map[string]bool{}
 	var speakerConfigs []geminiSpeakerVoiceConfig
 	for _, seg := range segments {
@@ -226,59 +213,65 @@ func (p *GeminiProvider) SynthesizeBatch(ctx context.Context, segments []script.
 		})
 	}
 
-	fmt.Fprintf(os.Stderr, "[gemini-batch] Starting batch TTS: segments=%d speakers=%d chars=%d model=%s\n",
-		len(segments), len(speakerConfigs), len(dialogue), p.model)
-	start := time.Now()
+	var dialogue strings.Builder
+	dialogue.WriteString(batchStylePreamble(tone))
+	var speech geminiSpeechConfig
+	if len(speakerConfigs) == 1 {
+		for _, seg := range segments {
+			dialogue.WriteString(seg.Text + "\n")
+		}
+		speech.VoiceConfig = &speakerConfigs[0].VoiceConfig
+	} else {
+		for _, seg := range segments {
+			fmt.Fprintf(&dialogue, "%s: %s\n", seg.Speaker, seg.Text)
+		}
+		speech.MultiSpeakerVoiceConfig = &geminiMultiSpeakerConfig{
+			SpeakerVoiceConfigs: speakerConfigs,
+		}
+	}
 
 	req := geminiRequest{
 		Contents: []geminiContent{
-			{Parts: []geminiPart{{Text: dialogue}}},
+			{Role: role, Parts: []geminiPart{{Text: dialogue.String()}}},
 		},
 		GenerationConfig: geminiGenConfig{
 			ResponseModalities: []string{"AUDIO"},
-			SpeechConfig: geminiSpeechConfig{
-				MultiSpeakerVoiceConfig: &geminiMultiSpeakerConfig{
-					SpeakerVoiceConfigs: speakerConfigs,
-				},
-			},
+			SpeechConfig:       speech,
 		},
 	}
-
-	data, err := p.doRequest(ctx, req, p.batchHttpClient)
-	elapsed := time.Since(start).Round(time.Millisecond)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "[gemini-batch] FAILED after %s: %v\n", elapsed, err)
-		return AudioResult{}, err
-	}
-
-	fmt.Fprintf(os.Stderr, "[gemini-batch] SUCCESS in %s: audio_bytes=%d\n", elapsed, len(data))
-	return AudioResult{Data: data, Format: FormatPCM}, nil
+	return req, len(speakerConfigs), dialogue.Len()
 }
 
-func (p *GeminiProvider) doRequest(ctx context.Context, reqBody geminiRequest, client *http.Client) ([]byte, error) {
+func (p *GeminiProvider) doRequest(ctx context.Context, reqBody geminiRequest, client *http.Client, w io.Writer) (int64, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("marshal Gemini request: %w", err)
+		return 0, fmt.Errorf("marshal Gemini request: %w", err)
 	}
 
-	url := p.endpoint() + "?key=" + p.apiKey
+	keyIdx, apiKey := p.keys.pick()
 	reqSize := len(bodyBytes)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint(), bytes.NewReader(bodyBytes))
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return 0, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", apiKey)
 
-	fmt.Fprintf(os.Stderr, "[gemini] POST %s request_bytes=%d timeout=%s\n", p.model, reqSize, client.Timeout)
+	keyNote := ""
+	if p.keys.size() > 1 {
+		keyNote = " " + p.keys.label(keyIdx)
+	}
+	fmt.Fprintf(os.Stderr, "[gemini] POST %s%s request_bytes=%d timeout=%s\n", p.model, keyNote, reqSize, client.Timeout)
 	start := time.Now()
 
 	res, err := client.Do(req)
 	elapsed := time.Since(start).Round(time.Millisecond)
 	if err != nil {
+		err = transportCause(err)
 		fmt.Fprintf(os.Stderr, "[gemini] HTTP error after %s: %v\n", elapsed, err)
-		return nil, &RetryableError{StatusCode: 0, Body: fmt.Sprintf("network error after %s: %v", elapsed, err)}
+		return 0, &RetryableError{StatusCode: 0, Body: fmt.Sprintf("network error after %s: %v", elapsed, err)}
 	}
 	defer res.Body.Close()
 
@@ -295,8 +288,15 @@ func (p *GeminiProvider) doRequest(ctx context.Context, reqBody geminiRequest, c
 			bodyLower := strings.ToLower(bodyStr)
 			if strings.Contains(bodyLower, "resource_exhausted") &&
 				(strings.Contains(bodyLower, "per day") || strings.Contains(bodyLower, "per_day") || strings.Contains(bodyLower, "rpd")) {
-				fmt.Fprintf(os.Stderr, "[gemini] Daily quota exhausted (RPD limit reached)\n")
-				return nil, fmt.Errorf("Gemini TTS daily quota exhausted (RPD limit). Try again tomorrow or switch to --tts elevenlabs or --tts gemini-vertex")
+				fmt.Fprintf(os.Stderr, "[gemini] Daily quota exhausted (RPD limit reached)%s\n", keyNote)
+				if p.keys.rateLimited(keyIdx, keyQuotaCooldown) {
+					fmt.Fprintf(os.Stderr, "[gemini] Rotating to another API key\n")
+					return 0, &RetryableError{StatusCode: res.StatusCode, Body: bodyStr}
+				}
+				return 0, &QuotaExhaustedError{
+					Provider: p.Name(),
+					Message:  "Gemini TTS daily quota exhausted (RPD limit). Try again tomorrow, switch to --tts elevenlabs or --tts gemini-vertex, or set --tts-fallback",
+				}
 			}
 		}
 
@@ -309,7 +309,14 @@ func (p *GeminiProvider) doRequest(ctx context.Context, reqBody geminiRequest, c
 			}
 		}
 
-		return nil, &RetryableError{
+		// Another key can take the retry right away; this one sits out
+		// its Retry-After (or a default cooldown).
+		if res.StatusCode == http.StatusTooManyRequests && p.keys.rateLimited(keyIdx, retryAfter) {
+			fmt.Fprintf(os.Stderr, "[gemini] Rotating to another API key\n")
+			retryAfter = 0
+		}
+
+		return 0, &RetryableError{
 			StatusCode: res.StatusCode,
 			Body:       bodyStr,
 			RetryAfter: retryAfter,
@@ -319,70 +326,52 @@ func (p *GeminiProvider) doRequest(ctx context.Context, reqBody geminiRequest, c
 	if res.StatusCode != http.StatusOK {
 		errBody, _ := io.ReadAll(res.Body)
 		fmt.Fprintf(os.Stderr, "[gemini] API error %d: %s\n", res.StatusCode, string(errBody)[:min(200, len(errBody))])
-		return nil, fmt.Errorf("Gemini API error (status %d): %s", res.StatusCode, string(errBody))
-	}
-
-	respBody, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read Gemini response: %w", err)
-	}
-
-	fmt.Fprintf(os.Stderr, "[gemini] Response body read: %d bytes in %s\n", len(respBody), time.Since(start).Round(time.Millisecond))
-
-	var resp geminiResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("parse Gemini response: %w", err)
-	}
-
-	if len(resp.Candidates) == 0 ||
-		len(resp.Candidates[0].Content.Parts) == 0 ||
-		resp.Candidates[0].Content.Parts[0].InlineData == nil {
-		return nil, &RetryableError{StatusCode: 200, Body: "Gemini response contained no audio data"}
+		return 0, statusError("gemini", res.StatusCode, string(errBody),
+			fmt.Errorf("Gemini API error (status %d): %s", res.StatusCode, string(errBody)))
 	}
 
-	audioB64 := resp.Candidates[0].Content.Parts[0].InlineData.Data
-	audioBytes, err := base64.StdEncoding.DecodeString(audioB64)
+	n, err := streamInlineAudio(res.Body, w)
 	if err != nil {
-		return nil, fmt.Errorf("decode Gemini audio base64: %w", err)
+		return n, err
 	}
 
-	fmt.Fprintf(os.Stderr, "[gemini] Audio decoded: %d bytes (base64: %d)\n", len(audioBytes), len(audioB64))
-	return audioBytes, nil
+	fmt.Fprintf(os.Stderr, "[gemini] Audio decoded: %d bytes in %s\n", n, time.Since(start).Round(time.Millisecond))
+	return n, nil
 }
 
 func (p *GeminiProvider) Close() error { return nil }
 
 func geminiAvailableVoices() []VoiceInfo {
 	return []VoiceInfo{
-		{ID: "Charon", Name: "Charon", Gender: "male", Description: "Informative", DefaultFor: "Voice 1"},
-		{ID: "Leda", Name: "Leda", Gender: "female", Description: "Youthful", DefaultFor: "Voice 2"},
-		{ID: "Fenrir", Name: "Fenrir", Gender: "male", Description: "Excitable", DefaultFor: "Voice 3"},
-		{ID: "Achernar", Name: "Achernar", Gender: "female", Description: "Soft"},
-		{ID: "Achird", Name: "Achird", Gender: "male", Description: "Friendly"},
-		{ID: "Algenib", Name: "Algenib", Gender: "male", Description: "Gravelly"},
-		{ID: "Algieba", Name: "Algieba", Gender: "male", Description: "Smooth"},
-		{ID: "Alnilam", Name: "Alnilam", Gender: "male", Description: "Firm"},
-		{ID: "Aoede", Name: "Aoede", Gender: "female", Description: "Breezy"},
-		{ID: "Autonoe", Name: "Autonoe", Gender: "female", Description: "Bright"},
-		{ID: "Callirrhoe", Name: "Callirrhoe", Gender: "female", Description: "Easy-going"},
-		{ID: "Despina", Name: "Despina", Gender: "female", Description: "Smooth"},
-		{ID: "Enceladus", Name: "Enceladus", Gender: "male", Description: "Breathy"},
-		{ID: "Erinome", Name: "Erinome", Gender: "female", Description: "Clear"},
-		{ID: "Gacrux", Name: "Gacrux", Gender: "male", Description: "Mature"},
-		{ID: "Iapetus", Name: "Iapetus", Gender: "male", Description: "Clear"},
-		{ID: "Kore", Name: "Kore", Gender: "female", Description: "Firm"},
-		{ID: "Laomedeia", Name: "Laomedeia", Gender: "female", Description: "Upbeat"},
-		{ID: "Orus", Name: "Orus", Gender: "male", Description: "Firm"},
-		{ID: "Puck", Name: "Puck", Gender: "male", Description: "Upbeat"},
-		{ID: "Pulcherrima", Name: "Pulcherrima", Gender: "female", Description: "Forward"},
-		{ID: "Rasalgethi", Name: "Rasalgethi", Gender: "male", Description: "Informative"},
-		{ID: "Sadachbia", Name: "Sadachbia", Gender: "female", Description: "Lively"},
-		{ID: "Sadaltager", Name: "Sadaltager", Gender: "male", Description: "Knowledgeable"},
-		{ID: "Schedar", Name: "Schedar", Gender: "female", Description: "Even"},
-		{ID: "Sulafat", Name: "Sulafat", Gender: "female", Description: "Warm"},
-		{ID: "Umbriel", Name: "Umbriel", Gender: "male", Description: "Easy-going"},
-		{ID: "Vindemiatrix", Name: "Vindemiatrix", Gender: "female", Description: "Gentle"},
-		{ID: "Zephyr", Name: "Zephyr", Gender: "female", Description: "Bright"},
-		{ID: "Zubenelgenubi", Name: "Zubenelgenubi", Gender: "male", Description: "Casual"},
+		{ID: "Charon", Name: "Charon", Gender: "male", Description: "Informative", Language: LanguageMultilingual, DefaultFor: "Voice 1"},
+		{ID: "Leda", Name: "Leda", Gender: "female", Description: "Youthful", Language: LanguageMultilingual, DefaultFor: "Voice 2"},
+		{ID: "Fenrir", Name: "Fenrir", Gender: "male", Description: "Excitable", Language: LanguageMultilingual, DefaultFor: "Voice 3"},
+		{ID: "Achernar", Name: "Achernar", Gender: "female", Description: "Soft", Language: LanguageMultilingual},
+		{ID: "Achird", Name: "Achird", Gender: "male", Description: "Friendly", Language: LanguageMultilingual},
+		{ID: "Algenib", Name: "Algenib", Gender: "male", Description: "Gravelly", Language: LanguageMultilingual},
+		{ID: "Algieba", Name: "Algieba", Gender: "male", Description: "Smooth", Language: LanguageMultilingual},
+		{ID: "Alnilam", Name: "Alnilam", Gender: "male", Description: "Firm", Language: LanguageMultilingual},
+		{ID: "Aoede", Name: "Aoede", Gender: "female", Description: "Breezy", Language: LanguageMultilingual},
+		{ID: "Autonoe", Name: "Autonoe", Gender: "female", Description: "Bright", Language: LanguageMultilingual},
+		{ID: "Callirrhoe", Name: "Callirrhoe", Gender: "female", Description: "Easy-going", Language: LanguageMultilingual},
+		{ID: "Despina", Name: "Despina", Gender: "female", Description: "Smooth", Language: LanguageMultilingual},
+		{ID: "Enceladus", Name: "Enceladus", Gender: "male", Description: "Breathy", Language: LanguageMultilingual},
+		{ID: "Erinome", Name: "Erinome", Gender: "female", Description: "Clear", Language: LanguageMultilingual},
+		{ID: "Gacrux", Name: "Gacrux", Gender: "male", Description: "Mature", Language: LanguageMultilingual},
+		{ID: "Iapetus", Name: "Iapetus", Gender: "male", Description: "Clear", Language: LanguageMultilingual},
+		{ID: "Kore", Name: "Kore", Gender: "female", Description: "Firm", Language: LanguageMultilingual},
+		{ID: "Laomedeia", Name: "Laomedeia", Gender: "female", Description: "Upbeat", Language: LanguageMultilingual},
+		{ID: "Orus", Name: "Orus", Gender: "male", Description: "Firm", Language: LanguageMultilingual},
+		{ID: "Puck", Name: "Puck", Gender: "male", Description: "Upbeat", Language: LanguageMultilingual},
+		{ID: "Pulcherrima", Name: "Pulcherrima", Gender: "female", Description: "Forward", Language: LanguageMultilingual},
+		{ID: "Rasalgethi", Name: "Rasalgethi", Gender: "male", Description: "Informative", Language: LanguageMultilingual},
+		{ID: "Sadachbia", Name: "Sadachbia", Gender: "female", Description: "Lively", Language: LanguageMultilingual},
+		{ID: "Sadaltager", Name: "Sadaltager", Gender: "male", Description: "Knowledgeable", Language: LanguageMultilingual},
+		{ID: "Schedar", Name: "Schedar", Gender: "female", Description: "Even", Language: LanguageMultilingual},
+		{ID: "Sulafat", Name: "Sulafat", Gender: "female", Description: "Warm", Language: LanguageMultilingual},
+		{ID: "Umbriel", Name: "Umbriel", Gender: "male", Description: "Easy-going", Language: LanguageMultilingual},
+		{ID: "Vindemiatrix", Name: "Vindemiatrix", Gender: "female", Description: "Gentle", Language: LanguageMultilingual},
+		{ID: "Zephyr", Name: "Zephyr", Gender: "female", Description: "Bright", Language: LanguageMultilingual},
+		{ID: "Zubenelgenubi", Name: "Zubenelgenubi", Gender: "male", Description: "Casual", Language: LanguageMultilingual},
 	}
 }
@@ -3,6 +3,7 @@ package tts
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	texttospeech "cloud.google.com/go/texttospeech/apiv1"
 	texttospeechpb "cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
@@ -36,9 +37,9 @@ func NewGoogleProvider(voice1, voice2, voice3 string, cfg ProviderConfig) (*Goog
 		v3 = voice3
 	}
 
-	client, err := texttospeech.NewClient(context.Background())
+	client, err := googleClient(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("create Google TTS client: %w", err)
+		return nil, err
 	}
 
 	return &GoogleProvider{
@@ -72,7 +73,7 @@ func (p *GoogleProvider) Synthesize(ctx context.Context, text string, voice Voic
 			LanguageCode: "en-US",
 			Name:         voice.ID,
 		},
-		AudioConfig: p.audioConfig(),
+		AudioConfig: p.audioConfig(voice),
 	}
 
 	resp, err := p.client.SynthesizeSpeech(ctx, req)
@@ -83,7 +84,36 @@ func (p *GoogleProvider) Synthesize(ctx context.Context, text string, voice Voic
 	return AudioResult{Data: resp.AudioContent, Format: FormatMP3}, nil
 }
 
-func (p *GoogleProvider) audioConfig() *texttospeechpb.AudioConfig {
+// SynthesizeSSML implements SSMLProvider. Chirp 3 HD voices don't accept
+// SSML, so they get the markup input instead, which keeps the pauses.
+func (p *GoogleProvider) SynthesizeSSML(ctx context.Context, ssml string, voice Voice) (AudioResult, error) {
+	input := &texttospeechpb.SynthesisInput{
+		InputSource: &texttospeechpb.SynthesisInput_Ssml{Ssml: ssml},
+	}
+	if strings.Contains(voice.ID, "Chirp3-HD") {
+		input.InputSource = &texttospeechpb.SynthesisInput_Markup{Markup: ssmlToMarkup(ssml)}
+	}
+
+	req := &texttospeechpb.SynthesizeSpeechRequest{
+		Input: input,
+		Voice: &texttospeechpb.VoiceSelectionParams{
+			LanguageCode: "en-US",
+			Name:         voice.ID,
+		},
+		AudioConfig: p.audioConfig(voice),
+	}
+
+	resp, err := p.client.SynthesizeSpeech(ctx, req)
+	if err != nil {
+		return AudioResult{}, fmt.Errorf("Google TTS synthesize SSML: %w", err)
+	}
+
+	return AudioResult{Data: resp.AudioContent, Format: FormatMP3}, nil
+}
+
+// audioConfig applies the provider's speed and pitch, overridden by the
+// voice's own settings.
+func (p *GoogleProvider) audioConfig(voice Voice) *texttospeechpb.AudioConfig {
 	cfg := &texttospeechpb.AudioConfig{
 		AudioEncoding: texttospeechpb.AudioEncoding_MP3,
 	}
@@ -93,20 +123,27 @@ func (p *GoogleProvider) audioConfig() *texttospeechpb.AudioConfig {
 	if p.pitch != 0 {
 		cfg.Pitch = p.pitch
 	}
+	if voice.Settings.Speed != 0 {
+		cfg.SpeakingRate = voice.Settings.Speed
+	}
+	if voice.Settings.Pitch != nil {
+		cfg.Pitch = *voice.Settings.Pitch
+	}
 	return cfg
 }
 
-func (p *GoogleProvider) Close() error { return p.client.Close() }
+// Close is a no-op: the client is shared by the process (see googleClient).
+func (p *GoogleProvider) Close() error { return nil }
 
 func googleAvailableVoices() []VoiceInfo {
 	return []VoiceInfo{
-		{ID: "en-US-Chirp3-HD-Charon", Name: "Charon", Gender: "male", Description: "Informative, clear male narrator", DefaultFor: "Voice 1"},
-		{ID: "en-US-Chirp3-HD-Leda", Name: "Leda", Gender: "female", Description: "Youthful, bright female voice", DefaultFor: "Voice 2"},
-		{ID: "en-US-Chirp3-HD-Fenrir", Name: "Fenrir", Gender: "male", Description: "Deep, resonant male voice", DefaultFor: "Voice 3"},
-		{ID: "en-US-Chirp3-HD-Kore", Name: "Kore", Gender: "female", Description: "Firm, confident female voice"},
-		{ID: "en-US-Chirp3-HD-Aoede", Name: "Aoede", Gender: "female", Description: "Bright, expressive female voice"},
-		{ID: "en-US-Chirp3-HD-Puck", Name: "Puck", Gender: "male", Description: "Upbeat, energetic male voice"},
-		{ID: "en-US-Chirp3-HD-Orus", Name: "Orus", Gender: "male", Description: "Warm, steady male narrator"},
-		{ID: "en-US-Chirp3-HD-Zephyr", Name: "Zephyr", Gender: "female", Description: "Breezy, relaxed female voice"},
+		{ID: "en-US-Chirp3-HD-Charon", Name: "Charon", Gender: "male", Description: "Informative, clear male narrator", Language: "en-US", DefaultFor: "Voice 1"},
+		{ID: "en-US-Chirp3-HD-Leda", Name: "Leda", Gender: "female", Description: "Youthful, bright female voice", Language: "en-US", DefaultFor: "Voice 2"},
+		{ID: "en-US-Chirp3-HD-Fenrir", Name: "Fenrir", Gender: "male", Description: "Deep, resonant male voice", Language: "en-US", DefaultFor: "Voice 3"},
+		{ID: "en-US-Chirp3-HD-Kore", Name: "Kore", Gender: "female", Description: "Firm, confident female voice", Language: "en-US"},
+		{ID: "en-US-Chirp3-HD-Aoede", Name: "Aoede", Gender: "female", Description: "Bright, expressive female voice", Language: "en-US"},
+		{ID: "en-US-Chirp3-HD-Puck", Name: "Puck", Gender: "male", Description: "Upbeat, energetic male voice", Language: "en-US"},
+		{ID: "en-US-Chirp3-HD-Orus", Name: "Orus", Gender: "male", Description: "Warm, steady male narrator", Language: "en-US"},
+		{ID: "en-US-Chirp3-HD-Zephyr", Name: "Zephyr", Gender: "female", Description: "Breezy, relaxed female voice", Language: "en-US"},
 	}
 }
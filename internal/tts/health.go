@@ -0,0 +1,323 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
+	"github.com/aws/aws-sdk-go-v2/service/polly"
+	"github.com/aws/aws-sdk-go-v2/service/polly/types"
+)
+
+const elevenLabsSubscriptionURL = "https://api.elevenlabs.io/v1/user/subscription"
+
+// ProviderNames lists every TTS provider, in the order diagnostics report them.
+var ProviderNames = []string{"gemini", "vertex-express", "gemini-vertex", "elevenlabs", "google", "polly", "cartesia", "hume", "deepgram"}
+
+// Health is the result of a provider health check.
+type Health struct {
+	Provider   string
+	Configured bool          // credentials were found (false = check skipped)
+	Latency    time.Duration // of the check's API calls
+	Detail     string        // model, key count, quota, ...
+	Err        error
+}
+
+// CheckHealth verifies that a provider's credentials work, using the
+// cheapest call its API offers: a model lookup, token count, voice list, or
+// account query. No audio is synthesized, so checks cost nothing. A
+// provider whose credentials aren't set at all is reported as not
+// configured rather than failed.
+func CheckHealth(ctx context.Context, name string, cfg ProviderConfig) Health {
+	h := Health{Provider: name, Configured: true}
+	start := time.Now()
+	switch name {
+	case "gemini":
+		h.Detail, h.Err = checkGemini(ctx, cfg, &h.Configured)
+	case "vertex-express":
+		h.Detail, h.Err = checkVertexExpress(ctx, cfg, &h.Configured)
+	case "gemini-vertex":
+		h.Detail, h.Err = checkVertex(ctx, cfg, &h.Configured)
+	case "elevenlabs":
+		h.Detail, h.Err = checkElevenLabs(ctx, cfg, &h.Configured)
+	case "cartesia":
+		h.Detail, h.Err = checkCartesia(ctx, cfg, &h.Configured)
+	case "hume":
+		h.Detail, h.Err = checkHume(ctx, cfg, &h.Configured)
+	case "deepgram":
+		h.Detail, h.Err = checkDeepgram(ctx, cfg, &h.Configured)
+	case "google":
+		h.Detail, h.Err = checkGoogle(ctx, &h.Configured)
+	case "polly":
+		h.Detail, h.Err = checkPolly(ctx, &h.Configured)
+	default:
+		h.Err = fmt.Errorf("unknown TTS provider %q", name)
+	}
+	h.Latency = time.Since(start)
+	return h
+}
+
+// checkGemini looks up the TTS model with every configured key, so a dead
+// key in a rotation pool is caught too.
+func checkGemini(ctx context.Context, cfg ProviderConfig, configured *bool) (string, error) {
+	p := NewGeminiProvider("", "", "", cfg)
+	if p.keys.size() == 0 {
+		*configured = false
+		return "GEMINI_API_KEY not set", nil
+	}
+	var errs []error
+	for i, key := range p.keys.keys {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, geminiEndpointBase+p.model, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("x-goog-api-key", key)
+		if _, err := healthDo(req); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.keys.label(i), err))
+		}
+	}
+	return fmt.Sprintf("model %s, %d key(s)", p.model, p.keys.size()), errors.Join(errs...)
+}
+
+// checkVertexExpress counts tokens for a one-word prompt with every key;
+// countTokens is free.
+func checkVertexExpress(ctx context.Context, cfg ProviderConfig, configured *bool) (string, error) {
+	p, err := NewVertexExpressProvider("", "", "", cfg)
+	if err != nil {
+		*configured = false
+		return "VERTEX_AI_API_KEY not set", nil
+	}
+	var errs []error
+	for i, key := range p.keys.keys {
+		req, err := countTokensRequest(ctx, vertexExpressEndpointBase+p.model+":countTokens")
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("x-goog-api-key", key)
+		if _, err := healthDo(req); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.keys.label(i), err))
+		}
+	}
+	return fmt.Sprintf("model %s, %d key(s)", p.model, p.keys.size()), errors.Join(errs...)
+}
+
+// checkVertex gets an ADC access token and counts tokens against the
+// project's regional endpoint, which also proves the Vertex AI API is
+// enabled and the credentials may call it.
+func checkVertex(ctx context.Context, cfg ProviderConfig, configured *bool) (string, error) {
+	p, err := NewVertexProvider("", "", "", cfg)
+	if err != nil {
+		*configured = false
+		return "GCP_PROJECT not set", nil
+	}
+	detail := fmt.Sprintf("project %s, region %s, model %s", p.project, p.region, p.model)
+	token, err := vertexAccessToken(ctx)
+	if err != nil {
+		return detail, fmt.Errorf("application default credentials: %w (run: gcloud auth application-default login)", err)
+	}
+	req, err := countTokensRequest(ctx, strings.TrimSuffix(p.endpoint(), ":generateContent")+":countTokens")
+	if err != nil {
+		return detail, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	_, err = healthDo(req)
+	return detail, err
+}
+
+// checkElevenLabs reads the account's subscription, which also reports the
+// character quota left this billing period.
+func checkElevenLabs(ctx context.Context, cfg ProviderConfig, configured *bool) (string, error) {
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("ELEVENLABS_API_KEY")
+	}
+	if apiKey == "" {
+		*configured = false
+		return "ELEVENLABS_API_KEY not set", nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, elevenLabsSubscriptionURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("xi-api-key", apiKey)
+	body, err := healthDo(req)
+	if err != nil {
+		return "", err
+	}
+	var sub struct {
+		Tier           string `json:"tier"`
+		CharacterCount int    `json:"character_count"`
+		CharacterLimit int    `json:"character_limit"`
+	}
+	if json.Unmarshal(body, &sub) != nil || sub.CharacterLimit == 0 {
+		return "key valid", nil
+	}
+	return fmt.Sprintf("%s tier, %d of %d characters left this period",
+		sub.Tier, max(sub.CharacterLimit-sub.CharacterCount, 0), sub.CharacterLimit), nil
+}
+
+// checkCartesia lists one voice.
+func checkCartesia(ctx context.Context, cfg ProviderConfig, configured *bool) (string, error) {
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("CARTESIA_API_KEY")
+	}
+	if apiKey == "" {
+		*configured = false
+		return "CARTESIA_API_KEY not set", nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cartesiaVoicesURL+"?limit=1", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-API-Key", apiKey)
+	req.Header.Set("Cartesia-Version", cartesiaAPIVersion)
+	_, err = healthDo(req)
+	return "key valid", err
+}
+
+// checkHume lists one library voice.
+func checkHume(ctx context.Context, cfg ProviderConfig, configured *bool) (string, error) {
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("HUME_API_KEY")
+	}
+	if apiKey == "" {
+		*configured = false
+		return "HUME_API_KEY not set", nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, humeVoicesURL+"?provider=HUME_AI&page_size=1", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Hume-Api-Key", apiKey)
+	_, err = healthDo(req)
+	return "key valid", err
+}
+
+// checkDeepgram lists the key's projects, which any valid key can read.
+func checkDeepgram(ctx context.Context, cfg ProviderConfig, configured *bool) (string, error) {
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("DEEPGRAM_API_KEY")
+	}
+	if apiKey == "" {
+		*configured = false
+		return "DEEPGRAM_API_KEY not set", nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, deepgramProjectsURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Token "+apiKey)
+	_, err = healthDo(req)
+	return "key valid", err
+}
+
+// checkGoogle lists the en-US voices through the shared client.
+func checkGoogle(ctx context.Context, configured *bool) (string, error) {
+	if !hasGoogleADC() {
+		*configured = false
+		return "no application default credentials", nil
+	}
+	client, err := googleClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.ListVoices(ctx, &texttospeechpb.ListVoicesRequest{LanguageCode: "en-US"})
+	if err != nil {
+		return "", fmt.Errorf("list voices: %w", err)
+	}
+	return fmt.Sprintf("%d en-US voices", len(resp.Voices)), nil
+}
+
+// checkPolly resolves AWS credentials and lists the generative voices.
+func checkPolly(ctx context.Context, configured *bool) (string, error) {
+	if !hasAWSConfig() {
+		*configured = false
+		return "no AWS credentials or profile", nil
+	}
+	cfg, err := awsConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	if _, err := cfg.Credentials.Retrieve(ctx); err != nil {
+		return "", fmt.Errorf("AWS credentials: %w", err)
+	}
+	resp, err := polly.NewFromConfig(cfg).DescribeVoices(ctx, &polly.DescribeVoicesInput{
+		Engine:       types.EngineGenerative,
+		LanguageCode: types.LanguageCodeEnUs,
+	})
+	if err != nil {
+		return "", fmt.Errorf("describe voices: %w", err)
+	}
+	return fmt.Sprintf("region %s, %d generative en-US voices", cfg.Region, len(resp.Voices)), nil
+}
+
+// hasGoogleADC reports whether Application Default Credentials are set up:
+// a key file, gcloud's ADC file, or a GCE/Cloud Run metadata server (assumed
+// when GCE_METADATA_HOST or K_SERVICE is set).
+func hasGoogleADC() bool {
+	if os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") != "" || os.Getenv("GCE_METADATA_HOST") != "" || os.Getenv("K_SERVICE") != "" {
+		return true
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, "gcloud", "application_default_credentials.json"))
+	return err == nil
+}
+
+// hasAWSConfig reports whether the AWS default chain has something to go
+// on: credential env vars, a profile, ~/.aws, or a Lambda/ECS role.
+func hasAWSConfig() bool {
+	for _, v := range []string{"AWS_ACCESS_KEY_ID", "AWS_PROFILE", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_LAMBDA_FUNCTION_NAME"} {
+		if os.Getenv(v) != "" {
+			return true
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(home, ".aws"))
+	return err == nil
+}
+
+// countTokensRequest builds a countTokens call for a one-word prompt.
+func countTokensRequest(ctx context.Context, endpoint string) (*http.Request, error) {
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hello"}]}]}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// healthDo sends a health check request and returns the body of a 2xx
+// response. Other statuses become errors carrying the start of the body.
+func healthDo(req *http.Request) ([]byte, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, transportCause(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode/100 != 2 {
+		msg := strings.TrimSpace(string(body))
+		return nil, fmt.Errorf("status %d: %s", res.StatusCode, msg[:min(200, len(msg))])
+	}
+	return body, nil
+}
@@ -0,0 +1,289 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+)
+
+const (
+	humeDefaultVoice1 = "Colton Rivers"
+	humeDefaultVoice2 = "Ava Song"
+	humeDefaultVoice3 = "Vince Douglas"
+
+	// humeBaseURL returns the generated audio as a file rather than JSON
+	// with base64 inside.
+	humeBaseURL      = "https://api.hume.ai/v0/tts/file"
+	humeVoicesURL    = "https://api.hume.ai/v0/tts/voices"
+	humeDefaultModel = "octave-1"
+)
+
+// humeModelVersions maps --tts-model values to the API's version field.
+var humeModelVersions = map[string]string{
+	"octave-1": "1",
+	"octave-2": "2",
+}
+
+// humeVoiceIDPattern matches voice UUIDs; anything else is a voice name.
+var humeVoiceIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type humeRequest struct {
+	Utterances     []humeUtterance `json:"utterances"`
+	Format         humeFormat      `json:"format"`
+	NumGenerations int             `json:"num_generations"`
+	Version        string          `json:"version,omitempty"`
+}
+
+type humeUtterance struct {
+	Text        string    `json:"text"`
+	Voice       humeVoice `json:"voice"`
+	Description string    `json:"description,omitempty"` // acting instructions
+}
+
+type humeVoice struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Provider string `json:"provider,omitempty"` // HUME_AI (library) or CUSTOM_VOICE
+}
+
+type humeFormat struct {
+	Type string `json:"type"`
+}
+
+// HumeProvider implements Provider using the Hume AI Octave TTS API.
+// Octave takes natural-language acting instructions per utterance, so it
+// implements DeliveryProvider with the script's delivery directions.
+type HumeProvider struct {
+	voices     VoiceMap
+	apiKey     string
+	httpClient *http.Client
+	model      string
+}
+
+func NewHumeProvider(voice1, voice2, voice3 string, cfg ProviderConfig) *HumeProvider {
+	v1 := humeDefaultVoice1
+	v2 := humeDefaultVoice2
+	v3 := humeDefaultVoice3
+	if voice1 != "" {
+		v1 = voice1
+	}
+	if voice2 != "" {
+		v2 = voice2
+	}
+	if voice3 != "" {
+		v3 = voice3
+	}
+
+	model := humeDefaultModel
+	if cfg.Model != "" {
+		model = cfg.Model
+	}
+
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("HUME_API_KEY")
+	}
+
+	return &HumeProvider{
+		voices: VoiceMap{
+			Host1: Voice{ID: v1, Name: "Colton"},
+			Host2: Voice{ID: v2, Name: "Ava"},
+			Host3: Voice{ID: v3, Name: "Vince"},
+		},
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 90 * time.Second, Transport: ttsTransport(cfg, 0)},
+		model:      model,
+	}
+}
+
+func (p *HumeProvider) Name() string { return "hume" }
+
+func (p *HumeProvider) DefaultVoices() VoiceMap {
+	return VoiceMap{
+		Host1: Voice{ID: humeDefaultVoice1, Name: "Colton"},
+		Host2: Voice{ID: humeDefaultVoice2, Name: "Ava"},
+		Host3: Voice{ID: humeDefaultVoice3, Name: "Vince"},
+	}
+}
+
+func (p *HumeProvider) Synthesize(ctx context.Context, text string, voice Voice) (AudioResult, error) {
+	return p.synthesize(ctx, text, "", voice)
+}
+
+// SynthesizeDelivery implements DeliveryProvider. The delivery direction
+// becomes the utterance's acting instructions, expanded when it names a
+// known style. Octave doesn't perform inline audio tags, so they're
+// stripped.
+func (p *HumeProvider) SynthesizeDelivery(ctx context.Context, text, delivery string, voice Voice) (AudioResult, error) {
+	delivery = NormalizeDelivery(delivery)
+	if desc, ok := humeDeliveryDescriptions[delivery]; ok {
+		delivery = desc
+	}
+	return p.synthesize(ctx, StripAudioTags(text), delivery, voice)
+}
+
+// humeDeliveryDescriptions expands the script's common delivery directions
+// into fuller acting instructions. Octave understands free text, so other
+// directions are passed through as they are.
+var humeDeliveryDescriptions = map[string]string{
+	"whispering":   "whispering, hushed and intimate",
+	"whispers":     "whispering, hushed and intimate",
+	"calm":         "calm and measured, unhurried",
+	"serious":      "serious and grave, deliberate pacing",
+	"thoughtful":   "thoughtful and reflective, pausing to consider",
+	"curious":      "curious and intrigued, rising inflection",
+	"sarcastic":    "dry and sarcastic, deadpan",
+	"playful":      "playful and teasing, light",
+	"laughing":     "laughing while speaking, amused",
+	"excited":      "excited and energetic, fast-paced",
+	"enthusiastic": "enthusiastic and warm, upbeat",
+}
+
+func (p *HumeProvider) synthesize(ctx context.Context, text, description string, voice Voice) (AudioResult, error) {
+	reqBody := humeRequest{
+		Utterances: []humeUtterance{{
+			Text:        text,
+			Voice:       humeVoiceSpec(voice.ID),
+			Description: description,
+		}},
+		Format:         humeFormat{Type: "mp3"},
+		NumGenerations: 1,
+		Version:        humeModelVersions[p.model],
+	}
+
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return AudioResult{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, humeBaseURL, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return AudioResult{}, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("X-Hume-Api-Key", p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return AudioResult{}, fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusTooManyRequests ||
+		res.StatusCode >= http.StatusInternalServerError {
+		errBody, _ := io.ReadAll(res.Body)
+		return AudioResult{}, &RetryableError{
+			StatusCode: res.StatusCode,
+			Body:       string(errBody),
+		}
+	}
+
+	if res.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(res.Body)
+		return AudioResult{}, statusError("hume", res.StatusCode, string(errBody),
+			fmt.Errorf("Hume API error (status %d): %s", res.StatusCode, string(errBody)))
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return AudioResult{}, fmt.Errorf("read response: %w", err)
+	}
+	if len(data) == 0 {
+		return AudioResult{}, fmt.Errorf("Hume returned empty audio")
+	}
+
+	return AudioResult{Data: data, Format: FormatMP3}, nil
+}
+
+// humeVoiceSpec selects a voice by ID when given a UUID (library or custom
+// voices), otherwise by name from Hume's voice library.
+func humeVoiceSpec(id string) humeVoice {
+	if humeVoiceIDPattern.MatchString(id) {
+		return humeVoice{ID: id}
+	}
+	return humeVoice{Name: id, Provider: "HUME_AI"}
+}
+
+func (p *HumeProvider) Close() error { return nil }
+
+// humeVoicesResponse is the API response from GET /v0/tts/voices.
+type humeVoicesResponse struct {
+	VoicesPage []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"voices_page"`
+}
+
+// fetchHumeVoices lists Hume's voice library. Library voices are addressed
+// by name, which is what the catalog uses as the ID.
+func fetchHumeVoices(apiKey string) ([]VoiceInfo, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	req, err := http.NewRequest(http.MethodGet, humeVoicesURL+"?provider=HUME_AI&page_size=100", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("X-Hume-Api-Key", apiKey)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch voices: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("Hume voices API error (status %d): %s", res.StatusCode, string(body))
+	}
+
+	var resp humeVoicesResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	// The library listing has no gender or description; keep ours for the
+	// voices the fallback list knows.
+	known := make(map[string]VoiceInfo)
+	for _, v := range humeFallbackVoices() {
+		known[v.ID] = v
+	}
+	voices := make([]VoiceInfo, 0, len(resp.VoicesPage))
+	for _, v := range resp.VoicesPage {
+		info, ok := known[v.Name]
+		if !ok {
+			info = VoiceInfo{ID: v.Name, Name: v.Name, Language: "en"}
+		}
+		voices = append(voices, info)
+	}
+	return voices, nil
+}
+
+func humeAvailableVoices() []VoiceInfo {
+	// Try live fetch if API key is available.
+	if apiKey := os.Getenv("HUME_API_KEY"); apiKey != "" {
+		if voices, err := fetchHumeVoices(apiKey); err == nil && len(voices) > 0 {
+			return voices
+		}
+	}
+	return humeFallbackVoices()
+}
+
+func humeFallbackVoices() []VoiceInfo {
+	return []VoiceInfo{
+		{ID: "Colton Rivers", Name: "Colton", Gender: "male", Description: "Warm, easygoing American male", Language: "en-US", DefaultFor: "Voice 1"},
+		{ID: "Ava Song", Name: "Ava", Gender: "female", Description: "Bright, expressive female narrator", Language: "en-US", DefaultFor: "Voice 2"},
+		{ID: "Vince Douglas", Name: "Vince", Gender: "male", Description: "Deep, dramatic male storyteller", Language: "en-US", DefaultFor: "Voice 3"},
+		{ID: "Kora", Name: "Kora", Gender: "female", Description: "Calm, clear female voice", Language: "en-US"},
+		{ID: "Dacher", Name: "Dacher", Gender: "male", Description: "Thoughtful, conversational male", Language: "en-US"},
+		{ID: "Ito", Name: "Ito", Gender: "male", Description: "Gentle, soft-spoken male", Language: "en-US"},
+		{ID: "Literature Professor", Name: "Literature Professor", Gender: "male", Description: "Erudite British lecturer", Language: "en-GB"},
+		{ID: "Sitcom Girl", Name: "Sitcom Girl", Gender: "female", Description: "Bubbly, comedic female", Language: "en-US"},
+	}
+}
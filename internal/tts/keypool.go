@@ -0,0 +1,121 @@
+package tts
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// keyRateLimitCooldown is how long a key that returned 429 is skipped
+	// when no Retry-After was given.
+	keyRateLimitCooldown = time.Minute
+	// keyQuotaCooldown is how long a key whose daily quota is exhausted is
+	// skipped. Quotas reset at midnight Pacific; an hour avoids hammering it
+	// while still picking it up again the same day.
+	keyQuotaCooldown = time.Hour
+	// maxPooledKeys bounds the NAME_1..NAME_N scan.
+	maxPooledKeys = 50
+)
+
+// EnvKeys returns the API keys configured for envVar: envVar itself plus
+// envVar_1, envVar_2, ... up to the first unset index, without duplicates.
+func EnvKeys(envVar string) []string {
+	var keys []string
+	seen := map[string]bool{}
+	add := func(k string) {
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	add(os.Getenv(envVar))
+	for i := 1; i <= maxPooledKeys; i++ {
+		k := os.Getenv(envVar + "_" + strconv.Itoa(i))
+		if k == "" {
+			break
+		}
+		add(k)
+	}
+	return keys
+}
+
+// keyPool round-robins requests across several API keys for one provider
+// and skips keys that were recently rate limited, so a set of AI Studio or
+// Vertex Express keys shares the per-key RPM/RPD limits. A pool with one
+// key behaves exactly like a plain key.
+type keyPool struct {
+	mu       sync.Mutex
+	keys     []string
+	next     int
+	coolDown []time.Time // per key: skip until this time
+}
+
+// newKeyPool builds a pool from an explicit (BYOK) key, which is used
+// alone, or from the envVar family (see EnvKeys).
+func newKeyPool(explicit, envVar string) *keyPool {
+	keys := []string{explicit}
+	if explicit == "" {
+		keys = EnvKeys(envVar)
+	}
+	return &keyPool{keys: keys, coolDown: make([]time.Time, len(keys))}
+}
+
+// size returns the number of keys in the pool.
+func (p *keyPool) size() int { return len(p.keys) }
+
+// pick returns the next key not cooling down, and its index for reporting
+// back to rateLimited. When every key is cooling down it returns the one
+// that recovers first. An empty pool returns (-1, "").
+func (p *keyPool) pick() (int, string) {
+	if len(p.keys) == 0 {
+		return -1, ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	best := -1
+	for i := range p.keys {
+		idx := (p.next + i) % len(p.keys)
+		if !now.Before(p.coolDown[idx]) {
+			best = idx
+			break
+		}
+		if best < 0 || p.coolDown[idx].Before(p.coolDown[best]) {
+			best = idx
+		}
+	}
+	p.next = (best + 1) % len(p.keys)
+	return best, p.keys[best]
+}
+
+// rateLimited takes key idx out of rotation for d (or the default cooldown
+// when d is 0). It reports whether another key is available right now, in
+// which case the caller can retry without waiting.
+func (p *keyPool) rateLimited(idx int, d time.Duration) bool {
+	if idx < 0 {
+		return false
+	}
+	if d <= 0 {
+		d = keyRateLimitCooldown
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.coolDown[idx] = now.Add(d)
+	for i := range p.keys {
+		if i != idx && !now.Before(p.coolDown[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// label identifies key idx in logs without revealing it.
+func (p *keyPool) label(idx int) string {
+	return fmt.Sprintf("key %d/%d", idx+1, len(p.keys))
+}
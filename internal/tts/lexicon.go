@@ -0,0 +1,230 @@
+package tts
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lexicon is a user-supplied pronunciation dictionary (--lexicon). The file
+// maps terms to how they should be spoken, either as a plain respelling or
+// with an IPA transcription:
+//
+//	kubectl: cube control
+//	etcd:
+//	  say: et see dee
+//	  ipa: ˈɛt siː diː
+//
+// Providers that accept SSML get <phoneme> (when ipa is set) or <sub>;
+// everyone else gets the respelling substituted into the text. Terms that
+// are all lowercase match case-insensitively; terms with capitals ("SQL",
+// "IT") match exactly so they don't rewrite ordinary words.
+//
+// All methods are safe on a nil *Lexicon and leave text unchanged.
+type Lexicon struct {
+	entries []LexiconEntry
+	pattern *regexp.Regexp // one capture group per entry, in entries order
+}
+
+// LexiconEntry is one term in a Lexicon.
+type LexiconEntry struct {
+	Term string
+	Say  string // respelling; used by non-SSML providers
+	IPA  string // optional IPA transcription for SSML providers
+}
+
+// LoadLexicon reads a YAML pronunciation lexicon.
+func LoadLexicon(path string) (*Lexicon, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read lexicon: %w", err)
+	}
+
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse lexicon %s: %w", path, err)
+	}
+
+	entries := make([]LexiconEntry, 0, len(raw))
+	for term, node := range raw {
+		e := LexiconEntry{Term: strings.TrimSpace(term)}
+		switch node.Kind {
+		case yaml.ScalarNode:
+			e.Say = node.Value
+		case yaml.MappingNode:
+			var v struct {
+				Say string `yaml:"say"`
+				IPA string `yaml:"ipa"`
+			}
+			if err := node.Decode(&v); err != nil {
+				return nil, fmt.Errorf("lexicon %s: term %q: %w", path, term, err)
+			}
+			e.Say, e.IPA = v.Say, v.IPA
+		default:
+			return nil, fmt.Errorf("lexicon %s: term %q: expected a string or a say/ipa mapping", path, term)
+		}
+		e.Say, e.IPA = strings.TrimSpace(e.Say), strings.TrimSpace(e.IPA)
+		if e.Term == "" || (e.Say == "" && e.IPA == "") {
+			return nil, fmt.Errorf("lexicon %s: term %q needs a respelling or an ipa value", path, term)
+		}
+		entries = append(entries, e)
+	}
+	return NewLexicon(entries), nil
+}
+
+// NewLexicon builds a lexicon from entries. Longer terms take precedence
+// over shorter ones they contain ("kubectl apply" before "kubectl").
+func NewLexicon(entries []LexiconEntry) *Lexicon {
+	if len(entries) == 0 {
+		return nil
+	}
+	sorted := append([]LexiconEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if len(sorted[i].Term) != len(sorted[j].Term) {
+			return len(sorted[i].Term) > len(sorted[j].Term)
+		}
+		return sorted[i].Term < sorted[j].Term
+	})
+
+	alts := make([]string, len(sorted))
+	for i, e := range sorted {
+		alts[i] = "(" + termPattern(e.Term) + ")"
+	}
+	return &Lexicon{
+		entries: sorted,
+		pattern: regexp.MustCompile(strings.Join(alts, "|")),
+	}
+}
+
+// termPattern matches term as a whole word. Word boundaries are only added
+// on edges that are word characters, so terms like "C++" still match.
+func termPattern(term string) string {
+	p := regexp.QuoteMeta(term)
+	if strings.ToLower(term) == term {
+		p = "(?i:" + p + ")"
+	}
+	if isWordRune(firstRune(term)) {
+		p = `\b` + p
+	}
+	if isWordRune(lastRune(term)) {
+		p += `\b`
+	}
+	return p
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+func lastRune(s string) rune {
+	r := []rune(s)
+	if len(r) == 0 {
+		return 0
+	}
+	return r[len(r)-1]
+}
+
+// Len returns the number of terms.
+func (l *Lexicon) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.entries)
+}
+
+// Matches reports whether text contains any lexicon term.
+func (l *Lexicon) Matches(text string) bool {
+	return l != nil && l.pattern.MatchString(text)
+}
+
+// replace rewrites every term in text with repl(entry, matched); the text
+// between matches goes through plain.
+func (l *Lexicon) replace(text string, plain func(string) string, repl func(LexiconEntry, string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range l.pattern.FindAllStringSubmatchIndex(text, -1) {
+		for g := 1; g <= len(l.entries); g++ {
+			if m[2*g] < 0 {
+				continue
+			}
+			b.WriteString(plain(text[last:m[0]]))
+			b.WriteString(repl(l.entries[g-1], text[m[0]:m[1]]))
+			break
+		}
+		last = m[1]
+	}
+	b.WriteString(plain(text[last:]))
+	return b.String()
+}
+
+// Respell substitutes each term's respelling. Terms with only an IPA
+// transcription are left as written.
+func (l *Lexicon) Respell(text string) string {
+	if !l.Matches(text) {
+		return text
+	}
+	return l.replace(text, identity, func(e LexiconEntry, matched string) string {
+		if e.Say == "" {
+			return matched
+		}
+		return e.Say
+	})
+}
+
+// TextToSSML converts plain text to an SSML document with lexicon terms
+// marked up.
+func (l *Lexicon) TextToSSML(text string) string {
+	if l == nil {
+		return "<speak>" + html.EscapeString(text) + "</speak>"
+	}
+	return "<speak>" + l.markup(text) + "</speak>"
+}
+
+// ApplySSML marks up lexicon terms in an existing SSML document, touching
+// only the text between tags.
+func (l *Lexicon) ApplySSML(ssml string) string {
+	if l == nil {
+		return ssml
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range ssmlTag.FindAllStringIndex(ssml, -1) {
+		b.WriteString(l.markup(html.UnescapeString(ssml[last:m[0]])))
+		b.WriteString(ssml[m[0]:m[1]])
+		last = m[1]
+	}
+	b.WriteString(l.markup(html.UnescapeString(ssml[last:])))
+	return b.String()
+}
+
+// markup escapes plain text for SSML, wrapping terms in <phoneme> when an
+// IPA transcription is available and <sub> otherwise. The phoneme's content
+// is the respelling so downgrading to Google markup (which drops tags)
+// still reads it correctly.
+func (l *Lexicon) markup(text string) string {
+	return l.replace(text, html.EscapeString, func(e LexiconEntry, matched string) string {
+		if e.IPA != "" {
+			inner := matched
+			if e.Say != "" {
+				inner = e.Say
+			}
+			return `<phoneme alphabet="ipa" ph="` + html.EscapeString(e.IPA) + `">` + html.EscapeString(inner) + `</phoneme>`
+		}
+		return `<sub alias="` + html.EscapeString(e.Say) + `">` + html.EscapeString(matched) + `</sub>`
+	})
+}
+
+func identity(s string) string { return s }
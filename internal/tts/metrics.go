@@ -0,0 +1,230 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apresai/podcaster/internal/errkind"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Call is one logical TTS request: a segment's Synthesize or one batch
+// chunk, including its retries.
+type Call struct {
+	Provider string
+	Op       string // "segment" or "batch"
+	Duration time.Duration
+	Bytes    int64
+	Attempts int // 1 = no retries
+	Err      error
+}
+
+// callStatus is the status label for a call: "ok", "timeout", or the
+// errkind of its error.
+func callStatus(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, context.DeadlineExceeded) || os.IsTimeout(err):
+		return "timeout"
+	}
+	return string(errkind.Of(err))
+}
+
+// ProviderStats aggregates the calls to one provider for one operation.
+type ProviderStats struct {
+	Provider string
+	Op       string
+	Calls    int
+	Failures int
+	Retries  int
+	Bytes    int64
+	Total    time.Duration
+	Statuses map[string]int // calls per callStatus
+
+	latencies []time.Duration
+}
+
+// Percentile returns the p-th percentile (0-100, nearest rank) call
+// duration.
+func (s ProviderStats) Percentile(p float64) time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), s.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// Metrics collects the TTS calls of one pipeline run. Every call is also
+// recorded as OTEL metrics on the global MeterProvider, which is a no-op
+// unless one is installed (the MCP server does; see
+// observability.InitMeter). Safe for concurrent use; a nil *Metrics records
+// OTEL metrics only.
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[[2]string]*ProviderStats
+}
+
+// NewMetrics returns an empty per-run collector.
+func NewMetrics() *Metrics {
+	return &Metrics{stats: make(map[[2]string]*ProviderStats)}
+}
+
+type metricsKey struct{}
+
+// ContextWithMetrics returns ctx carrying m, for the synthesis helpers that
+// record calls (the per-segment pool and SynthesizeBatchToFile).
+func ContextWithMetrics(ctx context.Context, m *Metrics) context.Context {
+	return context.WithValue(ctx, metricsKey{}, m)
+}
+
+// MetricsFromContext returns the collector carried by ctx, or nil.
+func MetricsFromContext(ctx context.Context) *Metrics {
+	m, _ := ctx.Value(metricsKey{}).(*Metrics)
+	return m
+}
+
+// OTEL instruments. otel.Meter delegates to whichever MeterProvider is
+// installed later, so these can be created at init.
+var (
+	meter            = otel.Meter("podcaster-tts")
+	requestDuration  metric.Float64Histogram
+	requestCount     metric.Int64Counter
+	requestBytes     metric.Int64Counter
+	requestRetries   metric.Int64Counter
+	instrumentsError error
+)
+
+func init() {
+	var errs [4]error
+	requestDuration, errs[0] = meter.Float64Histogram("tts.request.duration",
+		metric.WithUnit("s"), metric.WithDescription("TTS request duration, including retries"))
+	requestCount, errs[1] = meter.Int64Counter("tts.requests",
+		metric.WithDescription("TTS requests by provider, operation, and status"))
+	requestBytes, errs[2] = meter.Int64Counter("tts.request.bytes",
+		metric.WithUnit("By"), metric.WithDescription("Audio bytes returned by TTS requests"))
+	requestRetries, errs[3] = meter.Int64Counter("tts.request.retries",
+		metric.WithDescription("TTS request retries"))
+	instrumentsError = errors.Join(errs[:]...)
+}
+
+// Record adds a call to m and to the OTEL instruments.
+func (m *Metrics) Record(ctx context.Context, c Call) {
+	status := callStatus(c.Err)
+	retries := c.Attempts - 1
+	if retries < 0 {
+		retries = 0
+	}
+
+	if instrumentsError == nil {
+		attrs := metric.WithAttributes(
+			attribute.String("provider", c.Provider),
+			attribute.String("operation", c.Op),
+			attribute.String("status", status),
+		)
+		requestDuration.Record(ctx, c.Duration.Seconds(), attrs)
+		requestCount.Add(ctx, 1, attrs)
+		requestBytes.Add(ctx, c.Bytes, attrs)
+		requestRetries.Add(ctx, int64(retries), attrs)
+	}
+
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := [2]string{c.Provider, c.Op}
+	s, ok := m.stats[key]
+	if !ok {
+		s = &ProviderStats{Provider: c.Provider, Op: c.Op, Statuses: make(map[string]int)}
+		m.stats[key] = s
+	}
+	s.Calls++
+	if c.Err != nil {
+		s.Failures++
+	}
+	s.Retries += retries
+	s.Bytes += c.Bytes
+	s.Total += c.Duration
+	s.Statuses[status]++
+	s.latencies = append(s.latencies, c.Duration)
+}
+
+// Stats returns the aggregates by provider, then operation.
+func (m *Metrics) Stats() []ProviderStats {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]ProviderStats, 0, len(m.stats))
+	for _, s := range m.stats {
+		cp := *s
+		cp.latencies = append([]time.Duration(nil), s.latencies...)
+		cp.Statuses = make(map[string]int, len(s.Statuses))
+		for k, v := range s.Statuses {
+			cp.Statuses[k] = v
+		}
+		out = append(out, cp)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Provider != out[j].Provider {
+			return out[i].Provider < out[j].Provider
+		}
+		return out[i].Op < out[j].Op
+	})
+	return out
+}
+
+// WriteSummary writes the per-provider table printed at the end of a run.
+// Nothing is written if no calls were recorded.
+func (m *Metrics) WriteSummary(w io.Writer) {
+	stats := m.Stats()
+	if len(stats) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tOP\tCALLS\tFAILED\tRETRIES\tAUDIO\tP50\tP95\tMAX\tERRORS")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%.1f MB\t%s\t%s\t%s\t%s\n",
+			s.Provider, s.Op, s.Calls, s.Failures, s.Retries,
+			float64(s.Bytes)/(1024*1024),
+			s.Percentile(50).Round(time.Millisecond),
+			s.Percentile(95).Round(time.Millisecond),
+			s.Percentile(100).Round(time.Millisecond),
+			formatStatuses(s.Statuses))
+	}
+	tw.Flush()
+}
+
+// formatStatuses lists the non-ok statuses with their counts
+// ("provider_quota=2 timeout=1"), or "-" if every call succeeded.
+func formatStatuses(statuses map[string]int) string {
+	var parts []string
+	for name, n := range statuses {
+		if name != "ok" {
+			parts = append(parts, fmt.Sprintf("%s=%d", name, n))
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
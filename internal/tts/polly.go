@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/polly"
 	"github.com/aws/aws-sdk-go-v2/service/polly/types"
 )
@@ -47,9 +46,9 @@ func NewPollyProvider(voice1, voice2, voice3 string, cfg ProviderConfig) (*Polly
 		v3 = voice3
 	}
 
-	awsCfg, err := config.LoadDefaultConfig(context.Background())
+	awsCfg, err := awsConfig(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("load AWS config for Polly: %w", err)
+		return nil, err
 	}
 
 	return &PollyProvider{
@@ -110,12 +109,12 @@ func strPtr(s string) *string { return &s }
 
 func pollyAvailableVoices() []VoiceInfo {
 	return []VoiceInfo{
-		{ID: "Matthew", Name: "Matthew", Gender: "male", Description: "en-US, Generative", DefaultFor: "Voice 1"},
-		{ID: "Ruth", Name: "Ruth", Gender: "female", Description: "en-US, Generative", DefaultFor: "Voice 2"},
-		{ID: "Amy", Name: "Amy", Gender: "female", Description: "en-GB, Generative", DefaultFor: "Voice 3"},
-		{ID: "Stephen", Name: "Stephen", Gender: "male", Description: "en-US, Generative"},
-		{ID: "Danielle", Name: "Danielle", Gender: "female", Description: "en-US, Generative"},
-		{ID: "Olivia", Name: "Olivia", Gender: "female", Description: "en-AU, Generative"},
-		{ID: "Kajal", Name: "Kajal", Gender: "female", Description: "en-IN, Generative"},
+		{ID: "Matthew", Name: "Matthew", Gender: "male", Description: "en-US, Generative", Language: "en-US", DefaultFor: "Voice 1"},
+		{ID: "Ruth", Name: "Ruth", Gender: "female", Description: "en-US, Generative", Language: "en-US", DefaultFor: "Voice 2"},
+		{ID: "Amy", Name: "Amy", Gender: "female", Description: "en-GB, Generative", Language: "en-GB", DefaultFor: "Voice 3"},
+		{ID: "Stephen", Name: "Stephen", Gender: "male", Description: "en-US, Generative", Language: "en-US"},
+		{ID: "Danielle", Name: "Danielle", Gender: "female", Description: "en-US, Generative", Language: "en-US"},
+		{ID: "Olivia", Name: "Olivia", Gender: "female", Description: "en-AU, Generative", Language: "en-AU"},
+		{ID: "Kajal", Name: "Kajal", Gender: "female", Description: "en-IN, Generative", Language: "en-IN"},
 	}
 }
@@ -0,0 +1,21 @@
+package tts
+
+// charCostUSD is the approximate list price per synthesized character, used
+// for cost estimates only. Plans and free tiers make real bills vary.
+var charCostUSD = map[string]float64{
+	"gemini":         0.000016, // ~$16 per 1M chars; Gemini TTS is billed with the API
+	"gemini-vertex":  0.000016,
+	"vertex-express": 0.000016,
+	"elevenlabs":     0.00018,  // ~$180 per 1M chars (Creator plan rate)
+	"google":         0.000016, // Google Cloud TTS standard
+	"polly":          0.00003,  // ~$30 per 1M chars (generative engine)
+	"cartesia":       0.00005,  // ~$50 per 1M chars (Scale plan credits)
+	"hume":           0.0001,   // ~$100 per 1M chars (Creator plan rate)
+	"deepgram":       0.00003,  // ~$30 per 1M chars (Aura 2; Aura is half that)
+}
+
+// EstimateCost returns the approximate USD cost of synthesizing chars
+// characters with the named provider (0 for unknown providers).
+func EstimateCost(provider string, chars int) float64 {
+	return charCostUSD[provider] * float64(chars)
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/apresai/podcaster/internal/errkind"
 	"github.com/apresai/podcaster/internal/script"
 )
 
@@ -23,9 +28,10 @@ const (
 
 // Voice holds a provider-specific voice identifier.
 type Voice struct {
-	ID       string // Provider-specific voice identifier
-	Name     string // Human-readable label
-	Provider string // "elevenlabs", "gemini", "google"
+	ID       string        // Provider-specific voice identifier
+	Name     string        // Human-readable label
+	Provider string        // "elevenlabs", "gemini", "google", "cartesia", "hume", "deepgram"
+	Settings VoiceSettings // per-voice overrides of ProviderConfig (voicesettings.go)
 }
 
 // VoiceMap maps podcast hosts to voices.
@@ -57,29 +63,89 @@ type BatchProvider interface {
 	SynthesizeBatch(ctx context.Context, segments []script.Segment, voices VoiceMap) (AudioResult, error)
 }
 
+// StreamBatchProvider is a BatchProvider that can write the audio to w as it
+// arrives instead of returning it in memory. Batch responses for a long
+// script run to hundreds of MB, so the pipeline streams them to disk.
+type StreamBatchProvider interface {
+	BatchProvider
+	StreamBatch(ctx context.Context, segments []script.Segment, voices VoiceMap, w io.Writer) (AudioFormat, error)
+}
+
 // VoiceInfo describes an available voice for display in the registry.
 type VoiceInfo struct {
 	ID          string
 	Name        string
 	Gender      string // "male" or "female"
 	Description string
+	Language    string // BCP 47 tag ("en-US", or "en" for any region), or LanguageMultilingual
 	DefaultFor  string // "Voice 1", "Voice 2", "Voice 3", or ""
+
+	// Catalog metadata for choosing a voice (see voicemeta.go); any may be
+	// empty.
+	Accent    string   // "American", "British", ...
+	Age       string   // "young", "middle-aged", or "old"
+	Tags      []string // style: "warm", "energetic", "narrator", ...
+	SampleURL string   // short sample clip (MP3)
+}
+
+// LanguageMultilingual marks voices that speak every language their model
+// supports, like Gemini's.
+const LanguageMultilingual = "multilingual"
+
+// SpeaksLanguage reports whether the voice can be used for lang, a BCP 47
+// tag such as "es" or "pt-BR". The primary languages must match, and the
+// regions too when both tags have one, so "en" matches an en-GB voice and
+// "en-GB" matches an "en" voice but not an en-US one. An empty lang and
+// multilingual voices match everything.
+func (v VoiceInfo) SpeaksLanguage(lang string) bool {
+	if lang == "" || v.Language == LanguageMultilingual {
+		return true
+	}
+	wantLang, wantRegion, _ := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-")
+	gotLang, gotRegion, _ := strings.Cut(strings.ReplaceAll(v.Language, "_", "-"), "-")
+	if !strings.EqualFold(wantLang, gotLang) {
+		return false
+	}
+	return wantRegion == "" || gotRegion == "" || strings.EqualFold(wantRegion, gotRegion)
+}
+
+// FilterVoicesByLanguage returns the voices that speak lang (see
+// VoiceInfo.SpeaksLanguage).
+func FilterVoicesByLanguage(voices []VoiceInfo, lang string) []VoiceInfo {
+	if lang == "" {
+		return voices
+	}
+	var out []VoiceInfo
+	for _, v := range voices {
+		if v.SpeaksLanguage(lang) {
+			out = append(out, v)
+		}
+	}
+	return out
 }
 
 // AvailableVoices returns the voice catalog for the named provider.
 func AvailableVoices(providerName string) ([]VoiceInfo, error) {
+	var voices []VoiceInfo
 	switch providerName {
 	case "elevenlabs":
-		return elevenLabsAvailableVoices(), nil
+		voices = elevenLabsAvailableVoices()
 	case "google":
-		return googleAvailableVoices(), nil
+		voices = googleAvailableVoices()
 	case "gemini", "gemini-vertex", "vertex-express":
-		return geminiAvailableVoices(), nil
+		voices = geminiAvailableVoices()
 	case "polly":
-		return pollyAvailableVoices(), nil
+		voices = pollyAvailableVoices()
+	case "cartesia":
+		voices = cartesiaAvailableVoices()
+	case "hume":
+		voices = humeAvailableVoices()
+	case "deepgram":
+		voices = deepgramAvailableVoices()
 	default:
 		return nil, fmt.Errorf("unknown TTS provider %q", providerName)
 	}
+	return enrichVoices(providerName, voices), nil
 }
 
 // ResolveVoiceName resolves a voice display name to a provider-specific voice ID.
@@ -126,6 +192,59 @@ func (e *RetryableError) Error() string {
 	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
 }
 
+// ErrorKind classifies a retryable error that outlasted its retries: a 429
+// is the provider's rate limit, a 401 that survived a credential refresh is
+// an auth failure, and anything else is the provider being down.
+func (e *RetryableError) ErrorKind() errkind.Kind {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests:
+		return errkind.ProviderQuota
+	case http.StatusUnauthorized:
+		return errkind.ProviderAuth
+	}
+	return errkind.ProviderUnavailable
+}
+
+// transportCause strips the method and URL that net/http wraps around a
+// transport error, so a request URL never ends up in a job's error message.
+func transportCause(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		return ue.Err
+	}
+	return err
+}
+
+// statusError classifies a provider's error for a non-retryable API
+// response. 401 and 403, and Google's 400 with API_KEY_INVALID, mean the API
+// key or credentials were rejected; anything else returns err unchanged.
+func statusError(provider string, status int, body string, err error) error {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden ||
+		strings.Contains(body, "API_KEY_INVALID") {
+		return errkind.Wrap(errkind.ProviderAuth, provider+" rejected the API key or credentials", err)
+	}
+	return err
+}
+
+// QuotaExhaustedError signals that a provider's daily quota is used up.
+// Retrying is pointless until the quota resets, but another provider in the
+// ProviderSet fallback chain can take over.
+type QuotaExhaustedError struct {
+	Provider string
+	Message  string
+}
+
+func (e *QuotaExhaustedError) Error() string { return e.Message }
+
+func (e *QuotaExhaustedError) ErrorKind() errkind.Kind { return errkind.ProviderQuota }
+
+// IsQuotaExhausted reports whether err (or anything it wraps) is a
+// QuotaExhaustedError.
+func IsQuotaExhausted(err error) bool {
+	var qe *QuotaExhaustedError
+	return errors.As(err, &qe)
+}
+
 // isRetryable checks if an error should be retried.
 // Retryable: RetryableError (429/5xx), timeout errors, deadline exceeded
 // (but only if the parent context is still valid — a cancelled parent means shutdown).
@@ -141,14 +260,55 @@ func isRetryable(ctx context.Context, err error) bool {
 	return false
 }
 
-// WithRetry executes fn with exponential backoff on retryable errors.
-// When the error includes a Retry-After duration (from HTTP headers),
-// the wait time is max(retryAfter, backoff) to respect server guidance.
+// RetryPolicy controls how WithRetry backs off. Zero fields use the
+// defaults (5 attempts, 2s initial backoff doubling up to 30s).
+type RetryPolicy struct {
+	MaxAttempts    int           // total attempts including the first (1 = no retries)
+	InitialBackoff time.Duration // wait before the first retry
+	MaxBackoff     time.Duration // cap on the exponential backoff
+}
+
+// withDefaults fills zero fields with the package defaults.
+func (rp RetryPolicy) withDefaults() RetryPolicy {
+	if rp.MaxAttempts <= 0 {
+		rp.MaxAttempts = defaultMaxAttempts
+	}
+	if rp.InitialBackoff <= 0 {
+		rp.InitialBackoff = defaultInitialBackoff
+	}
+	if rp.MaxBackoff <= 0 {
+		rp.MaxBackoff = defaultMaxBackoff
+	}
+	if rp.MaxBackoff < rp.InitialBackoff {
+		rp.MaxBackoff = rp.InitialBackoff
+	}
+	return rp
+}
+
+// jitter spreads a backoff over [d/2, d] so parallel workers that failed
+// together don't retry in lockstep.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// WithRetry executes fn with the default RetryPolicy.
 func WithRetry(ctx context.Context, fn func() error) error {
+	return WithRetryPolicy(ctx, RetryPolicy{}, fn)
+}
+
+// WithRetryPolicy executes fn with jittered exponential backoff on retryable
+// errors. When the error includes a Retry-After duration (from HTTP headers),
+// the wait time is max(retryAfter, backoff) to respect server guidance.
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	policy = policy.withDefaults()
 	var lastErr error
-	backoff := defaultInitialBackoff
+	backoff := policy.InitialBackoff
 
-	for attempt := 1; attempt <= defaultMaxAttempts; attempt++ {
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
 		if err := fn(); err == nil {
 			return nil
 		} else if !isRetryable(ctx, err) {
@@ -157,14 +317,14 @@ func WithRetry(ctx context.Context, fn func() error) error {
 			lastErr = err
 		}
 
-		if attempt < defaultMaxAttempts {
-			wait := backoff
+		if attempt < policy.MaxAttempts {
+			wait := jitter(backoff)
 			if re, ok := lastErr.(*RetryableError); ok && re.RetryAfter > 0 {
 				if re.RetryAfter > wait {
 					wait = re.RetryAfter
 				}
 				fmt.Fprintf(os.Stderr, "[retry] 429 with Retry-After: %s, waiting %s (attempt %d/%d)\n",
-					re.RetryAfter, wait, attempt, defaultMaxAttempts)
+					re.RetryAfter, wait, attempt, policy.MaxAttempts)
 			}
 			select {
 			case <-ctx.Done():
@@ -172,8 +332,8 @@ func WithRetry(ctx context.Context, fn func() error) error {
 			case <-time.After(wait):
 			}
 			backoff *= time.Duration(defaultBackoffMulti)
-			if backoff > defaultMaxBackoff {
-				backoff = defaultMaxBackoff
+			if backoff > policy.MaxBackoff {
+				backoff = policy.MaxBackoff
 			}
 		}
 	}
@@ -188,6 +348,21 @@ type ProviderConfig struct {
 	Stability float64 // ElevenLabs voice stability 0-1 (0 = default 0.5)
 	Pitch     float64 // Google Cloud pitch in semitones (0 = default)
 	APIKey    string  // per-request API key override (empty = use env var)
+
+	// Retry is the backoff policy for this provider's requests.
+	Retry RetryPolicy
+
+	// DisableKeepAlives opens a new connection per request instead of
+	// reusing pooled ones (see ttsTransport).
+	DisableKeepAlives bool
+
+	// StylePrompts enables natural-language style instructions for
+	// Gemini-family providers (--tts-style-prompts). StyleTone is the
+	// episode's tone from its script styles (see StyleTone): batch requests
+	// open with it, and the pipeline prefixes per-segment text with a
+	// StyleDirective built from it and the segment's delivery.
+	StylePrompts bool
+	StyleTone    string
 }
 
 // validModels maps provider names to their valid model IDs.
@@ -210,6 +385,19 @@ var validModels = map[string]map[string]bool{
 		"gemini-2.5-flash-tts": true,
 		"gemini-2.5-pro-tts":   true,
 	},
+	"cartesia": {
+		"sonic-2":     true,
+		"sonic-turbo": true,
+		"sonic":       true,
+	},
+	"hume": {
+		"octave-1": true,
+		"octave-2": true,
+	},
+	"deepgram": {
+		"aura-2": true,
+		"aura":   true,
+	},
 }
 
 // ValidateModel checks that the given model ID is valid for the provider.
@@ -249,8 +437,14 @@ func NewProvider(name string, voice1, voice2, voice3 string, cfg ProviderConfig)
 		return NewVertexExpressProvider(voice1, voice2, voice3, cfg)
 	case "polly":
 		return NewPollyProvider(voice1, voice2, voice3, cfg)
+	case "cartesia":
+		return NewCartesiaProvider(voice1, voice2, voice3, cfg), nil
+	case "hume":
+		return NewHumeProvider(voice1, voice2, voice3, cfg), nil
+	case "deepgram":
+		return NewDeepgramProvider(voice1, voice2, voice3, cfg), nil
 	default:
-		return nil, fmt.Errorf("unknown TTS provider %q: choose elevenlabs, google, gemini, gemini-vertex, vertex-express, or polly", name)
+		return nil, fmt.Errorf("unknown TTS provider %q: choose elevenlabs, google, gemini, gemini-vertex, vertex-express, polly, cartesia, hume, or deepgram", name)
 	}
 }
 
@@ -261,7 +455,7 @@ func ParseVoiceSpec(spec string) (provider, voiceID string) {
 		prefix := spec[:i]
 		// Only treat as provider prefix if it's a known provider name
 		switch prefix {
-		case "elevenlabs", "gemini", "gemini-vertex", "vertex-express", "google", "polly":
+		case "elevenlabs", "gemini", "gemini-vertex", "vertex-express", "google", "polly", "cartesia", "hume", "deepgram":
 			return prefix, spec[i+1:]
 		}
 	}
@@ -269,18 +463,70 @@ func ParseVoiceSpec(spec string) (provider, voiceID string) {
 }
 
 // ProviderSet is a lazy pool of TTS providers, created on first use.
+// An optional fallback chain lets the pipeline switch providers mid-run when
+// one runs out of daily quota or its circuit breaker trips (see breaker.go).
 type ProviderSet struct {
 	mu        sync.Mutex
 	providers map[string]Provider
 	configs   map[string]ProviderConfig
+	fallbacks []string
+	exhausted map[string]bool
+
+	breakerThreshold int            // consecutive failures that trip a provider (<= 0 = never)
+	failures         map[string]int // consecutive failed requests per provider
+	tripped          map[string]bool
+	openedAt         map[string]time.Time // when each breaker last tripped or failed a probe
+	probing          map[string]bool      // a half-open probe is in flight
 }
 
 // NewProviderSet creates an empty provider pool.
 func NewProviderSet() *ProviderSet {
 	return &ProviderSet{
-		providers: make(map[string]Provider),
-		configs:   make(map[string]ProviderConfig),
+		providers:        make(map[string]Provider),
+		configs:          make(map[string]ProviderConfig),
+		exhausted:        make(map[string]bool),
+		breakerThreshold: DefaultBreakerThreshold,
+		failures:         make(map[string]int),
+		tripped:          make(map[string]bool),
+		openedAt:         make(map[string]time.Time),
+		probing:          make(map[string]bool),
+	}
+}
+
+// SetFallbacks sets the ordered list of providers to switch to when a
+// provider reports QuotaExhaustedError.
+func (ps *ProviderSet) SetFallbacks(names []string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.fallbacks = append([]string(nil), names...)
+}
+
+// MarkExhausted records that the named provider is out of quota, so
+// Fallback stops returning it.
+func (ps *ProviderSet) MarkExhausted(name string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.exhausted[name] = true
+}
+
+// Exhausted reports whether the named provider has been marked out of quota.
+func (ps *ProviderSet) Exhausted(name string) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.exhausted[name]
+}
+
+// Fallback returns the first provider in the fallback chain that has not been
+// marked exhausted, or false if the chain is used up.
+func (ps *ProviderSet) Fallback() (string, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for _, name := range ps.fallbacks {
+		if !ps.exhausted[name] {
+			return name, true
+		}
 	}
+	return "", false
 }
 
 // SetConfig stores a ProviderConfig for the named provider.
@@ -291,6 +537,14 @@ func (ps *ProviderSet) SetConfig(name string, cfg ProviderConfig) {
 	ps.configs[name] = cfg
 }
 
+// Config returns the ProviderConfig stored for the named provider
+// (zero value if none was set).
+func (ps *ProviderSet) Config(name string) ProviderConfig {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.configs[name]
+}
+
 // Get returns a provider by name, creating it on first call.
 // Voice IDs are not passed here — they are routed per-segment via Voice.ID.
 func (ps *ProviderSet) Get(name string) (Provider, error) {
@@ -0,0 +1,186 @@
+package tts
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Voice recommendations: rank a catalog against a description of the show
+// using the metadata in voicemeta.go, and pick two hosts that fit it and
+// are easy to tell apart. The MCP recommend_voices tool refines these
+// picks with a small LLM call and falls back to them as-is.
+
+// VoiceBrief describes the show voices are being chosen for.
+type VoiceBrief struct {
+	Format   string // script format: conversation, interview, news, ...
+	Tone     string // casual, technical, or educational
+	Language string // BCP 47 tag; empty = any
+	Vibe     string // free text, e.g. "energetic morning-show duo"
+}
+
+// VoicePairing is a suggested host 1 / host 2 pair from one provider.
+type VoicePairing struct {
+	Provider  string
+	Host1     VoiceInfo
+	Host2     VoiceInfo
+	Matched   []string // brief terms the pair's metadata matched
+	Rationale string
+}
+
+// formatTraits and toneTraits are the voice tags that suit each script
+// format and tone.
+var formatTraits = map[string][]string{
+	"conversation": {"conversational", "friendly", "casual"},
+	"interview":    {"professional", "clear", "friendly"},
+	"deep-dive":    {"measured", "knowledgeable", "narrator"},
+	"explainer":    {"clear", "informative", "friendly"},
+	"debate":       {"confident", "firm", "expressive"},
+	"news":         {"news", "authoritative", "clear"},
+	"storytelling": {"storyteller", "narrator", "expressive"},
+	"challenger":   {"confident", "firm", "animated"},
+}
+
+var toneTraits = map[string][]string{
+	"casual":      {"casual", "relaxed", "conversational"},
+	"technical":   {"clear", "precise", "measured"},
+	"educational": {"clear", "informative", "warm"},
+}
+
+// vibeTraits maps common words in a free-text vibe to the tags they imply;
+// other words are matched against tags and descriptions as written.
+var vibeTraits = map[string][]string{
+	"morning":     {"energetic", "upbeat", "bright", "cheerful"},
+	"fun":         {"playful", "upbeat", "animated"},
+	"funny":       {"playful", "animated", "expressive"},
+	"comedy":      {"playful", "animated", "expressive"},
+	"hype":        {"energetic", "excitable", "animated"},
+	"chill":       {"relaxed", "laid-back", "calm"},
+	"cozy":        {"warm", "soft", "gentle"},
+	"late-night":  {"smooth", "deep", "relaxed"},
+	"serious":     {"authoritative", "measured", "firm"},
+	"academic":    {"knowledgeable", "measured", "informative"},
+	"business":    {"professional", "polished", "confident"},
+	"dramatic":    {"expressive", "deep", "storyteller"},
+	"bedtime":     {"soft", "gentle", "calm"},
+	"mature":      {"mature", "warm", "deep"},
+	"youthful":    {"youthful", "bright", "energetic"},
+	"trustworthy": {"reassuring", "warm", "steady"},
+}
+
+// briefTerms returns the deduplicated terms to match voices against: the
+// format and tone traits, then the vibe's words and what they imply.
+func briefTerms(b VoiceBrief) []string {
+	var terms []string
+	add := func(ts ...string) {
+		for _, t := range ts {
+			if t != "" && !slices.Contains(terms, t) {
+				terms = append(terms, t)
+			}
+		}
+	}
+	add(formatTraits[strings.ToLower(b.Format)]...)
+	add(toneTraits[strings.ToLower(b.Tone)]...)
+	words := strings.FieldsFunc(strings.ToLower(b.Vibe), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r == '-')
+	})
+	for _, w := range words {
+		for _, part := range append([]string{w}, strings.Split(w, "-")...) {
+			add(vibeTraits[part]...)
+		}
+		if len(w) > 3 {
+			add(w)
+		}
+	}
+	return terms
+}
+
+// voiceScore scores v against terms: two points per matching tag, one per
+// term found only in the description, and one for an age the vibe asks for.
+// It returns the matched terms too.
+func voiceScore(v VoiceInfo, terms []string, vibe string) (int, []string) {
+	score := 0
+	var matched []string
+	desc := strings.ToLower(v.Description)
+	for _, t := range terms {
+		switch {
+		case slices.ContainsFunc(v.Tags, func(tag string) bool { return strings.EqualFold(tag, t) }):
+			score += 2
+			matched = append(matched, t)
+		case strings.Contains(desc, t):
+			score++
+			matched = append(matched, t)
+		}
+	}
+	vibe = strings.ToLower(vibe)
+	switch {
+	case v.Age == "young" && (strings.Contains(vibe, "young") || strings.Contains(vibe, "youthful")):
+		score++
+	case v.Age == "old" && (strings.Contains(vibe, "older") || strings.Contains(vibe, "mature")):
+		score++
+	}
+	return score, matched
+}
+
+// RankVoices returns the voices that speak b.Language, best match for b
+// first. Ties keep catalog order, so default voices stay near the top.
+func RankVoices(voices []VoiceInfo, b VoiceBrief) []VoiceInfo {
+	terms := briefTerms(b)
+	ranked := slices.Clone(FilterVoicesByLanguage(voices, b.Language))
+	scores := make(map[string]int, len(ranked))
+	for _, v := range ranked {
+		scores[v.ID], _ = voiceScore(v, terms, b.Vibe)
+	}
+	slices.SortStableFunc(ranked, func(a, b VoiceInfo) int {
+		return scores[b.ID] - scores[a.ID]
+	})
+	return ranked
+}
+
+// RecommendPairing picks two hosts from a provider's catalog for b: the best
+// match as host 1, and the best remaining match of the other gender as host
+// 2 (any other voice if the catalog has none), so listeners can tell them
+// apart.
+func RecommendPairing(provider string, voices []VoiceInfo, b VoiceBrief) (VoicePairing, error) {
+	ranked := RankVoices(voices, b)
+	if len(ranked) < 2 {
+		return VoicePairing{}, fmt.Errorf("%s has fewer than two voices for language %q", provider, b.Language)
+	}
+	host1 := ranked[0]
+	host2 := ranked[1]
+	for _, v := range ranked[1:] {
+		if v.Gender != "" && v.Gender != host1.Gender {
+			host2 = v
+			break
+		}
+	}
+
+	terms := briefTerms(b)
+	_, m1 := voiceScore(host1, terms, b.Vibe)
+	_, m2 := voiceScore(host2, terms, b.Vibe)
+	var matched []string
+	for _, t := range append(m1, m2...) {
+		if !slices.Contains(matched, t) {
+			matched = append(matched, t)
+		}
+	}
+
+	p := VoicePairing{Provider: provider, Host1: host1, Host2: host2, Matched: matched}
+	p.Rationale = pairingRationale(p)
+	return p, nil
+}
+
+// pairingRationale explains a heuristic pairing in a sentence or two.
+func pairingRationale(p VoicePairing) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (%s) and %s (%s)", p.Host1.Name, p.Host1.Summary(), p.Host2.Name, p.Host2.Summary())
+	if len(p.Matched) > 0 {
+		fmt.Fprintf(&sb, " match %s.", strings.Join(p.Matched, ", "))
+	} else {
+		sb.WriteString(" are this catalog's closest fit; nothing in their metadata matched the brief directly.")
+	}
+	if p.Host1.Gender != "" && p.Host2.Gender != "" && p.Host1.Gender != p.Host2.Gender {
+		sb.WriteString(" Contrasting voices keep the two hosts easy to tell apart.")
+	}
+	return sb.String()
+}
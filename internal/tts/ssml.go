@@ -0,0 +1,101 @@
+package tts
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SSMLProvider is implemented by providers that accept SSML input. Segments
+// whose script carries SSML are routed here; other providers get plain text.
+type SSMLProvider interface {
+	Provider
+	SynthesizeSSML(ctx context.Context, ssml string, voice Voice) (AudioResult, error)
+}
+
+// Prosody hints the script generator can emit inline (see --ssml-hints):
+//
+//	[pause short] [pause] [pause long]   a beat of silence
+//	*word*                                 spoken emphasis
+//
+// HintsToSSML converts them to SSML; providers without SSML support get the
+// text with hints stripped.
+var (
+	pauseHint    = regexp.MustCompile(`\s*\[pause(?: (short|long))?\]\s*`)
+	emphasisHint = regexp.MustCompile(`\*([^*\n]+)\*`)
+
+	// Years and comma-grouped numbers are wrapped in say-as so they're read
+	// as "twenty twenty-four" and "one million", not digit by digit.
+	yearPattern    = regexp.MustCompile(`\b(1[5-9]\d\d|20\d\d)\b`)
+	groupedNumbers = regexp.MustCompile(`\b\d{1,3}(?:,\d{3})+\b`)
+)
+
+// pauseDurations maps pause hints to SSML break times.
+var pauseDurations = map[string]string{
+	"short": "250ms",
+	"":      "500ms",
+	"long":  "1s",
+}
+
+// HasHints reports whether text contains prosody hints.
+func HasHints(text string) bool {
+	return pauseHint.MatchString(text) || emphasisHint.MatchString(text)
+}
+
+// StripHints removes prosody hints, leaving plain speakable text.
+func StripHints(text string) string {
+	text = pauseHint.ReplaceAllString(text, " ")
+	text = emphasisHint.ReplaceAllString(text, "$1")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// HintsToSSML converts hinted text to an SSML <speak> document. Returns ""
+// when text has no hints, so callers can keep the plain-text path.
+func HintsToSSML(text string) string {
+	if !HasHints(text) {
+		return ""
+	}
+
+	// Escape first; hint syntax uses no XML-special characters.
+	s := html.EscapeString(text)
+	s = yearPattern.ReplaceAllString(s, `<say-as interpret-as="date" format="y">$1</say-as>`)
+	s = groupedNumbers.ReplaceAllString(s, `<say-as interpret-as="cardinal">$0</say-as>`)
+	s = emphasisHint.ReplaceAllString(s, `<emphasis level="moderate">$1</emphasis>`)
+	s = pauseHint.ReplaceAllStringFunc(s, func(m string) string {
+		kind := pauseHint.FindStringSubmatch(m)[1]
+		return ` <break time="` + pauseDurations[kind] + `"/> `
+	})
+	return "<speak>" + strings.TrimSpace(s) + "</speak>"
+}
+
+var (
+	ssmlBreak = regexp.MustCompile(`<break time="(\d+)(ms|s)"\s*/>`)
+	ssmlSub   = regexp.MustCompile(`<sub alias="([^"]*)">[^<]*</sub>`)
+	ssmlTag   = regexp.MustCompile(`<[^>]+>`)
+)
+
+// ssmlToMarkup downgrades SSML to Google's markup input, which supports
+// only pause tags. Used for voices that reject SSML (Chirp 3 HD). <sub>
+// becomes its alias so lexicon substitutions survive.
+func ssmlToMarkup(ssml string) string {
+	s := ssmlBreak.ReplaceAllStringFunc(ssml, func(m string) string {
+		sub := ssmlBreak.FindStringSubmatch(m)
+		ms, _ := strconv.Atoi(sub[1])
+		if sub[2] == "s" {
+			ms *= 1000
+		}
+		switch {
+		case ms < 400:
+			return "[pause short]"
+		case ms < 800:
+			return "[pause]"
+		default:
+			return "[pause long]"
+		}
+	})
+	s = ssmlSub.ReplaceAllString(s, "$1")
+	s = ssmlTag.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
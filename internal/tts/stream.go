@@ -0,0 +1,176 @@
+package tts
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errNoAudioField means a response ended without an inlineData "data" field.
+var errNoAudioField = errors.New("no audio data field")
+
+// streamInlineAudio decodes the base64 audio in a Gemini-family
+// generateContent response (candidates[0].content.parts[0].inlineData.data)
+// straight from r into w, without holding the response, the base64 text,
+// or the decoded audio in memory. A long batch response can run to hundreds
+// of MB. It returns the number of audio bytes written.
+//
+// A response without audio, or one cut off mid-stream, is a RetryableError
+// (like a network error); corrupt base64 and write failures are not.
+func streamInlineAudio(r io.Reader, w io.Writer) (int64, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	if err := seekJSONKey(br, "data"); err != nil {
+		if errors.Is(err, errNoAudioField) {
+			return 0, &RetryableError{StatusCode: 200, Body: "response contained no audio data"}
+		}
+		return 0, &RetryableError{StatusCode: 0, Body: fmt.Sprintf("read response: %v", err)}
+	}
+
+	src := &jsonStringReader{r: br}
+	n, err := io.Copy(w, base64.NewDecoder(base64.StdEncoding, src))
+	switch {
+	case err == nil && n == 0:
+		return 0, &RetryableError{StatusCode: 200, Body: "response contained no audio data"}
+	case err == nil:
+		return n, nil
+	case src.err != nil:
+		return n, &RetryableError{StatusCode: 0, Body: fmt.Sprintf("read response after %d audio bytes: %v", n, src.err)}
+	}
+	var corrupt base64.CorruptInputError
+	if errors.As(err, &corrupt) {
+		return n, fmt.Errorf("decode audio base64: %w", err)
+	}
+	return n, fmt.Errorf("write audio: %w", err)
+}
+
+// seekJSONKey advances br just past the opening quote of the first string
+// value whose key is key. Only enough of each string is kept to compare it
+// with key, so skipping large values costs no memory.
+func seekJSONKey(br *bufio.Reader, key string) error {
+	var cur []byte
+	inString, escaped := false, false
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return errNoAudioField
+		}
+		if err != nil {
+			return err
+		}
+		if !inString {
+			if b == '"' {
+				inString = true
+				cur = cur[:0]
+			}
+			continue
+		}
+		switch {
+		case escaped:
+			escaped = false
+		case b == '\\':
+			escaped = true
+			continue
+		case b == '"':
+			inString = false
+			if string(cur) != key {
+				continue
+			}
+			if c, err := nextNonSpace(br); err != nil {
+				return err
+			} else if c != ':' {
+				br.UnreadByte()
+				continue
+			}
+			if c, err := nextNonSpace(br); err != nil {
+				return err
+			} else if c == '"' {
+				return nil
+			}
+			br.UnreadByte() // non-string value: keep scanning inside it
+			continue
+		}
+		if len(cur) <= len(key) {
+			cur = append(cur, b)
+		}
+	}
+}
+
+func nextNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return 0, errNoAudioField
+			}
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, nil
+	}
+}
+
+// jsonStringReader reads the rest of a JSON string from r, up to its closing
+// quote, unescaping the escapes that can appear in base64 text (\/ and
+// line breaks, which the base64 decoder skips). Read errors from r are kept
+// in err so callers can tell them from decode and write errors.
+type jsonStringReader struct {
+	r    *bufio.Reader
+	done bool
+	err  error
+}
+
+func (j *jsonStringReader) Read(p []byte) (int, error) {
+	if j.done {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if j.r.Buffered() == 0 {
+		if _, err := j.r.Peek(1); err != nil {
+			return 0, j.fail(err)
+		}
+	}
+	buf, _ := j.r.Peek(min(j.r.Buffered(), len(p)))
+	if i := bytes.IndexAny(buf, "\"\\"); i != 0 {
+		if i < 0 {
+			i = len(buf)
+		}
+		copy(p, buf[:i])
+		j.r.Discard(i)
+		return i, nil
+	}
+
+	b, _ := j.r.ReadByte()
+	if b == '"' {
+		j.done = true
+		return 0, io.EOF
+	}
+	e, err := j.r.ReadByte()
+	if err != nil {
+		return 0, j.fail(err)
+	}
+	switch e {
+	case '/', '\\', '"':
+		p[0] = e
+	case 'n', 'r':
+		p[0] = '\n'
+	default:
+		return 0, fmt.Errorf("unexpected escape \\%c in audio data", e)
+	}
+	return 1, nil
+}
+
+func (j *jsonStringReader) fail(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	j.err = err
+	return err
+}
@@ -0,0 +1,64 @@
+package tts
+
+import "strings"
+
+// styleTones describes how each script style (--style) should sound, for
+// the natural-language style prompts Gemini TTS accepts. Each starts with a
+// consonant so it reads after "in a".
+var styleTones = map[string]string{
+	"humor":        "playful, witty",
+	"wow":          "genuinely amazed, animated",
+	"serious":      "measured, serious",
+	"debate":       "confident, persuasive",
+	"storytelling": "vivid, storytelling",
+}
+
+// GeminiFamily reports whether provider is one of the Gemini TTS providers,
+// which take style instructions in the text itself ("Say cheerfully: ...")
+// rather than as a request parameter.
+func GeminiFamily(provider string) bool {
+	switch provider {
+	case "gemini", "gemini-vertex", "vertex-express":
+		return true
+	}
+	return false
+}
+
+// StyleTone returns the tone for an episode's script styles ("playful,
+// witty, measured, serious"), or "" if none of them has one.
+func StyleTone(styles []string) string {
+	var tones []string
+	for _, s := range styles {
+		if t, ok := styleTones[strings.TrimSpace(s)]; ok {
+			tones = append(tones, t)
+		}
+	}
+	return strings.Join(tones, ", ")
+}
+
+// StyleDirective returns the instruction prepended to a segment's text for a
+// Gemini-family provider: "Say in a playful, witty tone: ". A segment's
+// delivery direction is more specific than the episode tone, so it is added
+// to it ("Say in a playful, witty tone (whispering): ") or used alone
+// ("Say, whispering: "). Returns "" if there is neither.
+func StyleDirective(tone, delivery string) string {
+	delivery = NormalizeDelivery(delivery)
+	switch {
+	case tone != "" && delivery != "":
+		return "Say in a " + tone + " tone (" + delivery + "): "
+	case tone != "":
+		return "Say in a " + tone + " tone: "
+	case delivery != "":
+		return "Say, " + delivery + ": "
+	}
+	return ""
+}
+
+// batchStylePreamble is the instruction that opens a batch dialogue prompt,
+// which covers the whole conversation rather than one line.
+func batchStylePreamble(tone string) string {
+	if tone == "" {
+		return ""
+	}
+	return "Read this conversation in a " + tone + " tone:\n"
+}
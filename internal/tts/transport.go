@@ -0,0 +1,37 @@
+package tts
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/apresai/podcaster/internal/observability"
+)
+
+// ttsIdleConnTimeout closes pooled connections before proxies and load
+// balancers typically drop them (AgentCore's network path included), so a
+// reused connection is rarely stale. A stale one fails fast with a network
+// error, which WithRetry retries on a new connection.
+const ttsIdleConnTimeout = 30 * time.Second
+
+// ttsTransport returns the HTTP transport for a TTS client. Connections are
+// kept alive and reused, over HTTP/2 where the server supports it, so
+// per-segment synthesis pays one TLS handshake per provider instead of one
+// per segment. cfg.DisableKeepAlives (--no-keepalive) restores a fresh
+// connection per request for debugging. responseHeaderTimeout 0 means none.
+// Each request is traced as a span under the synthesis that made it.
+func ttsTransport(cfg ProviderConfig, responseHeaderTimeout time.Duration) http.RoundTripper {
+	return observability.Transport(&http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		IdleConnTimeout:       ttsIdleConnTimeout,
+		MaxIdleConnsPerHost:   8,
+		DisableKeepAlives:     cfg.DisableKeepAlives,
+	})
+}
@@ -3,17 +3,17 @@ package tts
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/apresai/podcaster/internal/script"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
@@ -33,6 +33,7 @@ type VertexProvider struct {
 	model      string
 	httpClient *http.Client
 	batchHTTPClient *http.Client
+	styleTone  string
 }
 
 func NewVertexProvider(voice1, voice2, voice3 string, cfg ProviderConfig) (*VertexProvider, error) {
@@ -74,29 +75,14 @@ func NewVertexProvider(voice1, voice2, voice3 string, cfg ProviderConfig) (*Vert
 		region:  region,
 		model:   model,
 		httpClient: &http.Client{
-			Timeout: 90 * time.Second,
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout: 10 * time.Second,
-				}).DialContext,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ResponseHeaderTimeout: 70 * time.Second,
-				IdleConnTimeout:       10 * time.Second,
-				DisableKeepAlives:     true,
-			},
+			Timeout:   90 * time.Second,
+			Transport: ttsTransport(cfg, 70*time.Second),
 		},
 		batchHTTPClient: &http.Client{
-			Timeout: 5 * time.Minute,
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout: 10 * time.Second,
-				}).DialContext,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ResponseHeaderTimeout: 4 * time.Minute,
-				IdleConnTimeout:       10 * time.Second,
-				DisableKeepAlives:     true,
-			},
+			Timeout:   5 * time.Minute,
+			Transport: ttsTransport(cfg, 4*time.Minute),
 		},
+		styleTone: cfg.StyleTone,
 	}, nil
 }
 
@@ -115,12 +101,35 @@ func (p *VertexProvider) endpoint() string {
 		p.region, p.project, p.region, p.model)
 }
 
-// getAccessToken obtains an OAuth2 token via Application Default Credentials.
-func (p *VertexProvider) getAccessToken(ctx context.Context) (string, error) {
-	ts, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
-	if err != nil {
-		return "", fmt.Errorf("get default token source: %w (hint: run 'gcloud auth application-default login' or set GOOGLE_APPLICATION_CREDENTIALS)", err)
+// vertexTokenEarlyRefresh is how long before expiry a cached token is
+// replaced, so a long batch request never starts with a token about to lapse.
+const vertexTokenEarlyRefresh = 5 * time.Minute
+
+// The ADC token source is shared by every VertexProvider in the process and
+// reuses its token until near expiry. Without it each request re-ran
+// credential discovery and fetched a new token from the metadata server.
+var (
+	vertexTokenMu      sync.Mutex
+	vertexTokenSource  oauth2.TokenSource
+	vertexTokenResetAt time.Time
+)
+
+// vertexAccessToken returns a cached OAuth2 token from Application Default
+// Credentials, fetching a new one when none is cached or it is near expiry.
+func vertexAccessToken(ctx context.Context) (string, error) {
+	vertexTokenMu.Lock()
+	if vertexTokenSource == nil {
+		// Refreshes happen on later calls, after ctx may be gone.
+		ts, err := google.DefaultTokenSource(context.WithoutCancel(ctx), "https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			vertexTokenMu.Unlock()
+			return "", fmt.Errorf("get default token source: %w (hint: run 'gcloud auth application-default login' or set GOOGLE_APPLICATION_CREDENTIALS)", err)
+		}
+		vertexTokenSource = oauth2.ReuseTokenSourceWithExpiry(nil, ts, vertexTokenEarlyRefresh)
 	}
+	ts := vertexTokenSource
+	vertexTokenMu.Unlock()
+
 	token, err := ts.Token()
 	if err != nil {
 		return "", fmt.Errorf("get access token: %w", err)
@@ -128,6 +137,21 @@ func (p *VertexProvider) getAccessToken(ctx context.Context) (string, error) {
 	return token.AccessToken, nil
 }
 
+// resetVertexToken drops the cached token source, so the next request
+// rediscovers credentials and fetches a fresh token. It reports false, doing
+// nothing, if a reset already happened in the last minute: a fresh token
+// that is also rejected is a permissions problem, not a stale token.
+func resetVertexToken() bool {
+	vertexTokenMu.Lock()
+	defer vertexTokenMu.Unlock()
+	if time.Since(vertexTokenResetAt) < time.Minute {
+		return false
+	}
+	vertexTokenSource = nil
+	vertexTokenResetAt = time.Now()
+	return true
+}
+
 // Synthesize does single-speaker synthesis for one segment.
 func (p *VertexProvider) Synthesize(ctx context.Context, text string, voice Voice) (AudioResult, error) {
 	req := geminiRequest{
@@ -144,75 +168,53 @@ func (p *VertexProvider) Synthesize(ctx context.Context, text string, voice Voic
 		},
 	}
 
-	data, err := p.doRequest(ctx, req, p.httpClient)
-	if err != nil {
+	var buf bytes.Buffer
+	if _, err := p.doRequest(ctx, req, p.httpClient, &buf); err != nil {
 		return AudioResult{}, err
 	}
 
-	return AudioResult{Data: data, Format: FormatPCM}, nil
+	return AudioResult{Data: buf.Bytes(), Format: FormatPCM}, nil
 }
 
 // SynthesizeBatch sends the entire script as a multi-speaker dialogue.
+// Long scripts should go through SynthesizeBatchChunked.
 func (p *VertexProvider) SynthesizeBatch(ctx context.Context, segments []script.Segment, voices VoiceMap) (AudioResult, error) {
-	var dialogue string
-	for _, seg := range segments {
-		dialogue += fmt.Sprintf("%s: %s\n", seg.Speaker, seg.Text)
+	var buf bytes.Buffer
+	format, err := p.StreamBatch(ctx, segments, voices, &buf)
+	if err != nil {
+		return AudioResult{}, err
 	}
+	return AudioResult{Data: buf.Bytes(), Format: format}, nil
+}
 
-	seen := map[string]bool{}
-	var speakerConfigs []geminiSpeakerVoiceConfig
-	for _, seg := range segments {
-		if seen[seg.Speaker] {
-			continue
-		}
-		seen[seg.Speaker] = true
-		voice := VoiceForSpeaker(seg.Speaker, voices)
-		speakerConfigs = append(speakerConfigs, geminiSpeakerVoiceConfig{
-			Speaker: seg.Speaker,
-			VoiceConfig: geminiVoiceConfig{
-				PrebuiltVoiceConfig: geminiPrebuiltVoice{VoiceName: voice.ID},
-			},
-		})
-	}
+// StreamBatch is SynthesizeBatch writing the audio to w as it is decoded.
+func (p *VertexProvider) StreamBatch(ctx context.Context, segments []script.Segment, voices VoiceMap, w io.Writer) (AudioFormat, error) {
+	req, speakers, chars := batchRequest(segments, voices, "user", p.styleTone)
 
 	fmt.Fprintf(os.Stderr, "[vertex-batch] Starting batch TTS: segments=%d speakers=%d chars=%d model=%s\n",
-		len(segments), len(speakerConfigs), len(dialogue), p.model)
+		len(segments), speakers, chars, p.model)
 	start := time.Now()
 
-	req := geminiRequest{
-		Contents: []geminiContent{
-			{Role: "user", Parts: []geminiPart{{Text: dialogue}}},
-		},
-		GenerationConfig: geminiGenConfig{
-			ResponseModalities: []string{"AUDIO"},
-			SpeechConfig: geminiSpeechConfig{
-				MultiSpeakerVoiceConfig: &geminiMultiSpeakerConfig{
-					SpeakerVoiceConfigs: speakerConfigs,
-				},
-			},
-		},
-	}
-
-	data, err := p.doRequest(ctx, req, p.batchHTTPClient)
+	n, err := p.doRequest(ctx, req, p.batchHTTPClient, w)
 	elapsed := time.Since(start).Round(time.Millisecond)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[vertex-batch] FAILED after %s: %v\n", elapsed, err)
-		return AudioResult{}, err
+		return "", err
 	}
 
-	fmt.Fprintf(os.Stderr, "[vertex-batch] SUCCESS in %s: audio_bytes=%d\n", elapsed, len(data))
-	return AudioResult{Data: data, Format: FormatPCM}, nil
+	fmt.Fprintf(os.Stderr, "[vertex-batch] SUCCESS in %s: audio_bytes=%d\n", elapsed, n)
+	return FormatPCM, nil
 }
 
-func (p *VertexProvider) doRequest(ctx context.Context, reqBody geminiRequest, client *http.Client) ([]byte, error) {
+func (p *VertexProvider) doRequest(ctx context.Context, reqBody geminiRequest, client *http.Client, w io.Writer) (int64, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("marshal Vertex request: %w", err)
+		return 0, fmt.Errorf("marshal Vertex request: %w", err)
 	}
 
-	token, err := p.getAccessToken(ctx)
+	token, err := vertexAccessToken(ctx)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	url := p.endpoint()
@@ -220,7 +222,7 @@ func (p *VertexProvider) doRequest(ctx context.Context, reqBody geminiRequest, c
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return 0, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -232,8 +234,9 @@ func (p *VertexProvider) doRequest(ctx context.Context, reqBody geminiRequest, c
 	res, err := client.Do(req)
 	elapsed := time.Since(start).Round(time.Millisecond)
 	if err != nil {
+		err = transportCause(err)
 		fmt.Fprintf(os.Stderr, "[vertex] HTTP error after %s: %v\n", elapsed, err)
-		return nil, &RetryableError{StatusCode: 0, Body: fmt.Sprintf("network error after %s: %v", elapsed, err)}
+		return 0, &RetryableError{StatusCode: 0, Body: fmt.Sprintf("network error after %s: %v", elapsed, err)}
 	}
 	defer res.Body.Close()
 
@@ -253,46 +256,36 @@ func (p *VertexProvider) doRequest(ctx context.Context, reqBody geminiRequest, c
 			}
 		}
 
-		return nil, &RetryableError{
+		return 0, &RetryableError{
 			StatusCode: res.StatusCode,
 			Body:       bodyStr,
 			RetryAfter: retryAfter,
 		}
 	}
 
+	// A rejected token (revoked, or rotated credentials) is dropped from the
+	// cache and the request retried with a fresh one.
+	if res.StatusCode == http.StatusUnauthorized && resetVertexToken() {
+		errBody, _ := io.ReadAll(res.Body)
+		fmt.Fprintf(os.Stderr, "[vertex] Token rejected (401); refreshing credentials\n")
+		return 0, &RetryableError{StatusCode: res.StatusCode, Body: string(errBody)}
+	}
+
 	if res.StatusCode != http.StatusOK {
 		errBody, _ := io.ReadAll(res.Body)
 		bodyStr := string(errBody)
 		fmt.Fprintf(os.Stderr, "[vertex] API error %d: %s\n", res.StatusCode, bodyStr[:min(200, len(bodyStr))])
-		return nil, fmt.Errorf("Vertex AI API error (status %d): %s", res.StatusCode, bodyStr)
-	}
-
-	respBody, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read Vertex response: %w", err)
-	}
-
-	fmt.Fprintf(os.Stderr, "[vertex] Response body read: %d bytes in %s\n", len(respBody), time.Since(start).Round(time.Millisecond))
-
-	var resp geminiResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("parse Vertex response: %w", err)
-	}
-
-	if len(resp.Candidates) == 0 ||
-		len(resp.Candidates[0].Content.Parts) == 0 ||
-		resp.Candidates[0].Content.Parts[0].InlineData == nil {
-		return nil, &RetryableError{StatusCode: 200, Body: "Vertex response contained no audio data"}
+		return 0, statusError("gemini-vertex", res.StatusCode, bodyStr,
+			fmt.Errorf("Vertex AI API error (status %d): %s", res.StatusCode, bodyStr))
 	}
 
-	audioB64 := resp.Candidates[0].Content.Parts[0].InlineData.Data
-	audioBytes, err := base64.StdEncoding.DecodeString(audioB64)
+	n, err := streamInlineAudio(res.Body, w)
 	if err != nil {
-		return nil, fmt.Errorf("decode Vertex audio base64: %w", err)
+		return n, err
 	}
 
-	fmt.Fprintf(os.Stderr, "[vertex] Audio decoded: %d bytes (base64: %d)\n", len(audioBytes), len(audioB64))
-	return audioBytes, nil
+	fmt.Fprintf(os.Stderr, "[vertex] Audio decoded: %d bytes in %s\n", n, time.Since(start).Round(time.Millisecond))
+	return n, nil
 }
 
 func (p *VertexProvider) Close() error { return nil }
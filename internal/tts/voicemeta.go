@@ -0,0 +1,242 @@
+package tts
+
+import (
+	"os"
+	"strings"
+)
+
+// Voice metadata beyond the one-line description: accent, apparent age,
+// and style tags, so a voice can be chosen from 30+ without auditioning
+// each. Live catalogs (ElevenLabs, Deepgram) fill these from the
+// provider's own labels; the tables below cover the built-in catalogs and
+// fallbacks. Gemini and Google Chirp 3 HD share voices, so Google's are
+// looked up by name in geminiVoiceMeta.
+
+// voiceMeta is the catalog metadata for one voice.
+type voiceMeta struct {
+	accent string
+	age    string // "young", "middle-aged", or "old"
+	tags   []string
+}
+
+var geminiVoiceMeta = map[string]voiceMeta{
+	"Achernar":      {"American", "young", []string{"soft", "gentle", "calm"}},
+	"Achird":        {"American", "young", []string{"friendly", "conversational", "warm"}},
+	"Algenib":       {"American", "middle-aged", []string{"gravelly", "deep", "textured"}},
+	"Algieba":       {"American", "middle-aged", []string{"smooth", "relaxed", "narrator"}},
+	"Alnilam":       {"American", "middle-aged", []string{"firm", "authoritative", "news"}},
+	"Aoede":         {"American", "young", []string{"breezy", "light", "conversational"}},
+	"Autonoe":       {"American", "young", []string{"bright", "clear", "energetic"}},
+	"Callirrhoe":    {"American", "middle-aged", []string{"easy-going", "relaxed", "conversational"}},
+	"Charon":        {"American", "middle-aged", []string{"informative", "clear", "narrator"}},
+	"Despina":       {"American", "young", []string{"smooth", "warm", "polished"}},
+	"Enceladus":     {"American", "middle-aged", []string{"breathy", "soft", "intimate"}},
+	"Erinome":       {"American", "young", []string{"clear", "precise", "informative"}},
+	"Fenrir":        {"American", "young", []string{"excitable", "energetic", "animated"}},
+	"Gacrux":        {"American", "old", []string{"mature", "warm", "storyteller"}},
+	"Iapetus":       {"American", "middle-aged", []string{"clear", "even", "narrator"}},
+	"Kore":          {"American", "middle-aged", []string{"firm", "confident", "authoritative"}},
+	"Laomedeia":     {"American", "young", []string{"upbeat", "cheerful", "energetic"}},
+	"Leda":          {"American", "young", []string{"youthful", "bright", "friendly"}},
+	"Orus":          {"American", "middle-aged", []string{"firm", "steady", "warm"}},
+	"Puck":          {"American", "young", []string{"upbeat", "playful", "energetic"}},
+	"Pulcherrima":   {"American", "young", []string{"forward", "expressive", "confident"}},
+	"Rasalgethi":    {"American", "middle-aged", []string{"informative", "professional", "narrator"}},
+	"Sadachbia":     {"American", "young", []string{"lively", "animated", "energetic"}},
+	"Sadaltager":    {"American", "middle-aged", []string{"knowledgeable", "measured", "professional"}},
+	"Schedar":       {"American", "middle-aged", []string{"even", "calm", "neutral"}},
+	"Sulafat":       {"American", "middle-aged", []string{"warm", "friendly", "reassuring"}},
+	"Umbriel":       {"American", "middle-aged", []string{"easy-going", "casual", "conversational"}},
+	"Vindemiatrix":  {"American", "middle-aged", []string{"gentle", "soft", "calm"}},
+	"Zephyr":        {"American", "young", []string{"bright", "breezy", "cheerful"}},
+	"Zubenelgenubi": {"American", "middle-aged", []string{"casual", "laid-back", "conversational"}},
+}
+
+// providerVoiceMeta covers the other built-in catalogs, by provider and
+// voice ID.
+var providerVoiceMeta = map[string]map[string]voiceMeta{
+	"polly": {
+		"Matthew":  {"American", "middle-aged", []string{"clear", "professional", "narrator"}},
+		"Ruth":     {"American", "middle-aged", []string{"warm", "conversational", "friendly"}},
+		"Amy":      {"British", "middle-aged", []string{"clear", "polished", "professional"}},
+		"Stephen":  {"American", "middle-aged", []string{"steady", "calm", "informative"}},
+		"Danielle": {"American", "young", []string{"bright", "friendly", "conversational"}},
+		"Olivia":   {"Australian", "young", []string{"warm", "relaxed", "friendly"}},
+		"Kajal":    {"Indian", "young", []string{"clear", "friendly", "professional"}},
+	},
+	"elevenlabs": {
+		"R1iO02imWa46t8ckcxFN": {"American", "middle-aged", []string{"casual", "energetic", "conversational"}},
+		"56bWURjYFHyYyVf490Dp": {"Australian", "young", []string{"warm", "friendly", "conversational"}},
+		"iWP0zWXsAkUmG0R4IMeO": {"American", "old", []string{"masculine", "iconic", "storyteller"}},
+		"4YYIPFl9wE5c4L2eu2Gb": {"American", "old", []string{"deep", "smooth", "clear"}},
+		"UgBBYS2sOqTuMpoF3BR0": {"American", "middle-aged", []string{"natural", "conversational", "relaxed"}},
+		"JBFqnCBsd6RMkjVDRZzb": {"British", "middle-aged", []string{"warm", "storyteller", "narrator"}},
+		"EXAVITQu4vr4xnSDxMaL": {"American", "young", []string{"soft", "reassuring", "news"}},
+		"pNInz6obpgDQGcFmaJgB": {"American", "middle-aged", []string{"deep", "narrator", "authoritative"}},
+		"onwK4e9ZLuTAKqWW03F9": {"British", "middle-aged", []string{"authoritative", "news", "professional"}},
+		"pFZP5JQG7iQjIQuC4Bku": {"British", "middle-aged", []string{"warm", "narrator", "gentle"}},
+	},
+	"cartesia": {
+		"228fca29-3a0a-435c-8728-5cb483251068": {"American", "middle-aged", []string{"calm", "steady", "narrator"}},
+		"f786b574-daa5-4673-aa0c-cbe3e8534c02": {"American", "young", []string{"friendly", "conversational", "bright"}},
+		"694f9389-aac1-45b6-b726-9d9369183238": {"American", "young", []string{"soft", "clear", "gentle"}},
+		"a0e99841-438c-4a64-b679-ae501e7d6091": {"American", "middle-aged", []string{"casual", "warm", "conversational"}},
+		"79a125e8-cd45-4c13-8a67-188112f4dd22": {"British", "middle-aged", []string{"elegant", "polished", "narrator"}},
+	},
+	"hume": {
+		"Colton Rivers":        {"American", "young", []string{"warm", "easygoing", "conversational"}},
+		"Ava Song":             {"American", "young", []string{"bright", "expressive", "narrator"}},
+		"Vince Douglas":        {"American", "middle-aged", []string{"deep", "dramatic", "storyteller"}},
+		"Kora":                 {"American", "young", []string{"calm", "clear", "gentle"}},
+		"Dacher":               {"American", "middle-aged", []string{"thoughtful", "conversational", "measured"}},
+		"Ito":                  {"American", "middle-aged", []string{"gentle", "soft-spoken", "calm"}},
+		"Literature Professor": {"British", "old", []string{"erudite", "lecturer", "measured"}},
+		"Sitcom Girl":          {"American", "young", []string{"bubbly", "comedic", "energetic"}},
+	},
+	"deepgram": {
+		"apollo":    {"American", "middle-aged", []string{"confident", "casual", "comfortable"}},
+		"thalia":    {"American", "young", []string{"clear", "energetic", "enthusiastic"}},
+		"arcas":     {"American", "middle-aged", []string{"natural", "smooth", "clear"}},
+		"andromeda": {"American", "young", []string{"casual", "expressive", "comfortable"}},
+		"asteria":   {"American", "middle-aged", []string{"clear", "confident", "knowledgeable"}},
+		"luna":      {"American", "young", []string{"friendly", "natural", "engaging"}},
+		"orion":     {"American", "middle-aged", []string{"approachable", "calm", "polite"}},
+		"orpheus":   {"American", "middle-aged", []string{"professional", "trustworthy", "warm"}},
+		"zeus":      {"American", "middle-aged", []string{"deep", "trustworthy", "smooth"}},
+		"draco":     {"British", "middle-aged", []string{"warm", "trustworthy", "baritone"}},
+		"pandora":   {"British", "middle-aged", []string{"smooth", "calm", "melodic"}},
+		"hyperion":  {"Australian", "middle-aged", []string{"caring", "warm", "empathetic"}},
+		"theia":     {"Australian", "young", []string{"expressive", "polite", "sincere"}},
+	},
+}
+
+// regionAccents names the accent of a voice's language region when the
+// catalog doesn't say.
+var regionAccents = map[string]string{
+	"US": "American",
+	"GB": "British",
+	"AU": "Australian",
+	"IN": "Indian",
+	"IE": "Irish",
+	"CA": "Canadian",
+	"NZ": "New Zealand",
+	"ZA": "South African",
+}
+
+// normalizeAge maps a provider's age label onto VoiceInfo.Age's values,
+// or "" if it isn't one we know.
+func normalizeAge(label string) string {
+	switch strings.ToLower(strings.NewReplacer("_", " ", "-", " ").Replace(strings.TrimSpace(label))) {
+	case "young", "young adult":
+		return "young"
+	case "adult", "middle aged":
+		return "middle-aged"
+	case "mature", "old", "senior":
+		return "old"
+	}
+	return ""
+}
+
+// capitalize upper-cases the first letter of each word ("american" ->
+// "American").
+func capitalize(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// VoiceSamplesURLEnv names the base URL of hosted voice samples, laid out
+// as <base>/<provider>/<voice ID>.mp3 (see VoiceSampleURL).
+const VoiceSamplesURLEnv = "PODCASTER_VOICE_SAMPLES_URL"
+
+// VoiceSampleURL returns the hosted sample clip for a voice, or "" if no
+// sample host is configured. Gemini-family voices share one set of samples.
+func VoiceSampleURL(provider, voiceID string) string {
+	base := strings.TrimRight(os.Getenv(VoiceSamplesURLEnv), "/")
+	if base == "" {
+		return ""
+	}
+	switch provider {
+	case "gemini-vertex", "vertex-express":
+		provider = "gemini"
+	}
+	return base + "/" + provider + "/" + SampleFileName(voiceID)
+}
+
+// SampleFileName is a voice's sample file name under its provider's
+// directory: the ID with characters awkward in URLs and file names
+// replaced.
+func SampleFileName(voiceID string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, voiceID) + ".mp3"
+}
+
+// enrichVoices fills in the metadata a provider's catalog left empty: the
+// tables above, then the accent implied by the language region, then the
+// hosted sample URL.
+func enrichVoices(provider string, voices []VoiceInfo) []VoiceInfo {
+	for i := range voices {
+		v := &voices[i]
+		var meta voiceMeta
+		var ok bool
+		switch provider {
+		case "gemini", "gemini-vertex", "vertex-express", "google":
+			meta, ok = geminiVoiceMeta[v.Name]
+		default:
+			meta, ok = providerVoiceMeta[provider][v.ID]
+		}
+		if ok {
+			if v.Accent == "" {
+				v.Accent = meta.accent
+			}
+			if v.Age == "" {
+				v.Age = meta.age
+			}
+			if len(v.Tags) == 0 {
+				v.Tags = meta.tags
+			}
+		}
+		if v.Accent == "" {
+			if _, region, found := strings.Cut(v.Language, "-"); found {
+				v.Accent = regionAccents[strings.ToUpper(region)]
+			}
+		}
+		if v.SampleURL == "" {
+			v.SampleURL = VoiceSampleURL(provider, v.ID)
+		}
+	}
+	return voices
+}
+
+// Summary returns a one-line description of the voice for pickers and
+// listings: description, then accent, age, and tags where they add to it.
+func (v VoiceInfo) Summary() string {
+	parts := []string{v.Description}
+	lower := strings.ToLower(v.Description)
+	if v.Accent != "" && !strings.Contains(lower, strings.ToLower(v.Accent)) {
+		parts = append(parts, v.Accent)
+	}
+	if v.Age != "" {
+		parts = append(parts, v.Age)
+	}
+	for _, t := range v.Tags {
+		if !strings.Contains(lower, strings.ToLower(t)) {
+			parts = append(parts, t)
+		}
+	}
+	var out []string
+	for _, p := range parts {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, ", ")
+}
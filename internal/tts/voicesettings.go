@@ -0,0 +1,163 @@
+package tts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VoiceSettings are per-voice overrides of the provider-wide speed,
+// stability, and pitch from ProviderConfig, so hosts can have distinct
+// deliveries. A zero Speed and nil Stability or Pitch inherit the provider
+// setting; stability and pitch are pointers because 0 is a valid override.
+type VoiceSettings struct {
+	Speed     float64
+	Stability *float64 // ElevenLabs only
+	Pitch     *float64 // semitones; native on Google, emulated elsewhere
+}
+
+// IsZero reports whether no override is set.
+func (s VoiceSettings) IsZero() bool {
+	return s.Speed == 0 && s.Stability == nil && s.Pitch == nil
+}
+
+// String formats the settings as a voice spec suffix ("@speed=1.1,stability=0.3"),
+// or "" when none are set.
+func (s VoiceSettings) String() string {
+	var parts []string
+	add := func(key string, val float64) {
+		parts = append(parts, key+"="+strconv.FormatFloat(val, 'f', -1, 64))
+	}
+	if s.Speed != 0 {
+		add("speed", s.Speed)
+	}
+	if s.Stability != nil {
+		add("stability", *s.Stability)
+	}
+	if s.Pitch != nil {
+		add("pitch", *s.Pitch)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "@" + strings.Join(parts, ",")
+}
+
+// ParseVoiceSettings splits per-voice settings off a voice spec:
+// "elevenlabs:rachel@stability=0.3,speed=1.1" returns "elevenlabs:rachel"
+// and the settings. A spec without "@" is returned unchanged.
+func ParseVoiceSettings(spec string) (string, VoiceSettings, error) {
+	i := strings.LastIndex(spec, "@")
+	if i < 0 {
+		return spec, VoiceSettings{}, nil
+	}
+	var s VoiceSettings
+	for _, kv := range strings.Split(spec[i+1:], ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			return "", VoiceSettings{}, fmt.Errorf("invalid voice setting %q in %q: use key=value (speed, stability, pitch)", kv, spec)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil {
+			return "", VoiceSettings{}, fmt.Errorf("invalid value for %s in %q: %w", key, spec, err)
+		}
+		switch strings.TrimSpace(key) {
+		case "speed":
+			s.Speed = f
+		case "stability":
+			s.Stability = &f
+		case "pitch":
+			s.Pitch = &f
+		default:
+			return "", VoiceSettings{}, fmt.Errorf("unknown voice setting %q in %q: use speed, stability, or pitch", key, spec)
+		}
+	}
+	return spec[:i], s, nil
+}
+
+// Speed and pitch limits when the pipeline emulates them with FFmpeg for a
+// provider that can't apply them itself (see Emulated). Beyond an octave
+// either way, pitch shifting sounds artificial.
+const (
+	EmulatedSpeedMin = 0.5
+	EmulatedSpeedMax = 2.0
+	EmulatedPitchMax = 12.0
+)
+
+// NativeSpeed reports whether the provider applies speed itself.
+func NativeSpeed(provider string) bool {
+	return provider == "elevenlabs" || provider == "google"
+}
+
+// NativePitch reports whether the provider applies pitch itself.
+func NativePitch(provider string) bool {
+	return provider == "google"
+}
+
+// Emulated returns the speed and pitch in cfg that the provider can't apply
+// natively, for the pipeline to apply with FFmpeg after synthesis (0 = none).
+func Emulated(provider string, cfg ProviderConfig) (speed, pitch float64) {
+	if !NativeSpeed(provider) {
+		speed = cfg.Speed
+	}
+	if !NativePitch(provider) {
+		pitch = cfg.Pitch
+	}
+	return speed, pitch
+}
+
+// Validate checks the settings against what the provider supports, using
+// the same ranges as --tts-speed, --tts-stability, and --tts-pitch. Speed
+// and pitch the provider lacks are emulated, within narrower ranges.
+func (s VoiceSettings) Validate(provider string) error {
+	if s.Speed != 0 {
+		switch provider {
+		case "elevenlabs":
+			if s.Speed < 0.7 || s.Speed > 1.2 {
+				return fmt.Errorf("speed for ElevenLabs must be between 0.7 and 1.2 (got %.2f)", s.Speed)
+			}
+		case "google":
+			if s.Speed < 0.25 || s.Speed > 2.0 {
+				return fmt.Errorf("speed for Google must be between 0.25 and 2.0 (got %.2f)", s.Speed)
+			}
+		default:
+			if s.Speed < EmulatedSpeedMin || s.Speed > EmulatedSpeedMax {
+				return fmt.Errorf("speed for %s must be between %.1f and %.1f (got %.2f)", provider, EmulatedSpeedMin, EmulatedSpeedMax, s.Speed)
+			}
+		}
+	}
+	if s.Stability != nil {
+		if provider != "elevenlabs" {
+			return fmt.Errorf("stability is only supported by ElevenLabs")
+		}
+		if *s.Stability < 0 || *s.Stability > 1.0 {
+			return fmt.Errorf("stability must be between 0.0 and 1.0 (got %.2f)", *s.Stability)
+		}
+	}
+	if s.Pitch != nil {
+		pitch := *s.Pitch
+		if NativePitch(provider) {
+			if pitch < -20.0 || pitch > 20.0 {
+				return fmt.Errorf("pitch must be between -20.0 and 20.0 (got %.2f)", pitch)
+			}
+		} else if pitch < -EmulatedPitchMax || pitch > EmulatedPitchMax {
+			return fmt.Errorf("pitch for %s must be between %.1f and %.1f semitones (got %.2f)", provider, -EmulatedPitchMax, EmulatedPitchMax, pitch)
+		}
+	}
+	return nil
+}
+
+// ForVoice returns cfg with the voice's overrides applied. Use it wherever
+// the effective settings matter, such as cache keys.
+func (cfg ProviderConfig) ForVoice(v Voice) ProviderConfig {
+	if v.Settings.Speed != 0 {
+		cfg.Speed = v.Settings.Speed
+	}
+	if v.Settings.Stability != nil {
+		cfg.Stability = *v.Settings.Stability
+	}
+	if v.Settings.Pitch != nil {
+		cfg.Pitch = *v.Settings.Pitch
+	}
+	return cfg
+}
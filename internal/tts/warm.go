@@ -0,0 +1,86 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	texttospeech "cloud.google.com/go/texttospeech/apiv1"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// Clients that don't depend on a per-request API key are created once per
+// process and shared by every provider instance, so a long-running server
+// pays their setup (credential discovery, gRPC dial) once rather than per
+// job. Warm creates them ahead of the first job.
+var (
+	sharedMu           sync.Mutex
+	sharedGoogleClient *texttospeech.Client
+	sharedAWSConfig    *aws.Config
+)
+
+// googleClient returns the process-wide Google Cloud TTS client. A failed
+// creation is not cached, so a later call can succeed.
+func googleClient(ctx context.Context) (*texttospeech.Client, error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if sharedGoogleClient == nil {
+		// The client outlives the caller, so it must not inherit its cancellation.
+		client, err := texttospeech.NewClient(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("create Google TTS client: %w", err)
+		}
+		sharedGoogleClient = client
+	}
+	return sharedGoogleClient, nil
+}
+
+// awsConfig returns the process-wide AWS config used by Polly, with
+// credentials already resolved once it has been warmed.
+func awsConfig(ctx context.Context) (aws.Config, error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if sharedAWSConfig == nil {
+		cfg, err := config.LoadDefaultConfig(ctx)
+		if err != nil {
+			return aws.Config{}, fmt.Errorf("load AWS config for Polly: %w", err)
+		}
+		sharedAWSConfig = &cfg
+	}
+	return *sharedAWSConfig, nil
+}
+
+// Warm initializes the shared state the named providers need before their
+// first request: the Google TTS client, the AWS config and credentials for
+// Polly, and the cached ADC access token for gemini-vertex. Providers without such
+// state are ignored. Errors are joined; a failed warm-up only means the
+// first job does the work instead.
+func Warm(ctx context.Context, providers ...string) error {
+	var errs []error
+	for _, name := range providers {
+		var err error
+		switch name {
+		case "google":
+			_, err = googleClient(ctx)
+		case "polly":
+			var cfg aws.Config
+			if cfg, err = awsConfig(ctx); err == nil {
+				_, err = cfg.Credentials.Retrieve(ctx)
+			}
+		case "gemini-vertex":
+			if os.Getenv("GCP_PROJECT") == "" {
+				continue
+			}
+			_, err = vertexAccessToken(ctx)
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("warm %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
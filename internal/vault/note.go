@@ -0,0 +1,123 @@
+// Package vault turns flagged notes in a Markdown vault (Obsidian and the
+// like) into episodes: `podcaster watch` scans a directory for notes whose
+// front matter carries a tag, generates an episode from each one's text,
+// and writes a link to it back into the note.
+package vault
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Marker starts the episode link written into a note. A note containing it
+// is done; deleting the marker and link asks for a new episode.
+const Marker = "<!-- podcaster -->"
+
+// Note is a Markdown note split into its front matter and body.
+type Note struct {
+	Path        string
+	FrontMatter map[string]any // nil without front matter
+	Body        string
+}
+
+// ParseNote splits data, the contents of the note at path, into front
+// matter (a YAML block between --- lines at the very top) and body.
+func ParseNote(path string, data []byte) (Note, error) {
+	n := Note{Path: path, Body: string(data)}
+	text := string(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")))
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return n, nil
+	}
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return n, nil
+	}
+	after := rest[end+len("\n---"):]
+	if after != "" && after[0] != '\n' {
+		return n, nil // "---" starting some other line
+	}
+	if err := yaml.Unmarshal([]byte(rest[:end]), &n.FrontMatter); err != nil {
+		return Note{}, fmt.Errorf("%s: front matter: %w", path, err)
+	}
+	n.Body = strings.TrimPrefix(after, "\n")
+	return n, nil
+}
+
+// Flagged reports whether the note's front matter asks for an episode with
+// tag: in its tags (a list or a comma-separated string, with or without
+// #), or as a key set to true ("podcast: true").
+func (n Note) Flagged(tag string) bool {
+	if v, ok := n.FrontMatter[tag].(bool); ok && v {
+		return true
+	}
+	var tags []string
+	switch v := n.FrontMatter["tags"].(type) {
+	case string:
+		tags = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	case []any:
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	}
+	for _, t := range tags {
+		if strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(t), "#"), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Done reports whether an episode link has been written into the note.
+func (n Note) Done() bool {
+	return strings.Contains(n.Body, Marker)
+}
+
+// Title returns the note's title: its front matter title, else its file
+// name.
+func (n Note) Title() string {
+	if t, ok := n.FrontMatter["title"].(string); ok && strings.TrimSpace(t) != "" {
+		return strings.TrimSpace(t)
+	}
+	return strings.TrimSuffix(filepath.Base(n.Path), filepath.Ext(n.Path))
+}
+
+// Source returns the text an episode is generated from: the title as a
+// heading (the text ingester titles content by its first line) and the
+// body.
+func (n Note) Source() string {
+	return "# " + n.Title() + "\n\n" + strings.TrimSpace(n.Body) + "\n"
+}
+
+// Episode is a generated episode, as linked from its note.
+type Episode struct {
+	Path     string // absolute
+	Title    string
+	Duration string // e.g. "8:32"; may be empty
+	Created  time.Time
+}
+
+// AppendEpisode returns data, a note's contents, with a link to ep
+// appended after Marker.
+func AppendEpisode(data []byte, ep Episode) []byte {
+	link := (&url.URL{Scheme: "file", Path: filepath.ToSlash(ep.Path)}).String()
+	details := ep.Created.Format("2006-01-02 15:04")
+	if ep.Duration != "" {
+		details = ep.Duration + ", " + details
+	}
+	// Brackets would end the link text early.
+	title := strings.NewReplacer("[", "(", "]", ")").Replace(ep.Title)
+
+	var b bytes.Buffer
+	b.Write(bytes.TrimRight(data, "\n"))
+	fmt.Fprintf(&b, "\n\n%s\n🎧 **Podcast:** [%s](%s) (%s)\n", Marker, title, link, details)
+	return b.Bytes()
+}
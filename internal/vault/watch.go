@@ -0,0 +1,176 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apresai/podcaster/internal/pipeline"
+	"github.com/apresai/podcaster/internal/schedule"
+	"github.com/apresai/podcaster/internal/script"
+)
+
+// DefaultSettle is how long a note must go unmodified before it is
+// generated from, so a note still being written isn't picked up half done.
+const DefaultSettle = 30 * time.Second
+
+// Watcher generates episodes for a vault's flagged notes. Like scheduled
+// shows, each episode is a generate run of the podcaster binary, so it
+// matches the same command typed by hand and lands in the history.
+type Watcher struct {
+	Dir    string   // vault root, scanned recursively
+	Tag    string   // front matter flag, e.g. "podcast"
+	Args   []string // generate flags for every episode
+	Settle time.Duration
+	Runner schedule.Runner
+	Log    io.Writer
+
+	// failed holds notes whose generation failed, or whose episode
+	// couldn't be linked, by modification time, so they are retried only
+	// once edited.
+	failed map[string]time.Time
+}
+
+// Scan generates an episode for each flagged note not yet done, one at a
+// time, and returns how many were generated.
+func (w *Watcher) Scan(ctx context.Context) (int, error) {
+	if w.failed == nil {
+		w.failed = make(map[string]time.Time)
+	}
+	var pending []string
+	err := filepath.WalkDir(w.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Skip .obsidian, .git, .trash, and the like.
+			if path != w.Dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".md") {
+			pending = append(pending, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("scan %s: %w", w.Dir, err)
+	}
+
+	generated := 0
+	for _, path := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		ok, err := w.process(ctx, path)
+		if err != nil {
+			fmt.Fprintf(w.Log, "[%s] %s: %v\n", time.Now().Format("15:04:05"), path, err)
+		}
+		if ok {
+			generated++
+		}
+	}
+	return generated, ctx.Err()
+}
+
+// process generates an episode for the note at path if it is flagged, not
+// done, settled, and hasn't failed since it was last modified.
+func (w *Watcher) process(ctx context.Context, path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if time.Since(info.ModTime()) < w.Settle {
+		return false, nil
+	}
+	if t, ok := w.failed[path]; ok && t.Equal(info.ModTime()) {
+		return false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	note, err := ParseNote(path, data)
+	if err != nil {
+		w.failed[path] = info.ModTime()
+		return false, err
+	}
+	if !note.Flagged(w.Tag) || note.Done() {
+		return false, nil
+	}
+
+	fmt.Fprintf(w.Log, "[%s] Generating episode for %s\n", time.Now().Format("15:04:05"), path)
+	ep, err := w.generate(ctx, note)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.failed[path] = info.ModTime()
+		}
+		return false, fmt.Errorf("generate: %w", err)
+	}
+	if err := writeBack(path, ep); err != nil {
+		// The episode exists; don't generate it again until the note is
+		// edited.
+		w.failed[path] = info.ModTime()
+		if cur, serr := os.Stat(path); serr == nil {
+			w.failed[path] = cur.ModTime()
+		}
+		return true, fmt.Errorf("%w (episode at %s)", err, ep.Path)
+	}
+	fmt.Fprintf(w.Log, "[%s] %s: linked %s\n", time.Now().Format("15:04:05"), path, ep.Path)
+	return true, nil
+}
+
+// generate runs generate on the note's text and returns the episode.
+func (w *Watcher) generate(ctx context.Context, note Note) (Episode, error) {
+	now := time.Now()
+	name := pipeline.AutoOutputName(note.Title(), schedule.OutputFormat(w.Args))
+
+	dir := filepath.Join(w.Runner.OutputDir, "vault")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Episode{}, fmt.Errorf("create vault directory: %w", err)
+	}
+	input := filepath.Join(dir, strings.TrimSuffix(name, filepath.Ext(name))+".md")
+	if err := os.WriteFile(input, []byte(note.Source()), 0644); err != nil {
+		return Episode{}, fmt.Errorf("write note text: %w", err)
+	}
+	// generate writes every episode to episodes/ under the output dir.
+	output := filepath.Join(w.Runner.OutputDir, "episodes", name)
+
+	args := append([]string{"generate", "--input", input, "--output", output}, w.Args...)
+	if err := w.Runner.Exec(ctx, args...); err != nil {
+		return Episode{}, err
+	}
+
+	abs, err := filepath.Abs(output)
+	if err != nil {
+		return Episode{}, err
+	}
+	ep := Episode{Path: abs, Title: note.Title(), Duration: pipeline.ProbeDuration(ctx, output), Created: now}
+	if s, err := script.LoadScript(pipeline.ScriptPath(output)); err == nil && s.Title != "" {
+		ep.Title = s.Title
+	}
+	return ep, nil
+}
+
+// writeBack appends the episode link to the note at path, re-reading it
+// first since it may have been edited during generation.
+func writeBack(path string, ep Episode) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("link episode: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("link episode: %w", err)
+	}
+	if err := os.WriteFile(path, AppendEpisode(data, ep), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("link episode: %w", err)
+	}
+	return nil
+}
@@ -57,7 +57,8 @@ export function PodcastAudioControls({
     setIsPlaying(true);
   };
 
-  const filename = `${title.replace(/[^a-zA-Z0-9]+/g, "-").replace(/-+$/, "").toLowerCase()}.mp3`;
+  const ext = audioUrl.match(/\.(mp3|m4a|opus|wav)(?:\?|$)/)?.[1] ?? "mp3";
+  const filename = `${title.replace(/[^a-zA-Z0-9]+/g, "-").replace(/-+$/, "").toLowerCase()}.${ext}`;
 
   return (
     <span className="inline-flex items-center gap-1">
@@ -29,9 +29,10 @@ export interface APIKey {
   userId: string;
   keyHash: string;
   name: string;
-  status: "active" | "revoked";
+  status: "active" | "revoked" | "suspended";
   createdAt: string;
   lastUsedAt?: string;
+  suspendedReason?: string;
   encryptedKey?: string;
 }
 
@@ -276,6 +277,7 @@ export async function listAPIKeys(userId: string): Promise<APIKey[]> {
     status: item.status,
     createdAt: item.createdAt,
     lastUsedAt: item.lastUsedAt,
+    suspendedReason: item.suspendedReason as string | undefined,
     encryptedKey: item.encryptedKey as string | undefined,
   }));
 }
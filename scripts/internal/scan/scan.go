@@ -0,0 +1,261 @@
+// Package scan runs resumable parallel scans of a DynamoDB table for the
+// admin scripts: one worker per scan segment, a shared write throttle, and
+// a checkpoint file recording each segment's last evaluated key.
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Parallel calls fn once per segment, each in its own goroutine, and waits
+// for them all. The first failure cancels the others' context; every
+// segment's error is returned, joined.
+func Parallel(ctx context.Context, segments int, fn func(ctx context.Context, seg int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for seg := range segments {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(ctx, seg); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("segment %d: %w", seg, err))
+				mu.Unlock()
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Segment pages through one parallel-scan segment starting after startKey
+// (nil for the beginning), calling fn for every page. input supplies the
+// table and any filter; its segment fields and start key are set here.
+func Segment(ctx context.Context, client *dynamodb.Client, input dynamodb.ScanInput, seg, total int, startKey map[string]types.AttributeValue, fn func(*dynamodb.ScanOutput) error) error {
+	input.Segment = aws.Int32(int32(seg))
+	input.TotalSegments = aws.Int32(int32(total))
+	for {
+		input.ExclusiveStartKey = startKey
+		page, err := client.Scan(ctx, &input)
+		if err != nil {
+			return fmt.Errorf("scan %s: %w", aws.ToString(input.TableName), err)
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		if len(page.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		startKey = page.LastEvaluatedKey
+	}
+}
+
+// Throttle spaces writes out to at most perSecond items per second across
+// all workers. A nil Throttle (perSecond <= 0) never waits.
+type Throttle struct {
+	mu       sync.Mutex
+	interval time.Duration // per item
+	next     time.Time     // when the next write may start
+}
+
+func NewThrottle(perSecond int) *Throttle {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &Throttle{interval: time.Second / time.Duration(perSecond)}
+}
+
+// Wait blocks until n items may be written.
+func (t *Throttle) Wait(ctx context.Context, n int) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(time.Duration(n) * t.interval)
+	t.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// Checkpoint is the resumable progress of a scan job: each segment's last
+// evaluated key, saved after every processed page. Job describes what the
+// run does (tables, filters, changes) so a checkpoint is never resumed by a
+// different job.
+type Checkpoint struct {
+	Job           string            `json:"job"`
+	TotalSegments int               `json:"totalSegments"`
+	Segments      []SegmentProgress `json:"segments"`
+
+	mu   sync.Mutex
+	path string // "" = don't persist
+}
+
+type SegmentProgress struct {
+	LastKey map[string]string `json:"lastKey,omitempty"` // string key attributes
+	Done    bool              `json:"done"`
+}
+
+// LoadCheckpoint reads the checkpoint at path, or starts a fresh one if
+// there is none. A checkpoint for another job or segment count can't be
+// resumed and is an error. An empty path keeps progress in memory only.
+func LoadCheckpoint(path, job string, segments int) (*Checkpoint, error) {
+	cp := &Checkpoint{
+		Job:           job,
+		TotalSegments: segments,
+		Segments:      make([]SegmentProgress, segments),
+		path:          path,
+	}
+	if path == "" {
+		return cp, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	var saved Checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", path, err)
+	}
+	if saved.Job != job || saved.TotalSegments != segments || len(saved.Segments) != segments {
+		return nil, fmt.Errorf("checkpoint %s is for %q with %d segments; re-run with those flags or delete it to start over",
+			path, saved.Job, saved.TotalSegments)
+	}
+	cp.Segments = saved.Segments
+	slog.Info("Resuming from checkpoint", "path", path)
+	return cp, nil
+}
+
+// ReadOnly stops the checkpoint from being saved or removed. A dry run
+// resumes from an existing checkpoint but mustn't leave one that makes the
+// real run skip items it never wrote.
+func (cp *Checkpoint) ReadOnly() { cp.path = "" }
+
+// Path returns the checkpoint file, or "" if progress isn't persisted.
+func (cp *Checkpoint) Path() string { return cp.path }
+
+// Started returns how many segments have progress to resume from.
+func (cp *Checkpoint) Started() int {
+	n := 0
+	for _, s := range cp.Segments {
+		if s.Done || s.LastKey != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Done reports whether a segment has been scanned to the end.
+func (cp *Checkpoint) Done(seg int) bool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.Segments[seg].Done
+}
+
+// StartKey returns the key a segment's scan resumes after, or nil.
+func (cp *Checkpoint) StartKey(seg int) map[string]types.AttributeValue {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	key := cp.Segments[seg].LastKey
+	if len(key) == 0 {
+		return nil
+	}
+	out := make(map[string]types.AttributeValue, len(key))
+	for name, v := range key {
+		out[name] = &types.AttributeValueMemberS{Value: v}
+	}
+	return out
+}
+
+// Update records that a segment has processed everything up to lastKey (a
+// page's LastEvaluatedKey; empty at the end of the segment) and saves the
+// checkpoint, replacing the file atomically so a crash mid-write can't
+// corrupt it.
+func (cp *Checkpoint) Update(seg int, lastKey map[string]types.AttributeValue) error {
+	key, err := encodeKey(lastKey)
+	if err != nil {
+		return err
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.Segments[seg] = SegmentProgress{LastKey: key, Done: len(lastKey) == 0}
+	if cp.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+	tmp := cp.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, cp.path); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the checkpoint after a complete run, so the next run
+// starts from the beginning.
+func (cp *Checkpoint) Remove() error {
+	if cp.path == "" {
+		return nil
+	}
+	if err := os.Remove(cp.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+// encodeKey converts a LastEvaluatedKey to JSON-friendly strings. The
+// table's keys (PK, SK, and any index keys) are all strings.
+func encodeKey(key map[string]types.AttributeValue) (map[string]string, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(key))
+	for name, v := range key {
+		s, ok := v.(*types.AttributeValueMemberS)
+		if !ok {
+			return nil, fmt.Errorf("key attribute %s is not a string", name)
+		}
+		out[name] = s.Value
+	}
+	return out, nil
+}
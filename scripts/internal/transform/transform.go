@@ -0,0 +1,264 @@
+package transform
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Item is a DynamoDB item as returned by Scan.
+type Item = map[string]types.AttributeValue
+
+// Transform is a declarative data fix: Match selects the items it applies
+// to and Apply rewrites a matched item. Apply works on a shallow copy, so it
+// must replace attribute values (item["x"] = ...) rather than modify them.
+type Transform struct {
+	Name        string
+	Description string
+	Match       func(Item) bool
+	Apply       func(Item)
+}
+
+var registry = map[string]Transform{}
+
+// Register adds a transform to the registry. It panics on a duplicate or
+// incomplete transform, since registration happens in init.
+func Register(t Transform) {
+	if t.Name == "" || t.Match == nil || t.Apply == nil {
+		panic("transform: Register needs a name, Match, and Apply")
+	}
+	if _, dup := registry[t.Name]; dup {
+		panic("transform: duplicate transform " + t.Name)
+	}
+	registry[t.Name] = t
+}
+
+// Names returns the registered transform names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the named transform.
+func Get(name string) (Transform, error) {
+	t, ok := registry[name]
+	if !ok {
+		return Transform{}, fmt.Errorf("unknown transform %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return t, nil
+}
+
+// Parse resolves a comma-separated list of transform names, keeping their
+// order. An empty list yields no transforms.
+func Parse(list string) ([]Transform, error) {
+	var ts []Transform
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		t, err := Get(name)
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+// Apply runs each matching transform, in order, on a copy of item and
+// returns the copy with the names of the transforms that matched. Later
+// transforms see earlier ones' changes. item itself is never modified; with
+// no matches the result is item.
+func Apply(item Item, ts []Transform) (Item, []string) {
+	var out Item
+	var applied []string
+	for _, t := range ts {
+		cur := item
+		if out != nil {
+			cur = out
+		}
+		if !t.Match(cur) {
+			continue
+		}
+		if out == nil {
+			out = make(Item, len(item))
+			for k, v := range item {
+				out[k] = v
+			}
+		}
+		t.Apply(out)
+		applied = append(applied, t.Name)
+	}
+	if out == nil {
+		return item, nil
+	}
+	return out, applied
+}
+
+// Diff describes how after differs from before, one line per changed
+// attribute in name order: "name: old -> new", with (none) for an attribute
+// that was added or removed.
+func Diff(before, after Item) []string {
+	var lines []string
+	for _, name := range attrNames(before, after) {
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		if inBefore && inAfter && reflect.DeepEqual(a, b) {
+			continue
+		}
+		old, cur := "(none)", "(none)"
+		if inBefore {
+			old = Format(b)
+		}
+		if inAfter {
+			cur = Format(a)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s -> %s", name, old, cur))
+	}
+	return lines
+}
+
+// UpdateInput builds the UpdateItem call that turns before into after in
+// place: SET for added or changed attributes, REMOVE for removed ones. The
+// key attributes must be unchanged. The update is conditional on the item
+// still existing, so an item deleted since the scan isn't recreated as a
+// stub. Returns nil if nothing changed.
+func UpdateInput(table string, keyNames []string, before, after Item) (*dynamodb.UpdateItemInput, error) {
+	if len(keyNames) == 0 {
+		return nil, fmt.Errorf("no key attributes given")
+	}
+	key := make(Item, len(keyNames))
+	isKey := make(map[string]bool, len(keyNames))
+	for _, k := range keyNames {
+		v, ok := before[k]
+		if !ok {
+			return nil, fmt.Errorf("item has no key attribute %s", k)
+		}
+		if !reflect.DeepEqual(v, after[k]) {
+			return nil, fmt.Errorf("transform changed key attribute %s", k)
+		}
+		key[k] = v
+		isKey[k] = true
+	}
+
+	names := map[string]string{}
+	values := map[string]types.AttributeValue{}
+	var set, remove []string
+	for i, name := range attrNames(before, after) {
+		if isKey[name] {
+			continue
+		}
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		placeholder := "#a" + strconv.Itoa(i)
+		switch {
+		case !inAfter:
+			remove = append(remove, placeholder)
+		case !inBefore || !reflect.DeepEqual(a, b):
+			set = append(set, placeholder+" = :v"+strconv.Itoa(i))
+			values[":v"+strconv.Itoa(i)] = a
+		default:
+			continue
+		}
+		names[placeholder] = name
+	}
+	if len(set) == 0 && len(remove) == 0 {
+		return nil, nil
+	}
+
+	var expr []string
+	if len(set) > 0 {
+		expr = append(expr, "SET "+strings.Join(set, ", "))
+	}
+	if len(remove) > 0 {
+		expr = append(expr, "REMOVE "+strings.Join(remove, ", "))
+	}
+	names["#k"] = keyNames[0]
+	in := &dynamodb.UpdateItemInput{
+		TableName:                aws.String(table),
+		Key:                      key,
+		UpdateExpression:         aws.String(strings.Join(expr, " ")),
+		ConditionExpression:      aws.String("attribute_exists(#k)"),
+		ExpressionAttributeNames: names,
+	}
+	if len(values) > 0 {
+		in.ExpressionAttributeValues = values
+	}
+	return in, nil
+}
+
+// Format renders an attribute value compactly for diffs and logs.
+func Format(v types.AttributeValue) string {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		return strconv.Quote(v.Value)
+	case *types.AttributeValueMemberN:
+		return v.Value
+	case *types.AttributeValueMemberBOOL:
+		return strconv.FormatBool(v.Value)
+	case *types.AttributeValueMemberNULL:
+		return "null"
+	case *types.AttributeValueMemberB:
+		return fmt.Sprintf("<%d bytes>", len(v.Value))
+	case *types.AttributeValueMemberSS:
+		return fmt.Sprintf("%q", v.Value)
+	case *types.AttributeValueMemberNS:
+		return "[" + strings.Join(v.Value, " ") + "]"
+	case *types.AttributeValueMemberBS:
+		return fmt.Sprintf("<%d binary values>", len(v.Value))
+	case *types.AttributeValueMemberL:
+		parts := make([]string, len(v.Value))
+		for i, e := range v.Value {
+			parts[i] = Format(e)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case *types.AttributeValueMemberM:
+		names := make([]string, 0, len(v.Value))
+		for name := range v.Value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = name + ": " + Format(v.Value[name])
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// Str returns a string attribute's value, or "" if it's missing or not a
+// string.
+func Str(item Item, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+// attrNames returns the attribute names of both items, sorted.
+func attrNames(a, b Item) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var names []string
+	for _, item := range []Item{a, b} {
+		for name := range item {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
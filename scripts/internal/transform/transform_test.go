@@ -0,0 +1,193 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func s(v string) types.AttributeValue { return &types.AttributeValueMemberS{Value: v} }
+
+func TestParse(t *testing.T) {
+	ts, err := Parse(" backfill-gsi2, ,rewrite-audio-url")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tr := range ts {
+		names = append(names, tr.Name)
+	}
+	if want := []string{"backfill-gsi2", "rewrite-audio-url"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Parse names = %v, want %v", names, want)
+	}
+
+	if ts, err := Parse(""); err != nil || len(ts) != 0 {
+		t.Errorf("Parse(\"\") = %v, %v; want no transforms", ts, err)
+	}
+	if _, err := Parse("no-such-fix"); err == nil || !strings.Contains(err.Error(), "backfill-gsi2") {
+		t.Errorf("Parse(unknown) error = %v; want one listing the available transforms", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	setA := Transform{
+		Name:  "set-a",
+		Match: func(item Item) bool { return Str(item, "a") == "" },
+		Apply: func(item Item) { item["a"] = s("1") },
+	}
+	// Matches only after set-a has run, so it sees earlier changes.
+	setB := Transform{
+		Name:  "set-b",
+		Match: func(item Item) bool { return Str(item, "a") == "1" },
+		Apply: func(item Item) { item["b"] = s("2") },
+	}
+
+	item := Item{"PK": s("X#1")}
+	out, applied := Apply(item, []Transform{setA, setB})
+	if want := []string{"set-a", "set-b"}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied = %v, want %v", applied, want)
+	}
+	if Str(out, "a") != "1" || Str(out, "b") != "2" {
+		t.Errorf("out = %v, want a=1 and b=2", out)
+	}
+	if len(item) != 1 {
+		t.Errorf("Apply modified its input: %v", item)
+	}
+
+	done := Item{"PK": s("X#1"), "a": s("9")}
+	out, applied = Apply(done, []Transform{setA, setB})
+	if applied != nil || !reflect.DeepEqual(out, done) {
+		t.Errorf("Apply with no matches = %v, %v; want the item unchanged", out, applied)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := Item{"PK": s("P#1"), "gone": s("x"), "n": &types.AttributeValueMemberN{Value: "1"}}
+	after := Item{"PK": s("P#1"), "added": &types.AttributeValueMemberBOOL{Value: true}, "n": &types.AttributeValueMemberN{Value: "2"}}
+	want := []string{
+		"added: (none) -> true",
+		`gone: "x" -> (none)`,
+		"n: 1 -> 2",
+	}
+	if got := Diff(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff = %q, want %q", got, want)
+	}
+	if got := Diff(before, before); got != nil {
+		t.Errorf("Diff of identical items = %q, want none", got)
+	}
+}
+
+func TestUpdateInput(t *testing.T) {
+	keys := []string{"PK", "SK"}
+	before := Item{"PK": s("P#1"), "SK": s("METADATA"), "keep": s("k"), "old": s("o"), "url": s("a")}
+	after := Item{"PK": s("P#1"), "SK": s("METADATA"), "keep": s("k"), "new": s("n"), "url": s("b")}
+
+	in, err := UpdateInput("table", keys, before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr := *in.UpdateExpression
+	setPart, removePart, _ := strings.Cut(expr, " REMOVE ")
+	if !strings.HasPrefix(setPart, "SET ") || strings.Count(setPart, "=") != 2 || removePart == "" {
+		t.Fatalf("UpdateExpression = %q, want SET of two attributes and a REMOVE", expr)
+	}
+	var set, removed []string
+	for name, attr := range in.ExpressionAttributeNames {
+		switch {
+		case name == "#k":
+		case strings.Contains(setPart, name+" = "):
+			set = append(set, attr)
+		case strings.Contains(removePart, name):
+			removed = append(removed, attr)
+		}
+	}
+	if len(set) != 2 || !reflect.DeepEqual(removed, []string{"old"}) {
+		t.Errorf("set %v and removed %v; want new and url set, old removed", set, removed)
+	}
+	if _, ok := in.ExpressionAttributeNames["#k"]; !ok || *in.ConditionExpression != "attribute_exists(#k)" {
+		t.Errorf("update isn't conditional on the item existing: %v", in.ConditionExpression)
+	}
+	if !reflect.DeepEqual(in.Key, Item{"PK": s("P#1"), "SK": s("METADATA")}) {
+		t.Errorf("Key = %v", in.Key)
+	}
+
+	if in, err := UpdateInput("table", keys, before, before); in != nil || err != nil {
+		t.Errorf("UpdateInput with no change = %v, %v; want nil, nil", in, err)
+	}
+	moved := Item{"PK": s("P#2"), "SK": s("METADATA")}
+	if _, err := UpdateInput("table", keys, before, moved); err == nil {
+		t.Error("UpdateInput accepted a changed key attribute")
+	}
+	if _, err := UpdateInput("table", []string{"id"}, before, after); err == nil {
+		t.Error("UpdateInput accepted an item without its key attribute")
+	}
+}
+
+func TestRewriteAudioURL(t *testing.T) {
+	old := Item{"PK": s("PODCAST#1"), "audioUrl": s("https://apresai.dev/audio/1.mp3")}
+	out, applied := Apply(old, []Transform{rewriteAudioURL})
+	if len(applied) != 1 || Str(out, "audioUrl") != "https://podcasts.apresai.dev/audio/1.mp3" {
+		t.Fatalf("rewrite-audio-url = %v, %v", out, applied)
+	}
+	// Idempotent: the rewritten URL no longer matches.
+	if _, applied := Apply(out, []Transform{rewriteAudioURL}); applied != nil {
+		t.Errorf("rewrite-audio-url matched its own output")
+	}
+	if rewriteAudioURL.Match(Item{"PK": s("USER#1"), "audioUrl": s("https://apresai.dev/audio/1.mp3")}) {
+		t.Error("rewrite-audio-url matched a non-podcast item")
+	}
+}
+
+func TestBackfillGSI2(t *testing.T) {
+	tests := []struct {
+		name   string
+		item   Item
+		gsi1pk string // "" when the item shouldn't match
+	}{
+		{
+			name:   "user ID",
+			item:   Item{"PK": s("PODCAST#1"), "SK": s("METADATA"), "GSI1SK": s("2025-01-01"), "userId": s("u1")},
+			gsi1pk: "USER#u1#PODCASTS",
+		},
+		{
+			name:   "ULID owner",
+			item:   Item{"PK": s("PODCAST#2"), "SK": s("METADATA"), "GSI1SK": s("2025-01-01"), "owner": s("01HZY3K9V6W8X0ABCDEFGHJKMN")},
+			gsi1pk: "USER#01HZY3K9V6W8X0ABCDEFGHJKMN#PODCASTS",
+		},
+		{
+			name:   "anonymous",
+			item:   Item{"PK": s("PODCAST#3"), "SK": s("METADATA"), "GSI1SK": s("2025-01-01"), "owner": s("someone")},
+			gsi1pk: "PODCASTS",
+		},
+		{
+			name: "already indexed",
+			item: Item{"PK": s("PODCAST#4"), "SK": s("METADATA"), "GSI1SK": s("2025-01-01"), "GSI2PK": s("PODCASTS")},
+		},
+		{
+			name: "no GSI1SK",
+			item: Item{"PK": s("PODCAST#5"), "SK": s("METADATA")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, applied := Apply(tt.item, []Transform{backfillGSI2})
+			if tt.gsi1pk == "" {
+				if applied != nil {
+					t.Fatalf("backfill-gsi2 matched %v", tt.item)
+				}
+				return
+			}
+			if applied == nil {
+				t.Fatalf("backfill-gsi2 didn't match %v", tt.item)
+			}
+			if got := Str(out, "GSI1PK"); got != tt.gsi1pk {
+				t.Errorf("GSI1PK = %q, want %q", got, tt.gsi1pk)
+			}
+			if Str(out, "GSI2PK") != "PODCASTS" || Str(out, "GSI2SK") != Str(tt.item, "GSI1SK") {
+				t.Errorf("GSI2 keys = %q/%q", Str(out, "GSI2PK"), Str(out, "GSI2SK"))
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package transform
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// The built-in data fixes. Add new ones here with Register.
+func init() {
+	Register(rewriteAudioURL)
+	Register(backfillGSI2)
+}
+
+// rewriteAudioURL moves podcast audio URLs from apresai.dev/audio/ to
+// podcasts.apresai.dev/audio/. URLs already on the new domain don't match,
+// so it is idempotent.
+var rewriteAudioURL = Transform{
+	Name:        "rewrite-audio-url",
+	Description: "Point podcast audioUrl at podcasts.apresai.dev/audio/ instead of apresai.dev/audio/",
+	Match: func(item Item) bool {
+		url := Str(item, "audioUrl")
+		return strings.HasPrefix(Str(item, "PK"), "PODCAST#") &&
+			strings.Contains(url, "apresai.dev/audio/") &&
+			!strings.Contains(url, "podcasts.apresai.dev/audio/")
+	},
+	Apply: func(item Item) {
+		url := strings.ReplaceAll(Str(item, "audioUrl"), "apresai.dev/audio/", "podcasts.apresai.dev/audio/")
+		item["audioUrl"] = &types.AttributeValueMemberS{Value: url}
+	},
+}
+
+var ulidRe = regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+
+// backfillGSI2 adds podcasts to GSI2 (the global podcast index) and fixes
+// GSI1 (the per-user index) for podcasts created before those indexes were
+// keyed this way. GSI2SK reuses GSI1SK, so podcasts without one (which
+// couldn't be listed anyway) are left alone.
+var backfillGSI2 = Transform{
+	Name:        "backfill-gsi2",
+	Description: "Add podcasts to GSI2 (global index) and key GSI1 by owning user",
+	Match: func(item Item) bool {
+		return strings.HasPrefix(Str(item, "PK"), "PODCAST#") &&
+			Str(item, "SK") == "METADATA" &&
+			Str(item, "GSI2PK") != "PODCASTS" &&
+			Str(item, "GSI1SK") != ""
+	},
+	Apply: func(item Item) {
+		// Determine user ID for per-user GSI1PK; an owner that looks like
+		// a ULID is an authenticated user.
+		userID := Str(item, "userId")
+		if owner := Str(item, "owner"); userID == "" && ulidRe.MatchString(owner) {
+			userID = owner
+		}
+		gsi1pk := "PODCASTS" // anonymous fallback
+		if userID != "" {
+			gsi1pk = "USER#" + userID + "#PODCASTS"
+		}
+		item["GSI1PK"] = &types.AttributeValueMemberS{Value: gsi1pk}
+		item["GSI2PK"] = &types.AttributeValueMemberS{Value: "PODCASTS"}
+		item["GSI2SK"] = &types.AttributeValueMemberS{Value: Str(item, "GSI1SK")}
+	},
+}
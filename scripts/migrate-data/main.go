@@ -1,26 +1,70 @@
+// Copy every item from the old DynamoDB table to the new one, applying
+// data fixes from scripts/internal/transform on the way (by default,
+// rewriting audio URLs to the podcasts.apresai.dev domain).
+//
+// The source table is scanned in parallel segments and writes are throttled
+// to --max-writes items per second. Progress (each segment's last evaluated
+// key) is checkpointed after every page, so an interrupted run picks up
+// where it stopped when started again with the same flags. --verify compares
+// the two tables item by item afterwards.
+//
+// Usage:
+//
+//	go run ./scripts/migrate-data --dry-run                 # scan, count, and print diffs
+//	go run ./scripts/migrate-data --segments 8 --verify     # migrate, then verify
+//	go run ./scripts/migrate-data --verify-only             # compare tables only
 package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"flag"
 	"fmt"
+	"hash"
 	"log/slog"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/apresai/podcaster/scripts/internal/scan"
+	"github.com/apresai/podcaster/scripts/internal/transform"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+const (
+	batchSize = 25 // BatchWriteItem limit
+	// maxWriteAttempts bounds retries of a batch's unprocessed items.
+	maxWriteAttempts = 8
+	// maxVerifySamples is how many mismatched keys verification prints.
+	maxVerifySamples = 20
+)
+
+// counters are the run totals, shared by the segment workers.
+type counters struct {
+	scanned     atomic.Int64
+	written     atomic.Int64
+	transformed atomic.Int64
+}
+
 func main() {
 	var (
-		sourceTable = flag.String("source-table", "apresai-podcasts-prod", "Source DynamoDB table")
-		destTable   = flag.String("dest-table", "podcaster-prod", "Destination DynamoDB table")
-		dryRun      = flag.Bool("dry-run", false, "Scan and count but don't write")
-		region      = flag.String("region", "us-east-1", "AWS region")
+		sourceTable    = flag.String("source-table", "apresai-podcasts-prod", "Source DynamoDB table")
+		destTable      = flag.String("dest-table", "podcaster-prod", "Destination DynamoDB table")
+		dryRun         = flag.Bool("dry-run", false, "Scan and count but don't write")
+		region         = flag.String("region", "us-east-1", "AWS region")
+		segments       = flag.Int("segments", 4, "Parallel scan segments (one worker each)")
+		maxWrites      = flag.Int("max-writes", 200, "Write throughput limit in items per second across all workers (0 = unlimited)")
+		checkpointPath = flag.String("checkpoint", "migrate-data.checkpoint.json", "Progress file for resuming an interrupted run (removed on success)")
+		verify         = flag.Bool("verify", false, "After migrating, compare item counts and hashes between the tables")
+		verifyOnly     = flag.Bool("verify-only", false, "Skip migration and only run the verification pass")
+		transforms     = flag.String("transforms", "rewrite-audio-url", "Comma-separated transforms applied to items on the way (see go run ./scripts/transform --list)")
 	)
 	flag.Parse()
 
@@ -29,6 +73,16 @@ func main() {
 	}))
 	slog.SetDefault(logger)
 
+	if *segments < 1 {
+		slog.Error("--segments must be at least 1")
+		os.Exit(1)
+	}
+	ts, err := transform.Parse(*transforms)
+	if err != nil {
+		slog.Error("Invalid --transforms", "error", err)
+		os.Exit(1)
+	}
+
 	ctx := context.Background()
 
 	// Load AWS config
@@ -40,163 +94,333 @@ func main() {
 
 	ddbClient := dynamodb.NewFromConfig(cfg)
 
-	if *dryRun {
-		slog.Info("DRY RUN MODE - no writes will be performed")
+	if !*verifyOnly {
+		if err := migrate(ctx, ddbClient, *sourceTable, *destTable, ts, *segments, *maxWrites, *checkpointPath, *dryRun); err != nil {
+			slog.Error("Migration failed", "error", err)
+			os.Exit(1)
+		}
 	}
 
-	slog.Info("Starting migration",
-		"source", *sourceTable,
-		"dest", *destTable,
-		"region", *region,
-	)
+	if *verify || *verifyOnly {
+		ok, err := verifyTables(ctx, ddbClient, *sourceTable, *destTable, ts, *segments)
+		if err != nil {
+			slog.Error("Verification failed", "error", err)
+			os.Exit(1)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	}
+}
 
-	// Counters
-	var (
-		totalScanned  atomic.Int64
-		totalWritten  atomic.Int64
-		totalRewrites atomic.Int64
-	)
+// migrate copies the source table to the destination with one worker per
+// scan segment, resuming from the checkpoint file if it exists.
+func migrate(ctx context.Context, client *dynamodb.Client, source, dest string, ts []transform.Transform, segments, maxWrites int, checkpointPath string, dryRun bool) error {
+	if dryRun {
+		slog.Info("DRY RUN MODE - no writes will be performed")
+	}
 
-	// Scan parameters
-	scanInput := &dynamodb.ScanInput{
-		TableName: aws.String(*sourceTable),
+	cp, err := scan.LoadCheckpoint(checkpointPath, source+" -> "+dest, segments)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		cp.ReadOnly()
 	}
 
-	var batch []types.WriteRequest
-	const batchSize = 25
+	slog.Info("Starting migration",
+		"source", source,
+		"dest", dest,
+		"segments", segments,
+		"max_writes_per_sec", maxWrites,
+		"resumed_segments", cp.Started(),
+	)
 
-	// Paginate through all items
-	paginator := dynamodb.NewScanPaginator(ddbClient, scanInput)
+	var c counters
+	limit := scan.NewThrottle(maxWrites)
 
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
-		if err != nil {
-			slog.Error("Scan failed", "error", err)
-			os.Exit(1)
+	err = scan.Parallel(ctx, segments, func(ctx context.Context, seg int) error {
+		if cp.Done(seg) {
+			return nil
+		}
+		// On failure the other segments stop; the checkpoint keeps their
+		// progress.
+		return migrateSegment(ctx, client, source, dest, ts, seg, cp, limit, &c, dryRun)
+	})
+
+	// Final summary
+	slog.Info("Migration finished",
+		"total_scanned", c.scanned.Load(),
+		"total_written", c.written.Load(),
+		"total_transformed", c.transformed.Load(),
+		"dry_run", dryRun,
+	)
+	if err != nil {
+		if cp.Path() != "" {
+			slog.Info("Progress saved; re-run with the same flags to resume", "checkpoint", cp.Path())
 		}
+		return err
+	}
+	return cp.Remove()
+}
 
+// migrateSegment scans one segment from its checkpointed key, writing each
+// page before recording the page's last evaluated key. A dry run logs each
+// transformed item's diff instead of writing.
+func migrateSegment(ctx context.Context, client *dynamodb.Client, source, dest string, ts []transform.Transform, seg int, cp *scan.Checkpoint, limit *scan.Throttle, c *counters, dryRun bool) error {
+	input := dynamodb.ScanInput{TableName: aws.String(source)}
+	return scan.Segment(ctx, client, input, seg, cp.TotalSegments, cp.StartKey(seg), func(page *dynamodb.ScanOutput) error {
+		var batch []types.WriteRequest
 		for _, item := range page.Items {
-			totalScanned.Add(1)
-
-			// Process item
-			processedItem := processItem(item, &totalRewrites)
-
-			if !*dryRun {
-				// Add to batch
-				batch = append(batch, types.WriteRequest{
-					PutRequest: &types.PutRequest{
-						Item: processedItem,
-					},
-				})
-
-				// Write batch if full
-				if len(batch) >= batchSize {
-					if err := writeBatch(ctx, ddbClient, *destTable, batch); err != nil {
-						slog.Error("Batch write failed", "error", err)
-						os.Exit(1)
-					}
-					totalWritten.Add(int64(len(batch)))
-					batch = batch[:0] // Clear batch
+			c.scanned.Add(1)
+			processedItem, applied := transform.Apply(item, ts)
+			if len(applied) > 0 {
+				c.transformed.Add(1)
+				if dryRun {
+					slog.Info("Would transform",
+						"key", itemKey(item),
+						"transforms", strings.Join(applied, ","),
+						"diff", strings.Join(transform.Diff(item, processedItem), "; "),
+					)
 				}
 			}
-
-			// Log progress every 100 items
-			if totalScanned.Load()%100 == 0 {
-				slog.Info("Progress",
-					"scanned", totalScanned.Load(),
-					"written", totalWritten.Load(),
-					"rewrites", totalRewrites.Load(),
-				)
+			if dryRun {
+				continue
+			}
+			batch = append(batch, types.WriteRequest{
+				PutRequest: &types.PutRequest{Item: processedItem},
+			})
+			if len(batch) == batchSize {
+				if err := writeBatch(ctx, client, dest, batch, limit); err != nil {
+					return err
+				}
+				c.written.Add(int64(len(batch)))
+				batch = batch[:0]
 			}
 		}
-	}
-
-	// Write remaining items in batch
-	if !*dryRun && len(batch) > 0 {
-		if err := writeBatch(ctx, ddbClient, *destTable, batch); err != nil {
-			slog.Error("Final batch write failed", "error", err)
-			os.Exit(1)
+		if len(batch) > 0 {
+			if err := writeBatch(ctx, client, dest, batch, limit); err != nil {
+				return err
+			}
+			c.written.Add(int64(len(batch)))
 		}
-		totalWritten.Add(int64(len(batch)))
-	}
 
-	// Final summary
-	slog.Info("Migration complete",
-		"total_scanned", totalScanned.Load(),
-		"total_written", totalWritten.Load(),
-		"total_rewrites", totalRewrites.Load(),
-		"dry_run", *dryRun,
-	)
+		if err := cp.Update(seg, page.LastEvaluatedKey); err != nil {
+			return err
+		}
+		slog.Info("Progress",
+			"segment", seg,
+			"scanned", c.scanned.Load(),
+			"written", c.written.Load(),
+			"transformed", c.transformed.Load(),
+		)
+		return nil
+	})
 }
 
-// processItem processes a single DynamoDB item, rewriting audioUrl if needed
-func processItem(item map[string]types.AttributeValue, rewriteCounter *atomic.Int64) map[string]types.AttributeValue {
-	// Check if PK starts with "PODCAST#"
-	pkAttr, ok := item["PK"]
-	if !ok {
-		return item
+// writeBatch writes a batch of items to the destination table, waiting on
+// the throttle first. Unprocessed items (the table pushing back) are retried
+// with exponential backoff.
+func writeBatch(ctx context.Context, client *dynamodb.Client, tableName string, batch []types.WriteRequest, limit *scan.Throttle) error {
+	if len(batch) == 0 {
+		return nil
 	}
-
-	pkStr, ok := pkAttr.(*types.AttributeValueMemberS)
-	if !ok || !strings.HasPrefix(pkStr.Value, "PODCAST#") {
-		return item
+	if err := limit.Wait(ctx, len(batch)); err != nil {
+		return err
 	}
 
-	// Check for audioUrl attribute
-	audioUrlAttr, ok := item["audioUrl"]
-	if !ok {
-		return item
+	requests := map[string][]types.WriteRequest{tableName: batch}
+	for attempt := 0; ; attempt++ {
+		result, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: requests,
+		})
+		if err != nil {
+			return fmt.Errorf("BatchWriteItem failed: %w", err)
+		}
+		unprocessed := len(result.UnprocessedItems[tableName])
+		if unprocessed == 0 {
+			return nil
+		}
+		if attempt+1 == maxWriteAttempts {
+			return fmt.Errorf("still have %d unprocessed items after %d attempts", unprocessed, maxWriteAttempts)
+		}
+		backoff := time.Duration(100<<attempt) * time.Millisecond
+		slog.Warn("Unprocessed items, retrying", "count", unprocessed, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		requests = result.UnprocessedItems
 	}
+}
+
+// verifyTables scans both tables and compares them item by item: every
+// source item (with the transforms applied, as migrated) must exist in the
+// destination with the same content hash. Destination items with no source
+// are reported but don't fail verification, since the new table may have
+// taken writes since the migration. Reports whether the tables match.
+func verifyTables(ctx context.Context, client *dynamodb.Client, source, dest string, ts []transform.Transform, segments int) (bool, error) {
+	slog.Info("Verifying", "source", source, "dest", dest)
 
-	audioUrlStr, ok := audioUrlAttr.(*types.AttributeValueMemberS)
-	if !ok {
-		return item
+	want, err := tableDigests(ctx, client, source, segments, ts)
+	if err != nil {
+		return false, err
+	}
+	got, err := tableDigests(ctx, client, dest, segments, nil)
+	if err != nil {
+		return false, err
 	}
 
-	// Rewrite URL: apresai.dev/audio/ → podcasts.apresai.dev/audio/
-	// Skip URLs already pointing to podcasts.apresai.dev (idempotent)
-	if strings.Contains(audioUrlStr.Value, "apresai.dev/audio/") &&
-		!strings.Contains(audioUrlStr.Value, "podcasts.apresai.dev/audio/") {
-		newUrl := strings.ReplaceAll(audioUrlStr.Value, "apresai.dev/audio/", "podcasts.apresai.dev/audio/")
-		item["audioUrl"] = &types.AttributeValueMemberS{Value: newUrl}
-		rewriteCounter.Add(1)
+	var missing, different, extra []string
+	var sourceSum, destSum uint64
+	for key, sum := range want {
+		sourceSum += sum
+		switch d, ok := got[key]; {
+		case !ok:
+			missing = append(missing, key)
+		case d != sum:
+			different = append(different, key)
+		}
+	}
+	for key, sum := range got {
+		destSum += sum
+		if _, ok := want[key]; !ok {
+			extra = append(extra, key)
+		}
 	}
 
-	return item
+	slog.Info("Verification complete",
+		"source_items", len(want),
+		"dest_items", len(got),
+		"source_hash", fmt.Sprintf("%016x", sourceSum),
+		"dest_hash", fmt.Sprintf("%016x", destSum),
+		"missing", len(missing),
+		"different", len(different),
+		"extra_in_dest", len(extra),
+	)
+	logSamples("Missing from destination", missing)
+	logSamples("Content differs", different)
+	logSamples("Only in destination", extra)
+	return len(missing) == 0 && len(different) == 0, nil
 }
 
-// writeBatch writes a batch of items to the destination table
-func writeBatch(ctx context.Context, client *dynamodb.Client, tableName string, batch []types.WriteRequest) error {
-	if len(batch) == 0 {
-		return nil
-	}
+// tableDigests scans a table in parallel and returns a content hash per
+// item, keyed by PK and SK, after applying ts (the migration's transforms
+// for the source table, so its items hash as they were written).
+func tableDigests(ctx context.Context, client *dynamodb.Client, table string, segments int, ts []transform.Transform) (map[string]uint64, error) {
+	var (
+		mu      sync.Mutex
+		digests = make(map[string]uint64)
+	)
+	input := dynamodb.ScanInput{TableName: aws.String(table)}
+	err := scan.Parallel(ctx, segments, func(ctx context.Context, seg int) error {
+		return scan.Segment(ctx, client, input, seg, segments, nil, func(page *dynamodb.ScanOutput) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, item := range page.Items {
+				item, _ = transform.Apply(item, ts)
+				digests[itemKey(item)] = itemDigest(item)
+			}
+			return nil
+		})
+	})
+	return digests, err
+}
 
-	input := &dynamodb.BatchWriteItemInput{
-		RequestItems: map[string][]types.WriteRequest{
-			tableName: batch,
-		},
+func itemKey(item map[string]types.AttributeValue) string {
+	var pk, sk string
+	if v, ok := item["PK"].(*types.AttributeValueMemberS); ok {
+		pk = v.Value
 	}
+	if v, ok := item["SK"].(*types.AttributeValueMemberS); ok {
+		sk = v.Value
+	}
+	return pk + " | " + sk
+}
 
-	result, err := client.BatchWriteItem(ctx, input)
-	if err != nil {
-		return fmt.Errorf("BatchWriteItem failed: %w", err)
+// itemDigest hashes an item independently of attribute and set order. The
+// first 8 bytes of the SHA-256 are plenty to spot a difference, and summing
+// them gives an order-independent hash of a whole table.
+func itemDigest(item map[string]types.AttributeValue) uint64 {
+	h := sha256.New()
+	hashValue(h, &types.AttributeValueMemberM{Value: item})
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
+
+// hashValue writes a canonical encoding of v: a type tag, then the value,
+// with lengths so that adjacent values can't run together.
+func hashValue(h hash.Hash, v types.AttributeValue) {
+	writeStr := func(tag byte, s string) {
+		h.Write([]byte{tag})
+		binary.Write(h, binary.BigEndian, uint32(len(s)))
+		h.Write([]byte(s))
+	}
+	writeSet := func(tag byte, values []string) {
+		sorted := append([]string(nil), values...)
+		sort.Strings(sorted)
+		h.Write([]byte{tag})
+		binary.Write(h, binary.BigEndian, uint32(len(sorted)))
+		for _, s := range sorted {
+			writeStr(tag, s)
+		}
 	}
 
-	// Handle unprocessed items (retry logic could be added here)
-	if len(result.UnprocessedItems) > 0 {
-		slog.Warn("Unprocessed items detected", "count", len(result.UnprocessedItems[tableName]))
-		// Simple retry: just attempt once more
-		retryInput := &dynamodb.BatchWriteItemInput{
-			RequestItems: result.UnprocessedItems,
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		writeStr('S', v.Value)
+	case *types.AttributeValueMemberN:
+		writeStr('N', v.Value)
+	case *types.AttributeValueMemberB:
+		writeStr('B', string(v.Value))
+	case *types.AttributeValueMemberBOOL:
+		if v.Value {
+			h.Write([]byte{'T'})
+		} else {
+			h.Write([]byte{'F'})
 		}
-		retryResult, err := client.BatchWriteItem(ctx, retryInput)
-		if err != nil {
-			return fmt.Errorf("Retry BatchWriteItem failed: %w", err)
+	case *types.AttributeValueMemberNULL:
+		h.Write([]byte{'0'})
+	case *types.AttributeValueMemberSS:
+		writeSet('s', v.Value)
+	case *types.AttributeValueMemberNS:
+		writeSet('n', v.Value)
+	case *types.AttributeValueMemberBS:
+		bs := make([]string, len(v.Value))
+		for i, b := range v.Value {
+			bs[i] = string(b)
 		}
-		if len(retryResult.UnprocessedItems) > 0 {
-			return fmt.Errorf("Still have %d unprocessed items after retry", len(retryResult.UnprocessedItems[tableName]))
+		writeSet('b', bs)
+	case *types.AttributeValueMemberL:
+		h.Write([]byte{'L'})
+		binary.Write(h, binary.BigEndian, uint32(len(v.Value)))
+		for _, e := range v.Value {
+			hashValue(h, e)
 		}
+	case *types.AttributeValueMemberM:
+		names := make([]string, 0, len(v.Value))
+		for name := range v.Value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		h.Write([]byte{'M'})
+		binary.Write(h, binary.BigEndian, uint32(len(names)))
+		for _, name := range names {
+			writeStr('K', name)
+			hashValue(h, v.Value[name])
+		}
+	default:
+		h.Write([]byte{'?'})
 	}
+}
 
-	return nil
+func logSamples(msg string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	sort.Strings(keys)
+	if len(keys) > maxVerifySamples {
+		keys = keys[:maxVerifySamples]
+	}
+	slog.Warn(msg, "keys", strings.Join(keys, ", "))
 }
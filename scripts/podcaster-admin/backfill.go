@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"github.com/apresai/podcaster/scripts/internal/scan"
+	"github.com/apresai/podcaster/scripts/internal/transform"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// backfillCounters are the run totals, shared by the segment workers.
+type backfillCounters struct {
+	scanned   atomic.Int64 // items read by the scan
+	matched   atomic.Int64 // items that passed the filter
+	updated   atomic.Int64
+	unchanged atomic.Int64 // already had the values (or, without --overwrite, some value)
+	skipped   atomic.Int64 // template referenced a missing attribute, or deleted since the scan
+}
+
+// runBackfill sets attributes on every item matching --filter. Items are
+// updated in place with only the attributes that change, conditional on the
+// item still existing, so a re-run (or a resumed run) is a no-op for items
+// already done. Without --overwrite, attributes the item already has are
+// left alone.
+func runBackfill(args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	var sets setFlags
+	var (
+		tableName      = fs.String("table", "podcaster-prod", "DynamoDB table name")
+		filterExpr     = fs.String("filter", "", "Items to update, e.g. 'begins_with(PK, PODCAST#) AND SK = METADATA' (required)")
+		overwrite      = fs.Bool("overwrite", false, "Replace attributes that already have a different value")
+		dryRun         = fs.Bool("dry-run", false, "Print the changes without writing them")
+		region         = fs.String("region", "us-east-1", "AWS region")
+		segments       = fs.Int("segments", 4, "Parallel scan segments (one worker each)")
+		maxWrites      = fs.Int("max-writes", 100, "Write throughput limit in items per second across all workers (0 = unlimited)")
+		checkpointPath = fs.String("checkpoint", "podcaster-admin-backfill.checkpoint.json", "Progress file for resuming an interrupted run (removed on success)")
+	)
+	fs.Var(&sets, "set", "NAME=VALUE to set; {attr} in VALUE is replaced by the item's attribute (repeatable)")
+	fs.Parse(args)
+
+	if *filterExpr == "" {
+		return errors.New("--filter is required (use 'attribute_exists(PK)' to update every item)")
+	}
+	if len(sets) == 0 {
+		return errors.New("at least one --set is required")
+	}
+	if *segments < 1 {
+		return errors.New("--segments must be at least 1")
+	}
+	f, err := parseFilter(*filterExpr)
+	if err != nil {
+		return fmt.Errorf("--filter: %w", err)
+	}
+	assignments := make([]assignment, 0, len(sets))
+	for _, s := range sets {
+		a, err := parseAssignment(s)
+		if err != nil {
+			return err
+		}
+		if a.attr == "PK" || a.attr == "SK" {
+			return fmt.Errorf("--set %s: key attributes can't be changed", a.attr)
+		}
+		assignments = append(assignments, a)
+	}
+
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(*region))
+	if err != nil {
+		return fmt.Errorf("load aws config: %w", err)
+	}
+	client := dynamodb.NewFromConfig(cfg)
+
+	// The job names everything that decides which items change and how, so
+	// a checkpoint is only resumed by the same backfill.
+	job := fmt.Sprintf("backfill %s filter=%q set=%q overwrite=%v", *tableName, *filterExpr, []string(sets), *overwrite)
+	cp, err := scan.LoadCheckpoint(*checkpointPath, job, *segments)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		slog.Info("DRY RUN MODE - no writes will be performed")
+		cp.ReadOnly()
+	}
+
+	input := dynamodb.ScanInput{TableName: tableName}
+	f.apply(&input)
+
+	slog.Info("Starting backfill",
+		"table", *tableName,
+		"filter", f.expr,
+		"set", sets.String(),
+		"overwrite", *overwrite,
+		"segments", *segments,
+		"max_writes_per_sec", *maxWrites,
+		"resumed_segments", cp.Started(),
+	)
+
+	b := &backfill{
+		client:      client,
+		table:       *tableName,
+		assignments: assignments,
+		overwrite:   *overwrite,
+		dryRun:      *dryRun,
+		limit:       scan.NewThrottle(*maxWrites),
+	}
+	err = scan.Parallel(ctx, *segments, func(ctx context.Context, seg int) error {
+		if cp.Done(seg) {
+			return nil
+		}
+		return scan.Segment(ctx, client, input, seg, *segments, cp.StartKey(seg), func(page *dynamodb.ScanOutput) error {
+			b.c.scanned.Add(int64(page.ScannedCount))
+			for _, item := range page.Items {
+				if err := b.item(ctx, item); err != nil {
+					return err
+				}
+			}
+			// Only record the page once all its items are written, so a
+			// failure re-does the page on resume.
+			if err := cp.Update(seg, page.LastEvaluatedKey); err != nil {
+				return err
+			}
+			slog.Info("Progress",
+				"segment", seg,
+				"scanned", b.c.scanned.Load(),
+				"matched", b.c.matched.Load(),
+				"updated", b.c.updated.Load(),
+			)
+			return nil
+		})
+	})
+
+	slog.Info("Backfill finished",
+		"scanned", b.c.scanned.Load(),
+		"matched", b.c.matched.Load(),
+		"updated", b.c.updated.Load(),
+		"unchanged", b.c.unchanged.Load(),
+		"skipped", b.c.skipped.Load(),
+		"dry_run", *dryRun,
+	)
+	if err != nil {
+		if cp.Path() != "" {
+			slog.Info("Progress saved; re-run with the same flags to resume", "checkpoint", cp.Path())
+		}
+		return err
+	}
+	return cp.Remove()
+}
+
+type backfill struct {
+	client      *dynamodb.Client
+	table       string
+	assignments []assignment
+	overwrite   bool
+	dryRun      bool
+	limit       *scan.Throttle
+	c           backfillCounters
+}
+
+// item updates one matched item. Only write failures are returned; items
+// that can't or needn't be updated are counted and logged.
+func (b *backfill) item(ctx context.Context, item transform.Item) error {
+	b.c.matched.Add(1)
+	key := transform.Str(item, "PK") + " | " + transform.Str(item, "SK")
+
+	after, err := b.apply(item)
+	if err != nil {
+		slog.Warn("Skipping item", "key", key, "error", err)
+		b.c.skipped.Add(1)
+		return nil
+	}
+	in, err := transform.UpdateInput(b.table, []string{"PK", "SK"}, item, after)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if in == nil {
+		b.c.unchanged.Add(1)
+		return nil
+	}
+
+	if b.dryRun {
+		slog.Info("Would update", "key", key, "diff", strings.Join(transform.Diff(item, after), "; "))
+		b.c.updated.Add(1)
+		return nil
+	}
+	if err := b.limit.Wait(ctx, 1); err != nil {
+		return err
+	}
+	if _, err := b.client.UpdateItem(ctx, in); err != nil {
+		var deleted *types.ConditionalCheckFailedException
+		if errors.As(err, &deleted) {
+			slog.Warn("Skipping item deleted since the scan", "key", key)
+			b.c.skipped.Add(1)
+			return nil
+		}
+		return fmt.Errorf("update %s: %w", key, err)
+	}
+	b.c.updated.Add(1)
+	return nil
+}
+
+// apply returns a copy of item with the assignments rendered and set.
+// Without overwrite, attributes the item already has keep their values.
+func (b *backfill) apply(item transform.Item) (transform.Item, error) {
+	after := make(transform.Item, len(item)+len(b.assignments))
+	for k, v := range item {
+		after[k] = v
+	}
+	for _, a := range b.assignments {
+		if _, exists := item[a.attr]; exists && !b.overwrite {
+			continue
+		}
+		// Render from the original item so assignments don't depend on
+		// their order.
+		v, err := a.render(item)
+		if err != nil {
+			return nil, err
+		}
+		after[a.attr] = &types.AttributeValueMemberS{Value: v}
+	}
+	return after, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/apresai/podcaster/scripts/internal/transform"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// filter is a --filter expression translated to a Scan FilterExpression.
+//
+// The syntax is DynamoDB's condition functions and comparisons with plain
+// attribute names and unquoted string values, joined by AND:
+//
+//	begins_with(PK, PODCAST#) AND SK = METADATA AND attribute_not_exists(GSI2PK)
+//
+// Supported: begins_with(a, v), contains(a, v), attribute_exists(a),
+// attribute_not_exists(a), a = v, a <> v. Quote a value ('…' or "…") if it
+// contains a comma, a closing parenthesis, or " AND ".
+type filter struct {
+	expr   string
+	names  map[string]string
+	values map[string]types.AttributeValue
+}
+
+var (
+	filterAndRe    = regexp.MustCompile(`(?i)\s+AND\s+`)
+	filterArgSepRe = regexp.MustCompile(`\s*,\s*`)
+	filterFuncRe   = regexp.MustCompile(`^(\w+)\s*\((.*)\)$`)
+	filterCmpRe    = regexp.MustCompile(`^([\w.-]+)\s*(=|<>)\s*(.+)$`)
+	attrNameRe     = regexp.MustCompile(`^[A-Za-z_][\w.-]*$`)
+)
+
+func parseFilter(s string) (*filter, error) {
+	f := &filter{
+		names:  map[string]string{},
+		values: map[string]types.AttributeValue{},
+	}
+	var conds []string
+	for _, part := range splitOutsideQuotes(strings.TrimSpace(s), filterAndRe) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("empty condition in filter %q", s)
+		}
+		cond, err := f.condition(part)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	f.expr = strings.Join(conds, " AND ")
+	return f, nil
+}
+
+// condition translates one condition, adding its placeholders to f.
+func (f *filter) condition(s string) (string, error) {
+	if m := filterFuncRe.FindStringSubmatch(s); m != nil {
+		fn := strings.ToLower(m[1])
+		args := splitOutsideQuotes(m[2], filterArgSepRe)
+		switch fn {
+		case "attribute_exists", "attribute_not_exists":
+			if len(args) != 1 {
+				return "", fmt.Errorf("%s takes one attribute: %q", fn, s)
+			}
+			name, err := f.name(args[0])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s(%s)", fn, name), nil
+		case "begins_with", "contains":
+			if len(args) != 2 {
+				return "", fmt.Errorf("%s takes an attribute and a value: %q", fn, s)
+			}
+			name, err := f.name(args[0])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s(%s, %s)", fn, name, f.value(args[1])), nil
+		default:
+			return "", fmt.Errorf("unsupported function %s in %q (use begins_with, contains, attribute_exists, attribute_not_exists)", m[1], s)
+		}
+	}
+	if m := filterCmpRe.FindStringSubmatch(s); m != nil {
+		name, err := f.name(m[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %s", name, m[2], f.value(m[3])), nil
+	}
+	return "", fmt.Errorf("can't parse condition %q (expected a function like begins_with(PK, PODCAST#) or a comparison like SK = METADATA)", s)
+}
+
+func (f *filter) name(attr string) (string, error) {
+	attr = strings.TrimSpace(attr)
+	if !attrNameRe.MatchString(attr) {
+		return "", fmt.Errorf("invalid attribute name %q", attr)
+	}
+	placeholder := "#f" + strconv.Itoa(len(f.names))
+	f.names[placeholder] = attr
+	return placeholder, nil
+}
+
+func (f *filter) value(v string) string {
+	placeholder := ":f" + strconv.Itoa(len(f.values))
+	f.values[placeholder] = &types.AttributeValueMemberS{Value: unquote(strings.TrimSpace(v))}
+	return placeholder
+}
+
+// apply sets the filter on a Scan.
+func (f *filter) apply(in *dynamodb.ScanInput) {
+	in.FilterExpression = aws.String(f.expr)
+	in.ExpressionAttributeNames = f.names
+	if len(f.values) > 0 {
+		in.ExpressionAttributeValues = f.values
+	}
+}
+
+// splitOutsideQuotes splits s on sep, ignoring separators inside '…' or
+// "…" quotes.
+func splitOutsideQuotes(s string, sep *regexp.Regexp) []string {
+	var parts []string
+	start := 0
+	for _, loc := range sep.FindAllStringIndex(s, -1) {
+		if quoted(s[:loc[0]]) {
+			continue
+		}
+		parts = append(parts, s[start:loc[0]])
+		start = loc[1]
+	}
+	return append(parts, s[start:])
+}
+
+// quoted reports whether a quote opened in prefix is still open at its end.
+func quoted(prefix string) bool {
+	var open rune
+	for _, r := range prefix {
+		switch {
+		case open == 0 && (r == '\'' || r == '"'):
+			open = r
+		case r == open:
+			open = 0
+		}
+	}
+	return open != 0
+}
+
+func unquote(v string) string {
+	if len(v) >= 2 && (v[0] == '\'' || v[0] == '"') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// assignment is one --set NAME=TEMPLATE. The template is literal text with
+// {attr} placeholders replaced by the item's attribute values, so
+// GSI1PK=USER#{userId}#PODCASTS keys an index by another attribute. The
+// result is always a string.
+type assignment struct {
+	attr     string
+	template string
+}
+
+var templateRefRe = regexp.MustCompile(`\{([^{}]*)\}`)
+
+func parseAssignment(s string) (assignment, error) {
+	attr, template, ok := strings.Cut(s, "=")
+	attr = strings.TrimSpace(attr)
+	if !ok || !attrNameRe.MatchString(attr) {
+		return assignment{}, fmt.Errorf("invalid --set %q (expected NAME=VALUE, e.g. GSI2PK=PODCASTS)", s)
+	}
+	for _, m := range templateRefRe.FindAllStringSubmatch(template, -1) {
+		if !attrNameRe.MatchString(m[1]) {
+			return assignment{}, fmt.Errorf("invalid attribute reference {%s} in --set %q", m[1], s)
+		}
+	}
+	return assignment{attr: attr, template: template}, nil
+}
+
+// render fills in the template from item. A referenced attribute that is
+// missing, or isn't a string or number, is an error: the item is skipped
+// rather than given a half-built key.
+func (a assignment) render(item transform.Item) (string, error) {
+	var err error
+	out := templateRefRe.ReplaceAllStringFunc(a.template, func(ref string) string {
+		name := ref[1 : len(ref)-1]
+		switch v := item[name].(type) {
+		case *types.AttributeValueMemberS:
+			if v.Value != "" {
+				return v.Value
+			}
+		case *types.AttributeValueMemberN:
+			return v.Value
+		}
+		if err == nil {
+			err = fmt.Errorf("%s: no string or number attribute %s", a.attr, name)
+		}
+		return ""
+	})
+	return out, err
+}
+
+// setFlags collects repeated --set flags.
+type setFlags []string
+
+func (s *setFlags) String() string     { return strings.Join(*s, ", ") }
+func (s *setFlags) Set(v string) error { *s = append(*s, v); return nil }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/apresai/podcaster/internal/feature"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// featuresKey is the item holding the MCP server's feature flag overrides,
+// percent by flag in its rollouts map. Servers re-read it every minute.
+var featuresKey = map[string]types.AttributeValue{
+	"PK": &types.AttributeValueMemberS{Value: "CONFIG"},
+	"SK": &types.AttributeValueMemberS{Value: "FEATURES"},
+}
+
+// runFeatures lists the feature flag overrides, or with --set or --clear
+// changes them. An override replaces the server's configured rollout for
+// that flag; --clear returns the flag to its configured rollout.
+func runFeatures(args []string) error {
+	fs := flag.NewFlagSet("features", flag.ExitOnError)
+	var (
+		tableName = fs.String("table", "podcaster-prod", "DynamoDB table name")
+		region    = fs.String("region", "us-east-1", "AWS region")
+		set       = fs.String("set", "", "Overrides to set, e.g. 'parallel-tts=10' (0 turns a flag off everywhere)")
+		clearFlag = fs.String("clear", "", "Flag whose override to remove")
+	)
+	fs.Parse(args)
+
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(*region))
+	if err != nil {
+		return fmt.Errorf("load aws config: %w", err)
+	}
+	client := dynamodb.NewFromConfig(cfg)
+
+	if *set != "" {
+		rollouts, err := feature.ParseRollouts(*set)
+		if err != nil {
+			return fmt.Errorf("--set: %w", err)
+		}
+		for name, pct := range rollouts {
+			// Create the map if needed, then set each flag on its own
+			// so concurrent changes to other flags aren't lost.
+			if _, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName:                 tableName,
+				Key:                       featuresKey,
+				UpdateExpression:          aws.String("SET rollouts = if_not_exists(rollouts, :empty)"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":empty": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}}},
+			}); err != nil {
+				return fmt.Errorf("create overrides: %w", err)
+			}
+			if _, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName:                 tableName,
+				Key:                       featuresKey,
+				UpdateExpression:          aws.String("SET rollouts.#f = :pct"),
+				ExpressionAttributeNames:  map[string]string{"#f": name},
+				ExpressionAttributeValues: map[string]types.AttributeValue{":pct": &types.AttributeValueMemberN{Value: strconv.Itoa(pct)}},
+			}); err != nil {
+				return fmt.Errorf("set %s: %w", name, err)
+			}
+		}
+	}
+	if *clearFlag != "" {
+		if _, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                tableName,
+			Key:                      featuresKey,
+			UpdateExpression:         aws.String("REMOVE rollouts.#f"),
+			ExpressionAttributeNames: map[string]string{"#f": *clearFlag},
+		}); err != nil {
+			return fmt.Errorf("clear %s: %w", *clearFlag, err)
+		}
+	}
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{TableName: tableName, Key: featuresKey, ConsistentRead: aws.Bool(true)})
+	if err != nil {
+		return fmt.Errorf("get overrides: %w", err)
+	}
+	var overrides map[string]types.AttributeValue
+	if m, ok := out.Item["rollouts"].(*types.AttributeValueMemberM); ok {
+		overrides = m.Value
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FLAG\tOVERRIDE\tDESCRIPTION")
+	names := make([]string, 0, len(feature.Known))
+	for name := range feature.Known {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		override := "-"
+		if n, ok := overrides[name].(*types.AttributeValueMemberN); ok {
+			override = n.Value + "%"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, override, feature.Known[name])
+	}
+	w.Flush()
+	fmt.Println("\nFlags without an override use the server's configured rollout (features, PODCASTER_FEATURES).")
+	return nil
+}
@@ -0,0 +1,64 @@
+// Admin commands for the podcaster DynamoDB table.
+//
+// Usage:
+//
+//	go run ./scripts/podcaster-admin backfill --filter 'begins_with(PK, PODCAST#)' --set 'GSI2PK=PODCASTS' --dry-run
+//	go run ./scripts/podcaster-admin backfill --filter 'begins_with(PK, PODCAST#) AND SK = METADATA' \
+//	    --set 'GSI2PK=PODCASTS' --set 'GSI2SK={GSI1SK}'
+//	go run ./scripts/podcaster-admin features --set 'parallel-tts=10'
+//
+// Run a command with -h for its flags.
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+)
+
+// commands maps subcommand names to their entry points, which parse their
+// own flags from args.
+var commands = map[string]struct {
+	run     func(args []string) error
+	summary string
+}{
+	"backfill": {runBackfill, "Set attributes on every item matching a filter (e.g. keys for a new index)"},
+	"features": {runFeatures, "List or change the MCP server's feature flag rollout overrides"},
+}
+
+func main() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	})))
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		if os.Args[1] != "-h" && os.Args[1] != "--help" && os.Args[1] != "help" {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		}
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		slog.Error(os.Args[1]+" failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: podcaster-admin <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
@@ -0,0 +1,119 @@
+// Apply registered data fixes (scripts/internal/transform) to a DynamoDB
+// table in place. Every item is scanned; items a transform matches are
+// updated with only the attributes it changed.
+//
+// Usage:
+//
+//	go run ./scripts/transform --list                                 # show transforms
+//	go run ./scripts/transform --transform backfill-gsi2 --dry-run    # preview diffs
+//	go run ./scripts/transform --transform backfill-gsi2              # apply changes
+//	go run ./scripts/transform --transform a,b --table my-table       # several, in order
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/apresai/podcaster/scripts/internal/transform"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func main() {
+	tableName := flag.String("table", "podcaster-prod", "DynamoDB table name")
+	names := flag.String("transform", "", "Comma-separated transforms to apply, in order")
+	dryRun := flag.Bool("dry-run", false, "Print the changes without writing them")
+	region := flag.String("region", "us-east-1", "AWS region")
+	list := flag.Bool("list", false, "List the available transforms and exit")
+	flag.Parse()
+
+	if *list {
+		for _, name := range transform.Names() {
+			t, _ := transform.Get(name)
+			fmt.Printf("%-20s %s\n", t.Name, t.Description)
+		}
+		return
+	}
+
+	transforms, err := transform.Parse(*names)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(transforms) == 0 {
+		log.Fatal("--transform is required (see --list)")
+	}
+
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(*region))
+	if err != nil {
+		log.Fatalf("load aws config: %v", err)
+	}
+	client := dynamodb.NewFromConfig(cfg)
+
+	fmt.Printf("Table: %s | Transforms: %s | Dry run: %v\n", *tableName, *names, *dryRun)
+
+	var scanned, updated, failed int
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{TableName: tableName})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("scan: %v", err)
+		}
+
+		for _, item := range page.Items {
+			scanned++
+			after, applied := transform.Apply(item, transforms)
+			if len(applied) == 0 {
+				continue
+			}
+
+			action := "UPDATE"
+			if *dryRun {
+				action = "DRY-RUN"
+			}
+			fmt.Printf("[%s] %s %s (%s)\n", action, transform.Str(item, "PK"), transform.Str(item, "SK"), strings.Join(applied, ", "))
+			for _, line := range transform.Diff(item, after) {
+				fmt.Printf("    %s\n", line)
+			}
+			if *dryRun {
+				updated++
+				continue
+			}
+
+			in, err := transform.UpdateInput(*tableName, []string{"PK", "SK"}, item, after)
+			if err != nil {
+				log.Printf("ERROR %s: %v", transform.Str(item, "PK"), err)
+				failed++
+				continue
+			}
+			if in == nil {
+				continue // matched but changed nothing
+			}
+			if _, err := client.UpdateItem(ctx, in); err != nil {
+				var deleted *types.ConditionalCheckFailedException
+				if errors.As(err, &deleted) {
+					log.Printf("SKIP %s: deleted since the scan", transform.Str(item, "PK"))
+					continue
+				}
+				log.Printf("ERROR updating %s: %v", transform.Str(item, "PK"), err)
+				failed++
+				continue
+			}
+			updated++
+		}
+	}
+
+	fmt.Printf("\nDone. Scanned: %d, Updated: %d, Failed: %d\n", scanned, updated, failed)
+	if *dryRun {
+		fmt.Println("(dry run — no changes written)")
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}